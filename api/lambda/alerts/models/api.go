@@ -22,10 +22,11 @@ import "time"
 
 // LambdaInput is the request structure for the alerts-api Lambda function.
 type LambdaInput struct {
-	GetAlert            *GetAlertInput            `json:"getAlert"`
-	ListAlerts          *ListAlertsInput          `json:"listAlerts"`
-	UpdateAlertStatus   *UpdateAlertStatusInput   `json:"updateAlertStatus"`
-	UpdateAlertDelivery *UpdateAlertDeliveryInput `json:"updateAlertDelivery"`
+	GetAlert             *GetAlertInput             `json:"getAlert"`
+	ListAlerts           *ListAlertsInput           `json:"listAlerts"`
+	UpdateAlertStatus    *UpdateAlertStatusInput    `json:"updateAlertStatus"`
+	UpdateAlertDelivery  *UpdateAlertDeliveryInput  `json:"updateAlertDelivery"`
+	ListDeliveryAttempts *ListDeliveryAttemptsInput `json:"listDeliveryAttempts"`
 }
 
 // GetAlertInput retrieves details for a single alert.
@@ -145,6 +146,69 @@ type DeliveryResponse struct {
 	StatusCode   int       `json:"statusCode"`
 	Success      bool      `json:"success"`
 	DispatchedAt time.Time `json:"dispatchedAt"`
+	// TicketID is the ID of the ticket created by ticketing outputs (Jira, Github, Asana, ServiceNow, Zendesk)
+	TicketID string `json:"ticketId,omitempty"`
+	// LatencyMillis is how long the output took to respond
+	LatencyMillis int64 `json:"latencyMillis,omitempty"`
+	// RetryCount is the number of previous attempts to deliver the alert
+	RetryCount int `json:"retryCount,omitempty"`
+	// Resent is true if the delivery was manually requested by a user
+	Resent bool `json:"resent,omitempty"`
+	// Status is set for deliveries which did not reach the output, e.g. SUPPRESSED
+	Status string `json:"status,omitempty"`
+}
+
+// ListDeliveryAttemptsInput lists the delivery history of an alert or an output,
+// in reverse-chronological order (newest to oldest).
+//
+// Exactly one of "alertId" or "outputId" must be set.
+//
+// {
+//     "listDeliveryAttempts": {
+//         "alertId": "84c3e4b27c702a1c31e6eb412fc377f6",
+//         "pageSize": 25,
+//         "exclusiveStartKey": "abcdef",
+//         "dispatchedAtAfter": "2020-06-17T15:49:40Z",
+//         "dispatchedAtBefore": "2020-06-18T15:49:40Z"
+//     }
+// }
+type ListDeliveryAttemptsInput struct {
+	AlertID  *string `json:"alertId" validate:"omitempty,hexadecimal,len=32"`
+	OutputID *string `json:"outputId" validate:"omitempty,uuid4"`
+
+	// Number of results to return per query
+	PageSize *int `json:"pageSize" validate:"omitempty,min=1,max=50"`
+
+	// Infinite scroll/pagination query key
+	ExclusiveStartKey *string `json:"exclusiveStartKey"`
+
+	// Filtering
+	DispatchedAtAfter  *time.Time `json:"dispatchedAtAfter"`
+	DispatchedAtBefore *time.Time `json:"dispatchedAtBefore"`
+}
+
+// ListDeliveryAttemptsOutput is the returned delivery timeline.
+type ListDeliveryAttemptsOutput struct {
+	// DeliveryAttempts is a list of attempts sorted by dispatch time descending.
+	DeliveryAttempts []*DeliveryAttempt `json:"deliveryAttempts"`
+	// LastEvaluatedKey is populated if there are more attempts available
+	LastEvaluatedKey *string `json:"lastEvaluatedKey,omitempty"`
+}
+
+// DeliveryAttempt is a single attempt to deliver an alert to an output
+type DeliveryAttempt struct {
+	AlertID       string    `json:"alertId"`
+	OutputID      string    `json:"outputId"`
+	DispatchedAt  time.Time `json:"dispatchedAt"`
+	StatusCode    int       `json:"statusCode"`
+	Success       bool      `json:"success"`
+	LatencyMillis int64     `json:"latencyMillis"`
+	RetryCount    int       `json:"retryCount"`
+	Resent        bool      `json:"resent"`
+	TicketID      string    `json:"ticketId,omitempty"`
+	Status        string    `json:"status,omitempty"`
+	// ResponseExcerpt is the (truncated) message or response body returned by the output
+	ResponseExcerpt string `json:"responseExcerpt"`
 }
 
 // UpdateAlertStatusOutput is an alias for an alert summary
@@ -168,6 +232,9 @@ const (
 	ResolvedStatus = "RESOLVED"
 )
 
+// DeliverySuppressedStatus is set on the delivery responses of an alert held back by a maintenance window
+const DeliverySuppressedStatus = "SUPPRESSED"
+
 // ListAlertsOutput is the returned alert list.
 type ListAlertsOutput struct {
 	// Alerts is a list of alerts sorted by timestamp descending.
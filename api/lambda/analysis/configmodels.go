@@ -23,12 +23,14 @@ package analysis
 // YAML tags required because the YAML unmarshaller needs them
 // JSON tags not present because the JSON unmarshaller is easy
 type Config struct {
+	AlertGrouping             *AlertGrouping      `yaml:"AlertGrouping"`
 	AnalysisType              string              `yaml:"AnalysisType"`
 	AutoRemediationID         string              `yaml:"AutoRemediationID"`
 	AutoRemediationParameters map[string]string   `yaml:"AutoRemediationParameters"`
 	DataModelID               string              `yaml:"DataModelID"`
 	DedupPeriodMinutes        int                 `yaml:"DedupPeriodMinutes"`
 	Description               string              `yaml:"Description"`
+	Detection                 interface{}         `yaml:"Detection"` // declarative rule spec, instead of a Filename
 	DisplayName               string              `yaml:"DisplayName"`
 	Enabled                   bool                `yaml:"Enabled"`
 	Filename                  string              `yaml:"Filename"`
@@ -49,6 +51,13 @@ type Config struct {
 	Threshold                 int                 `yaml:"Threshold"`
 }
 
+// AlertGrouping configures how the matches of a rule are grouped into alerts.
+type AlertGrouping struct {
+	Window             string `yaml:"Window"`
+	MaxAlertAgeMinutes int    `yaml:"MaxAlertAgeMinutes"`
+	MaxEventsPerAlert  int64  `yaml:"MaxEventsPerAlert"`
+}
+
 // Mapping converts source log field name to standard field name.
 type Mapping struct {
 	Path   string `yaml:"Path"`
@@ -21,17 +21,22 @@ package models
 type DetectionType string
 
 const (
-	TypePolicy    DetectionType = "POLICY"
-	TypeRule      DetectionType = "RULE"
-	TypeGlobal    DetectionType = "GLOBAL"
-	TypeDataModel DetectionType = "DATAMODEL"
+	TypePolicy        DetectionType = "POLICY"
+	TypeRule          DetectionType = "RULE"
+	TypeScheduledRule DetectionType = "SCHEDULED_RULE"
+	TypeGlobal        DetectionType = "GLOBAL"
+	TypeDataModel     DetectionType = "DATAMODEL"
+	TypePack          DetectionType = "PACK"
 )
 
 type LambdaInput struct {
 	// Shared
-	BulkUpload       *BulkUploadInput     `json:"bulkUpload,omitempty"`
-	ListDetections   *ListDetectionsInput `json:"listDetections,omitempty"`
-	DeleteDetections *DeletePoliciesInput `json:"deleteDetections,omitempty"`
+	BulkUpload            *BulkUploadInput            `json:"bulkUpload,omitempty"`
+	ListDetections        *ListDetectionsInput        `json:"listDetections,omitempty"`
+	DeleteDetections      *DeletePoliciesInput        `json:"deleteDetections,omitempty"`
+	DiffDetectionVersions *DiffDetectionVersionsInput `json:"diffDetectionVersions,omitempty"`
+	ListDetectionVersions *ListDetectionVersionsInput `json:"listDetectionVersions,omitempty"`
+	RevertDetection       *RevertDetectionInput       `json:"revertDetection,omitempty"`
 
 	// Globals
 	CreateGlobal  *CreateGlobalInput  `json:"createGlobal,omitempty"`
@@ -56,6 +61,7 @@ type LambdaInput struct {
 	GetRule     *GetRuleInput     `json:"getRule,omitempty"`
 	// TODO deprecate this endpoint in favor of ListDetections
 	ListRules  *ListRulesInput  `json:"listRules,omitempty"`
+	ReplayRule *ReplayRuleInput `json:"replayRule,omitempty"`
 	TestRule   *TestRuleInput   `json:"testRule,omitempty"`
 	UpdateRule *UpdateRuleInput `json:"updateRule,omitempty"`
 
@@ -65,6 +71,15 @@ type LambdaInput struct {
 	GetDataModel     *GetDataModelInput     `json:"getDataModel,omitempty"`
 	ListDataModels   *ListDataModelsInput   `json:"listDataModels,omitempty"`
 	UpdateDataModel  *UpdateDataModelInput  `json:"updateDataModel,omitempty"`
+
+	// Detection packs
+	ApplyPack         *ApplyPackInput         `json:"applyPack,omitempty"`
+	CreatePackSource  *CreatePackSourceInput  `json:"createPackSource,omitempty"`
+	DeletePackSources *DeletePackSourcesInput `json:"deletePackSources,omitempty"`
+	ListPackSources   *ListPackSourcesInput   `json:"listPackSources,omitempty"`
+	ListPackVersions  *ListPackVersionsInput  `json:"listPackVersions,omitempty"`
+	PreviewPack       *PreviewPackInput       `json:"previewPack,omitempty"`
+	RollbackPack      *RollbackPackInput      `json:"rollbackPack,omitempty"`
 }
 
 type UnitTest struct {
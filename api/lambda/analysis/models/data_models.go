@@ -60,7 +60,7 @@ type UpdateDataModelInput struct {
 	DisplayName string             `json:"displayName" validate:"max=1000,excludesall='<>&\""`
 	Enabled     bool               `json:"enabled"`
 	ID          string             `json:"id" validate:"required,max=1000,excludesall='<>&\""`
-	LogTypes    []string           `json:"logTypes" validate:"len=1,dive,required,max=500"` // for now, only one logtype allowed
+	LogTypes    []string           `json:"logTypes" validate:"min=1,max=500,dive,required,max=500"`
 	Mappings    []DataModelMapping `json:"mappings" validate:"min=1,max=500,dive"`
 	UserID      string             `json:"userId" validate:"required"`
 }
@@ -78,6 +78,9 @@ type DataModel struct {
 	LogTypes       []string           `json:"logTypes"`
 	Mappings       []DataModelMapping `json:"mappings"`
 	VersionID      string             `json:"versionId"`
+
+	// The unified field names each log type supports, checked against the log type schema on save
+	SupportedFields map[string][]string `json:"supportedFields"`
 }
 
 type DataModelMapping struct {
@@ -40,7 +40,7 @@ type ListDetectionsInput struct {
 	LogTypes []string `json:"logTypes" validate:"max=500,dive,required,max=500"`
 
 	// Only include detections with the following type
-	AnalysisTypes []DetectionType `json:"analysisTypes" validate:"omitempty,dive,oneof=RULE POLICY"`
+	AnalysisTypes []DetectionType `json:"analysisTypes" validate:"omitempty,dive,oneof=RULE POLICY SCHEDULED_RULE"`
 
 	// Only include detections whose ID or display name contains this case-insensitive substring
 	NameContains string `json:"nameContains" validate:"max=1000"`
@@ -92,13 +92,17 @@ type Detection struct {
 	Suppressions              []string                `json:"suppressions" validate:"max=500,dive,required,max=1000"`
 
 	// Rule only
-	DedupPeriodMinutes int      `json:"dedupPeriodMinutes"`
-	LogTypes           []string `json:"logTypes"`
-	Threshold          int      `json:"threshold"`
+	AlertGrouping      *AlertGrouping  `json:"alertGrouping,omitempty"`
+	DedupPeriodMinutes int             `json:"dedupPeriodMinutes"`
+	Exceptions         []RuleException `json:"exceptions"`
+	LogTypes           []string        `json:"logTypes"`
+	ScheduledQuery     *ScheduledQuery `json:"scheduledQuery,omitempty"`
+	Spec               string          `json:"spec"`
+	Threshold          int             `json:"threshold"`
 
 	// Shared
 	AnalysisType   DetectionType       `json:"analysisType"`
-	Body           string              `json:"body" validate:"required,max=100000"`
+	Body           string              `json:"body" validate:"required_without_all=Spec ScheduledQuery,max=100000"`
 	CreatedAt      time.Time           `json:"createdAt"`
 	CreatedBy      string              `json:"createdBy"`
 	Description    string              `json:"description"`
@@ -108,6 +112,8 @@ type Detection struct {
 	LastModified   time.Time           `json:"lastModified"`
 	LastModifiedBy string              `json:"lastModifiedBy"`
 	OutputIDs      []string            `json:"outputIds" validate:"max=500,dive,required,max=5000"`
+	PackSource     string              `json:"packSource"`
+	PackVersion    string              `json:"packVersion"`
 	Reference      string              `json:"reference" validate:"max=10000"`
 	Reports        map[string][]string `json:"reports" validate:"max=500"`
 	Runbook        string              `json:"runbook" validate:"max=10000"`
@@ -0,0 +1,136 @@
+package models
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import "time"
+
+type PackSourceType string
+
+const (
+	// Versions are the releases of a GitHub repository, the pack is a release asset
+	PackSourceGitHub PackSourceType = "GITHUB"
+	// Versions are the zip files under an S3 prefix, named after the version
+	PackSourceS3 PackSourceType = "S3"
+)
+
+type PackChangeType string
+
+const (
+	PackChangeAdded    PackChangeType = "ADDED"
+	PackChangeModified PackChangeType = "MODIFIED"
+	PackChangeRemoved  PackChangeType = "REMOVED"
+)
+
+type CreatePackSourceInput struct {
+	ID         string         `json:"id" validate:"required,max=1000,excludesall='<>&\""`
+	SourceType PackSourceType `json:"sourceType" validate:"oneof=GITHUB S3"`
+
+	// GitHub sources
+	Owner      string `json:"owner" validate:"max=100"`
+	Repository string `json:"repository" validate:"max=100"`
+	AssetName  string `json:"assetName" validate:"max=1000"` // default: panther-analysis-all.zip
+
+	// S3 sources, e.g. "s3://bucket/packs/"
+	S3URL string `json:"s3Url" validate:"max=2000"`
+
+	UserID string `json:"userId" validate:"required"`
+}
+
+type DeletePackSourcesInput struct {
+	IDs []string `json:"ids" validate:"min=1,max=1000,dive,required,max=1000"`
+}
+
+type ListPackSourcesInput struct{}
+
+type ListPackSourcesOutput struct {
+	Sources []PackSource `json:"sources"`
+}
+
+type ListPackVersionsInput struct {
+	SourceID string `json:"sourceId" validate:"required,max=1000"`
+}
+
+type ListPackVersionsOutput struct {
+	Versions []PackVersion `json:"versions"`
+}
+
+type PreviewPackInput struct {
+	SourceID string `json:"sourceId" validate:"required,max=1000"`
+	Version  string `json:"version" validate:"required,max=1000"`
+}
+
+type PreviewPackOutput struct {
+	Changes []PackChange `json:"changes"`
+}
+
+type ApplyPackInput struct {
+	SourceID string `json:"sourceId" validate:"required,max=1000"`
+	Version  string `json:"version" validate:"required,max=1000"`
+	UserID   string `json:"userId" validate:"required"`
+}
+
+type ApplyPackOutput struct {
+	Source  PackSource   `json:"source"`
+	Changes []PackChange `json:"changes"`
+}
+
+// Re-apply the version which was applied before the current one
+type RollbackPackInput struct {
+	SourceID string `json:"sourceId" validate:"required,max=1000"`
+	UserID   string `json:"userId" validate:"required"`
+}
+
+type RollbackPackOutput = ApplyPackOutput
+
+type PackSource struct {
+	ID         string         `json:"id"`
+	SourceType PackSourceType `json:"sourceType"`
+	Owner      string         `json:"owner,omitempty"`
+	Repository string         `json:"repository,omitempty"`
+	AssetName  string         `json:"assetName,omitempty"`
+	S3URL      string         `json:"s3Url,omitempty"`
+
+	// The version currently deployed and the one it replaced
+	AppliedVersion  string     `json:"appliedVersion,omitempty"`
+	PreviousVersion string     `json:"previousVersion,omitempty"`
+	AppliedAt       *time.Time `json:"appliedAt,omitempty"`
+	AppliedBy       string     `json:"appliedBy,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	CreatedBy string    `json:"createdBy"`
+}
+
+type PackVersion struct {
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+}
+
+// A detection which differs between the deployed analysis set and a pack version
+type PackChange struct {
+	ID           string         `json:"id"`
+	AnalysisType DetectionType  `json:"analysisType"`
+	Change       PackChangeType `json:"change"`
+
+	// Modified fields of the detection
+	Fields []string `json:"fields"`
+
+	// Fields which were changed locally and are kept as deployed
+	Overridden []string `json:"overridden"`
+}
@@ -0,0 +1,95 @@
+package models
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import "time"
+
+type ReplayRuleInput struct {
+	// The rule to replay, which need not be saved
+	Body     string   `json:"body" validate:"required_without=Spec,max=100000"`
+	ID       string   `json:"id" validate:"max=1000"`
+	LogTypes []string `json:"logTypes" validate:"max=500,dive,required,max=500"`
+	Spec     string   `json:"spec" validate:"max=100000"`
+
+	// The historical data to replay the rule against
+	LogType   string    `json:"logType" validate:"required,max=500"`
+	StartTime time.Time `json:"startTime" validate:"required"`
+	EndTime   time.Time `json:"endTime" validate:"required,gtfield=StartTime"`
+
+	// Stop after this many events (default: 100000)
+	MaxEvents int `json:"maxEvents" validate:"min=0,max=1000000"`
+
+	// Number of matched events to return (default: 10)
+	MaxSamples int `json:"maxSamples" validate:"min=0,max=100"`
+
+	// Continue a truncated replay from the cursor of its output
+	Cursor *ReplayCursor `json:"cursor,omitempty"`
+}
+
+type ReplayRuleOutput struct {
+	// Number of events the rule was evaluated against
+	EventCount int `json:"eventCount"`
+
+	// Number of events the rule matched
+	MatchCount int `json:"matchCount"`
+
+	// Number of events for which the rule raised an error
+	ErrorCount int `json:"errorCount"`
+
+	// Matched events by the hour of their event time
+	MatchesByHour []ReplayHourCount `json:"matchesByHour"`
+
+	// Number of distinct dedup strings, i.e. the number of alerts ignoring the dedup period
+	DedupGroupCount int `json:"dedupGroupCount"`
+
+	// The dedup groups with the most matches
+	TopDedupGroups []ReplayDedupGroup `json:"topDedupGroups"`
+
+	SampleMatches []ReplaySample `json:"sampleMatches"`
+
+	// True if the replay stopped before the end of the time range,
+	// because the event limit or the time budget of the request was reached
+	Truncated bool `json:"truncated"`
+
+	// Position of the next event, to continue a truncated replay
+	Cursor *ReplayCursor `json:"cursor,omitempty"`
+}
+
+// ReplayCursor is the position of an event in the processed data.
+// Events are not ordered by time within or across files, so replays continue from a position instead of a time.
+type ReplayCursor struct {
+	Key  string `json:"key" validate:"required,max=1024"`
+	Line int    `json:"line" validate:"min=0"`
+}
+
+type ReplayHourCount struct {
+	Hour       time.Time `json:"hour"`
+	MatchCount int       `json:"matchCount"`
+}
+
+type ReplayDedupGroup struct {
+	Dedup      string `json:"dedup"`
+	MatchCount int    `json:"matchCount"`
+}
+
+type ReplaySample struct {
+	Event string `json:"event"`
+	Title string `json:"title"`
+	Dedup string `json:"dedup"`
+}
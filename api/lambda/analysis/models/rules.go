@@ -79,8 +79,9 @@ type ListRulesOutput struct {
 }
 
 type TestRuleInput struct {
-	Body     string     `json:"body" validate:"required,max=100000"`
+	Body     string     `json:"body" validate:"required_without=Spec,max=100000"`
 	LogTypes []string   `json:"logTypes" validate:"max=500,dive,required,max=500"`
+	Spec     string     `json:"spec" validate:"max=100000"`
 	Tests    []UnitTest `json:"tests" validate:"max=500,dive"`
 }
 
@@ -111,19 +112,23 @@ type TestRuleRecordFunctions struct {
 }
 
 type UpdateRuleInput struct {
+	AlertGrouping      *AlertGrouping      `json:"alertGrouping"`
 	AnalysisType       DetectionType       `json:"analysisType"`
-	Body               string              `json:"body" validate:"required,max=100000"`
+	Body               string              `json:"body" validate:"required_without_all=Spec ScheduledQuery,max=100000"`
 	DedupPeriodMinutes int                 `json:"dedupPeriodMinutes" validate:"min=0"`
 	Description        string              `json:"description" validate:"max=10000"`
 	DisplayName        string              `json:"displayName" validate:"max=1000,excludesall='<>&\""`
 	Enabled            bool                `json:"enabled"`
+	Exceptions         []RuleException     `json:"exceptions" validate:"max=100,dive"`
 	ID                 string              `json:"id" validate:"required,max=1000,excludesall='<>&\""`
 	LogTypes           []string            `json:"logTypes" validate:"max=500,dive,required,max=500"`
 	OutputIDs          []string            `json:"outputIds" validate:"max=500,dive,required,max=5000"`
 	Reference          string              `json:"reference" validate:"max=10000"`
 	Reports            map[string][]string `json:"reports" validate:"max=500"`
 	Runbook            string              `json:"runbook" validate:"max=10000"`
+	ScheduledQuery     *ScheduledQuery     `json:"scheduledQuery"`
 	Severity           models.Severity     `json:"severity" validate:"oneof=INFO LOW MEDIUM HIGH CRITICAL"`
+	Spec               string              `json:"spec" validate:"max=100000"`
 	Tags               []string            `json:"tags" validate:"max=500,dive,required,max=1000"`
 	Tests              []UnitTest          `json:"tests" validate:"max=500,dive"`
 	Threshold          int                 `json:"threshold" validate:"min=0"`
@@ -131,6 +136,7 @@ type UpdateRuleInput struct {
 }
 
 type Rule struct {
+	AlertGrouping      *AlertGrouping      `json:"alertGrouping"`
 	AnalysisType       DetectionType       `json:"analysisType"`
 	Body               string              `json:"body"`
 	CreatedAt          time.Time           `json:"createdAt"`
@@ -139,6 +145,7 @@ type Rule struct {
 	Description        string              `json:"description"`
 	DisplayName        string              `json:"displayName"`
 	Enabled            bool                `json:"enabled"`
+	Exceptions         []RuleException     `json:"exceptions"`
 	ID                 string              `json:"id"`
 	LastModified       time.Time           `json:"lastModified"`
 	LastModifiedBy     string              `json:"lastModifiedBy"`
@@ -147,9 +154,103 @@ type Rule struct {
 	Reference          string              `json:"reference"`
 	Reports            map[string][]string `json:"reports"`
 	Runbook            string              `json:"runbook"`
+	ScheduledQuery     *ScheduledQuery     `json:"scheduledQuery,omitempty"`
 	Severity           models.Severity     `json:"severity"`
+	Spec               string              `json:"spec"`
 	Tags               []string            `json:"tags"`
 	Tests              []UnitTest          `json:"tests"`
 	Threshold          int                 `json:"threshold"`
 	VersionID          string              `json:"versionId"`
 }
+
+// UpdateInput returns the input to save the rule as it is, for callers changing only some of its settings
+func (rule *Rule) UpdateInput(userID string) *UpdateRuleInput {
+	return &UpdateRuleInput{
+		AlertGrouping:      rule.AlertGrouping,
+		AnalysisType:       rule.AnalysisType,
+		Body:               rule.Body,
+		DedupPeriodMinutes: rule.DedupPeriodMinutes,
+		Description:        rule.Description,
+		DisplayName:        rule.DisplayName,
+		Enabled:            rule.Enabled,
+		Exceptions:         rule.Exceptions,
+		ID:                 rule.ID,
+		LogTypes:           rule.LogTypes,
+		OutputIDs:          rule.OutputIDs,
+		Reference:          rule.Reference,
+		Reports:            rule.Reports,
+		Runbook:            rule.Runbook,
+		ScheduledQuery:     rule.ScheduledQuery,
+		Severity:           rule.Severity,
+		Spec:               rule.Spec,
+		Tags:               rule.Tags,
+		Tests:              rule.Tests,
+		Threshold:          rule.Threshold,
+		UserID:             userID,
+	}
+}
+
+const (
+	AlertGroupingFixed   = "FIXED"
+	AlertGroupingSliding = "SLIDING"
+)
+
+// AlertGrouping controls how the alert forwarder groups the matches of a rule with the same dedup string
+// into alerts. The grouping window is the dedup period of the rule.
+type AlertGrouping struct {
+	// FIXED (default): an alert groups the events of one window, starting at its first event.
+	// SLIDING: an alert stays open as long as events keep matching within the window of the last one.
+	Window string `json:"window" validate:"omitempty,oneof=FIXED SLIDING"`
+
+	// Start a new alert once the alert is this old, even if events are still grouped into it (default: no limit)
+	MaxAlertAgeMinutes int `json:"maxAlertAgeMinutes" validate:"min=0"`
+
+	// Start a new alert once the alert has this many events (default: no limit)
+	MaxEventsPerAlert int64 `json:"maxEventsPerAlert" validate:"min=0"`
+}
+
+// RuleException suppresses the matches of a rule on events with a known-benign field value,
+// without changing the rule code. Exceptions apply before alerts are created.
+type RuleException struct {
+	// Generated if not set
+	ID string `json:"id" validate:"max=1000"`
+
+	// Dot-separated path into the event, list indices are allowed (e.g. "userIdentity.arn", "records.0.ip")
+	Field string `json:"field" validate:"required,max=1000"`
+
+	// How the field is compared to the values, the exception applies if any value matches
+	Operator string   `json:"operator" validate:"oneof=equals contains regex cidr"`
+	Values   []string `json:"values" validate:"min=1,max=500,dive,required,max=1000"`
+
+	// The exception stops applying at this time (default: never expires)
+	ExpiresAt *time.Time `json:"expiresAt"`
+
+	// Why the matches are benign, shown to analysts reviewing the rule
+	Justification string `json:"justification" validate:"required,max=10000"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	CreatedBy string    `json:"createdBy"`
+
+	// Output only: the number of events suppressed by this exception
+	SuppressedCount  int64      `json:"suppressedCount"`
+	LastSuppressedAt *time.Time `json:"lastSuppressedAt"`
+}
+
+// ScheduledQuery turns a rule into a scheduled rule: instead of matching streaming events,
+// the SQL runs in Athena on a cron schedule and every result row generates an alert.
+type ScheduledQuery struct {
+	// SQL may reference the {{start_time}} and {{end_time}} macros for the lookback window
+	SQL string `json:"sql" validate:"required,max=100000"`
+
+	// Standard five field cron expression, evaluated in UTC
+	Schedule string `json:"schedule" validate:"required,max=1000"`
+
+	// Length of the window ending at the scheduled time (default: 60)
+	LookbackMinutes int `json:"lookbackMinutes" validate:"min=0,max=10080"`
+
+	// Maximum number of result rows turned into alerts per run (default: 100)
+	ResultLimit int `json:"resultLimit" validate:"min=0,max=1000"`
+
+	// Result column used as the dedup string (default: all rows of a rule are grouped together)
+	DedupColumn string `json:"dedupColumn" validate:"max=1000"`
+}
@@ -0,0 +1,76 @@
+package models
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import "time"
+
+type ListDetectionVersionsInput struct {
+	ID string `json:"id" validate:"required,max=1000"`
+
+	// Return versions older than this one (from the nextVersionIdMarker of the previous page)
+	VersionIDMarker string `json:"versionIdMarker" validate:"omitempty,len=32"`
+
+	PageSize int `json:"pageSize" validate:"min=0,max=100"` // default: 25
+}
+
+type ListDetectionVersionsOutput struct {
+	// Newest first
+	Versions []DetectionVersion `json:"versions"`
+
+	// Set if there are older versions
+	NextVersionIDMarker string `json:"nextVersionIdMarker,omitempty"`
+}
+
+type DetectionVersion struct {
+	VersionID      string    `json:"versionId"`
+	LastModified   time.Time `json:"lastModified"`
+	LastModifiedBy string    `json:"lastModifiedBy"`
+
+	// The detection was deleted in this version
+	Deleted bool `json:"deleted"`
+
+	// This is the current version of the detection
+	Latest bool `json:"latest"`
+
+	// Fields changed since the previous version (empty for the first version)
+	ChangedFields []string `json:"changedFields"`
+}
+
+type DiffDetectionVersionsInput struct {
+	ID            string `json:"id" validate:"required,max=1000"`
+	FromVersionID string `json:"fromVersionId" validate:"required,len=32"`
+	ToVersionID   string `json:"toVersionId" validate:"omitempty,len=32"` // default: the current version
+}
+
+type DiffDetectionVersionsOutput struct {
+	Fields []DetectionFieldDiff `json:"fields"`
+}
+
+type DetectionFieldDiff struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// Restore a prior version of a detection, which is saved as a new version
+type RevertDetectionInput struct {
+	ID        string `json:"id" validate:"required,max=1000"`
+	VersionID string `json:"versionId" validate:"required,len=32"`
+	UserID    string `json:"userId" validate:"required"`
+}
@@ -47,9 +47,103 @@ type LambdaInput struct {
 	// (hence 'Records' being the name of the field), but genericapi will route the
 	// request to the DispatchAlerts handler. This way all requests can be routed
 	// by genericapi without having to inspect the message ahead of time.
-	DispatchAlerts []*DispatchAlertsInput `json:"Records"`
-	DeliverAlert   *DeliverAlertInput     `json:"deliverAlert"`
-	SendTestAlert  *SendTestAlertInput    `json:"sendTestAlert"`
+	DispatchAlerts           []*DispatchAlertsInput         `json:"Records"`
+	DeliverAlert             *DeliverAlertInput             `json:"deliverAlert"`
+	SendTestAlert            *SendTestAlertInput            `json:"sendTestAlert"`
+	SendEmailDigests         *SendEmailDigestsInput         `json:"sendEmailDigests"`
+	SyncTicketStatuses       *SyncTicketStatusesInput       `json:"syncTicketStatuses"`
+	RouteAlert               *RouteAlertInput               `json:"routeAlert"`
+	FlushAlertBatches        *FlushAlertBatchesInput        `json:"flushAlertBatches"`
+	SendMaintenanceSummaries *SendMaintenanceSummariesInput `json:"sendMaintenanceSummaries"`
+	EscalateAlerts           *EscalateAlertsInput           `json:"escalateAlerts"`
+}
+
+// SendEmailDigestsInput sends the batched alerts of every email output whose digest is due.
+// This is invoked on a schedule.
+//
+// Example:
+// {
+//     "sendEmailDigests": {}
+// }
+type SendEmailDigestsInput struct{}
+
+// FlushAlertBatchesInput sends the batched alerts of every output whose batching window has passed.
+// This is invoked on a schedule.
+//
+// Example:
+// {
+//     "flushAlertBatches": {}
+// }
+type FlushAlertBatchesInput struct{}
+
+// SendMaintenanceSummariesInput delivers the alerts suppressed by maintenance windows which have closed,
+// if they are still open. This is invoked on a schedule.
+//
+// Example:
+// {
+//     "sendMaintenanceSummaries": {}
+// }
+type SendMaintenanceSummariesInput struct{}
+
+// EscalateAlertsInput re-delivers the alerts which are still open to the next steps of their escalation policy.
+// This is invoked on a schedule.
+//
+// Example:
+// {
+//     "escalateAlerts": {}
+// }
+type EscalateAlertsInput struct{}
+
+// SyncTicketStatuses updates the status of open and triaged alerts to match the state of the
+// tickets created for them by ticketing outputs. This is invoked on a schedule.
+//
+// Example:
+// {
+//     "syncTicketStatuses": {}
+// }
+type SyncTicketStatusesInput struct{}
+
+// RouteAlertInput is a dry run of alert routing: it shows where an alert would be delivered
+// without sending anything.
+//
+// Example:
+// {
+//     "routeAlert": {
+//         "alert": {
+//             "analysisId": "AWS.Root.Login",
+//             "type": "RULE",
+//             "createdAt": "2020-07-01T12:00:00Z",
+//             "severity": "HIGH",
+//             "logTypes": ["AWS.CloudTrail"]
+//         },
+//         "time": "2020-07-01T22:00:00Z"
+//     }
+// }
+type RouteAlertInput struct {
+	Alert *Alert `json:"alert" validate:"required"`
+	// The time the routing table is evaluated at (defaults to now)
+	Time *time.Time `json:"time"`
+}
+
+// RouteAlertOutput describes where an alert would be delivered.
+type RouteAlertOutput struct {
+	// The routing rules which matched the alert, in evaluation order
+	MatchedRules []RouteAlertMatch `json:"matchedRules"`
+	// The action of the last matching rule, DEFAULT if no rule matched, SKIP if the detection suppressed delivery
+	// or SUPPRESS if a maintenance window is holding the alert back
+	Action    string   `json:"action"`
+	OutputIDs []string `json:"outputIds"`
+	// The maintenance window which suppresses the alert
+	MaintenanceWindowID string `json:"maintenanceWindowId,omitempty"`
+	// The severity the alert is delivered with, after any escalation
+	Severity     string     `json:"severity"`
+	DeliverAfter *time.Time `json:"deliverAfter,omitempty"`
+}
+
+// RouteAlertMatch is a routing rule which matched an alert
+type RouteAlertMatch struct {
+	RuleID      string `json:"ruleId"`
+	DisplayName string `json:"displayName"`
 }
 
 // SendTestAlertInput sends a dummy alert to the specified destinations
@@ -186,4 +280,20 @@ type Alert struct {
 
 	// IsResent is a flag set to indicate the alert is not new
 	IsResent bool `json:"isResent,omitempty"`
+
+	// IsEscalated is set when an escalation policy re-delivers the alert because it is still open
+	IsEscalated bool `json:"isEscalated,omitempty"`
+
+	// DeliverAfter is set when a routing rule delays the alert. The alert is then held in the queue until
+	// this time and delivered to its OutputIds.
+	DeliverAfter *time.Time `json:"deliverAfter,omitempty"`
+
+	// RateLimited is set when the alert was held back by the rate limit of its output. The alert already
+	// holds a reserved slot, so it is not counted against the rate limit again.
+	RateLimited bool `json:"rateLimited,omitempty"`
+
+	// SuppressedBy is the maintenance window which held the alert back. The alert is kept until the
+	// window closes at SuppressedUntil, and delivered with a summary then if it is still open.
+	SuppressedBy    string     `json:"suppressedBy,omitempty"`
+	SuppressedUntil *time.Time `json:"suppressedUntil,omitempty"`
 }
@@ -36,6 +36,8 @@ type GetMetricsInput struct {
 	FromDate        time.Time `json:"fromDate" validate:"required"`
 	ToDate          time.Time `json:"toDate" validate:"required,gtfield=FromDate"`
 	IntervalMinutes int64     `json:"intervalMinutes" validate:"required,gt=0"`
+	// Restricts the per-rule metrics to these rules, by default all rules which reported them are included
+	RuleIDs []string `json:"ruleIds" validate:"max=100,dive,required,max=1000"`
 }
 
 // GetMetricsOutput contains data points for a number of metrics over the specified time frame
@@ -45,6 +47,10 @@ type GetMetricsOutput struct {
 	TotalAlertsDelta *MetricResult `json:"totalAlertsDelta,omitempty"`
 	AlertsBySeverity *MetricResult `json:"alertsBySeverity,omitempty"`
 	AlertsByRuleID   *MetricResult `json:"alertsByRuleID,omitempty"`
+	RuleEvaluations  *MetricResult `json:"ruleEvaluations,omitempty"`
+	RuleErrors       *MetricResult `json:"ruleErrors,omitempty"`
+	RuleMatches      *MetricResult `json:"ruleMatches,omitempty"`
+	RuleLatency      *MetricResult `json:"ruleLatency,omitempty"`
 	FromDate         time.Time     `json:"fromDate"`
 	ToDate           time.Time     `json:"toDate"`
 	IntervalMinutes  int64         `json:"intervalMinutes"`
@@ -18,16 +18,34 @@ package models
  * along with this program.  If not, see <https://www.gnu.org/licenses/>.
  */
 
+import (
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+)
+
 // LambdaInput is the invocation event expected by the Lambda function.
 //
 // Exactly one action must be specified.
 type LambdaInput struct {
-	AddOutput             *AddOutputInput             `json:"addOutput"`
-	UpdateOutput          *UpdateOutputInput          `json:"updateOutput"`
-	GetOutput             *GetOutputInput             `json:"getOutput"`
-	DeleteOutput          *DeleteOutputInput          `json:"deleteOutput"`
-	GetOutputs            *GetOutputsInput            `json:"getOutputs"`
-	GetOutputsWithSecrets *GetOutputsWithSecretsInput `json:"getOutputsWithSecrets"`
+	AddOutput               *AddOutputInput               `json:"addOutput"`
+	UpdateOutput            *UpdateOutputInput            `json:"updateOutput"`
+	GetOutput               *GetOutputInput               `json:"getOutput"`
+	DeleteOutput            *DeleteOutputInput            `json:"deleteOutput"`
+	GetOutputs              *GetOutputsInput              `json:"getOutputs"`
+	GetOutputsWithSecrets   *GetOutputsWithSecretsInput   `json:"getOutputsWithSecrets"`
+	RenderOutputTemplate    *RenderOutputTemplateInput    `json:"renderOutputTemplate"`
+	ListRoutingRules        *ListRoutingRulesInput        `json:"listRoutingRules"`
+	PutRoutingRule          *PutRoutingRuleInput          `json:"putRoutingRule"`
+	DeleteRoutingRule       *DeleteRoutingRuleInput       `json:"deleteRoutingRule"`
+	GetOutputsHealth        *GetOutputsHealthInput        `json:"getOutputsHealth"`
+	ListMaintenanceWindows  *ListMaintenanceWindowsInput  `json:"listMaintenanceWindows"`
+	PutMaintenanceWindow    *PutMaintenanceWindowInput    `json:"putMaintenanceWindow"`
+	DeleteMaintenanceWindow *DeleteMaintenanceWindowInput `json:"deleteMaintenanceWindow"`
+	ListEscalationPolicies  *ListEscalationPoliciesInput  `json:"listEscalationPolicies"`
+	PutEscalationPolicy     *PutEscalationPolicyInput     `json:"putEscalationPolicy"`
+	DeleteEscalationPolicy  *DeleteEscalationPolicyInput  `json:"deleteEscalationPolicy"`
+	ListOnCallSchedules     *ListOnCallSchedulesInput     `json:"listOnCallSchedules"`
+	PutOnCallSchedule       *PutOnCallScheduleInput       `json:"putOnCallSchedule"`
+	DeleteOnCallSchedule    *DeleteOnCallScheduleInput    `json:"deleteOnCallSchedule"`
 }
 
 // AddOutputInput adds a new encrypted alert output to DynamoDB.
@@ -43,11 +61,12 @@ type LambdaInput struct {
 //     }
 // }
 type AddOutputInput struct {
-	UserID             *string       `json:"userId" validate:"required,uuid4"`
-	DisplayName        *string       `json:"displayName" validate:"required,min=1,excludesall='<>&\""`
-	OutputConfig       *OutputConfig `json:"outputConfig" validate:"required"`
-	DefaultForSeverity []*string     `json:"defaultForSeverity"`
-	AlertTypes         []string      `json:"alertTypes" validate:"omitempty,dive,oneof=RULE RULE_ERROR POLICY"`
+	UserID             *string         `json:"userId" validate:"required,uuid4"`
+	DisplayName        *string         `json:"displayName" validate:"required,min=1,excludesall='<>&\""`
+	OutputConfig       *OutputConfig   `json:"outputConfig" validate:"required"`
+	DefaultForSeverity []*string       `json:"defaultForSeverity"`
+	AlertTypes         []string        `json:"alertTypes" validate:"omitempty,dive,oneof=RULE RULE_ERROR POLICY"`
+	DeliveryPolicy     *DeliveryPolicy `json:"deliveryPolicy"`
 }
 
 // AddOutputOutput returns a randomly generated UUID for the output.
@@ -84,12 +103,13 @@ type DeleteOutputInput struct {
 //     }
 // }
 type UpdateOutputInput struct {
-	UserID             *string       `json:"userId" validate:"required,uuid4"`
-	DisplayName        *string       `json:"displayName" validate:"omitempty,min=1,excludesall='<>&\""`
-	OutputID           *string       `json:"outputId" validate:"required,uuid4"`
-	OutputConfig       *OutputConfig `json:"outputConfig"`
-	DefaultForSeverity []*string     `json:"defaultForSeverity"`
-	AlertTypes         []string      `json:"alertTypes" validate:"omitempty,dive,oneof=RULE RULE_ERROR POLICY"`
+	UserID             *string         `json:"userId" validate:"required,uuid4"`
+	DisplayName        *string         `json:"displayName" validate:"omitempty,min=1,excludesall='<>&\""`
+	OutputID           *string         `json:"outputId" validate:"required,uuid4"`
+	OutputConfig       *OutputConfig   `json:"outputConfig"`
+	DefaultForSeverity []*string       `json:"defaultForSeverity"`
+	AlertTypes         []string        `json:"alertTypes" validate:"omitempty,dive,oneof=RULE RULE_ERROR POLICY"`
+	DeliveryPolicy     *DeliveryPolicy `json:"deliveryPolicy"`
 }
 
 // UpdateOutputOutput returns the new updated output
@@ -135,6 +155,26 @@ type GetOutputsWithSecretsInput struct {
 // }
 type GetOutputsOutput = []*AlertOutput
 
+// RenderOutputTemplateInput renders a payload template against an alert without sending anything.
+//
+// If no alert is given, a sample alert is used.
+//
+// Example:
+// {
+//     "renderOutputTemplate": {
+//         "template": "{{.Title}} ({{.Severity}}): {{context .AlertContext \"host.name\"}}"
+//     }
+// }
+type RenderOutputTemplateInput struct {
+	Template string               `json:"template" validate:"required,max=20000"`
+	Alert    *deliverymodel.Alert `json:"alert"`
+}
+
+// RenderOutputTemplateOutput is the rendered payload
+type RenderOutputTemplateOutput struct {
+	Body string `json:"body"`
+}
+
 // AlertOutput contains the information for alert output configuration
 type AlertOutput struct {
 	// AlertTypes is a whitelist of alert types to send to this destination.
@@ -168,9 +208,15 @@ type AlertOutput struct {
 
 	// DefaultForSeverity defines the alert severities that will be forwarded through this output
 	DefaultForSeverity []*string `json:"defaultForSeverity"`
+
+	// DeliveryPolicy limits the rate of deliveries to this output, batches them or stops them while it is failing
+	DeliveryPolicy *DeliveryPolicy `json:"deliveryPolicy,omitempty"`
 }
 
 // OutputConfig contains the configuration for the output
+//
+// The credential fields can reference a secret in AWS Secrets Manager or SSM Parameter Store by its ARN
+// instead of holding the value itself, see SecretReference.
 type OutputConfig struct {
 	// SlackConfig contains the configuration for Slack alert output
 	Slack *SlackConfig `json:"slack,omitempty"`
@@ -201,11 +247,31 @@ type OutputConfig struct {
 
 	// CustomWebhook contains the configuration for a Custom Webhook alert output
 	CustomWebhook *CustomWebhookConfig `json:"customWebhook,omitempty"`
+
+	// EmailConfig contains the configuration for Email alert output
+	Email *EmailConfig `json:"email,omitempty"`
+
+	// ServiceNowConfig contains the configuration for ServiceNow alert output
+	ServiceNow *ServiceNowConfig `json:"serviceNow,omitempty"`
+
+	// ZendeskConfig contains the configuration for Zendesk alert output
+	Zendesk *ZendeskConfig `json:"zendesk,omitempty"`
+
+	// SplunkConfig contains the configuration for Splunk HTTP Event Collector alert output
+	Splunk *SplunkConfig `json:"splunk,omitempty"`
+
+	// ElasticsearchConfig contains the configuration for Elasticsearch/OpenSearch alert output
+	Elasticsearch *ElasticsearchConfig `json:"elasticsearch,omitempty"`
+
+	// DatadogConfig contains the configuration for Datadog Events alert output
+	Datadog *DatadogConfig `json:"datadog,omitempty"`
 }
 
 // SlackConfig defines options for each Slack output.
 type SlackConfig struct {
-	WebhookURL string `json:"webhookURL" validate:"omitempty,url"` // https://hooks.slack.com/services/...
+	WebhookURL string `json:"webhookURL" validate:"omitempty,secretRef|url"` // https://hooks.slack.com/services/...
+	// Optional Go template rendering the JSON message payload, replaces the default message
+	MessageTemplate string `json:"messageTemplate" validate:"max=20000"`
 }
 
 // SnsConfig defines options for each SNS topic output
@@ -215,7 +281,7 @@ type SnsConfig struct {
 
 // PagerDutyConfig defines options for each PagerDuty output
 type PagerDutyConfig struct {
-	IntegrationKey string `json:"integrationKey" validate:"omitempty,hexadecimal,len=32"`
+	IntegrationKey string `json:"integrationKey" validate:"omitempty,secretRef|hexadecimal,secretRef|len=32"`
 }
 
 // GithubConfig defines options for each Github output
@@ -243,7 +309,9 @@ type OpsgenieConfig struct {
 
 // MsTeamsConfig defines options for each MsTeams output
 type MsTeamsConfig struct {
-	WebhookURL string `json:"webhookURL" validate:"omitempty,url"`
+	WebhookURL string `json:"webhookURL" validate:"omitempty,secretRef|url"`
+	// Optional Go template rendering the JSON message card, replaces the default card
+	MessageTemplate string `json:"messageTemplate" validate:"max=20000"`
 }
 
 // SqsConfig defines options for each Sqs topic output
@@ -259,5 +327,83 @@ type AsanaConfig struct {
 
 // CustomWebhookConfig defines options for each CustomWebhook output
 type CustomWebhookConfig struct {
-	WebhookURL string `json:"webhookURL" validate:"omitempty,url"`
+	WebhookURL string `json:"webhookURL" validate:"omitempty,secretRef|url"`
+	// The HTTP method, defaults to POST
+	Method  string            `json:"method" validate:"omitempty,oneof=POST PUT PATCH"`
+	Headers map[string]string `json:"headers,omitempty" validate:"max=20"`
+	// Optional Go template rendering the request body, replaces the default JSON notification
+	PayloadTemplate string `json:"payloadTemplate" validate:"max=20000"`
+
+	// AuthType is one of BASIC, BEARER (AuthSecret is the token) or HMAC (AuthSecret signs the body)
+	AuthType     string `json:"authType" validate:"omitempty,oneof=BASIC BEARER HMAC"`
+	AuthUsername string `json:"authUsername"`
+	AuthSecret   string `json:"authSecret"`
+	// The header carrying the HMAC signature (default X-Panther-Signature)
+	SignatureHeader string `json:"signatureHeader"`
+}
+
+// ServiceNowConfig defines options for each ServiceNow output
+type ServiceNowConfig struct {
+	InstanceURL     string `json:"instanceUrl" validate:"omitempty,url"` // https://<instance>.service-now.com
+	UserName        string `json:"userName"`
+	Password        string `json:"password"`
+	AssignmentGroup string `json:"assignmentGroup"`
+}
+
+// ZendeskConfig defines options for each Zendesk output
+type ZendeskConfig struct {
+	OrgDomain string `json:"orgDomain" validate:"omitempty,url"` // https://<subdomain>.zendesk.com
+	UserEmail string `json:"userEmail" validate:"omitempty,email"`
+	APIToken  string `json:"apiToken"`
+	GroupID   int64  `json:"groupId" validate:"min=0"`
+}
+
+// SplunkConfig defines options for each Splunk HTTP Event Collector output
+type SplunkConfig struct {
+	HecURL     string `json:"hecUrl" validate:"omitempty,url"` // https://<host>:8088
+	Token      string `json:"token"`
+	Index      string `json:"index"`
+	Source     string `json:"source"`
+	SourceType string `json:"sourceType"` // defaults to panther:alert
+}
+
+// ElasticsearchConfig defines options for each Elasticsearch or OpenSearch output
+type ElasticsearchConfig struct {
+	Endpoint string `json:"endpoint" validate:"omitempty,url"` // https://<host>:9200
+	Index    string `json:"index"`
+	// Either basic auth credentials or an API key (base64 encoded "id:api_key") can be used
+	UserName string `json:"userName"`
+	Password string `json:"password"`
+	APIKey   string `json:"apiKey"`
+}
+
+// DatadogConfig defines options for each Datadog Events output
+type DatadogConfig struct {
+	APIKey string `json:"apiKey"`
+	// The Datadog site, e.g. datadoghq.eu (defaults to datadoghq.com)
+	Site string   `json:"site" validate:"omitempty,hostname"`
+	Tags []string `json:"tags" validate:"omitempty,max=20,dive,required"`
+}
+
+// EmailConfig defines options for each Email output
+type EmailConfig struct {
+	// Transport is how the email is sent, either through an SMTP server or Amazon SES
+	Transport   string   `json:"transport" validate:"oneof=SMTP SES"`
+	FromAddress string   `json:"fromAddress" validate:"omitempty,email"`
+	Recipients  []string `json:"recipients" validate:"omitempty,max=50,dive,email"`
+
+	// SMTP transport only. The port defaults to 587, port 465 uses implicit TLS (SMTPS).
+	SMTPHost     string `json:"smtpHost" validate:"omitempty,hostname"`
+	SMTPPort     int    `json:"smtpPort" validate:"min=0,max=65535"`
+	SMTPUsername string `json:"smtpUsername"`
+	SMTPPassword string `json:"smtpPassword"`
+	SMTPStartTLS bool   `json:"smtpStartTls"`
+
+	// SES transport only. Defaults to the region Panther is deployed in.
+	SESRegion string `json:"sesRegion"`
+
+	// Alerts with one of these severities are batched into a periodic digest email instead of being sent one by one
+	DigestSeverities []string `json:"digestSeverities" validate:"omitempty,dive,oneof=INFO LOW MEDIUM HIGH CRITICAL"`
+	// How often the digest is sent (default 60 minutes)
+	DigestIntervalMinutes int `json:"digestIntervalMinutes" validate:"min=0,max=1440"`
 }
@@ -0,0 +1,177 @@
+package models
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+)
+
+// ListEscalationPoliciesInput lists the escalation policies, ordered by display name.
+//
+// Example:
+// {
+//     "listEscalationPolicies": {}
+// }
+type ListEscalationPoliciesInput struct {
+}
+
+// ListEscalationPoliciesOutput is the list of escalation policies
+type ListEscalationPoliciesOutput = []*EscalationPolicy
+
+// PutEscalationPolicyInput creates an escalation policy, or replaces it if the policyId is given.
+//
+// Example:
+// {
+//     "putEscalationPolicy": {
+//         "userId": "9d1c5854-f3ea-491c-8a52-0aa0d58cb456",
+//         "displayName": "Critical alerts",
+//         "enabled": true,
+//         "match": {
+//             "severities": ["HIGH", "CRITICAL"]
+//         },
+//         "steps": [
+//             {
+//                 "afterMinutes": 15,
+//                 "scheduleIds": ["0dd5ec36-43b5-4bb3-a4d4-7a1e6d7a2cf5"]
+//             },
+//             {
+//                 "afterMinutes": 60,
+//                 "outputIds": ["6b3ccd37-0dbd-4a3d-b5ab-b2ea4a2a3b01"]
+//             }
+//         ]
+//     }
+// }
+type PutEscalationPolicyInput struct {
+	UserID      string           `json:"userId" validate:"required,uuid4"`
+	PolicyID    string           `json:"policyId" validate:"omitempty,uuid4"`
+	DisplayName string           `json:"displayName" validate:"required,min=1,max=200"`
+	Enabled     bool             `json:"enabled"`
+	Match       EscalationMatch  `json:"match"`
+	Steps       []EscalationStep `json:"steps" validate:"min=1,max=10,dive"`
+}
+
+// PutEscalationPolicyOutput is the stored escalation policy
+type PutEscalationPolicyOutput = EscalationPolicy
+
+// DeleteEscalationPolicyInput removes an escalation policy.
+type DeleteEscalationPolicyInput struct {
+	PolicyID string `json:"policyId" validate:"required,uuid4"`
+}
+
+// EscalationPolicy re-delivers alerts which are still open to further destinations as time passes.
+//
+// Only the first enabled policy (by display name) matching an alert applies to it.
+type EscalationPolicy struct {
+	PolicyID         string           `json:"policyId"`
+	DisplayName      string           `json:"displayName"`
+	Enabled          bool             `json:"enabled"`
+	Match            EscalationMatch  `json:"match"`
+	Steps            []EscalationStep `json:"steps"`
+	CreatedBy        string           `json:"createdBy"`
+	CreationTime     time.Time        `json:"creationTime"`
+	LastModifiedBy   string           `json:"lastModifiedBy"`
+	LastModifiedTime time.Time        `json:"lastModifiedTime"`
+}
+
+// EscalationMatch holds the conditions of an escalation policy. Empty conditions match every alert.
+type EscalationMatch struct {
+	// IDs of the rules or policies of the alert
+	AnalysisIDs []string `json:"analysisIds,omitempty" validate:"omitempty,dive,required"`
+	Severities  []string `json:"severities,omitempty" validate:"omitempty,dive,oneof=INFO LOW MEDIUM HIGH CRITICAL"`
+	// The alert needs at least one of these log types
+	LogTypes []string `json:"logTypes,omitempty" validate:"omitempty,dive,required"`
+}
+
+// EscalationStep is a delivery made once an alert has been open for some time.
+type EscalationStep struct {
+	// Minutes since the alert was created, steps are in increasing order
+	AfterMinutes int      `json:"afterMinutes" validate:"min=1,max=10080"`
+	OutputIDs    []string `json:"outputIds,omitempty" validate:"omitempty,dive,uuid4"`
+	// The user on call for each schedule is emailed
+	ScheduleIDs []string `json:"scheduleIds,omitempty" validate:"omitempty,dive,uuid4"`
+}
+
+// ListOnCallSchedulesInput lists the on-call schedules, ordered by display name.
+//
+// Example:
+// {
+//     "listOnCallSchedules": {}
+// }
+type ListOnCallSchedulesInput struct {
+}
+
+// ListOnCallSchedulesOutput is the list of on-call schedules
+type ListOnCallSchedulesOutput = []*OnCallSchedule
+
+// PutOnCallScheduleInput creates an on-call schedule, or replaces it if the scheduleId is given.
+//
+// Example:
+// {
+//     "putOnCallSchedule": {
+//         "userId": "9d1c5854-f3ea-491c-8a52-0aa0d58cb456",
+//         "displayName": "Security on-call",
+//         "outputId": "9a1ad3ac-9ec9-4e3a-a4b8-ea6c1bd3a1e1",
+//         "rotationStart": "2020-07-06T09:00:00Z",
+//         "shiftHours": 168,
+//         "userIds": ["9d1c5854-f3ea-491c-8a52-0aa0d58cb456", "2f8a8c4e-65f4-4c50-9b0c-1f0d8a4b2a77"]
+//     }
+// }
+type PutOnCallScheduleInput struct {
+	UserID      string `json:"userId" validate:"required,uuid4"`
+	ScheduleID  string `json:"scheduleId" validate:"omitempty,uuid4"`
+	DisplayName string `json:"displayName" validate:"required,min=1,max=200"`
+	// The email destination used to notify the user on call
+	OutputID      string    `json:"outputId" validate:"required,uuid4"`
+	RotationStart time.Time `json:"rotationStart" validate:"required"`
+	ShiftHours    int       `json:"shiftHours" validate:"min=1,max=8760"`
+	// Panther users, in rotation order
+	UserIDs []string `json:"userIds" validate:"min=1,dive,uuid4"`
+}
+
+// PutOnCallScheduleOutput is the stored on-call schedule
+type PutOnCallScheduleOutput = OnCallSchedule
+
+// DeleteOnCallScheduleInput removes an on-call schedule.
+type DeleteOnCallScheduleInput struct {
+	ScheduleID string `json:"scheduleId" validate:"required,uuid4"`
+}
+
+// OnCallSchedule is a simple rotation of Panther users: the first user is on call from the start of
+// the rotation, and hands over to the next one every shiftHours.
+type OnCallSchedule struct {
+	ScheduleID       string    `json:"scheduleId"`
+	DisplayName      string    `json:"displayName"`
+	OutputID         string    `json:"outputId"`
+	RotationStart    time.Time `json:"rotationStart"`
+	ShiftHours       int       `json:"shiftHours"`
+	UserIDs          []string  `json:"userIds"`
+	CreatedBy        string    `json:"createdBy"`
+	CreationTime     time.Time `json:"creationTime"`
+	LastModifiedBy   string    `json:"lastModifiedBy"`
+	LastModifiedTime time.Time `json:"lastModifiedTime"`
+}
+
+// OnCall returns the ID of the user on call at time t, or an empty string if the rotation has not started.
+func (schedule *OnCallSchedule) OnCall(t time.Time) string {
+	if len(schedule.UserIDs) == 0 || schedule.ShiftHours <= 0 || t.Before(schedule.RotationStart) {
+		return ""
+	}
+	shifts := int64(t.Sub(schedule.RotationStart) / (time.Duration(schedule.ShiftHours) * time.Hour))
+	return schedule.UserIDs[shifts%int64(len(schedule.UserIDs))]
+}
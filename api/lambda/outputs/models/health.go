@@ -0,0 +1,76 @@
+package models
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+)
+
+// Circuit breaker states of an output
+const (
+	// Deliveries go through
+	CircuitClosed = "CLOSED"
+	// Deliveries are parked until the circuit is tried again
+	CircuitOpen = "OPEN"
+	// A single trial delivery is let through to check if the output recovered
+	CircuitHalfOpen = "HALF_OPEN"
+)
+
+// DeliveryPolicy controls how alerts are delivered to an output during an alert storm or an outage.
+//
+// All settings are optional, a zero value disables them.
+type DeliveryPolicy struct {
+	// The most alerts delivered per minute, excess alerts are held back in the queue
+	RateLimitPerMinute int `json:"rateLimitPerMinute" validate:"min=0,max=10000"`
+	// Alerts arriving within this window are summarized in a single message (Slack and Microsoft Teams only)
+	BatchWindowMinutes int `json:"batchWindowMinutes" validate:"min=0,max=1440"`
+	// The number of consecutive failed deliveries which opens the circuit of the output
+	CircuitBreakerThreshold int `json:"circuitBreakerThreshold" validate:"min=0,max=100"`
+	// How long deliveries are parked once the circuit opens (defaults to 5 minutes)
+	CircuitBreakerCooldownMinutes int `json:"circuitBreakerCooldownMinutes" validate:"min=0,max=60"`
+}
+
+// GetOutputsHealthInput fetches the delivery health of the outputs with a circuit breaker.
+//
+// Example:
+// {
+//     "getOutputsHealth": {
+//         "outputIds": ["7d1c5854-f3ea-491c-8a52-0aa0d58cb456"]
+//     }
+// }
+type GetOutputsHealthInput struct {
+	// Only return the health of these outputs (defaults to all outputs)
+	OutputIDs []string `json:"outputIds" validate:"omitempty,dive,uuid4"`
+}
+
+// GetOutputsHealthOutput is the delivery health of each output
+type GetOutputsHealthOutput = []*OutputHealth
+
+// OutputHealth is the circuit breaker state of an output, kept up to date by alert delivery.
+type OutputHealth struct {
+	OutputID     string `json:"outputId"`
+	CircuitState string `json:"circuitState"`
+	// Failed deliveries since the last successful one
+	ConsecutiveFailures int `json:"consecutiveFailures"`
+	// Deliveries are parked until this time while the circuit is open
+	OpenUntil       *time.Time `json:"openUntil,omitempty"`
+	LastError       string     `json:"lastError,omitempty"`
+	LastFailureTime *time.Time `json:"lastFailureTime,omitempty"`
+	LastSuccessTime *time.Time `json:"lastSuccessTime,omitempty"`
+}
@@ -0,0 +1,135 @@
+package models
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+)
+
+// Maintenance window recurrences
+const (
+	RecurrenceNone   = "NONE"
+	RecurrenceDaily  = "DAILY"
+	RecurrenceWeekly = "WEEKLY"
+)
+
+// ListMaintenanceWindowsInput lists the maintenance windows, ordered by start time.
+//
+// Example:
+// {
+//     "listMaintenanceWindows": {}
+// }
+type ListMaintenanceWindowsInput struct {
+}
+
+// ListMaintenanceWindowsOutput is the list of maintenance windows
+type ListMaintenanceWindowsOutput = []*MaintenanceWindow
+
+// PutMaintenanceWindowInput creates a maintenance window, or replaces it if the windowId is given.
+//
+// Example:
+// {
+//     "putMaintenanceWindow": {
+//         "userId": "9d1c5854-f3ea-491c-8a52-0aa0d58cb456",
+//         "displayName": "Weekly database patching",
+//         "enabled": true,
+//         "startTime": "2020-07-04T22:00:00Z",
+//         "endTime": "2020-07-05T02:00:00Z",
+//         "recurrence": "WEEKLY",
+//         "match": {
+//             "logTypes": ["AWS.RDS"]
+//         }
+//     }
+// }
+type PutMaintenanceWindowInput struct {
+	UserID      string    `json:"userId" validate:"required,uuid4"`
+	WindowID    string    `json:"windowId" validate:"omitempty,uuid4"`
+	DisplayName string    `json:"displayName" validate:"required,min=1,max=200"`
+	Enabled     bool      `json:"enabled"`
+	StartTime   time.Time `json:"startTime" validate:"required"`
+	EndTime     time.Time `json:"endTime" validate:"required"`
+	// Recurring windows repeat every day or week from their first occurrence, until recurUntil if given
+	Recurrence string           `json:"recurrence" validate:"oneof=NONE DAILY WEEKLY"`
+	RecurUntil *time.Time       `json:"recurUntil,omitempty"`
+	Match      MaintenanceMatch `json:"match"`
+}
+
+// PutMaintenanceWindowOutput is the stored maintenance window
+type PutMaintenanceWindowOutput = MaintenanceWindow
+
+// DeleteMaintenanceWindowInput removes a maintenance window.
+type DeleteMaintenanceWindowInput struct {
+	WindowID string `json:"windowId" validate:"required,uuid4"`
+}
+
+// MaintenanceWindow suppresses the delivery of matching alerts while it is active.
+//
+// Suppressed alerts are kept, and the ones which are still open when the window closes
+// are delivered as a summary to their destinations.
+type MaintenanceWindow struct {
+	WindowID         string           `json:"windowId"`
+	DisplayName      string           `json:"displayName"`
+	Enabled          bool             `json:"enabled"`
+	StartTime        time.Time        `json:"startTime"`
+	EndTime          time.Time        `json:"endTime"`
+	Recurrence       string           `json:"recurrence"`
+	RecurUntil       *time.Time       `json:"recurUntil,omitempty"`
+	Match            MaintenanceMatch `json:"match"`
+	CreatedBy        string           `json:"createdBy"`
+	CreationTime     time.Time        `json:"creationTime"`
+	LastModifiedBy   string           `json:"lastModifiedBy"`
+	LastModifiedTime time.Time        `json:"lastModifiedTime"`
+}
+
+// MaintenanceMatch holds the conditions of a maintenance window. Empty conditions match every alert.
+type MaintenanceMatch struct {
+	// IDs of the rules or policies of the alert
+	AnalysisIDs []string `json:"analysisIds,omitempty" validate:"omitempty,dive,required"`
+	Severities  []string `json:"severities,omitempty" validate:"omitempty,dive,oneof=INFO LOW MEDIUM HIGH CRITICAL"`
+	// The alert needs at least one of these log types, and at least one of these tags
+	LogTypes []string `json:"logTypes,omitempty" validate:"omitempty,dive,required"`
+	Tags     []string `json:"tags,omitempty" validate:"omitempty,dive,required"`
+}
+
+// Occurrence returns the start and end of the occurrence of the window which includes t, if any.
+func (window *MaintenanceWindow) Occurrence(t time.Time) (start, end time.Time, ok bool) {
+	start, end = window.StartTime, window.EndTime
+	if t.Before(start) {
+		return start, end, false
+	}
+
+	var period time.Duration
+	switch window.Recurrence {
+	case RecurrenceDaily:
+		period = 24 * time.Hour
+	case RecurrenceWeekly:
+		period = 7 * 24 * time.Hour
+	default:
+		return start, end, t.Before(end)
+	}
+
+	// The latest occurrence which started before t
+	occurrences := int64(t.Sub(start) / period)
+	start = start.Add(time.Duration(occurrences) * period)
+	end = end.Add(time.Duration(occurrences) * period)
+	if window.RecurUntil != nil && start.After(*window.RecurUntil) {
+		return start, end, false
+	}
+	return start, end, t.Before(end)
+}
@@ -0,0 +1,146 @@
+package models
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+)
+
+// Routing rule actions
+const (
+	RoutingActionSend     = "SEND"
+	RoutingActionDrop     = "DROP"
+	RoutingActionDelay    = "DELAY"
+	RoutingActionEscalate = "ESCALATE"
+)
+
+// ListRoutingRulesInput lists the alert routing rules in evaluation order.
+//
+// Example:
+// {
+//     "listRoutingRules": {}
+// }
+type ListRoutingRulesInput struct {
+}
+
+// ListRoutingRulesOutput is the ordered routing table
+type ListRoutingRulesOutput = []*RoutingRule
+
+// PutRoutingRuleInput creates a routing rule, or replaces it if the ruleId is given.
+//
+// Example:
+// {
+//     "putRoutingRule": {
+//         "userId": "9d1c5854-f3ea-491c-8a52-0aa0d58cb456",
+//         "displayName": "Critical AWS alerts to the SOC",
+//         "order": 10,
+//         "enabled": true,
+//         "match": {
+//             "severities": ["CRITICAL"],
+//             "logTypes": ["AWS.CloudTrail"]
+//         },
+//         "action": {
+//             "type": "SEND",
+//             "outputIds": ["7d1c5854-f3ea-491c-8a52-0aa0d58cb456"]
+//         }
+//     }
+// }
+type PutRoutingRuleInput struct {
+	UserID      string        `json:"userId" validate:"required,uuid4"`
+	RuleID      string        `json:"ruleId" validate:"omitempty,uuid4"`
+	DisplayName string        `json:"displayName" validate:"required,min=1,max=200"`
+	Order       int           `json:"order" validate:"min=0"`
+	Enabled     bool          `json:"enabled"`
+	Match       RoutingMatch  `json:"match"`
+	Action      RoutingAction `json:"action"`
+}
+
+// PutRoutingRuleOutput is the stored routing rule
+type PutRoutingRuleOutput = RoutingRule
+
+// DeleteRoutingRuleInput removes a routing rule.
+type DeleteRoutingRuleInput struct {
+	RuleID string `json:"ruleId" validate:"required,uuid4"`
+}
+
+// RoutingRule decides where alerts matching all of its conditions are delivered.
+//
+// Enabled rules are evaluated in ascending order and the first matching rule wins, unless it is a SEND
+// rule set to continue evaluation. Alerts not matched by any rule use the default destinations:
+// dynamic destinations, then detection overrides, then the severity defaults of the outputs.
+type RoutingRule struct {
+	RuleID           string        `json:"ruleId"`
+	DisplayName      string        `json:"displayName"`
+	Order            int           `json:"order"`
+	Enabled          bool          `json:"enabled"`
+	Match            RoutingMatch  `json:"match"`
+	Action           RoutingAction `json:"action"`
+	CreatedBy        string        `json:"createdBy"`
+	CreationTime     time.Time     `json:"creationTime"`
+	LastModifiedBy   string        `json:"lastModifiedBy"`
+	LastModifiedTime time.Time     `json:"lastModifiedTime"`
+}
+
+// RoutingMatch holds the conditions of a routing rule. Empty conditions match every alert.
+type RoutingMatch struct {
+	Severities []string `json:"severities,omitempty" validate:"omitempty,dive,oneof=INFO LOW MEDIUM HIGH CRITICAL"`
+	AlertTypes []string `json:"alertTypes,omitempty" validate:"omitempty,dive,oneof=RULE RULE_ERROR POLICY"`
+	// The alert needs at least one of these log types, and at least one of these tags
+	LogTypes []string `json:"logTypes,omitempty" validate:"omitempty,dive,required"`
+	Tags     []string `json:"tags,omitempty" validate:"omitempty,dive,required"`
+	// Regular expression matched against the rule or policy ID
+	AnalysisIDPattern string `json:"analysisIdPattern,omitempty" validate:"max=1000"`
+	// All of the alert context conditions must hold
+	Context    []RoutingContextCondition `json:"context,omitempty" validate:"max=20,dive"`
+	TimeWindow *RoutingTimeWindow        `json:"timeWindow,omitempty"`
+}
+
+// RoutingContextCondition compares a value in the alert context.
+type RoutingContextCondition struct {
+	// Dot-separated path in the alert context, e.g. "user.department"
+	Path string `json:"path" validate:"required"`
+	// CONTAINS checks substrings of strings and elements of lists, MATCHES is a regular expression
+	Operator string `json:"operator" validate:"oneof=EQUALS NOT_EQUALS CONTAINS MATCHES EXISTS"`
+	Value    string `json:"value"`
+}
+
+// RoutingTimeWindow matches alerts routed within a daily time range.
+//
+// A window which ends before it starts spans midnight, e.g. 18:00 to 08:00.
+type RoutingTimeWindow struct {
+	Days      []string `json:"days,omitempty" validate:"omitempty,dive,oneof=MON TUE WED THU FRI SAT SUN"`
+	StartTime string   `json:"startTime" validate:"required,len=5"` // HH:MM
+	EndTime   string   `json:"endTime" validate:"required,len=5"`   // HH:MM
+	// IANA time zone, e.g. America/New_York (defaults to UTC)
+	Timezone string `json:"timezone"`
+}
+
+// RoutingAction is what happens to an alert matching a routing rule.
+type RoutingAction struct {
+	Type string `json:"type" validate:"oneof=SEND DROP DELAY ESCALATE"`
+	// Destinations of the alert. If empty, the default destinations are used.
+	// DROP stops evaluation, only the destinations added by earlier SEND rules are kept.
+	OutputIDs []string `json:"outputIds,omitempty" validate:"omitempty,max=50,dive,uuid4"`
+	// DELAY holds the alert back for this long before delivering it
+	DelayMinutes int `json:"delayMinutes" validate:"min=0,max=1440"`
+	// SEND only: keep evaluating the rules which follow and add their destinations
+	Continue bool `json:"continue"`
+	// ESCALATE raises the severity of the alert to this value (defaults to one level up)
+	Severity string `json:"severity,omitempty" validate:"omitempty,oneof=INFO LOW MEDIUM HIGH CRITICAL"`
+}
@@ -0,0 +1,142 @@
+package models
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+)
+
+// Services storing the secrets an output config field can reference
+const (
+	SecretsManagerService = "secretsmanager"
+	ParameterStoreService = "ssm"
+)
+
+// SecretNamePrefix starts the name of every secret and parameter Panther is allowed to read
+const SecretNamePrefix = "panther/"
+
+// SecretReference is an output config value read from AWS Secrets Manager or SSM Parameter Store at delivery time.
+//
+// The credential fields of an output config (see SecretFields) can hold the ARN of a secret instead of the value
+// itself. The secret has to be in the account and region of Panther, and its name has to start with "panther/":
+//     arn:aws:secretsmanager:us-west-2:123456789012:secret:panther/jira-api-key-AbCdEf
+//     arn:aws:ssm:us-west-2:123456789012:parameter/panther/github-token
+//
+// A key of a secret holding a JSON object is selected with a "#" suffix:
+//     arn:aws:secretsmanager:us-west-2:123456789012:secret:panther/asana-AbCdEf#personalAccessToken
+type SecretReference struct {
+	// The ARN of the secret or parameter, without the JSON key
+	ARN     string
+	Service string
+	Region  string
+	// The key to read from a JSON secret (Secrets Manager only)
+	JSONKey string
+}
+
+// ParseSecretReference returns the secret referenced by an output config value, or false if the value is not
+// a reference to a secret of the given account and region.
+func ParseSecretReference(value, accountID, region string) (*SecretReference, bool) {
+	if !strings.HasPrefix(value, "arn:") {
+		return nil, false
+	}
+	parsed, err := arn.Parse(value)
+	if err != nil || parsed.Region != region || parsed.AccountID != accountID || region == "" || accountID == "" {
+		return nil, false
+	}
+
+	ref := &SecretReference{ARN: value, Service: parsed.Service, Region: parsed.Region}
+	switch parsed.Service {
+	case SecretsManagerService:
+		if !strings.HasPrefix(parsed.Resource, "secret:"+SecretNamePrefix) {
+			return nil, false
+		}
+		if i := strings.LastIndex(value, "#"); i >= 0 {
+			ref.ARN, ref.JSONKey = value[:i], value[i+1:]
+			if ref.JSONKey == "" {
+				return nil, false
+			}
+		}
+	case ParameterStoreService:
+		if !strings.HasPrefix(parsed.Resource, "parameter/"+SecretNamePrefix) {
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+	return ref, true
+}
+
+// IsSecretReference returns true if an output config value is the ARN of a secret or parameter of the given
+// account and region.
+func IsSecretReference(value, accountID, region string) bool {
+	_, ok := ParseSecretReference(value, accountID, region)
+	return ok
+}
+
+// SecretFields returns the credential fields of an output config, the only ones which can reference a secret.
+//
+// The same fields are redacted when the output is returned to a user.
+func (config *OutputConfig) SecretFields() []*string {
+	var fields []*string
+	if config.Slack != nil {
+		fields = append(fields, &config.Slack.WebhookURL)
+	}
+	if config.PagerDuty != nil {
+		fields = append(fields, &config.PagerDuty.IntegrationKey)
+	}
+	if config.Github != nil {
+		fields = append(fields, &config.Github.Token)
+	}
+	if config.Jira != nil {
+		fields = append(fields, &config.Jira.APIKey)
+	}
+	if config.Opsgenie != nil {
+		fields = append(fields, &config.Opsgenie.APIKey)
+	}
+	if config.MsTeams != nil {
+		fields = append(fields, &config.MsTeams.WebhookURL)
+	}
+	if config.Asana != nil {
+		fields = append(fields, &config.Asana.PersonalAccessToken)
+	}
+	if config.CustomWebhook != nil {
+		fields = append(fields, &config.CustomWebhook.WebhookURL, &config.CustomWebhook.AuthSecret)
+	}
+	if config.Email != nil {
+		fields = append(fields, &config.Email.SMTPPassword)
+	}
+	if config.ServiceNow != nil {
+		fields = append(fields, &config.ServiceNow.Password)
+	}
+	if config.Zendesk != nil {
+		fields = append(fields, &config.Zendesk.APIToken)
+	}
+	if config.Splunk != nil {
+		fields = append(fields, &config.Splunk.Token)
+	}
+	if config.Elasticsearch != nil {
+		fields = append(fields, &config.Elasticsearch.Password, &config.Elasticsearch.APIKey)
+	}
+	if config.Datadog != nil {
+		fields = append(fields, &config.Datadog.APIKey)
+	}
+	return fields
+}
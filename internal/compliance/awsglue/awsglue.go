@@ -50,14 +50,21 @@ var (
 	}
 )
 
-func CreateOrUpdateResourcesTable(glueClient glueiface.GlueAPI, locationARN string) error {
-	// FIXME: Remove when the DDB connector is GA
+// DDBConnectorSupported returns true if Athena can query the DynamoDB table at locationARN
+func DDBConnectorSupported(locationARN string) (bool, error) {
 	parsedARN, err := arn.Parse(locationARN)
 	if err != nil {
-		return err
+		return false, err
 	}
-	if _, found := anthenaDDBConnectorRegions[parsedARN.Region]; !found {
-		return nil // not supported
+	_, found := anthenaDDBConnectorRegions[parsedARN.Region]
+	return found, nil
+}
+
+func CreateOrUpdateResourcesTable(glueClient glueiface.GlueAPI, locationARN string) error {
+	// FIXME: Remove when the DDB connector is GA
+	supported, err := DDBConnectorSupported(locationARN)
+	if err != nil || !supported {
+		return err
 	}
 
 	tableInput := &glue.TableInput{
@@ -155,13 +162,10 @@ func CreateOrUpdateResourcesTable(glueClient glueiface.GlueAPI, locationARN stri
 
 func CreateOrUpdateComplianceTable(glueClient glueiface.GlueAPI, locationARN string) error {
 	// FIXME: Remove when the DDB connector is GA
-	parsedARN, err := arn.Parse(locationARN)
-	if err != nil {
+	supported, err := DDBConnectorSupported(locationARN)
+	if err != nil || !supported {
 		return err
 	}
-	if _, found := anthenaDDBConnectorRegions[parsedARN.Region]; !found {
-		return nil // not supported
-	}
 
 	tableInput := &glue.TableInput{
 		Name:        aws.String(ComplianceTable),
@@ -0,0 +1,111 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
+)
+
+// supportsBatching returns true if the output can summarize a batch of alerts in a single message
+func supportsBatching(output *outputModels.AlertOutput) bool {
+	switch *output.OutputType {
+	case "slack", "msteams":
+		return true
+	default:
+		return false
+	}
+}
+
+// queueBatchAlert stores an alert until the next batch of the output is sent
+func queueBatchAlert(alert *deliverymodel.Alert, outputID string) DispatchStatus {
+	response := queueAlert(env.AlertBatchTableName, alert, outputID, "batch")
+	return DispatchStatus{
+		Alert:        *alert,
+		OutputID:     outputID,
+		StatusCode:   response.StatusCode,
+		Success:      response.Success,
+		Message:      response.Message,
+		NeedsRetry:   !response.Success && !response.Permanent,
+		DispatchedAt: time.Now().UTC(),
+	}
+}
+
+// FlushAlertBatches sends the queued alerts of every output whose batching window has passed.
+//
+// Outputs which turned batching off since their alerts were queued are flushed right away.
+func (API) FlushAlertBatches(ctx context.Context, input *deliverymodel.FlushAlertBatchesInput) (interface{}, error) {
+	alertOutputs, err := getOutputs()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	var result error
+	for _, output := range alertOutputs {
+		if !supportsBatching(output) {
+			continue
+		}
+		if err := flushAlertBatch(ctx, output, now); err != nil {
+			zap.L().Error("failed to send alert batch", zap.Stringp("outputID", output.OutputID), zap.Error(err))
+			result = multierr.Append(result, err)
+		}
+	}
+	return nil, result
+}
+
+// flushAlertBatch sends one message summarizing the queued alerts of an output once its batching window has passed
+func flushAlertBatch(ctx context.Context, output *outputModels.AlertOutput, now time.Time) error {
+	// Without a batching window the queued alerts are due now
+	var window time.Duration
+	if output.DeliveryPolicy != nil {
+		window = time.Duration(output.DeliveryPolicy.BatchWindowMinutes) * time.Minute
+	}
+	return sendQueuedAlerts(env.AlertBatchTableName, *output.OutputID, window, now,
+		func(alerts []*deliverymodel.Alert) *outputs.AlertDeliveryResponse {
+			return sendAlertSummary(ctx, output, alerts)
+		})
+}
+
+// sendAlertSummary sends one message summarizing several alerts. Returns nil if the output does not support it.
+func sendAlertSummary(ctx context.Context, output *outputModels.AlertOutput, alerts []*deliverymodel.Alert) *outputs.AlertDeliveryResponse {
+	output, err := outputClient.ResolveSecrets(ctx, output)
+	if err != nil {
+		zap.L().Warn("failed to resolve output secrets", zap.Error(err))
+		return &outputs.AlertDeliveryResponse{StatusCode: 500, Message: "failed to resolve output secrets"}
+	}
+
+	switch *output.OutputType {
+	case "slack":
+		return outputClient.SlackBatch(ctx, alerts, output.OutputConfig.Slack)
+	case "msteams":
+		return outputClient.MsTeamsBatch(ctx, alerts, output.OutputConfig.MsTeams)
+	case "email":
+		return outputClient.EmailDigest(ctx, alerts, output.OutputConfig.Email)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,126 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+func genBatchOutput() *outputModels.AlertOutput {
+	output := genAlertOutput()
+	output.DeliveryPolicy = &outputModels.DeliveryPolicy{BatchWindowMinutes: 10}
+	return output
+}
+
+func TestThrottleDeliveriesBatch(t *testing.T) {
+	mockDynamo := &testutils.DynamoDBMock{}
+	dynamoClient = mockDynamo
+	env.AlertBatchTableName = "batches"
+	mockDynamo.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once()
+
+	alert := sampleAlert()
+	allowed, queued := throttleDeliveries(AlertOutputMap{alert: {genBatchOutput()}})
+	assert.Equal(t, AlertOutputMap{alert: {}}, allowed)
+	require.Len(t, queued, 1)
+	assert.Equal(t, 202, queued[0].StatusCode)
+	assert.True(t, queued[0].Success)
+	assert.Equal(t, "alert queued for the next batch", queued[0].Message)
+	mockDynamo.AssertExpectations(t)
+
+	input := mockDynamo.Calls[0].Arguments.Get(0).(*dynamodb.PutItemInput)
+	assert.Equal(t, "batches", *input.TableName)
+}
+
+func TestFlushAlertBatches(t *testing.T) {
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	mockDynamo := &testutils.DynamoDBMock{}
+	dynamoClient = mockDynamo
+	env.AlertBatchTableName = "batches"
+	output := genBatchOutput()
+	defer func() { outputsCache = nil }()
+	outputsCache = &alertOutputsCache{
+		Outputs:         []*outputModels.AlertOutput{output, genEmailOutput()},
+		Expiry:          time.Now().UTC(),
+		RefreshInterval: time.Hour,
+	}
+
+	alert := sampleAlert()
+	alert.CreatedAt = time.Now().UTC().Add(-15 * time.Minute)
+	mockDynamo.On("Query", mock.Anything).Return(&dynamodb.QueryOutput{
+		Items: []map[string]*dynamodb.AttributeValue{digestItemAttributes(t, alert)},
+	}, nil).Once()
+	ctx := context.Background()
+	mockClient.On("SlackBatch", ctx, mock.Anything, output.OutputConfig.Slack).
+		Return(&outputs.AlertDeliveryResponse{StatusCode: 200, Success: true}).Once()
+	mockDynamo.On("BatchWriteItem", mock.Anything).Return(&dynamodb.BatchWriteItemOutput{}, nil).Once()
+
+	_, err := API{}.FlushAlertBatches(ctx, &deliverymodel.FlushAlertBatchesInput{})
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+	mockDynamo.AssertExpectations(t)
+
+	query := mockDynamo.Calls[0].Arguments.Get(0).(*dynamodb.QueryInput)
+	assert.Equal(t, "batches", *query.TableName)
+	batchInput := mockDynamo.Calls[1].Arguments.Get(0).(*dynamodb.BatchWriteItemInput)
+	assert.Len(t, batchInput.RequestItems["batches"], 1)
+}
+
+func TestFlushAlertBatchesPolicyRemoved(t *testing.T) {
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	mockDynamo := &testutils.DynamoDBMock{}
+	dynamoClient = mockDynamo
+	env.AlertBatchTableName = "batches"
+	// The alert was queued before the delivery policy of the output was removed
+	output := genAlertOutput()
+	defer func() { outputsCache = nil }()
+	outputsCache = &alertOutputsCache{
+		Outputs:         []*outputModels.AlertOutput{output},
+		Expiry:          time.Now().UTC(),
+		RefreshInterval: time.Hour,
+	}
+
+	alert := sampleAlert()
+	alert.CreatedAt = time.Now().UTC()
+	mockDynamo.On("Query", mock.Anything).Return(&dynamodb.QueryOutput{
+		Items: []map[string]*dynamodb.AttributeValue{digestItemAttributes(t, alert)},
+	}, nil).Once()
+	ctx := context.Background()
+	mockClient.On("SlackBatch", ctx, mock.Anything, output.OutputConfig.Slack).
+		Return(&outputs.AlertDeliveryResponse{StatusCode: 200, Success: true}).Once()
+	mockDynamo.On("BatchWriteItem", mock.Anything).Return(&dynamodb.BatchWriteItemOutput{}, nil).Once()
+
+	_, err := API{}.FlushAlertBatches(ctx, &deliverymodel.FlushAlertBatchesInput{})
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+	mockDynamo.AssertExpectations(t)
+}
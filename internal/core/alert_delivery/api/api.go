@@ -23,6 +23,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 	"github.com/aws/aws-sdk-go/service/lambda"
 	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
 	"github.com/aws/aws-sdk-go/service/sqs"
@@ -38,16 +39,24 @@ import (
 type API struct{}
 
 type envConfig struct {
-	AlertRetryCount        int           `required:"true" split_words:"true"`
-	OutputsRefreshInterval time.Duration `required:"true" split_words:"true"`
-	MinRetryDelaySecs      int           `required:"true" split_words:"true"`
-	MaxRetryDelaySecs      int           `required:"true" split_words:"true"`
-	AlertsTableName        string        `required:"true" split_words:"true"`
-	RuleIndexName          string        `required:"true" split_words:"true"`
-	TimeIndexName          string        `required:"true" split_words:"true"`
-	AlertQueueURL          string        `required:"true" split_words:"true"`
-	AlertsAPI              string        `required:"true" split_words:"true"`
-	OutputsAPI             string        `required:"true" split_words:"true"`
+	AlertRetryCount           int           `required:"true" split_words:"true"`
+	OutputsRefreshInterval    time.Duration `required:"true" split_words:"true"`
+	MinRetryDelaySecs         int           `required:"true" split_words:"true"`
+	MaxRetryDelaySecs         int           `required:"true" split_words:"true"`
+	AlertsTableName           string        `required:"true" split_words:"true"`
+	RuleIndexName             string        `required:"true" split_words:"true"`
+	TimeIndexName             string        `required:"true" split_words:"true"`
+	AlertQueueURL             string        `required:"true" split_words:"true"`
+	AlertsAPI                 string        `required:"true" split_words:"true"`
+	OutputsAPI                string        `required:"true" split_words:"true"`
+	EmailDigestTableName      string        `required:"true" split_words:"true"`
+	AlertBatchTableName       string        `required:"true" split_words:"true"`
+	OutputHealthTableName     string        `required:"true" split_words:"true"`
+	SuppressedAlertsTableName string        `required:"true" split_words:"true"`
+	EscalationTableName       string        `required:"true" split_words:"true"`
+	JobCursorsTableName       string        `required:"true" split_words:"true"`
+	UsersAPI                  string        `required:"true" split_words:"true"`
+	AccountID                 string        `required:"true" split_words:"true"`
 }
 
 // Globals
@@ -55,6 +64,7 @@ var (
 	env                  envConfig
 	awsSession           *session.Session
 	alertsTableClient    *alertTable.AlertsTable
+	dynamoClient         dynamodbiface.DynamoDBAPI
 	lambdaClient         lambdaiface.LambdaAPI
 	outputClient         outputs.API
 	sqsClient            sqsiface.SQSAPI
@@ -68,14 +78,15 @@ func Setup() {
 	envconfig.MustProcess("", &env)
 	awsSession = session.Must(session.NewSession())
 	lambdaClient = lambda.New(awsSession)
-	outputClient = outputs.New(awsSession)
+	outputClient = outputs.New(awsSession, env.AccountID)
 	sqsClient = sqs.New(awsSession)
+	dynamoClient = dynamodb.New(awsSession)
 	outputsCache = &alertOutputsCache{
 		RefreshInterval: env.OutputsRefreshInterval,
 	}
 	alertsTableClient = &alertTable.AlertsTable{
 		AlertsTableName:                    env.AlertsTableName,
-		Client:                             dynamodb.New(awsSession),
+		Client:                             dynamoClient,
 		RuleIDCreationTimeIndexName:        env.RuleIndexName,
 		TimePartitionCreationTimeIndexName: env.TimeIndexName,
 	}
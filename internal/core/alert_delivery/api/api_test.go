@@ -33,6 +33,8 @@ import (
 type mockOutputsClient struct {
 	outputs.API
 	mock.Mock
+	// Returned by ResolveSecrets, which otherwise passes the outputs through unchanged
+	resolveSecretsErr error
 }
 
 func (m *mockOutputsClient) Slack(
@@ -45,6 +47,57 @@ func (m *mockOutputsClient) Slack(
 	return args.Get(0).(*outputs.AlertDeliveryResponse)
 }
 
+func (m *mockOutputsClient) Email(
+	ctx context.Context,
+	alert *deliverymodel.Alert,
+	config *outputModels.EmailConfig,
+) *outputs.AlertDeliveryResponse {
+
+	args := m.Called(ctx, alert, config)
+	return args.Get(0).(*outputs.AlertDeliveryResponse)
+}
+
+func (m *mockOutputsClient) EmailDigest(
+	ctx context.Context,
+	alerts []*deliverymodel.Alert,
+	config *outputModels.EmailConfig,
+) *outputs.AlertDeliveryResponse {
+
+	args := m.Called(ctx, alerts, config)
+	return args.Get(0).(*outputs.AlertDeliveryResponse)
+}
+
+func (m *mockOutputsClient) SlackBatch(
+	ctx context.Context,
+	alerts []*deliverymodel.Alert,
+	config *outputModels.SlackConfig,
+) *outputs.AlertDeliveryResponse {
+
+	args := m.Called(ctx, alerts, config)
+	return args.Get(0).(*outputs.AlertDeliveryResponse)
+}
+
+func (m *mockOutputsClient) TicketStatus(
+	ctx context.Context,
+	output *outputModels.AlertOutput,
+	ticketID string,
+) (string, error) {
+
+	args := m.Called(ctx, output, ticketID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockOutputsClient) ResolveSecrets(
+	_ context.Context,
+	output *outputModels.AlertOutput,
+) (*outputModels.AlertOutput, error) {
+
+	if m.resolveSecretsErr != nil {
+		return nil, m.resolveSecretsErr
+	}
+	return output, nil
+}
+
 func sampleAlert() *deliverymodel.Alert {
 	return &deliverymodel.Alert{
 		AlertID:      aws.String("alert-id"),
@@ -30,6 +30,12 @@ type alertOutputsCache struct {
 	Outputs         []*outputModels.AlertOutput
 	Expiry          time.Time
 	RefreshInterval time.Duration
+	// The routing table, refreshed on its own as only dispatched alerts are routed
+	RoutingRules       []*outputModels.RoutingRule
+	RoutingRulesExpiry time.Time
+	// The maintenance windows, refreshed along with the routing table
+	MaintenanceWindows       []*outputModels.MaintenanceWindow
+	MaintenanceWindowsExpiry time.Time
 }
 
 // get - Gets a pointer to the outputsCache singleton
@@ -76,3 +82,43 @@ func (c *alertOutputsCache) setRefreshInterval(duration time.Duration) {
 func (c *alertOutputsCache) isExpired() bool {
 	return time.Since(c.getExpiry()) > c.getRefreshInterval()
 }
+
+// getRoutingRules - Gets the routing rules stored in the cache
+func (c *alertOutputsCache) getRoutingRules() []*outputModels.RoutingRule {
+	return c.get().RoutingRules
+}
+
+// setRoutingRules - Stores the routing rules in the cache
+func (c *alertOutputsCache) setRoutingRules(rules []*outputModels.RoutingRule) {
+	c.get().RoutingRules = rules
+}
+
+// setRoutingRulesExpiry - Sets the expiry time of the cached routing rules
+func (c *alertOutputsCache) setRoutingRulesExpiry(time time.Time) {
+	c.get().RoutingRulesExpiry = time
+}
+
+// isRoutingRulesExpired - determines if the cached routing rules have expired
+func (c *alertOutputsCache) isRoutingRulesExpired() bool {
+	return time.Since(c.get().RoutingRulesExpiry) > c.getRefreshInterval()
+}
+
+// getMaintenanceWindows - Gets the maintenance windows stored in the cache
+func (c *alertOutputsCache) getMaintenanceWindows() []*outputModels.MaintenanceWindow {
+	return c.get().MaintenanceWindows
+}
+
+// setMaintenanceWindows - Stores the maintenance windows in the cache
+func (c *alertOutputsCache) setMaintenanceWindows(windows []*outputModels.MaintenanceWindow) {
+	c.get().MaintenanceWindows = windows
+}
+
+// setMaintenanceWindowsExpiry - Sets the expiry time of the cached maintenance windows
+func (c *alertOutputsCache) setMaintenanceWindowsExpiry(time time.Time) {
+	c.get().MaintenanceWindowsExpiry = time
+}
+
+// isMaintenanceWindowsExpired - determines if the cached maintenance windows have expired
+func (c *alertOutputsCache) isMaintenanceWindowsExpired() bool {
+	return time.Since(c.get().MaintenanceWindowsExpiry) > c.getRefreshInterval()
+}
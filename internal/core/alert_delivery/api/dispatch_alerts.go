@@ -20,6 +20,7 @@ package api
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-playground/validator"
 	jsoniter "github.com/json-iterator/go"
@@ -39,14 +40,22 @@ func (API) DispatchAlerts(ctx context.Context, input []*deliverymodel.DispatchAl
 	zap.L().Debug("Extracted from input", zap.Any("alerts", alerts))
 
 	// Get our Alert -> Output mappings. We determine which destinations an alert should be sent.
-	alertOutputMap, err := getAlertOutputMap(alerts)
+	alertOutputMap, suppressedStatuses, err := getAlertOutputMap(alerts)
 	if err != nil {
 		return nil, err
 	}
 
+	// Hold back or batch deliveries according to the delivery policy of each output
+	alertOutputMap, queuedStatuses := throttleDeliveries(alertOutputMap)
+
 	// Send alerts to the specified destination(s) and obtain each response status
 	dispatchStatuses := sendAlerts(ctx, alertOutputMap, outputClient)
 
+	// Open or close the circuit of outputs based on the outcome of their deliveries
+	recordOutputHealth(alertOutputMap, dispatchStatuses)
+	dispatchStatuses = append(dispatchStatuses, queuedStatuses...)
+	dispatchStatuses = append(dispatchStatuses, suppressedStatuses...)
+
 	// Record the delivery statuses to ddb. Ignore the returned output.
 	updateAlerts(dispatchStatuses)
 	zap.L().Debug("Finished updating alert delivery statuses")
@@ -85,20 +94,49 @@ func getAlerts(input []*deliverymodel.DispatchAlertsInput) []*deliverymodel.Aler
 }
 
 // getAlertOutputMap - maps a list of alerts to their specified destinations or defaults
-func getAlertOutputMap(alerts []*deliverymodel.Alert) (AlertOutputMap, error) {
+//
+// Alerts delayed by a routing rule are put back on the queue instead, and alerts suppressed by a
+// maintenance window are kept until the window closes. The statuses of the suppressed deliveries are returned.
+func getAlertOutputMap(alerts []*deliverymodel.Alert) (AlertOutputMap, []DispatchStatus, error) {
 	// Create our Alert -> Output mappings
 	alertOutputMap := make(AlertOutputMap)
+	suppressedStatuses := []DispatchStatus{}
+	delayedAlerts := []*deliverymodel.Alert{}
+
 	for _, alert := range alerts {
+		if isDelayed(alert) {
+			delayedAlerts = append(delayedAlerts, alert)
+			continue
+		}
+
 		// We get a list of outputs depending on several dynamic factors
 		outputs, err := getAlertOutputs(alert)
 		if err != nil {
-			return alertOutputMap, errors.Wrapf(err, "Failed to fetch outputIds")
+			return alertOutputMap, suppressedStatuses, errors.Wrapf(err, "Failed to fetch outputIds")
+		}
+		if isDelayed(alert) {
+			delayedAlerts = append(delayedAlerts, alert)
+			continue
+		}
+		if alert.SuppressedBy != "" {
+			if err := suppressAlert(alert); err != nil {
+				return alertOutputMap, suppressedStatuses, errors.Wrapf(err, "Failed to suppress alert")
+			}
+			suppressedStatuses = append(suppressedStatuses, getSuppressedStatuses(alert, outputs)...)
+			continue
 		}
 
 		// Finally, assign the alert to the set of unique outputs
 		alertOutputMap[alert] = outputs
 	}
-	return alertOutputMap, nil
+	// On errors the whole batch is redelivered by SQS, so delayed alerts are only put back on success
+	delay(delayedAlerts, env.AlertQueueURL)
+	return alertOutputMap, suppressedStatuses, nil
+}
+
+// isDelayed - checks if a routing rule holds back the alert
+func isDelayed(alert *deliverymodel.Alert) bool {
+	return alert.DeliverAfter != nil && time.Now().Before(*alert.DeliverAfter)
 }
 
 // filterDispatches - returns a tuple (success, failed) of lists containing dispatch statuses
@@ -182,6 +220,8 @@ func getAlertsToRetry(failedDispatchStatuses []DispatchStatus, maximumRetryCount
 		mutatedAlert.RetryCount++
 		// Overwrite the list of outputs with the output that failed
 		mutatedAlert.OutputIds = []string{failed.OutputID}
+		// The slot reserved in the rate limit was used by the failed delivery
+		mutatedAlert.RateLimited = false
 		// Add the alert in question to a new list to be retried
 		alertsToRetry = append(alertsToRetry, &mutatedAlert)
 	}
@@ -144,8 +144,11 @@ func TestGetAlertOutputMap(t *testing.T) {
 	outputsCache = &alertOutputsCache{
 		RefreshInterval: time.Second * time.Duration(30),
 		Expiry:          time.Now().Add(time.Minute * time.Duration(-5)),
+		// No routing rules
+		RoutingRulesExpiry:       time.Now(),
+		MaintenanceWindowsExpiry: time.Now(),
 	}
-	result, err := getAlertOutputMap(alerts)
+	result, _, err := getAlertOutputMap(alerts)
 	require.NoError(t, err)
 
 	assert.Equal(t, len(expectedResult[alerts[0]]), len(result[alerts[0]]))
@@ -189,6 +192,13 @@ func TestGetAlertOutputMapError(t *testing.T) {
 
 	mockClient.On("Invoke", mock.Anything).Return((*lambda.InvokeOutput)(nil), errors.New("error")).Once()
 
+	// The whole batch is redelivered after an error, so a delayed alert must not be put back on the queue as well
+	mockSQS := &testutils.SqsMock{}
+	sqsClient = mockSQS
+	delayed := *alerts[0]
+	delayed.DeliverAfter = aws.Time(time.Now().Add(time.Hour))
+	alerts = append([]*deliverymodel.Alert{&delayed}, alerts...)
+
 	// AlertOutputMap map[*deliverymodel.Alert][]*outputModels.AlertOutput
 	expectedResult := AlertOutputMap{}
 
@@ -196,11 +206,15 @@ func TestGetAlertOutputMapError(t *testing.T) {
 	outputsCache = &alertOutputsCache{
 		RefreshInterval: time.Second * time.Duration(30),
 		Expiry:          time.Now().Add(time.Minute * time.Duration(-5)),
+		// No routing rules
+		RoutingRulesExpiry:       time.Now(),
+		MaintenanceWindowsExpiry: time.Now(),
 	}
-	result, err := getAlertOutputMap(alerts)
+	result, _, err := getAlertOutputMap(alerts)
 	require.Error(t, err)
 
 	assert.Equal(t, expectedResult, result)
+	mockSQS.AssertNotCalled(t, "SendMessageBatch", mock.Anything)
 }
 
 func TestFilterDispatches(t *testing.T) {
@@ -254,7 +268,7 @@ func TestGetAlertsToRetry(t *testing.T) {
 	createdAt := time.Now().UTC()
 	dispatchedAt := time.Now().UTC()
 	alerts := []*deliverymodel.Alert{
-		// Needs to be retried
+		// Needs to be retried, the retry goes through the rate limit again
 		{
 			AlertID:             alertID,
 			AnalysisDescription: "A test alert",
@@ -269,6 +283,7 @@ func TestGetAlertsToRetry(t *testing.T) {
 			Severity:            "INFO",
 			CreatedAt:           createdAt,
 			Version:             aws.String("abc"),
+			RateLimited:         true,
 		},
 		// Should be ignored because it has exceeded the max retry count
 		{
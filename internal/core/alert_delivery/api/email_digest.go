@@ -0,0 +1,260 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
+	"github.com/panther-labs/panther/pkg/awsbatch/dynamodbbatch"
+)
+
+const (
+	defaultDigestIntervalMinutes = 60
+	// The most alerts sent in a single digest or batch, the rest are left for the next one
+	maxDigestAlerts = 500
+	// Queued alerts expire if they could not be sent for this long, e.g. because the output was deleted
+	digestItemTTL    = 7 * 24 * time.Hour
+	maxDigestBackoff = time.Minute
+	// Fixed width, so the keys sort chronologically
+	digestKeyTimeLayout = "2006-01-02T15:04:05.000000000Z"
+)
+
+// digestItem is an alert waiting to be sent with the next digest of an email output or the next batch of an output
+type digestItem struct {
+	OutputID string `json:"outputId"`
+	// Prefixed with the alert creation time, so alerts are queried in chronological order
+	AlertKey  string `json:"alertKey"`
+	Alert     string `json:"alert"`
+	ExpiresAt int64  `json:"expiresAt"`
+}
+
+// isDigestAlert returns true if the alert should be batched into the digest of an email output
+func isDigestAlert(alert *deliverymodel.Alert, config *outputModels.EmailConfig) bool {
+	// Test alerts, alerts re-sent by a user and escalations are expected to arrive right away
+	if alert.IsTest || alert.IsResent || alert.IsEscalated {
+		return false
+	}
+	for _, severity := range config.DigestSeverities {
+		if alert.Severity == severity {
+			return true
+		}
+	}
+	return false
+}
+
+// queueDigestAlert stores an alert until the next digest of an email output is sent
+func queueDigestAlert(alert *deliverymodel.Alert, outputID string) *outputs.AlertDeliveryResponse {
+	return queueAlert(env.EmailDigestTableName, alert, outputID, "email digest")
+}
+
+// queueAlert stores an alert in a queue table until it is sent along with the other alerts queued for the output
+func queueAlert(tableName string, alert *deliverymodel.Alert, outputID, queueName string) *outputs.AlertDeliveryResponse {
+	serializedAlert, err := jsoniter.MarshalToString(alert)
+	if err != nil {
+		zap.L().Error("failed to serialize queued alert", zap.Error(err))
+		return &outputs.AlertDeliveryResponse{
+			StatusCode: 500,
+			Message:    "failed to serialize queued alert",
+			Permanent:  true,
+			Success:    false,
+		}
+	}
+
+	item, err := dynamodbattribute.MarshalMap(&digestItem{
+		OutputID: outputID,
+		// Retries of the same alert overwrite the same item
+		AlertKey:  alert.CreatedAt.UTC().Format(digestKeyTimeLayout) + "#" + aws.StringValue(alert.AlertID),
+		Alert:     serializedAlert,
+		ExpiresAt: time.Now().Add(digestItemTTL).Unix(),
+	})
+	if err != nil {
+		zap.L().Error("failed to marshal queued alert item", zap.Error(err))
+		return &outputs.AlertDeliveryResponse{
+			StatusCode: 500,
+			Message:    "failed to marshal queued alert item",
+			Permanent:  true,
+			Success:    false,
+		}
+	}
+
+	_, err = dynamoClient.PutItem(&dynamodb.PutItemInput{
+		TableName: &tableName,
+		Item:      item,
+	})
+	if err != nil {
+		zap.L().Warn("failed to queue alert", zap.String("queue", queueName), zap.Error(err))
+		return &outputs.AlertDeliveryResponse{
+			StatusCode: 500,
+			Message:    "failed to queue alert for the " + queueName,
+			Permanent:  false,
+			Success:    false,
+		}
+	}
+	return &outputs.AlertDeliveryResponse{
+		StatusCode: 202,
+		Message:    "alert queued for the next " + queueName,
+		Permanent:  false,
+		Success:    true,
+	}
+}
+
+// SendEmailDigests sends the queued alerts of every email output whose digest is due.
+func (API) SendEmailDigests(ctx context.Context, input *deliverymodel.SendEmailDigestsInput) (interface{}, error) {
+	alertOutputs, err := getOutputs()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	var result error
+	for _, output := range alertOutputs {
+		if output.OutputConfig == nil || output.OutputConfig.Email == nil {
+			continue
+		}
+		if err := sendEmailDigest(ctx, output, now); err != nil {
+			zap.L().Error("failed to send email digest", zap.Stringp("outputID", output.OutputID), zap.Error(err))
+			result = multierr.Append(result, err)
+		}
+	}
+	return nil, result
+}
+
+// sendEmailDigest sends one digest email once the oldest queued alert of the output is older than its interval
+func sendEmailDigest(ctx context.Context, output *outputModels.AlertOutput, now time.Time) error {
+	config := output.OutputConfig.Email
+	interval := time.Duration(config.DigestIntervalMinutes) * time.Minute
+	if interval == 0 {
+		interval = defaultDigestIntervalMinutes * time.Minute
+	}
+
+	return sendQueuedAlerts(env.EmailDigestTableName, *output.OutputID, interval, now,
+		func(alerts []*deliverymodel.Alert) *outputs.AlertDeliveryResponse {
+			resolved, err := outputClient.ResolveSecrets(ctx, output)
+			if err != nil {
+				zap.L().Warn("failed to resolve output secrets", zap.Error(err))
+				return &outputs.AlertDeliveryResponse{StatusCode: 500, Message: "failed to resolve output secrets"}
+			}
+			return outputClient.EmailDigest(ctx, alerts, resolved.OutputConfig.Email)
+		})
+}
+
+// sendQueuedAlerts sends the queued alerts of an output with a single call once the oldest one is older than
+// the interval, and removes them from the queue table.
+func sendQueuedAlerts(
+	tableName, outputID string,
+	interval time.Duration,
+	now time.Time,
+	send func([]*deliverymodel.Alert) *outputs.AlertDeliveryResponse,
+) error {
+
+	items, err := queryDigestItems(tableName, outputID)
+	if err != nil || len(items) == 0 {
+		return err
+	}
+
+	alerts := make([]*deliverymodel.Alert, 0, len(items))
+	for _, item := range items {
+		alert := &deliverymodel.Alert{}
+		if err := jsoniter.UnmarshalFromString(item.Alert, alert); err != nil {
+			// Skip it, the item is deleted along with the rest of the queued alerts
+			zap.L().Error("failed to unmarshal queued alert", zap.String("alertKey", item.AlertKey), zap.Error(err))
+			continue
+		}
+		alerts = append(alerts, alert)
+	}
+	if len(alerts) == 0 || now.Sub(alerts[0].CreatedAt) < interval {
+		return nil
+	}
+
+	response := send(alerts)
+	if response == nil || !response.Success {
+		if response == nil {
+			return errors.New("queued alerts response is nil")
+		}
+		return errors.Errorf("failed to send queued alerts: %s", response.Message)
+	}
+	zap.L().Info("sent queued alerts",
+		zap.String("table", tableName), zap.String("outputID", outputID), zap.Int("numAlerts", len(alerts)))
+
+	deleteRequests := make([]*dynamodb.WriteRequest, 0, len(items))
+	for _, item := range items {
+		deleteRequests = append(deleteRequests, &dynamodb.WriteRequest{
+			DeleteRequest: &dynamodb.DeleteRequest{
+				Key: map[string]*dynamodb.AttributeValue{
+					"outputId": {S: aws.String(item.OutputID)},
+					"alertKey": {S: aws.String(item.AlertKey)},
+				},
+			},
+		})
+	}
+	batchInput := &dynamodb.BatchWriteItemInput{
+		RequestItems: map[string][]*dynamodb.WriteRequest{tableName: deleteRequests},
+	}
+	return dynamodbbatch.BatchWriteItem(dynamoClient, maxDigestBackoff, batchInput)
+}
+
+// queryDigestItems returns the oldest queued alerts of an output
+func queryDigestItems(tableName, outputID string) ([]*digestItem, error) {
+	keyCondition := expression.Key("outputId").Equal(expression.Value(outputID))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build digest query")
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:                 &tableName,
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ConsistentRead:            aws.Bool(true),
+	}
+	var items []*digestItem
+	for len(items) < maxDigestAlerts {
+		page, err := dynamoClient.Query(input)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to query digest alerts")
+		}
+		var pageItems []*digestItem
+		if err := dynamodbattribute.UnmarshalListOfMaps(page.Items, &pageItems); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal digest alerts")
+		}
+		items = append(items, pageItems...)
+		if len(page.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = page.LastEvaluatedKey
+	}
+	if len(items) > maxDigestAlerts {
+		items = items[:maxDigestAlerts]
+	}
+	return items, nil
+}
@@ -0,0 +1,203 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+func genEmailOutput() *outputModels.AlertOutput {
+	return &outputModels.AlertOutput{
+		OutputID:    aws.String("output-id"),
+		OutputType:  aws.String("email"),
+		DisplayName: aws.String("email:team"),
+		OutputConfig: &outputModels.OutputConfig{
+			Email: &outputModels.EmailConfig{
+				Transport:             outputs.EmailTransportSES,
+				FromAddress:           "panther@example.com",
+				Recipients:            []string{"team@example.com"},
+				DigestSeverities:      []string{"INFO", "LOW"},
+				DigestIntervalMinutes: 30,
+			},
+		},
+		DefaultForSeverity: []*string{aws.String("INFO")},
+		AlertTypes:         []string{deliverymodel.RuleType},
+	}
+}
+
+func digestItemAttributes(t *testing.T, alert *deliverymodel.Alert) map[string]*dynamodb.AttributeValue {
+	serializedAlert, err := jsoniter.MarshalToString(alert)
+	require.NoError(t, err)
+	item, err := dynamodbattribute.MarshalMap(&digestItem{
+		OutputID: "output-id",
+		AlertKey: alert.CreatedAt.Format(digestKeyTimeLayout) + "#" + *alert.AlertID,
+		Alert:    serializedAlert,
+	})
+	require.NoError(t, err)
+	return item
+}
+
+func TestIsDigestAlert(t *testing.T) {
+	config := genEmailOutput().OutputConfig.Email
+	alert := sampleAlert()
+	assert.True(t, isDigestAlert(alert, config))
+
+	alert.Severity = "HIGH"
+	assert.False(t, isDigestAlert(alert, config))
+
+	alert = sampleAlert()
+	alert.IsTest = true
+	assert.False(t, isDigestAlert(alert, config))
+
+	alert = sampleAlert()
+	alert.IsResent = true
+	assert.False(t, isDigestAlert(alert, config))
+
+	alert = sampleAlert()
+	alert.IsEscalated = true
+	assert.False(t, isDigestAlert(alert, config))
+}
+
+func TestSendAlertQueuesDigest(t *testing.T) {
+	mockClient := &mockOutputsClient{}
+	mockDynamo := &testutils.DynamoDBMock{}
+	dynamoClient = mockDynamo
+	env.EmailDigestTableName = "digests"
+
+	alert := sampleAlert()
+	alert.CreatedAt = time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	mockDynamo.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once()
+
+	ch := make(chan DispatchStatus, 1)
+	dispatchedAt := time.Now().UTC()
+	go sendAlert(context.Background(), alert, genEmailOutput(), dispatchedAt, ch, mockClient)
+	assert.Equal(t, DispatchStatus{
+		Alert:        *alert,
+		OutputID:     "output-id",
+		StatusCode:   202,
+		Success:      true,
+		Message:      "alert queued for the next email digest",
+		DispatchedAt: dispatchedAt,
+	}, <-ch)
+	mockDynamo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+
+	input := mockDynamo.Calls[0].Arguments.Get(0).(*dynamodb.PutItemInput)
+	assert.Equal(t, "digests", *input.TableName)
+	var item digestItem
+	require.NoError(t, dynamodbattribute.UnmarshalMap(input.Item, &item))
+	assert.Equal(t, "output-id", item.OutputID)
+	assert.Equal(t, "2020-01-01T12:00:00.000000000Z#alert-id", item.AlertKey)
+	queued := &deliverymodel.Alert{}
+	require.NoError(t, jsoniter.UnmarshalFromString(item.Alert, queued))
+	assert.Equal(t, alert.AlertID, queued.AlertID)
+}
+
+func TestSendAlertEmailHighSeverity(t *testing.T) {
+	mockClient := &mockOutputsClient{}
+	alert := sampleAlert()
+	alert.Severity = "HIGH"
+	output := genEmailOutput()
+	ctx := context.Background()
+	mockClient.On("Email", ctx, alert, output.OutputConfig.Email).
+		Return(&outputs.AlertDeliveryResponse{StatusCode: 200, Message: "messageId", Success: true}).Once()
+
+	ch := make(chan DispatchStatus, 1)
+	go sendAlert(ctx, alert, output, time.Now().UTC(), ch, mockClient)
+	status := <-ch
+	assert.True(t, status.Success)
+	assert.Equal(t, "messageId", status.Message)
+	mockClient.AssertExpectations(t)
+}
+
+func TestSendEmailDigests(t *testing.T) {
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	mockDynamo := &testutils.DynamoDBMock{}
+	dynamoClient = mockDynamo
+	env.EmailDigestTableName = "digests"
+	output := genEmailOutput()
+	outputsCache = &alertOutputsCache{
+		Outputs:         []*outputModels.AlertOutput{output, genAlertOutput()},
+		Expiry:          time.Now().UTC(),
+		RefreshInterval: time.Hour,
+	}
+
+	first, second := sampleAlert(), sampleAlert()
+	first.CreatedAt = time.Now().UTC().Add(-time.Hour)
+	second.AlertID = aws.String("other-alert-id")
+	mockDynamo.On("Query", mock.Anything).Return(&dynamodb.QueryOutput{
+		Items: []map[string]*dynamodb.AttributeValue{digestItemAttributes(t, first), digestItemAttributes(t, second)},
+	}, nil).Once()
+	ctx := context.Background()
+	mockClient.On("EmailDigest", ctx, mock.Anything, output.OutputConfig.Email).
+		Return(&outputs.AlertDeliveryResponse{StatusCode: 200, Success: true}).Once()
+	mockDynamo.On("BatchWriteItem", mock.Anything).Return(&dynamodb.BatchWriteItemOutput{}, nil).Once()
+
+	_, err := API{}.SendEmailDigests(ctx, &deliverymodel.SendEmailDigestsInput{})
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+	mockDynamo.AssertExpectations(t)
+
+	alerts := mockClient.Calls[0].Arguments.Get(1).([]*deliverymodel.Alert)
+	require.Len(t, alerts, 2)
+	assert.Equal(t, first.AlertID, alerts[0].AlertID)
+	assert.Equal(t, second.AlertID, alerts[1].AlertID)
+	batchInput := mockDynamo.Calls[1].Arguments.Get(0).(*dynamodb.BatchWriteItemInput)
+	assert.Len(t, batchInput.RequestItems["digests"], 2)
+}
+
+func TestSendEmailDigestsNotDue(t *testing.T) {
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	mockDynamo := &testutils.DynamoDBMock{}
+	dynamoClient = mockDynamo
+	outputsCache = &alertOutputsCache{
+		Outputs:         []*outputModels.AlertOutput{genEmailOutput()},
+		Expiry:          time.Now().UTC(),
+		RefreshInterval: time.Hour,
+	}
+
+	// The oldest alert was queued 10 minutes ago, the digest interval is 30 minutes
+	alert := sampleAlert()
+	alert.CreatedAt = time.Now().UTC().Add(-10 * time.Minute)
+	mockDynamo.On("Query", mock.Anything).Return(&dynamodb.QueryOutput{
+		Items: []map[string]*dynamodb.AttributeValue{digestItemAttributes(t, alert)},
+	}, nil).Once()
+
+	_, err := API{}.SendEmailDigests(context.Background(), &deliverymodel.SendEmailDigestsInput{})
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+	mockDynamo.AssertExpectations(t)
+}
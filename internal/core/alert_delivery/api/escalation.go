@@ -0,0 +1,484 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+
+	alertModels "github.com/panther-labs/panther/api/lambda/alerts/models"
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	userModels "github.com/panther-labs/panther/api/lambda/users/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	// The longest an escalation step can wait, the afterMinutes limit of the escalation policies
+	maxEscalationDelay = 7 * 24 * time.Hour
+	// Alerts older than this are no longer escalated. The last step of a week is due a day before,
+	// which leaves the runs walking through the open alerts plenty of time to reach it.
+	escalationLookback = maxEscalationDelay + 24*time.Hour
+	escalationPageSize = 50
+	// Each run checks at most this many alerts, the next run continues with the following alerts
+	escalationMaxAlerts = 500
+	escalationJob       = "escalateAlerts"
+	// The escalation state of an alert is kept for a day longer than it can be escalated
+	escalationItemTTL = escalationLookback + 24*time.Hour
+)
+
+// escalationItem is how far an alert has been escalated
+type escalationItem struct {
+	AlertID  string `json:"alertId"`
+	PolicyID string `json:"policyId"`
+	// The number of escalation steps delivered
+	Level     int   `json:"level"`
+	ExpiresAt int64 `json:"expiresAt"`
+}
+
+// escalation is the steps of an alert which became due, claimed before they are delivered
+type escalation struct {
+	alert    *deliverymodel.Alert
+	outputs  []*outputModels.AlertOutput
+	policyID string
+	// The number of steps delivered before, and along with this escalation
+	previous int
+	level    int
+}
+
+// escalationTargets resolves the destinations of escalation steps
+type escalationTargets struct {
+	outputs   map[string]*outputModels.AlertOutput
+	schedules map[string]*outputModels.OnCallSchedule
+	// Emails of the users found so far, by user ID
+	emails map[string]string
+	now    time.Time
+}
+
+// EscalateAlerts re-delivers the alerts which are still open to the steps of their escalation policy
+// which are due.
+//
+// Each step is delivered once. If a delivery of an alert failed and can be retried, the steps are
+// released and the next run delivers them again to all their destinations.
+//
+// A run stops after a batch of alerts or close to the deadline, and the next run continues from the page
+// it stopped in. Once all the alerts are checked, the next run starts over.
+func (API) EscalateAlerts(ctx context.Context, input *deliverymodel.EscalateAlertsInput) (interface{}, error) {
+	policies, err := listEscalationPolicies()
+	if err != nil {
+		return nil, err
+	}
+	if !anyEnabled(policies) {
+		return nil, nil
+	}
+	schedules, err := listOnCallSchedules()
+	if err != nil {
+		return nil, err
+	}
+	alertOutputs, err := getOutputs()
+	if err != nil {
+		return nil, err
+	}
+	windows, err := getMaintenanceWindows()
+	if err != nil {
+		return nil, err
+	}
+
+	targets := &escalationTargets{
+		outputs:   make(map[string]*outputModels.AlertOutput, len(alertOutputs)),
+		schedules: make(map[string]*outputModels.OnCallSchedule, len(schedules)),
+		emails:    make(map[string]string),
+		now:       time.Now().UTC(),
+	}
+	for _, output := range alertOutputs {
+		targets.outputs[*output.OutputID] = output
+	}
+	for _, schedule := range schedules {
+		targets.schedules[schedule.ScheduleID] = schedule
+	}
+
+	cursor, err := getJobCursor(escalationJob)
+	if err != nil {
+		return nil, err
+	}
+
+	// Stop listing alerts early enough to deliver the escalations found so far
+	deadline, hasDeadline := ctx.Deadline()
+	checked := 0
+	stop := func() bool {
+		return checked >= escalationMaxAlerts || (hasDeadline && time.Until(deadline) < 2*softDeadlineDuration)
+	}
+
+	alertOutputMap := make(AlertOutputMap)
+	escalations := make(map[string]*escalation)
+	var result error
+	listInput := &alertModels.ListAlertsInput{
+		PageSize:          aws.Int(escalationPageSize),
+		Status:            []string{alertModels.OpenStatus},
+		CreatedAtAfter:    aws.Time(targets.now.Add(-escalationLookback)),
+		ExclusiveStartKey: cursor,
+	}
+	cursor = nil
+pages:
+	for {
+		var page alertModels.ListAlertsOutput
+		if err := genericapi.Invoke(lambdaClient, env.AlertsAPI, &alertModels.LambdaInput{ListAlerts: listInput}, &page); err != nil {
+			return nil, err
+		}
+		for _, summary := range page.Alerts {
+			if stop() {
+				// Steps already delivered are not delivered again, the next run starts with this page
+				cursor = listInput.ExclusiveStartKey
+				break pages
+			}
+			checked++
+			escalated, err := escalateAlert(summary, policies, windows, targets)
+			if err != nil {
+				zap.L().Error("failed to escalate alert", zap.String("alertId", summary.AlertID), zap.Error(err))
+				result = multierr.Append(result, err)
+				continue
+			}
+			if escalated != nil && len(escalated.outputs) > 0 {
+				alertOutputMap[escalated.alert] = escalated.outputs
+				escalations[summary.AlertID] = escalated
+			}
+		}
+		if page.LastEvaluatedKey == nil {
+			break
+		}
+		listInput.ExclusiveStartKey = page.LastEvaluatedKey
+		if stop() {
+			cursor = listInput.ExclusiveStartKey
+			break
+		}
+	}
+
+	if len(alertOutputMap) > 0 {
+		dispatchStatuses := sendAlerts(ctx, alertOutputMap, outputClient)
+		recordOutputHealth(alertOutputMap, dispatchStatuses)
+		updateAlerts(dispatchStatuses)
+		result = multierr.Append(result, releaseFailedEscalations(escalations, dispatchStatuses))
+	}
+	return nil, multierr.Append(result, putJobCursor(escalationJob, cursor))
+}
+
+// releaseFailedEscalations - releases the steps of the alerts with a delivery which can be retried
+func releaseFailedEscalations(escalations map[string]*escalation, statuses []DispatchStatus) error {
+	var result error
+	for _, status := range statuses {
+		if status.Success || !status.NeedsRetry {
+			continue
+		}
+		alertID := aws.StringValue(status.Alert.AlertID)
+		escalated, ok := escalations[alertID]
+		if !ok {
+			continue
+		}
+		// Once per alert, the alert may have failed on several outputs
+		delete(escalations, alertID)
+		zap.L().Warn("escalation failed, releasing its steps",
+			zap.String("alertId", alertID), zap.String("outputId", status.OutputID), zap.String("message", status.Message))
+		result = multierr.Append(result, releaseEscalationLevel(alertID, escalated))
+	}
+	return result
+}
+
+// escalateAlert - claims the escalation steps of an open alert which became due, nil if there are none
+func escalateAlert(
+	summary *alertModels.AlertSummary,
+	policies []*outputModels.EscalationPolicy,
+	windows []*outputModels.MaintenanceWindow,
+	targets *escalationTargets,
+) (*escalation, error) {
+
+	policy := matchingEscalationPolicy(summary, policies)
+	if policy == nil || summary.CreationTime == nil {
+		return nil, nil
+	}
+	level := dueEscalationLevel(policy, targets.now.Sub(*summary.CreationTime))
+	if level == 0 {
+		return nil, nil
+	}
+	previous, err := getEscalationLevel(summary.AlertID, policy.PolicyID)
+	if err != nil || previous >= level {
+		return nil, err
+	}
+
+	alertItem, err := alertsTableClient.GetAlert(summary.AlertID)
+	if err != nil || alertItem == nil {
+		return nil, err
+	}
+	alert, err := populateAlertData(alertItem)
+	if err != nil {
+		return nil, err
+	}
+	// Planned work holds back escalations too, they resume once the window closes
+	if window, _ := matchingMaintenanceWindow(alert, windows, targets.now); window != nil {
+		return nil, nil
+	}
+
+	// Claimed before the delivery, so concurrent runs do not deliver the same steps twice
+	claimed, err := putEscalationLevel(summary.AlertID, policy.PolicyID, level)
+	if err != nil || !claimed {
+		return nil, err
+	}
+	alert.IsEscalated = true
+
+	var outputs []*outputModels.AlertOutput
+	for _, step := range policy.Steps[previous:level] {
+		outputs = append(outputs, targets.resolve(&step)...)
+	}
+	zap.L().Info("escalating alert",
+		zap.String("alertId", summary.AlertID),
+		zap.String("policyId", policy.PolicyID),
+		zap.Int("level", level),
+		zap.Int("numOutputs", len(outputs)))
+	return &escalation{alert: alert, outputs: outputs, policyID: policy.PolicyID, previous: previous, level: level}, nil
+}
+
+// matchingEscalationPolicy - the first enabled escalation policy matching the alert
+func matchingEscalationPolicy(summary *alertModels.AlertSummary, policies []*outputModels.EscalationPolicy) *outputModels.EscalationPolicy {
+	analysisID := aws.StringValue(summary.RuleID)
+	if analysisID == "" {
+		analysisID = summary.PolicyID
+	}
+	for _, policy := range policies {
+		match := &policy.Match
+		if !policy.Enabled {
+			continue
+		}
+		if len(match.AnalysisIDs) > 0 && !containsAny(match.AnalysisIDs, []string{analysisID}) {
+			continue
+		}
+		if len(match.Severities) > 0 && !containsAny(match.Severities, []string{aws.StringValue(summary.Severity)}) {
+			continue
+		}
+		if len(match.LogTypes) > 0 && !containsAny(match.LogTypes, summary.LogTypes) {
+			continue
+		}
+		return policy
+	}
+	return nil
+}
+
+// dueEscalationLevel - the number of escalation steps due for an alert open for the given time
+func dueEscalationLevel(policy *outputModels.EscalationPolicy, open time.Duration) int {
+	level := 0
+	for _, step := range policy.Steps {
+		if open < time.Duration(step.AfterMinutes)*time.Minute {
+			break
+		}
+		level++
+	}
+	return level
+}
+
+// resolve - the outputs of an escalation step. The on-call user of a schedule is emailed through a copy of
+// the schedule's email output.
+func (targets *escalationTargets) resolve(step *outputModels.EscalationStep) []*outputModels.AlertOutput {
+	var outputs []*outputModels.AlertOutput
+	for _, outputID := range step.OutputIDs {
+		if output, ok := targets.outputs[outputID]; ok {
+			outputs = append(outputs, output)
+		} else {
+			zap.L().Warn("escalation destination does not exist", zap.String("outputId", outputID))
+		}
+	}
+
+	for _, scheduleID := range step.ScheduleIDs {
+		schedule, ok := targets.schedules[scheduleID]
+		if !ok {
+			zap.L().Warn("on-call schedule does not exist", zap.String("scheduleId", scheduleID))
+			continue
+		}
+		output, ok := targets.outputs[schedule.OutputID]
+		if !ok || output.OutputConfig == nil || output.OutputConfig.Email == nil {
+			zap.L().Warn("on-call schedule has no email destination", zap.String("scheduleId", scheduleID))
+			continue
+		}
+		email, err := targets.onCallEmail(schedule)
+		if err != nil {
+			zap.L().Warn("failed to find the on-call user", zap.String("scheduleId", scheduleID), zap.Error(err))
+			continue
+		}
+		if email == "" {
+			continue
+		}
+
+		emailConfig := *output.OutputConfig.Email
+		emailConfig.Recipients = []string{email}
+		onCall := *output
+		onCall.OutputConfig = &outputModels.OutputConfig{Email: &emailConfig}
+		outputs = append(outputs, &onCall)
+	}
+	return outputs
+}
+
+// onCallEmail - the email of the user on call for a schedule, looked up once per user
+func (targets *escalationTargets) onCallEmail(schedule *outputModels.OnCallSchedule) (string, error) {
+	userID := schedule.OnCall(targets.now)
+	if userID == "" {
+		return "", nil
+	}
+	if email, ok := targets.emails[userID]; ok {
+		return email, nil
+	}
+
+	input := userModels.LambdaInput{GetUser: &userModels.GetUserInput{ID: aws.String(userID)}}
+	var user userModels.GetUserOutput
+	if err := genericapi.Invoke(lambdaClient, env.UsersAPI, &input, &user); err != nil {
+		return "", err
+	}
+	targets.emails[userID] = aws.StringValue(user.Email)
+	return targets.emails[userID], nil
+}
+
+// getEscalationLevel - the number of steps of an escalation policy already delivered for an alert
+func getEscalationLevel(alertID, policyID string) (int, error) {
+	result, err := dynamoClient.GetItem(&dynamodb.GetItemInput{
+		TableName:      &env.EscalationTableName,
+		Key:            map[string]*dynamodb.AttributeValue{"alertId": {S: &alertID}},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get escalation state")
+	}
+	var item escalationItem
+	if err := dynamodbattribute.UnmarshalMap(result.Item, &item); err != nil {
+		return 0, errors.Wrap(err, "failed to unmarshal escalation state")
+	}
+	// Alerts which match another policy now start over
+	if item.PolicyID != policyID {
+		return 0, nil
+	}
+	return item.Level, nil
+}
+
+// putEscalationLevel - records the steps delivered for an alert. Returns false if another invocation got there first.
+func putEscalationLevel(alertID, policyID string, level int) (bool, error) {
+	item, err := dynamodbattribute.MarshalMap(&escalationItem{
+		AlertID:   alertID,
+		PolicyID:  policyID,
+		Level:     level,
+		ExpiresAt: time.Now().Add(escalationItemTTL).Unix(),
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "failed to marshal escalation state")
+	}
+
+	condition := expression.AttributeNotExists(expression.Name("alertId")).
+		Or(expression.Name("policyId").NotEqual(expression.Value(policyID))).
+		Or(expression.Name("level").LessThan(expression.Value(level)))
+	expr, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to build escalation condition")
+	}
+
+	_, err = dynamoClient.PutItem(&dynamodb.PutItemInput{
+		TableName:                 &env.EscalationTableName,
+		Item:                      item,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "failed to store escalation state")
+	}
+	return true, nil
+}
+
+// releaseEscalationLevel - restores the steps delivered before an escalation which failed.
+// Nothing changes if another invocation escalated the alert further meanwhile.
+func releaseEscalationLevel(alertID string, escalated *escalation) error {
+	item, err := dynamodbattribute.MarshalMap(&escalationItem{
+		AlertID:   alertID,
+		PolicyID:  escalated.policyID,
+		Level:     escalated.previous,
+		ExpiresAt: time.Now().Add(escalationItemTTL).Unix(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal escalation state")
+	}
+
+	condition := expression.Name("policyId").Equal(expression.Value(escalated.policyID)).
+		And(expression.Name("level").Equal(expression.Value(escalated.level)))
+	expr, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		return errors.Wrap(err, "failed to build escalation condition")
+	}
+
+	_, err = dynamoClient.PutItem(&dynamodb.PutItemInput{
+		TableName:                 &env.EscalationTableName,
+		Item:                      item,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return nil
+		}
+		return errors.Wrap(err, "failed to release escalation state")
+	}
+	return nil
+}
+
+// listEscalationPolicies - the escalation policies from panther, always the latest ones
+func listEscalationPolicies() ([]*outputModels.EscalationPolicy, error) {
+	input := outputModels.LambdaInput{ListEscalationPolicies: &outputModels.ListEscalationPoliciesInput{}}
+	var policies outputModels.ListEscalationPoliciesOutput
+	if err := genericapi.Invoke(lambdaClient, env.OutputsAPI, &input, &policies); err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// listOnCallSchedules - the on-call schedules from panther, always the latest ones
+func listOnCallSchedules() ([]*outputModels.OnCallSchedule, error) {
+	input := outputModels.LambdaInput{ListOnCallSchedules: &outputModels.ListOnCallSchedulesInput{}}
+	var schedules outputModels.ListOnCallSchedulesOutput
+	if err := genericapi.Invoke(lambdaClient, env.OutputsAPI, &input, &schedules); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// anyEnabled - checks if any escalation policy is enabled
+func anyEnabled(policies []*outputModels.EscalationPolicy) bool {
+	for _, policy := range policies {
+		if policy.Enabled {
+			return true
+		}
+	}
+	return false
+}
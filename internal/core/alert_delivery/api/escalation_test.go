@@ -0,0 +1,344 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/go-playground/validator"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	alertModels "github.com/panther-labs/panther/api/lambda/alerts/models"
+	analysisModels "github.com/panther-labs/panther/api/lambda/analysis/models"
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	userModels "github.com/panther-labs/panther/api/lambda/users/models"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
+	alertTable "github.com/panther-labs/panther/internal/log_analysis/alerts_api/table"
+	"github.com/panther-labs/panther/pkg/gatewayapi"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+var rotationStart = time.Date(2020, 7, 6, 9, 0, 0, 0, time.UTC)
+
+func genEscalationPolicy() *outputModels.EscalationPolicy {
+	return &outputModels.EscalationPolicy{
+		PolicyID:    "policy-id",
+		DisplayName: "Critical alerts",
+		Enabled:     true,
+		Match:       outputModels.EscalationMatch{Severities: []string{"HIGH", "CRITICAL"}},
+		Steps: []outputModels.EscalationStep{
+			{AfterMinutes: 15, ScheduleIDs: []string{"schedule-id"}},
+			{AfterMinutes: 60, OutputIDs: []string{"output-id"}},
+		},
+	}
+}
+
+func genOnCallSchedule() *outputModels.OnCallSchedule {
+	return &outputModels.OnCallSchedule{
+		ScheduleID:    "schedule-id",
+		OutputID:      "email-id",
+		RotationStart: rotationStart,
+		ShiftHours:    24,
+		UserIDs:       []string{"user-1", "user-2"},
+	}
+}
+
+func genEscalationTargets(now time.Time) *escalationTargets {
+	email := genEmailOutput()
+	email.OutputID = aws.String("email-id")
+	return &escalationTargets{
+		outputs: map[string]*outputModels.AlertOutput{
+			"output-id": genAlertOutput(),
+			"email-id":  email,
+		},
+		schedules: map[string]*outputModels.OnCallSchedule{"schedule-id": genOnCallSchedule()},
+		emails:    make(map[string]string),
+		now:       now,
+	}
+}
+
+func userPayload(t *testing.T, email string) *lambda.InvokeOutput {
+	payload, err := jsoniter.Marshal(&userModels.GetUserOutput{Email: aws.String(email)})
+	require.NoError(t, err)
+	return &lambda.InvokeOutput{Payload: payload}
+}
+
+func TestOnCallRotation(t *testing.T) {
+	schedule := genOnCallSchedule()
+	assert.Equal(t, "", schedule.OnCall(rotationStart.Add(-time.Minute)))
+	assert.Equal(t, "user-1", schedule.OnCall(rotationStart))
+	assert.Equal(t, "user-2", schedule.OnCall(rotationStart.Add(30*time.Hour)))
+	assert.Equal(t, "user-1", schedule.OnCall(rotationStart.Add(48*time.Hour)))
+}
+
+func TestMaxEscalationDelay(t *testing.T) {
+	// Alerts are listed for a while after their last step is due
+	assert.Greater(t, int64(escalationLookback), int64(maxEscalationDelay))
+
+	validate := validator.New()
+	step := &outputModels.EscalationStep{AfterMinutes: int(maxEscalationDelay / time.Minute)}
+	require.NoError(t, validate.Struct(step))
+	step.AfterMinutes++
+	assert.Error(t, validate.Struct(step))
+}
+
+func TestDueEscalationLevel(t *testing.T) {
+	policy := genEscalationPolicy()
+	assert.Equal(t, 0, dueEscalationLevel(policy, 14*time.Minute))
+	assert.Equal(t, 1, dueEscalationLevel(policy, 15*time.Minute))
+	assert.Equal(t, 2, dueEscalationLevel(policy, 2*time.Hour))
+}
+
+func TestMatchingEscalationPolicy(t *testing.T) {
+	disabled, policy := genEscalationPolicy(), genEscalationPolicy()
+	disabled.Enabled = false
+	policy.Match.AnalysisIDs = []string{"Compliance.Policy"}
+	policies := []*outputModels.EscalationPolicy{disabled, policy}
+
+	summary := &alertModels.AlertSummary{PolicyID: "Compliance.Policy", Severity: aws.String("HIGH")}
+	assert.Equal(t, policy, matchingEscalationPolicy(summary, policies))
+
+	summary.Severity = aws.String("LOW")
+	assert.Nil(t, matchingEscalationPolicy(summary, policies))
+
+	summary = &alertModels.AlertSummary{RuleID: aws.String("AWS.Root.Login"), Severity: aws.String("HIGH")}
+	assert.Nil(t, matchingEscalationPolicy(summary, policies))
+}
+
+func TestEscalationTargetsResolve(t *testing.T) {
+	mockLambda := &testutils.LambdaMock{}
+	lambdaClient = mockLambda
+	env.UsersAPI = "users-api"
+	targets := genEscalationTargets(rotationStart.Add(30 * time.Hour))
+	mockLambda.On("Invoke", invokedWith("getUser")).Return(userPayload(t, "user-2@example.com"), nil).Once()
+
+	step := &outputModels.EscalationStep{OutputIDs: []string{"output-id", "missing-id"}, ScheduleIDs: []string{"schedule-id"}}
+	result := targets.resolve(step)
+	require.Len(t, result, 2)
+	assert.Equal(t, targets.outputs["output-id"], result[0])
+	assert.Equal(t, "email-id", *result[1].OutputID)
+	assert.Equal(t, []string{"user-2@example.com"}, result[1].OutputConfig.Email.Recipients)
+	// The email output itself is unchanged
+	assert.Equal(t, []string{"team@example.com"}, targets.outputs["email-id"].OutputConfig.Email.Recipients)
+
+	// The user is only looked up once
+	result = targets.resolve(step)
+	assert.Equal(t, []string{"user-2@example.com"}, result[1].OutputConfig.Email.Recipients)
+	mockLambda.AssertExpectations(t)
+}
+
+func TestEscalateAlerts(t *testing.T) {
+	mockLambda := &testutils.LambdaMock{}
+	lambdaClient = mockLambda
+	mockDynamo := &testutils.DynamoDBMock{}
+	dynamoClient = mockDynamo
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	mockAnalysisClient := &gatewayapi.MockClient{}
+	analysisClient = mockAnalysisClient
+	alertsTableClient = &alertTable.AlertsTable{AlertsTableName: "alerts", Client: mockDynamo}
+	env.EscalationTableName = "escalations"
+	env.JobCursorsTableName = "cursors"
+	defer func() { outputsCache = nil }()
+	outputsCache = &alertOutputsCache{
+		Outputs:                  []*outputModels.AlertOutput{genAlertOutput()},
+		Expiry:                   time.Now(),
+		RefreshInterval:          time.Hour,
+		MaintenanceWindowsExpiry: time.Now(),
+	}
+
+	policy := genEscalationPolicy()
+	policy.Steps = policy.Steps[1:]
+	policiesPayload, err := jsoniter.Marshal([]*outputModels.EscalationPolicy{policy})
+	require.NoError(t, err)
+	createdAt := time.Now().UTC().Add(-2 * time.Hour)
+	alertsPayload, err := jsoniter.Marshal(&alertModels.ListAlertsOutput{
+		Alerts: []*alertModels.AlertSummary{
+			{AlertID: "alert-id", RuleID: aws.String("rule-id"), Severity: aws.String("HIGH"), CreationTime: &createdAt},
+			{AlertID: "low-id", RuleID: aws.String("rule-id"), Severity: aws.String("LOW"), CreationTime: &createdAt},
+		},
+	})
+	require.NoError(t, err)
+	mockLambda.On("Invoke", invokedWith("listEscalationPolicies")).Return(&lambda.InvokeOutput{Payload: policiesPayload}, nil).Once()
+	mockLambda.On("Invoke", invokedWith("listOnCallSchedules")).Return(&lambda.InvokeOutput{Payload: []byte("[]")}, nil).Once()
+	mockLambda.On("Invoke", invokedWith("listAlerts")).Return(&lambda.InvokeOutput{Payload: alertsPayload}, nil).Once()
+	mockLambda.On("Invoke", invokedWith("updateAlertDelivery")).Return(&lambda.InvokeOutput{Payload: []byte("{}")}, nil).Once()
+
+	mockDynamo.On("GetItem", mock.MatchedBy(func(input *dynamodb.GetItemInput) bool {
+		return *input.TableName == "cursors"
+	})).Return(&dynamodb.GetItemOutput{}, nil).Once()
+	mockDynamo.On("GetItem", mock.MatchedBy(func(input *dynamodb.GetItemInput) bool {
+		return *input.TableName == "escalations"
+	})).Return(&dynamodb.GetItemOutput{}, nil).Once()
+	alertItem, err := dynamodbattribute.MarshalMap(&alertTable.AlertItem{
+		AlertID: "alert-id", Type: deliverymodel.RuleType, RuleID: "rule-id", Severity: "HIGH", CreationTime: createdAt})
+	require.NoError(t, err)
+	mockDynamo.On("GetItem", mock.MatchedBy(func(input *dynamodb.GetItemInput) bool {
+		return *input.TableName == "alerts"
+	})).Return(&dynamodb.GetItemOutput{Item: alertItem}, nil).Once()
+	mockDynamo.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once()
+	// All the alerts were checked, the next run starts over
+	mockDynamo.On("DeleteItem", mock.Anything).Return(&dynamodb.DeleteItemOutput{}, nil).Once()
+	mockAnalysisClient.On("Invoke", mock.Anything, &analysisModels.Rule{}).Return(
+		http.StatusOK, nil, &analysisModels.Rule{ID: "rule-id"}).Once()
+	mockClient.On("Slack", mock.Anything, mock.MatchedBy(func(alert *deliverymodel.Alert) bool {
+		return *alert.AlertID == "alert-id" && alert.IsEscalated
+	}), mock.Anything).Return(&outputs.AlertDeliveryResponse{StatusCode: 200, Success: true}).Once()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	_, err = API{}.EscalateAlerts(ctx, &deliverymodel.EscalateAlertsInput{})
+	require.NoError(t, err)
+	mockLambda.AssertExpectations(t)
+	mockDynamo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+	mockAnalysisClient.AssertExpectations(t)
+
+	put := mockDynamo.Calls[3].Arguments.Get(0).(*dynamodb.PutItemInput)
+	var item escalationItem
+	require.NoError(t, dynamodbattribute.UnmarshalMap(put.Item, &item))
+	assert.Equal(t, escalationItem{AlertID: "alert-id", PolicyID: "policy-id", Level: 1, ExpiresAt: item.ExpiresAt}, item)
+}
+
+func TestEscalateAlertsContinuesFromCursor(t *testing.T) {
+	mockLambda := &testutils.LambdaMock{}
+	lambdaClient = mockLambda
+	mockDynamo := &testutils.DynamoDBMock{}
+	dynamoClient = mockDynamo
+	env.JobCursorsTableName = "cursors"
+	softDeadlineDuration = 10 * time.Second
+	defer func() { outputsCache = nil }()
+	outputsCache = &alertOutputsCache{
+		Outputs:                  []*outputModels.AlertOutput{genAlertOutput()},
+		Expiry:                   time.Now(),
+		RefreshInterval:          time.Hour,
+		MaintenanceWindowsExpiry: time.Now(),
+	}
+
+	policiesPayload, err := jsoniter.Marshal([]*outputModels.EscalationPolicy{genEscalationPolicy()})
+	require.NoError(t, err)
+	createdAt := time.Now().UTC().Add(-2 * time.Hour)
+	alertsPayload, err := jsoniter.Marshal(&alertModels.ListAlertsOutput{
+		Alerts: []*alertModels.AlertSummary{
+			{AlertID: "alert-2", RuleID: aws.String("rule-id"), Severity: aws.String("HIGH"), CreationTime: &createdAt},
+		},
+		LastEvaluatedKey: aws.String("alert-2"),
+	})
+	require.NoError(t, err)
+	mockLambda.On("Invoke", invokedWith("listEscalationPolicies")).Return(&lambda.InvokeOutput{Payload: policiesPayload}, nil).Once()
+	mockLambda.On("Invoke", invokedWith("listOnCallSchedules")).Return(&lambda.InvokeOutput{Payload: []byte("[]")}, nil).Once()
+	mockLambda.On("Invoke", invokedWith("listAlerts")).Return(&lambda.InvokeOutput{Payload: alertsPayload}, nil).Once()
+	cursor, err := dynamodbattribute.MarshalMap(&jobCursor{Job: escalationJob, ExclusiveStartKey: "alert-1"})
+	require.NoError(t, err)
+	mockDynamo.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: cursor}, nil).Once()
+	mockDynamo.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once()
+
+	// Too close to the deadline to check any alert
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	_, err = API{}.EscalateAlerts(ctx, &deliverymodel.EscalateAlertsInput{})
+	require.NoError(t, err)
+	mockLambda.AssertExpectations(t)
+	mockDynamo.AssertExpectations(t)
+
+	var list alertModels.LambdaInput
+	require.NoError(t, jsoniter.Unmarshal(mockLambda.Calls[2].Arguments.Get(0).(*lambda.InvokeInput).Payload, &list))
+	assert.Equal(t, aws.String("alert-1"), list.ListAlerts.ExclusiveStartKey)
+
+	// The next run starts with the same page
+	var stored jobCursor
+	require.NoError(t, dynamodbattribute.UnmarshalMap(mockDynamo.Calls[1].Arguments.Get(0).(*dynamodb.PutItemInput).Item, &stored))
+	assert.Equal(t, jobCursor{Job: escalationJob, ExclusiveStartKey: "alert-1", ExpiresAt: stored.ExpiresAt}, stored)
+}
+
+func TestReleaseFailedEscalations(t *testing.T) {
+	mockDynamo := &testutils.DynamoDBMock{}
+	dynamoClient = mockDynamo
+	env.EscalationTableName = "escalations"
+	mockDynamo.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once()
+
+	escalations := map[string]*escalation{
+		"alert-id":     {policyID: "policy-id", previous: 1, level: 2},
+		"delivered-id": {policyID: "policy-id", previous: 0, level: 1},
+		"rejected-id":  {policyID: "policy-id", previous: 0, level: 1},
+	}
+	statuses := []DispatchStatus{
+		{Alert: deliverymodel.Alert{AlertID: aws.String("alert-id")}, OutputID: "output-1", NeedsRetry: true},
+		{Alert: deliverymodel.Alert{AlertID: aws.String("alert-id")}, OutputID: "output-2", NeedsRetry: true},
+		{Alert: deliverymodel.Alert{AlertID: aws.String("delivered-id")}, OutputID: "output-1", Success: true},
+		{Alert: deliverymodel.Alert{AlertID: aws.String("rejected-id")}, OutputID: "output-1"},
+	}
+	require.NoError(t, releaseFailedEscalations(escalations, statuses))
+	mockDynamo.AssertExpectations(t)
+
+	// Released once, back to the steps delivered before unless the alert was escalated further meanwhile
+	put := mockDynamo.Calls[0].Arguments.Get(0).(*dynamodb.PutItemInput)
+	var item escalationItem
+	require.NoError(t, dynamodbattribute.UnmarshalMap(put.Item, &item))
+	assert.Equal(t, escalationItem{AlertID: "alert-id", PolicyID: "policy-id", Level: 1, ExpiresAt: item.ExpiresAt}, item)
+	assert.Equal(t, "(#0 = :0) AND (#1 = :1)", *put.ConditionExpression)
+	assert.Equal(t, "2", *put.ExpressionAttributeValues[":1"].N)
+}
+
+func TestReleaseEscalationLevelTaken(t *testing.T) {
+	mockDynamo := &testutils.DynamoDBMock{}
+	dynamoClient = mockDynamo
+	mockDynamo.On("PutItem", mock.Anything).Return((*dynamodb.PutItemOutput)(nil),
+		awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil)).Once()
+
+	assert.NoError(t, releaseEscalationLevel("alert-id", &escalation{policyID: "policy-id", previous: 1, level: 2}))
+}
+
+func TestPutEscalationLevelTaken(t *testing.T) {
+	mockDynamo := &testutils.DynamoDBMock{}
+	dynamoClient = mockDynamo
+	mockDynamo.On("PutItem", mock.Anything).Return((*dynamodb.PutItemOutput)(nil),
+		awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil)).Once()
+
+	claimed, err := putEscalationLevel("alert-id", "policy-id", 1)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+}
+
+func TestGetEscalationLevelOtherPolicy(t *testing.T) {
+	mockDynamo := &testutils.DynamoDBMock{}
+	dynamoClient = mockDynamo
+	item, err := dynamodbattribute.MarshalMap(&escalationItem{AlertID: "alert-id", PolicyID: "old-policy", Level: 2})
+	require.NoError(t, err)
+	mockDynamo.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: item}, nil).Twice()
+
+	level, err := getEscalationLevel("alert-id", "old-policy")
+	require.NoError(t, err)
+	assert.Equal(t, 2, level)
+	level, err = getEscalationLevel("alert-id", "policy-id")
+	require.NoError(t, err)
+	assert.Equal(t, 0, level)
+}
@@ -0,0 +1,84 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/pkg/errors"
+)
+
+// A cursor nobody moved for this long is from a job which stopped running, the job starts over
+const jobCursorTTL = 24 * time.Hour
+
+// jobCursor is where a scheduled job walking through the alerts stopped, the next run continues from there
+type jobCursor struct {
+	Job string `json:"job"`
+	// The ListAlerts key of the first page which was not finished
+	ExclusiveStartKey string `json:"exclusiveStartKey"`
+	ExpiresAt         int64  `json:"expiresAt"`
+}
+
+// getJobCursor - the ListAlerts key a job continues from, nil to start from the first page
+func getJobCursor(job string) (*string, error) {
+	result, err := dynamoClient.GetItem(&dynamodb.GetItemInput{
+		TableName:      &env.JobCursorsTableName,
+		Key:            map[string]*dynamodb.AttributeValue{"job": {S: &job}},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get job cursor")
+	}
+	var cursor jobCursor
+	if err := dynamodbattribute.UnmarshalMap(result.Item, &cursor); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal job cursor")
+	}
+	if cursor.ExclusiveStartKey == "" {
+		return nil, nil
+	}
+	return &cursor.ExclusiveStartKey, nil
+}
+
+// putJobCursor - records where a job stopped. A nil key means the job went through all the alerts.
+func putJobCursor(job string, exclusiveStartKey *string) error {
+	if exclusiveStartKey == nil {
+		_, err := dynamoClient.DeleteItem(&dynamodb.DeleteItemInput{
+			TableName: &env.JobCursorsTableName,
+			Key:       map[string]*dynamodb.AttributeValue{"job": {S: &job}},
+		})
+		return errors.Wrap(err, "failed to delete job cursor")
+	}
+
+	item, err := dynamodbattribute.MarshalMap(&jobCursor{
+		Job:               job,
+		ExclusiveStartKey: *exclusiveStartKey,
+		ExpiresAt:         time.Now().Add(jobCursorTTL).Unix(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal job cursor")
+	}
+	_, err = dynamoClient.PutItem(&dynamodb.PutItemInput{
+		TableName: &env.JobCursorsTableName,
+		Item:      item,
+	})
+	return errors.Wrap(err, "failed to store job cursor")
+}
@@ -0,0 +1,360 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	alertModels "github.com/panther-labs/panther/api/lambda/alerts/models"
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
+	"github.com/panther-labs/panther/pkg/awsbatch/dynamodbbatch"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// Suppressed alerts are kept for this long after their window closed, if they could not be delivered
+const suppressedItemTTL = 7 * 24 * time.Hour
+
+// suppressedItem is an alert held back by a maintenance window
+type suppressedItem struct {
+	WindowID string `json:"windowId"`
+	// Prefixed with the end of the window occurrence, so each occurrence keeps its own alerts
+	AlertKey string `json:"alertKey"`
+	Alert    string `json:"alert"`
+	// When the window occurrence closes (seconds since epoch)
+	WindowEnd int64 `json:"windowEnd"`
+	ExpiresAt int64 `json:"expiresAt"`
+}
+
+// alertSummary is a single message summarizing the alerts delivered to an output
+type alertSummary struct {
+	Output *outputModels.AlertOutput
+	Alerts []*deliverymodel.Alert
+}
+
+// getMaintenanceWindows - Gets the maintenance windows from panther (using a cache)
+func getMaintenanceWindows() ([]*outputModels.MaintenanceWindow, error) {
+	if outputsCache.isMaintenanceWindowsExpired() {
+		input := outputModels.LambdaInput{ListMaintenanceWindows: &outputModels.ListMaintenanceWindowsInput{}}
+		var windows outputModels.ListMaintenanceWindowsOutput
+		if err := genericapi.Invoke(lambdaClient, env.OutputsAPI, &input, &windows); err != nil {
+			return nil, err
+		}
+		outputsCache.setMaintenanceWindows(windows)
+		outputsCache.setMaintenanceWindowsExpiry(time.Now().UTC())
+	}
+	return outputsCache.getMaintenanceWindows(), nil
+}
+
+// activeMaintenanceWindow - the maintenance window suppressing the delivery of a new alert, and the end of its occurrence
+func activeMaintenanceWindow(
+	alert *deliverymodel.Alert,
+	windows []*outputModels.MaintenanceWindow,
+	now time.Time,
+) (*outputModels.MaintenanceWindow, time.Time) {
+
+	// Test alerts, alerts re-sent by a user and retries of earlier deliveries are expected to arrive right away
+	if alert.IsTest || alert.IsResent || alert.RetryCount > 0 {
+		return nil, time.Time{}
+	}
+	return matchingMaintenanceWindow(alert, windows, now)
+}
+
+// matchingMaintenanceWindow - the first active maintenance window matching the alert, and the end of its occurrence
+func matchingMaintenanceWindow(
+	alert *deliverymodel.Alert,
+	windows []*outputModels.MaintenanceWindow,
+	now time.Time,
+) (*outputModels.MaintenanceWindow, time.Time) {
+
+	for _, window := range windows {
+		if !window.Enabled || !maintenanceWindowMatches(&window.Match, alert) {
+			continue
+		}
+		if _, end, ok := window.Occurrence(now); ok {
+			return window, end
+		}
+	}
+	return nil, time.Time{}
+}
+
+// maintenanceWindowMatches - checks if an alert satisfies all conditions of a maintenance window
+func maintenanceWindowMatches(match *outputModels.MaintenanceMatch, alert *deliverymodel.Alert) bool {
+	if len(match.AnalysisIDs) > 0 && !containsAny(match.AnalysisIDs, []string{alert.AnalysisID}) {
+		return false
+	}
+	if len(match.Severities) > 0 && !containsAny(match.Severities, []string{alert.Severity}) {
+		return false
+	}
+	if len(match.LogTypes) > 0 && !containsAny(match.LogTypes, alert.LogTypes) {
+		return false
+	}
+	if len(match.Tags) > 0 && !containsAny(match.Tags, alert.Tags) {
+		return false
+	}
+	return true
+}
+
+// suppressAlert stores an alert held back by a maintenance window until the window closes
+func suppressAlert(alert *deliverymodel.Alert) error {
+	serializedAlert, err := jsoniter.MarshalToString(alert)
+	if err != nil {
+		return errors.Wrap(err, "failed to serialize suppressed alert")
+	}
+
+	item, err := dynamodbattribute.MarshalMap(&suppressedItem{
+		WindowID: alert.SuppressedBy,
+		AlertKey: alert.SuppressedUntil.UTC().Format(digestKeyTimeLayout) + "#" +
+			alert.CreatedAt.UTC().Format(digestKeyTimeLayout) + "#" + aws.StringValue(alert.AlertID),
+		Alert:     serializedAlert,
+		WindowEnd: alert.SuppressedUntil.Unix(),
+		ExpiresAt: alert.SuppressedUntil.Add(suppressedItemTTL).Unix(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal suppressed alert item")
+	}
+
+	if _, err = dynamoClient.PutItem(&dynamodb.PutItemInput{
+		TableName: &env.SuppressedAlertsTableName,
+		Item:      item,
+	}); err != nil {
+		return errors.Wrap(err, "failed to store suppressed alert")
+	}
+	zap.L().Info("alert suppressed by maintenance window",
+		zap.Stringp("alertID", alert.AlertID),
+		zap.String("windowID", alert.SuppressedBy),
+		zap.Timep("suppressedUntil", alert.SuppressedUntil))
+	return nil
+}
+
+// getSuppressedStatuses - the statuses of a suppressed alert, one for each output it would be delivered to now
+func getSuppressedStatuses(alert *deliverymodel.Alert, outputs []*outputModels.AlertOutput) []DispatchStatus {
+	message := "suppressed by maintenance window " + alert.SuppressedBy +
+		" until " + alert.SuppressedUntil.UTC().Format(time.RFC3339)
+	statuses := make([]DispatchStatus, 0, len(outputs))
+	for _, output := range outputs {
+		statuses = append(statuses, DispatchStatus{
+			Alert:        *alert,
+			OutputID:     *output.OutputID,
+			Message:      message,
+			Success:      true,
+			DispatchedAt: time.Now().UTC(),
+			Status:       alertModels.DeliverySuppressedStatus,
+		})
+	}
+	return statuses
+}
+
+// SendMaintenanceSummaries delivers the alerts suppressed by maintenance windows which have closed.
+//
+// Alerts which were resolved meanwhile are dropped. Outputs which can summarize several alerts in a
+// single message receive one summary, the others receive the alerts one by one.
+func (API) SendMaintenanceSummaries(ctx context.Context, input *deliverymodel.SendMaintenanceSummariesInput) (interface{}, error) {
+	items, err := scanClosedSuppressions(time.Now().UTC())
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+
+	alerts := make([]*deliverymodel.Alert, 0, len(items))
+	for _, item := range items {
+		alert := &deliverymodel.Alert{}
+		if err := jsoniter.UnmarshalFromString(item.Alert, alert); err != nil {
+			// Skip it, the item is deleted along with the rest of the suppressed alerts
+			zap.L().Error("failed to unmarshal suppressed alert", zap.String("alertKey", item.AlertKey), zap.Error(err))
+			continue
+		}
+		open, err := isAlertOpen(alert)
+		if err != nil {
+			return nil, err
+		}
+		if !open {
+			continue
+		}
+		alert.SuppressedBy, alert.SuppressedUntil = "", nil
+		alerts = append(alerts, alert)
+	}
+	zap.L().Info("delivering alerts of closed maintenance windows",
+		zap.Int("numSuppressed", len(items)), zap.Int("numOpen", len(alerts)))
+
+	// Route the alerts now, another maintenance window may still be suppressing them
+	alertOutputMap, suppressedStatuses, err := getAlertOutputMap(alerts)
+	if err != nil {
+		return nil, err
+	}
+
+	alertOutputMap, summaries := takeAlertSummaries(alertOutputMap)
+	alertOutputMap, queuedStatuses := throttleDeliveries(alertOutputMap)
+	dispatchStatuses := sendAlerts(ctx, alertOutputMap, outputClient)
+	recordOutputHealth(alertOutputMap, dispatchStatuses)
+	dispatchStatuses = append(dispatchStatuses, sendAlertSummaries(ctx, summaries)...)
+	dispatchStatuses = append(dispatchStatuses, queuedStatuses...)
+	dispatchStatuses = append(dispatchStatuses, suppressedStatuses...)
+
+	updateAlerts(dispatchStatuses)
+	_, failed := filterDispatches(dispatchStatuses)
+	retry(getAlertsToRetry(failed, env.AlertRetryCount), env.AlertQueueURL, env.MinRetryDelaySecs, env.MaxRetryDelaySecs)
+
+	return nil, deleteSuppressedItems(items)
+}
+
+// isAlertOpen - checks if an alert has not been triaged or resolved
+func isAlertOpen(alert *deliverymodel.Alert) (bool, error) {
+	if alert.AlertID == nil {
+		return true, nil
+	}
+	alertItem, err := alertsTableClient.GetAlert(*alert.AlertID)
+	if err != nil {
+		return false, err
+	}
+	// The status of an alert which was never updated is empty
+	return alertItem == nil || alertItem.Status == "" || alertItem.Status == alertModels.OpenStatus, nil
+}
+
+// takeAlertSummaries - takes the deliveries to outputs which can summarize several alerts in one message
+// out of the alert output map, grouped by output
+func takeAlertSummaries(alertOutputs AlertOutputMap) (AlertOutputMap, []*alertSummary) {
+	remaining := make(AlertOutputMap, len(alertOutputs))
+	summaries := make(map[string]*alertSummary)
+	for alert, destinations := range alertOutputs {
+		remaining[alert] = []*outputModels.AlertOutput{}
+		for _, output := range destinations {
+			if !supportsBatching(output) && *output.OutputType != "email" {
+				remaining[alert] = append(remaining[alert], output)
+				continue
+			}
+			summary, ok := summaries[*output.OutputID]
+			if !ok {
+				summary = &alertSummary{Output: output}
+				summaries[*output.OutputID] = summary
+			}
+			summary.Alerts = append(summary.Alerts, alert)
+		}
+	}
+
+	result := make([]*alertSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		sort.Slice(summary.Alerts, func(i, j int) bool {
+			return summary.Alerts[i].CreatedAt.Before(summary.Alerts[j].CreatedAt)
+		})
+		result = append(result, summary)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return *result[i].Output.OutputID < *result[j].Output.OutputID
+	})
+	return remaining, result
+}
+
+// sendAlertSummaries - sends each summary, and returns the delivery status of every alert in it
+func sendAlertSummaries(ctx context.Context, summaries []*alertSummary) []DispatchStatus {
+	dispatchStatuses := []DispatchStatus{}
+	for _, summary := range summaries {
+		dispatchedAt := time.Now().UTC()
+		response := sendAlertSummary(ctx, summary.Output, summary.Alerts)
+		if response == nil {
+			response = &outputs.AlertDeliveryResponse{
+				StatusCode: 500,
+				Message:    "output response is nil",
+				Permanent:  true,
+				Success:    false,
+			}
+		}
+		latency := time.Since(dispatchedAt).Milliseconds()
+		for _, alert := range summary.Alerts {
+			dispatchStatuses = append(dispatchStatuses, DispatchStatus{
+				Alert:         *alert,
+				OutputID:      *summary.Output.OutputID,
+				StatusCode:    response.StatusCode,
+				Success:       response.Success && !response.Permanent,
+				Message:       response.Message,
+				NeedsRetry:    !response.Success && !response.Permanent,
+				DispatchedAt:  dispatchedAt,
+				LatencyMillis: latency,
+			})
+		}
+	}
+	return dispatchStatuses
+}
+
+// scanClosedSuppressions - returns the suppressed alerts whose maintenance window closed
+func scanClosedSuppressions(now time.Time) ([]*suppressedItem, error) {
+	filter := expression.Name("windowEnd").LessThanEqual(expression.Value(now.Unix()))
+	expr, err := expression.NewBuilder().WithFilter(filter).Build()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build suppressed alerts scan")
+	}
+
+	input := &dynamodb.ScanInput{
+		TableName:                 &env.SuppressedAlertsTableName,
+		FilterExpression:          expr.Filter(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ConsistentRead:            aws.Bool(true),
+	}
+	var items []*suppressedItem
+	for len(items) < maxDigestAlerts {
+		page, err := dynamoClient.Scan(input)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan suppressed alerts")
+		}
+		var pageItems []*suppressedItem
+		if err := dynamodbattribute.UnmarshalListOfMaps(page.Items, &pageItems); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal suppressed alerts")
+		}
+		items = append(items, pageItems...)
+		if len(page.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = page.LastEvaluatedKey
+	}
+	// The rest are left for the next run
+	if len(items) > maxDigestAlerts {
+		items = items[:maxDigestAlerts]
+	}
+	return items, nil
+}
+
+// deleteSuppressedItems - removes delivered alerts from the suppressed alerts table
+func deleteSuppressedItems(items []*suppressedItem) error {
+	deleteRequests := make([]*dynamodb.WriteRequest, 0, len(items))
+	for _, item := range items {
+		deleteRequests = append(deleteRequests, &dynamodb.WriteRequest{
+			DeleteRequest: &dynamodb.DeleteRequest{
+				Key: map[string]*dynamodb.AttributeValue{
+					"windowId": {S: aws.String(item.WindowID)},
+					"alertKey": {S: aws.String(item.AlertKey)},
+				},
+			},
+		})
+	}
+	batchInput := &dynamodb.BatchWriteItemInput{
+		RequestItems: map[string][]*dynamodb.WriteRequest{env.SuppressedAlertsTableName: deleteRequests},
+	}
+	return dynamodbbatch.BatchWriteItem(dynamoClient, maxDigestBackoff, batchInput)
+}
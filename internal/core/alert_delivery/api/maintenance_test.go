@@ -0,0 +1,235 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	alertModels "github.com/panther-labs/panther/api/lambda/alerts/models"
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
+	alertTable "github.com/panther-labs/panther/internal/log_analysis/alerts_api/table"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+var maintenanceStart = time.Date(2020, 7, 4, 22, 0, 0, 0, time.UTC)
+
+func genMaintenanceWindow(recurrence string, match outputModels.MaintenanceMatch) *outputModels.MaintenanceWindow {
+	return &outputModels.MaintenanceWindow{
+		WindowID:    "window-id",
+		DisplayName: "patching",
+		Enabled:     true,
+		StartTime:   maintenanceStart,
+		EndTime:     maintenanceStart.Add(4 * time.Hour),
+		Recurrence:  recurrence,
+		Match:       match,
+	}
+}
+
+func suppressedItemAttributes(t *testing.T, alert *deliverymodel.Alert) map[string]*dynamodb.AttributeValue {
+	serializedAlert, err := jsoniter.MarshalToString(alert)
+	require.NoError(t, err)
+	item, err := dynamodbattribute.MarshalMap(&suppressedItem{
+		WindowID: alert.SuppressedBy,
+		AlertKey: alert.SuppressedUntil.Format(digestKeyTimeLayout) + "#" +
+			alert.CreatedAt.Format(digestKeyTimeLayout) + "#" + *alert.AlertID,
+		Alert:     serializedAlert,
+		WindowEnd: alert.SuppressedUntil.Unix(),
+	})
+	require.NoError(t, err)
+	return item
+}
+
+func TestActiveMaintenanceWindow(t *testing.T) {
+	alert := sampleAlert()
+	alert.LogTypes = []string{"AWS.RDS"}
+	once := genMaintenanceWindow(outputModels.RecurrenceNone, outputModels.MaintenanceMatch{LogTypes: []string{"AWS.RDS"}})
+	windows := []*outputModels.MaintenanceWindow{once}
+
+	window, end := activeMaintenanceWindow(alert, windows, maintenanceStart.Add(time.Hour))
+	assert.Equal(t, once, window)
+	assert.Equal(t, maintenanceStart.Add(4*time.Hour), end)
+
+	window, _ = activeMaintenanceWindow(alert, windows, maintenanceStart.Add(4*time.Hour))
+	assert.Nil(t, window)
+	window, _ = activeMaintenanceWindow(alert, windows, maintenanceStart.Add(-time.Minute))
+	assert.Nil(t, window)
+
+	// Test alerts and retries are never suppressed
+	alert.RetryCount = 1
+	window, _ = activeMaintenanceWindow(alert, windows, maintenanceStart.Add(time.Hour))
+	assert.Nil(t, window)
+
+	alert = sampleAlert()
+	alert.LogTypes = []string{"AWS.CloudTrail"}
+	window, _ = activeMaintenanceWindow(alert, windows, maintenanceStart.Add(time.Hour))
+	assert.Nil(t, window)
+
+	once.Enabled = false
+	alert.LogTypes = []string{"AWS.RDS"}
+	window, _ = activeMaintenanceWindow(alert, windows, maintenanceStart.Add(time.Hour))
+	assert.Nil(t, window)
+}
+
+func TestActiveMaintenanceWindowRecurring(t *testing.T) {
+	alert := sampleAlert()
+	weekly := genMaintenanceWindow(outputModels.RecurrenceWeekly, outputModels.MaintenanceMatch{
+		AnalysisIDs: []string{alert.AnalysisID},
+		Severities:  []string{"INFO", "LOW"},
+	})
+	windows := []*outputModels.MaintenanceWindow{weekly}
+
+	nextWeek := maintenanceStart.AddDate(0, 0, 7)
+	window, end := activeMaintenanceWindow(alert, windows, nextWeek.Add(3*time.Hour))
+	assert.Equal(t, weekly, window)
+	assert.Equal(t, nextWeek.Add(4*time.Hour), end)
+
+	window, _ = activeMaintenanceWindow(alert, windows, nextWeek.Add(-time.Hour))
+	assert.Nil(t, window)
+
+	weekly.RecurUntil = aws.Time(nextWeek.Add(-time.Hour))
+	window, _ = activeMaintenanceWindow(alert, windows, nextWeek.Add(time.Hour))
+	assert.Nil(t, window)
+
+	alert.Severity = "HIGH"
+	window, _ = activeMaintenanceWindow(alert, windows, maintenanceStart.Add(time.Hour))
+	assert.Nil(t, window)
+}
+
+func TestShouldSkip(t *testing.T) {
+	alert := sampleAlert()
+	assert.False(t, shouldSkip(alert))
+
+	alert.OutputIds = []string{"output-id", alertOutputSkip}
+	assert.True(t, shouldSkip(alert))
+	assert.Empty(t, alert.SuppressedBy)
+}
+
+func TestGetAlertOutputMapSuppressed(t *testing.T) {
+	mockDynamo := &testutils.DynamoDBMock{}
+	dynamoClient = mockDynamo
+	env.SuppressedAlertsTableName = "suppressed"
+	defer func() { outputsCache = nil }()
+	outputsCache = &alertOutputsCache{
+		Outputs:                  []*outputModels.AlertOutput{genAlertOutput()},
+		Expiry:                   time.Now(),
+		RefreshInterval:          time.Hour,
+		RoutingRulesExpiry:       time.Now(),
+		MaintenanceWindowsExpiry: time.Now(),
+		MaintenanceWindows: []*outputModels.MaintenanceWindow{{
+			WindowID:  "window-id",
+			Enabled:   true,
+			StartTime: time.Now().Add(-time.Hour),
+			EndTime:   time.Now().Add(time.Hour),
+			Match:     outputModels.MaintenanceMatch{Severities: []string{"INFO"}},
+		}},
+	}
+	mockDynamo.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once()
+
+	suppressed, delivered := sampleAlert(), sampleAlert()
+	delivered.AlertID, delivered.Severity = aws.String("delivered-id"), "HIGH"
+	result, statuses, err := getAlertOutputMap([]*deliverymodel.Alert{suppressed, delivered})
+	require.NoError(t, err)
+	assert.Equal(t, AlertOutputMap{delivered: {genAlertOutput()}}, result)
+	mockDynamo.AssertExpectations(t)
+
+	assert.Equal(t, "window-id", suppressed.SuppressedBy)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, *genAlertOutput().OutputID, statuses[0].OutputID)
+	assert.Equal(t, alertModels.DeliverySuppressedStatus, statuses[0].Status)
+	assert.True(t, statuses[0].Success)
+	assert.False(t, statuses[0].NeedsRetry)
+	assert.Equal(t, "suppressed by maintenance window window-id until "+
+		suppressed.SuppressedUntil.UTC().Format(time.RFC3339), statuses[0].Message)
+
+	input := mockDynamo.Calls[0].Arguments.Get(0).(*dynamodb.PutItemInput)
+	assert.Equal(t, "suppressed", *input.TableName)
+	var item suppressedItem
+	require.NoError(t, dynamodbattribute.UnmarshalMap(input.Item, &item))
+	assert.Equal(t, "window-id", item.WindowID)
+	assert.Equal(t, suppressed.SuppressedUntil.Unix(), item.WindowEnd)
+}
+
+func TestSendMaintenanceSummaries(t *testing.T) {
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	mockDynamo := &testutils.DynamoDBMock{}
+	dynamoClient = mockDynamo
+	mockLambda := &testutils.LambdaMock{}
+	lambdaClient = mockLambda
+	alertsTableClient = &alertTable.AlertsTable{AlertsTableName: "alerts", Client: mockDynamo}
+	env.SuppressedAlertsTableName = "suppressed"
+	output := genAlertOutput()
+	defer func() { outputsCache = nil }()
+	outputsCache = &alertOutputsCache{
+		Outputs:                  []*outputModels.AlertOutput{output},
+		Expiry:                   time.Now(),
+		RefreshInterval:          time.Hour,
+		RoutingRulesExpiry:       time.Now(),
+		MaintenanceWindowsExpiry: time.Now(),
+	}
+
+	open, resolved := sampleAlert(), sampleAlert()
+	resolved.AlertID = aws.String("resolved-id")
+	for _, alert := range []*deliverymodel.Alert{open, resolved} {
+		alert.SuppressedBy, alert.SuppressedUntil = "window-id", aws.Time(time.Now().UTC().Add(-time.Minute))
+	}
+	mockDynamo.On("Scan", mock.Anything).Return(&dynamodb.ScanOutput{
+		Items: []map[string]*dynamodb.AttributeValue{suppressedItemAttributes(t, open), suppressedItemAttributes(t, resolved)},
+	}, nil).Once()
+	for id, status := range map[string]string{"alert-id": alertModels.OpenStatus, "resolved-id": alertModels.ResolvedStatus} {
+		id := id
+		item, err := dynamodbattribute.MarshalMap(&alertTable.AlertItem{AlertID: id, Status: status})
+		require.NoError(t, err)
+		mockDynamo.On("GetItem", mock.MatchedBy(func(input *dynamodb.GetItemInput) bool {
+			return *input.Key["id"].S == id
+		})).Return(&dynamodb.GetItemOutput{Item: item}, nil).Once()
+	}
+	ctx := context.Background()
+	mockClient.On("SlackBatch", ctx, mock.MatchedBy(func(alerts []*deliverymodel.Alert) bool {
+		return len(alerts) == 1 && *alerts[0].AlertID == "alert-id" && alerts[0].SuppressedBy == ""
+	}), output.OutputConfig.Slack).Return(&outputs.AlertDeliveryResponse{StatusCode: 200, Success: true}).Once()
+	payload, err := jsoniter.Marshal(alertModels.AlertSummary{AlertID: "alert-id"})
+	require.NoError(t, err)
+	mockLambda.On("Invoke", mock.Anything).Return(&lambda.InvokeOutput{Payload: payload}, nil).Once()
+	mockDynamo.On("BatchWriteItem", mock.Anything).Return(&dynamodb.BatchWriteItemOutput{}, nil).Once()
+
+	_, err = API{}.SendMaintenanceSummaries(ctx, &deliverymodel.SendMaintenanceSummariesInput{})
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+	mockDynamo.AssertExpectations(t)
+	mockLambda.AssertExpectations(t)
+
+	scan := mockDynamo.Calls[0].Arguments.Get(0).(*dynamodb.ScanInput)
+	assert.Equal(t, "suppressed", *scan.TableName)
+	batchInput := mockDynamo.Calls[3].Arguments.Get(0).(*dynamodb.BatchWriteItemInput)
+	assert.Len(t, batchInput.RequestItems["suppressed"], 2)
+}
@@ -31,7 +31,12 @@ import (
 
 const alertOutputSkip = "SKIP"
 
-// getAlertOutputs - Get output ids for an alert by dynmaic destinations, destination overrides, or default severity
+// getAlertOutputs - Get outputs for an alert by the routing table, dynamic destinations, destination overrides,
+// or default severity.
+//
+// A routing rule can escalate the severity of the alert or delay it, by setting its DeliverAfter time.
+// An alert suppressed by a maintenance window gets its SuppressedBy and SuppressedUntil fields set instead,
+// and the outputs it would be delivered to now are returned; it is routed again once the window closes.
 func getAlertOutputs(alert *deliverymodel.Alert) ([]*outputModels.AlertOutput, error) {
 	// fetch all available panther outputs
 	outputs, err := getOutputs()
@@ -39,6 +44,11 @@ func getAlertOutputs(alert *deliverymodel.Alert) ([]*outputModels.AlertOutput, e
 		return nil, err
 	}
 
+	windows, err := getMaintenanceWindows()
+	if err != nil {
+		return nil, err
+	}
+
 	alertOutputs := []*outputModels.AlertOutput{}
 
 	// First, check if we have an override to SKIP dispatching this alert
@@ -46,24 +56,56 @@ func getAlertOutputs(alert *deliverymodel.Alert) ([]*outputModels.AlertOutput, e
 		return alertOutputs, nil
 	}
 
-	// Next, use any dynamic destinations specified (set in the detection's python body)
-	if alertOutputs, ok := getOutputsByDynamicDestinations(alert, outputs); ok {
+	if window, end := activeMaintenanceWindow(alert, windows, time.Now().UTC()); window != nil {
+		alert.SuppressedBy = window.WindowID
+		alert.SuppressedUntil = &end
+	}
+
+	// Retried and delayed alerts have already been routed, they go to the outputs chosen back then
+	if alert.RetryCount > 0 || alert.DeliverAfter != nil {
+		alertOutputs, _ = getOutputsByDestinationOverrides(alert, outputs)
 		return alertOutputs, nil
 	}
 
+	// Next, evaluate the routing table
+	rules, err := getRoutingRules()
+	if err != nil {
+		return nil, err
+	}
+	if result := routeAlert(alert, outputs, rules, time.Now().UTC()); result != nil {
+		if alert.SuppressedBy != "" {
+			return result.Outputs, nil
+		}
+		applyRouting(alert, result)
+		if alert.DeliverAfter != nil {
+			return alertOutputs, nil
+		}
+		return result.Outputs, nil
+	}
+
+	return getDefaultOutputs(alert, outputs), nil
+}
+
+// getDefaultOutputs - Get outputs for an alert which is not matched by the routing table
+func getDefaultOutputs(alert *deliverymodel.Alert, outputs []*outputModels.AlertOutput) []*outputModels.AlertOutput {
+	// Use any dynamic destinations specified (set in the detection's python body)
+	if alertOutputs, ok := getOutputsByDynamicDestinations(alert, outputs); ok {
+		return alertOutputs
+	}
+
 	// Then, use any destination overrides specified (set in the detection's form)
 	if alertOutputs, ok := getOutputsByDestinationOverrides(alert, outputs); ok {
-		return alertOutputs, nil
+		return alertOutputs
 	}
 
 	// If no other dynamic/overrides were set, we calculate based on the severity rating (default)
-	alertOutputs = getOutputsBySeverity(alert, outputs)
+	alertOutputs := getOutputsBySeverity(alert, outputs)
 
 	// Next, we filter out any outputs that don't match the Alert Type setting
 	alertOutputs = filterOutputsByAlertType(alert, alertOutputs)
 
 	// Then, we obtain a list of unique outputs
-	return getUniqueOutputs(alertOutputs), nil
+	return getUniqueOutputs(alertOutputs)
 }
 
 // getOutputs - Gets a list of outputs from panther (using a cache)
@@ -79,6 +121,7 @@ func getOutputs() ([]*outputModels.AlertOutput, error) {
 	return outputsCache.getOutputs(), nil
 }
 
+// shouldSkip - checks if the alert overrides its destinations to SKIP the delivery
 func shouldSkip(alert *deliverymodel.Alert) bool {
 	for _, outputID := range alert.OutputIds {
 		if outputID == alertOutputSkip {
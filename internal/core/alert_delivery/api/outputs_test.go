@@ -75,6 +75,9 @@ func TestGetAlertOutputsFromDynamicDestinations(t *testing.T) {
 	outputsCache = &alertOutputsCache{
 		RefreshInterval: time.Second * time.Duration(30),
 		Expiry:          time.Now().Add(time.Minute * time.Duration(-5)),
+		// No routing rules
+		RoutingRulesExpiry:       time.Now(),
+		MaintenanceWindowsExpiry: time.Now(),
 	}
 	mockClient.On("Invoke", mock.Anything).Return(mockLambdaResponse, nil).Once()
 
@@ -121,6 +124,9 @@ func TestGetAlertOutputsFromDestinationOverrides(t *testing.T) {
 	outputsCache = &alertOutputsCache{
 		RefreshInterval: time.Second * time.Duration(30),
 		Expiry:          time.Now().Add(time.Minute * time.Duration(-5)),
+		// No routing rules
+		RoutingRulesExpiry:       time.Now(),
+		MaintenanceWindowsExpiry: time.Now(),
 	}
 	mockClient.On("Invoke", mock.Anything).Return(mockLambdaResponse, nil).Once()
 
@@ -165,6 +171,9 @@ func TestGetAlertOutputsFromDefaultSeverity(t *testing.T) {
 	outputsCache = &alertOutputsCache{
 		RefreshInterval: time.Second * time.Duration(30),
 		Expiry:          time.Now().Add(time.Minute * time.Duration(-5)),
+		// No routing rules
+		RoutingRulesExpiry:       time.Now(),
+		MaintenanceWindowsExpiry: time.Now(),
 	}
 	mockClient.On("Invoke", mock.Anything).Return(mockLambdaResponse, nil).Once()
 
@@ -208,6 +217,9 @@ func TestGetAlertOutputsFromAlertType(t *testing.T) {
 	outputsCache = &alertOutputsCache{
 		RefreshInterval: time.Second * time.Duration(30),
 		Expiry:          time.Now().Add(time.Minute * time.Duration(-5)),
+		// No routing rules
+		RoutingRulesExpiry:       time.Now(),
+		MaintenanceWindowsExpiry: time.Now(),
 	}
 	mockClient.On("Invoke", mock.Anything).Return(mockLambdaResponse, nil).Once()
 
@@ -260,6 +272,9 @@ func TestGetAlertOutputsIdsError(t *testing.T) {
 	outputsCache = &alertOutputsCache{
 		RefreshInterval: time.Second * time.Duration(30),
 		Expiry:          time.Now().Add(time.Minute * time.Duration(-5)),
+		// No routing rules
+		RoutingRulesExpiry:       time.Now(),
+		MaintenanceWindowsExpiry: time.Now(),
 	}
 	result, err := getAlertOutputs(alert)
 	require.Error(t, err)
@@ -32,7 +32,11 @@ import (
 	"github.com/panther-labs/panther/pkg/awsbatch/sqsbatch"
 )
 
-const maxSQSBackoff = 30 * time.Second
+const (
+	maxSQSBackoff = 30 * time.Second
+	// SQS can hold back a message for at most 15 minutes
+	maxSQSDelay = 15 * time.Minute
+)
 
 // retry - sends a list of alerts back to the queue with random delays.
 func retry(alerts []*deliverymodel.Alert, queueURL string, minDelaySecs int, maxDelaySecs int) {
@@ -47,6 +51,34 @@ func retry(alerts []*deliverymodel.Alert, queueURL string, minDelaySecs int, max
 	sendToSQS(input)
 }
 
+// delay - sends alerts delayed by a routing rule back to the queue until they are due.
+//
+// Alerts delayed for longer than SQS allows come back early and are sent back again.
+func delay(alerts []*deliverymodel.Alert, queueURL string) {
+	if len(alerts) == 0 {
+		return
+	}
+
+	entries := []*sqs.SendMessageBatchRequestEntry{}
+	for i, alert := range alerts {
+		body, err := jsoniter.MarshalToString(alert)
+		if err != nil {
+			zap.L().Panic("error encoding alert as JSON", zap.Error(err))
+		}
+		remaining := time.Until(*alert.DeliverAfter)
+		if remaining > maxSQSDelay {
+			remaining = maxSQSDelay
+		} else if remaining < 0 {
+			remaining = 0
+		}
+		entries = append(entries, createEntry(body, i, int64(remaining.Seconds())))
+	}
+	sendToSQS(&sqs.SendMessageBatchInput{
+		Entries:  entries,
+		QueueUrl: aws.String(queueURL),
+	})
+}
+
 func createInput(alerts []*deliverymodel.Alert, queueURL string, minDelaySecs int, maxDelaySecs int) *sqs.SendMessageBatchInput {
 	return &sqs.SendMessageBatchInput{
 		Entries:  createEntries(alerts, minDelaySecs, maxDelaySecs),
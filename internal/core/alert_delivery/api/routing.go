@@ -0,0 +1,360 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	// Dry run actions for alerts which are not matched by any routing rule
+	routeActionDefault  = "DEFAULT"
+	routeActionSkip     = "SKIP"
+	routeActionSuppress = "SUPPRESS"
+
+	routingTimeLayout = "15:04"
+)
+
+// Severities in increasing order, used to escalate alerts
+var severityLevels = []string{"INFO", "LOW", "MEDIUM", "HIGH", "CRITICAL"}
+
+// Compiled routing patterns, shared by all invocations of the lambda
+var routingPatterns sync.Map
+
+// routingResult - where the routing table sends an alert
+type routingResult struct {
+	// The rules which matched the alert, in evaluation order
+	Rules   []*outputModels.RoutingRule
+	Outputs []*outputModels.AlertOutput
+	// The action of the last matching rule
+	Action   string
+	Severity string
+	Delay    time.Duration
+}
+
+// RouteAlert shows where an alert would be delivered, without sending it.
+func (API) RouteAlert(_ context.Context, input *deliverymodel.RouteAlertInput) (*deliverymodel.RouteAlertOutput, error) {
+	now := time.Now().UTC()
+	if input.Time != nil {
+		now = input.Time.UTC()
+	}
+
+	// Always use the latest outputs, routing table and maintenance windows
+	outputsCache.setExpiry(time.Time{})
+	outputsCache.setRoutingRulesExpiry(time.Time{})
+	outputsCache.setMaintenanceWindowsExpiry(time.Time{})
+	outputs, err := getOutputs()
+	if err != nil {
+		return nil, err
+	}
+	rules, err := getRoutingRules()
+	if err != nil {
+		return nil, err
+	}
+	windows, err := getMaintenanceWindows()
+	if err != nil {
+		return nil, err
+	}
+
+	alert := input.Alert
+	output := &deliverymodel.RouteAlertOutput{
+		MatchedRules: []deliverymodel.RouteAlertMatch{},
+		OutputIDs:    []string{},
+		Severity:     alert.Severity,
+	}
+	var alertOutputs []*outputModels.AlertOutput
+	window, _ := activeMaintenanceWindow(alert, windows, now)
+	switch result := routeAlert(alert, outputs, rules, now); {
+	case shouldSkip(alert):
+		output.Action = routeActionSkip
+	case window != nil:
+		output.Action = routeActionSuppress
+		output.MaintenanceWindowID = window.WindowID
+	case result == nil:
+		output.Action = routeActionDefault
+		alertOutputs = getDefaultOutputs(alert, outputs)
+	default:
+		output.Action = result.Action
+		for _, rule := range result.Rules {
+			output.MatchedRules = append(output.MatchedRules, deliverymodel.RouteAlertMatch{
+				RuleID:      rule.RuleID,
+				DisplayName: rule.DisplayName,
+			})
+		}
+		if result.Severity != "" {
+			output.Severity = result.Severity
+		}
+		if result.Delay > 0 {
+			deliverAfter := now.Add(result.Delay)
+			output.DeliverAfter = &deliverAfter
+		}
+		alertOutputs = result.Outputs
+	}
+
+	for _, alertOutput := range alertOutputs {
+		output.OutputIDs = append(output.OutputIDs, *alertOutput.OutputID)
+	}
+	return output, nil
+}
+
+// getRoutingRules - Gets the routing table from panther (using a cache)
+func getRoutingRules() ([]*outputModels.RoutingRule, error) {
+	if outputsCache.isRoutingRulesExpired() {
+		input := outputModels.LambdaInput{ListRoutingRules: &outputModels.ListRoutingRulesInput{}}
+		var rules outputModels.ListRoutingRulesOutput
+		if err := genericapi.Invoke(lambdaClient, env.OutputsAPI, &input, &rules); err != nil {
+			return nil, err
+		}
+		outputsCache.setRoutingRules(rules)
+		outputsCache.setRoutingRulesExpiry(time.Now().UTC())
+	}
+	return outputsCache.getRoutingRules(), nil
+}
+
+// routeAlert - evaluates the routing table (sorted in evaluation order) for an alert.
+//
+// Returns nil if no rule matched the alert.
+func routeAlert(
+	alert *deliverymodel.Alert,
+	outputs []*outputModels.AlertOutput,
+	rules []*outputModels.RoutingRule,
+	now time.Time,
+) *routingResult {
+
+	var result *routingResult
+	for _, rule := range rules {
+		if !rule.Enabled || !routingRuleMatches(&rule.Match, alert, now) {
+			continue
+		}
+		if result == nil {
+			result = &routingResult{}
+		}
+		result.Rules = append(result.Rules, rule)
+		result.Action = rule.Action.Type
+
+		routed := *alert
+		switch rule.Action.Type {
+		case outputModels.RoutingActionDrop:
+			result.Outputs = getUniqueOutputs(result.Outputs)
+			return result
+		case outputModels.RoutingActionEscalate:
+			result.Severity = escalateSeverity(alert.Severity, rule.Action.Severity)
+			// The default outputs of the escalated alert are the ones of its new severity
+			routed.Severity = result.Severity
+		case outputModels.RoutingActionDelay:
+			result.Delay = time.Duration(rule.Action.DelayMinutes) * time.Minute
+		}
+		result.Outputs = append(result.Outputs, getRoutingRuleOutputs(&routed, &rule.Action, outputs)...)
+
+		if rule.Action.Type != outputModels.RoutingActionSend || !rule.Action.Continue {
+			break
+		}
+	}
+
+	if result != nil {
+		result.Outputs = getUniqueOutputs(result.Outputs)
+	}
+	return result
+}
+
+// applyRouting - updates an alert with the result of routing
+func applyRouting(alert *deliverymodel.Alert, result *routingResult) {
+	zap.L().Debug("routed alert",
+		zap.Stringp("alertId", alert.AlertID),
+		zap.String("action", result.Action),
+		zap.Int("numOutputs", len(result.Outputs)))
+
+	if result.Severity != "" {
+		alert.Severity = result.Severity
+	}
+	if result.Delay > 0 && len(result.Outputs) > 0 {
+		deliverAfter := time.Now().UTC().Add(result.Delay)
+		alert.DeliverAfter = &deliverAfter
+		// The alert is delivered to the outputs chosen now, even if the routing table changes meanwhile
+		alert.OutputIds = make([]string, 0, len(result.Outputs))
+		for _, output := range result.Outputs {
+			alert.OutputIds = append(alert.OutputIds, *output.OutputID)
+		}
+	}
+}
+
+// getRoutingRuleOutputs - the outputs of a routing action, or the default outputs of the alert if it has none
+func getRoutingRuleOutputs(
+	alert *deliverymodel.Alert,
+	action *outputModels.RoutingAction,
+	outputs []*outputModels.AlertOutput,
+) []*outputModels.AlertOutput {
+
+	if len(action.OutputIDs) == 0 {
+		return getDefaultOutputs(alert, outputs)
+	}
+	return intersection(action.OutputIDs, outputs)
+}
+
+// escalateSeverity - the severity of an escalated alert, one level up unless a severity is given
+func escalateSeverity(severity, target string) string {
+	if target != "" {
+		return target
+	}
+	for i, level := range severityLevels[:len(severityLevels)-1] {
+		if level == severity {
+			return severityLevels[i+1]
+		}
+	}
+	return severity
+}
+
+// routingRuleMatches - checks if an alert satisfies all conditions of a routing rule
+func routingRuleMatches(match *outputModels.RoutingMatch, alert *deliverymodel.Alert, now time.Time) bool {
+	if len(match.Severities) > 0 && !containsAny(match.Severities, []string{alert.Severity}) {
+		return false
+	}
+	if len(match.AlertTypes) > 0 && !containsAny(match.AlertTypes, []string{alert.Type}) {
+		return false
+	}
+	if len(match.LogTypes) > 0 && !containsAny(match.LogTypes, alert.LogTypes) {
+		return false
+	}
+	if len(match.Tags) > 0 && !containsAny(match.Tags, alert.Tags) {
+		return false
+	}
+	if match.AnalysisIDPattern != "" && !matchesPattern(match.AnalysisIDPattern, alert.AnalysisID) {
+		return false
+	}
+	for i := range match.Context {
+		if !contextConditionMatches(&match.Context[i], alert.Context) {
+			return false
+		}
+	}
+	if match.TimeWindow != nil && !inTimeWindow(match.TimeWindow, now) {
+		return false
+	}
+	return true
+}
+
+func contextConditionMatches(condition *outputModels.RoutingContextCondition, alertContext map[string]interface{}) bool {
+	var value interface{} = alertContext
+	for _, key := range strings.Split(condition.Path, ".") {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			value = nil
+			break
+		}
+		value = obj[key]
+	}
+
+	switch condition.Operator {
+	case "EXISTS":
+		return value != nil
+	case "EQUALS":
+		return value != nil && fmt.Sprint(value) == condition.Value
+	case "NOT_EQUALS":
+		return value == nil || fmt.Sprint(value) != condition.Value
+	case "CONTAINS":
+		if list, ok := value.([]interface{}); ok {
+			for _, item := range list {
+				if fmt.Sprint(item) == condition.Value {
+					return true
+				}
+			}
+			return false
+		}
+		return value != nil && strings.Contains(fmt.Sprint(value), condition.Value)
+	case "MATCHES":
+		return value != nil && matchesPattern(condition.Value, fmt.Sprint(value))
+	default:
+		return false
+	}
+}
+
+// inTimeWindow - checks if a time is within a daily time window
+func inTimeWindow(window *outputModels.RoutingTimeWindow, now time.Time) bool {
+	location, err := time.LoadLocation(window.Timezone)
+	if err != nil {
+		zap.L().Warn("invalid routing time zone", zap.String("timezone", window.Timezone))
+		return false
+	}
+	start, startErr := time.Parse(routingTimeLayout, window.StartTime)
+	end, endErr := time.Parse(routingTimeLayout, window.EndTime)
+	if startErr != nil || endErr != nil {
+		zap.L().Warn("invalid routing time window",
+			zap.String("start", window.StartTime), zap.String("end", window.EndTime))
+		return false
+	}
+
+	local := now.In(location)
+	minute := local.Hour()*60 + local.Minute()
+	startMinute, endMinute := start.Hour()*60+start.Minute(), end.Hour()*60+end.Minute()
+
+	day := local
+	inWindow := minute >= startMinute && minute < endMinute
+	if startMinute > endMinute {
+		// The window spans midnight. Early morning times belong to the window which started the day before.
+		inWindow = minute >= startMinute || minute < endMinute
+		if minute < endMinute {
+			day = local.AddDate(0, 0, -1)
+		}
+	}
+	if !inWindow {
+		return false
+	}
+
+	if len(window.Days) == 0 {
+		return true
+	}
+	weekday := strings.ToUpper(day.Weekday().String()[:3])
+	return containsAny(window.Days, []string{weekday})
+}
+
+// matchesPattern - matches a regular expression, compiling it only once
+func matchesPattern(pattern, value string) bool {
+	compiled, ok := routingPatterns.Load(pattern)
+	if !ok {
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			zap.L().Warn("invalid routing pattern", zap.String("pattern", pattern), zap.Error(err))
+			return false
+		}
+		compiled, _ = routingPatterns.LoadOrStore(pattern, regex)
+	}
+	return compiled.(*regexp.Regexp).MatchString(value)
+}
+
+// containsAny - checks if any of the values is in the list
+func containsAny(list []string, values []string) bool {
+	for _, item := range list {
+		for _, value := range values {
+			if item == value {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,258 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+var routingOutputs = []*outputModels.AlertOutput{
+	{
+		OutputID:           aws.String("soc-id"),
+		DefaultForSeverity: aws.StringSlice([]string{"CRITICAL"}),
+		AlertTypes:         []string{deliverymodel.RuleType},
+	},
+	{
+		OutputID:           aws.String("team-id"),
+		DefaultForSeverity: aws.StringSlice([]string{"HIGH"}),
+		AlertTypes:         []string{deliverymodel.RuleType},
+	},
+	{
+		OutputID:   aws.String("archive-id"),
+		AlertTypes: []string{deliverymodel.RuleType},
+	},
+}
+
+func genRoutingAlert() *deliverymodel.Alert {
+	return &deliverymodel.Alert{
+		AlertID:    aws.String("alert-id"),
+		AnalysisID: "AWS.Root.Login",
+		Type:       deliverymodel.RuleType,
+		Severity:   "HIGH",
+		LogTypes:   []string{"AWS.CloudTrail"},
+		Tags:       []string{"aws", "iam"},
+		CreatedAt:  time.Now().UTC(),
+		Context: map[string]interface{}{
+			"user":   map[string]interface{}{"department": "finance", "groups": []interface{}{"admins", "users"}},
+			"region": "us-east-1",
+		},
+	}
+}
+
+func genRoutingRule(id string, match outputModels.RoutingMatch, action outputModels.RoutingAction) *outputModels.RoutingRule {
+	return &outputModels.RoutingRule{RuleID: id, DisplayName: id, Enabled: true, Match: match, Action: action}
+}
+
+func routedOutputIDs(result *routingResult) []string {
+	ids := []string{}
+	for _, output := range result.Outputs {
+		ids = append(ids, *output.OutputID)
+	}
+	return ids
+}
+
+func TestRouteAlertNoMatch(t *testing.T) {
+	rules := []*outputModels.RoutingRule{
+		genRoutingRule("critical", outputModels.RoutingMatch{Severities: []string{"CRITICAL"}},
+			outputModels.RoutingAction{Type: outputModels.RoutingActionDrop}),
+	}
+	assert.Nil(t, routeAlert(genRoutingAlert(), routingOutputs, rules, time.Now()))
+}
+
+func TestRouteAlertFirstMatchWins(t *testing.T) {
+	disabled := genRoutingRule("disabled", outputModels.RoutingMatch{},
+		outputModels.RoutingAction{Type: outputModels.RoutingActionDrop})
+	disabled.Enabled = false
+	rules := []*outputModels.RoutingRule{
+		disabled,
+		genRoutingRule("cloudtrail", outputModels.RoutingMatch{LogTypes: []string{"AWS.CloudTrail"}},
+			outputModels.RoutingAction{Type: outputModels.RoutingActionSend, OutputIDs: []string{"archive-id", "missing-id"}}),
+		genRoutingRule("everything", outputModels.RoutingMatch{},
+			outputModels.RoutingAction{Type: outputModels.RoutingActionSend, OutputIDs: []string{"soc-id"}}),
+	}
+
+	result := routeAlert(genRoutingAlert(), routingOutputs, rules, time.Now())
+	require.NotNil(t, result)
+	assert.Equal(t, outputModels.RoutingActionSend, result.Action)
+	assert.Equal(t, []*outputModels.RoutingRule{rules[1]}, result.Rules)
+	assert.Equal(t, []string{"archive-id"}, routedOutputIDs(result))
+}
+
+func TestRouteAlertContinueAndDrop(t *testing.T) {
+	rules := []*outputModels.RoutingRule{
+		genRoutingRule("archive", outputModels.RoutingMatch{},
+			outputModels.RoutingAction{Type: outputModels.RoutingActionSend, OutputIDs: []string{"archive-id"}, Continue: true}),
+		genRoutingRule("defaults", outputModels.RoutingMatch{Tags: []string{"iam"}},
+			outputModels.RoutingAction{Type: outputModels.RoutingActionSend, Continue: true}),
+		genRoutingRule("drop", outputModels.RoutingMatch{AnalysisIDPattern: `^AWS\.Root\.`},
+			outputModels.RoutingAction{Type: outputModels.RoutingActionDrop}),
+		genRoutingRule("never", outputModels.RoutingMatch{},
+			outputModels.RoutingAction{Type: outputModels.RoutingActionSend, OutputIDs: []string{"soc-id"}}),
+	}
+
+	result := routeAlert(genRoutingAlert(), routingOutputs, rules, time.Now())
+	require.NotNil(t, result)
+	assert.Equal(t, outputModels.RoutingActionDrop, result.Action)
+	assert.Len(t, result.Rules, 3)
+	// The defaults of a HIGH alert, plus the archive
+	assert.Equal(t, []string{"archive-id", "team-id"}, routedOutputIDs(result))
+}
+
+func TestRouteAlertEscalate(t *testing.T) {
+	rules := []*outputModels.RoutingRule{
+		genRoutingRule("finance", outputModels.RoutingMatch{
+			Context: []outputModels.RoutingContextCondition{
+				{Path: "user.department", Operator: "EQUALS", Value: "finance"},
+				{Path: "user.groups", Operator: "CONTAINS", Value: "admins"},
+			},
+		}, outputModels.RoutingAction{Type: outputModels.RoutingActionEscalate}),
+	}
+
+	alert := genRoutingAlert()
+	result := routeAlert(alert, routingOutputs, rules, time.Now())
+	require.NotNil(t, result)
+	assert.Equal(t, "CRITICAL", result.Severity)
+	// The default outputs are the ones of the new severity
+	assert.Equal(t, []string{"soc-id"}, routedOutputIDs(result))
+	// The alert is only changed once the result is applied
+	assert.Equal(t, "HIGH", alert.Severity)
+	applyRouting(alert, result)
+	assert.Equal(t, "CRITICAL", alert.Severity)
+	assert.Nil(t, alert.DeliverAfter)
+}
+
+func TestGetAlertOutputsDelayed(t *testing.T) {
+	outputsCache = &alertOutputsCache{
+		Outputs: routingOutputs,
+		RoutingRules: []*outputModels.RoutingRule{
+			genRoutingRule("after-hours", outputModels.RoutingMatch{Severities: []string{"HIGH"}},
+				outputModels.RoutingAction{Type: outputModels.RoutingActionDelay, DelayMinutes: 30, OutputIDs: []string{"team-id"}}),
+		},
+		RefreshInterval:          time.Minute,
+		Expiry:                   time.Now(),
+		RoutingRulesExpiry:       time.Now(),
+		MaintenanceWindowsExpiry: time.Now(),
+	}
+
+	alert := genRoutingAlert()
+	result, err := getAlertOutputs(alert)
+	require.NoError(t, err)
+	assert.Empty(t, result)
+	require.NotNil(t, alert.DeliverAfter)
+	assert.WithinDuration(t, time.Now().Add(30*time.Minute), *alert.DeliverAfter, time.Minute)
+	assert.Equal(t, []string{"team-id"}, alert.OutputIds)
+	assert.True(t, isDelayed(alert))
+
+	// Once due, the alert goes to the outputs chosen when it was delayed
+	alert.DeliverAfter = aws.Time(time.Now().Add(-time.Second))
+	result, err = getAlertOutputs(alert)
+	require.NoError(t, err)
+	assert.Equal(t, []*outputModels.AlertOutput{routingOutputs[1]}, result)
+}
+
+func TestContextConditionMatches(t *testing.T) {
+	alertContext := genRoutingAlert().Context
+	for _, tc := range []struct {
+		condition outputModels.RoutingContextCondition
+		expected  bool
+	}{
+		{outputModels.RoutingContextCondition{Path: "region", Operator: "EXISTS"}, true},
+		{outputModels.RoutingContextCondition{Path: "user.name", Operator: "EXISTS"}, false},
+		{outputModels.RoutingContextCondition{Path: "region", Operator: "NOT_EQUALS", Value: "us-west-2"}, true},
+		{outputModels.RoutingContextCondition{Path: "region.name", Operator: "NOT_EQUALS", Value: "x"}, true},
+		{outputModels.RoutingContextCondition{Path: "region", Operator: "CONTAINS", Value: "east"}, true},
+		{outputModels.RoutingContextCondition{Path: "user.groups", Operator: "CONTAINS", Value: "admin"}, false},
+		{outputModels.RoutingContextCondition{Path: "region", Operator: "MATCHES", Value: `^us-(east|west)-\d$`}, true},
+		{outputModels.RoutingContextCondition{Path: "region", Operator: "MATCHES", Value: `(`}, false},
+	} {
+		assert.Equal(t, tc.expected, contextConditionMatches(&tc.condition, alertContext), tc.condition)
+	}
+}
+
+func TestInTimeWindow(t *testing.T) {
+	// Weekday nights in New York
+	window := &outputModels.RoutingTimeWindow{
+		Days:      []string{"MON", "TUE", "WED", "THU", "FRI"},
+		StartTime: "18:00",
+		EndTime:   "08:00",
+		Timezone:  "America/New_York",
+	}
+
+	// Friday 23:00 in New York
+	assert.True(t, inTimeWindow(window, time.Date(2020, 7, 4, 3, 0, 0, 0, time.UTC)))
+	// Saturday 07:00 belongs to the window which started on Friday
+	assert.True(t, inTimeWindow(window, time.Date(2020, 7, 4, 11, 0, 0, 0, time.UTC)))
+	// Saturday 23:00
+	assert.False(t, inTimeWindow(window, time.Date(2020, 7, 5, 3, 0, 0, 0, time.UTC)))
+	// Monday 12:00
+	assert.False(t, inTimeWindow(window, time.Date(2020, 7, 6, 16, 0, 0, 0, time.UTC)))
+
+	// Business hours in UTC, every day
+	window = &outputModels.RoutingTimeWindow{StartTime: "09:00", EndTime: "17:00"}
+	assert.True(t, inTimeWindow(window, time.Date(2020, 7, 5, 9, 0, 0, 0, time.UTC)))
+	assert.False(t, inTimeWindow(window, time.Date(2020, 7, 5, 17, 0, 0, 0, time.UTC)))
+}
+
+func TestEscalateSeverity(t *testing.T) {
+	assert.Equal(t, "MEDIUM", escalateSeverity("LOW", ""))
+	assert.Equal(t, "CRITICAL", escalateSeverity("CRITICAL", ""))
+	assert.Equal(t, "CRITICAL", escalateSeverity("INFO", "CRITICAL"))
+}
+
+func TestRouteAlertDryRun(t *testing.T) {
+	mockClient := &testutils.LambdaMock{}
+	lambdaClient = mockClient
+	outputsCache = &alertOutputsCache{RefreshInterval: time.Minute}
+
+	outputsPayload, err := jsoniter.Marshal(routingOutputs)
+	require.NoError(t, err)
+	rulesPayload, err := jsoniter.Marshal([]*outputModels.RoutingRule{
+		genRoutingRule("nights", outputModels.RoutingMatch{
+			TimeWindow: &outputModels.RoutingTimeWindow{StartTime: "20:00", EndTime: "06:00"},
+		}, outputModels.RoutingAction{Type: outputModels.RoutingActionDelay, DelayMinutes: 60}),
+	})
+	require.NoError(t, err)
+	mockClient.On("Invoke", invokedWith("getOutputsWithSecrets")).Return(&lambda.InvokeOutput{Payload: outputsPayload}, nil).Once()
+	mockClient.On("Invoke", invokedWith("listRoutingRules")).Return(&lambda.InvokeOutput{Payload: rulesPayload}, nil).Once()
+	mockClient.On("Invoke", invokedWith("listMaintenanceWindows")).Return(&lambda.InvokeOutput{Payload: []byte("[]")}, nil).Once()
+
+	at := time.Date(2020, 7, 1, 22, 0, 0, 0, time.UTC)
+	result, err := (API{}).RouteAlert(context.Background(), &deliverymodel.RouteAlertInput{Alert: genRoutingAlert(), Time: &at})
+	require.NoError(t, err)
+	assert.Equal(t, &deliverymodel.RouteAlertOutput{
+		MatchedRules: []deliverymodel.RouteAlertMatch{{RuleID: "nights", DisplayName: "nights"}},
+		Action:       outputModels.RoutingActionDelay,
+		OutputIDs:    []string{"team-id"},
+		Severity:     "HIGH",
+		DeliverAfter: aws.Time(at.Add(time.Hour)),
+	}, result)
+	mockClient.AssertExpectations(t)
+}
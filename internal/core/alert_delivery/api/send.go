@@ -41,6 +41,11 @@ type DispatchStatus struct {
 	Success      bool
 	NeedsRetry   bool
 	DispatchedAt time.Time
+	TicketID     string
+	// LatencyMillis is how long the output took to respond
+	LatencyMillis int64
+	// Status is set for deliveries which did not reach the output, e.g. SUPPRESSED
+	Status string
 }
 
 // sendAlerts - dispatches alerts to their associated outputIds in parallel
@@ -138,6 +143,23 @@ func sendAlert(
 		}
 	}()
 
+	// Secret references in the config are only resolved when sending, the cached outputs keep them as is
+	resolved, err := outputClient.ResolveSecrets(ctx, output)
+	if err != nil {
+		zap.L().Warn("failed to resolve output secrets", append(commonFields, zap.Error(err))...)
+		statusChannel <- DispatchStatus{
+			Alert:        *alert,
+			OutputID:     *output.OutputID,
+			StatusCode:   500,
+			Success:      false,
+			Message:      "failed to resolve output secrets",
+			NeedsRetry:   true,
+			DispatchedAt: dispatchedAt,
+		}
+		return
+	}
+	output = resolved
+
 	response := (*outputs.AlertDeliveryResponse)(nil)
 	switch *output.OutputType {
 	case "slack":
@@ -160,6 +182,22 @@ func sendAlert(
 		response = outputClient.Asana(ctx, alert, output.OutputConfig.Asana)
 	case "customwebhook":
 		response = outputClient.CustomWebhook(ctx, alert, output.OutputConfig.CustomWebhook)
+	case "email":
+		if isDigestAlert(alert, output.OutputConfig.Email) {
+			response = queueDigestAlert(alert, *output.OutputID)
+		} else {
+			response = outputClient.Email(ctx, alert, output.OutputConfig.Email)
+		}
+	case "servicenow":
+		response = outputClient.ServiceNow(ctx, alert, output.OutputConfig.ServiceNow)
+	case "zendesk":
+		response = outputClient.Zendesk(ctx, alert, output.OutputConfig.Zendesk)
+	case "splunk":
+		response = outputClient.Splunk(ctx, alert, output.OutputConfig.Splunk)
+	case "elasticsearch":
+		response = outputClient.Elasticsearch(ctx, alert, output.OutputConfig.Elasticsearch)
+	case "datadog":
+		response = outputClient.Datadog(ctx, alert, output.OutputConfig.Datadog)
 	default:
 		zap.L().Warn("unsupported output type", commonFields...)
 		statusChannel <- DispatchStatus{
@@ -190,12 +228,14 @@ func sendAlert(
 
 	// Retry only if not successful and we don't have a permanent failure
 	statusChannel <- DispatchStatus{
-		Alert:        *alert,
-		OutputID:     *output.OutputID,
-		StatusCode:   response.StatusCode,
-		Success:      response.Success && !response.Permanent,
-		Message:      response.Message,
-		NeedsRetry:   !response.Success && !response.Permanent,
-		DispatchedAt: dispatchedAt,
+		Alert:         *alert,
+		OutputID:      *output.OutputID,
+		StatusCode:    response.StatusCode,
+		Success:       response.Success && !response.Permanent,
+		Message:       response.Message,
+		NeedsRetry:    !response.Success && !response.Permanent,
+		DispatchedAt:  dispatchedAt,
+		TicketID:      response.TicketID,
+		LatencyMillis: time.Since(dispatchedAt).Milliseconds(),
 	}
 }
@@ -20,6 +20,7 @@ package api
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -104,6 +105,30 @@ func TestSendUnsupportedOutput(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
+func TestSendResolveSecretsFailure(t *testing.T) {
+	mockClient := &mockOutputsClient{resolveSecretsErr: errors.New("AccessDeniedException")}
+	outputClient = mockClient
+
+	ch := make(chan DispatchStatus, 1)
+	alert := sampleAlert()
+	alertOutput := genAlertOutput()
+	dispatchedAt := time.Now().UTC()
+
+	expectedResponse := DispatchStatus{
+		Alert:        *alert,
+		OutputID:     *alertOutput.OutputID,
+		StatusCode:   500,
+		Success:      false,
+		Message:      "failed to resolve output secrets",
+		NeedsRetry:   true,
+		DispatchedAt: dispatchedAt,
+	}
+	ctx := context.Background()
+	go sendAlert(ctx, alert, alertOutput, dispatchedAt, ch, outputClient)
+	assert.Equal(t, expectedResponse, <-ch)
+	mockClient.AssertExpectations(t)
+}
+
 func TestSendResponseNil(t *testing.T) {
 	mockClient := &mockOutputsClient{}
 	outputClient = mockClient
@@ -158,7 +183,10 @@ func TestSendPermanentFailure(t *testing.T) {
 	ctx := context.Background()
 	mockClient.On("Slack", ctx, mock.Anything, mock.Anything).Return(response)
 	go sendAlert(ctx, alert, alertOutput, dispatchedAt, ch, outputClient)
-	assert.Equal(t, expectedResponse, <-ch)
+	status := <-ch
+	// latency depends on the scheduler, it is checked in TestSendSuccess
+	status.LatencyMillis = 0
+	assert.Equal(t, expectedResponse, status)
 	mockClient.AssertExpectations(t)
 }
 
@@ -189,7 +217,10 @@ func TestSendTransientFailure(t *testing.T) {
 	ctx := context.Background()
 	mockClient.On("Slack", ctx, mock.Anything, mock.Anything).Return(response)
 	go sendAlert(ctx, alert, alertOutput, dispatchedAt, ch, outputClient)
-	assert.Equal(t, expectedResponse, <-ch)
+	status := <-ch
+	// latency depends on the scheduler, it is checked in TestSendSuccess
+	status.LatencyMillis = 0
+	assert.Equal(t, expectedResponse, status)
 	mockClient.AssertExpectations(t)
 }
 
@@ -218,9 +249,14 @@ func TestSendSuccess(t *testing.T) {
 		DispatchedAt: dispatchedAt,
 	}
 	ctx := context.Background()
-	mockClient.On("Slack", ctx, mock.Anything, mock.Anything).Return(response)
+	mockClient.On("Slack", ctx, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		time.Sleep(10 * time.Millisecond)
+	}).Return(response)
 	go sendAlert(ctx, alert, alertOutput, dispatchedAt, ch, outputClient)
-	assert.Equal(t, expectedResponse, <-ch)
+	status := <-ch
+	assert.GreaterOrEqual(t, status.LatencyMillis, int64(10))
+	status.LatencyMillis = 0
+	assert.Equal(t, expectedResponse, status)
 	mockClient.AssertExpectations(t)
 }
 
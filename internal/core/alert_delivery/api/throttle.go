@@ -0,0 +1,438 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+const (
+	defaultCircuitCooldown = 5 * time.Minute
+	// While a trial delivery checks if an output recovered, the other deliveries are parked for this long
+	circuitProbeInterval = time.Minute
+	// Deliveries parked behind an open circuit give up once the alert is this old, well within the queue retention
+	maxCircuitParkAge = 24 * time.Hour
+	// Concurrent invocations update the token bucket of an output optimistically and try again on conflicts
+	maxRateLimitAttempts = 5
+)
+
+// outputState is the item kept in the output health table for each output with a delivery policy
+type outputState struct {
+	outputModels.OutputHealth
+	// The token bucket of the rate limit. It goes negative as held back deliveries reserve their slot.
+	Tokens float64 `json:"tokens"`
+	// Epoch milliseconds of the last update of the token bucket
+	TokensUpdatedAt int64 `json:"tokensUpdatedAt"`
+}
+
+// deliveryDecision is the outcome of the delivery policy of an output for one alert
+type deliveryDecision struct {
+	// The alert is held back until this time, zero if it is delivered now
+	deliverAfter time.Time
+	// The held back alert already reserved its slot in the rate limit
+	reserved bool
+	// The alert is queued for the next batch of the output
+	batch bool
+	// The alert was parked behind an open circuit for too long and is not delivered
+	expired bool
+}
+
+// throttleDeliveries - applies the delivery policy of each output before the alerts are sent.
+//
+// Deliveries to an output whose circuit is open, or which is over its rate limit, are put back on the queue
+// without counting as a retry. Deliveries to an output batching its alerts are queued for the next batch.
+// The statuses of queued deliveries and of deliveries which gave up on an open circuit are returned.
+func throttleDeliveries(alertOutputs AlertOutputMap) (AlertOutputMap, []DispatchStatus) {
+	allowed := make(AlertOutputMap, len(alertOutputs))
+	queuedStatuses := []DispatchStatus{}
+	heldBack := []*deliverymodel.Alert{}
+	defer func() {
+		delay(heldBack, env.AlertQueueURL)
+	}()
+
+	states := make(map[string]*outputState)
+	for alert, outputs := range alertOutputs {
+		allowed[alert] = make([]*outputModels.AlertOutput, 0, len(outputs))
+		for _, output := range outputs {
+			if output.DeliveryPolicy == nil {
+				allowed[alert] = append(allowed[alert], output)
+				continue
+			}
+
+			decision, err := checkDeliveryPolicy(alert, output, states, time.Now().UTC())
+			if err != nil {
+				// A duplicate or early delivery is better than a lost alert
+				zap.L().Warn("failed to apply delivery policy, delivering now",
+					zap.Stringp("alertID", alert.AlertID), zap.Stringp("outputID", output.OutputID), zap.Error(err))
+				decision = &deliveryDecision{}
+			}
+
+			switch {
+			case decision.batch:
+				queuedStatuses = append(queuedStatuses, queueBatchAlert(alert, *output.OutputID))
+			case decision.expired:
+				zap.L().Error("gave up delivery to output with open circuit",
+					zap.Stringp("alertID", alert.AlertID), zap.Stringp("outputID", output.OutputID))
+				queuedStatuses = append(queuedStatuses, DispatchStatus{
+					Alert:        *alert,
+					OutputID:     *output.OutputID,
+					Message:      "output circuit open for more than " + maxCircuitParkAge.String(),
+					StatusCode:   http.StatusServiceUnavailable,
+					Success:      false,
+					NeedsRetry:   false,
+					DispatchedAt: time.Now().UTC(),
+				})
+			case !decision.deliverAfter.IsZero():
+				// Create a shallow copy to mutate, the alert may go to other outputs right away
+				heldAlert := *alert
+				heldAlert.OutputIds = []string{*output.OutputID}
+				heldAlert.DeliverAfter = &decision.deliverAfter
+				heldAlert.RateLimited = decision.reserved
+				heldBack = append(heldBack, &heldAlert)
+			default:
+				allowed[alert] = append(allowed[alert], output)
+			}
+		}
+	}
+	return allowed, queuedStatuses
+}
+
+// checkDeliveryPolicy - decides if an alert is delivered to an output now, later or with the next batch
+func checkDeliveryPolicy(
+	alert *deliverymodel.Alert,
+	output *outputModels.AlertOutput,
+	states map[string]*outputState,
+	now time.Time,
+) (*deliveryDecision, error) {
+
+	policy := output.DeliveryPolicy
+	if policy.CircuitBreakerThreshold > 0 {
+		state, err := getOutputState(states, *output.OutputID)
+		if err != nil {
+			return nil, err
+		}
+		parkUntil, err := checkCircuit(state, now)
+		if err != nil {
+			return nil, err
+		}
+		if !parkUntil.IsZero() {
+			if now.Sub(alert.CreatedAt) > maxCircuitParkAge {
+				return &deliveryDecision{expired: true}, nil
+			}
+			return &deliveryDecision{deliverAfter: parkUntil}, nil
+		}
+	}
+
+	if policy.BatchWindowMinutes > 0 && supportsBatching(output) {
+		return &deliveryDecision{batch: true}, nil
+	}
+
+	if policy.RateLimitPerMinute > 0 && !alert.RateLimited {
+		state, err := getOutputState(states, *output.OutputID)
+		if err != nil {
+			return nil, err
+		}
+		wait, reserved, err := reserveRateLimit(state, policy.RateLimitPerMinute, now)
+		if err != nil {
+			return nil, err
+		}
+		if wait > 0 {
+			return &deliveryDecision{deliverAfter: now.Add(wait), reserved: reserved}, nil
+		}
+	}
+	return &deliveryDecision{}, nil
+}
+
+// getOutputState - returns the state of an output, read once per invocation
+func getOutputState(states map[string]*outputState, outputID string) (*outputState, error) {
+	if state, ok := states[outputID]; ok {
+		return state, nil
+	}
+	state, err := readOutputState(outputID)
+	if err != nil {
+		return nil, err
+	}
+	states[outputID] = state
+	return state, nil
+}
+
+// readOutputState - reads the state of an output from the output health table
+func readOutputState(outputID string) (*outputState, error) {
+	result, err := dynamoClient.GetItem(&dynamodb.GetItemInput{
+		TableName:      &env.OutputHealthTableName,
+		Key:            map[string]*dynamodb.AttributeValue{"outputId": {S: aws.String(outputID)}},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read output health")
+	}
+	state := &outputState{}
+	if err := dynamodbattribute.UnmarshalMap(result.Item, state); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal output health")
+	}
+	state.OutputID = outputID
+	return state, nil
+}
+
+// checkCircuit - returns the time deliveries to an output are parked until, zero if the circuit lets them through
+//
+// Once the cooldown of an open circuit has passed, the first delivery is let through as a trial and the
+// circuit is half open until its result is recorded.
+func checkCircuit(state *outputState, now time.Time) (time.Time, error) {
+	if state.CircuitState != outputModels.CircuitOpen && state.CircuitState != outputModels.CircuitHalfOpen {
+		return time.Time{}, nil
+	}
+	if state.OpenUntil != nil && now.Before(*state.OpenUntil) {
+		return *state.OpenUntil, nil
+	}
+
+	trialUntil := now.Add(circuitProbeInterval)
+	condition := expression.Name("circuitState").Equal(expression.Value(state.CircuitState))
+	if state.OpenUntil != nil {
+		condition = condition.And(expression.Name("openUntil").Equal(expression.Value(state.OpenUntil)))
+	}
+	update := expression.
+		Set(expression.Name("circuitState"), expression.Value(outputModels.CircuitHalfOpen)).
+		Set(expression.Name("openUntil"), expression.Value(trialUntil))
+	taken, err := updateOutputState(state.OutputID, update, &condition)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !taken {
+		// Another delivery is the trial
+		return trialUntil, nil
+	}
+
+	zap.L().Info("trying delivery to output with open circuit", zap.String("outputID", state.OutputID))
+	state.CircuitState = outputModels.CircuitHalfOpen
+	state.OpenUntil = &trialUntil
+	return time.Time{}, nil
+}
+
+// reserveRateLimit - takes a token from the bucket of an output and returns how long the delivery must wait for it
+//
+// The bucket holds a minute worth of deliveries and refills continuously. Deliveries over the limit reserve a
+// future token, so held back alerts come back spread out instead of all at once. Deliveries which would wait
+// longer than SQS can hold them back do not reserve anything and try again when they come back.
+func reserveRateLimit(state *outputState, limitPerMinute int, now time.Time) (time.Duration, bool, error) {
+	capacity := float64(limitPerMinute)
+	ratePerSecond := capacity / time.Minute.Seconds()
+	for attempt := 0; attempt < maxRateLimitAttempts; attempt++ {
+		tokens := capacity
+		if state.TokensUpdatedAt != 0 {
+			elapsed := now.Sub(time.Unix(0, state.TokensUpdatedAt*int64(time.Millisecond))).Seconds()
+			tokens = math.Min(capacity, state.Tokens+math.Max(elapsed, 0)*ratePerSecond)
+		}
+		tokens--
+
+		var wait time.Duration
+		if tokens < 0 {
+			wait = time.Duration(-tokens / ratePerSecond * float64(time.Second))
+		}
+		if wait > maxSQSDelay {
+			return maxSQSDelay, false, nil
+		}
+
+		updatedAt := now.UnixNano() / int64(time.Millisecond)
+		condition := expression.Name("tokensUpdatedAt").AttributeNotExists()
+		if state.TokensUpdatedAt != 0 {
+			condition = expression.Name("tokensUpdatedAt").Equal(expression.Value(state.TokensUpdatedAt))
+		}
+		update := expression.
+			Set(expression.Name("tokens"), expression.Value(tokens)).
+			Set(expression.Name("tokensUpdatedAt"), expression.Value(updatedAt))
+		reserved, err := updateOutputState(state.OutputID, update, &condition)
+		if err != nil {
+			return 0, false, err
+		}
+		if reserved {
+			state.Tokens, state.TokensUpdatedAt = tokens, updatedAt
+			return wait, true, nil
+		}
+
+		// Another invocation took a token in the meantime
+		latest, err := readOutputState(state.OutputID)
+		if err != nil {
+			return 0, false, err
+		}
+		*state = *latest
+	}
+	return 0, false, errors.New("too many concurrent updates of the rate limit")
+}
+
+// recordOutputHealth - updates the circuit breaker of each output which has one with the outcome of its deliveries
+//
+// An output which accepted any delivery is considered healthy. Only failures which are retried count towards
+// opening the circuit, permanent failures are caused by the alert or the output configuration.
+func recordOutputHealth(alertOutputs AlertOutputMap, dispatchStatuses []DispatchStatus) {
+	policies := make(map[string]*outputModels.DeliveryPolicy)
+	for _, outputs := range alertOutputs {
+		for _, output := range outputs {
+			if output.DeliveryPolicy != nil && output.DeliveryPolicy.CircuitBreakerThreshold > 0 {
+				policies[*output.OutputID] = output.DeliveryPolicy
+			}
+		}
+	}
+	if len(policies) == 0 {
+		return
+	}
+
+	type outcome struct {
+		successes int
+		failures  int
+		lastError string
+	}
+	outcomes := make(map[string]*outcome)
+	for _, status := range dispatchStatuses {
+		if policies[status.OutputID] == nil {
+			continue
+		}
+		if outcomes[status.OutputID] == nil {
+			outcomes[status.OutputID] = &outcome{}
+		}
+		switch {
+		case status.Success:
+			outcomes[status.OutputID].successes++
+		case status.NeedsRetry:
+			outcomes[status.OutputID].failures++
+			outcomes[status.OutputID].lastError = status.Message
+		}
+	}
+
+	now := time.Now().UTC()
+	for outputID, result := range outcomes {
+		var err error
+		switch {
+		case result.successes > 0:
+			err = recordDeliverySuccess(outputID, now)
+		case result.failures > 0:
+			err = recordDeliveryFailures(outputID, result.failures, result.lastError, policies[outputID], now)
+		}
+		if err != nil {
+			zap.L().Warn("failed to record output health", zap.String("outputID", outputID), zap.Error(err))
+		}
+	}
+}
+
+// recordDeliverySuccess - closes the circuit of an output
+func recordDeliverySuccess(outputID string, now time.Time) error {
+	update := expression.
+		Set(expression.Name("circuitState"), expression.Value(outputModels.CircuitClosed)).
+		Set(expression.Name("consecutiveFailures"), expression.Value(0)).
+		Set(expression.Name("lastSuccessTime"), expression.Value(now)).
+		Remove(expression.Name("openUntil"))
+	_, err := updateOutputState(outputID, update, nil)
+	return err
+}
+
+// recordDeliveryFailures - counts failed deliveries to an output and opens its circuit once they reach the threshold
+func recordDeliveryFailures(
+	outputID string,
+	failures int,
+	lastError string,
+	policy *outputModels.DeliveryPolicy,
+	now time.Time,
+) error {
+
+	update := expression.
+		Add(expression.Name("consecutiveFailures"), expression.Value(failures)).
+		Set(expression.Name("lastError"), expression.Value(lastError)).
+		Set(expression.Name("lastFailureTime"), expression.Value(now))
+	expr, err := expression.NewBuilder().WithUpdate(update).Build()
+	if err != nil {
+		return errors.Wrap(err, "failed to build output health update")
+	}
+	result, err := dynamoClient.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:                 &env.OutputHealthTableName,
+		Key:                       map[string]*dynamodb.AttributeValue{"outputId": {S: aws.String(outputID)}},
+		UpdateExpression:          expr.Update(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ReturnValues:              aws.String(dynamodb.ReturnValueAllNew),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to update output health")
+	}
+	state := &outputState{}
+	if err := dynamodbattribute.UnmarshalMap(result.Attributes, state); err != nil {
+		return errors.Wrap(err, "failed to unmarshal output health")
+	}
+
+	if state.ConsecutiveFailures < policy.CircuitBreakerThreshold {
+		return nil
+	}
+	// Deliveries which were in flight when the circuit opened don't extend its cooldown
+	if state.CircuitState == outputModels.CircuitOpen && state.OpenUntil != nil && now.Before(*state.OpenUntil) {
+		return nil
+	}
+
+	cooldown := time.Duration(policy.CircuitBreakerCooldownMinutes) * time.Minute
+	if cooldown == 0 {
+		cooldown = defaultCircuitCooldown
+	}
+	zap.L().Warn("opening circuit of failing output",
+		zap.String("outputID", outputID), zap.Int("consecutiveFailures", state.ConsecutiveFailures), zap.String("lastError", lastError))
+	open := expression.
+		Set(expression.Name("circuitState"), expression.Value(outputModels.CircuitOpen)).
+		Set(expression.Name("openUntil"), expression.Value(now.Add(cooldown)))
+	_, err = updateOutputState(outputID, open, nil)
+	return err
+}
+
+// updateOutputState - updates the state of an output, returns false if the condition was not met
+func updateOutputState(outputID string, update expression.UpdateBuilder, condition *expression.ConditionBuilder) (bool, error) {
+	builder := expression.NewBuilder().WithUpdate(update)
+	if condition != nil {
+		builder = builder.WithCondition(*condition)
+	}
+	expr, err := builder.Build()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to build output health update")
+	}
+
+	_, err = dynamoClient.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:                 &env.OutputHealthTableName,
+		Key:                       map[string]*dynamodb.AttributeValue{"outputId": {S: aws.String(outputID)}},
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "failed to update output health")
+	}
+	return true, nil
+}
@@ -0,0 +1,274 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+var errConditionFailed = awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "conditional check failed", nil)
+
+func outputStateItem(t *testing.T, state *outputState) *dynamodb.GetItemOutput {
+	item, err := dynamodbattribute.MarshalMap(state)
+	require.NoError(t, err)
+	return &dynamodb.GetItemOutput{Item: item}
+}
+
+func TestThrottleDeliveriesWithoutPolicy(t *testing.T) {
+	mockDynamo := &testutils.DynamoDBMock{}
+	dynamoClient = mockDynamo
+	alert := sampleAlert()
+	alertOutputs := AlertOutputMap{alert: {genAlertOutput()}}
+
+	allowed, queued := throttleDeliveries(alertOutputs)
+	assert.Equal(t, alertOutputs, allowed)
+	assert.Empty(t, queued)
+	mockDynamo.AssertExpectations(t)
+}
+
+func TestThrottleDeliveriesRateLimited(t *testing.T) {
+	mockDynamo := &testutils.DynamoDBMock{}
+	dynamoClient = mockDynamo
+	mockSQS := &testutils.SqsMock{}
+	sqsClient = mockSQS
+	env.OutputHealthTableName = "health"
+
+	output := genAlertOutput()
+	output.DeliveryPolicy = &outputModels.DeliveryPolicy{RateLimitPerMinute: 60}
+	alert := sampleAlert()
+
+	// The bucket was emptied just now
+	mockDynamo.On("GetItem", mock.Anything).Return(outputStateItem(t, &outputState{
+		Tokens:          0,
+		TokensUpdatedAt: time.Now().UnixNano() / int64(time.Millisecond),
+	}), nil).Once()
+	mockDynamo.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+	mockSQS.On("SendMessageBatch", mock.Anything).Return(&sqs.SendMessageBatchOutput{}, nil).Once()
+
+	allowed, queued := throttleDeliveries(AlertOutputMap{alert: {output}})
+	assert.Equal(t, AlertOutputMap{alert: {}}, allowed)
+	assert.Empty(t, queued)
+	mockDynamo.AssertExpectations(t)
+	mockSQS.AssertExpectations(t)
+
+	entries := mockSQS.Calls[0].Arguments.Get(0).(*sqs.SendMessageBatchInput).Entries
+	require.Len(t, entries, 1)
+	heldAlert := &deliverymodel.Alert{}
+	require.NoError(t, jsoniter.UnmarshalFromString(*entries[0].MessageBody, heldAlert))
+	assert.True(t, heldAlert.RateLimited)
+	assert.Equal(t, []string{"output-id"}, heldAlert.OutputIds)
+	assert.Zero(t, heldAlert.RetryCount)
+	assert.WithinDuration(t, time.Now().Add(time.Second), *heldAlert.DeliverAfter, time.Second)
+}
+
+func TestThrottleDeliveriesCircuitParkExpired(t *testing.T) {
+	mockDynamo := &testutils.DynamoDBMock{}
+	dynamoClient = mockDynamo
+	env.OutputHealthTableName = "health"
+
+	output := genAlertOutput()
+	output.DeliveryPolicy = &outputModels.DeliveryPolicy{CircuitBreakerThreshold: 3}
+	alert := sampleAlert()
+	alert.CreatedAt = time.Now().UTC().Add(-maxCircuitParkAge - time.Minute)
+
+	// The output has been down since before the alert was created
+	openUntil := time.Now().UTC().Add(time.Minute)
+	mockDynamo.On("GetItem", mock.Anything).Return(outputStateItem(t, &outputState{
+		OutputHealth: outputModels.OutputHealth{CircuitState: outputModels.CircuitOpen, OpenUntil: &openUntil},
+	}), nil).Once()
+
+	allowed, statuses := throttleDeliveries(AlertOutputMap{alert: {output}})
+	assert.Equal(t, AlertOutputMap{alert: {}}, allowed)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "output-id", statuses[0].OutputID)
+	assert.False(t, statuses[0].Success)
+	assert.False(t, statuses[0].NeedsRetry)
+	assert.Equal(t, 503, statuses[0].StatusCode)
+	mockDynamo.AssertExpectations(t)
+}
+
+func TestReserveRateLimitFullBucket(t *testing.T) {
+	mockDynamo := &testutils.DynamoDBMock{}
+	dynamoClient = mockDynamo
+	mockDynamo.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+
+	state := &outputState{OutputHealth: outputModels.OutputHealth{OutputID: "output-id"}}
+	now := time.Now()
+	wait, reserved, err := reserveRateLimit(state, 10, now)
+	require.NoError(t, err)
+	assert.Zero(t, wait)
+	assert.True(t, reserved)
+	assert.Equal(t, 9.0, state.Tokens)
+	mockDynamo.AssertExpectations(t)
+
+	input := mockDynamo.Calls[0].Arguments.Get(0).(*dynamodb.UpdateItemInput)
+	assert.Equal(t, "attribute_not_exists (#0)", *input.ConditionExpression)
+}
+
+func TestReserveRateLimitConflict(t *testing.T) {
+	mockDynamo := &testutils.DynamoDBMock{}
+	dynamoClient = mockDynamo
+	now := time.Now().Truncate(time.Millisecond)
+	nowMillis := now.UnixNano() / int64(time.Millisecond)
+
+	// Another invocation emptied the bucket in the meantime
+	mockDynamo.On("UpdateItem", mock.Anything).Return((*dynamodb.UpdateItemOutput)(nil), errConditionFailed).Once()
+	mockDynamo.On("GetItem", mock.Anything).Return(outputStateItem(t, &outputState{
+		Tokens:          -2,
+		TokensUpdatedAt: nowMillis,
+	}), nil).Once()
+	mockDynamo.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+
+	state := &outputState{OutputHealth: outputModels.OutputHealth{OutputID: "output-id"}}
+	wait, reserved, err := reserveRateLimit(state, 60, now)
+	require.NoError(t, err)
+	assert.Equal(t, 3*time.Second, wait)
+	assert.True(t, reserved)
+	assert.Equal(t, -3.0, state.Tokens)
+	mockDynamo.AssertExpectations(t)
+}
+
+func TestReserveRateLimitTooFarAhead(t *testing.T) {
+	mockDynamo := &testutils.DynamoDBMock{}
+	dynamoClient = mockDynamo
+	now := time.Now()
+
+	// 20 minutes worth of deliveries are already reserved
+	state := &outputState{Tokens: -20, TokensUpdatedAt: now.UnixNano() / int64(time.Millisecond)}
+	wait, reserved, err := reserveRateLimit(state, 1, now)
+	require.NoError(t, err)
+	assert.Equal(t, maxSQSDelay, wait)
+	assert.False(t, reserved)
+	mockDynamo.AssertExpectations(t)
+}
+
+func TestCheckCircuit(t *testing.T) {
+	mockDynamo := &testutils.DynamoDBMock{}
+	dynamoClient = mockDynamo
+	now := time.Now().UTC()
+
+	parkUntil, err := checkCircuit(&outputState{}, now)
+	require.NoError(t, err)
+	assert.True(t, parkUntil.IsZero())
+
+	openUntil := now.Add(time.Minute)
+	state := &outputState{OutputHealth: outputModels.OutputHealth{CircuitState: outputModels.CircuitOpen, OpenUntil: &openUntil}}
+	parkUntil, err = checkCircuit(state, now)
+	require.NoError(t, err)
+	assert.Equal(t, openUntil, parkUntil)
+	mockDynamo.AssertExpectations(t)
+}
+
+func TestCheckCircuitTrial(t *testing.T) {
+	mockDynamo := &testutils.DynamoDBMock{}
+	dynamoClient = mockDynamo
+	now := time.Now().UTC()
+	mockDynamo.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+
+	// The cooldown has passed, the first delivery is the trial and the next ones are parked
+	openUntil := now.Add(-time.Minute)
+	state := &outputState{OutputHealth: outputModels.OutputHealth{CircuitState: outputModels.CircuitOpen, OpenUntil: &openUntil}}
+	parkUntil, err := checkCircuit(state, now)
+	require.NoError(t, err)
+	assert.True(t, parkUntil.IsZero())
+	assert.Equal(t, outputModels.CircuitHalfOpen, state.CircuitState)
+
+	parkUntil, err = checkCircuit(state, now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(circuitProbeInterval), parkUntil)
+	mockDynamo.AssertExpectations(t)
+}
+
+func TestCheckCircuitTrialTaken(t *testing.T) {
+	mockDynamo := &testutils.DynamoDBMock{}
+	dynamoClient = mockDynamo
+	now := time.Now().UTC()
+	mockDynamo.On("UpdateItem", mock.Anything).Return((*dynamodb.UpdateItemOutput)(nil), errConditionFailed).Once()
+
+	openUntil := now.Add(-time.Minute)
+	state := &outputState{OutputHealth: outputModels.OutputHealth{CircuitState: outputModels.CircuitOpen, OpenUntil: &openUntil}}
+	parkUntil, err := checkCircuit(state, now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(circuitProbeInterval), parkUntil)
+	mockDynamo.AssertExpectations(t)
+}
+
+func TestRecordOutputHealthOpensCircuit(t *testing.T) {
+	mockDynamo := &testutils.DynamoDBMock{}
+	dynamoClient = mockDynamo
+	output := genAlertOutput()
+	output.DeliveryPolicy = &outputModels.DeliveryPolicy{CircuitBreakerThreshold: 3}
+	alert := sampleAlert()
+
+	failures, err := dynamodbattribute.MarshalMap(&outputState{OutputHealth: outputModels.OutputHealth{ConsecutiveFailures: 3}})
+	require.NoError(t, err)
+	mockDynamo.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{Attributes: failures}, nil).Once()
+	mockDynamo.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+
+	recordOutputHealth(AlertOutputMap{alert: {output}}, []DispatchStatus{
+		{Alert: *alert, OutputID: "output-id", StatusCode: 503, Message: "unavailable", NeedsRetry: true},
+		// Permanent failures don't count
+		{Alert: *alert, OutputID: "output-id", StatusCode: 400, Message: "bad request"},
+	})
+	mockDynamo.AssertExpectations(t)
+
+	input := mockDynamo.Calls[0].Arguments.Get(0).(*dynamodb.UpdateItemInput)
+	assert.Contains(t, input.ExpressionAttributeValues, ":0")
+	assert.Equal(t, "1", *input.ExpressionAttributeValues[":0"].N)
+	input = mockDynamo.Calls[1].Arguments.Get(0).(*dynamodb.UpdateItemInput)
+	assert.Contains(t, *input.UpdateExpression, "SET")
+	var values map[string]interface{}
+	require.NoError(t, dynamodbattribute.UnmarshalMap(input.ExpressionAttributeValues, &values))
+	assert.Contains(t, values, ":0")
+	assert.Equal(t, outputModels.CircuitOpen, values[":0"])
+}
+
+func TestRecordOutputHealthSuccess(t *testing.T) {
+	mockDynamo := &testutils.DynamoDBMock{}
+	dynamoClient = mockDynamo
+	output := genAlertOutput()
+	output.DeliveryPolicy = &outputModels.DeliveryPolicy{CircuitBreakerThreshold: 3}
+	alert := sampleAlert()
+	mockDynamo.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+
+	recordOutputHealth(AlertOutputMap{alert: {output}}, []DispatchStatus{
+		{Alert: *alert, OutputID: "output-id", StatusCode: 200, Success: true},
+		{Alert: *alert, OutputID: "output-id", StatusCode: 503, NeedsRetry: true},
+	})
+	mockDynamo.AssertExpectations(t)
+
+	input := mockDynamo.Calls[0].Arguments.Get(0).(*dynamodb.UpdateItemInput)
+	assert.Contains(t, *input.UpdateExpression, "REMOVE")
+	assert.Equal(t, aws.String("output-id"), input.Key["outputId"].S)
+}
@@ -0,0 +1,183 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+
+	alertModels "github.com/panther-labs/panther/api/lambda/alerts/models"
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	// Tickets of alerts older than this are no longer synced
+	ticketSyncLookback = 30 * 24 * time.Hour
+	ticketSyncPageSize = 50
+	// Tickets are checked one at a time, each run checks the tickets of at most this many alerts.
+	// The next run continues with the following alerts.
+	ticketSyncMaxAlerts = 500
+	ticketSyncJob       = "syncTicketStatuses"
+	// Status changes made by the ticket sync are attributed to the system user
+	systemUserID = "00000000-0000-4000-8000-000000000000"
+)
+
+// Alert statuses in triage order. Ticket states only ever move an alert forward, so changes made in
+// Panther are not reverted by a ticket which has not caught up.
+var alertStatusRank = map[string]int{
+	alertModels.OpenStatus:     0,
+	alertModels.TriagedStatus:  1,
+	alertModels.ResolvedStatus: 2,
+	alertModels.ClosedStatus:   2,
+}
+
+// SyncTicketStatuses updates open and triaged alerts to match the state of the tickets created for them.
+//
+// A run stops after a batch of alerts or close to the deadline, and the next run continues from the page
+// it stopped in. Once all the alerts are synced, the next run starts over.
+func (API) SyncTicketStatuses(ctx context.Context, input *deliverymodel.SyncTicketStatusesInput) (interface{}, error) {
+	alertOutputs, err := getOutputs()
+	if err != nil {
+		return nil, err
+	}
+	outputsByID := make(map[string]*outputModels.AlertOutput, len(alertOutputs))
+	for _, output := range alertOutputs {
+		outputsByID[*output.OutputID] = output
+	}
+
+	cursor, err := getJobCursor(ticketSyncJob)
+	if err != nil {
+		return nil, err
+	}
+
+	// Stop checking tickets early enough to update the alerts found so far
+	deadline, hasDeadline := ctx.Deadline()
+	checked := 0
+	stop := func() bool {
+		return checked >= ticketSyncMaxAlerts || (hasDeadline && time.Until(deadline) < 2*softDeadlineDuration)
+	}
+
+	updates := make(map[string][]string) // status -> alert IDs
+	listInput := &alertModels.ListAlertsInput{
+		PageSize:          aws.Int(ticketSyncPageSize),
+		Status:            []string{alertModels.OpenStatus, alertModels.TriagedStatus},
+		CreatedAtAfter:    aws.Time(time.Now().UTC().Add(-ticketSyncLookback)),
+		ExclusiveStartKey: cursor,
+	}
+	cursor = nil
+pages:
+	for {
+		var page alertModels.ListAlertsOutput
+		if err := genericapi.Invoke(lambdaClient, env.AlertsAPI, &alertModels.LambdaInput{ListAlerts: listInput}, &page); err != nil {
+			return nil, err
+		}
+		for _, alert := range page.Alerts {
+			if stop() {
+				// Moving alerts forward again is a no-op, the next run starts with this page
+				cursor = listInput.ExclusiveStartKey
+				break pages
+			}
+			checked++
+			if status := ticketAlertStatus(ctx, alert, outputsByID); status != "" {
+				updates[status] = append(updates[status], alert.AlertID)
+			}
+		}
+		if page.LastEvaluatedKey == nil {
+			break
+		}
+		listInput.ExclusiveStartKey = page.LastEvaluatedKey
+		if stop() {
+			cursor = listInput.ExclusiveStartKey
+			break
+		}
+	}
+
+	// Sorted for a deterministic order of updates
+	statuses := make([]string, 0, len(updates))
+	for status := range updates {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+
+	var result error
+	for _, status := range statuses {
+		zap.L().Info("syncing alert statuses from tickets", zap.String("status", status), zap.Strings("alertIds", updates[status]))
+		updateInput := alertModels.LambdaInput{
+			UpdateAlertStatus: &alertModels.UpdateAlertStatusInput{
+				AlertIDs: updates[status],
+				Status:   status,
+				UserID:   systemUserID,
+			},
+		}
+		var updateOutput alertModels.UpdateAlertStatusOutput
+		if err := genericapi.Invoke(lambdaClient, env.AlertsAPI, &updateInput, &updateOutput); err != nil {
+			result = multierr.Append(result, err)
+		}
+	}
+	return nil, multierr.Append(result, putJobCursor(ticketSyncJob, cursor))
+}
+
+// ticketAlertStatus returns the new status of an alert based on its tickets, or an empty string if it is unchanged.
+//
+// If an alert has several tickets, the most advanced ticket state wins.
+func ticketAlertStatus(ctx context.Context, alert *alertModels.AlertSummary, outputsByID map[string]*outputModels.AlertOutput) string {
+	currentStatus := alert.Status
+	if currentStatus == "" {
+		currentStatus = alertModels.OpenStatus
+	}
+
+	newStatus := ""
+	checked := make(map[string]struct{})
+	for _, response := range alert.DeliveryResponses {
+		if !response.Success || response.TicketID == "" {
+			continue
+		}
+		// Re-sent alerts can list the same ticket more than once
+		key := response.OutputID + "/" + response.TicketID
+		if _, ok := checked[key]; ok {
+			continue
+		}
+		checked[key] = struct{}{}
+
+		output, ok := outputsByID[response.OutputID]
+		if !ok {
+			continue // the output was deleted
+		}
+		status, err := outputClient.TicketStatus(ctx, output, response.TicketID)
+		if err != nil {
+			zap.L().Warn("failed to get ticket status",
+				zap.String("alertId", alert.AlertID), zap.String("outputId", response.OutputID), zap.Error(err))
+			continue
+		}
+		if status == "" {
+			continue
+		}
+		if alertStatusRank[status] > alertStatusRank[currentStatus] && (newStatus == "" || alertStatusRank[status] > alertStatusRank[newStatus]) {
+			newStatus = status
+		}
+	}
+	return newStatus
+}
@@ -0,0 +1,217 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	alertModels "github.com/panther-labs/panther/api/lambda/alerts/models"
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+func genTicketOutput(outputID, outputType string) *outputModels.AlertOutput {
+	return &outputModels.AlertOutput{
+		OutputID:     aws.String(outputID),
+		OutputType:   aws.String(outputType),
+		OutputConfig: &outputModels.OutputConfig{},
+	}
+}
+
+func invokedWith(action string) interface{} {
+	return mock.MatchedBy(func(input *lambda.InvokeInput) bool {
+		return strings.Contains(string(input.Payload), `"`+action+`"`)
+	})
+}
+
+func TestTicketAlertStatus(t *testing.T) {
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	jira, zendesk := genTicketOutput("jira-id", "jira"), genTicketOutput("zendesk-id", "zendesk")
+	outputsByID := map[string]*outputModels.AlertOutput{"jira-id": jira, "zendesk-id": zendesk}
+	ctx := context.Background()
+
+	alert := &alertModels.AlertSummary{
+		AlertID: "alert-id",
+		DeliveryResponses: []*alertModels.DeliveryResponse{
+			{OutputID: "jira-id", Success: true, TicketID: "QR-1"},
+			{OutputID: "jira-id", Success: true, TicketID: "QR-1"}, // re-sent alert
+			{OutputID: "zendesk-id", Success: true, TicketID: "35436"},
+			{OutputID: "deleted-id", Success: true, TicketID: "1"},
+			{OutputID: "slack-id", Success: true},
+			{OutputID: "zendesk-id", Success: false},
+		},
+	}
+	mockClient.On("TicketStatus", ctx, jira, "QR-1").Return("TRIAGED", nil).Once()
+	mockClient.On("TicketStatus", ctx, zendesk, "35436").Return("RESOLVED", nil).Once()
+	assert.Equal(t, "RESOLVED", ticketAlertStatus(ctx, alert, outputsByID))
+	mockClient.AssertExpectations(t)
+
+	// Tickets never move an alert backwards
+	alert.Status = "TRIAGED"
+	mockClient.On("TicketStatus", ctx, jira, "QR-1").Return("OPEN", nil).Once()
+	mockClient.On("TicketStatus", ctx, zendesk, "35436").Return("", errors.New("unauthorized")).Once()
+	assert.Equal(t, "", ticketAlertStatus(ctx, alert, outputsByID))
+	mockClient.AssertExpectations(t)
+}
+
+func TestSyncTicketStatuses(t *testing.T) {
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	mockLambda := &testutils.LambdaMock{}
+	lambdaClient = mockLambda
+	mockDynamo := &testutils.DynamoDBMock{}
+	dynamoClient = mockDynamo
+	env.AlertsAPI = "panther-alerts-api"
+	env.JobCursorsTableName = "cursors"
+	softDeadlineDuration = 10 * time.Second
+	servicenow := genTicketOutput("servicenow-id", "servicenow")
+	outputsCache = &alertOutputsCache{
+		Outputs:         []*outputModels.AlertOutput{servicenow},
+		Expiry:          time.Now().UTC(),
+		RefreshInterval: time.Hour,
+	}
+
+	pages := []alertModels.ListAlertsOutput{
+		{
+			Alerts: []*alertModels.AlertSummary{
+				{
+					AlertID:           "alert-1",
+					DeliveryResponses: []*alertModels.DeliveryResponse{{OutputID: "servicenow-id", Success: true, TicketID: "sys-1"}},
+				},
+			},
+			LastEvaluatedKey: aws.String("alert-1"),
+		},
+		{
+			Alerts: []*alertModels.AlertSummary{
+				{
+					AlertID:           "alert-2",
+					Status:            "TRIAGED",
+					DeliveryResponses: []*alertModels.DeliveryResponse{{OutputID: "servicenow-id", Success: true, TicketID: "sys-2"}},
+				},
+			},
+		},
+	}
+	for _, page := range pages {
+		payload, err := jsoniter.Marshal(page)
+		require.NoError(t, err)
+		mockLambda.On("Invoke", invokedWith("listAlerts")).Return(&lambda.InvokeOutput{Payload: payload}, nil).Once()
+	}
+	mockLambda.On("Invoke", invokedWith("updateAlertStatus")).Return(&lambda.InvokeOutput{Payload: []byte("[]")}, nil).Twice()
+	mockDynamo.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil).Once()
+	// All the alerts were synced, the next run starts over
+	mockDynamo.On("DeleteItem", mock.Anything).Return(&dynamodb.DeleteItemOutput{}, nil).Once()
+
+	ctx := context.Background()
+	mockClient.On("TicketStatus", ctx, servicenow, "sys-1").Return("TRIAGED", nil).Once()
+	mockClient.On("TicketStatus", ctx, servicenow, "sys-2").Return("CLOSED", nil).Once()
+
+	_, err := API{}.SyncTicketStatuses(ctx, &deliverymodel.SyncTicketStatusesInput{})
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+	mockLambda.AssertExpectations(t)
+	mockDynamo.AssertExpectations(t)
+
+	var secondList alertModels.LambdaInput
+	require.NoError(t, jsoniter.Unmarshal(mockLambda.Calls[1].Arguments.Get(0).(*lambda.InvokeInput).Payload, &secondList))
+	assert.Equal(t, []string{"OPEN", "TRIAGED"}, secondList.ListAlerts.Status)
+	assert.Equal(t, aws.String("alert-1"), secondList.ListAlerts.ExclusiveStartKey)
+
+	// Updates are sorted by status
+	var closed, triaged alertModels.LambdaInput
+	require.NoError(t, jsoniter.Unmarshal(mockLambda.Calls[2].Arguments.Get(0).(*lambda.InvokeInput).Payload, &closed))
+	require.NoError(t, jsoniter.Unmarshal(mockLambda.Calls[3].Arguments.Get(0).(*lambda.InvokeInput).Payload, &triaged))
+	assert.Equal(t, &alertModels.UpdateAlertStatusInput{
+		AlertIDs: []string{"alert-2"},
+		Status:   "CLOSED",
+		UserID:   systemUserID,
+	}, closed.UpdateAlertStatus)
+	assert.Equal(t, &alertModels.UpdateAlertStatusInput{
+		AlertIDs: []string{"alert-1"},
+		Status:   "TRIAGED",
+		UserID:   systemUserID,
+	}, triaged.UpdateAlertStatus)
+}
+
+func TestSyncTicketStatusesContinuesFromCursor(t *testing.T) {
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	mockLambda := &testutils.LambdaMock{}
+	lambdaClient = mockLambda
+	mockDynamo := &testutils.DynamoDBMock{}
+	dynamoClient = mockDynamo
+	env.AlertsAPI = "panther-alerts-api"
+	env.JobCursorsTableName = "cursors"
+	softDeadlineDuration = 10 * time.Second
+	outputsCache = &alertOutputsCache{
+		Outputs:         []*outputModels.AlertOutput{genTicketOutput("servicenow-id", "servicenow")},
+		Expiry:          time.Now().UTC(),
+		RefreshInterval: time.Hour,
+	}
+
+	cursor, err := dynamodbattribute.MarshalMap(&jobCursor{Job: ticketSyncJob, ExclusiveStartKey: "alert-1"})
+	require.NoError(t, err)
+	mockDynamo.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: cursor}, nil).Once()
+	payload, err := jsoniter.Marshal(alertModels.ListAlertsOutput{
+		Alerts: []*alertModels.AlertSummary{
+			{
+				AlertID:           "alert-2",
+				DeliveryResponses: []*alertModels.DeliveryResponse{{OutputID: "servicenow-id", Success: true, TicketID: "sys-2"}},
+			},
+		},
+		LastEvaluatedKey: aws.String("alert-2"),
+	})
+	require.NoError(t, err)
+	mockLambda.On("Invoke", invokedWith("listAlerts")).Return(&lambda.InvokeOutput{Payload: payload}, nil).Once()
+	mockDynamo.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once()
+
+	// Too close to the deadline to check any ticket
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	_, err = API{}.SyncTicketStatuses(ctx, &deliverymodel.SyncTicketStatusesInput{})
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+	mockLambda.AssertExpectations(t)
+	mockDynamo.AssertExpectations(t)
+
+	var list alertModels.LambdaInput
+	require.NoError(t, jsoniter.Unmarshal(mockLambda.Calls[0].Arguments.Get(0).(*lambda.InvokeInput).Payload, &list))
+	assert.Equal(t, aws.String("alert-1"), list.ListAlerts.ExclusiveStartKey)
+
+	// The next run starts with the same page
+	var stored jobCursor
+	require.NoError(t, dynamodbattribute.UnmarshalMap(mockDynamo.Calls[1].Arguments.Get(0).(*dynamodb.PutItemInput).Item, &stored))
+	assert.Equal(t, ticketSyncJob, stored.Job)
+	assert.Equal(t, "alert-1", stored.ExclusiveStartKey)
+	assert.Equal(t, "cursors", *mockDynamo.Calls[1].Arguments.Get(0).(*dynamodb.PutItemInput).TableName)
+}
@@ -32,11 +32,16 @@ func updateAlerts(statuses []DispatchStatus) []*alertModels.AlertSummary {
 	for _, status := range statuses {
 		// convert to the response type the lambda expects
 		deliveryResponse := &alertModels.DeliveryResponse{
-			OutputID:     status.OutputID,
-			Message:      status.Message,
-			StatusCode:   status.StatusCode,
-			Success:      status.Success,
-			DispatchedAt: status.DispatchedAt,
+			OutputID:      status.OutputID,
+			Message:       status.Message,
+			StatusCode:    status.StatusCode,
+			Success:       status.Success,
+			DispatchedAt:  status.DispatchedAt,
+			TicketID:      status.TicketID,
+			LatencyMillis: status.LatencyMillis,
+			RetryCount:    status.Alert.RetryCount,
+			Resent:        status.Alert.IsResent,
+			Status:        status.Status,
 		}
 		alertMap[*status.Alert.AlertID] = append(alertMap[*status.Alert.AlertID], deliveryResponse)
 	}
@@ -19,6 +19,7 @@ package api
  */
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -59,18 +60,21 @@ func TestUpdateAlerts(t *testing.T) {
 		},
 		{
 			Alert: deliverymodel.Alert{
-				AlertID:   &alertID,
-				Type:      deliverymodel.PolicyType,
-				OutputIds: outputIds,
-				Severity:  "INFO",
-				CreatedAt: time.Now().UTC(),
+				AlertID:    &alertID,
+				Type:       deliverymodel.PolicyType,
+				OutputIds:  outputIds,
+				Severity:   "INFO",
+				CreatedAt:  time.Now().UTC(),
+				RetryCount: 2,
+				IsResent:   true,
 			},
-			OutputID:     outputIds[1],
-			Message:      "failure",
-			StatusCode:   401,
-			Success:      false,
-			NeedsRetry:   true,
-			DispatchedAt: dispatchedAt,
+			OutputID:      outputIds[1],
+			Message:       "failure",
+			StatusCode:    401,
+			Success:       false,
+			NeedsRetry:    true,
+			DispatchedAt:  dispatchedAt,
+			LatencyMillis: 250,
 		},
 		{
 			Alert: deliverymodel.Alert{
@@ -98,11 +102,14 @@ func TestUpdateAlerts(t *testing.T) {
 			DispatchedAt: dispatchedAt,
 		},
 		{
-			OutputID:     outputIds[1],
-			Message:      "failure",
-			StatusCode:   401,
-			Success:      false,
-			DispatchedAt: dispatchedAt,
+			OutputID:      outputIds[1],
+			Message:       "failure",
+			StatusCode:    401,
+			Success:       false,
+			DispatchedAt:  dispatchedAt,
+			LatencyMillis: 250,
+			RetryCount:    2,
+			Resent:        true,
 		},
 		{
 			OutputID:     outputIds[2],
@@ -127,7 +134,10 @@ func TestUpdateAlerts(t *testing.T) {
 	payload, err := jsoniter.Marshal(expectedLambdaResponse)
 	require.NoError(t, err)
 	mockLambdaResponse := &lambda.InvokeOutput{Payload: payload}
-	mockClient.On("Invoke", mock.Anything).Return(mockLambdaResponse, nil).Times(1)
+	mockClient.On("Invoke", mock.MatchedBy(func(input *lambda.InvokeInput) bool {
+		// The delivery history needs the latency and whether it was a retry or a manual re-delivery
+		return strings.Contains(string(input.Payload), `"latencyMillis":250,"retryCount":2,"resent":true`)
+	})).Return(mockLambdaResponse, nil).Times(1)
 
 	response := updateAlerts(statuses)
 	assert.Equal(t, expectedResponse, response)
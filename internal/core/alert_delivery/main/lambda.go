@@ -39,6 +39,12 @@ var router = genericapi.NewRouter("api", "delivery", nil, api.API{})
 // 1. SQSMessage trigger that takes data from the queue or can be directly invoked
 // 2. HTTP API for re-sending an alert to the specified outputs
 // 3. HTTP API for sending a test alert
+// 4. Scheduled sending of email digests
+// 5. Scheduled sync of alert statuses from ticketing outputs
+// 6. HTTP API for a dry run of alert routing
+// 7. Scheduled sending of alert batches
+// 8. Scheduled delivery of the alerts suppressed by closed maintenance windows
+// 9. Scheduled escalation of alerts which are still open
 func lambdaHandler(ctx context.Context, input json.RawMessage) (output interface{}, err error) {
 	lc, _ := lambdalogger.ConfigureGlobal(ctx, nil)
 	operation := oplog.NewManager("core", "alert_delivery").Start(lc.InvokedFunctionArn).WithMemUsed(lambdacontext.MemoryLimitInMB)
@@ -21,15 +21,18 @@ package outputs
 import (
 	"context"
 	"fmt"
+	"net/url"
 
 	"go.uber.org/zap"
 
+	alertModels "github.com/panther-labs/panther/api/lambda/alerts/models"
 	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
 	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
 )
 
 const (
 	asanaCreateTaskURL             = "https://app.asana.com/api/1.0/tasks"
+	asanaTaskStatusURLFormat       = asanaCreateTaskURL + "/%s?opt_fields=completed"
 	asanaAuthorizationHeaderFormat = "Bearer %s"
 )
 
@@ -50,11 +53,29 @@ func (client *OutputClient) Asana(
 	}
 
 	postInput := &PostInput{
-		url:  asanaCreateTaskURL,
-		body: payload,
-		headers: map[string]string{
-			AuthorizationHTTPHeader: fmt.Sprintf(asanaAuthorizationHeaderFormat, config.PersonalAccessToken),
-		},
+		url:     asanaCreateTaskURL,
+		body:    payload,
+		headers: asanaHeaders(config),
+	}
+	return withTicketID(client.httpWrapper.post(ctx, postInput), "data", "gid")
+}
+
+func (client *OutputClient) asanaTicketStatus(ctx context.Context, config *outputModels.AsanaConfig, taskGid string) (string, error) {
+	completed, err := client.getTicket(ctx, &GetInput{
+		url:     fmt.Sprintf(asanaTaskStatusURLFormat, url.PathEscape(taskGid)),
+		headers: asanaHeaders(config),
+	}, "data", "completed")
+	if err != nil {
+		return "", err
+	}
+	if completed.ToBool() {
+		return alertModels.ResolvedStatus, nil
+	}
+	return alertModels.OpenStatus, nil
+}
+
+func asanaHeaders(config *outputModels.AsanaConfig) map[string]string {
+	return map[string]string{
+		AuthorizationHTTPHeader: fmt.Sprintf(asanaAuthorizationHeaderFormat, config.PersonalAccessToken),
 	}
-	return client.httpWrapper.post(ctx, postInput)
 }
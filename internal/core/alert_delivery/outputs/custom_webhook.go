@@ -20,18 +20,82 @@ package outputs
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+
+	jsoniter "github.com/json-iterator/go"
 
 	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
 	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
 )
 
+const (
+	CustomWebhookAuthBasic  = "BASIC"
+	CustomWebhookAuthBearer = "BEARER"
+	CustomWebhookAuthHMAC   = "HMAC"
+
+	// The header carrying the HMAC signature if the output does not configure one
+	defaultSignatureHeader = "X-Panther-Signature"
+)
+
 // CustomWebhook alert send an alert.
 func (client *OutputClient) CustomWebhook(
 	ctx context.Context, alert *deliverymodel.Alert, config *outputModels.CustomWebhookConfig) *AlertDeliveryResponse {
 
 	postInput := &PostInput{
-		url:  config.WebhookURL,
-		body: generateNotificationFromAlert(alert),
+		url:    config.WebhookURL,
+		method: config.Method,
+	}
+	if len(config.Headers) > 0 || config.AuthType != "" {
+		// Copy the headers, the config is shared with other goroutines
+		postInput.headers = make(map[string]string, len(config.Headers)+1)
+		for key, value := range config.Headers {
+			postInput.headers[key] = value
+		}
+	}
+
+	var err error
+	switch {
+	case config.PayloadTemplate != "":
+		if postInput.rawBody, err = RenderTemplate(config.PayloadTemplate, alert); err != nil {
+			return templateRenderFailure(err)
+		}
+		// Templates can render other formats than JSON. A Content-Type header in the config still wins.
+		if !jsoniter.Valid(postInput.rawBody) {
+			postInput.contentType = http.DetectContentType(postInput.rawBody)
+		}
+	case config.AuthType == CustomWebhookAuthHMAC:
+		// The signature has to be computed over the exact bytes that are sent
+		if postInput.rawBody, err = jsoniter.Marshal(generateNotificationFromAlert(alert)); err != nil {
+			return templateRenderFailure(err)
+		}
+	default:
+		postInput.body = generateNotificationFromAlert(alert)
+	}
+
+	switch config.AuthType {
+	case CustomWebhookAuthBasic:
+		credentials := base64.StdEncoding.EncodeToString([]byte(config.AuthUsername + ":" + config.AuthSecret))
+		postInput.headers[AuthorizationHTTPHeader] = "Basic " + credentials
+	case CustomWebhookAuthBearer:
+		postInput.headers[AuthorizationHTTPHeader] = "Bearer " + config.AuthSecret
+	case CustomWebhookAuthHMAC:
+		header := config.SignatureHeader
+		if header == "" {
+			header = defaultSignatureHeader
+		}
+		postInput.headers[header] = "sha256=" + signPayload(config.AuthSecret, postInput.rawBody)
 	}
+
 	return client.httpWrapper.post(ctx, postInput)
 }
+
+// signPayload returns the hex encoded HMAC-SHA256 of the payload
+func signPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload) // never returns an error
+	return hex.EncodeToString(mac.Sum(nil))
+}
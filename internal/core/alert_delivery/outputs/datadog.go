@@ -0,0 +1,99 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+const (
+	datadogDefaultSite    = "datadoghq.com"
+	datadogEventsEndpoint = "/api/v1/events"
+	datadogMaxTitleLength = 100
+	datadogMaxTextLength  = 4000
+)
+
+// Datadog event alert types by severity
+var datadogAlertType = map[string]string{
+	"CRITICAL": "error",
+	"HIGH":     "error",
+	"MEDIUM":   "warning",
+	"LOW":      "info",
+	"INFO":     "info",
+}
+
+// Datadog sends an alert as an event to Datadog.
+func (client *OutputClient) Datadog(
+	ctx context.Context,
+	alert *deliverymodel.Alert,
+	config *outputModels.DatadogConfig,
+) *AlertDeliveryResponse {
+
+	alertType, ok := datadogAlertType[alert.Severity]
+	if !ok {
+		alertType = "info"
+	}
+	priority := "normal"
+	if alertType == "info" {
+		priority = "low"
+	}
+
+	tags := []string{
+		"source:panther",
+		"severity:" + strings.ToLower(alert.Severity),
+		"alert_type:" + strings.ToLower(alert.Type),
+		"analysis_id:" + alert.AnalysisID,
+	}
+	tags = append(tags, alert.Tags...)
+	tags = append(tags, config.Tags...)
+
+	// Datadog renders the text as markdown if it is wrapped in %%%
+	text := "%%%\n" + truncate(generateDatadogText(alert), datadogMaxTextLength-8) + "\n%%%"
+	event := map[string]interface{}{
+		"title":           truncate(removeNewLines(generateAlertTitle(alert)), datadogMaxTitleLength),
+		"text":            text,
+		"date_happened":   alert.CreatedAt.Unix(),
+		"alert_type":      alertType,
+		"priority":        priority,
+		"aggregation_key": alert.AnalysisID,
+		"tags":            tags,
+	}
+
+	site := config.Site
+	if site == "" {
+		site = datadogDefaultSite
+	}
+	postInput := &PostInput{
+		url:     "https://api." + site + datadogEventsEndpoint,
+		body:    event,
+		headers: map[string]string{"DD-API-KEY": config.APIKey},
+	}
+	return client.httpWrapper.post(ctx, postInput)
+}
+
+// generateDatadogText renders the event body as markdown
+func generateDatadogText(alert *deliverymodel.Alert) string {
+	return fmt.Sprintf("[Click here to view in the Panther UI](%s)\n\n**Severity:** %s\n\n**Runbook:** %s\n\n**Description:** %s",
+		generateURL(alert), alert.Severity, alert.Runbook, alert.AnalysisDescription)
+}
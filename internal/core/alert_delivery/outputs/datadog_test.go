@@ -0,0 +1,74 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/require"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+var datadogConfig = &outputModels.DatadogConfig{
+	APIKey: "dd-api-key",
+	Site:   "datadoghq.eu",
+	Tags:   []string{"team:secops"},
+}
+
+func TestDatadogAlert(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+
+	createdAtTime, _ := time.Parse(time.RFC3339, "2019-08-03T11:40:13Z")
+	alert := &deliverymodel.Alert{
+		AlertID:    aws.String("alertId"),
+		AnalysisID: "policyId",
+		Type:       deliverymodel.PolicyType,
+		CreatedAt:  createdAtTime,
+		Severity:   "MEDIUM",
+		Runbook:    "Rotate the keys",
+		Tags:       []string{"aws"},
+	}
+	expectedPostInput := &PostInput{
+		url: "https://api.datadoghq.eu/api/v1/events",
+		body: map[string]interface{}{
+			"title": "Policy Failure: policyId",
+			"text": "%%%\n[Click here to view in the Panther UI](https://panther.io/alerts/alertId)\n\n" +
+				"**Severity:** MEDIUM\n\n**Runbook:** Rotate the keys\n\n**Description:** \n%%%",
+			"date_happened":   int64(1564832413),
+			"alert_type":      "warning",
+			"priority":        "normal",
+			"aggregation_key": "policyId",
+			"tags": []string{
+				"source:panther", "severity:medium", "alert_type:policy", "analysis_id:policyId", "aws", "team:secops",
+			},
+		},
+		headers: map[string]string{"DD-API-KEY": "dd-api-key"},
+	}
+	ctx := context.Background()
+	httpWrapper.On("post", ctx, expectedPostInput).Return((*AlertDeliveryResponse)(nil))
+
+	require.Nil(t, client.Datadog(ctx, alert, datadogConfig))
+	httpWrapper.AssertExpectations(t)
+}
@@ -0,0 +1,121 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	jsoniter "github.com/json-iterator/go"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+const elasticsearchBulkEndpoint = "/_bulk"
+
+// The document indexed for each alert
+type elasticsearchDocument struct {
+	Notification
+	Timestamp time.Time `json:"@timestamp"`
+}
+
+// Elasticsearch indexes an alert through the bulk API of Elasticsearch or OpenSearch.
+func (client *OutputClient) Elasticsearch(
+	ctx context.Context,
+	alert *deliverymodel.Alert,
+	config *outputModels.ElasticsearchConfig,
+) *AlertDeliveryResponse {
+
+	// The alert ID is used as the document ID, so a retried delivery overwrites the same document
+	action := map[string]interface{}{"_index": config.Index}
+	if alertID := aws.StringValue(alert.AlertID); alertID != "" {
+		action["_id"] = alertID
+	}
+	document := &elasticsearchDocument{
+		Notification: generateNotificationFromAlert(alert),
+		Timestamp:    alert.CreatedAt,
+	}
+
+	// The bulk body is newline delimited JSON, an action line followed by the document
+	actionLine, err := jsoniter.Marshal(map[string]interface{}{"index": action})
+	if err != nil {
+		return elasticsearchMarshalFailure(err)
+	}
+	documentLine, err := jsoniter.Marshal(document)
+	if err != nil {
+		return elasticsearchMarshalFailure(err)
+	}
+	body := bytes.Join([][]byte{actionLine, documentLine, nil}, []byte("\n"))
+
+	postInput := &PostInput{
+		url:     strings.TrimSuffix(config.Endpoint, "/") + elasticsearchBulkEndpoint,
+		rawBody: body,
+		headers: elasticsearchHeaders(config),
+	}
+	return elasticsearchBulkResponse(client.httpWrapper.post(ctx, postInput))
+}
+
+// The bulk API responds with 200 even if indexing failed, the result of each action is in its items
+func elasticsearchBulkResponse(response *AlertDeliveryResponse) *AlertDeliveryResponse {
+	if response == nil || !response.Success {
+		return response
+	}
+
+	result := jsoniter.Get([]byte(response.Message))
+	if !result.Get("errors").ToBool() {
+		return response
+	}
+	item := result.Get("items", 0, "index")
+	status := item.Get("status").ToInt()
+	return &AlertDeliveryResponse{
+		StatusCode: status,
+		Success:    false,
+		Message:    "indexing failed: " + strconv.Itoa(status) + ": " + item.Get("error").ToString(),
+		// A rejected document (mapping conflict, missing index...) is rejected again, unless the cluster is busy
+		Permanent: status >= 400 && status < 500 && status != http.StatusTooManyRequests,
+	}
+}
+
+func elasticsearchMarshalFailure(err error) *AlertDeliveryResponse {
+	return &AlertDeliveryResponse{
+		StatusCode: 500, // Internal server error
+		Success:    false,
+		Message:    "json marshal error: " + err.Error(),
+		Permanent:  true,
+	}
+}
+
+func elasticsearchHeaders(config *outputModels.ElasticsearchConfig) map[string]string {
+	headers := map[string]string{"Content-Type": "application/x-ndjson"}
+	switch {
+	case config.APIKey != "":
+		headers[AuthorizationHTTPHeader] = "ApiKey " + config.APIKey
+	case config.UserName != "":
+		credentials := base64.StdEncoding.EncodeToString([]byte(config.UserName + ":" + config.Password))
+		headers[AuthorizationHTTPHeader] = "Basic " + credentials
+	}
+	return headers
+}
@@ -0,0 +1,130 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+var elasticsearchConfig = &outputModels.ElasticsearchConfig{
+	Endpoint: "https://search.acme.com:9200",
+	Index:    "panther-alerts",
+	APIKey:   "a2V5OnNlY3JldA==",
+}
+
+var elasticsearchAlert = &deliverymodel.Alert{
+	AlertID:    aws.String("alertId"),
+	AnalysisID: "ruleId",
+	Type:       deliverymodel.RuleType,
+	CreatedAt:  time.Date(2020, 7, 1, 12, 0, 0, 0, time.UTC),
+	Severity:   "HIGH",
+}
+
+func TestElasticsearchAlert(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+
+	var postInput *PostInput
+	ctx := context.Background()
+	httpWrapper.On("post", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		postInput = args.Get(1).(*PostInput)
+	}).Return(&AlertDeliveryResponse{
+		StatusCode: 200,
+		Success:    true,
+		Message:    `{"took": 3, "errors": false, "items": [{"index": {"_id": "alertId", "status": 201}}]}`,
+	})
+
+	result := client.Elasticsearch(ctx, elasticsearchAlert, elasticsearchConfig)
+	assert.True(t, result.Success)
+	httpWrapper.AssertExpectations(t)
+
+	assert.Equal(t, "https://search.acme.com:9200/_bulk", postInput.url)
+	assert.Equal(t, map[string]string{
+		"Content-Type":          "application/x-ndjson",
+		AuthorizationHTTPHeader: "ApiKey a2V5OnNlY3JldA==",
+	}, postInput.headers)
+
+	lines := strings.Split(string(postInput.rawBody), "\n")
+	require.Len(t, lines, 3)
+	assert.JSONEq(t, `{"index": {"_index": "panther-alerts", "_id": "alertId"}}`, lines[0])
+	assert.Equal(t, "2020-07-01T12:00:00Z", jsoniter.Get([]byte(lines[1]), "@timestamp").ToString())
+	assert.Equal(t, "New Alert: ruleId", jsoniter.Get([]byte(lines[1]), "title").ToString())
+	assert.Empty(t, lines[2])
+}
+
+func TestElasticsearchItemError(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+
+	ctx := context.Background()
+	httpWrapper.On("post", ctx, mock.Anything).Return(&AlertDeliveryResponse{
+		StatusCode: 200,
+		Success:    true,
+		Message:    `{"errors": true, "items": [{"index": {"status": 429, "error": {"type": "es_rejected_execution_exception"}}}]}`,
+	})
+
+	result := client.Elasticsearch(ctx, elasticsearchAlert, elasticsearchConfig)
+	assert.False(t, result.Success)
+	assert.False(t, result.Permanent)
+	assert.Equal(t, 429, result.StatusCode)
+}
+
+func TestElasticsearchItemRejected(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+
+	ctx := context.Background()
+	httpWrapper.On("post", ctx, mock.Anything).Return(&AlertDeliveryResponse{
+		StatusCode: 200,
+		Success:    true,
+		Message:    `{"errors": true, "items": [{"index": {"status": 400, "error": {"type": "mapper_parsing_exception"}}}]}`,
+	}).Once()
+	httpWrapper.On("post", ctx, mock.Anything).Return(&AlertDeliveryResponse{
+		StatusCode: 200,
+		Success:    true,
+		Message:    `{"errors": true, "items": [{"index": {"status": 503, "error": {"type": "unavailable_shards_exception"}}}]}`,
+	}).Once()
+
+	result := client.Elasticsearch(ctx, elasticsearchAlert, elasticsearchConfig)
+	assert.False(t, result.Success)
+	assert.True(t, result.Permanent)
+	assert.Equal(t, 400, result.StatusCode)
+
+	result = client.Elasticsearch(ctx, elasticsearchAlert, elasticsearchConfig)
+	assert.False(t, result.Success)
+	assert.False(t, result.Permanent)
+	assert.Equal(t, 503, result.StatusCode)
+}
+
+func TestElasticsearchBasicAuth(t *testing.T) {
+	config := &outputModels.ElasticsearchConfig{UserName: "panther", Password: "secret"}
+	assert.Equal(t, "Basic cGFudGhlcjpzZWNyZXQ=", elasticsearchHeaders(config)[AuthorizationHTTPHeader])
+}
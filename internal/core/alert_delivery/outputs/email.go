@@ -0,0 +1,439 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	htmlTemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	textTemplate "text/template"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/pkg/awsutils"
+)
+
+const (
+	// EmailTransportSMTP sends emails through the SMTP server of the output
+	EmailTransportSMTP = "SMTP"
+	// EmailTransportSES sends emails with Amazon SES
+	EmailTransportSES = "SES"
+
+	defaultSMTPPort = 587
+	// SMTPS, the session starts with a TLS handshake instead of STARTTLS
+	implicitTLSPort = 465
+	emailTimeLayout = "2006-01-02 15:04:05 MST"
+)
+
+// Tests can replace these with mock implementations
+var (
+	sendSMTPEmail = smtpSendEmail
+	getSesClient  = buildSesClient
+)
+
+var emailTemplateFuncs = map[string]interface{}{
+	"str":  aws.StringValue,
+	"join": strings.Join,
+	"time": func(t time.Time) string { return t.UTC().Format(emailTimeLayout) },
+	"json": func(v interface{}) string {
+		// Best effort to marshal alert context
+		out, _ := jsoniter.MarshalToString(v)
+		return out
+	},
+}
+
+var alertTextTemplate = textTemplate.Must(textTemplate.New("alert").Funcs(emailTemplateFuncs).Parse(
+	`{{.Title}}
+
+Severity: {{.Severity}}
+Detection: {{str .Name}} ({{.ID}})
+Created at: {{time .CreatedAt}}
+{{- with str .Description}}
+Description: {{.}}
+{{- end}}
+{{- with str .Runbook}}
+Runbook: {{.}}
+{{- end}}
+{{- if .Tags}}
+Tags: {{join .Tags ", "}}
+{{- end}}
+{{- if .AlertContext}}
+Alert context: {{json .AlertContext}}
+{{- end}}
+
+For more details please visit: {{.Link}}
+`))
+
+var alertHTMLTemplate = htmlTemplate.Must(htmlTemplate.New("alert").Funcs(emailTemplateFuncs).Parse(
+	`<html>
+<body style="font-family: Helvetica, Arial, sans-serif;">
+<h2>{{.Title}}</h2>
+<table cellpadding="4">
+<tr><td><b>Severity</b></td><td>{{.Severity}}</td></tr>
+<tr><td><b>Detection</b></td><td>{{str .Name}} ({{.ID}})</td></tr>
+<tr><td><b>Created at</b></td><td>{{time .CreatedAt}}</td></tr>
+{{- with str .Description}}
+<tr><td><b>Description</b></td><td>{{.}}</td></tr>
+{{- end}}
+{{- with str .Runbook}}
+<tr><td><b>Runbook</b></td><td>{{.}}</td></tr>
+{{- end}}
+{{- if .Tags}}
+<tr><td><b>Tags</b></td><td>{{join .Tags ", "}}</td></tr>
+{{- end}}
+{{- if .AlertContext}}
+<tr><td><b>Alert context</b></td><td><pre>{{json .AlertContext}}</pre></td></tr>
+{{- end}}
+</table>
+<p><a href="{{.Link}}">View this alert in Panther</a></p>
+</body>
+</html>
+`))
+
+var digestTextTemplate = textTemplate.Must(textTemplate.New("digest").Funcs(emailTemplateFuncs).Parse(
+	`{{len .}} alerts were batched into this digest:
+{{range .}}
+[{{.Severity}}] {{.Title}}
+Created at: {{time .CreatedAt}}
+{{.Link}}
+{{end}}`))
+
+var digestHTMLTemplate = htmlTemplate.Must(htmlTemplate.New("digest").Funcs(emailTemplateFuncs).Parse(
+	`<html>
+<body style="font-family: Helvetica, Arial, sans-serif;">
+<h2>{{len .}} alerts were batched into this digest</h2>
+<table cellpadding="4">
+<tr><th align="left">Severity</th><th align="left">Alert</th><th align="left">Created at</th></tr>
+{{- range .}}
+<tr><td>{{.Severity}}</td><td><a href="{{.Link}}">{{.Title}}</a></td><td>{{time .CreatedAt}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+// emailMessage is the rendered content of an email, independent of the transport
+type emailMessage struct {
+	subject string
+	text    string
+	html    string
+}
+
+// Email sends an alert to the recipients of an Email output.
+func (client *OutputClient) Email(
+	ctx context.Context,
+	alert *deliverymodel.Alert,
+	config *outputModels.EmailConfig,
+) *AlertDeliveryResponse {
+
+	notification := generateNotificationFromAlert(alert)
+	message, err := renderEmail(notification.Title, &notification, alertTextTemplate, alertHTMLTemplate)
+	if err != nil {
+		return emailRenderFailure(err)
+	}
+	return client.sendEmail(ctx, config, message)
+}
+
+// EmailDigest sends a single email summarizing all of the given alerts to the recipients of an Email output.
+func (client *OutputClient) EmailDigest(
+	ctx context.Context,
+	alerts []*deliverymodel.Alert,
+	config *outputModels.EmailConfig,
+) *AlertDeliveryResponse {
+
+	notifications := make([]Notification, 0, len(alerts))
+	for _, alert := range alerts {
+		notifications = append(notifications, generateNotificationFromAlert(alert))
+	}
+	subject := fmt.Sprintf("Panther Alert Digest: %d alerts", len(alerts))
+	message, err := renderEmail(subject, notifications, digestTextTemplate, digestHTMLTemplate)
+	if err != nil {
+		return emailRenderFailure(err)
+	}
+	return client.sendEmail(ctx, config, message)
+}
+
+func renderEmail(
+	subject string,
+	data interface{},
+	text *textTemplate.Template,
+	html *htmlTemplate.Template,
+) (*emailMessage, error) {
+
+	var textBody, htmlBody strings.Builder
+	if err := text.Execute(&textBody, data); err != nil {
+		return nil, errors.Wrap(err, "failed to render plain-text body")
+	}
+	if err := html.Execute(&htmlBody, data); err != nil {
+		return nil, errors.Wrap(err, "failed to render HTML body")
+	}
+	return &emailMessage{
+		// Newlines in the subject would break the message headers
+		subject: strings.Join(strings.Fields(subject), " "),
+		text:    textBody.String(),
+		html:    htmlBody.String(),
+	}, nil
+}
+
+func emailRenderFailure(err error) *AlertDeliveryResponse {
+	errorMsg := "Failed to render email message"
+	zap.L().Error(errorMsg, zap.Error(errors.WithStack(err)))
+	return &AlertDeliveryResponse{
+		StatusCode: 500,
+		Message:    errorMsg,
+		Permanent:  true,
+		Success:    false,
+	}
+}
+
+func (client *OutputClient) sendEmail(
+	ctx context.Context,
+	config *outputModels.EmailConfig,
+	message *emailMessage,
+) *AlertDeliveryResponse {
+
+	rawMessage, err := message.mime(config, time.Now().UTC())
+	if err != nil {
+		errorMsg := "Failed to build email message"
+		zap.L().Error(errorMsg, zap.Error(errors.WithStack(err)))
+		return &AlertDeliveryResponse{
+			StatusCode: 500,
+			Message:    errorMsg,
+			Permanent:  true,
+			Success:    false,
+		}
+	}
+
+	if config.Transport == EmailTransportSES {
+		response, err := client.sesClients.get(client.session, config.SESRegion).SendRawEmailWithContext(ctx, &ses.SendRawEmailInput{
+			Source:       aws.String(config.FromAddress),
+			Destinations: aws.StringSlice(config.Recipients),
+			RawMessage:   &ses.RawMessage{Data: rawMessage},
+		})
+		if err != nil {
+			zap.L().Warn("failed to send email with SES", zap.Error(err))
+			return getAlertResponseFromSESError(err)
+		}
+		return &AlertDeliveryResponse{
+			StatusCode: 200,
+			Message:    aws.StringValue(response.MessageId),
+			Permanent:  false,
+			Success:    true,
+		}
+	}
+
+	if err := sendSMTPEmail(ctx, config, rawMessage); err != nil {
+		zap.L().Warn("failed to send email through SMTP", zap.Error(err))
+		return getAlertResponseFromSMTPError(err)
+	}
+	return &AlertDeliveryResponse{
+		StatusCode: 200,
+		Message:    "email sent",
+		Permanent:  false,
+		Success:    true,
+	}
+}
+
+// mime builds a multipart/alternative message, so mail clients can pick the HTML or plain-text body
+func (m *emailMessage) mime(config *outputModels.EmailConfig, now time.Time) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := multipart.NewWriter(&buffer)
+
+	fmt.Fprintf(&buffer, "From: %s\r\n", config.FromAddress)
+	fmt.Fprintf(&buffer, "To: %s\r\n", strings.Join(config.Recipients, ", "))
+	fmt.Fprintf(&buffer, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.subject))
+	fmt.Fprintf(&buffer, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	fmt.Fprintf(&buffer, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buffer, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", writer.Boundary())
+
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{contentType: "text/plain; charset=utf-8", body: m.text},
+		{contentType: "text/html; charset=utf-8", body: m.html},
+	}
+	for _, part := range parts {
+		partWriter, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		encoder := quotedprintable.NewWriter(partWriter)
+		if _, err := encoder.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func smtpSendEmail(ctx context.Context, config *outputModels.EmailConfig, message []byte) error {
+	port := config.SMTPPort
+	if port == 0 {
+		port = defaultSMTPPort
+	}
+	address := net.JoinHostPort(config.SMTPHost, strconv.Itoa(port))
+	tlsConfig := &tls.Config{ServerName: config.SMTPHost, MinVersion: tls.VersionTLS12}
+	var conn net.Conn
+	var err error
+	if port == implicitTLSPort {
+		dialer := &tls.Dialer{Config: tlsConfig}
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to SMTP server")
+	}
+	// The SMTP client has no context support, the deadline makes sure we don't outlive the lambda
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			conn.Close()
+			return errors.Wrap(err, "failed to set SMTP connection deadline")
+		}
+	}
+
+	smtpClient, err := smtp.NewClient(conn, config.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return errors.Wrap(err, "failed to start SMTP session")
+	}
+	defer smtpClient.Close()
+
+	if config.SMTPStartTLS && port != implicitTLSPort {
+		if err := smtpClient.StartTLS(tlsConfig); err != nil {
+			return errors.Wrap(err, "STARTTLS failed")
+		}
+	}
+	if config.SMTPUsername != "" {
+		auth := smtp.PlainAuth("", config.SMTPUsername, config.SMTPPassword, config.SMTPHost)
+		if err := smtpClient.Auth(auth); err != nil {
+			return errors.Wrap(err, "SMTP authentication failed")
+		}
+	}
+
+	if err := smtpClient.Mail(config.FromAddress); err != nil {
+		return errors.Wrap(err, "SMTP server rejected the sender")
+	}
+	for _, recipient := range config.Recipients {
+		if err := smtpClient.Rcpt(recipient); err != nil {
+			return errors.Wrapf(err, "SMTP server rejected recipient %s", recipient)
+		}
+	}
+	dataWriter, err := smtpClient.Data()
+	if err != nil {
+		return errors.Wrap(err, "SMTP server rejected the message")
+	}
+	if _, err := dataWriter.Write(message); err != nil {
+		return errors.Wrap(err, "failed to write the message")
+	}
+	if err := dataWriter.Close(); err != nil {
+		return errors.Wrap(err, "SMTP server rejected the message")
+	}
+	return smtpClient.Quit()
+}
+
+// getAlertResponseFromSMTPError - 5xx SMTP replies are permanent failures, anything else can be retried
+func getAlertResponseFromSMTPError(err error) *AlertDeliveryResponse {
+	var smtpErr *textproto.Error
+	if errors.As(err, &smtpErr) && smtpErr.Code >= 500 {
+		return &AlertDeliveryResponse{
+			StatusCode: 400,
+			Message:    err.Error(),
+			Permanent:  true,
+			Success:    false,
+		}
+	}
+	return getResponse(500, err.Error())
+}
+
+// getAlertResponseFromSESError - rejected messages and unverified senders won't succeed on retry
+func getAlertResponseFromSESError(err error) *AlertDeliveryResponse {
+	if awsutils.IsAnyError(err, ses.ErrCodeMessageRejected, ses.ErrCodeMailFromDomainNotVerifiedException) {
+		return &AlertDeliveryResponse{
+			StatusCode: 400,
+			Message:    err.Error(),
+			Permanent:  true,
+			Success:    false,
+		}
+	}
+	return getResponse(500, err.Error())
+}
+
+// sesClientCache holds an SES client per region, shared by concurrent deliveries.
+//
+// A nil cache builds a client on every use.
+type sesClientCache struct {
+	mu      sync.Mutex
+	clients map[string]sesiface.SESAPI
+}
+
+func newSesClientCache() *sesClientCache {
+	return &sesClientCache{clients: make(map[string]sesiface.SESAPI)}
+}
+
+func (cache *sesClientCache) get(awsSession *session.Session, region string) sesiface.SESAPI {
+	if cache == nil {
+		return getSesClient(awsSession, region)
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	sesClient, ok := cache.clients[region]
+	if !ok {
+		sesClient = getSesClient(awsSession, region)
+		cache.clients[region] = sesClient
+	}
+	return sesClient
+}
+
+func buildSesClient(awsSession *session.Session, region string) sesiface.SESAPI {
+	if region == "" {
+		return ses.New(awsSession)
+	}
+	return ses.New(awsSession, aws.NewConfig().WithRegion(region))
+}
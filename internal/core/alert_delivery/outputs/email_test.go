@@ -0,0 +1,248 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+type mockSesClient struct {
+	sesiface.SESAPI
+	mock.Mock
+}
+
+func (m *mockSesClient) SendRawEmailWithContext(
+	ctx aws.Context,
+	input *ses.SendRawEmailInput,
+	options ...request.Option,
+) (*ses.SendRawEmailOutput, error) {
+
+	args := m.Called(ctx, input)
+	return args.Get(0).(*ses.SendRawEmailOutput), args.Error(1)
+}
+
+func sampleEmailAlert() *deliverymodel.Alert {
+	return &deliverymodel.Alert{
+		AlertID:             aws.String("alertId"),
+		AnalysisID:          "ruleId",
+		AnalysisName:        aws.String("Rule <Name>"),
+		AnalysisDescription: "ruleDescription",
+		Type:                deliverymodel.RuleType,
+		Title:               "Suspicious login",
+		Severity:            "LOW",
+		Runbook:             "check the user",
+		Tags:                []string{"tag1", "tag2"},
+		CreatedAt:           time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC),
+		Context:             map[string]interface{}{"key": "value"},
+	}
+}
+
+// parseEmail returns the headers and the decoded plain-text and HTML bodies of a raw message
+func parseEmail(t *testing.T, raw []byte) (mail.Header, string, string) {
+	message, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	require.NoError(t, err)
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+
+	bodies := make(map[string]string)
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			break
+		}
+		body, err := ioutil.ReadAll(part) // quoted-printable is decoded by the reader
+		require.NoError(t, err)
+		// Line breaks are encoded as CRLF
+		bodies[part.Header.Get("Content-Type")] = strings.ReplaceAll(string(body), "\r\n", "\n")
+	}
+	return message.Header, bodies["text/plain; charset=utf-8"], bodies["text/html; charset=utf-8"]
+}
+
+func TestEmailSMTP(t *testing.T) {
+	config := &outputModels.EmailConfig{
+		Transport:   EmailTransportSMTP,
+		FromAddress: "panther@example.com",
+		Recipients:  []string{"a@example.com", "b@example.com"},
+		SMTPHost:    "smtp.example.com",
+	}
+	var sent []byte
+	sendSMTPEmail = func(_ context.Context, smtpConfig *outputModels.EmailConfig, message []byte) error {
+		assert.Equal(t, config, smtpConfig)
+		sent = message
+		return nil
+	}
+
+	result := (&OutputClient{}).Email(context.Background(), sampleEmailAlert(), config)
+	assert.Equal(t, &AlertDeliveryResponse{StatusCode: 200, Message: "email sent", Success: true}, result)
+
+	header, text, html := parseEmail(t, sent)
+	assert.Equal(t, "panther@example.com", header.Get("From"))
+	assert.Equal(t, "a@example.com, b@example.com", header.Get("To"))
+	assert.Equal(t, "New Alert: Suspicious login", header.Get("Subject"))
+
+	assert.Contains(t, text, "Severity: LOW\n")
+	assert.Contains(t, text, "Detection: Rule <Name> (ruleId)\n")
+	assert.Contains(t, text, "Created at: 2020-01-01 12:00:00 UTC\n")
+	assert.Contains(t, text, "Tags: tag1, tag2\n")
+	assert.Contains(t, text, "Alert context: {\"key\":\"value\"}\n")
+	assert.Contains(t, text, "For more details please visit: https://panther.io/alerts/alertId\n")
+
+	// HTML values are escaped
+	assert.Contains(t, html, "<td>Rule &lt;Name&gt; (ruleId)</td>")
+	assert.Contains(t, html, `<a href="https://panther.io/alerts/alertId">`)
+}
+
+func TestEmailSMTPErrors(t *testing.T) {
+	config := &outputModels.EmailConfig{Transport: EmailTransportSMTP}
+	client := &OutputClient{}
+
+	sendSMTPEmail = func(context.Context, *outputModels.EmailConfig, []byte) error {
+		return &textproto.Error{Code: 535, Msg: "authentication failed"}
+	}
+	result := client.Email(context.Background(), sampleEmailAlert(), config)
+	assert.Equal(t, 400, result.StatusCode)
+	assert.True(t, result.Permanent)
+
+	sendSMTPEmail = func(context.Context, *outputModels.EmailConfig, []byte) error {
+		return &textproto.Error{Code: 421, Msg: "try again later"}
+	}
+	result = client.Email(context.Background(), sampleEmailAlert(), config)
+	assert.Equal(t, 500, result.StatusCode)
+	assert.False(t, result.Permanent)
+	assert.False(t, result.Success)
+
+	sendSMTPEmail = func(context.Context, *outputModels.EmailConfig, []byte) error {
+		return errors.New("connection refused")
+	}
+	result = client.Email(context.Background(), sampleEmailAlert(), config)
+	assert.Equal(t, getResponse(500, "connection refused"), result)
+}
+
+func TestEmailSES(t *testing.T) {
+	config := &outputModels.EmailConfig{
+		Transport:   EmailTransportSES,
+		FromAddress: "panther@example.com",
+		Recipients:  []string{"a@example.com"},
+		SESRegion:   "eu-west-1",
+	}
+	client := &mockSesClient{}
+	getSesClient = func(_ *session.Session, region string) sesiface.SESAPI {
+		assert.Equal(t, "eu-west-1", region)
+		return client
+	}
+	ctx := context.Background()
+	client.On("SendRawEmailWithContext", ctx, mock.Anything).Return(&ses.SendRawEmailOutput{MessageId: aws.String("messageId")}, nil).Once()
+
+	result := (&OutputClient{}).Email(ctx, sampleEmailAlert(), config)
+	assert.Equal(t, &AlertDeliveryResponse{StatusCode: 200, Message: "messageId", Success: true}, result)
+	client.AssertExpectations(t)
+
+	input := client.Calls[0].Arguments.Get(1).(*ses.SendRawEmailInput)
+	assert.Equal(t, "panther@example.com", *input.Source)
+	assert.Equal(t, []*string{aws.String("a@example.com")}, input.Destinations)
+	header, _, _ := parseEmail(t, input.RawMessage.Data)
+	assert.Equal(t, "New Alert: Suspicious login", header.Get("Subject"))
+
+	client.On("SendRawEmailWithContext", ctx, mock.Anything).
+		Return((*ses.SendRawEmailOutput)(nil), awserr.New(ses.ErrCodeMessageRejected, "rejected", nil)).Once()
+	result = (&OutputClient{}).Email(ctx, sampleEmailAlert(), config)
+	assert.Equal(t, 400, result.StatusCode)
+	assert.True(t, result.Permanent)
+}
+
+func TestEmailSESClientCache(t *testing.T) {
+	built := map[string]int{}
+	getSesClient = func(_ *session.Session, region string) sesiface.SESAPI {
+		built[region]++
+		return &mockSesClient{}
+	}
+
+	cache := newSesClientCache()
+	euClient := cache.get(nil, "eu-west-1")
+	assert.Same(t, euClient, cache.get(nil, "eu-west-1"))
+	assert.NotSame(t, euClient, cache.get(nil, "us-east-1"))
+	assert.Equal(t, map[string]int{"eu-west-1": 1, "us-east-1": 1}, built)
+}
+
+func TestEmailDigest(t *testing.T) {
+	config := &outputModels.EmailConfig{
+		Transport:   EmailTransportSMTP,
+		FromAddress: "panther@example.com",
+		Recipients:  []string{"a@example.com"},
+		SMTPHost:    "smtp.example.com",
+	}
+	var sent []byte
+	sendSMTPEmail = func(_ context.Context, _ *outputModels.EmailConfig, message []byte) error {
+		sent = message
+		return nil
+	}
+
+	first, second := sampleEmailAlert(), sampleEmailAlert()
+	second.AlertID = aws.String("otherAlertId")
+	second.Title = "Another\ntitle"
+	second.Severity = "INFO"
+
+	result := (&OutputClient{}).EmailDigest(context.Background(), []*deliverymodel.Alert{first, second}, config)
+	require.True(t, result.Success)
+
+	header, text, html := parseEmail(t, sent)
+	assert.Equal(t, "Panther Alert Digest: 2 alerts", header.Get("Subject"))
+	assert.True(t, strings.HasPrefix(text, "2 alerts were batched into this digest:\n"))
+	assert.Contains(t, text, "[LOW] New Alert: Suspicious login\n")
+	assert.Contains(t, text, "https://panther.io/alerts/otherAlertId\n")
+	assert.Contains(t, html, `<a href="https://panther.io/alerts/otherAlertId">New Alert: Another
+title</a>`)
+}
+
+func TestEmailSubjectEncoding(t *testing.T) {
+	message := &emailMessage{subject: "Alert: ünïcode", text: "text", html: "<p>html</p>"}
+	raw, err := message.mime(&outputModels.EmailConfig{FromAddress: "a@example.com", Recipients: []string{"b@example.com"}}, time.Now())
+	require.NoError(t, err)
+
+	header, text, html := parseEmail(t, raw)
+	subject, err := new(mime.WordDecoder).DecodeHeader(header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Alert: ünïcode", subject)
+	assert.Equal(t, "text", text)
+	assert.Equal(t, "<p>html</p>", html)
+}
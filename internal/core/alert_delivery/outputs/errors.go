@@ -33,6 +33,9 @@ type AlertDeliveryResponse struct {
 
 	// Success is true if we determine the request executed successfully. False otherwise.
 	Success bool
+
+	// TicketID is the ID of the ticket created by ticketing outputs
+	TicketID string
 }
 
 func (e *AlertDeliveryResponse) Error() string { return e.Message }
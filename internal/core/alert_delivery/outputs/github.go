@@ -20,10 +20,12 @@ package outputs
 
 import (
 	"context"
+	"net/url"
 	"strings"
 
 	jsoniter "github.com/json-iterator/go"
 
+	alertModels "github.com/panther-labs/panther/api/lambda/alerts/models"
 	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
 	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
 )
@@ -52,16 +54,31 @@ func (client *OutputClient) Github(
 		"body":  description + link + runBook + severity + tags + alertContext,
 	}
 
-	token := "token " + config.Token
 	repoURL := githubEndpoint + config.RepoName + requestType
-	requestHeader := map[string]string{
-		AuthorizationHTTPHeader: token,
-	}
-
 	postInput := &PostInput{
 		url:     repoURL,
 		body:    githubRequest,
-		headers: requestHeader,
+		headers: githubHeaders(config),
+	}
+	return withTicketID(client.httpWrapper.post(ctx, postInput), "number")
+}
+
+func (client *OutputClient) githubTicketStatus(ctx context.Context, config *outputModels.GithubConfig, number string) (string, error) {
+	state, err := client.getTicket(ctx, &GetInput{
+		url:     githubEndpoint + config.RepoName + requestType + "/" + url.PathEscape(number),
+		headers: githubHeaders(config),
+	}, "state")
+	if err != nil {
+		return "", err
+	}
+	if state.ToString() == "closed" {
+		return alertModels.ResolvedStatus, nil
+	}
+	return alertModels.OpenStatus, nil
+}
+
+func githubHeaders(config *outputModels.GithubConfig) map[string]string {
+	return map[string]string{
+		AuthorizationHTTPHeader: "token " + config.Token,
 	}
-	return client.httpWrapper.post(ctx, postInput)
 }
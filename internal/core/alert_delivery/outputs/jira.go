@@ -21,11 +21,13 @@ package outputs
 import (
 	"context"
 	"encoding/base64"
+	"net/url"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	jsoniter "github.com/json-iterator/go"
 
+	alertModels "github.com/panther-labs/panther/api/lambda/alerts/models"
 	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
 	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
 )
@@ -71,17 +73,38 @@ func (client *OutputClient) Jira(
 		"fields": fields,
 	}
 
-	auth := config.UserName + ":" + config.APIKey
-	basicAuthToken := "Basic " + base64.StdEncoding.EncodeToString([]byte(auth))
 	jiraRestURL := config.OrgDomain + jiraEndpoint
-	requestHeader := map[string]string{
-		AuthorizationHTTPHeader: basicAuthToken,
-	}
-
 	postInput := &PostInput{
 		url:     jiraRestURL,
 		body:    jiraRequest,
-		headers: requestHeader,
+		headers: jiraHeaders(config),
+	}
+	return withTicketID(client.httpWrapper.post(ctx, postInput), "key")
+}
+
+func (client *OutputClient) jiraTicketStatus(ctx context.Context, config *outputModels.JiraConfig, issueKey string) (string, error) {
+	category, err := client.getTicket(ctx, &GetInput{
+		url:     config.OrgDomain + jiraEndpoint + url.PathEscape(issueKey) + "?fields=status",
+		headers: jiraHeaders(config),
+	}, "fields", "status", "statusCategory", "key")
+	if err != nil {
+		return "", err
+	}
+	// Jira workflows are customizable, but every status belongs to one of these categories
+	switch category.ToString() {
+	case "indeterminate":
+		return alertModels.TriagedStatus, nil
+	case "done":
+		return alertModels.ResolvedStatus, nil
+	default:
+		return alertModels.OpenStatus, nil
+	}
+}
+
+func jiraHeaders(config *outputModels.JiraConfig) map[string]string {
+	auth := config.UserName + ":" + config.APIKey
+	basicAuthToken := "Basic " + base64.StdEncoding.EncodeToString([]byte(auth))
+	return map[string]string{
+		AuthorizationHTTPHeader: basicAuthToken,
 	}
-	return client.httpWrapper.post(ctx, postInput)
 }
@@ -20,6 +20,7 @@ package outputs
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	jsoniter "github.com/json-iterator/go"
@@ -32,6 +33,14 @@ import (
 func (client *OutputClient) MsTeams(
 	ctx context.Context, alert *deliverymodel.Alert, config *outputModels.MsTeamsConfig) *AlertDeliveryResponse {
 
+	if config.MessageTemplate != "" {
+		payload, err := renderJSONTemplate(config.MessageTemplate, alert)
+		if err != nil {
+			return templateRenderFailure(err)
+		}
+		return client.httpWrapper.post(ctx, &PostInput{url: config.WebhookURL, rawBody: payload})
+	}
+
 	link := "[Click here to view in the Panther UI](" + generateURL(alert) + ").\n"
 
 	// Best effort attempt to marshal Alert Context
@@ -73,3 +82,33 @@ func (client *OutputClient) MsTeams(
 	}
 	return client.httpWrapper.post(ctx, postInput)
 }
+
+// MsTeamsBatch sends a single message summarizing all of the given alerts.
+func (client *OutputClient) MsTeamsBatch(
+	ctx context.Context, alerts []*deliverymodel.Alert, config *outputModels.MsTeamsConfig) *AlertDeliveryResponse {
+
+	var lines []string
+	for i, alert := range alerts {
+		if i == maxBatchListedAlerts {
+			break
+		}
+		lines = append(lines, fmt.Sprintf("- **%s** [%s](%s)", alert.Severity, generateAlertTitle(alert), generateURL(alert)))
+	}
+
+	msTeamsRequestBody := map[string]interface{}{
+		"@context": "http://schema.org/extensions",
+		"@type":    "MessageCard",
+		"text":     generateBatchSummary(alerts),
+		"sections": []interface{}{
+			map[string]interface{}{
+				"text": strings.Join(lines, "\n"),
+			},
+		},
+	}
+
+	postInput := &PostInput{
+		url:  config.WebhookURL,
+		body: msTeamsRequestBody,
+	}
+	return client.httpWrapper.post(ctx, postInput)
+}
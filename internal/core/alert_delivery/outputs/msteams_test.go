@@ -92,3 +92,32 @@ func TestMsTeamsAlert(t *testing.T) {
 	assert.Nil(t, client.MsTeams(ctx, alert, msTeamConfig))
 	httpWrapper.AssertExpectations(t)
 }
+
+func TestMsTeamsBatch(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+
+	alerts := []*deliverymodel.Alert{
+		{AlertID: aws.String("a"), Type: deliverymodel.RuleType, Title: "first", Severity: "HIGH"},
+		{AlertID: aws.String("b"), Type: deliverymodel.RuleType, Title: "second", Severity: "LOW"},
+	}
+	expectedPostInput := &PostInput{
+		url: "msteam-url",
+		body: map[string]interface{}{
+			"@context": "http://schema.org/extensions",
+			"@type":    "MessageCard",
+			"text":     "Panther batched 2 alerts",
+			"sections": []interface{}{
+				map[string]interface{}{
+					"text": "- **HIGH** [New Alert: first](https://panther.io/alerts/a)\n" +
+						"- **LOW** [New Alert: second](https://panther.io/alerts/b)",
+				},
+			},
+		},
+	}
+	ctx := context.Background()
+	httpWrapper.On("post", ctx, expectedPostInput).Return((*AlertDeliveryResponse)(nil))
+
+	assert.Nil(t, client.MsTeamsBatch(ctx, alerts, msTeamConfig))
+	httpWrapper.AssertExpectations(t)
+}
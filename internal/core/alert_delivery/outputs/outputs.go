@@ -49,11 +49,24 @@ type PostInput struct {
 	url     string
 	body    interface{}
 	headers map[string]string
+	// The HTTP method to use instead of POST
+	method string
+	// A pre-rendered payload, sent as is instead of the JSON encoded body
+	rawBody []byte
+	// The Content-Type of the payload, defaults to application/json. A Content-Type header overrides it.
+	contentType string
+}
+
+// GetInput type
+type GetInput struct {
+	url     string
+	headers map[string]string
 }
 
 // HTTPWrapperiface is the interface for our wrapper around Golang's http client
 type HTTPWrapperiface interface {
 	post(context.Context, *PostInput) *AlertDeliveryResponse
+	get(context.Context, *GetInput) *AlertDeliveryResponse
 }
 
 // HTTPiface is an interface for http.Client to simplify unit testing.
@@ -73,6 +86,17 @@ type API interface {
 	Sns(context.Context, *deliverymodel.Alert, *outputModels.SnsConfig) *AlertDeliveryResponse
 	Asana(context.Context, *deliverymodel.Alert, *outputModels.AsanaConfig) *AlertDeliveryResponse
 	CustomWebhook(context.Context, *deliverymodel.Alert, *outputModels.CustomWebhookConfig) *AlertDeliveryResponse
+	Email(context.Context, *deliverymodel.Alert, *outputModels.EmailConfig) *AlertDeliveryResponse
+	EmailDigest(context.Context, []*deliverymodel.Alert, *outputModels.EmailConfig) *AlertDeliveryResponse
+	SlackBatch(context.Context, []*deliverymodel.Alert, *outputModels.SlackConfig) *AlertDeliveryResponse
+	MsTeamsBatch(context.Context, []*deliverymodel.Alert, *outputModels.MsTeamsConfig) *AlertDeliveryResponse
+	ServiceNow(context.Context, *deliverymodel.Alert, *outputModels.ServiceNowConfig) *AlertDeliveryResponse
+	Zendesk(context.Context, *deliverymodel.Alert, *outputModels.ZendeskConfig) *AlertDeliveryResponse
+	Splunk(context.Context, *deliverymodel.Alert, *outputModels.SplunkConfig) *AlertDeliveryResponse
+	Elasticsearch(context.Context, *deliverymodel.Alert, *outputModels.ElasticsearchConfig) *AlertDeliveryResponse
+	Datadog(context.Context, *deliverymodel.Alert, *outputModels.DatadogConfig) *AlertDeliveryResponse
+	TicketStatus(context.Context, *outputModels.AlertOutput, string) (string, error)
+	ResolveSecrets(context.Context, *outputModels.AlertOutput) (*outputModels.AlertOutput, error)
 }
 
 // OutputClient encapsulates the clients that allow sending alerts to multiple outputs
@@ -81,16 +105,25 @@ type OutputClient struct {
 	// Do not mutate any fields in the goroutines, and do not use maps without proper locking.
 	session     *session.Session // safe for concurrent reads, not writes
 	httpWrapper HTTPWrapperiface
+	secrets     *secretCache    // guarded by its own lock
+	sesClients  *sesClientCache // guarded by its own lock
+	// Secret references are only resolved in the account and region Panther is deployed in
+	accountID string
+	region    string
 }
 
 // OutputClient must satisfy the API interface.
 var _ API = (*OutputClient)(nil)
 
 // New creates a new client for alert delivery.
-func New(sess *session.Session) *OutputClient {
+func New(sess *session.Session, accountID string) *OutputClient {
 	return &OutputClient{
 		session:     sess,
 		httpWrapper: &HTTPWrapper{httpClient: &http.Client{}},
+		secrets:     newSecretCache(),
+		sesClients:  newSesClientCache(),
+		accountID:   accountID,
+		region:      aws.StringValue(sess.Config.Region),
 	}
 }
 
@@ -211,6 +244,15 @@ func generateAlertTitle(alert *deliverymodel.Alert) string {
 	}
 }
 
+// generateBatchSummary is the headline of a message summarizing a batch of alerts
+func generateBatchSummary(alerts []*deliverymodel.Alert) string {
+	summary := fmt.Sprintf("Panther batched %d alerts", len(alerts))
+	if len(alerts) > maxBatchListedAlerts {
+		summary += fmt.Sprintf(", showing the first %d", maxBatchListedAlerts)
+	}
+	return summary
+}
+
 func getDisplayName(alert *deliverymodel.Alert) string {
 	if aws.StringValue(alert.AnalysisName) != "" {
 		return *alert.AnalysisName
@@ -43,6 +43,11 @@ func (m *mockHTTPWrapper) post(cxt context.Context, postInput *PostInput) *Alert
 	return args.Get(0).(*AlertDeliveryResponse)
 }
 
+func (m *mockHTTPWrapper) get(cxt context.Context, getInput *GetInput) *AlertDeliveryResponse {
+	args := m.Called(cxt, getInput)
+	return args.Get(0).(*AlertDeliveryResponse)
+}
+
 func TestGenerateAlertTitleReturnGivenTitle(t *testing.T) {
 	alert := &alertModel.Alert{
 		Title: "my title",
@@ -31,21 +31,51 @@ const (
 	AuthorizationHTTPHeader = "Authorization"
 )
 
-// post sends a JSON body to an endpoint.
+// post sends a JSON body (or a pre-rendered payload) to an endpoint.
 func (client *HTTPWrapper) post(ctx context.Context, input *PostInput) *AlertDeliveryResponse {
-	payload, err := jsoniter.Marshal(input.body)
+	payload := input.rawBody
+	if payload == nil {
+		var err error
+		payload, err = jsoniter.Marshal(input.body)
+
+		// If there was an error marshaling the input
+		if err != nil {
+			return &AlertDeliveryResponse{
+				StatusCode: 500, // Internal server error
+				Success:    false,
+				Message:    "json marshal error: " + err.Error(),
+				Permanent:  true,
+			}
+		}
+	}
 
-	// If there was an error marshaling the input
+	method := input.method
+	if method == "" {
+		method = http.MethodPost
+	}
+	request, err := http.NewRequestWithContext(ctx, method, input.url, bytes.NewBuffer(payload))
+
+	// If there was an error creating the request
 	if err != nil {
 		return &AlertDeliveryResponse{
 			StatusCode: 500, // Internal server error
 			Success:    false,
-			Message:    "json marshal error: " + err.Error(),
+			Message:    "http request error: " + err.Error(),
 			Permanent:  true,
 		}
 	}
 
-	request, err := http.NewRequestWithContext(ctx, "POST", input.url, bytes.NewBuffer(payload))
+	contentType := input.contentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	request.Header.Set("Content-Type", contentType)
+	return client.send(request, input.headers)
+}
+
+// get fetches a JSON document from an endpoint.
+func (client *HTTPWrapper) get(ctx context.Context, input *GetInput) *AlertDeliveryResponse {
+	request, err := http.NewRequestWithContext(ctx, "GET", input.url, nil)
 
 	// If there was an error creating the request
 	if err != nil {
@@ -56,12 +86,15 @@ func (client *HTTPWrapper) post(ctx context.Context, input *PostInput) *AlertDel
 			Permanent:  true,
 		}
 	}
+	return client.send(request, input.headers)
+}
 
-	request.Header.Set("Content-Type", "application/json")
+// send adds the headers to a request and reads its response
+func (client *HTTPWrapper) send(request *http.Request, headers map[string]string) *AlertDeliveryResponse {
 	request.Header.Set("Accept", "application/json")
 
 	//Adding dynamic headers
-	for key, value := range input.headers {
+	for key, value := range headers {
 		request.Header.Set(key, value)
 	}
 
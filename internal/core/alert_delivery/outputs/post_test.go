@@ -31,9 +31,12 @@ import (
 
 type mockHTTPClient struct {
 	HTTPiface
-	statusCode   int
-	requestError bool
-	requestBody  string // Request body is saved here for tests to verify
+	statusCode    int
+	requestError  bool
+	requestBody   string // Request body is saved here for tests to verify
+	requestMethod string
+	// The Content-Type header of the request
+	requestContentType string
 }
 
 const requestEndpoint = "https://runpanther.io"
@@ -42,11 +45,15 @@ func (m *mockHTTPClient) Do(request *http.Request) (*http.Response, error) {
 	if m.requestError {
 		return nil, errors.New("endpoint unreachable")
 	}
-	requestBytes, err := ioutil.ReadAll(request.Body)
-	if err != nil {
-		panic(err)
+	m.requestMethod = request.Method
+	m.requestContentType = request.Header.Get("Content-Type")
+	if request.Body != nil {
+		requestBytes, err := ioutil.ReadAll(request.Body)
+		if err != nil {
+			panic(err)
+		}
+		m.requestBody = string(requestBytes)
 	}
-	m.requestBody = string(requestBytes)
 
 	responseBody := ioutil.NopCloser(bytes.NewReader([]byte("response")))
 	return &http.Response{Body: responseBody, StatusCode: m.statusCode}, nil
@@ -112,3 +119,46 @@ func TestPostCreated(t *testing.T) {
 		Permanent:  false,
 	}, c.post(ctx, postInput))
 }
+
+func TestPostRawBody(t *testing.T) {
+	httpClient := &mockHTTPClient{statusCode: http.StatusOK}
+	c := &HTTPWrapper{httpClient: httpClient}
+	postInput := &PostInput{
+		url:     requestEndpoint,
+		method:  http.MethodPut,
+		rawBody: []byte("key=value"),
+		headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+	}
+	ctx := context.Background()
+	assert.True(t, c.post(ctx, postInput).Success)
+	assert.Equal(t, http.MethodPut, httpClient.requestMethod)
+	assert.Equal(t, "key=value", httpClient.requestBody)
+	assert.Equal(t, "application/x-www-form-urlencoded", httpClient.requestContentType)
+
+	// The detected content type of a payload, overridden by the headers above
+	postInput = &PostInput{url: requestEndpoint, rawBody: []byte("text"), contentType: "text/plain; charset=utf-8"}
+	assert.True(t, c.post(ctx, postInput).Success)
+	assert.Equal(t, "text/plain; charset=utf-8", httpClient.requestContentType)
+}
+
+func TestGetOk(t *testing.T) {
+	c := &HTTPWrapper{httpClient: &mockHTTPClient{statusCode: http.StatusOK}}
+	getInput := &GetInput{
+		url:     requestEndpoint,
+		headers: map[string]string{AuthorizationHTTPHeader: "token"},
+	}
+	expectedResponse := &AlertDeliveryResponse{
+		StatusCode: 200,
+		Success:    true,
+		Message:    "response",
+	}
+	assert.Equal(t, expectedResponse, c.get(context.Background(), getInput))
+}
+
+func TestGetNotOk(t *testing.T) {
+	c := &HTTPWrapper{httpClient: &mockHTTPClient{statusCode: http.StatusNotFound}}
+	getInput := &GetInput{url: requestEndpoint}
+	response := c.get(context.Background(), getInput)
+	assert.False(t, response.Success)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
@@ -0,0 +1,189 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+// Resolved secrets are read again after this long, so rotated secrets are picked up.
+const secretCacheTTL = 5 * time.Minute
+
+// Tests can replace these with mock implementations
+var (
+	getSecretsManagerClient = buildSecretsManagerClient
+	getSsmClient            = buildSsmClient
+)
+
+// secretCache holds the values of the referenced secrets, shared by concurrent deliveries.
+//
+// A nil cache reads the secrets on every use.
+type secretCache struct {
+	mu     sync.Mutex
+	values map[string]cachedSecret
+}
+
+type cachedSecret struct {
+	value     string
+	expiresAt time.Time
+}
+
+func newSecretCache() *secretCache {
+	return &secretCache{values: make(map[string]cachedSecret)}
+}
+
+func (cache *secretCache) get(arn string) (cachedSecret, bool) {
+	if cache == nil {
+		return cachedSecret{}, false
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	secret, ok := cache.values[arn]
+	return secret, ok
+}
+
+func (cache *secretCache) set(arn string, secret cachedSecret) {
+	if cache == nil {
+		return
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.values[arn] = secret
+}
+
+// ResolveSecrets replaces the secret references in the credential fields of an output with the values they point to.
+//
+// The output is returned as is if its config has no references, otherwise a copy is returned
+// so the cached outputs keep the references.
+func (client *OutputClient) ResolveSecrets(ctx context.Context, output *outputModels.AlertOutput) (*outputModels.AlertOutput, error) {
+	if output == nil || output.OutputConfig == nil || !client.hasSecretReferences(output.OutputConfig) {
+		return output, nil
+	}
+
+	// Deep copy of the config, the credential fields point into the config of each output type
+	raw, err := jsoniter.Marshal(output.OutputConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal output config")
+	}
+	config := &outputModels.OutputConfig{}
+	if err = jsoniter.Unmarshal(raw, config); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal output config")
+	}
+	for _, field := range config.SecretFields() {
+		ref, ok := outputModels.ParseSecretReference(*field, client.accountID, client.region)
+		if !ok {
+			continue
+		}
+		if *field, err = client.readSecret(ctx, ref); err != nil {
+			return nil, err
+		}
+	}
+
+	result := *output
+	result.OutputConfig = config
+	return &result, nil
+}
+
+// hasSecretReferences returns true if a credential field of an output config references a secret
+func (client *OutputClient) hasSecretReferences(config *outputModels.OutputConfig) bool {
+	for _, field := range config.SecretFields() {
+		if outputModels.IsSecretReference(*field, client.accountID, client.region) {
+			return true
+		}
+	}
+	return false
+}
+
+// readSecret returns the value of a secret reference, read from the cache until it expires.
+//
+// If the secret can't be read again once expired, the cached value is used until the next attempt.
+func (client *OutputClient) readSecret(ctx context.Context, ref *outputModels.SecretReference) (string, error) {
+	now := time.Now()
+	cached, ok := client.secrets.get(ref.ARN)
+	if !ok || now.After(cached.expiresAt) {
+		value, err := fetchSecret(ctx, client.session, ref)
+		switch {
+		case err == nil:
+			cached = cachedSecret{value: value, expiresAt: now.Add(secretCacheTTL)}
+			client.secrets.set(ref.ARN, cached)
+		case ok:
+			zap.L().Warn("failed to refresh secret, using the cached value", zap.String("arn", ref.ARN), zap.Error(err))
+		default:
+			return "", err
+		}
+	}
+
+	if ref.JSONKey == "" {
+		return cached.value, nil
+	}
+	key := jsoniter.Get([]byte(cached.value), ref.JSONKey)
+	if key.ValueType() != jsoniter.StringValue {
+		return "", errors.Errorf("secret %s has no string key %q", ref.ARN, ref.JSONKey)
+	}
+	return key.ToString(), nil
+}
+
+// fetchSecret reads the current value of a secret from Secrets Manager or SSM Parameter Store.
+func fetchSecret(ctx context.Context, sess *session.Session, ref *outputModels.SecretReference) (string, error) {
+	switch ref.Service {
+	case outputModels.SecretsManagerService:
+		response, err := getSecretsManagerClient(sess, ref.Region).GetSecretValueWithContext(ctx,
+			&secretsmanager.GetSecretValueInput{SecretId: aws.String(ref.ARN)})
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to read secret %s", ref.ARN)
+		}
+		if response.SecretString != nil {
+			return *response.SecretString, nil
+		}
+		return string(response.SecretBinary), nil
+	case outputModels.ParameterStoreService:
+		response, err := getSsmClient(sess, ref.Region).GetParameterWithContext(ctx, &ssm.GetParameterInput{
+			Name:           aws.String(ref.ARN),
+			WithDecryption: aws.Bool(true),
+		})
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to read parameter %s", ref.ARN)
+		}
+		return aws.StringValue(response.Parameter.Value), nil
+	default:
+		return "", errors.Errorf("unsupported secret reference %s", ref.ARN)
+	}
+}
+
+func buildSecretsManagerClient(awsSession *session.Session, region string) secretsmanageriface.SecretsManagerAPI {
+	return secretsmanager.New(awsSession, aws.NewConfig().WithRegion(region))
+}
+
+func buildSsmClient(awsSession *session.Session, region string) ssmiface.SSMAPI {
+	return ssm.New(awsSession, aws.NewConfig().WithRegion(region))
+}
@@ -0,0 +1,246 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+const (
+	testAccountID    = "123456789012"
+	testRegion       = "us-west-2"
+	testSecretArn    = "arn:aws:secretsmanager:us-west-2:123456789012:secret:panther/jira-AbCdEf"
+	testParameterArn = "arn:aws:ssm:us-west-2:123456789012:parameter/panther/github-token"
+)
+
+type mockSecretsManager struct {
+	secretsmanageriface.SecretsManagerAPI
+	mock.Mock
+}
+
+func (m *mockSecretsManager) GetSecretValueWithContext(
+	ctx aws.Context, input *secretsmanager.GetSecretValueInput, options ...request.Option) (*secretsmanager.GetSecretValueOutput, error) {
+
+	args := m.Called(ctx, input)
+	return args.Get(0).(*secretsmanager.GetSecretValueOutput), args.Error(1)
+}
+
+type mockSsm struct {
+	ssmiface.SSMAPI
+	mock.Mock
+}
+
+func (m *mockSsm) GetParameterWithContext(
+	ctx aws.Context, input *ssm.GetParameterInput, options ...request.Option) (*ssm.GetParameterOutput, error) {
+
+	args := m.Called(ctx, input)
+	return args.Get(0).(*ssm.GetParameterOutput), args.Error(1)
+}
+
+func mockSecretClients(t *testing.T) (*mockSecretsManager, *mockSsm) {
+	secretsClient, ssmClient := &mockSecretsManager{}, &mockSsm{}
+	getSecretsManagerClient = func(_ *session.Session, region string) secretsmanageriface.SecretsManagerAPI {
+		assert.Equal(t, testRegion, region)
+		return secretsClient
+	}
+	getSsmClient = func(_ *session.Session, region string) ssmiface.SSMAPI {
+		assert.Equal(t, testRegion, region)
+		return ssmClient
+	}
+	t.Cleanup(func() {
+		getSecretsManagerClient = buildSecretsManagerClient
+		getSsmClient = buildSsmClient
+	})
+	return secretsClient, ssmClient
+}
+
+func newSecretsTestClient() *OutputClient {
+	return &OutputClient{secrets: newSecretCache(), accountID: testAccountID, region: testRegion}
+}
+
+func TestParseSecretReference(t *testing.T) {
+	ref, ok := outputModels.ParseSecretReference(testSecretArn+"#apiKey", testAccountID, testRegion)
+	require.True(t, ok)
+	assert.Equal(t, &outputModels.SecretReference{
+		ARN:     testSecretArn,
+		Service: outputModels.SecretsManagerService,
+		Region:  "us-west-2",
+		JSONKey: "apiKey",
+	}, ref)
+
+	ref, ok = outputModels.ParseSecretReference(testParameterArn, testAccountID, testRegion)
+	require.True(t, ok)
+	assert.Equal(t, &outputModels.SecretReference{
+		ARN:     testParameterArn,
+		Service: outputModels.ParameterStoreService,
+		Region:  testRegion,
+	}, ref)
+
+	for _, value := range []string{
+		"",
+		"abc123",
+		"https://example.atlassian.net",
+		"arn:aws:sns:us-west-2:123456789012:MyTopic",
+		"arn:aws:secretsmanager:us-west-2:123456789012:rotation:jira",
+		"arn:aws:ssm:us-west-2:123456789012:document/jira",
+		"arn:aws:ssm:::parameter/jira",
+		testSecretArn + "#",
+		// Outside of panther/, another account or another region
+		"arn:aws:secretsmanager:us-west-2:123456789012:secret:jira-AbCdEf",
+		"arn:aws:ssm:us-west-2:123456789012:parameter/github-token",
+		"arn:aws:secretsmanager:us-west-2:210987654321:secret:panther/jira-AbCdEf",
+		"arn:aws:ssm:eu-west-1:123456789012:parameter/panther/github-token",
+	} {
+		assert.False(t, outputModels.IsSecretReference(value, testAccountID, testRegion), value)
+	}
+}
+
+func TestResolveSecretsNoReferences(t *testing.T) {
+	mockSecretClients(t)
+	output := &outputModels.AlertOutput{
+		OutputType:   aws.String("jira"),
+		OutputConfig: &outputModels.OutputConfig{Jira: &outputModels.JiraConfig{APIKey: "abc123"}},
+	}
+
+	result, err := newSecretsTestClient().ResolveSecrets(context.Background(), output)
+	require.NoError(t, err)
+	assert.Same(t, output, result)
+}
+
+func TestResolveSecrets(t *testing.T) {
+	secretsClient, ssmClient := mockSecretClients(t)
+	ctx := context.Background()
+	secretsClient.On("GetSecretValueWithContext", ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(testSecretArn)}).
+		Return(&secretsmanager.GetSecretValueOutput{
+			SecretString: aws.String(`{"orgDomain": "https://example.atlassian.net", "apiKey": "jira-key"}`),
+		}, nil).Once()
+	ssmClient.On("GetParameterWithContext", ctx, &ssm.GetParameterInput{
+		Name:           aws.String(testParameterArn),
+		WithDecryption: aws.Bool(true),
+	}).Return(&ssm.GetParameterOutput{Parameter: &ssm.Parameter{Value: aws.String("github-token")}}, nil).Once()
+
+	client := newSecretsTestClient()
+	jira := &outputModels.AlertOutput{
+		OutputType: aws.String("jira"),
+		OutputConfig: &outputModels.OutputConfig{Jira: &outputModels.JiraConfig{
+			// Only the credential fields are resolved
+			OrgDomain: testSecretArn + "#orgDomain",
+			APIKey:    testSecretArn + "#apiKey",
+			Labels:    []string{"panther"},
+		}},
+	}
+	result, err := client.ResolveSecrets(ctx, jira)
+	require.NoError(t, err)
+	assert.Equal(t, &outputModels.JiraConfig{
+		OrgDomain: testSecretArn + "#orgDomain",
+		APIKey:    "jira-key",
+		Labels:    []string{"panther"},
+	}, result.OutputConfig.Jira)
+	// The output itself keeps the references
+	assert.Equal(t, testSecretArn+"#apiKey", jira.OutputConfig.Jira.APIKey)
+
+	github := &outputModels.AlertOutput{
+		OutputType:   aws.String("github"),
+		OutputConfig: &outputModels.OutputConfig{Github: &outputModels.GithubConfig{RepoName: "repo", Token: testParameterArn}},
+	}
+	result, err = client.ResolveSecrets(ctx, github)
+	require.NoError(t, err)
+	assert.Equal(t, &outputModels.GithubConfig{RepoName: "repo", Token: "github-token"}, result.OutputConfig.Github)
+
+	// Both secrets are cached, each was only read once
+	_, err = client.ResolveSecrets(ctx, jira)
+	require.NoError(t, err)
+	_, err = client.ResolveSecrets(ctx, github)
+	require.NoError(t, err)
+	secretsClient.AssertExpectations(t)
+	ssmClient.AssertExpectations(t)
+}
+
+func TestResolveSecretsRotated(t *testing.T) {
+	_, ssmClient := mockSecretClients(t)
+	ctx := context.Background()
+	ssmClient.On("GetParameterWithContext", ctx, mock.Anything).
+		Return(&ssm.GetParameterOutput{Parameter: &ssm.Parameter{Value: aws.String("rotated-token")}}, nil).Once()
+
+	client := newSecretsTestClient()
+	client.secrets.set(testParameterArn, cachedSecret{value: "old-token", expiresAt: time.Now().Add(-time.Second)})
+	output := &outputModels.AlertOutput{
+		OutputType:   aws.String("github"),
+		OutputConfig: &outputModels.OutputConfig{Github: &outputModels.GithubConfig{Token: testParameterArn}},
+	}
+
+	result, err := client.ResolveSecrets(ctx, output)
+	require.NoError(t, err)
+	assert.Equal(t, "rotated-token", result.OutputConfig.Github.Token)
+	ssmClient.AssertExpectations(t)
+}
+
+func TestResolveSecretsFailure(t *testing.T) {
+	_, ssmClient := mockSecretClients(t)
+	ctx := context.Background()
+	ssmClient.On("GetParameterWithContext", ctx, mock.Anything).
+		Return((*ssm.GetParameterOutput)(nil), errors.New("AccessDeniedException"))
+
+	client := newSecretsTestClient()
+	output := &outputModels.AlertOutput{
+		OutputType:   aws.String("github"),
+		OutputConfig: &outputModels.OutputConfig{Github: &outputModels.GithubConfig{Token: testParameterArn}},
+	}
+
+	// Nothing to fall back to
+	_, err := client.ResolveSecrets(ctx, output)
+	require.Error(t, err)
+
+	// An expired value is still used while the secret can't be read
+	client.secrets.set(testParameterArn, cachedSecret{value: "old-token", expiresAt: time.Now().Add(-time.Second)})
+	result, err := client.ResolveSecrets(ctx, output)
+	require.NoError(t, err)
+	assert.Equal(t, "old-token", result.OutputConfig.Github.Token)
+}
+
+func TestResolveSecretsMissingKey(t *testing.T) {
+	secretsClient, _ := mockSecretClients(t)
+	ctx := context.Background()
+	secretsClient.On("GetSecretValueWithContext", ctx, mock.Anything).
+		Return(&secretsmanager.GetSecretValueOutput{SecretString: aws.String(`{"apiKey": "jira-key"}`)}, nil)
+
+	output := &outputModels.AlertOutput{
+		OutputType:   aws.String("jira"),
+		OutputConfig: &outputModels.OutputConfig{Jira: &outputModels.JiraConfig{APIKey: testSecretArn + "#token"}},
+	}
+	_, err := (&OutputClient{accountID: testAccountID, region: testRegion}).ResolveSecrets(ctx, output)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `no string key "token"`)
+}
@@ -0,0 +1,117 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"encoding/base64"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	alertModels "github.com/panther-labs/panther/api/lambda/alerts/models"
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+const (
+	serviceNowIncidentEndpoint = "/api/now/table/incident"
+	serviceNowDefaultUrgency   = "3"
+)
+
+// ServiceNow urgency and impact: 1 (High), 2 (Medium), 3 (Low)
+var serviceNowUrgency = map[string]string{
+	"CRITICAL": "1",
+	"HIGH":     "2",
+	"MEDIUM":   "2",
+	"LOW":      "3",
+	"INFO":     "3",
+}
+
+// ServiceNow incident states mapped to alert statuses
+var serviceNowStatus = map[string]string{
+	"1": alertModels.OpenStatus,     // New
+	"2": alertModels.TriagedStatus,  // In Progress
+	"3": alertModels.TriagedStatus,  // On Hold
+	"6": alertModels.ResolvedStatus, // Resolved
+	"7": alertModels.ClosedStatus,   // Closed
+	"8": alertModels.ClosedStatus,   // Canceled
+}
+
+// ServiceNow creates an incident in ServiceNow.
+func (client *OutputClient) ServiceNow(
+	ctx context.Context,
+	alert *deliverymodel.Alert,
+	config *outputModels.ServiceNowConfig,
+) *AlertDeliveryResponse {
+
+	urgency, ok := serviceNowUrgency[alert.Severity]
+	if !ok {
+		urgency = serviceNowDefaultUrgency
+	}
+	incident := map[string]interface{}{
+		"short_description":   removeNewLines(generateAlertTitle(alert)),
+		"description":         generateDetailedAlertMessage(alert),
+		"urgency":             urgency,
+		"impact":              urgency,
+		"correlation_id":      aws.StringValue(alert.AlertID),
+		"correlation_display": "Panther",
+	}
+	if config.AssignmentGroup != "" {
+		incident["assignment_group"] = config.AssignmentGroup
+	}
+
+	postInput := &PostInput{
+		url:     serviceNowIncidentURL(config),
+		body:    incident,
+		headers: serviceNowHeaders(config),
+	}
+	return withTicketID(client.httpWrapper.post(ctx, postInput), "result", "sys_id")
+}
+
+func (client *OutputClient) serviceNowTicketStatus(
+	ctx context.Context,
+	config *outputModels.ServiceNowConfig,
+	sysID string,
+) (string, error) {
+
+	state, err := client.getTicket(ctx, &GetInput{
+		url:     serviceNowIncidentURL(config) + "/" + url.PathEscape(sysID) + "?sysparm_fields=state",
+		headers: serviceNowHeaders(config),
+	}, "result", "state")
+	if err != nil {
+		return "", err
+	}
+	if status, ok := serviceNowStatus[state.ToString()]; ok {
+		return status, nil
+	}
+	return alertModels.OpenStatus, nil
+}
+
+func serviceNowIncidentURL(config *outputModels.ServiceNowConfig) string {
+	return strings.TrimSuffix(config.InstanceURL, "/") + serviceNowIncidentEndpoint
+}
+
+func serviceNowHeaders(config *outputModels.ServiceNowConfig) map[string]string {
+	auth := config.UserName + ":" + config.Password
+	return map[string]string{
+		AuthorizationHTTPHeader: "Basic " + base64.StdEncoding.EncodeToString([]byte(auth)),
+	}
+}
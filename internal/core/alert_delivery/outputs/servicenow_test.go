@@ -0,0 +1,109 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+var serviceNowConfig = &outputModels.ServiceNowConfig{
+	InstanceURL:     "https://acme.service-now.com/",
+	UserName:        "panther",
+	Password:        "password",
+	AssignmentGroup: "security",
+}
+
+var serviceNowHeader = map[string]string{
+	AuthorizationHTTPHeader: "Basic " + base64.StdEncoding.EncodeToString([]byte("panther:password")),
+}
+
+func TestServiceNowAlert(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+
+	createdAtTime, _ := time.Parse(time.RFC3339, "2019-08-03T11:40:13Z")
+	alert := &deliverymodel.Alert{
+		AlertID:             aws.String("alertId"),
+		AnalysisID:          "ruleId",
+		Type:                deliverymodel.RuleType,
+		Title:               "Suspicious\nlogin",
+		CreatedAt:           createdAtTime,
+		AnalysisDescription: "ruleDescription",
+		Severity:            "CRITICAL",
+	}
+	expectedPostInput := &PostInput{
+		url: "https://acme.service-now.com/api/now/table/incident",
+		body: map[string]interface{}{
+			"short_description":   "New Alert: Suspiciouslogin",
+			"description":         generateDetailedAlertMessage(alert),
+			"urgency":             "1",
+			"impact":              "1",
+			"correlation_id":      "alertId",
+			"correlation_display": "Panther",
+			"assignment_group":    "security",
+		},
+		headers: serviceNowHeader,
+	}
+	ctx := context.Background()
+	httpWrapper.On("post", ctx, expectedPostInput).Return(&AlertDeliveryResponse{
+		StatusCode: 201,
+		Success:    true,
+		Message:    `{"result": {"sys_id": "9d385017c611228701d22104cc95c371", "number": "INC0010001"}}`,
+	})
+
+	result := client.ServiceNow(ctx, alert, serviceNowConfig)
+	assert.True(t, result.Success)
+	assert.Equal(t, "9d385017c611228701d22104cc95c371", result.TicketID)
+	httpWrapper.AssertExpectations(t)
+}
+
+func TestServiceNowTicketStatus(t *testing.T) {
+	ctx := context.Background()
+	expectedGetInput := &GetInput{
+		url:     "https://acme.service-now.com/api/now/table/incident/sys-id?sysparm_fields=state",
+		headers: serviceNowHeader,
+	}
+	for state, expected := range map[string]string{"1": "OPEN", "2": "TRIAGED", "6": "RESOLVED", "7": "CLOSED", "100": "OPEN"} {
+		httpWrapper := &mockHTTPWrapper{}
+		client := &OutputClient{httpWrapper: httpWrapper}
+		httpWrapper.On("get", ctx, expectedGetInput).Return(&AlertDeliveryResponse{
+			StatusCode: 200,
+			Success:    true,
+			Message:    `{"result": {"state": "` + state + `"}}`,
+		})
+		status, err := client.serviceNowTicketStatus(ctx, serviceNowConfig, "sys-id")
+		assert.NoError(t, err)
+		assert.Equal(t, expected, status, state)
+	}
+
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+	httpWrapper.On("get", ctx, expectedGetInput).Return(&AlertDeliveryResponse{StatusCode: 404, Message: "not found"})
+	_, err := client.serviceNowTicketStatus(ctx, serviceNowConfig, "sys-id")
+	assert.EqualError(t, err, "not found")
+}
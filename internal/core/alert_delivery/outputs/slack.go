@@ -35,6 +35,9 @@ var severityColors = map[string]string{
 	"INFO":     "#47b881",
 }
 
+// The most alerts listed in a single batch message, the message still counts all of them
+const maxBatchListedAlerts = 20
+
 // Slack sends an alert to a slack channel.
 func (client *OutputClient) Slack(
 	ctx context.Context,
@@ -42,6 +45,14 @@ func (client *OutputClient) Slack(
 	config *outputModels.SlackConfig,
 ) *AlertDeliveryResponse {
 
+	if config.MessageTemplate != "" {
+		payload, err := renderJSONTemplate(config.MessageTemplate, alert)
+		if err != nil {
+			return templateRenderFailure(err)
+		}
+		return client.httpWrapper.post(ctx, &PostInput{url: config.WebhookURL, rawBody: payload})
+	}
+
 	messageField := fmt.Sprintf("<%s|%s>",
 		generateURL(alert),
 		"Click here to view in the Panther UI")
@@ -79,3 +90,33 @@ func (client *OutputClient) Slack(
 
 	return client.httpWrapper.post(ctx, postInput)
 }
+
+// SlackBatch sends a single message summarizing all of the given alerts to a slack channel.
+func (client *OutputClient) SlackBatch(
+	ctx context.Context,
+	alerts []*deliverymodel.Alert,
+	config *outputModels.SlackConfig,
+) *AlertDeliveryResponse {
+
+	attachments := make([]map[string]interface{}, 0, maxBatchListedAlerts)
+	for _, alert := range alerts {
+		if len(attachments) == maxBatchListedAlerts {
+			break
+		}
+		attachments = append(attachments, map[string]interface{}{
+			"fallback":   generateAlertTitle(alert),
+			"color":      severityColors[alert.Severity],
+			"title":      generateAlertTitle(alert),
+			"title_link": generateURL(alert),
+		})
+	}
+
+	postInput := &PostInput{
+		url: config.WebhookURL,
+		body: map[string]interface{}{
+			"text":        generateBatchSummary(alerts),
+			"attachments": attachments,
+		},
+	}
+	return client.httpWrapper.post(ctx, postInput)
+}
@@ -24,6 +24,8 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
@@ -83,3 +85,63 @@ func TestSlackAlert(t *testing.T) {
 	require.Nil(t, client.Slack(ctx, alert, slackConfig))
 	httpWrapper.AssertExpectations(t)
 }
+
+func TestSlackMessageTemplate(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+	config := &outputModels.SlackConfig{
+		WebhookURL:      "slack-channel-url",
+		MessageTemplate: `{"text": {{json .Title}}, "blocks": [{"type": "section", "text": {"type": "mrkdwn", "text": "<{{.Link}}|View>"}}]}`,
+	}
+	alert := &deliverymodel.Alert{
+		AlertID:      aws.String("alertId"),
+		AnalysisID:   "policyId",
+		Type:         deliverymodel.PolicyType,
+		AnalysisName: aws.String("policyName"),
+		Severity:     "INFO",
+	}
+
+	expectedPostInput := &PostInput{
+		url: "slack-channel-url",
+		rawBody: []byte(`{"text": "Policy Failure: policyName", "blocks": [{"type": "section", ` +
+			`"text": {"type": "mrkdwn", "text": "<https://panther.io/alerts/alertId|View>"}}]}`),
+	}
+	ctx := context.Background()
+	httpWrapper.On("post", ctx, expectedPostInput).Return((*AlertDeliveryResponse)(nil))
+
+	require.Nil(t, client.Slack(ctx, alert, config))
+	httpWrapper.AssertExpectations(t)
+}
+
+func TestSlackBatch(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+
+	alerts := make([]*deliverymodel.Alert, 0, maxBatchListedAlerts+5)
+	for i := 0; i < maxBatchListedAlerts+5; i++ {
+		alerts = append(alerts, &deliverymodel.Alert{
+			AlertID:      aws.String("alertId"),
+			AnalysisID:   "policyId",
+			Type:         deliverymodel.PolicyType,
+			AnalysisName: aws.String("policyName"),
+			Severity:     "HIGH",
+		})
+	}
+
+	ctx := context.Background()
+	httpWrapper.On("post", ctx, mock.Anything).Return(&AlertDeliveryResponse{Success: true})
+
+	require.True(t, client.SlackBatch(ctx, alerts, slackConfig).Success)
+	postInput := httpWrapper.Calls[0].Arguments.Get(1).(*PostInput)
+	body := postInput.body.(map[string]interface{})
+	assert.Equal(t, "slack-channel-url", postInput.url)
+	assert.Equal(t, "Panther batched 25 alerts, showing the first 20", body["text"])
+	attachments := body["attachments"].([]map[string]interface{})
+	require.Len(t, attachments, maxBatchListedAlerts)
+	assert.Equal(t, map[string]interface{}{
+		"fallback":   "Policy Failure: policyName",
+		"color":      "#cb2e2e",
+		"title":      "Policy Failure: policyName",
+		"title_link": "https://panther.io/alerts/alertId",
+	}, attachments[0])
+}
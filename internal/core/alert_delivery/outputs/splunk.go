@@ -0,0 +1,66 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"strings"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+const (
+	splunkEventEndpoint     = "/services/collector/event"
+	splunkDefaultSourceType = "panther:alert"
+)
+
+// Splunk sends an alert to a Splunk HTTP Event Collector.
+func (client *OutputClient) Splunk(
+	ctx context.Context,
+	alert *deliverymodel.Alert,
+	config *outputModels.SplunkConfig,
+) *AlertDeliveryResponse {
+
+	sourceType := config.SourceType
+	if sourceType == "" {
+		sourceType = splunkDefaultSourceType
+	}
+	event := map[string]interface{}{
+		// HEC expects epoch seconds, with optional sub-second precision
+		"time":       float64(alert.CreatedAt.UnixNano()/1e6) / 1e3,
+		"sourcetype": sourceType,
+		"event":      generateNotificationFromAlert(alert),
+	}
+	if config.Index != "" {
+		event["index"] = config.Index
+	}
+	if config.Source != "" {
+		event["source"] = config.Source
+	}
+
+	postInput := &PostInput{
+		url:  strings.TrimSuffix(config.HecURL, "/") + splunkEventEndpoint,
+		body: event,
+		headers: map[string]string{
+			AuthorizationHTTPHeader: "Splunk " + config.Token,
+		},
+	}
+	return client.httpWrapper.post(ctx, postInput)
+}
@@ -0,0 +1,66 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/require"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+var splunkConfig = &outputModels.SplunkConfig{
+	HecURL: "https://splunk.acme.com:8088/",
+	Token:  "hec-token",
+	Index:  "security",
+}
+
+func TestSplunkAlert(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+
+	createdAtTime, _ := time.Parse(time.RFC3339, "2019-08-03T11:40:13.250Z")
+	alert := &deliverymodel.Alert{
+		AlertID:    aws.String("alertId"),
+		AnalysisID: "ruleId",
+		Type:       deliverymodel.RuleType,
+		CreatedAt:  createdAtTime,
+		Severity:   "HIGH",
+	}
+	expectedPostInput := &PostInput{
+		url: "https://splunk.acme.com:8088/services/collector/event",
+		body: map[string]interface{}{
+			"time":       1564832413.25,
+			"sourcetype": "panther:alert",
+			"index":      "security",
+			"event":      generateNotificationFromAlert(alert),
+		},
+		headers: map[string]string{AuthorizationHTTPHeader: "Splunk hec-token"},
+	}
+	ctx := context.Background()
+	httpWrapper.On("post", ctx, expectedPostInput).Return((*AlertDeliveryResponse)(nil))
+
+	require.Nil(t, client.Splunk(ctx, alert, splunkConfig))
+	httpWrapper.AssertExpectations(t)
+}
@@ -0,0 +1,154 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	jsoniter "github.com/json-iterator/go"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+)
+
+// Rendered payloads larger than this are rejected
+const maxRenderedTemplateSize = 256 * 1024
+
+// TemplateData is the value user-defined payload templates are executed against.
+//
+// The default notification fields are promoted, so templates can use {{.Title}}, {{.Link}},
+// {{.AlertContext}} etc. The full alert is available as {{.Alert}}.
+type TemplateData struct {
+	Notification
+	Alert *deliverymodel.Alert
+	// The color used for this severity in the Panther UI
+	Color string
+}
+
+var payloadTemplateFuncs = template.FuncMap{
+	"str":   aws.StringValue,
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"time":  func(t time.Time) string { return t.UTC().Format(time.RFC3339) },
+	// json renders any value as JSON, so strings are quoted and escaped for use in JSON bodies
+	"json": func(v interface{}) (string, error) {
+		return jsoniter.MarshalToString(v)
+	},
+	// default returns the fallback if the value is empty
+	"default": func(fallback, value interface{}) interface{} {
+		switch v := value.(type) {
+		case nil:
+			return fallback
+		case string:
+			if v == "" {
+				return fallback
+			}
+		case *string:
+			if aws.StringValue(v) == "" {
+				return fallback
+			}
+		}
+		return value
+	},
+	// truncate shortens a string to at most n characters
+	"truncate": func(n int, s string) string { return truncate(s, n) },
+	"context":  contextValue,
+}
+
+// contextValue looks up a dot-separated path in the alert context, returning nil if it is missing.
+func contextValue(alertContext map[string]interface{}, path string) interface{} {
+	var value interface{} = alertContext
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = obj[key]
+	}
+	return value
+}
+
+// ParseTemplate parses a user-defined payload template.
+func ParseTemplate(text string) (*template.Template, error) {
+	return template.New("payload").Funcs(payloadTemplateFuncs).Parse(text)
+}
+
+// RenderTemplate renders a user-defined payload template for an alert.
+func RenderTemplate(text string, alert *deliverymodel.Alert) ([]byte, error) {
+	tmpl, err := ParseTemplate(text)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &TemplateData{
+		Notification: generateNotificationFromAlert(alert),
+		Alert:        alert,
+		Color:        severityColors[alert.Severity],
+	}
+	// Templates can loop over the alert context, stop them before they use up the memory of the lambda
+	buffer := &limitedBuffer{max: maxRenderedTemplateSize}
+	if err = tmpl.Execute(buffer, data); err != nil {
+		return nil, err
+	}
+	return buffer.buffer.Bytes(), nil
+}
+
+var errRenderedTemplateTooLarge = errors.New("rendered payload exceeds 256 KB")
+
+// limitedBuffer is a buffer which fails writes beyond its maximum size
+type limitedBuffer struct {
+	buffer bytes.Buffer
+	max    int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.buffer.Len()+len(p) > b.max {
+		return 0, errRenderedTemplateTooLarge
+	}
+	return b.buffer.Write(p)
+}
+
+// renderJSONTemplate renders a template which must produce a JSON document
+func renderJSONTemplate(text string, alert *deliverymodel.Alert) ([]byte, error) {
+	body, err := RenderTemplate(text, alert)
+	if err != nil {
+		return nil, err
+	}
+	if !jsoniter.Valid(body) {
+		return nil, errors.New("rendered payload is not valid JSON")
+	}
+	return body, nil
+}
+
+// templateRenderFailure is the response when a payload template can not be rendered.
+//
+// Retrying won't fix a broken template, so the failure is permanent.
+func templateRenderFailure(err error) *AlertDeliveryResponse {
+	return &AlertDeliveryResponse{
+		StatusCode: 400,
+		Success:    false,
+		Message:    "payload template error: " + err.Error(),
+		Permanent:  true,
+	}
+}
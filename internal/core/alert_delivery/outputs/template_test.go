@@ -0,0 +1,88 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+)
+
+var templateAlert = &deliverymodel.Alert{
+	AlertID:      aws.String("alertId"),
+	AnalysisID:   "ruleId",
+	AnalysisName: aws.String("ruleName"),
+	Type:         deliverymodel.RuleType,
+	CreatedAt:    time.Date(2020, 7, 1, 12, 0, 0, 0, time.UTC),
+	Severity:     "HIGH",
+	Tags:         []string{"aws", "iam"},
+	Title:        `Root "login"`,
+	Context: map[string]interface{}{
+		"account": map[string]interface{}{"id": "123456789012"},
+	},
+}
+
+func TestRenderTemplate(t *testing.T) {
+	body, err := RenderTemplate(
+		`{"title": {{json .Title}}, "sev": "{{lower .Severity}}", "color": "{{.Color}}", "tags": "{{join .Tags ","}}", `+
+			`"account": {{json (context .AlertContext "account.id")}}, `+
+			`"missing": {{json (context .AlertContext "account.name")}}, `+
+			`"owner": "{{default "unassigned" (context .AlertContext "owner")}}", `+
+			`"at": "{{time .CreatedAt}}", "rule": "{{str .Alert.AnalysisName}}"}`,
+		templateAlert)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"title": "New Alert: Root \"login\"",
+		"sev": "high",
+		"color": "#cb2e2e",
+		"tags": "aws,iam",
+		"account": "123456789012",
+		"missing": null,
+		"owner": "unassigned",
+		"at": "2020-07-01T12:00:00Z",
+		"rule": "ruleName"
+	}`, string(body))
+}
+
+func TestRenderTemplateTruncate(t *testing.T) {
+	body, err := RenderTemplate(`{{truncate 4 .Alert.AnalysisID}}`, templateAlert)
+	require.NoError(t, err)
+	assert.Equal(t, "rule", string(body))
+}
+
+func TestRenderTemplateErrors(t *testing.T) {
+	_, err := RenderTemplate(`{{.Title`, templateAlert)
+	assert.Error(t, err)
+
+	_, err = RenderTemplate(`{{.NoSuchField}}`, templateAlert)
+	assert.Error(t, err)
+
+	_, err = renderJSONTemplate(`{"text": {{.Title}}}`, templateAlert)
+	assert.EqualError(t, err, "rendered payload is not valid JSON")
+
+	// Rendering stops once the payload is too large
+	_, err = RenderTemplate(strings.Repeat("{{.Title}}", 20000), templateAlert)
+	assert.Equal(t, errRenderedTemplateTooLarge, err)
+}
@@ -0,0 +1,79 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	jsoniter "github.com/json-iterator/go"
+
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+// withTicketID sets the ID of the created ticket, read from the given path of the JSON response body
+func withTicketID(response *AlertDeliveryResponse, path ...interface{}) *AlertDeliveryResponse {
+	if response != nil && response.Success {
+		response.TicketID = jsoniter.Get([]byte(response.Message), path...).ToString()
+	}
+	return response
+}
+
+// TicketStatus returns the alert status matching the current state of a ticket created by an output.
+//
+// An empty status is returned for outputs which don't create tickets.
+func (client *OutputClient) TicketStatus(ctx context.Context, output *outputModels.AlertOutput, ticketID string) (string, error) {
+	output, err := client.ResolveSecrets(ctx, output)
+	if err != nil {
+		return "", err
+	}
+	config := output.OutputConfig
+	switch aws.StringValue(output.OutputType) {
+	case "jira":
+		if config.Jira != nil {
+			return client.jiraTicketStatus(ctx, config.Jira, ticketID)
+		}
+	case "github":
+		if config.Github != nil {
+			return client.githubTicketStatus(ctx, config.Github, ticketID)
+		}
+	case "asana":
+		if config.Asana != nil {
+			return client.asanaTicketStatus(ctx, config.Asana, ticketID)
+		}
+	case "servicenow":
+		if config.ServiceNow != nil {
+			return client.serviceNowTicketStatus(ctx, config.ServiceNow, ticketID)
+		}
+	case "zendesk":
+		if config.Zendesk != nil {
+			return client.zendeskTicketStatus(ctx, config.Zendesk, ticketID)
+		}
+	}
+	return "", nil
+}
+
+// getTicket fetches a ticket and returns the value at the given path of the JSON response body
+func (client *OutputClient) getTicket(ctx context.Context, input *GetInput, path ...interface{}) (jsoniter.Any, error) {
+	response := client.httpWrapper.get(ctx, input)
+	if !response.Success {
+		return nil, response
+	}
+	return jsoniter.Get([]byte(response.Message), path...), nil
+}
@@ -0,0 +1,119 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+func TestWithTicketID(t *testing.T) {
+	response := withTicketID(&AlertDeliveryResponse{Success: true, Message: `{"key": "QR-12"}`}, "key")
+	assert.Equal(t, "QR-12", response.TicketID)
+
+	response = withTicketID(&AlertDeliveryResponse{Success: true, Message: `{"number": 1347}`}, "number")
+	assert.Equal(t, "1347", response.TicketID)
+
+	response = withTicketID(&AlertDeliveryResponse{Success: true, Message: "not json"}, "key")
+	assert.Equal(t, "", response.TicketID)
+
+	response = withTicketID(&AlertDeliveryResponse{Success: false, Message: `{"key": "QR-12"}`}, "key")
+	assert.Equal(t, "", response.TicketID)
+
+	assert.Nil(t, withTicketID(nil, "key"))
+}
+
+func TestTicketStatus(t *testing.T) {
+	ctx := context.Background()
+	testCases := []struct {
+		output   *outputModels.AlertOutput
+		ticketID string
+		url      string
+		body     string
+		expected string
+	}{
+		{
+			output: &outputModels.AlertOutput{
+				OutputType:   aws.String("jira"),
+				OutputConfig: &outputModels.OutputConfig{Jira: jiraConfig},
+			},
+			ticketID: "QR-12",
+			url:      "https://panther-labs.atlassian.net/rest/api/latest/issue/QR-12?fields=status",
+			body:     `{"fields": {"status": {"name": "Code Review", "statusCategory": {"key": "indeterminate"}}}}`,
+			expected: "TRIAGED",
+		},
+		{
+			output: &outputModels.AlertOutput{
+				OutputType:   aws.String("jira"),
+				OutputConfig: &outputModels.OutputConfig{Jira: jiraConfig},
+			},
+			ticketID: "QR-12",
+			url:      "https://panther-labs.atlassian.net/rest/api/latest/issue/QR-12?fields=status",
+			body:     `{"fields": {"status": {"name": "Won't Do", "statusCategory": {"key": "done"}}}}`,
+			expected: "RESOLVED",
+		},
+		{
+			output: &outputModels.AlertOutput{
+				OutputType:   aws.String("github"),
+				OutputConfig: &outputModels.OutputConfig{Github: &outputModels.GithubConfig{RepoName: "acme/alerts", Token: "token"}},
+			},
+			ticketID: "1347",
+			url:      "https://api.github.com/repos/acme/alerts/issues/1347",
+			body:     `{"number": 1347, "state": "closed"}`,
+			expected: "RESOLVED",
+		},
+		{
+			output: &outputModels.AlertOutput{
+				OutputType:   aws.String("asana"),
+				OutputConfig: &outputModels.OutputConfig{Asana: &outputModels.AsanaConfig{PersonalAccessToken: "token"}},
+			},
+			ticketID: "1200",
+			url:      "https://app.asana.com/api/1.0/tasks/1200?opt_fields=completed",
+			body:     `{"data": {"gid": "1200", "completed": false}}`,
+			expected: "OPEN",
+		},
+	}
+
+	for _, tc := range testCases {
+		httpWrapper := &mockHTTPWrapper{}
+		client := &OutputClient{httpWrapper: httpWrapper}
+		httpWrapper.On("get", ctx, mock.MatchedBy(func(input *GetInput) bool {
+			return input.url == tc.url
+		})).Return(&AlertDeliveryResponse{StatusCode: 200, Success: true, Message: tc.body}).Once()
+
+		status, err := client.TicketStatus(ctx, tc.output, tc.ticketID)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, status, *tc.output.OutputType)
+		httpWrapper.AssertExpectations(t)
+	}
+
+	// Outputs which don't create tickets have no ticket status
+	status, err := (&OutputClient{}).TicketStatus(ctx, &outputModels.AlertOutput{
+		OutputType:   aws.String("slack"),
+		OutputConfig: &outputModels.OutputConfig{Slack: &outputModels.SlackConfig{}},
+	}, "ticket")
+	assert.NoError(t, err)
+	assert.Equal(t, "", status)
+}
@@ -116,3 +116,11 @@ func removeNewLines(input string) string {
 	re := regexp.MustCompile(`\n`)
 	return re.ReplaceAllString(input, "")
 }
+
+// truncate shortens a string to at most maxLen characters
+func truncate(input string, maxLen int) string {
+	if runes := []rune(input); len(runes) > maxLen {
+		return string(runes[:maxLen])
+	}
+	return input
+}
@@ -0,0 +1,118 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"encoding/base64"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	alertModels "github.com/panther-labs/panther/api/lambda/alerts/models"
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+const (
+	zendeskTicketsEndpoint = "/api/v2/tickets"
+	zendeskDefaultPriority = "normal"
+)
+
+var zendeskPriority = map[string]string{
+	"CRITICAL": "urgent",
+	"HIGH":     "high",
+	"MEDIUM":   "normal",
+	"LOW":      "low",
+	"INFO":     "low",
+}
+
+// Zendesk ticket statuses mapped to alert statuses
+var zendeskStatus = map[string]string{
+	"new":     alertModels.OpenStatus,
+	"open":    alertModels.TriagedStatus,
+	"pending": alertModels.TriagedStatus,
+	"hold":    alertModels.TriagedStatus,
+	"solved":  alertModels.ResolvedStatus,
+	"closed":  alertModels.ClosedStatus,
+}
+
+// Zendesk creates a ticket in Zendesk.
+func (client *OutputClient) Zendesk(
+	ctx context.Context,
+	alert *deliverymodel.Alert,
+	config *outputModels.ZendeskConfig,
+) *AlertDeliveryResponse {
+
+	priority, ok := zendeskPriority[alert.Severity]
+	if !ok {
+		priority = zendeskDefaultPriority
+	}
+	ticket := map[string]interface{}{
+		"subject": removeNewLines(generateAlertTitle(alert)),
+		"comment": map[string]string{
+			"body": generateDetailedAlertMessage(alert),
+		},
+		"priority":    priority,
+		"external_id": aws.StringValue(alert.AlertID),
+		"tags":        []string{"panther"},
+	}
+	if config.GroupID != 0 {
+		ticket["group_id"] = config.GroupID
+	}
+
+	postInput := &PostInput{
+		url:     zendeskTicketsURL(config) + ".json",
+		body:    map[string]interface{}{"ticket": ticket},
+		headers: zendeskHeaders(config),
+	}
+	return withTicketID(client.httpWrapper.post(ctx, postInput), "ticket", "id")
+}
+
+func (client *OutputClient) zendeskTicketStatus(
+	ctx context.Context,
+	config *outputModels.ZendeskConfig,
+	ticketID string,
+) (string, error) {
+
+	state, err := client.getTicket(ctx, &GetInput{
+		url:     zendeskTicketsURL(config) + "/" + url.PathEscape(ticketID) + ".json",
+		headers: zendeskHeaders(config),
+	}, "ticket", "status")
+	if err != nil {
+		return "", err
+	}
+	if status, ok := zendeskStatus[state.ToString()]; ok {
+		return status, nil
+	}
+	return alertModels.OpenStatus, nil
+}
+
+func zendeskTicketsURL(config *outputModels.ZendeskConfig) string {
+	return strings.TrimSuffix(config.OrgDomain, "/") + zendeskTicketsEndpoint
+}
+
+// Zendesk API tokens authenticate as "{email}/token:{token}"
+func zendeskHeaders(config *outputModels.ZendeskConfig) map[string]string {
+	auth := config.UserEmail + "/token:" + config.APIToken
+	return map[string]string{
+		AuthorizationHTTPHeader: "Basic " + base64.StdEncoding.EncodeToString([]byte(auth)),
+	}
+}
@@ -0,0 +1,102 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+var zendeskConfig = &outputModels.ZendeskConfig{
+	OrgDomain: "https://acme.zendesk.com",
+	UserEmail: "panther@acme.com",
+	APIToken:  "token",
+	GroupID:   42,
+}
+
+var zendeskHeader = map[string]string{
+	AuthorizationHTTPHeader: "Basic " + base64.StdEncoding.EncodeToString([]byte("panther@acme.com/token:token")),
+}
+
+func TestZendeskAlert(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+
+	createdAtTime, _ := time.Parse(time.RFC3339, "2019-08-03T11:40:13Z")
+	alert := &deliverymodel.Alert{
+		AlertID:    aws.String("alertId"),
+		AnalysisID: "policyId",
+		Type:       deliverymodel.PolicyType,
+		CreatedAt:  createdAtTime,
+		Severity:   "MEDIUM",
+	}
+	expectedPostInput := &PostInput{
+		url: "https://acme.zendesk.com/api/v2/tickets.json",
+		body: map[string]interface{}{
+			"ticket": map[string]interface{}{
+				"subject":     "Policy Failure: policyId",
+				"comment":     map[string]string{"body": generateDetailedAlertMessage(alert)},
+				"priority":    "normal",
+				"external_id": "alertId",
+				"tags":        []string{"panther"},
+				"group_id":    int64(42),
+			},
+		},
+		headers: zendeskHeader,
+	}
+	ctx := context.Background()
+	httpWrapper.On("post", ctx, expectedPostInput).Return(&AlertDeliveryResponse{
+		StatusCode: 201,
+		Success:    true,
+		Message:    `{"ticket": {"id": 35436, "status": "new"}}`,
+	})
+
+	result := client.Zendesk(ctx, alert, zendeskConfig)
+	assert.True(t, result.Success)
+	assert.Equal(t, "35436", result.TicketID)
+	httpWrapper.AssertExpectations(t)
+}
+
+func TestZendeskTicketStatus(t *testing.T) {
+	ctx := context.Background()
+	expectedGetInput := &GetInput{
+		url:     "https://acme.zendesk.com/api/v2/tickets/35436.json",
+		headers: zendeskHeader,
+	}
+	for state, expected := range map[string]string{"new": "OPEN", "pending": "TRIAGED", "solved": "RESOLVED", "closed": "CLOSED"} {
+		httpWrapper := &mockHTTPWrapper{}
+		client := &OutputClient{httpWrapper: httpWrapper}
+		httpWrapper.On("get", ctx, expectedGetInput).Return(&AlertDeliveryResponse{
+			StatusCode: 200,
+			Success:    true,
+			Message:    `{"ticket": {"id": 35436, "status": "` + state + `"}}`,
+		})
+		status, err := client.zendeskTicketStatus(ctx, zendeskConfig, "35436")
+		assert.NoError(t, err)
+		assert.Equal(t, expected, status, state)
+	}
+}
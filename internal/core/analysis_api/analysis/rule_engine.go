@@ -30,6 +30,7 @@ import (
 
 	enginemodels "github.com/panther-labs/panther/api/lambda/analysis"
 	"github.com/panther-labs/panther/api/lambda/analysis/models"
+	"github.com/panther-labs/panther/internal/log_analysis/rulespec"
 	"github.com/panther-labs/panther/pkg/genericapi"
 )
 
@@ -71,29 +72,16 @@ func (e *RuleEngine) TestRule(rule *models.TestRuleInput) (*models.TestRuleOutpu
 		}
 	}
 
-	input := enginemodels.RulesEngineInput{
-		Rules: []enginemodels.Rule{
-			{
-				Body:     rule.Body,
-				ID:       testRuleID, // doesn't matter as we're only running one rule
-				LogTypes: rule.LogTypes,
-			},
-		},
-		Events: inputEvents,
-	}
-
-	// Send the request to the rule-engine
-	var engineOutput enginemodels.RulesEngineOutput
-	err := genericapi.Invoke(e.lambdaClient, e.lambdaName, &input, &engineOutput)
+	results, err := e.RunRule(testRuleID, rule.Body, rule.Spec, rule.LogTypes, inputEvents)
 	if err != nil {
-		return nil, errors.Wrap(err, "error invoking rule engine")
+		return nil, err
 	}
 
 	// Translate rule engine output to test results.
 	testResult := &models.TestRuleOutput{
-		Results: make([]models.TestRuleRecord, len(engineOutput.Results)),
+		Results: make([]models.TestRuleRecord, len(results)),
 	}
-	for i, result := range engineOutput.Results {
+	for i, result := range results {
 		// Determine which test case this result corresponds to.
 		testIndex, err := strconv.Atoi(result.ID)
 		if err != nil {
@@ -131,6 +119,50 @@ func (e *RuleEngine) TestRule(rule *models.TestRuleInput) (*models.TestRuleOutpu
 	return testResult, nil
 }
 
+// RunRule evaluates a rule against the events in test mode, returning one result per event.
+func (e *RuleEngine) RunRule(ruleID, body, spec string, logTypes []string,
+	events []enginemodels.Event) ([]enginemodels.RuleResult, error) {
+
+	if spec != "" {
+		// Declarative rules are evaluated natively, there is no need to call the rule-engine
+		return testRuleSpec(ruleID, spec, events), nil
+	}
+	input := enginemodels.RulesEngineInput{
+		Rules: []enginemodels.Rule{
+			{
+				Body:     body,
+				ID:       ruleID,
+				LogTypes: logTypes,
+			},
+		},
+		Events: events,
+	}
+
+	// Send the request to the rule-engine
+	var engineOutput enginemodels.RulesEngineOutput
+	if err := genericapi.Invoke(e.lambdaClient, e.lambdaName, &input, &engineOutput); err != nil {
+		return nil, errors.Wrap(err, "error invoking rule engine")
+	}
+	return engineOutput.Results, nil
+}
+
+// testRuleSpec evaluates a rule spec against the test events, returning the same results as the rule-engine.
+func testRuleSpec(ruleID, spec string, events []enginemodels.Event) []enginemodels.RuleResult {
+	rule, err := rulespec.Parse(ruleID, spec)
+	results := make([]enginemodels.RuleResult, len(events))
+	for i, event := range events {
+		if err != nil {
+			// Same as an import or syntax error in a Python rule
+			results[i] = enginemodels.RuleResult{ID: event.ID, RuleID: ruleID, GenericError: err.Error(), Errored: true}
+			continue
+		}
+		data, _ := event.Data.(map[string]interface{})
+		results[i] = *rule.Run(data, false)
+		results[i].ID = event.ID
+	}
+	return results
+}
+
 func buildTestSubRecord(output, error string) *models.TestDetectionSubRecord {
 	if output == "" && error == "" {
 		return nil
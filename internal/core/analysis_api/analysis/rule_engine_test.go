@@ -119,6 +119,82 @@ def dedup(e):
 	require.EqualValues(t, expected, res)
 }
 
+func TestRuleEngine_TestRuleSpec(t *testing.T) {
+	// Spec rules are evaluated without invoking the rule-engine lambda
+	ruleEngine := RuleEngine{
+		lambdaClient: &mockLambdaClient{},
+	}
+
+	testRuleInput := &models.TestRuleInput{
+		Spec: `
+match:
+  field: eventName
+  equals: ConsoleLogin
+title: "Login by {user}"
+alertContext:
+  user: user
+`,
+		LogTypes: []string{"AWS.CloudTrail"},
+		Tests: []models.UnitTest{
+			{
+				Name:           "Matches",
+				ExpectedResult: true,
+				Resource:       `{"eventName": "ConsoleLogin", "user": "alice"}`,
+			},
+			{
+				Name:           "Missing title field",
+				ExpectedResult: true,
+				Resource:       `{"eventName": "ConsoleLogin"}`,
+			},
+			{
+				Name:           "Does not match",
+				ExpectedResult: false,
+				Resource:       `{"eventName": "GetObject"}`,
+			},
+		},
+	}
+	res, err := ruleEngine.TestRule(testRuleInput)
+	require.NoError(t, err)
+
+	expected := &models.TestRuleOutput{
+		Results: []models.TestRuleRecord{
+			{
+				ID:     "0",
+				Name:   "Matches",
+				Passed: true,
+				Functions: models.TestRuleRecordFunctions{
+					Rule:         &models.TestDetectionSubRecord{Output: aws.String("true")},
+					Title:        &models.TestDetectionSubRecord{Output: aws.String("Login by alice")},
+					Dedup:        &models.TestDetectionSubRecord{Output: aws.String("Login by alice")},
+					AlertContext: &models.TestDetectionSubRecord{Output: aws.String(`{"user":"alice"}`)},
+				},
+			},
+			{
+				ID:     "1",
+				Name:   "Missing title field",
+				Passed: false,
+				Functions: models.TestRuleRecordFunctions{
+					Rule: &models.TestDetectionSubRecord{Output: aws.String("true")},
+					Title: &models.TestDetectionSubRecord{
+						Error: &models.TestError{Message: `KeyError: field "user" not found`},
+					},
+					Dedup:        &models.TestDetectionSubRecord{Output: aws.String("defaultDedupString:" + testRuleID)},
+					AlertContext: &models.TestDetectionSubRecord{Output: aws.String(`{"user":null}`)},
+				},
+			},
+			{
+				ID:     "2",
+				Name:   "Does not match",
+				Passed: true,
+				Functions: models.TestRuleRecordFunctions{
+					Rule: &models.TestDetectionSubRecord{Output: aws.String("false")},
+				},
+			},
+		},
+	}
+	require.EqualValues(t, expected, res)
+}
+
 func mustMarshal(v interface{}) []byte {
 	data, err := json.Marshal(v)
 	if err != nil {
@@ -21,6 +21,7 @@ package handlers
 import (
 	"archive/zip"
 	"bytes"
+	"context"
 	"encoding/base64"
 	"io/ioutil"
 	"net/http"
@@ -37,6 +38,7 @@ import (
 	"github.com/panther-labs/panther/api/lambda/analysis"
 	"github.com/panther-labs/panther/api/lambda/analysis/models"
 	compliancemodels "github.com/panther-labs/panther/api/lambda/compliance/models"
+	"github.com/panther-labs/panther/internal/log_analysis/rulespec"
 	"github.com/panther-labs/panther/pkg/gatewayapi"
 )
 
@@ -47,8 +49,8 @@ type writeResult struct {
 }
 
 // BulkUpload uploads multiple analysis items from a zipfile.
-func (API) BulkUpload(input *models.BulkUploadInput) *events.APIGatewayProxyResponse {
-	policies, err := extractZipFile(input)
+func (API) BulkUpload(ctx context.Context, input *models.BulkUploadInput) *events.APIGatewayProxyResponse {
+	policies, err := extractZipFile(ctx, input)
 	if err != nil {
 		return &events.APIGatewayProxyResponse{
 			Body:       err.Error(),
@@ -56,29 +58,15 @@ func (API) BulkUpload(input *models.BulkUploadInput) *events.APIGatewayProxyResp
 		}
 	}
 
-	// Create/modify each policy in parallel
-	results := make(chan writeResult)
+	items := make([]*tableItem, 0, len(policies))
 	for _, policy := range policies {
-		go func(item *tableItem) {
-			defer func() {
-				// Recover from panic so we don't block forever when waiting for routines to finish.
-				if r := recover(); r != nil {
-					zap.L().Error("panicked while processing item",
-						zap.String("id", item.ID), zap.Any("panic", r))
-					results <- writeResult{err: errors.New("panicked goroutine")}
-				}
-			}()
-			changeType, err := writeItem(item, input.UserID, nil)
-			results <- writeResult{item: item, changeType: changeType, err: err}
-		}(policy)
+		items = append(items, policy)
 	}
 
 	var counts models.BulkUploadOutput
 	var response *events.APIGatewayProxyResponse
 
-	// Wait for all the goroutines to finish.
-	for range policies {
-		result := <-results
+	for _, result := range writeItems(items, input.UserID) {
 		if result.err != nil {
 			// Set the response with an error code - 4XX first, otherwise 5XX
 			if result.err == errWrongType {
@@ -151,14 +139,44 @@ func (API) BulkUpload(input *models.BulkUploadInput) *events.APIGatewayProxyResp
 	return gatewayapi.MarshalResponse(&counts, http.StatusOK)
 }
 
-func extractZipFile(input *models.BulkUploadInput) (map[string]*tableItem, error) {
+// Create/modify each item in parallel, returning the result of each write
+func writeItems(items []*tableItem, userID string) []writeResult {
+	results := make(chan writeResult)
+	for _, item := range items {
+		go func(item *tableItem) {
+			defer func() {
+				// Recover from panic so we don't block forever when waiting for routines to finish.
+				if r := recover(); r != nil {
+					zap.L().Error("panicked while processing item",
+						zap.String("id", item.ID), zap.Any("panic", r))
+					results <- writeResult{item: item, err: errors.New("panicked goroutine")}
+				}
+			}()
+			changeType, err := writeItem(item, userID, nil)
+			results <- writeResult{item: item, changeType: changeType, err: err}
+		}(item)
+	}
+
+	// Wait for all the goroutines to finish.
+	output := make([]writeResult, 0, len(items))
+	for range items {
+		output = append(output, <-results)
+	}
+	return output
+}
+
+func extractZipFile(ctx context.Context, input *models.BulkUploadInput) (map[string]*tableItem, error) {
 	// Base64-decode
 	content, err := base64.StdEncoding.DecodeString(input.Data)
 	if err != nil {
 		return nil, errors.Errorf("base64 decoding failed: %s", err)
 	}
+	return extractZipArchive(ctx, content)
+}
 
-	// Unzip in memory (the max request size is only 6 MB, so this should easily fit)
+// Parse the analysis items of a zipfile in the format of the panther_analysis_tool
+func extractZipArchive(ctx context.Context, content []byte) (map[string]*tableItem, error) {
+	// Unzip in memory (the max request size is only 6 MB and packs are of similar size, so this should easily fit)
 	zipReader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
 	if err != nil {
 		return nil, errors.Errorf("zipReader failed: %s", err)
@@ -214,6 +232,17 @@ func extractZipFile(input *models.BulkUploadInput) (map[string]*tableItem, error
 		// Map the Config struct fields over to the fields we need to store in Dynamo
 		analysisItem := tableItemFromConfig(config)
 
+		if config.Detection != nil {
+			if analysisItem.Type != models.TypeRule {
+				return nil, errors.Errorf("%s: only rules can have a Detection spec", zipFile.Name)
+			}
+			spec, err := yaml.Marshal(config.Detection)
+			if err != nil {
+				return nil, errors.Wrapf(err, "%s: invalid Detection spec", zipFile.Name)
+			}
+			analysisItem.Spec = string(spec)
+		}
+
 		if analysisItem.Type == models.TypeDataModel {
 			// ensure Mappings are nil rather than an empty slice
 			if len(config.Mappings) > 0 {
@@ -253,6 +282,13 @@ func extractZipFile(input *models.BulkUploadInput) (map[string]*tableItem, error
 
 	// Finish each policy by adding its body and then validate it
 	for _, policy := range result {
+		if policy.Spec != "" {
+			// Declarative rules do not have a Python body
+			if _, err := rulespec.Parse(policy.ID, policy.Spec); err != nil {
+				return nil, errors.Errorf("rule ID %s is invalid: %s", policy.ID, err)
+			}
+			continue
+		}
 		if body, ok := policyBodies[policy.Body]; ok {
 			policy.Body = body
 			if err := validateUploadedPolicy(policy); err != nil {
@@ -262,6 +298,13 @@ func extractZipFile(input *models.BulkUploadInput) (map[string]*tableItem, error
 			// it is ok for DataModels to be missing python body
 			return nil, errors.Errorf("policy %s is missing a body", policy.ID)
 		}
+
+		if policy.Type == models.TypeDataModel {
+			policy.SupportedFields, err = dataModelSupportedFields(ctx, policy.ResourceTypes, policy.Mappings, policy.Body)
+			if err != nil {
+				return nil, errors.Wrapf(err, "DataModel %s", policy.ID)
+			}
+		}
 	}
 
 	return result, nil
@@ -380,6 +423,14 @@ func tableItemFromConfig(config analysis.Config) *tableItem {
 			item.Threshold = config.Threshold
 		}
 
+		if grouping := config.AlertGrouping; grouping != nil {
+			item.AlertGrouping = &models.AlertGrouping{
+				Window:             strings.ToUpper(grouping.Window),
+				MaxAlertAgeMinutes: grouping.MaxAlertAgeMinutes,
+				MaxEventsPerAlert:  grouping.MaxEventsPerAlert,
+			}
+		}
+
 		// These "syntax sugar" re-mappings are to make managing rules from the CLI more intuitive
 		if config.PolicyID == "" {
 			item.ID = config.RuleID
@@ -405,11 +456,8 @@ func tableItemFromConfig(config analysis.Config) *tableItem {
 	return &item
 }
 
-// Data Model Validations: len(ResourceTypes) <= 1, Single Model Enabled
+// Data Model Validations: Single Model Enabled
 func validateUploadedDataModel(item *tableItem) error {
-	if len(item.ResourceTypes) > 1 {
-		return errors.New("only one LogType may be specified per DataModel")
-	}
 	isEnabled, err := isSingleDataModelEnabled(item.ID, item.Enabled, item.ResourceTypes)
 	if err != nil {
 		return err
@@ -438,5 +486,10 @@ func validateUploadedPolicy(item *tableItem) error {
 	if err := validate.New().Struct(policy); err != nil {
 		return errors.Errorf("policy ID %s is invalid: %s", policy.ID, err)
 	}
+	if item.AlertGrouping != nil {
+		if err := validate.New().Struct(item.AlertGrouping); err != nil {
+			return errors.Errorf("rule ID %s has an invalid AlertGrouping: %s", item.ID, err)
+		}
+	}
 	return nil
 }
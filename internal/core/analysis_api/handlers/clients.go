@@ -19,6 +19,8 @@ package handlers
  */
 
 import (
+	"net/http"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -29,6 +31,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/google/go-github/github"
 	"github.com/kelseyhightower/envconfig"
 
 	"github.com/panther-labs/panther/internal/core/analysis_api/analysis"
@@ -48,6 +51,7 @@ var (
 	s3Client         s3iface.S3API
 	sqsClient        sqsiface.SQSAPI
 	complianceClient gatewayapi.API
+	githubClient     *github.Client
 
 	policyEngine analysis.PolicyEngine
 	ruleEngine   analysis.RuleEngine
@@ -56,12 +60,15 @@ var (
 )
 
 type envConfig struct {
-	Bucket               string `required:"true" split_words:"true"`
-	LayerManagerQueueURL string `required:"true" split_words:"true"`
-	RulesEngine          string `required:"true" split_words:"true"`
-	PolicyEngine         string `required:"true" split_words:"true"`
-	ResourceQueueURL     string `required:"true" split_words:"true"`
-	Table                string `required:"true" split_words:"true"`
+	Bucket               string   `required:"true" split_words:"true"`
+	DetectionPackBuckets []string `split_words:"true"`
+	ExceptionCountsTable string   `required:"true" split_words:"true"`
+	LayerManagerQueueURL string   `required:"true" split_words:"true"`
+	RulesEngine          string   `required:"true" split_words:"true"`
+	PolicyEngine         string   `required:"true" split_words:"true"`
+	ProcessedDataBucket  string   `required:"true" split_words:"true"`
+	ResourceQueueURL     string   `required:"true" split_words:"true"`
+	Table                string   `required:"true" split_words:"true"`
 }
 
 // API defines all of the handlers as receiver functions.
@@ -78,6 +85,7 @@ func Setup() {
 	sqsClient = sqs.New(awsSession)
 	lambdaClient := lambda.New(awsSession)
 	complianceClient = gatewayapi.NewClient(lambdaClient, "panther-compliance-api")
+	githubClient = github.NewClient(&http.Client{})
 
 	policyEngine = analysis.NewPolicyEngine(lambdaClient, env.PolicyEngine)
 	ruleEngine = analysis.NewRuleEngine(lambdaClient, env.RulesEngine)
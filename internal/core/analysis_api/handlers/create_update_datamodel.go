@@ -19,12 +19,14 @@ package handlers
  */
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
 	"github.com/pkg/errors"
+	"go.uber.org/zap"
 
 	"github.com/panther-labs/panther/api/lambda/analysis/models"
 	"github.com/panther-labs/panther/pkg/gatewayapi"
@@ -37,15 +39,15 @@ var (
 )
 
 // CreateDataModel adds a new DataModel to the Dynamo table.
-func (API) CreateDataModel(input *models.CreateDataModelInput) *events.APIGatewayProxyResponse {
-	return writeDataModel(input, true)
+func (API) CreateDataModel(ctx context.Context, input *models.CreateDataModelInput) *events.APIGatewayProxyResponse {
+	return writeDataModel(ctx, input, true)
 }
 
-func (API) UpdateDataModel(input *models.UpdateDataModelInput) *events.APIGatewayProxyResponse {
-	return writeDataModel(input, false)
+func (API) UpdateDataModel(ctx context.Context, input *models.UpdateDataModelInput) *events.APIGatewayProxyResponse {
+	return writeDataModel(ctx, input, false)
 }
 
-func writeDataModel(input *models.UpdateDataModelInput, create bool) *events.APIGatewayProxyResponse {
+func writeDataModel(ctx context.Context, input *models.UpdateDataModelInput, create bool) *events.APIGatewayProxyResponse {
 	if err := validateUpdateDataModel(input); err != nil {
 		return &events.APIGatewayProxyResponse{
 			Body:       err.Error(),
@@ -53,6 +55,18 @@ func writeDataModel(input *models.UpdateDataModelInput, create bool) *events.API
 		}
 	}
 
+	supportedFields, err := dataModelSupportedFields(ctx, input.LogTypes, input.Mappings, input.Body)
+	if err != nil {
+		if _, ok := err.(*dataModelMappingError); ok {
+			return &events.APIGatewayProxyResponse{
+				Body:       err.Error(),
+				StatusCode: http.StatusBadRequest,
+			}
+		}
+		zap.L().Error("failed to validate data model mappings", zap.Error(err))
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+
 	// we only need to check for conflicting enabled DataModels if the new one is
 	// going to be enabled
 	isEnabled, err := isSingleDataModelEnabled(input.ID, input.Enabled, input.LogTypes)
@@ -78,6 +92,8 @@ func writeDataModel(input *models.UpdateDataModelInput, create bool) *events.API
 		Mappings:      input.Mappings,
 		ResourceTypes: input.LogTypes,
 		Type:          models.TypeDataModel,
+
+		SupportedFields: supportedFields,
 	}
 
 	var statusCode int
@@ -19,6 +19,7 @@ package handlers
  */
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/aws/aws-lambda-go/events"
@@ -28,11 +29,11 @@ import (
 	"github.com/panther-labs/panther/pkg/gatewayapi"
 )
 
-func (API) CreateGlobal(input *models.CreateGlobalInput) *events.APIGatewayProxyResponse {
+func (API) CreateGlobal(_ context.Context, input *models.CreateGlobalInput) *events.APIGatewayProxyResponse {
 	return writeGlobal(input, true)
 }
 
-func (API) UpdateGlobal(input *models.UpdateGlobalInput) *events.APIGatewayProxyResponse {
+func (API) UpdateGlobal(_ context.Context, input *models.UpdateGlobalInput) *events.APIGatewayProxyResponse {
 	return writeGlobal(input, false)
 }
 
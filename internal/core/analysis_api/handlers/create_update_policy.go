@@ -19,6 +19,7 @@ package handlers
  */
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/aws/aws-lambda-go/events"
@@ -32,11 +33,11 @@ import (
 )
 
 // CreatePolicy adds a new policy to the Dynamo table.
-func (API) CreatePolicy(input *models.CreatePolicyInput) *events.APIGatewayProxyResponse {
+func (API) CreatePolicy(_ context.Context, input *models.CreatePolicyInput) *events.APIGatewayProxyResponse {
 	return writePolicy(input, true)
 }
 
-func (API) UpdatePolicy(input *models.UpdatePolicyInput) *events.APIGatewayProxyResponse {
+func (API) UpdatePolicy(_ context.Context, input *models.UpdatePolicyInput) *events.APIGatewayProxyResponse {
 	return writePolicy(input, false)
 }
 
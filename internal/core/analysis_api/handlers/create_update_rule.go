@@ -19,6 +19,7 @@ package handlers
  */
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/aws/aws-lambda-go/events"
@@ -27,6 +28,8 @@ import (
 
 	"github.com/panther-labs/panther/api/lambda/analysis/models"
 	"github.com/panther-labs/panther/internal/core/analysis_api/analysis"
+	"github.com/panther-labs/panther/internal/log_analysis/rulespec"
+	"github.com/panther-labs/panther/internal/log_analysis/scheduledrules"
 	"github.com/panther-labs/panther/pkg/gatewayapi"
 )
 
@@ -37,11 +40,11 @@ const (
 	defaultRuleThreshold = 1
 )
 
-func (API) CreateRule(input *models.CreateRuleInput) *events.APIGatewayProxyResponse {
+func (API) CreateRule(_ context.Context, input *models.CreateRuleInput) *events.APIGatewayProxyResponse {
 	return writeRule(input, true)
 }
 
-func (API) UpdateRule(input *models.UpdateRuleInput) *events.APIGatewayProxyResponse {
+func (API) UpdateRule(_ context.Context, input *models.UpdateRuleInput) *events.APIGatewayProxyResponse {
 	return writeRule(input, false)
 }
 
@@ -76,23 +79,30 @@ func writeRule(input *models.CreateRuleInput, create bool) *events.APIGatewayPro
 	}
 
 	item := &tableItem{
+		AlertGrouping:      input.AlertGrouping,
 		Body:               input.Body,
 		DedupPeriodMinutes: input.DedupPeriodMinutes,
 		Threshold:          input.Threshold,
 		Description:        input.Description,
 		DisplayName:        input.DisplayName,
 		Enabled:            input.Enabled,
+		Exceptions:         newExceptions(input.Exceptions, input.UserID),
 		ID:                 input.ID,
 		OutputIDs:          input.OutputIDs,
 		Reference:          input.Reference,
 		Reports:            input.Reports,
 		ResourceTypes:      input.LogTypes,
 		Runbook:            input.Runbook,
+		ScheduledQuery:     input.ScheduledQuery,
 		Severity:           input.Severity,
+		Spec:               input.Spec,
 		Tags:               input.Tags,
 		Tests:              input.Tests,
 		Type:               models.TypeRule,
 	}
+	if input.ScheduledQuery != nil {
+		item.Type = models.TypeScheduledRule
+	}
 
 	var statusCode int
 
@@ -127,7 +137,34 @@ func validateUpdateRule(input *models.CreateRuleInput) error {
 	if err := validateLogtypeSet(input.LogTypes); err != nil {
 		return errors.Errorf("rule contains invalid log type: %s", err.Error())
 	}
-	return nil
+	if input.Spec != "" {
+		if input.Body != "" {
+			return errors.New("rule cannot have both a body and a spec")
+		}
+		if _, err := rulespec.Parse(input.ID, input.Spec); err != nil {
+			return err
+		}
+	}
+	if input.ScheduledQuery != nil {
+		if input.Body != "" || input.Spec != "" {
+			return errors.New("scheduled rule cannot have a body or a spec")
+		}
+		if len(input.Tests) > 0 {
+			return errors.New("scheduled rule cannot have unit tests")
+		}
+		// Alerts are filtered by log type, so scheduled rules declare the log types they query
+		if len(input.LogTypes) == 0 {
+			return errors.New("scheduled rule must specify the log types it queries")
+		}
+		// Scheduled rules alert on query results, filter the results in the query instead
+		if len(input.Exceptions) > 0 {
+			return errors.New("scheduled rule cannot have exceptions")
+		}
+		if err := scheduledrules.Validate(input.ScheduledQuery); err != nil {
+			return err
+		}
+	}
+	return validateExceptions(input.Exceptions)
 }
 
 // enabledRuleTestsPass returns false if the rule is enabled and its tests fail.
@@ -139,6 +176,7 @@ func enabledRuleTestsPass(rule *models.UpdateRuleInput) (bool, error) {
 	testResults, err := ruleEngine.TestRule(&models.TestRuleInput{
 		Body:     rule.Body,
 		LogTypes: rule.LogTypes,
+		Spec:     rule.Spec,
 		Tests:    rule.Tests,
 	})
 	if err != nil {
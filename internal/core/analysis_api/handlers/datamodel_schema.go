@@ -0,0 +1,213 @@
+package handlers
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	"github.com/panther-labs/panther/api/lambda/analysis/models"
+	"github.com/panther-labs/panther/internal/compliance/snapshotlogs"
+	"github.com/panther-labs/panther/internal/core/logtypesapi"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logschema"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+// A single step of a mapping path: a field name followed by any number of [n] or [*] indices
+var pathSegmentRegex = regexp.MustCompile(`^([^\[\]'"*?()@$]*)((?:\[(?:\d+|\*)\])*)$`)
+
+// A top-level python function definition, capturing its name
+var methodDefinitionRegex = regexp.MustCompile(`(?m)^def\s+(\w+)\s*\(`)
+
+// errUnsupportedPath is returned for JSONPath expressions which use more than field and index access
+// (filters, recursive descent, unions etc). These paths cannot be checked against a schema.
+var errUnsupportedPath = errors.New("unsupported JSONPath syntax")
+
+// Check every mapping of a data model against the schema of each of its log types.
+//
+// Returns the unified field names supported by each log type. A path mapping is supported by a log type
+// if the path exists in its schema and a method mapping is supported by all log types as long as the body
+// defines the method. Every mapping must be supported by at least one of the log types.
+func dataModelSupportedFields(
+	ctx context.Context, logTypes []string, mappings []models.DataModelMapping, body string) (map[string][]string, error) {
+
+	schemas := make(map[string]*logschema.ValueSchema, len(logTypes))
+	for _, logType := range logTypes {
+		schema, err := resolveLogTypeSchema(ctx, logType)
+		if err != nil {
+			return nil, err
+		}
+		schemas[logType] = schema
+	}
+
+	result := make(map[string][]string, len(logTypes))
+	for _, logType := range logTypes {
+		result[logType] = []string{}
+	}
+
+	for _, mapping := range mappings {
+		if mapping.Method != "" {
+			if !definesMethod(body, mapping.Method) {
+				return nil, &dataModelMappingError{
+					errors.Errorf("mapping %s: method %s is not defined in the body", mapping.Name, mapping.Method)}
+			}
+			for _, logType := range logTypes {
+				result[logType] = append(result[logType], mapping.Name)
+			}
+			continue
+		}
+
+		supported := false
+		for _, logType := range logTypes {
+			found, err := schemaHasPath(schemas[logType], mapping.Path)
+			if err == errUnsupportedPath {
+				// We can't tell, assume the user knows what they are doing
+				found = true
+			} else if err != nil {
+				return nil, &dataModelMappingError{errors.Wrapf(err, "mapping %s", mapping.Name)}
+			}
+			if found {
+				supported = true
+				result[logType] = append(result[logType], mapping.Name)
+			}
+		}
+		if !supported {
+			return nil, &dataModelMappingError{errors.Errorf("mapping %s: path %s does not exist in the schema of log types %s",
+				mapping.Name, mapping.Path, strings.Join(logTypes, ", "))}
+		}
+	}
+
+	for _, fields := range result {
+		sort.Strings(fields)
+	}
+	return result, nil
+}
+
+// dataModelMappingError marks validation failures caused by the user input (as opposed to failing to fetch a schema)
+type dataModelMappingError struct {
+	error
+}
+
+// Load the schema of a log type from its schema record. Snapshot log types are not stored as records.
+func resolveLogTypeSchema(ctx context.Context, logType string) (*logschema.ValueSchema, error) {
+	if entry := snapshotlogs.LogTypes().Find(logType); entry != nil {
+		schema, err := logschema.InferTypeValueSchema(reflect.TypeOf(entry.Schema()))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to infer schema of log type %s", logType)
+		}
+		return schema, nil
+	}
+
+	reply, err := logtypesAPI.GetSchema(ctx, &logtypesapi.GetSchemaInput{Name: logType})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get schema of log type %s", logType)
+	}
+	if reply.Error != nil {
+		if reply.Error.Code == logtypesapi.ErrNotFound {
+			return nil, &dataModelMappingError{errors.Errorf("log type %s does not exist", logType)}
+		}
+		return nil, errors.Wrapf(reply.Error, "failed to get schema of log type %s", logType)
+	}
+	if reply.Record == nil || reply.Record.Disabled {
+		return nil, &dataModelMappingError{errors.Errorf("log type %s does not exist", logType)}
+	}
+
+	var schema logschema.Schema
+	if err := yaml.Unmarshal([]byte(reply.Record.Spec), &schema); err != nil {
+		return nil, errors.Wrapf(err, "invalid schema of log type %s", logType)
+	}
+	valueSchema, err := logschema.Resolve(&schema)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid schema of log type %s", logType)
+	}
+	return valueSchema, nil
+}
+
+// Returns true if the python body contains a top-level definition of the method
+func definesMethod(body, method string) bool {
+	for _, match := range methodDefinitionRegex.FindAllStringSubmatch(body, -1) {
+		if match[1] == method {
+			return true
+		}
+	}
+	return false
+}
+
+// Report whether a JSONPath expression (as used by the python data models) resolves in the schema.
+// Fields of type JSON can contain any nested path.
+func schemaHasPath(schema *logschema.ValueSchema, path string) (bool, error) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" || strings.Contains(path, "..") {
+		return false, errUnsupportedPath
+	}
+	// The log processor adds the standard fields to the events of every log type
+	if strings.HasPrefix(path, pantherlog.FieldPrefixJSON) {
+		return true, nil
+	}
+
+	value := schema
+	for _, segment := range strings.Split(path, ".") {
+		match := pathSegmentRegex.FindStringSubmatch(segment)
+		if match == nil {
+			return false, errUnsupportedPath
+		}
+		if value.Type == logschema.TypeJSON {
+			return true, nil
+		}
+
+		if name := match[1]; name != "" {
+			if value.Type != logschema.TypeObject {
+				return false, nil
+			}
+			value = findField(value.Fields, name)
+			if value == nil {
+				return false, nil
+			}
+		}
+
+		for _, index := range strings.SplitAfter(match[2], "]") {
+			if index == "" {
+				continue
+			}
+			if value.Type == logschema.TypeJSON {
+				return true, nil
+			}
+			if value.Type != logschema.TypeArray || value.Element == nil {
+				return false, nil
+			}
+			value = value.Element
+		}
+	}
+	return true, nil
+}
+
+func findField(fields []logschema.FieldSchema, name string) *logschema.ValueSchema {
+	for i := range fields {
+		if fields[i].Name == name {
+			return &fields[i].ValueSchema
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package handlers
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/lambda"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/analysis/models"
+	"github.com/panther-labs/panther/internal/core/logtypesapi"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+const testAuthSchema = `
+fields:
+  - name: sourceIPAddress
+    type: string
+  - name: userIdentity
+    type: object
+    fields:
+      - name: arn
+        type: string
+  - name: resources
+    type: array
+    element:
+      type: object
+      fields:
+        - name: arn
+          type: string
+  - name: requestParameters
+    type: json
+`
+
+const testFlowSchema = `
+fields:
+  - name: srcAddr
+    type: string
+`
+
+// Serve the schema records from a mock of the logtypes API
+func mockLogTypeSchemas(specs map[string]string) *testutils.LambdaMock {
+	mockLambda := &testutils.LambdaMock{}
+	for name, spec := range specs {
+		name := name
+		reply, _ := jsoniter.Marshal(&logtypesapi.GetSchemaOutput{Record: &logtypesapi.SchemaRecord{Name: name, Spec: spec}})
+		mockLambda.On("InvokeWithContext", mock.Anything, mock.MatchedBy(func(input *lambda.InvokeInput) bool {
+			var payload logtypesapi.LogTypesAPIPayload
+			return jsoniter.Unmarshal(input.Payload, &payload) == nil && payload.GetSchema != nil && payload.GetSchema.Name == name
+		}), mock.Anything).Return(&lambda.InvokeOutput{Payload: reply}, nil)
+	}
+	notFound, _ := jsoniter.Marshal(&logtypesapi.GetSchemaOutput{
+		Error: logtypesapi.NewAPIError(logtypesapi.ErrNotFound, "schema record not found")})
+	mockLambda.On("InvokeWithContext", mock.Anything, mock.Anything, mock.Anything).Return(
+		&lambda.InvokeOutput{Payload: notFound}, nil)
+	logtypesAPI = &logtypesapi.LogTypesAPILambdaClient{LambdaName: "panther-logtypes-api", LambdaAPI: mockLambda}
+	return mockLambda
+}
+
+func TestDataModelSupportedFields(t *testing.T) {
+	mockLogTypeSchemas(map[string]string{"Custom.Auth": testAuthSchema, "Custom.Flow": testFlowSchema})
+	logTypes := []string{"Custom.Auth", "Custom.Flow"}
+	body := "def get_user(event):\n    return event.get('userIdentity', {}).get('arn')\n"
+
+	fields, err := dataModelSupportedFields(context.Background(), logTypes, []models.DataModelMapping{
+		{Name: "source_ip", Path: "sourceIPAddress"},
+		{Name: "source_address", Path: "$.srcAddr"},
+		{Name: "actor_arn", Path: "$.userIdentity.arn"},
+		{Name: "resource_arn", Path: "resources[*].arn"},
+		{Name: "bucket", Path: "$.requestParameters.bucketName"},
+		{Name: "any_ip", Path: "p_any_ip_addresses[0]"},
+		{Name: "filtered", Path: "$.resources[?(@.type == 'AWS::S3::Bucket')]"},
+		{Name: "user", Method: "get_user"},
+	}, body)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"Custom.Auth": {"actor_arn", "any_ip", "bucket", "filtered", "resource_arn", "source_ip", "user"},
+		"Custom.Flow": {"any_ip", "filtered", "source_address", "user"},
+	}, fields)
+
+	_, err = dataModelSupportedFields(context.Background(), logTypes, []models.DataModelMapping{
+		{Name: "actor", Path: "userIdentity.name"},
+	}, body)
+	require.Error(t, err)
+	assert.IsType(t, &dataModelMappingError{}, err)
+
+	_, err = dataModelSupportedFields(context.Background(), logTypes, []models.DataModelMapping{
+		{Name: "user", Method: "get_username"},
+	}, body)
+	require.Error(t, err)
+	assert.IsType(t, &dataModelMappingError{}, err)
+
+	_, err = dataModelSupportedFields(context.Background(), []string{"Custom.Missing"}, nil, "")
+	require.Error(t, err)
+	assert.IsType(t, &dataModelMappingError{}, err)
+
+	// Snapshot log types are defined in go
+	fields, err = dataModelSupportedFields(context.Background(), []string{"Resource.History"}, []models.DataModelMapping{
+		{Name: "resource_id", Path: "resourceId"},
+	}, "")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"Resource.History": {"resource_id"}}, fields)
+}
+
+func TestDefinesMethod(t *testing.T) {
+	body := "def get_user(event):\n    def get_name(user):\n        return user\n    return event\n"
+	assert.True(t, definesMethod(body, "get_user"))
+	assert.False(t, definesMethod(body, "get_name"))
+	assert.False(t, definesMethod(body, "get_use"))
+}
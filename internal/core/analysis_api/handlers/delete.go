@@ -19,6 +19,7 @@ package handlers
  */
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/aws/aws-lambda-go/events"
@@ -26,7 +27,7 @@ import (
 	"github.com/panther-labs/panther/api/lambda/analysis/models"
 )
 
-func (API) DeletePolicies(input *models.DeletePoliciesInput) *events.APIGatewayProxyResponse {
+func (API) DeletePolicies(_ context.Context, input *models.DeletePoliciesInput) *events.APIGatewayProxyResponse {
 	if err := dynamoBatchDelete(input); err != nil {
 		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
 	}
@@ -42,7 +43,7 @@ func (API) DeletePolicies(input *models.DeletePoliciesInput) *events.APIGatewayP
 	return &events.APIGatewayProxyResponse{StatusCode: http.StatusOK}
 }
 
-func (API) DeleteRules(input *models.DeleteRulesInput) *events.APIGatewayProxyResponse {
+func (API) DeleteRules(_ context.Context, input *models.DeleteRulesInput) *events.APIGatewayProxyResponse {
 	if err := dynamoBatchDelete(input); err != nil {
 		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
 	}
@@ -54,7 +55,7 @@ func (API) DeleteRules(input *models.DeleteRulesInput) *events.APIGatewayProxyRe
 	return &events.APIGatewayProxyResponse{StatusCode: http.StatusOK}
 }
 
-func (API) DeleteDetections(input *models.DeletePoliciesInput) *events.APIGatewayProxyResponse {
+func (API) DeleteDetections(_ context.Context, input *models.DeletePoliciesInput) *events.APIGatewayProxyResponse {
 	if err := s3BatchDelete(input); err != nil {
 		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
 	}
@@ -70,11 +71,11 @@ func (API) DeleteDetections(input *models.DeletePoliciesInput) *events.APIGatewa
 	return &events.APIGatewayProxyResponse{StatusCode: http.StatusOK}
 }
 
-func (api API) DeleteDataModels(input *models.DeleteDataModelsInput) *events.APIGatewayProxyResponse {
-	return api.DeleteRules(input)
+func (api API) DeleteDataModels(ctx context.Context, input *models.DeleteDataModelsInput) *events.APIGatewayProxyResponse {
+	return api.DeleteRules(ctx, input)
 }
 
-func (API) DeleteGlobals(input *models.DeleteGlobalsInput) *events.APIGatewayProxyResponse {
+func (API) DeleteGlobals(_ context.Context, input *models.DeleteGlobalsInput) *events.APIGatewayProxyResponse {
 	/*
 		There are three separate actions here, and each one could fail in turn leading to different scenarios:
 
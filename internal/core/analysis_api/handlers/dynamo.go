@@ -55,6 +55,7 @@ type tableItem struct {
 	Description               string            `json:"description,omitempty"`
 	DisplayName               string            `json:"displayName,omitempty"`
 	Enabled                   bool              `json:"enabled"`
+	Exceptions                []ruleException   `json:"exceptions,omitempty"`
 	ID                        string            `json:"id"`
 	LastModified              time.Time         `json:"lastModified"`
 	LastModifiedBy            string            `json:"lastModifiedBy"`
@@ -67,18 +68,38 @@ type tableItem struct {
 	// For log analysis rules, these are actually log types
 	ResourceTypes []string `json:"resourceTypes,omitempty" dynamodbav:"resourceTypes,stringset,omitempty"`
 
-	Mappings     []models.DataModelMapping `json:"mappings,omitempty"`
-	OutputIDs    []string                  `json:"outputIds,omitempty" dynamodbav:"outputIds,stringset,omitempty"`
-	Reference    string                    `json:"reference,omitempty"`
-	Reports      map[string][]string       `json:"reports,omitempty"`
-	Runbook      string                    `json:"runbook,omitempty"`
-	Severity     compliancemodels.Severity `json:"severity"`
-	Suppressions []string                  `json:"suppressions,omitempty" dynamodbav:"suppressions,stringset,omitempty"`
-	Tags         []string                  `json:"tags,omitempty" dynamodbav:"tags,stringset,omitempty"`
-	Tests        []models.UnitTest         `json:"tests,omitempty"`
+	// For rules, how matches are grouped into alerts
+	AlertGrouping *models.AlertGrouping `json:"alertGrouping,omitempty"`
+
+	// For data models, the mapping names supported by each log type
+	SupportedFields map[string][]string `json:"supportedFields,omitempty"`
+
+	Mappings       []models.DataModelMapping `json:"mappings,omitempty"`
+	OutputIDs      []string                  `json:"outputIds,omitempty" dynamodbav:"outputIds,stringset,omitempty"`
+	Reference      string                    `json:"reference,omitempty"`
+	Reports        map[string][]string       `json:"reports,omitempty"`
+	Runbook        string                    `json:"runbook,omitempty"`
+	ScheduledQuery *models.ScheduledQuery    `json:"scheduledQuery,omitempty"`
+	Severity       compliancemodels.Severity `json:"severity"`
+	Spec           string                    `json:"spec,omitempty"`
+	Suppressions   []string                  `json:"suppressions,omitempty" dynamodbav:"suppressions,stringset,omitempty"`
+	Tags           []string                  `json:"tags,omitempty" dynamodbav:"tags,stringset,omitempty"`
+	Tests          []models.UnitTest         `json:"tests,omitempty"`
 
 	Type      models.DetectionType `json:"type"`
 	VersionID string               `json:"versionId,omitempty"`
+
+	// Set for detections installed from a detection pack
+	PackSource   string        `json:"packSource,omitempty"`
+	PackVersion  string        `json:"packVersion,omitempty"`
+	PackDefaults *packDefaults `json:"packDefaults,omitempty"`
+}
+
+// The values of the user-configurable fields as shipped in the pack, to detect local overrides
+type packDefaults struct {
+	Enabled   bool                      `json:"enabled"`
+	OutputIDs []string                  `json:"outputIds,omitempty" dynamodbav:"outputIds,stringset,omitempty"`
+	Severity  compliancemodels.Severity `json:"severity"`
 }
 
 // Add extra internal filtering fields before serializing to Dynamo
@@ -117,6 +138,8 @@ func (r *tableItem) Detection(status compliancemodels.ComplianceStatus) *models.
 		LastModified:              r.LastModified,
 		LastModifiedBy:            r.LastModifiedBy,
 		OutputIDs:                 r.OutputIDs,
+		PackSource:                r.PackSource,
+		PackVersion:               r.PackVersion,
 		Reference:                 r.Reference,
 		Reports:                   r.Reports,
 		Runbook:                   r.Runbook,
@@ -127,8 +150,12 @@ func (r *tableItem) Detection(status compliancemodels.ComplianceStatus) *models.
 	}
 	if r.Type == models.TypePolicy {
 		result.ResourceTypes = r.ResourceTypes
-	} else if r.Type == models.TypeRule {
+	} else if isRuleType(r.Type) {
+		result.AlertGrouping = r.AlertGrouping
+		result.Exceptions = r.exceptions()
 		result.LogTypes = r.ResourceTypes
+		result.ScheduledQuery = r.ScheduledQuery
+		result.Spec = r.Spec
 	}
 
 	genericapi.ReplaceMapSliceNils(result)
@@ -171,7 +198,8 @@ func (r *tableItem) Policy(status compliancemodels.ComplianceStatus) *models.Pol
 func (r *tableItem) Rule() *models.Rule {
 	r.normalize()
 	result := &models.Rule{
-		AnalysisType:       models.TypeRule,
+		AlertGrouping:      r.AlertGrouping,
+		AnalysisType:       r.Type,
 		Body:               r.Body,
 		CreatedAt:          r.CreatedAt,
 		CreatedBy:          r.CreatedBy,
@@ -179,6 +207,7 @@ func (r *tableItem) Rule() *models.Rule {
 		Description:        r.Description,
 		DisplayName:        r.DisplayName,
 		Enabled:            r.Enabled,
+		Exceptions:         r.exceptions(),
 		ID:                 r.ID,
 		LastModified:       r.LastModified,
 		LastModifiedBy:     r.LastModifiedBy,
@@ -187,7 +216,9 @@ func (r *tableItem) Rule() *models.Rule {
 		Reference:          r.Reference,
 		Reports:            r.Reports,
 		Runbook:            r.Runbook,
+		ScheduledQuery:     r.ScheduledQuery,
 		Severity:           r.Severity,
+		Spec:               r.Spec,
 		Tags:               r.Tags,
 		Tests:              r.Tests,
 		Threshold:          r.Threshold,
@@ -231,6 +262,8 @@ func (r *tableItem) DataModel() *models.DataModel {
 		LogTypes:       r.ResourceTypes,
 		Mappings:       r.Mappings,
 		VersionID:      r.VersionID,
+
+		SupportedFields: r.SupportedFields,
 	}
 	genericapi.ReplaceMapSliceNils(result)
 	return result
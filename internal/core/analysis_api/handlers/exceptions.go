@@ -0,0 +1,232 @@
+package handlers
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/api/lambda/analysis/models"
+	"github.com/panther-labs/panther/internal/log_analysis/rulespec"
+)
+
+// A rule exception as stored in the analysis table. The suppression counts are written by the rules engines
+// to a separate table, so that counting never creates a new version of the rule.
+type ruleException struct {
+	ID            string     `json:"id"`
+	Field         string     `json:"field"`
+	Operator      string     `json:"operator"`
+	Values        []string   `json:"values"`
+	ExpiresAt     *time.Time `json:"expiresAt,omitempty"`
+	Justification string     `json:"justification"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	CreatedBy     string     `json:"createdBy"`
+}
+
+// A row of the exception counts table
+type exceptionCount struct {
+	RuleID           string     `json:"ruleId"`
+	ExceptionID      string     `json:"exceptionId"`
+	SuppressedCount  int64      `json:"suppressedCount"`
+	LastSuppressedAt *time.Time `json:"lastSuppressedAt"`
+}
+
+func (r *tableItem) exceptions() []models.RuleException {
+	if r.Exceptions == nil {
+		return nil
+	}
+	result := make([]models.RuleException, len(r.Exceptions))
+	for i, e := range r.Exceptions {
+		result[i] = models.RuleException{
+			ID:            e.ID,
+			Field:         e.Field,
+			Operator:      e.Operator,
+			Values:        e.Values,
+			ExpiresAt:     e.ExpiresAt,
+			Justification: e.Justification,
+			CreatedAt:     e.CreatedAt,
+			CreatedBy:     e.CreatedBy,
+		}
+	}
+	return result
+}
+
+// Validate rule exceptions, compiling them like the rules engine does
+func validateExceptions(exceptions []models.RuleException) error {
+	ids := make(map[string]struct{}, len(exceptions))
+	for _, e := range exceptions {
+		if _, err := rulespec.NewException(e.ID, e.Field, e.Operator, e.Values, e.ExpiresAt); err != nil {
+			return err
+		}
+		if e.ID == "" {
+			continue
+		}
+		if _, ok := ids[e.ID]; ok {
+			return errors.Errorf("duplicate exception id %q", e.ID)
+		}
+		ids[e.ID] = struct{}{}
+	}
+	return nil
+}
+
+// Convert the exceptions of a rule input to the table format. Missing IDs are set by setExceptionIDs.
+//
+// A nil input keeps the existing exceptions of the rule (see writeItem), an empty list removes them.
+func newExceptions(exceptions []models.RuleException, userID string) []ruleException {
+	if exceptions == nil {
+		return nil
+	}
+	result := make([]ruleException, len(exceptions))
+	now := time.Now()
+	for i, e := range exceptions {
+		if e.CreatedAt.IsZero() {
+			e.CreatedAt = now
+			e.CreatedBy = userID
+		}
+		result[i] = ruleException{
+			ID:            e.ID,
+			Field:         e.Field,
+			Operator:      e.Operator,
+			Values:        e.Values,
+			ExpiresAt:     e.ExpiresAt,
+			Justification: e.Justification,
+			CreatedAt:     e.CreatedAt,
+			CreatedBy:     e.CreatedBy,
+		}
+	}
+	return result
+}
+
+// Set the missing IDs of exceptions, keeping the ID of an existing exception with the same condition.
+//
+// The suppression counts are keyed by exception ID, so a client which saves a rule without the IDs
+// (e.g. from a file) does not reset them.
+func setExceptionIDs(exceptions, existing []ruleException) {
+	used := make(map[string]struct{}, len(exceptions))
+	for _, e := range exceptions {
+		if e.ID != "" {
+			used[e.ID] = struct{}{}
+		}
+	}
+	for i := range exceptions {
+		e := &exceptions[i]
+		if e.ID != "" {
+			continue
+		}
+		for j := range existing {
+			old := &existing[j]
+			if _, ok := used[old.ID]; ok || !sameCondition(e, old) {
+				continue
+			}
+			e.ID, e.CreatedAt, e.CreatedBy = old.ID, old.CreatedAt, old.CreatedBy
+			break
+		}
+		if e.ID == "" {
+			e.ID = uuid.New().String()
+		}
+		used[e.ID] = struct{}{}
+	}
+}
+
+func sameCondition(first, second *ruleException) bool {
+	if first.Field != second.Field || first.Operator != second.Operator || len(first.Values) != len(second.Values) {
+		return false
+	}
+	for i, value := range first.Values {
+		if second.Values[i] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Compare two lists of exceptions, treating nil and empty as equal.
+//
+// Times are compared by value: reflect.DeepEqual would also compare their location and monotonic clock.
+func exceptionsEqual(first, second []ruleException) bool {
+	if len(first) != len(second) {
+		return false
+	}
+	for i := range first {
+		a, b := &first[i], &second[i]
+		if a.ID != b.ID || a.Justification != b.Justification || a.CreatedBy != b.CreatedBy ||
+			!sameCondition(a, b) || !a.CreatedAt.Equal(b.CreatedAt) {
+			return false
+		}
+		if a.ExpiresAt == nil || b.ExpiresAt == nil {
+			if a.ExpiresAt != b.ExpiresAt {
+				return false
+			}
+		} else if !a.ExpiresAt.Equal(*b.ExpiresAt) {
+			return false
+		}
+	}
+	return true
+}
+
+// Fill in the number of events suppressed by each exception of a rule
+func addExceptionCounts(ruleID string, exceptions []models.RuleException) error {
+	if len(exceptions) == 0 {
+		return nil
+	}
+	keyCondition := expression.Key("ruleId").Equal(expression.Value(ruleID))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		return errors.Wrap(err, "failed to build exception counts query")
+	}
+
+	counts := make(map[string]exceptionCount)
+	input := &dynamodb.QueryInput{
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		TableName:                 aws.String(env.ExceptionCountsTable),
+	}
+	for {
+		output, err := dynamoClient.Query(input)
+		if err != nil {
+			return errors.Wrap(err, "failed to query exception counts")
+		}
+		var rows []exceptionCount
+		if err := dynamodbattribute.UnmarshalListOfMaps(output.Items, &rows); err != nil {
+			return errors.Wrap(err, "failed to unmarshal exception counts")
+		}
+		for _, row := range rows {
+			counts[row.ExceptionID] = row
+		}
+		if len(output.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+
+	for i := range exceptions {
+		if count, ok := counts[exceptions[i].ID]; ok {
+			exceptions[i].SuppressedCount = count.SuppressedCount
+			exceptions[i].LastSuppressedAt = count.LastSuppressedAt
+		}
+	}
+	return nil
+}
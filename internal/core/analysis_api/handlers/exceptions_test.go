@@ -0,0 +1,135 @@
+package handlers
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/analysis/models"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+func TestValidateExceptions(t *testing.T) {
+	valid := models.RuleException{ID: "a", Field: "user.name", Operator: "equals", Values: []string{"scanner"}}
+	assert.NoError(t, validateExceptions([]models.RuleException{valid, {Field: "ip", Operator: "cidr", Values: []string{"10.0.0.0/8"}}}))
+	assert.Error(t, validateExceptions([]models.RuleException{valid, valid}))
+	assert.Error(t, validateExceptions([]models.RuleException{{Field: "ip", Operator: "cidr", Values: []string{"10.0.0.1"}}}))
+	assert.Error(t, validateExceptions([]models.RuleException{{Field: "user", Operator: "regex", Values: []string{"("}}}))
+}
+
+func TestNewExceptions(t *testing.T) {
+	assert.Nil(t, newExceptions(nil, "alice"))
+	assert.Equal(t, []ruleException{}, newExceptions([]models.RuleException{}, "alice"))
+
+	createdAt := time.Date(2020, 11, 2, 10, 0, 0, 0, time.UTC)
+	result := newExceptions([]models.RuleException{
+		{ID: "existing", Field: "user", Operator: "equals", Values: []string{"a"}, CreatedAt: createdAt, CreatedBy: "bob"},
+		{Field: "user", Operator: "equals", Values: []string{"b"}, SuppressedCount: 10},
+	}, "alice")
+	require.Len(t, result, 2)
+	assert.Equal(t, "existing", result[0].ID)
+	assert.Equal(t, createdAt, result[0].CreatedAt)
+	assert.Equal(t, "bob", result[0].CreatedBy)
+	assert.Empty(t, result[1].ID)
+	assert.False(t, result[1].CreatedAt.IsZero())
+	assert.Equal(t, "alice", result[1].CreatedBy)
+}
+
+func TestSetExceptionIDs(t *testing.T) {
+	createdAt := time.Date(2020, 11, 2, 10, 0, 0, 0, time.UTC)
+	existing := []ruleException{
+		{ID: "a", Field: "user", Operator: "equals", Values: []string{"a"}, CreatedAt: createdAt, CreatedBy: "bob"},
+		{ID: "b", Field: "user", Operator: "equals", Values: []string{"b"}, CreatedAt: createdAt, CreatedBy: "bob"},
+	}
+	exceptions := []ruleException{
+		{Field: "user", Operator: "equals", Values: []string{"a"}, CreatedBy: "alice"},
+		{ID: "b", Field: "user", Operator: "equals", Values: []string{"c"}},
+		// Same condition as "b", but that ID is already taken
+		{Field: "user", Operator: "equals", Values: []string{"b"}, CreatedBy: "alice"},
+	}
+	setExceptionIDs(exceptions, existing)
+
+	assert.Equal(t, "a", exceptions[0].ID)
+	assert.Equal(t, createdAt, exceptions[0].CreatedAt)
+	assert.Equal(t, "bob", exceptions[0].CreatedBy)
+	assert.Equal(t, "b", exceptions[1].ID)
+	assert.Len(t, exceptions[2].ID, 36)
+	assert.Equal(t, "alice", exceptions[2].CreatedBy)
+}
+
+func TestExceptionsEqual(t *testing.T) {
+	expiresAt := time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC)
+	sameTime := expiresAt.In(time.FixedZone("EST", -5*3600))
+	first := []ruleException{{ID: "a", Field: "user", Operator: "equals", Values: []string{"a"}, ExpiresAt: &expiresAt}}
+	second := []ruleException{{ID: "a", Field: "user", Operator: "equals", Values: []string{"a"}, ExpiresAt: &sameTime}}
+	assert.True(t, exceptionsEqual(first, second))
+	assert.True(t, exceptionsEqual(nil, []ruleException{}))
+
+	second[0].ExpiresAt = nil
+	assert.False(t, exceptionsEqual(first, second))
+	second[0].ExpiresAt = &sameTime
+	second[0].Values = []string{"b"}
+	assert.False(t, exceptionsEqual(first, second))
+}
+
+func TestAddExceptionCounts(t *testing.T) {
+	mockDynamo := &testutils.DynamoDBMock{}
+	dynamoClient = mockDynamo
+	env.ExceptionCountsTable = "counts"
+
+	mockDynamo.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+		return *input.TableName == "counts" && input.ExclusiveStartKey == nil
+	})).Return(&dynamodb.QueryOutput{
+		Items: []map[string]*dynamodb.AttributeValue{
+			{
+				"ruleId":           {S: aws.String("Rule")},
+				"exceptionId":      {S: aws.String("a")},
+				"suppressedCount":  {N: aws.String("5")},
+				"lastSuppressedAt": {S: aws.String("2020-11-02T10:00:00Z")},
+			},
+		},
+		LastEvaluatedKey: map[string]*dynamodb.AttributeValue{"exceptionId": {S: aws.String("a")}},
+	}, nil).Once()
+	mockDynamo.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+		return input.ExclusiveStartKey != nil
+	})).Return(&dynamodb.QueryOutput{
+		Items: []map[string]*dynamodb.AttributeValue{
+			{
+				"ruleId":          {S: aws.String("Rule")},
+				"exceptionId":     {S: aws.String("removed")},
+				"suppressedCount": {N: aws.String("7")},
+			},
+		},
+	}, nil).Once()
+
+	exceptions := []models.RuleException{{ID: "a"}, {ID: "b"}}
+	require.NoError(t, addExceptionCounts("Rule", exceptions))
+	assert.Equal(t, int64(5), exceptions[0].SuppressedCount)
+	assert.Equal(t, time.Date(2020, 11, 2, 10, 0, 0, 0, time.UTC), *exceptions[0].LastSuppressedAt)
+	assert.Equal(t, int64(0), exceptions[1].SuppressedCount)
+	assert.Nil(t, exceptions[1].LastSuppressedAt)
+	mockDynamo.AssertExpectations(t)
+}
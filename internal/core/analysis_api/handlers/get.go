@@ -19,29 +19,31 @@ package handlers
  */
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
 
 	"github.com/aws/aws-lambda-go/events"
+	"go.uber.org/zap"
 
 	"github.com/panther-labs/panther/api/lambda/analysis/models"
 	"github.com/panther-labs/panther/pkg/gatewayapi"
 )
 
-func (API) GetPolicy(input *models.GetPolicyInput) *events.APIGatewayProxyResponse {
+func (API) GetPolicy(_ context.Context, input *models.GetPolicyInput) *events.APIGatewayProxyResponse {
 	return handleGet(input.ID, input.VersionID, models.TypePolicy)
 }
 
-func (API) GetRule(input *models.GetRuleInput) *events.APIGatewayProxyResponse {
+func (API) GetRule(_ context.Context, input *models.GetRuleInput) *events.APIGatewayProxyResponse {
 	return handleGet(input.ID, input.VersionID, models.TypeRule)
 }
 
-func (API) GetGlobal(input *models.GetGlobalInput) *events.APIGatewayProxyResponse {
+func (API) GetGlobal(_ context.Context, input *models.GetGlobalInput) *events.APIGatewayProxyResponse {
 	return handleGet(input.ID, input.VersionID, models.TypeGlobal)
 }
 
-func (API) GetDataModel(input *models.GetDataModelInput) *events.APIGatewayProxyResponse {
+func (API) GetDataModel(_ context.Context, input *models.GetDataModelInput) *events.APIGatewayProxyResponse {
 	return handleGet(input.ID, input.VersionID, models.TypeDataModel)
 }
 
@@ -68,7 +70,8 @@ func handleGet(itemID, versionID string, codeType models.DetectionType) *events.
 			StatusCode: http.StatusInternalServerError,
 		}
 	}
-	if item == nil || item.Type != codeType {
+	// Scheduled rules are returned by GetRule so alerts can be delivered like any other rule
+	if item == nil || (item.Type != codeType && !(codeType == models.TypeRule && isRuleType(item.Type))) {
 		return &events.APIGatewayProxyResponse{
 			Body:       fmt.Sprintf("Cannot find %s (%s)", itemID, codeType),
 			StatusCode: http.StatusNotFound,
@@ -94,6 +97,10 @@ func handleGet(itemID, versionID string, codeType models.DetectionType) *events.
 		if rule.Threshold == 0 {
 			rule.Threshold = defaultRuleThreshold
 		}
+		if err := addExceptionCounts(rule.ID, rule.Exceptions); err != nil {
+			// The counts are informational, return the rule without them
+			zap.L().Warn("failed to load exception counts", zap.String("ruleId", rule.ID), zap.Error(err))
+		}
 		return gatewayapi.MarshalResponse(rule, http.StatusOK)
 
 	case models.TypeGlobal:
@@ -19,6 +19,7 @@ package handlers
  */
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
@@ -31,7 +32,7 @@ import (
 	"github.com/panther-labs/panther/pkg/gatewayapi"
 )
 
-func (API) ListDataModels(input *models.ListDataModelsInput) *events.APIGatewayProxyResponse {
+func (API) ListDataModels(_ context.Context, input *models.ListDataModelsInput) *events.APIGatewayProxyResponse {
 	// Standardize input
 	input.NameContains = strings.ToLower(input.NameContains)
 	if input.Page == 0 {
@@ -19,6 +19,7 @@ package handlers
  */
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
@@ -31,7 +32,7 @@ import (
 	"github.com/panther-labs/panther/pkg/gatewayapi"
 )
 
-func (API) ListDetections(input *models.ListDetectionsInput) *events.APIGatewayProxyResponse {
+func (API) ListDetections(_ context.Context, input *models.ListDetectionsInput) *events.APIGatewayProxyResponse {
 	projectComplianceStatus := stdDetectionListInput(input)
 
 	// Scan dynamo
@@ -110,7 +111,7 @@ func stdDetectionListInput(input *models.ListDetectionsInput) bool {
 		input.SortDir = defaultSortDir
 	}
 	if len(input.AnalysisTypes) == 0 {
-		input.AnalysisTypes = []models.DetectionType{models.TypePolicy, models.TypeRule}
+		input.AnalysisTypes = []models.DetectionType{models.TypePolicy, models.TypeRule, models.TypeScheduledRule}
 	}
 	// If a compliance status was specified, we can only query policies.
 	// This is a unique field because we look it up from another table. For other fields (such as
@@ -19,6 +19,7 @@ package handlers
  */
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/aws/aws-lambda-go/events"
@@ -28,7 +29,7 @@ import (
 	"github.com/panther-labs/panther/pkg/gatewayapi"
 )
 
-func (API) ListGlobals(input *models.ListGlobalsInput) *events.APIGatewayProxyResponse {
+func (API) ListGlobals(_ context.Context, input *models.ListGlobalsInput) *events.APIGatewayProxyResponse {
 	// Set defaults
 	if input.Page == 0 {
 		input.Page = defaultPage
@@ -19,6 +19,7 @@ package handlers
  */
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
@@ -33,7 +34,7 @@ import (
 )
 
 // ListPolicies is being deprecated. Use ListDetections and specify AnalysisType POLICY instead
-func (API) ListPolicies(input *models.ListPoliciesInput) *events.APIGatewayProxyResponse {
+func (API) ListPolicies(_ context.Context, input *models.ListPoliciesInput) *events.APIGatewayProxyResponse {
 	stdPolicyListInput(input)
 
 	// Scan dynamo
@@ -19,6 +19,7 @@ package handlers
  */
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
@@ -31,7 +32,7 @@ import (
 )
 
 // ListRules is being deprecated. Use ListDetections and specify AnalysisType RULE instead
-func (API) ListRules(input *models.ListRulesInput) *events.APIGatewayProxyResponse {
+func (API) ListRules(_ context.Context, input *models.ListRulesInput) *events.APIGatewayProxyResponse {
 	stdRuleListInput(input)
 
 	// Scan dynamo
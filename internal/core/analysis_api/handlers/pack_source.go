@@ -0,0 +1,216 @@
+package handlers
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+	"golang.org/x/mod/semver"
+
+	"github.com/panther-labs/panther/api/lambda/analysis/models"
+	"github.com/panther-labs/panther/internal/log_analysis/managedschemas"
+)
+
+const (
+	// The asset of panther-analysis releases with all detections, in the format of the panther_analysis_tool
+	defaultPackAssetName = "panther-analysis-all.zip"
+	packFileSuffix       = ".zip"
+	// Pack zipfiles are read in memory
+	maxPackSize = 100 * 1024 * 1024
+)
+
+// Check the fields required by the source type
+func validatePackSource(source *models.PackSource) error {
+	switch source.SourceType {
+	case models.PackSourceGitHub:
+		if source.Owner == "" || source.Repository == "" {
+			return errors.New("GitHub pack sources require an owner and a repository")
+		}
+		if source.S3URL != "" {
+			return errors.New("GitHub pack sources cannot have an S3 URL")
+		}
+		if source.AssetName == "" {
+			source.AssetName = defaultPackAssetName
+		}
+	case models.PackSourceS3:
+		if source.Owner != "" || source.Repository != "" || source.AssetName != "" {
+			return errors.New("S3 pack sources cannot have GitHub settings")
+		}
+		bucket, _, err := parseS3URL(source.S3URL)
+		if err != nil {
+			return err
+		}
+		if !isDetectionPackBucket(bucket) {
+			return errors.Errorf("S3 bucket %q is not configured to host detection packs", bucket)
+		}
+	default:
+		return errors.Errorf("unknown pack source type %q", source.SourceType)
+	}
+	return nil
+}
+
+// The analysis API can only read the buckets allowed by the deployment
+func isDetectionPackBucket(bucket string) bool {
+	for _, allowed := range env.DetectionPackBuckets {
+		if bucket == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// Split an s3://bucket/prefix URL, the prefix always ends with a slash if not empty
+func parseS3URL(s3URL string) (string, string, error) {
+	parsed, err := url.Parse(s3URL)
+	if err != nil || parsed.Scheme != "s3" || parsed.Host == "" {
+		return "", "", errors.Errorf("invalid S3 URL %q, expected s3://bucket/prefix", s3URL)
+	}
+	prefix := strings.TrimPrefix(parsed.Path, "/")
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return parsed.Host, prefix, nil
+}
+
+// List the versions of a pack, from oldest to newest
+func listPackVersions(ctx context.Context, source *models.PackSource) ([]models.PackVersion, error) {
+	if source.SourceType == models.PackSourceGitHub {
+		return listGitHubPackVersions(ctx, source)
+	}
+	return listS3PackVersions(source)
+}
+
+func listGitHubPackVersions(ctx context.Context, source *models.PackSource) ([]models.PackVersion, error) {
+	repository := managedschemas.GitHubRepository{
+		Owner:     source.Owner,
+		Repo:      source.Repository,
+		Client:    githubClient,
+		AssetName: source.AssetName,
+	}
+	// The release feed only includes releases with a semver tag and the pack asset
+	releases, err := repository.ReleaseFeed(ctx, "")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list releases of %s/%s", source.Owner, source.Repository)
+	}
+	versions := make([]models.PackVersion, 0, len(releases))
+	for _, release := range releases {
+		versions = append(versions, models.PackVersion{
+			Version:     release.Tag,
+			Description: release.Description,
+			URL:         release.ManifestURL,
+		})
+	}
+	return versions, nil
+}
+
+func listS3PackVersions(source *models.PackSource) ([]models.PackVersion, error) {
+	bucket, prefix, err := parseS3URL(source.S3URL)
+	if err != nil {
+		return nil, err
+	}
+	var versions []models.PackVersion
+	err = s3Client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket:    &bucket,
+		Prefix:    &prefix,
+		Delimiter: aws.String("/"),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			key := aws.StringValue(object.Key)
+			if !strings.HasSuffix(key, packFileSuffix) {
+				continue
+			}
+			versions = append(versions, models.PackVersion{
+				Version: strings.TrimSuffix(path.Base(key), packFileSuffix),
+				URL:     "s3://" + bucket + "/" + key,
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list %s", source.S3URL)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		left, right := versions[i].Version, versions[j].Version
+		if semver.IsValid(left) && semver.IsValid(right) {
+			return semver.Compare(left, right) < 0
+		}
+		return left < right
+	})
+	return versions, nil
+}
+
+// Download the zipfile of a pack version
+func downloadPack(ctx context.Context, source *models.PackSource, version string) ([]byte, error) {
+	if source.SourceType == models.PackSourceS3 {
+		bucket, prefix, err := parseS3URL(source.S3URL)
+		if err != nil {
+			return nil, err
+		}
+		output, err := s3Client.GetObject(&s3.GetObjectInput{
+			Bucket: &bucket,
+			Key:    aws.String(prefix + version + packFileSuffix),
+		})
+		if err != nil {
+			if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == s3.ErrCodeNoSuchKey {
+				return nil, &packVersionError{version: version, sourceID: source.ID}
+			}
+			return nil, errors.Wrapf(err, "failed to download version %s of pack %s", version, source.ID)
+		}
+		defer output.Body.Close()
+		data, err := ioutil.ReadAll(io.LimitReader(output.Body, maxPackSize+1))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to download version %s of pack %s", version, source.ID)
+		}
+		if len(data) > maxPackSize {
+			return nil, errors.Errorf("version %s of pack %s exceeds %d bytes", version, source.ID, maxPackSize)
+		}
+		return data, nil
+	}
+
+	versions, err := listGitHubPackVersions(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range versions {
+		if v.Version == version {
+			return managedschemas.DownloadFile(ctx, nil, v.URL)
+		}
+	}
+	return nil, &packVersionError{version: version, sourceID: source.ID}
+}
+
+// The requested version is not available from the pack source
+type packVersionError struct {
+	version  string
+	sourceID string
+}
+
+func (e *packVersionError) Error() string {
+	return "version " + e.version + " of pack " + e.sourceID + " does not exist"
+}
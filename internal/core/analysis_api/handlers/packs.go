@@ -0,0 +1,463 @@
+package handlers
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/analysis/models"
+	"github.com/panther-labs/panther/pkg/gatewayapi"
+)
+
+// Pack sources are stored in the analysis table, next to the detections they install
+type packSourceItem struct {
+	models.PackSource
+	Type models.DetectionType `json:"type"`
+}
+
+// Detections which are installed and removed by packs
+var packDetectionTypes = []models.DetectionType{
+	models.TypeDataModel, models.TypeGlobal, models.TypePolicy, models.TypeRule, models.TypeScheduledRule,
+}
+
+func (API) CreatePackSource(_ context.Context, input *models.CreatePackSourceInput) *events.APIGatewayProxyResponse {
+	source := &models.PackSource{
+		ID:         input.ID,
+		SourceType: input.SourceType,
+		Owner:      input.Owner,
+		Repository: input.Repository,
+		AssetName:  input.AssetName,
+		S3URL:      input.S3URL,
+		CreatedAt:  time.Now(),
+		CreatedBy:  input.UserID,
+	}
+	if err := validatePackSource(source); err != nil {
+		return &events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: http.StatusBadRequest}
+	}
+	if err := putPackSource(source, true); err != nil {
+		if err == errExists {
+			return &events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: http.StatusConflict}
+		}
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+	return gatewayapi.MarshalResponse(source, http.StatusCreated)
+}
+
+// DeletePackSources removes pack sources. The detections installed from them are kept.
+func (API) DeletePackSources(_ context.Context, input *models.DeletePackSourcesInput) *events.APIGatewayProxyResponse {
+	condition := expression.Equal(expression.Name("type"), expression.Value(models.TypePack))
+	expr, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		zap.L().Error("failed to build delete condition", zap.Error(err))
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+	for _, id := range input.IDs {
+		_, err := dynamoClient.DeleteItem(&dynamodb.DeleteItemInput{
+			ConditionExpression:       expr.Condition(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			Key:                       tableKey(id),
+			TableName:                 &env.Table,
+		})
+		if err != nil {
+			if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+				continue // not a pack source
+			}
+			zap.L().Error("dynamoClient.DeleteItem failed", zap.Error(err))
+			return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+		}
+	}
+	return &events.APIGatewayProxyResponse{StatusCode: http.StatusOK}
+}
+
+func (API) ListPackSources(_ context.Context, _ *models.ListPackSourcesInput) *events.APIGatewayProxyResponse {
+	scanInput, err := buildScanInput([]models.DetectionType{models.TypePack}, nil)
+	if err != nil {
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+	result := models.ListPackSourcesOutput{Sources: []models.PackSource{}}
+	var unmarshalErr error
+	err = dynamoClient.ScanPages(scanInput, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		var items []packSourceItem
+		if unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &items); unmarshalErr != nil {
+			return false
+		}
+		for _, item := range items {
+			result.Sources = append(result.Sources, item.PackSource)
+		}
+		return true
+	})
+	if err == nil {
+		err = unmarshalErr
+	}
+	if err != nil {
+		zap.L().Error("failed to scan pack sources", zap.Error(err))
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+	sort.Slice(result.Sources, func(i, j int) bool { return result.Sources[i].ID < result.Sources[j].ID })
+	return gatewayapi.MarshalResponse(&result, http.StatusOK)
+}
+
+func (API) ListPackVersions(ctx context.Context, input *models.ListPackVersionsInput) *events.APIGatewayProxyResponse {
+	source, response := loadPackSource(input.SourceID)
+	if response != nil {
+		return response
+	}
+	versions, err := listPackVersions(ctx, source)
+	if err != nil {
+		zap.L().Error("failed to list pack versions", zap.String("sourceId", source.ID), zap.Error(err))
+		return &events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: http.StatusBadGateway}
+	}
+	if versions == nil {
+		versions = []models.PackVersion{}
+	}
+	return gatewayapi.MarshalResponse(&models.ListPackVersionsOutput{Versions: versions}, http.StatusOK)
+}
+
+// PreviewPack lists the changes that applying a pack version would make
+func (API) PreviewPack(ctx context.Context, input *models.PreviewPackInput) *events.APIGatewayProxyResponse {
+	source, response := loadPackSource(input.SourceID)
+	if response != nil {
+		return response
+	}
+	plan, response := buildPackPlan(ctx, source, input.Version)
+	if response != nil {
+		return response
+	}
+	return gatewayapi.MarshalResponse(&models.PreviewPackOutput{Changes: plan.changes}, http.StatusOK)
+}
+
+// ApplyPack installs a pack version, keeping the fields which were overridden locally
+func (API) ApplyPack(ctx context.Context, input *models.ApplyPackInput) *events.APIGatewayProxyResponse {
+	source, response := loadPackSource(input.SourceID)
+	if response != nil {
+		return response
+	}
+	return applyPack(ctx, source, input.Version, input.UserID)
+}
+
+// RollbackPack re-applies the version which was deployed before the current one
+func (API) RollbackPack(ctx context.Context, input *models.RollbackPackInput) *events.APIGatewayProxyResponse {
+	source, response := loadPackSource(input.SourceID)
+	if response != nil {
+		return response
+	}
+	if source.PreviousVersion == "" {
+		return &events.APIGatewayProxyResponse{
+			Body:       "pack " + source.ID + " has no previous version",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+	return applyPack(ctx, source, source.PreviousVersion, input.UserID)
+}
+
+func applyPack(ctx context.Context, source *models.PackSource, version, userID string) *events.APIGatewayProxyResponse {
+	plan, response := buildPackPlan(ctx, source, version)
+	if response != nil {
+		return response
+	}
+
+	updateGlobals := false
+	for _, result := range writeItems(plan.writes, userID) {
+		if result.err != nil {
+			zap.L().Error("failed to write pack detection", zap.String("id", result.item.ID), zap.Error(result.err))
+			return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+		}
+		updateGlobals = updateGlobals || result.item.Type == models.TypeGlobal
+	}
+
+	if len(plan.deletes) > 0 {
+		input := &models.DeletePoliciesInput{}
+		var policies []models.DeleteEntry
+		for _, item := range plan.deletes {
+			input.Entries = append(input.Entries, models.DeleteEntry{ID: item.ID})
+			if item.Type == models.TypePolicy {
+				policies = append(policies, models.DeleteEntry{ID: item.ID})
+			}
+			updateGlobals = updateGlobals || item.Type == models.TypeGlobal
+		}
+		if err := dynamoBatchDelete(input); err != nil {
+			return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+		}
+		if err := s3BatchDelete(input); err != nil {
+			return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+		}
+		if len(policies) > 0 {
+			if err := complianceBatchDelete(policies, []string{}); err != nil {
+				return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+			}
+		}
+	}
+
+	if updateGlobals {
+		if err := updateLayer(); err != nil {
+			return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+		}
+	}
+
+	now := time.Now()
+	if source.AppliedVersion != version {
+		source.PreviousVersion = source.AppliedVersion
+	}
+	source.AppliedVersion = version
+	source.AppliedAt = &now
+	source.AppliedBy = userID
+	if err := putPackSource(source, false); err != nil {
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+	return gatewayapi.MarshalResponse(&models.ApplyPackOutput{Source: *source, Changes: plan.changes}, http.StatusOK)
+}
+
+func loadPackSource(id string) (*models.PackSource, *events.APIGatewayProxyResponse) {
+	response, err := dynamoClient.GetItem(&dynamodb.GetItemInput{
+		ConsistentRead: aws.Bool(true),
+		Key:            tableKey(id),
+		TableName:      &env.Table,
+	})
+	if err != nil {
+		zap.L().Error("dynamoClient.GetItem failed", zap.Error(err))
+		return nil, &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+	var item packSourceItem
+	if err := dynamodbattribute.UnmarshalMap(response.Item, &item); err != nil {
+		zap.L().Error("dynamodbattribute.UnmarshalMap failed", zap.Error(err))
+		return nil, &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+	if item.Type != models.TypePack {
+		return nil, &events.APIGatewayProxyResponse{
+			Body:       "Cannot find pack source " + id,
+			StatusCode: http.StatusNotFound,
+		}
+	}
+	return &item.PackSource, nil
+}
+
+// Write a pack source, which must be new if create is set
+func putPackSource(source *models.PackSource, create bool) error {
+	body, err := dynamodbattribute.MarshalMap(&packSourceItem{PackSource: *source, Type: models.TypePack})
+	if err != nil {
+		zap.L().Error("dynamodbattribute.MarshalMap failed", zap.Error(err))
+		return err
+	}
+	input := &dynamodb.PutItemInput{Item: body, TableName: &env.Table}
+	if create {
+		input.ConditionExpression = aws.String("attribute_not_exists(id)")
+	}
+	if _, err := dynamoClient.PutItem(input); err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return errExists
+		}
+		zap.L().Error("dynamoClient.PutItem failed", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// packPlan lists the writes and deletes which deploy a pack version
+type packPlan struct {
+	changes []models.PackChange
+	writes  []*tableItem
+	deletes []*tableItem
+}
+
+func buildPackPlan(ctx context.Context, source *models.PackSource, version string) (*packPlan, *events.APIGatewayProxyResponse) {
+	content, err := downloadPack(ctx, source, version)
+	if err != nil {
+		if _, ok := err.(*packVersionError); ok {
+			return nil, &events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: http.StatusNotFound}
+		}
+		zap.L().Error("failed to download pack", zap.String("sourceId", source.ID), zap.Error(err))
+		return nil, &events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: http.StatusBadGateway}
+	}
+	packed, err := extractZipArchive(ctx, content)
+	if err != nil {
+		return nil, &events.APIGatewayProxyResponse{
+			Body:       "version " + version + " of pack " + source.ID + " is invalid: " + err.Error(),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	scanInput, err := buildScanInput(packDetectionTypes, nil)
+	if err != nil {
+		return nil, &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+	deployed := make(map[string]*tableItem)
+	err = scanPages(scanInput, func(item tableItem) error {
+		deployed[item.ID] = &item
+		return nil
+	})
+	if err != nil {
+		return nil, &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+
+	plan, err := planPack(source.ID, version, packed, deployed)
+	if err != nil {
+		return nil, &events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: http.StatusConflict}
+	}
+	return plan, nil
+}
+
+// planPack compares the detections of a pack version with the deployed detections
+func planPack(sourceID, version string, packed, deployed map[string]*tableItem) (*packPlan, error) {
+	plan := &packPlan{changes: []models.PackChange{}}
+
+	ids := make([]string, 0, len(packed))
+	for id := range packed {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		item := packed[id]
+		item.PackSource = sourceID
+		item.PackVersion = version
+		item.PackDefaults = &packDefaults{Enabled: item.Enabled, OutputIDs: item.OutputIDs, Severity: item.Severity}
+
+		current := deployed[id]
+		if current == nil {
+			plan.writes = append(plan.writes, item)
+			plan.changes = append(plan.changes, models.PackChange{
+				ID: id, AnalysisType: item.Type, Change: models.PackChangeAdded, Fields: []string{}, Overridden: []string{},
+			})
+			continue
+		}
+		if current.PackSource != "" && current.PackSource != sourceID {
+			return nil, errors.Errorf("%s %s is managed by pack %s", current.Type, id, current.PackSource)
+		}
+		if current.Type != item.Type && !(isRuleType(current.Type) && isRuleType(item.Type)) {
+			return nil, errors.Errorf("%s %s cannot be replaced by a %s", current.Type, id, item.Type)
+		}
+
+		overridden := keepOverrides(current, item)
+		fields := changedFields(current, item)
+		if len(fields) > 0 || current.PackSource != sourceID {
+			// Detections deployed before the pack are adopted even if they did not change
+			plan.writes = append(plan.writes, item)
+		}
+		if len(fields) > 0 {
+			plan.changes = append(plan.changes, models.PackChange{
+				ID: id, AnalysisType: item.Type, Change: models.PackChangeModified, Fields: fields, Overridden: overridden,
+			})
+		}
+	}
+
+	ids = ids[:0]
+	for id, item := range deployed {
+		if item.PackSource == sourceID && packed[id] == nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		item := deployed[id]
+		plan.deletes = append(plan.deletes, item)
+		plan.changes = append(plan.changes, models.PackChange{
+			ID: id, AnalysisType: item.Type, Change: models.PackChangeRemoved, Fields: []string{}, Overridden: []string{},
+		})
+	}
+	return plan, nil
+}
+
+// keepOverrides copies the fields which were changed locally from the deployed detection, returning
+// the fields which differ from the pack.
+//
+// Detections deployed before the pack have no defaults, all of their fields are treated as overrides.
+func keepOverrides(current, item *tableItem) []string {
+	defaults := current.PackDefaults
+	overridden := []string{}
+	if (defaults == nil || current.Enabled != defaults.Enabled) && current.Enabled != item.Enabled {
+		item.Enabled = current.Enabled
+		overridden = append(overridden, "enabled")
+	}
+	if (defaults == nil || !setEquality(current.OutputIDs, defaults.OutputIDs)) &&
+		!setEquality(current.OutputIDs, item.OutputIDs) {
+
+		item.OutputIDs = current.OutputIDs
+		overridden = append(overridden, "outputIds")
+	}
+	if (defaults == nil || current.Severity != defaults.Severity) && current.Severity != item.Severity {
+		item.Severity = current.Severity
+		overridden = append(overridden, "severity")
+	}
+	return overridden
+}
+
+// changedFields lists the API fields which differ between the deployed detection and its replacement
+func changedFields(oldItem, newItem *tableItem) []string {
+	resourceTypes := "resourceTypes"
+	if isRuleType(newItem.Type) || newItem.Type == models.TypeDataModel {
+		resourceTypes = "logTypes"
+	}
+	checks := []struct {
+		field string
+		equal bool
+	}{
+		{"alertGrouping", reflect.DeepEqual(oldItem.AlertGrouping, newItem.AlertGrouping)},
+		{"analysisType", oldItem.Type == newItem.Type},
+		{"autoRemediationId", oldItem.AutoRemediationID == newItem.AutoRemediationID},
+		{"autoRemediationParameters", mapsEqual(oldItem.AutoRemediationParameters, newItem.AutoRemediationParameters)},
+		{"body", oldItem.Body == newItem.Body},
+		{"dedupPeriodMinutes", oldItem.DedupPeriodMinutes == newItem.DedupPeriodMinutes},
+		{"description", oldItem.Description == newItem.Description},
+		{"displayName", oldItem.DisplayName == newItem.DisplayName},
+		{"enabled", oldItem.Enabled == newItem.Enabled},
+		{"exceptions", exceptionsEqual(oldItem.Exceptions, newItem.Exceptions)},
+		{resourceTypes, setEquality(oldItem.ResourceTypes, newItem.ResourceTypes)},
+		{"mappings", len(oldItem.Mappings) == len(newItem.Mappings) && mappingEquality(oldItem, newItem)},
+		{"outputIds", setEquality(oldItem.OutputIDs, newItem.OutputIDs)},
+		{"reference", oldItem.Reference == newItem.Reference},
+		{"reports", mapsEqual(oldItem.Reports, newItem.Reports)},
+		{"runbook", oldItem.Runbook == newItem.Runbook},
+		{"scheduledQuery", scheduledQueriesEqual(oldItem.ScheduledQuery, newItem.ScheduledQuery)},
+		{"severity", oldItem.Severity == newItem.Severity},
+		{"spec", oldItem.Spec == newItem.Spec},
+		{"suppressions", setEquality(oldItem.Suppressions, newItem.Suppressions)},
+		{"tags", setEquality(oldItem.Tags, newItem.Tags)},
+		{"tests", len(oldItem.Tests) == len(newItem.Tests) && testsEqual(oldItem.Tests, newItem.Tests)},
+		{"threshold", oldItem.Threshold == newItem.Threshold},
+	}
+	var fields []string
+	for _, check := range checks {
+		if !check.equal {
+			fields = append(fields, check.field)
+		}
+	}
+	return fields
+}
+
+// Compare two maps, treating nil and empty as equal
+func mapsEqual(first, second interface{}) bool {
+	firstValue, secondValue := reflect.ValueOf(first), reflect.ValueOf(second)
+	if firstValue.Len() == 0 && secondValue.Len() == 0 {
+		return true
+	}
+	return reflect.DeepEqual(first, second)
+}
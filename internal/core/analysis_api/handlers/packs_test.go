@@ -0,0 +1,183 @@
+package handlers
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/analysis/models"
+	compliancemodels "github.com/panther-labs/panther/api/lambda/compliance/models"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+func packRule(id string, severity compliancemodels.Severity) *tableItem {
+	return &tableItem{
+		ID:            id,
+		Body:          "def rule(event): return True",
+		Enabled:       true,
+		ResourceTypes: []string{"AWS.CloudTrail"},
+		Severity:      severity,
+		Type:          models.TypeRule,
+	}
+}
+
+func TestPlanPack(t *testing.T) {
+	// Deployed from v1 of the pack, the severity was raised locally
+	overridden := packRule("Overridden", compliancemodels.SeverityHigh)
+	overridden.PackSource, overridden.PackVersion = "community", "v1.0.0"
+	overridden.PackDefaults = &packDefaults{Enabled: true, Severity: compliancemodels.SeverityLow}
+
+	// Deployed from v1 of the pack and not modified
+	updated := packRule("Updated", compliancemodels.SeverityLow)
+	updated.PackSource, updated.PackVersion = "community", "v1.0.0"
+	updated.PackDefaults = &packDefaults{Enabled: true, Severity: compliancemodels.SeverityLow}
+
+	// Deployed from v1 of the pack and no longer in v2
+	removed := packRule("Removed", compliancemodels.SeverityLow)
+	removed.PackSource, removed.PackVersion = "community", "v1.0.0"
+
+	unchanged := packRule("Unchanged", compliancemodels.SeverityLow)
+	unchanged.PackSource, unchanged.PackVersion = "community", "v1.0.0"
+	unchanged.PackDefaults = &packDefaults{Enabled: true, Severity: compliancemodels.SeverityLow}
+
+	// Created before the pack source was registered, disabled locally
+	adopted := packRule("Adopted", compliancemodels.SeverityLow)
+	adopted.Enabled = false
+
+	local := packRule("Local", compliancemodels.SeverityLow)
+
+	deployed := map[string]*tableItem{}
+	for _, item := range []*tableItem{overridden, updated, removed, unchanged, adopted, local} {
+		deployed[item.ID] = item
+	}
+
+	packedOverridden := packRule("Overridden", compliancemodels.SeverityMedium)
+	packedOverridden.Body = "def rule(event): return False"
+	packedUpdated := packRule("Updated", compliancemodels.SeverityMedium)
+	packed := map[string]*tableItem{
+		"Added":      packRule("Added", compliancemodels.SeverityInfo),
+		"Adopted":    packRule("Adopted", compliancemodels.SeverityLow),
+		"Overridden": packedOverridden,
+		"Unchanged":  packRule("Unchanged", compliancemodels.SeverityLow),
+		"Updated":    packedUpdated,
+	}
+
+	plan, err := planPack("community", "v2.0.0", packed, deployed)
+	require.NoError(t, err)
+
+	assert.Equal(t, []models.PackChange{
+		{ID: "Added", AnalysisType: models.TypeRule, Change: models.PackChangeAdded, Fields: []string{}, Overridden: []string{}},
+		{ID: "Overridden", AnalysisType: models.TypeRule, Change: models.PackChangeModified,
+			Fields: []string{"body"}, Overridden: []string{"severity"}},
+		{ID: "Updated", AnalysisType: models.TypeRule, Change: models.PackChangeModified,
+			Fields: []string{"severity"}, Overridden: []string{}},
+		{ID: "Removed", AnalysisType: models.TypeRule, Change: models.PackChangeRemoved, Fields: []string{}, Overridden: []string{}},
+	}, plan.changes)
+
+	var written []string
+	for _, item := range plan.writes {
+		written = append(written, item.ID)
+		assert.Equal(t, "community", item.PackSource)
+		assert.Equal(t, "v2.0.0", item.PackVersion)
+	}
+	// Adopted is written to record the pack, even though nothing changed
+	assert.Equal(t, []string{"Added", "Adopted", "Overridden", "Updated"}, written)
+	assert.Equal(t, []*tableItem{removed}, plan.deletes)
+
+	assert.Equal(t, compliancemodels.SeverityHigh, packed["Overridden"].Severity)
+	assert.Equal(t, compliancemodels.SeverityMedium, packed["Overridden"].PackDefaults.Severity)
+	assert.Equal(t, compliancemodels.SeverityMedium, packed["Updated"].Severity)
+	assert.False(t, packed["Adopted"].Enabled)
+	assert.True(t, packed["Adopted"].PackDefaults.Enabled)
+}
+
+func TestPlanPackOtherSource(t *testing.T) {
+	deployed := packRule("Rule", compliancemodels.SeverityLow)
+	deployed.PackSource = "other"
+	_, err := planPack("community", "v1.0.0",
+		map[string]*tableItem{"Rule": packRule("Rule", compliancemodels.SeverityLow)},
+		map[string]*tableItem{"Rule": deployed})
+	assert.EqualError(t, err, "RULE Rule is managed by pack other")
+}
+
+func TestValidatePackSource(t *testing.T) {
+	env.DetectionPackBuckets = []string{"bucket"}
+	source := &models.PackSource{SourceType: models.PackSourceGitHub, Owner: "panther-labs", Repository: "panther-analysis"}
+	require.NoError(t, validatePackSource(source))
+	assert.Equal(t, defaultPackAssetName, source.AssetName)
+
+	assert.Error(t, validatePackSource(&models.PackSource{SourceType: models.PackSourceGitHub, Owner: "panther-labs"}))
+	assert.Error(t, validatePackSource(&models.PackSource{SourceType: models.PackSourceS3, S3URL: "https://bucket/packs"}))
+	assert.Error(t, validatePackSource(&models.PackSource{SourceType: models.PackSourceS3, S3URL: "s3://bucket", Owner: "me"}))
+	assert.NoError(t, validatePackSource(&models.PackSource{SourceType: models.PackSourceS3, S3URL: "s3://bucket"}))
+	// Only the configured buckets can host packs
+	assert.EqualError(t, validatePackSource(&models.PackSource{SourceType: models.PackSourceS3, S3URL: "s3://other/packs"}),
+		`S3 bucket "other" is not configured to host detection packs`)
+
+	bucket, prefix, err := parseS3URL("s3://bucket/packs/community")
+	require.NoError(t, err)
+	assert.Equal(t, "bucket", bucket)
+	assert.Equal(t, "packs/community/", prefix)
+}
+
+func TestListS3PackVersions(t *testing.T) {
+	mockS3 := &testutils.S3Mock{}
+	s3Client = mockS3
+	mockS3.On("ListObjectsV2Pages", mock.MatchedBy(func(input *s3.ListObjectsV2Input) bool {
+		return *input.Bucket == "bucket" && *input.Prefix == "packs/"
+	}), mock.Anything).Return(&s3.ListObjectsV2Output{
+		Contents: []*s3.Object{
+			{Key: aws.String("packs/v1.10.0.zip")},
+			{Key: aws.String("packs/README.md")},
+			{Key: aws.String("packs/v1.9.0.zip")},
+		},
+	}, nil)
+
+	versions, err := listPackVersions(context.Background(), &models.PackSource{SourceType: models.PackSourceS3, S3URL: "s3://bucket/packs"})
+	require.NoError(t, err)
+	assert.Equal(t, []models.PackVersion{
+		{Version: "v1.9.0", URL: "s3://bucket/packs/v1.9.0.zip"},
+		{Version: "v1.10.0", URL: "s3://bucket/packs/v1.10.0.zip"},
+	}, versions)
+	mockS3.AssertExpectations(t)
+}
+
+func TestPackSourceItem(t *testing.T) {
+	item := packSourceItem{
+		PackSource: models.PackSource{ID: "community", SourceType: models.PackSourceS3, S3URL: "s3://bucket"},
+		Type:       models.TypePack,
+	}
+	attributes, err := dynamodbattribute.MarshalMap(&item)
+	require.NoError(t, err)
+	assert.Equal(t, "PACK", *attributes["type"].S)
+	assert.Equal(t, "S3", *attributes["sourceType"].S)
+	assert.Equal(t, "community", *attributes["id"].S)
+
+	var result packSourceItem
+	require.NoError(t, dynamodbattribute.UnmarshalMap(attributes, &result))
+	assert.Equal(t, item, result)
+}
@@ -0,0 +1,325 @@
+package handlers
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/service/s3"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+
+	enginemodels "github.com/panther-labs/panther/api/lambda/analysis"
+	"github.com/panther-labs/panther/api/lambda/analysis/models"
+	"github.com/panther-labs/panther/internal/log_analysis/awsglue"
+	"github.com/panther-labs/panther/internal/log_analysis/awsglue/gluetimestamp"
+	"github.com/panther-labs/panther/internal/log_analysis/pantherdb"
+	"github.com/panther-labs/panther/internal/log_analysis/rulespec"
+	"github.com/panther-labs/panther/pkg/gatewayapi"
+)
+
+const (
+	defaultReplayMaxEvents  = 100000
+	defaultReplayMaxSamples = 10
+	maxReplayRange          = 7 * 24 * time.Hour
+	replayTopDedupGroups    = 10
+	replayRuleID            = "RuleAPIReplayRule"
+
+	// Events are sent to the rule engine in batches, below the 6MB payload limit of Lambda
+	replayBatchSize  = 1000
+	replayBatchBytes = 4 * 1024 * 1024
+
+	// The longest the GraphQL API waits for a response
+	replayMaxDuration = 29 * time.Second
+	// Stop this long before the invocation or the caller times out, to respond with the partial results
+	replayResponseMargin = 5 * time.Second
+)
+
+// ReplayRule evaluates a rule against the historical events of a log type, without creating alerts.
+func (API) ReplayRule(ctx context.Context, input *models.ReplayRuleInput) *events.APIGatewayProxyResponse {
+	if err := validateReplayRule(input); err != nil {
+		return &events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: http.StatusBadRequest}
+	}
+	invocationDeadline, _ := ctx.Deadline()
+	replay := newRuleReplay(input, replayDeadline(time.Now(), invocationDeadline))
+	if err := replay.run(); err != nil {
+		return &events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: http.StatusInternalServerError}
+	}
+	return gatewayapi.MarshalResponse(replay.result(), http.StatusOK)
+}
+
+// replayDeadline is the time to stop replaying, before either the Lambda invocation or the caller times out.
+func replayDeadline(now, invocationDeadline time.Time) time.Time {
+	deadline := now.Add(replayMaxDuration)
+	if !invocationDeadline.IsZero() && invocationDeadline.Before(deadline) {
+		deadline = invocationDeadline
+	}
+	return deadline.Add(-replayResponseMargin)
+}
+
+func validateReplayRule(input *models.ReplayRuleInput) error {
+	if input.EndTime.Sub(input.StartTime) > maxReplayRange {
+		return errors.Errorf("replay time range cannot exceed %s", maxReplayRange)
+	}
+	if err := validateLogtypeSet([]string{input.LogType}); err != nil {
+		return errors.Errorf("invalid log type: %s", err.Error())
+	}
+	if input.Spec != "" {
+		if input.Body != "" {
+			return errors.New("rule cannot have both a body and a spec")
+		}
+		if _, err := rulespec.Parse(replayRuleID, input.Spec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ruleReplay accumulates the results of a replay
+type ruleReplay struct {
+	input    *models.ReplayRuleInput
+	deadline time.Time
+
+	// Events waiting to be sent to the rule engine, with their event times by ID
+	batch      []enginemodels.Event
+	batchBytes int
+	batchTimes map[string]time.Time
+
+	output        models.ReplayRuleOutput
+	matchesByHour map[time.Time]int
+	dedupGroups   map[string]int
+}
+
+func newRuleReplay(input *models.ReplayRuleInput, deadline time.Time) *ruleReplay {
+	if input.MaxEvents == 0 {
+		input.MaxEvents = defaultReplayMaxEvents
+	}
+	if input.MaxSamples == 0 {
+		input.MaxSamples = defaultReplayMaxSamples
+	}
+	if input.ID == "" {
+		input.ID = replayRuleID
+	}
+	return &ruleReplay{
+		input:         input,
+		deadline:      deadline,
+		batchTimes:    make(map[string]time.Time),
+		matchesByHour: make(map[time.Time]int),
+		dedupGroups:   make(map[string]int),
+	}
+}
+
+// run streams the hourly partitions of the log type in order
+func (r *ruleReplay) run() error {
+	database := pantherdb.LogProcessingDatabase
+	if pantherdb.GetDataType(r.input.LogType) == pantherdb.CloudSecurity {
+		database = pantherdb.CloudSecurityDatabase
+	}
+	table := pantherdb.TableName(r.input.LogType)
+	start, end := r.input.StartTime.UTC(), r.input.EndTime.UTC()
+	for hour := awsglue.GlueTableHourly.Truncate(start); hour.Before(end); hour = awsglue.GlueTableHourly.Next(hour) {
+		prefix := awsglue.PartitionPrefix(database, table, awsglue.GlueTableHourly, hour)
+		var keys []string
+		err := s3Client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+			Bucket: &env.ProcessedDataBucket,
+			Prefix: &prefix,
+		}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+			for _, object := range page.Contents {
+				keys = append(keys, *object.Key)
+			}
+			return true
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to list s3://%s/%s", env.ProcessedDataBucket, prefix)
+		}
+		for _, key := range keys {
+			skipLines := 0
+			if cursor := r.input.Cursor; cursor != nil {
+				// Keys are listed in order and hourly partitions sort by time, so earlier keys were already replayed
+				if key < cursor.Key {
+					continue
+				}
+				if key == cursor.Key {
+					skipLines = cursor.Line
+				}
+			}
+			if done, err := r.replayObject(key, skipLines); err != nil || done {
+				if err == nil {
+					err = r.flush()
+				}
+				return err
+			}
+		}
+	}
+	return r.flush()
+}
+
+// replayObject reads the events of a processed data file after skipLines, returning true if the replay must stop
+func (r *ruleReplay) replayObject(key string, skipLines int) (bool, error) {
+	output, err := s3Client.GetObject(&s3.GetObjectInput{
+		Bucket: &env.ProcessedDataBucket,
+		Key:    &key,
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get s3://%s/%s", env.ProcessedDataBucket, key)
+	}
+	defer output.Body.Close()
+	gzipReader, err := gzip.NewReader(output.Body)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to read s3://%s/%s", env.ProcessedDataBucket, key)
+	}
+	reader := bufio.NewReader(gzipReader)
+	for lineNum := 0; ; lineNum++ {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 && lineNum >= skipLines {
+			done, replayErr := r.addEvent(line)
+			if replayErr != nil {
+				return false, replayErr
+			}
+			if done {
+				r.output.Cursor = &models.ReplayCursor{Key: key, Line: lineNum}
+				return true, nil
+			}
+		}
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to read s3://%s/%s", env.ProcessedDataBucket, key)
+		}
+	}
+}
+
+func (r *ruleReplay) addEvent(line []byte) (bool, error) {
+	var event map[string]interface{}
+	if err := jsoniter.Unmarshal(line, &event); err != nil {
+		return false, nil
+	}
+	eventTime, err := time.Parse(gluetimestamp.Layout, stringValue(event["p_event_time"]))
+	if err != nil {
+		return false, nil
+	}
+	if eventTime.Before(r.input.StartTime) || !eventTime.Before(r.input.EndTime) {
+		return false, nil
+	}
+	if r.output.EventCount == r.input.MaxEvents || time.Now().After(r.deadline) {
+		r.output.Truncated = true
+		return true, nil
+	}
+	r.output.EventCount++
+
+	id := strconv.Itoa(r.output.EventCount)
+	r.batch = append(r.batch, enginemodels.Event{Data: event, ID: id})
+	r.batchTimes[id] = eventTime
+	r.batchBytes += len(line)
+	if len(r.batch) == replayBatchSize || r.batchBytes >= replayBatchBytes {
+		return false, r.flush()
+	}
+	return false, nil
+}
+
+// flush evaluates the batched events
+func (r *ruleReplay) flush() error {
+	if len(r.batch) == 0 {
+		return nil
+	}
+	results, err := ruleEngine.RunRule(r.input.ID, r.input.Body, r.input.Spec, r.input.LogTypes, r.batch)
+	if err != nil {
+		return err
+	}
+	byID := make(map[string]enginemodels.Event, len(r.batch))
+	for _, event := range r.batch {
+		byID[event.ID] = event
+	}
+	for i := range results {
+		result := &results[i]
+		if result.GenericError != "" || result.RuleError != "" {
+			r.output.ErrorCount++
+			continue
+		}
+		if !result.RuleOutput {
+			continue
+		}
+		r.output.MatchCount++
+		r.matchesByHour[r.batchTimes[result.ID].Truncate(time.Hour)]++
+		dedup := result.DedupOutput
+		if dedup == "" {
+			dedup = result.TitleOutput
+		}
+		if dedup == "" {
+			dedup = "defaultDedupString:" + r.input.ID
+		}
+		r.dedupGroups[dedup]++
+		if len(r.output.SampleMatches) < r.input.MaxSamples {
+			data, _ := jsoniter.MarshalToString(byID[result.ID].Data)
+			r.output.SampleMatches = append(r.output.SampleMatches, models.ReplaySample{
+				Event: data,
+				Title: result.TitleOutput,
+				Dedup: dedup,
+			})
+		}
+	}
+	r.batch, r.batchBytes = nil, 0
+	r.batchTimes = make(map[string]time.Time)
+	return nil
+}
+
+func (r *ruleReplay) result() *models.ReplayRuleOutput {
+	output := r.output
+	output.MatchesByHour = make([]models.ReplayHourCount, 0, len(r.matchesByHour))
+	for hour, count := range r.matchesByHour {
+		output.MatchesByHour = append(output.MatchesByHour, models.ReplayHourCount{Hour: hour, MatchCount: count})
+	}
+	sort.Slice(output.MatchesByHour, func(i, j int) bool {
+		return output.MatchesByHour[i].Hour.Before(output.MatchesByHour[j].Hour)
+	})
+
+	output.DedupGroupCount = len(r.dedupGroups)
+	output.TopDedupGroups = make([]models.ReplayDedupGroup, 0, len(r.dedupGroups))
+	for dedup, count := range r.dedupGroups {
+		output.TopDedupGroups = append(output.TopDedupGroups, models.ReplayDedupGroup{Dedup: dedup, MatchCount: count})
+	}
+	sort.Slice(output.TopDedupGroups, func(i, j int) bool {
+		left, right := output.TopDedupGroups[i], output.TopDedupGroups[j]
+		if left.MatchCount != right.MatchCount {
+			return left.MatchCount > right.MatchCount
+		}
+		return left.Dedup < right.Dedup
+	})
+	if len(output.TopDedupGroups) > replayTopDedupGroups {
+		output.TopDedupGroups = output.TopDedupGroups[:replayTopDedupGroups]
+	}
+	if output.SampleMatches == nil {
+		output.SampleMatches = []models.ReplaySample{}
+	}
+	return &output
+}
+
+func stringValue(value interface{}) string {
+	s, _ := value.(string)
+	return s
+}
@@ -0,0 +1,192 @@
+package handlers
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/analysis/models"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+const replaySpec = `
+match:
+  field: eventName
+  equals: ConsoleLogin
+title: "Login by {user}"
+dedup: "{user}"
+`
+
+func gzipLines(t *testing.T, lines ...string) []byte {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	_, err := writer.Write([]byte(strings.Join(lines, "\n") + "\n"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return buffer.Bytes()
+}
+
+func mockReplayObjects(t *testing.T, mockS3 *testutils.S3Mock, objects map[string][]string) {
+	for key, lines := range objects {
+		key, data := key, gzipLines(t, lines...)
+		prefix := key[:strings.LastIndex(key, "/")+1]
+		mockS3.On("ListObjectsV2Pages", mock.MatchedBy(func(input *s3.ListObjectsV2Input) bool {
+			return *input.Prefix == prefix
+		}), mock.Anything).Return(&s3.ListObjectsV2Output{
+			Contents: []*s3.Object{{Key: aws.String(key)}},
+		}, nil)
+		mockS3.On("GetObject", &s3.GetObjectInput{Bucket: aws.String("processed"), Key: aws.String(key)}).Return(
+			&s3.GetObjectOutput{Body: ioutil.NopCloser(bytes.NewReader(data))}, nil)
+	}
+	// Partitions without data
+	mockS3.On("ListObjectsV2Pages", mock.Anything, mock.Anything).Return(&s3.ListObjectsV2Output{}, nil)
+}
+
+func TestReplayRule(t *testing.T) {
+	mockS3 := &testutils.S3Mock{}
+	s3Client = mockS3
+	env.ProcessedDataBucket = "processed"
+
+	mockReplayObjects(t, mockS3, map[string][]string{
+		"logs/aws_cloudtrail/year=2020/month=11/day=02/hour=10/data.json.gz": {
+			`{"eventName": "ConsoleLogin", "user": "alice", "p_event_time": "2020-11-02 10:05:00.000000000"}`,
+			`{"eventName": "ConsoleLogin", "user": "bob", "p_event_time": "2020-11-02 10:06:00.000000000"}`,
+			`{"eventName": "GetObject", "user": "alice", "p_event_time": "2020-11-02 10:07:00.000000000"}`,
+			// Before the start of the replay
+			`{"eventName": "ConsoleLogin", "user": "carol", "p_event_time": "2020-11-02 09:59:00.000000000"}`,
+		},
+		"logs/aws_cloudtrail/year=2020/month=11/day=02/hour=11/data.json.gz": {
+			`{"eventName": "ConsoleLogin", "user": "alice", "p_event_time": "2020-11-02 11:30:00.000000000"}`,
+		},
+	})
+
+	start := time.Date(2020, 11, 2, 10, 0, 0, 0, time.UTC)
+	replay := newRuleReplay(&models.ReplayRuleInput{
+		Spec:      replaySpec,
+		LogType:   "AWS.CloudTrail",
+		StartTime: start,
+		EndTime:   start.Add(3 * time.Hour),
+	}, time.Now().Add(time.Minute))
+	require.NoError(t, replay.run())
+	result := replay.result()
+
+	assert.Equal(t, 4, result.EventCount)
+	assert.Equal(t, 3, result.MatchCount)
+	assert.Equal(t, 0, result.ErrorCount)
+	assert.False(t, result.Truncated)
+	assert.Equal(t, []models.ReplayHourCount{
+		{Hour: start, MatchCount: 2},
+		{Hour: start.Add(time.Hour), MatchCount: 1},
+	}, result.MatchesByHour)
+	assert.Equal(t, 2, result.DedupGroupCount)
+	assert.Equal(t, []models.ReplayDedupGroup{{Dedup: "alice", MatchCount: 2}, {Dedup: "bob", MatchCount: 1}}, result.TopDedupGroups)
+	require.Len(t, result.SampleMatches, 3)
+	assert.Equal(t, "Login by alice", result.SampleMatches[0].Title)
+	mockS3.AssertExpectations(t)
+}
+
+func TestReplayRuleMaxEvents(t *testing.T) {
+	mockS3 := &testutils.S3Mock{}
+	s3Client = mockS3
+	env.ProcessedDataBucket = "processed"
+
+	mockReplayObjects(t, mockS3, map[string][]string{
+		"logs/aws_cloudtrail/year=2020/month=11/day=02/hour=10/data.json.gz": {
+			`{"eventName": "ConsoleLogin", "user": "alice", "p_event_time": "2020-11-02 10:05:00.000000000"}`,
+			`{"eventName": "ConsoleLogin", "user": "bob", "p_event_time": "2020-11-02 10:06:00.000000000"}`,
+			`{"eventName": "ConsoleLogin", "user": "carol", "p_event_time": "2020-11-02 10:07:00.000000000"}`,
+		},
+	})
+
+	start := time.Date(2020, 11, 2, 10, 0, 0, 0, time.UTC)
+	replay := newRuleReplay(&models.ReplayRuleInput{
+		Spec:       replaySpec,
+		LogType:    "AWS.CloudTrail",
+		StartTime:  start,
+		EndTime:    start.Add(time.Hour),
+		MaxEvents:  2,
+		MaxSamples: 1,
+	}, time.Now().Add(time.Minute))
+	require.NoError(t, replay.run())
+	result := replay.result()
+
+	assert.Equal(t, 2, result.EventCount)
+	assert.Equal(t, 2, result.MatchCount)
+	assert.True(t, result.Truncated)
+	assert.Len(t, result.SampleMatches, 1)
+	assert.Equal(t, &models.ReplayCursor{
+		Key:  "logs/aws_cloudtrail/year=2020/month=11/day=02/hour=10/data.json.gz",
+		Line: 2,
+	}, result.Cursor)
+}
+
+func TestReplayRuleCursor(t *testing.T) {
+	mockS3 := &testutils.S3Mock{}
+	s3Client = mockS3
+	env.ProcessedDataBucket = "processed"
+
+	// Events are not ordered by time within a file
+	mockReplayObjects(t, mockS3, map[string][]string{
+		"logs/aws_cloudtrail/year=2020/month=11/day=02/hour=10/data.json.gz": {
+			`{"eventName": "ConsoleLogin", "user": "alice", "p_event_time": "2020-11-02 10:30:00.000000000"}`,
+			`{"eventName": "ConsoleLogin", "user": "bob", "p_event_time": "2020-11-02 10:06:00.000000000"}`,
+			`{"eventName": "ConsoleLogin", "user": "carol", "p_event_time": "2020-11-02 10:07:00.000000000"}`,
+		},
+	})
+
+	start := time.Date(2020, 11, 2, 10, 0, 0, 0, time.UTC)
+	replay := newRuleReplay(&models.ReplayRuleInput{
+		Spec:      replaySpec,
+		LogType:   "AWS.CloudTrail",
+		StartTime: start,
+		EndTime:   start.Add(time.Hour),
+		Cursor: &models.ReplayCursor{
+			Key:  "logs/aws_cloudtrail/year=2020/month=11/day=02/hour=10/data.json.gz",
+			Line: 1,
+		},
+	}, time.Now().Add(time.Minute))
+	require.NoError(t, replay.run())
+	result := replay.result()
+
+	assert.Equal(t, 2, result.EventCount)
+	assert.False(t, result.Truncated)
+	assert.Nil(t, result.Cursor)
+	assert.Equal(t, []models.ReplayDedupGroup{{Dedup: "bob", MatchCount: 1}, {Dedup: "carol", MatchCount: 1}},
+		result.TopDedupGroups)
+}
+
+func TestReplayDeadline(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	// Bounded by the caller
+	assert.Equal(t, now.Add(24*time.Second), replayDeadline(now, time.Time{}))
+	assert.Equal(t, now.Add(24*time.Second), replayDeadline(now, now.Add(15*time.Minute)))
+	// Bounded by the time left in the invocation
+	assert.Equal(t, now.Add(5*time.Second), replayDeadline(now, now.Add(10*time.Second)))
+}
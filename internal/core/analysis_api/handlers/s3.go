@@ -21,6 +21,8 @@ package handlers
 import (
 	"bytes"
 	"io/ioutil"
+	"sort"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
@@ -106,3 +108,69 @@ func s3Upload(policy *tableItem) error {
 	policy.VersionID = *result.VersionId
 	return nil
 }
+
+// An S3 object version of a policy
+type s3Version struct {
+	versionID    string
+	lastModified time.Time
+	deleted      bool
+	latest       bool
+}
+
+// List up to limit versions of a policy from S3, newest first, starting after the versionIDMarker (if set).
+//
+// Deleted policies are included: their versions are kept behind a delete marker.
+func s3ListVersions(policyID, versionIDMarker string, limit int) ([]s3Version, error) {
+	input := &s3.ListObjectVersionsInput{
+		Bucket: &env.Bucket,
+		Prefix: &policyID,
+	}
+	if versionIDMarker != "" {
+		input.KeyMarker = &policyID
+		input.VersionIdMarker = &versionIDMarker
+	}
+
+	var result []s3Version
+	err := s3Client.ListObjectVersionsPages(input, func(page *s3.ListObjectVersionsOutput, lastPage bool) bool {
+		// Keys are listed in order, other policies with the same prefix come after this one
+		otherKeys := false
+		var versions []s3Version
+		for _, version := range page.Versions {
+			if aws.StringValue(version.Key) != policyID {
+				otherKeys = true
+				continue
+			}
+			versions = append(versions, s3Version{
+				versionID:    aws.StringValue(version.VersionId),
+				lastModified: aws.TimeValue(version.LastModified),
+				latest:       aws.BoolValue(version.IsLatest),
+			})
+		}
+		for _, marker := range page.DeleteMarkers {
+			if aws.StringValue(marker.Key) != policyID {
+				otherKeys = true
+				continue
+			}
+			versions = append(versions, s3Version{
+				versionID:    aws.StringValue(marker.VersionId),
+				lastModified: aws.TimeValue(marker.LastModified),
+				deleted:      true,
+				latest:       aws.BoolValue(marker.IsLatest),
+			})
+		}
+		sort.SliceStable(versions, func(i, j int) bool {
+			return versions[i].lastModified.After(versions[j].lastModified)
+		})
+		result = append(result, versions...)
+		return !otherKeys && len(result) < limit
+	})
+	if err != nil {
+		zap.L().Error("s3Client.ListObjectVersionsPages failed", zap.Error(err))
+		return nil, err
+	}
+
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
@@ -19,6 +19,7 @@ package handlers
  */
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/aws/aws-lambda-go/events"
@@ -28,7 +29,7 @@ import (
 )
 
 // Suppress adds suppressions for one or more policies in the same organization.
-func (API) Suppress(input *models.SuppressInput) *events.APIGatewayProxyResponse {
+func (API) Suppress(_ context.Context, input *models.SuppressInput) *events.APIGatewayProxyResponse {
 	updates, err := addSuppressions(input.PolicyIDs, input.ResourcePatterns)
 	if err != nil {
 		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
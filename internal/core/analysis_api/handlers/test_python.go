@@ -19,6 +19,7 @@ package handlers
  */
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/aws/aws-lambda-go/events"
@@ -28,11 +29,11 @@ import (
 	"github.com/panther-labs/panther/pkg/gatewayapi"
 )
 
-func (API) TestPolicy(input *models.TestPolicyInput) *events.APIGatewayProxyResponse {
+func (API) TestPolicy(_ context.Context, input *models.TestPolicyInput) *events.APIGatewayProxyResponse {
 	return testPython(policyEngine.TestPolicy(input))
 }
 
-func (API) TestRule(input *models.TestRuleInput) *events.APIGatewayProxyResponse {
+func (API) TestRule(_ context.Context, input *models.TestRuleInput) *events.APIGatewayProxyResponse {
 	return testPython(ruleEngine.TestRule(input))
 }
 
@@ -184,9 +184,10 @@ func writeItem(item *tableItem, userID string, mustExist *bool) (int, error) {
 	if oldItem == nil {
 		item.CreatedAt = time.Now()
 		item.CreatedBy = userID
+		setExceptionIDs(item.Exceptions, nil)
 		changeType = newItem
 	} else {
-		if oldItem.Type != item.Type {
+		if oldItem.Type != item.Type && !(isRuleType(oldItem.Type) && isRuleType(item.Type)) {
 			return changeType, errWrongType
 		}
 
@@ -199,6 +200,18 @@ func writeItem(item *tableItem, userID string, mustExist *bool) (int, error) {
 
 		item.CreatedAt = oldItem.CreatedAt
 		item.CreatedBy = oldItem.CreatedBy
+		if item.PackSource == "" {
+			// Local edits do not detach the detection from the pack it was installed from
+			item.PackSource = oldItem.PackSource
+			item.PackVersion = oldItem.PackVersion
+			item.PackDefaults = oldItem.PackDefaults
+		}
+		if item.Exceptions == nil {
+			// Exceptions are managed separately from the rule code, e.g. bulk uploads do not include them
+			item.Exceptions = oldItem.Exceptions
+		} else {
+			setExceptionIDs(item.Exceptions, oldItem.Exceptions)
+		}
 		if itemUpdated(oldItem, item) {
 			changeType = updatedItem
 		}
@@ -217,7 +230,7 @@ func writeItem(item *tableItem, userID string, mustExist *bool) (int, error) {
 		return changeType, err
 	}
 
-	if item.Type == models.TypeRule || item.Type == models.TypeDataModel {
+	if isRuleType(item.Type) || item.Type == models.TypeDataModel {
 		return changeType, nil
 	}
 
@@ -238,6 +251,18 @@ func writeItem(item *tableItem, userID string, mustExist *bool) (int, error) {
 	return changeType, nil
 }
 
+// Scheduled rules are managed through the rule API and share the rule alert pipeline
+func isRuleType(itemType models.DetectionType) bool {
+	return itemType == models.TypeRule || itemType == models.TypeScheduledRule
+}
+
+func scheduledQueriesEqual(oldQuery, newQuery *models.ScheduledQuery) bool {
+	if oldQuery == nil || newQuery == nil {
+		return oldQuery == newQuery
+	}
+	return *oldQuery == *newQuery
+}
+
 // itemUpdated checks if ANY field has been changed between the old and new item. Only used to inform users whether the
 // result of a BulkUpload operation actually changed something or not.
 //
@@ -252,11 +277,14 @@ func itemUpdated(oldItem, newItem *tableItem) bool {
 		oldItem.Runbook == newItem.Runbook && oldItem.Severity == newItem.Severity &&
 		oldItem.DedupPeriodMinutes == newItem.DedupPeriodMinutes &&
 		oldItem.Threshold == newItem.Threshold &&
+		reflect.DeepEqual(oldItem.AlertGrouping, newItem.AlertGrouping) &&
+		oldItem.Spec == newItem.Spec && scheduledQueriesEqual(oldItem.ScheduledQuery, newItem.ScheduledQuery) &&
 		setEquality(oldItem.ResourceTypes, newItem.ResourceTypes) &&
 		setEquality(oldItem.Suppressions, newItem.Suppressions) && setEquality(oldItem.Tags, newItem.Tags) &&
 		len(oldItem.AutoRemediationParameters) == len(newItem.AutoRemediationParameters) &&
 		len(oldItem.Tests) == len(newItem.Tests) &&
-		len(oldItem.Mappings) == len(newItem.Mappings)
+		len(oldItem.Mappings) == len(newItem.Mappings) &&
+		exceptionsEqual(oldItem.Exceptions, newItem.Exceptions)
 
 	if !itemsEqual {
 		return true
@@ -270,16 +298,29 @@ func itemUpdated(oldItem, newItem *tableItem) bool {
 		}
 	}
 	// Check Tests for equality
-	oldTests := make(map[string]models.UnitTest)
-	for _, test := range oldItem.Tests {
-		oldTests[test.Name] = test
+	if !testsEqual(oldItem.Tests, newItem.Tests) {
+		return true
+	}
+
+	// Check mappings for equality
+	itemsEqual = mappingEquality(oldItem, newItem)
+
+	// If they're the same, the item wasn't really updated
+	return !itemsEqual
+}
+
+// testsEqual returns true if every new test has an equivalent old test. Callers compare the number of tests.
+func testsEqual(oldTests, newTests []models.UnitTest) bool {
+	oldByName := make(map[string]models.UnitTest)
+	for _, test := range oldTests {
+		oldByName[test.Name] = test
 	}
-	for _, newTest := range newItem.Tests {
-		oldTest, ok := oldTests[newTest.Name]
+	for _, newTest := range newTests {
+		oldTest, ok := oldByName[newTest.Name]
 		// First check if the meta data of the test is equal
 		if !ok || oldTest.ExpectedResult != newTest.ExpectedResult {
 			// Something changed, so this item has been updated
-			return true
+			return false
 		}
 
 		// The resource is a string that consists of valid JSON, and represents a test case. At some point in the
@@ -292,27 +333,22 @@ func itemUpdated(oldItem, newItem *tableItem) bool {
 			// It is possible someone uploaded bad JSON in this test, it is not the responsibility of this test to
 			// report that. Just do a raw string comparison.
 			if oldTest.Resource != newTest.Resource {
-				return true
+				return false
 			}
 			continue
 		}
 		if err := jsoniter.UnmarshalFromString(newTest.Resource, &newResource); err != nil {
 			if oldTest.Resource != newTest.Resource {
-				return true
+				return false
 			}
 			continue
 		}
 
 		if !reflect.DeepEqual(oldResource, newResource) {
-			return true
+			return false
 		}
 	}
-
-	// Check mappings for equality
-	itemsEqual = mappingEquality(oldItem, newItem)
-
-	// If they're the same, the item wasn't really updated
-	return !itemsEqual
+	return true
 }
 
 func mappingEquality(oldItem, newItem *tableItem) bool {
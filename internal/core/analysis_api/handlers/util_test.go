@@ -100,6 +100,20 @@ func TestPoliciesEqual(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestItemUpdatedScheduledQuery(t *testing.T) {
+	query := models.ScheduledQuery{SQL: "SELECT 1", Schedule: "0 * * * *"}
+	first := &tableItem{ID: "Scheduled.Rule", ScheduledQuery: &query, Type: models.TypeScheduledRule}
+	changed := query
+	second := &tableItem{ID: "Scheduled.Rule", ScheduledQuery: &changed, Type: models.TypeScheduledRule}
+	assert.False(t, itemUpdated(first, second))
+
+	changed.LookbackMinutes = 120
+	assert.True(t, itemUpdated(first, second))
+
+	second.ScheduledQuery = nil
+	assert.True(t, itemUpdated(first, second))
+}
+
 func TestSortCaseInsensitive(t *testing.T) {
 	input := []string{"AWS.EC2.VPC", "AWS.EC2.Volume"}
 	sortCaseInsensitive(input)
@@ -0,0 +1,280 @@
+package handlers
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/analysis/models"
+	"github.com/panther-labs/panther/internal/core/analysis_api/analysis"
+	"github.com/panther-labs/panther/pkg/gatewayapi"
+)
+
+const defaultVersionsPageSize = 25
+
+// ListDetectionVersions lists the saved versions of a detection and the fields changed by each version
+func (API) ListDetectionVersions(_ context.Context, input *models.ListDetectionVersionsInput) *events.APIGatewayProxyResponse {
+	pageSize := input.PageSize
+	if pageSize == 0 {
+		pageSize = defaultVersionsPageSize
+	}
+
+	// One more version than the page size is needed to diff the oldest version of the page
+	versions, err := s3ListVersions(input.ID, input.VersionIDMarker, pageSize+1)
+	if err != nil {
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+	if len(versions) == 0 {
+		return &events.APIGatewayProxyResponse{
+			Body:       "Cannot find versions of " + input.ID,
+			StatusCode: http.StatusNotFound,
+		}
+	}
+
+	items := make([]*tableItem, len(versions))
+	for i, version := range versions {
+		if version.deleted {
+			continue
+		}
+		if items[i], err = s3Get(input.ID, version.versionID); err != nil {
+			return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+		}
+	}
+
+	result := models.ListDetectionVersionsOutput{Versions: make([]models.DetectionVersion, 0, pageSize)}
+	for i, version := range versions {
+		if i == pageSize {
+			result.NextVersionIDMarker = versions[i-1].versionID
+			break
+		}
+		output := models.DetectionVersion{
+			VersionID:     version.versionID,
+			LastModified:  version.lastModified,
+			Deleted:       version.deleted,
+			Latest:        version.latest,
+			ChangedFields: []string{},
+		}
+		if item := items[i]; item != nil {
+			output.LastModifiedBy = item.LastModifiedBy
+			// Compare with the previous version which was not a deletion
+			for _, previous := range items[i+1:] {
+				if previous != nil {
+					if fields := changedFields(previous, item); fields != nil {
+						output.ChangedFields = fields
+					}
+					break
+				}
+			}
+		}
+		result.Versions = append(result.Versions, output)
+	}
+	return gatewayapi.MarshalResponse(&result, http.StatusOK)
+}
+
+// DiffDetectionVersions returns the old and new value of each field changed between two versions
+func (API) DiffDetectionVersions(_ context.Context, input *models.DiffDetectionVersionsInput) *events.APIGatewayProxyResponse {
+	from, response := getVersion(input.ID, input.FromVersionID)
+	if response != nil {
+		return response
+	}
+	to, response := getVersion(input.ID, input.ToVersionID)
+	if response != nil {
+		return response
+	}
+	return gatewayapi.MarshalResponse(&models.DiffDetectionVersionsOutput{Fields: diffItems(from, to)}, http.StatusOK)
+}
+
+// RevertDetection saves a prior version of a detection as its latest version
+func (API) RevertDetection(ctx context.Context, input *models.RevertDetectionInput) *events.APIGatewayProxyResponse {
+	item, response := getVersion(input.ID, input.VersionID)
+	if response != nil {
+		return response
+	}
+	// Log types, schemas and globals may have changed since the version was saved
+	if response := validateRevert(ctx, item, input.UserID); response != nil {
+		return response
+	}
+
+	// Deleted detections are restored
+	if _, err := writeItem(item, input.UserID, nil); err != nil {
+		if err == errWrongType {
+			return &events.APIGatewayProxyResponse{
+				Body:       "ID " + item.ID + " is in use by a different type of detection",
+				StatusCode: http.StatusConflict,
+			}
+		}
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+	if item.Type == models.TypeGlobal {
+		if err := updateLayer(); err != nil {
+			return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+		}
+	}
+	return gatewayapi.MarshalResponse(item.Detection(""), http.StatusOK)
+}
+
+// Run the validation of the create and update handlers on a detection version, returning an error response if it fails
+func validateRevert(ctx context.Context, item *tableItem, userID string) *events.APIGatewayProxyResponse {
+	var testsPass bool
+	var err error
+	switch item.Type {
+	case models.TypeRule, models.TypeScheduledRule:
+		input := item.Rule().UpdateInput(userID)
+		if err := validateUpdateRule(input); err != nil {
+			return &events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: http.StatusBadRequest}
+		}
+		testsPass, err = enabledRuleTestsPass(input)
+	case models.TypePolicy:
+		input := &models.CreatePolicyInput{
+			Body:          item.Body,
+			Enabled:       item.Enabled,
+			ID:            item.ID,
+			ResourceTypes: item.ResourceTypes,
+			Tests:         item.Tests,
+			UserID:        userID,
+		}
+		if err := validateUpdatePolicy(input); err != nil {
+			return &events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: http.StatusBadRequest}
+		}
+		testsPass, err = enabledPolicyTestsPass(input)
+	case models.TypeDataModel:
+		return validateRevertDataModel(ctx, item, userID)
+	default:
+		return nil
+	}
+
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if _, ok := err.(*analysis.TestInputError); ok {
+			statusCode = http.StatusBadRequest
+		}
+		return &events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: statusCode}
+	}
+	if !testsPass {
+		return &events.APIGatewayProxyResponse{
+			Body:       "cannot revert to an enabled detection with failing unit tests",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+	return nil
+}
+
+// Validate a data model version like writeDataModel, refreshing the fields it supports
+func validateRevertDataModel(ctx context.Context, item *tableItem, userID string) *events.APIGatewayProxyResponse {
+	input := &models.UpdateDataModelInput{
+		Body:     item.Body,
+		Enabled:  item.Enabled,
+		ID:       item.ID,
+		LogTypes: item.ResourceTypes,
+		Mappings: item.Mappings,
+		UserID:   userID,
+	}
+	if err := validateUpdateDataModel(input); err != nil {
+		return &events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: http.StatusBadRequest}
+	}
+
+	supportedFields, err := dataModelSupportedFields(ctx, input.LogTypes, input.Mappings, input.Body)
+	if err != nil {
+		if _, ok := err.(*dataModelMappingError); ok {
+			return &events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: http.StatusBadRequest}
+		}
+		zap.L().Error("failed to validate data model mappings", zap.Error(err))
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+	item.SupportedFields = supportedFields
+
+	isEnabled, err := isSingleDataModelEnabled(input.ID, input.Enabled, input.LogTypes)
+	if err != nil {
+		return &events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: http.StatusInternalServerError}
+	}
+	if !isEnabled {
+		return &events.APIGatewayProxyResponse{Body: errMultipleDataModelsEnabled.Error(), StatusCode: http.StatusBadRequest}
+	}
+	return nil
+}
+
+// Load a version of a detection, or the current version if no version ID is given
+func getVersion(id, versionID string) (*tableItem, *events.APIGatewayProxyResponse) {
+	var item *tableItem
+	var err error
+	if versionID == "" {
+		item, err = dynamoGet(id, false)
+	} else {
+		item, err = s3Get(id, versionID)
+	}
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); !ok || !isVersionNotFound(awsErr) {
+			return nil, &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+		}
+		item = nil
+	}
+	if item == nil || item.Type == models.TypePack {
+		return nil, &events.APIGatewayProxyResponse{
+			Body:       "Cannot find version " + versionID + " of " + id,
+			StatusCode: http.StatusNotFound,
+		}
+	}
+	return item, nil
+}
+
+func isVersionNotFound(err awserr.Error) bool {
+	switch err.Code() {
+	case s3.ErrCodeNoSuchKey, "NoSuchVersion", "InvalidArgument":
+		return true
+	default:
+		return false
+	}
+}
+
+// diffItems returns the changed fields with their values, in the format of the stored detection
+func diffItems(oldItem, newItem *tableItem) []models.DetectionFieldDiff {
+	oldValues, newValues := itemValues(oldItem), itemValues(newItem)
+	result := []models.DetectionFieldDiff{}
+	for _, field := range changedFields(oldItem, newItem) {
+		column := dynamoColumn(field)
+		if field == "analysisType" {
+			column = "type"
+		}
+		result = append(result, models.DetectionFieldDiff{
+			Field: field,
+			Old:   oldValues[column],
+			New:   newValues[column],
+		})
+	}
+	return result
+}
+
+func itemValues(item *tableItem) map[string]interface{} {
+	var values map[string]interface{}
+	body, err := jsoniter.Marshal(item)
+	if err == nil {
+		err = jsoniter.Unmarshal(body, &values)
+	}
+	if err != nil {
+		zap.L().Warn("failed to convert item to map", zap.String("id", item.ID), zap.Error(err))
+	}
+	return values
+}
@@ -0,0 +1,128 @@
+package handlers
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/analysis/models"
+	compliancemodels "github.com/panther-labs/panther/api/lambda/compliance/models"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+func versionID(c string) string {
+	return strings.Repeat(c, 32)
+}
+
+func mockVersion(t *testing.T, mockS3 *testutils.S3Mock, version string, item *tableItem) {
+	body, err := jsoniter.Marshal(item)
+	require.NoError(t, err)
+	mockS3.On("GetObject", &s3.GetObjectInput{
+		Bucket: aws.String("versions"), Key: aws.String(item.ID), VersionId: aws.String(version),
+	}).Return(&s3.GetObjectOutput{Body: ioutil.NopCloser(bytes.NewReader(body))}, nil)
+}
+
+func TestListDetectionVersions(t *testing.T) {
+	mockS3 := &testutils.S3Mock{}
+	s3Client = mockS3
+	env.Bucket = "versions"
+
+	now := time.Date(2020, 11, 2, 10, 0, 0, 0, time.UTC)
+	mockS3.On("ListObjectVersionsPages", mock.MatchedBy(func(input *s3.ListObjectVersionsInput) bool {
+		return *input.Prefix == "Rule" && input.VersionIdMarker == nil
+	}), mock.Anything).Return(&s3.ListObjectVersionsOutput{
+		Versions: []*s3.ObjectVersion{
+			{Key: aws.String("Rule"), VersionId: aws.String(versionID("c")), LastModified: aws.Time(now.Add(-time.Hour))},
+			{Key: aws.String("Rule"), VersionId: aws.String(versionID("b")), LastModified: aws.Time(now.Add(-2 * time.Hour))},
+			{Key: aws.String("Rule"), VersionId: aws.String(versionID("a")), LastModified: aws.Time(now.Add(-3 * time.Hour))},
+			// Another rule with the same prefix
+			{Key: aws.String("Rule.Two"), VersionId: aws.String(versionID("e")), LastModified: aws.Time(now)},
+		},
+		DeleteMarkers: []*s3.DeleteMarkerEntry{
+			{Key: aws.String("Rule"), VersionId: aws.String(versionID("d")), LastModified: aws.Time(now), IsLatest: aws.Bool(true)},
+		},
+	}, nil)
+
+	first := &tableItem{ID: "Rule", Body: "one", Severity: compliancemodels.SeverityLow, LastModifiedBy: "alice"}
+	second := &tableItem{ID: "Rule", Body: "two", Severity: compliancemodels.SeverityLow, LastModifiedBy: "bob"}
+	third := &tableItem{ID: "Rule", Body: "two", Severity: compliancemodels.SeverityHigh, Enabled: true, LastModifiedBy: "alice"}
+	mockVersion(t, mockS3, versionID("a"), first)
+	mockVersion(t, mockS3, versionID("b"), second)
+	mockVersion(t, mockS3, versionID("c"), third)
+
+	response := API{}.ListDetectionVersions(context.Background(), &models.ListDetectionVersionsInput{ID: "Rule", PageSize: 3})
+	require.Equal(t, http.StatusOK, response.StatusCode, response.Body)
+	var result models.ListDetectionVersionsOutput
+	require.NoError(t, jsoniter.UnmarshalFromString(response.Body, &result))
+
+	assert.Equal(t, models.ListDetectionVersionsOutput{
+		Versions: []models.DetectionVersion{
+			{VersionID: versionID("d"), LastModified: now, Deleted: true, Latest: true, ChangedFields: []string{}},
+			{VersionID: versionID("c"), LastModified: now.Add(-time.Hour), LastModifiedBy: "alice",
+				ChangedFields: []string{"enabled", "severity"}},
+			{VersionID: versionID("b"), LastModified: now.Add(-2 * time.Hour), LastModifiedBy: "bob",
+				ChangedFields: []string{"body"}},
+		},
+		NextVersionIDMarker: versionID("b"),
+	}, result)
+	mockS3.AssertExpectations(t)
+}
+
+func TestDiffItems(t *testing.T) {
+	oldItem := &tableItem{
+		ID: "Rule", Type: models.TypeRule, ResourceTypes: []string{"AWS.CloudTrail"}, Severity: compliancemodels.SeverityLow}
+	newItem := &tableItem{
+		ID: "Rule", Type: models.TypeRule, ResourceTypes: []string{"AWS.CloudTrail", "AWS.S3ServerAccess"},
+		Severity: compliancemodels.SeverityLow, Runbook: "Call the owner"}
+
+	assert.Equal(t, []models.DetectionFieldDiff{
+		{Field: "logTypes", Old: []interface{}{"AWS.CloudTrail"}, New: []interface{}{"AWS.CloudTrail", "AWS.S3ServerAccess"}},
+		{Field: "runbook", Old: nil, New: "Call the owner"},
+	}, diffItems(oldItem, newItem))
+}
+
+func TestRevertDetectionInvalid(t *testing.T) {
+	mockS3 := &testutils.S3Mock{}
+	s3Client = mockS3
+	env.Bucket = "versions"
+
+	// The resource type is no longer supported, so the old version cannot be saved
+	item := &tableItem{ID: "Policy", Type: models.TypePolicy, Body: "def policy(resource): return True",
+		ResourceTypes: []string{"AWS.Removed.Type"}, Severity: compliancemodels.SeverityLow}
+	mockVersion(t, mockS3, versionID("a"), item)
+
+	input := &models.RevertDetectionInput{ID: "Policy", VersionID: versionID("a"), UserID: "alice"}
+	response := API{}.RevertDetection(context.Background(), input)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, "policy contains invalid resource type: AWS.Removed.Type", response.Body)
+	mockS3.AssertExpectations(t)
+}
@@ -33,7 +33,7 @@ var router = genericapi.NewRouter("api", "analysis", nil, handlers.API{})
 
 func lambdaHandler(ctx context.Context, input *models.LambdaInput) (interface{}, error) {
 	lambdalogger.ConfigureGlobal(ctx, nil)
-	return router.Handle(input)
+	return router.HandleWithContext(ctx, input)
 }
 
 func main() {
@@ -28,5 +28,5 @@ import (
 
 // The handler signatures must match those in the LambdaInput struct.
 func TestRouter(t *testing.T) {
-	assert.NoError(t, router.VerifyHandlers(&models.LambdaInput{}))
+	assert.NoError(t, router.VerifyHandlersWithContext(&models.LambdaInput{}))
 }
@@ -32,6 +32,7 @@ import (
 
 	cloudsecglue "github.com/panther-labs/panther/internal/compliance/awsglue"
 	"github.com/panther-labs/panther/internal/core/source_api/apifunctions"
+	alertsglue "github.com/panther-labs/panther/internal/log_analysis/alerts_api/awsglue"
 	"github.com/panther-labs/panther/internal/log_analysis/awsglue"
 	"github.com/panther-labs/panther/internal/log_analysis/datacatalog_updater/datacatalog"
 	"github.com/panther-labs/panther/internal/log_analysis/pantherdb"
@@ -72,6 +73,9 @@ func customUpdateLogTables(ctx context.Context, event cfn.Event) (string, map[st
 		if err := createCloudSecurityDDBTables(ctx); err != nil {
 			return physicalResourceID, nil, err
 		}
+		if err := createAlertDeliveryDDBTable(ctx); err != nil {
+			return physicalResourceID, nil, err
+		}
 
 		logTypesInUse, err := apifunctions.ListLogTypes(ctx, lambdaClient)
 		if err != nil {
@@ -135,6 +139,25 @@ func createCloudSecurityDDBTables(_ context.Context) error {
 	return nil
 }
 
+func createAlertDeliveryDDBTable(_ context.Context) error {
+	endpoint, err := endpointResolver.EndpointFor("dynamodb", *awsSession.Config.Region)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get endpoint information")
+	}
+
+	deliveryTableArn := arn.ARN{
+		Partition: endpoint.PartitionID,
+		Region:    aws.StringValue(awsSession.Config.Region),
+		AccountID: env.AccountID,
+		Service:   "dynamodb",
+		Resource:  alertsglue.DeliveryHistoryTableDDB,
+	}
+	if err := alertsglue.CreateOrUpdateDeliveryTable(glueClient, deliveryTableArn.String()); err != nil {
+		return errors.Wrap(err, "failed to create alert delivery table")
+	}
+	return nil
+}
+
 // FIXME: remove when this is supported by CloudFormation and add to Panther WorkGroup CF
 func useAthena2() {
 	version := "Athena engine version 2"
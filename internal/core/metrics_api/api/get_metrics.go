@@ -44,6 +44,10 @@ var (
 		"alertsBySeverity": getAlertsBySeverity,
 		"eventsLatency":    getEventsLatency,
 		"eventsProcessed":  getEventsProcessed,
+		"ruleErrors":       getRuleErrors,
+		"ruleEvaluations":  getRuleEvaluations,
+		"ruleLatency":      getRuleLatency,
+		"ruleMatches":      getRuleMatches,
 		"totalAlertsDelta": getTotalAlertsDelta,
 	}
 )
@@ -0,0 +1,142 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/metrics/models"
+)
+
+// Written by the rules engines for each rule they evaluate
+const (
+	ruleEvaluationsMetric = "RuleEvaluations"
+	ruleErrorsMetric      = "RuleErrors"
+	ruleMatchesMetric     = "RuleMatches"
+	ruleLatencyMetric     = "RuleLatency"
+	ruleIDDimension       = "AnalysisID"
+)
+
+// getRuleEvaluations returns the number of events each rule was evaluated against
+//
+// This is a time series metric.
+func getRuleEvaluations(input *models.GetMetricsInput, output *models.GetMetricsOutput) (err error) {
+	output.RuleEvaluations, err = getRuleMetric(input, ruleEvaluationsMetric, []string{"Sum"})
+	return err
+}
+
+// getRuleErrors returns the number of events each rule raised an error for
+//
+// This is a time series metric.
+func getRuleErrors(input *models.GetMetricsInput, output *models.GetMetricsOutput) (err error) {
+	output.RuleErrors, err = getRuleMetric(input, ruleErrorsMetric, []string{"Sum"})
+	return err
+}
+
+// getRuleMatches returns the number of events each rule matched
+//
+// This is a time series metric.
+func getRuleMatches(input *models.GetMetricsInput, output *models.GetMetricsOutput) (err error) {
+	output.RuleMatches, err = getRuleMetric(input, ruleMatchesMetric, []string{"Sum"})
+	return err
+}
+
+// getRuleLatency returns the median and 99th percentile of the time it took each rule to evaluate an event,
+// in milliseconds. The series are labeled with the rule ID and the statistic, e.g. "AWS.Root.Login p99".
+//
+// This is a time series metric.
+func getRuleLatency(input *models.GetMetricsInput, output *models.GetMetricsOutput) (err error) {
+	output.RuleLatency, err = getRuleMetric(input, ruleLatencyMetric, []string{"p50", "p99"})
+	return err
+}
+
+// getRuleMetric queries a per-rule metric, either for the requested rules or for all rules that reported it
+func getRuleMetric(input *models.GetMetricsInput, metricName string, stats []string) (*models.MetricResult, error) {
+	var ruleMetrics []*cloudwatch.Metric
+	if len(input.RuleIDs) > 0 {
+		for _, ruleID := range input.RuleIDs {
+			ruleMetrics = append(ruleMetrics, &cloudwatch.Metric{
+				Dimensions: []*cloudwatch.Dimension{{Name: aws.String(ruleIDDimension), Value: aws.String(ruleID)}},
+				MetricName: aws.String(metricName),
+				Namespace:  aws.String(input.Namespace),
+			})
+		}
+	} else {
+		err := cloudwatchClient.ListMetricsPages(&cloudwatch.ListMetricsInput{
+			MetricName: aws.String(metricName),
+			Namespace:  aws.String(input.Namespace),
+			Dimensions: []*cloudwatch.DimensionFilter{{Name: aws.String(ruleIDDimension)}},
+		}, func(page *cloudwatch.ListMetricsOutput, _ bool) bool {
+			ruleMetrics = append(ruleMetrics, page.Metrics...)
+			return true
+		})
+		if err != nil {
+			zap.L().Error("unable to list metrics", zap.String("metric", metricName), zap.Error(err))
+			return nil, metricsInternalError
+		}
+	}
+
+	queries := ruleMetricQueries(input, ruleMetrics, stats)
+	zap.L().Debug("prepared metric queries", zap.Any("queries", queries), zap.Any("toDate", input.ToDate), zap.Any("fromDate", input.FromDate))
+
+	metricData, err := getMetricData(input, queries)
+	if err != nil {
+		return nil, err
+	}
+	values, timestamps := normalizeTimeStamps(input, metricData)
+	return &models.MetricResult{
+		SeriesData: models.TimeSeriesMetric{
+			Timestamps: timestamps,
+			Series:     values,
+		},
+	}, nil
+}
+
+// ruleMetricQueries builds a query for each statistic of each rule metric, labeled with the rule ID
+func ruleMetricQueries(input *models.GetMetricsInput, ruleMetrics []*cloudwatch.Metric, stats []string) []*cloudwatch.MetricDataQuery {
+	queries := make([]*cloudwatch.MetricDataQuery, 0, len(ruleMetrics)*len(stats))
+	for _, metric := range ruleMetrics {
+		var ruleID string
+		for _, dimension := range metric.Dimensions {
+			if aws.StringValue(dimension.Name) == ruleIDDimension {
+				ruleID = aws.StringValue(dimension.Value)
+			}
+		}
+		for _, stat := range stats {
+			label := ruleID
+			if len(stats) > 1 {
+				label += " " + stat
+			}
+			queries = append(queries, &cloudwatch.MetricDataQuery{
+				Id:    aws.String("query" + strconv.Itoa(len(queries))),
+				Label: aws.String(label),
+				MetricStat: &cloudwatch.MetricStat{
+					Metric: metric,
+					Period: aws.Int64(input.IntervalMinutes * 60), // number of seconds, must be multiple of 60
+					Stat:   aws.String(stat),
+				},
+			})
+		}
+	}
+	return queries
+}
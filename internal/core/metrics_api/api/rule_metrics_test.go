@@ -0,0 +1,64 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/metrics/models"
+)
+
+func TestRuleMetricQueries(t *testing.T) {
+	input := &models.GetMetricsInput{IntervalMinutes: 5}
+	ruleMetrics := []*cloudwatch.Metric{
+		{
+			Dimensions: []*cloudwatch.Dimension{{Name: aws.String("AnalysisID"), Value: aws.String("Rule.One")}},
+			MetricName: aws.String(ruleLatencyMetric),
+		},
+		{
+			Dimensions: []*cloudwatch.Dimension{{Name: aws.String("AnalysisID"), Value: aws.String("Rule.Two")}},
+			MetricName: aws.String(ruleLatencyMetric),
+		},
+	}
+
+	queries := ruleMetricQueries(input, ruleMetrics, []string{"p50", "p99"})
+	require.Len(t, queries, 4)
+	var ids, labels, stats []string
+	for _, query := range queries {
+		ids = append(ids, *query.Id)
+		labels = append(labels, *query.Label)
+		stats = append(stats, *query.MetricStat.Stat)
+		assert.Equal(t, int64(300), *query.MetricStat.Period)
+	}
+	assert.Equal(t, []string{"query0", "query1", "query2", "query3"}, ids)
+	assert.Equal(t, []string{"Rule.One p50", "Rule.One p99", "Rule.Two p50", "Rule.Two p99"}, labels)
+	assert.Equal(t, []string{"p50", "p99", "p50", "p99"}, stats)
+	assert.Equal(t, ruleMetrics[1], queries[3].MetricStat.Metric)
+
+	// A single statistic is labeled with the rule ID only
+	queries = ruleMetricQueries(input, ruleMetrics, []string{"Sum"})
+	require.Len(t, queries, 2)
+	assert.Equal(t, "Rule.One", *queries[0].Label)
+	assert.Equal(t, "Rule.Two", *queries[1].Label)
+}
@@ -51,6 +51,14 @@ func (API) AddOutput(input *models.AddOutputInput) (*models.AddOutputOutput, err
 		return nil, &genericapi.InvalidInputError{Message: err.Error()}
 	}
 
+	if err = validateOutputTemplates(input.OutputConfig); err != nil {
+		return nil, &genericapi.InvalidInputError{Message: err.Error()}
+	}
+
+	if err = validateDeliveryPolicy(input.DeliveryPolicy, outputType); err != nil {
+		return nil, &genericapi.InvalidInputError{Message: err.Error()}
+	}
+
 	alertOutput := &models.AlertOutput{
 		OutputID:           aws.String(uuid.New().String()),
 		DisplayName:        input.DisplayName,
@@ -62,6 +70,7 @@ func (API) AddOutput(input *models.AddOutputInput) (*models.AddOutputOutput, err
 		OutputConfig:       input.OutputConfig,
 		DefaultForSeverity: input.DefaultForSeverity,
 		AlertTypes:         input.AlertTypes,
+		DeliveryPolicy:     input.DeliveryPolicy,
 	}
 
 	alertOutputItem, err := AlertOutputToItem(alertOutput)
@@ -31,6 +31,7 @@ import (
 	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
 	"github.com/panther-labs/panther/api/lambda/outputs/models"
 	"github.com/panther-labs/panther/internal/core/outputs_api/table"
+	"github.com/panther-labs/panther/pkg/genericapi"
 )
 
 func TestAddOutputSameNameAlreadyExists(t *testing.T) {
@@ -289,3 +290,23 @@ func TestAddOutputAsana(t *testing.T) {
 	_, err = uuid.Parse(*result.OutputID)
 	assert.NoError(t, err)
 }
+
+func TestAddOutputBatchingNotSupported(t *testing.T) {
+	mockOutputTable := &mockOutputTable{}
+	outputsTable = mockOutputTable
+
+	mockOutputTable.On("GetOutputByName", aws.String("my-topic")).Return(nil, nil)
+
+	input := &models.AddOutputInput{
+		UserID:         aws.String("userId"),
+		DisplayName:    aws.String("my-topic"),
+		OutputConfig:   &models.OutputConfig{Sns: &models.SnsConfig{TopicArn: "arn:aws:sns:us-west-2:123456789012:MyTopic"}},
+		DeliveryPolicy: &models.DeliveryPolicy{BatchWindowMinutes: 10},
+	}
+
+	result, err := (API{}).AddOutput(input)
+	assert.Nil(t, result)
+	assert.Equal(t, &genericapi.InvalidInputError{
+		Message: "batching is only supported by Slack and Microsoft Teams outputs"}, err)
+	mockOutputTable.AssertExpectations(t)
+}
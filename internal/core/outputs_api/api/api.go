@@ -40,4 +40,17 @@ var (
 		os.Getenv("OUTPUTS_TABLE_NAME"),
 		os.Getenv("OUTPUTS_DISPLAY_NAME_INDEX_NAME"),
 		awsSession)
+
+	routingRulesTable table.RoutingRulesAPI = table.NewRoutingRules(os.Getenv("ROUTING_RULES_TABLE_NAME"), awsSession)
+
+	maintenanceWindowsTable table.MaintenanceWindowsAPI = table.NewMaintenanceWindows(
+		os.Getenv("MAINTENANCE_WINDOWS_TABLE_NAME"), awsSession)
+
+	escalationPoliciesTable table.EscalationPoliciesAPI = table.NewEscalationPolicies(
+		os.Getenv("ESCALATION_POLICIES_TABLE_NAME"), awsSession)
+
+	onCallSchedulesTable table.OnCallSchedulesAPI = table.NewOnCallSchedules(
+		os.Getenv("ONCALL_SCHEDULES_TABLE_NAME"), awsSession)
+
+	outputHealthTable table.OutputHealthAPI = table.NewOutputHealth(os.Getenv("OUTPUT_HEALTH_TABLE_NAME"), awsSession)
 )
@@ -22,6 +22,7 @@ import (
 	jsoniter "github.com/json-iterator/go"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
 	"github.com/panther-labs/panther/internal/core/outputs_api/table"
 	"github.com/panther-labs/panther/pkg/encryption"
 )
@@ -65,6 +66,116 @@ func (m *mockOutputTable) PutOutput(output *table.AlertOutputItem) error {
 	return args.Error(0)
 }
 
+type mockRoutingRulesTable struct {
+	table.RoutingRulesTable
+	mock.Mock
+}
+
+func (m *mockRoutingRulesTable) GetRoutingRules() ([]*models.RoutingRule, error) {
+	args := m.Called()
+	return args.Get(0).([]*models.RoutingRule), args.Error(1)
+}
+
+func (m *mockRoutingRulesTable) GetRoutingRule(ruleID string) (*models.RoutingRule, error) {
+	args := m.Called(ruleID)
+	return args.Get(0).(*models.RoutingRule), args.Error(1)
+}
+
+func (m *mockRoutingRulesTable) PutRoutingRule(rule *models.RoutingRule) error {
+	args := m.Called(rule)
+	return args.Error(0)
+}
+
+func (m *mockRoutingRulesTable) DeleteRoutingRule(ruleID string) error {
+	args := m.Called(ruleID)
+	return args.Error(0)
+}
+
+type mockMaintenanceWindowsTable struct {
+	table.MaintenanceWindowsTable
+	mock.Mock
+}
+
+func (m *mockMaintenanceWindowsTable) GetMaintenanceWindows() ([]*models.MaintenanceWindow, error) {
+	args := m.Called()
+	return args.Get(0).([]*models.MaintenanceWindow), args.Error(1)
+}
+
+func (m *mockMaintenanceWindowsTable) GetMaintenanceWindow(windowID string) (*models.MaintenanceWindow, error) {
+	args := m.Called(windowID)
+	return args.Get(0).(*models.MaintenanceWindow), args.Error(1)
+}
+
+func (m *mockMaintenanceWindowsTable) PutMaintenanceWindow(window *models.MaintenanceWindow) error {
+	args := m.Called(window)
+	return args.Error(0)
+}
+
+func (m *mockMaintenanceWindowsTable) DeleteMaintenanceWindow(windowID string) error {
+	args := m.Called(windowID)
+	return args.Error(0)
+}
+
+type mockEscalationPoliciesTable struct {
+	table.EscalationPoliciesTable
+	mock.Mock
+}
+
+func (m *mockEscalationPoliciesTable) GetEscalationPolicies() ([]*models.EscalationPolicy, error) {
+	args := m.Called()
+	return args.Get(0).([]*models.EscalationPolicy), args.Error(1)
+}
+
+func (m *mockEscalationPoliciesTable) GetEscalationPolicy(policyID string) (*models.EscalationPolicy, error) {
+	args := m.Called(policyID)
+	return args.Get(0).(*models.EscalationPolicy), args.Error(1)
+}
+
+func (m *mockEscalationPoliciesTable) PutEscalationPolicy(policy *models.EscalationPolicy) error {
+	args := m.Called(policy)
+	return args.Error(0)
+}
+
+func (m *mockEscalationPoliciesTable) DeleteEscalationPolicy(policyID string) error {
+	args := m.Called(policyID)
+	return args.Error(0)
+}
+
+type mockOnCallSchedulesTable struct {
+	table.OnCallSchedulesTable
+	mock.Mock
+}
+
+func (m *mockOnCallSchedulesTable) GetOnCallSchedules() ([]*models.OnCallSchedule, error) {
+	args := m.Called()
+	return args.Get(0).([]*models.OnCallSchedule), args.Error(1)
+}
+
+func (m *mockOnCallSchedulesTable) GetOnCallSchedule(scheduleID string) (*models.OnCallSchedule, error) {
+	args := m.Called(scheduleID)
+	return args.Get(0).(*models.OnCallSchedule), args.Error(1)
+}
+
+func (m *mockOnCallSchedulesTable) PutOnCallSchedule(schedule *models.OnCallSchedule) error {
+	args := m.Called(schedule)
+	return args.Error(0)
+}
+
+func (m *mockOnCallSchedulesTable) DeleteOnCallSchedule(scheduleID string) error {
+	args := m.Called(scheduleID)
+	return args.Error(0)
+}
+
+type mockOutputHealthTable struct {
+	table.OutputHealthTable
+	mock.Mock
+}
+
+func (m *mockOutputHealthTable) GetOutputsHealth() ([]*models.OutputHealth, error) {
+	args := m.Called()
+	return args.Get(0).([]*models.OutputHealth), args.Error(1)
+}
+
 type mockEncryptionKey struct {
 	encryption.Key
 	mock.Mock
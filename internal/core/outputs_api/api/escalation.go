@@ -0,0 +1,208 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/google/uuid"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// ListEscalationPolicies returns the escalation policies ordered by display name.
+func (API) ListEscalationPolicies(_ *models.ListEscalationPoliciesInput) (models.ListEscalationPoliciesOutput, error) {
+	policies, err := escalationPoliciesTable.GetEscalationPolicies()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(policies, func(i, j int) bool {
+		if policies[i].DisplayName != policies[j].DisplayName {
+			return policies[i].DisplayName < policies[j].DisplayName
+		}
+		return policies[i].PolicyID < policies[j].PolicyID
+	})
+	return policies, nil
+}
+
+// PutEscalationPolicy creates an escalation policy, or replaces an existing one.
+func (API) PutEscalationPolicy(input *models.PutEscalationPolicyInput) (*models.PutEscalationPolicyOutput, error) {
+	if err := validateEscalationSteps(input.Steps); err != nil {
+		return nil, &genericapi.InvalidInputError{Message: err.Error()}
+	}
+	for _, step := range input.Steps {
+		if err := checkRoutingDestinations(step.OutputIDs); err != nil {
+			return nil, err
+		}
+		if err := checkOnCallSchedules(step.ScheduleIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now().UTC()
+	policy := &models.EscalationPolicy{
+		PolicyID:         input.PolicyID,
+		DisplayName:      input.DisplayName,
+		Enabled:          input.Enabled,
+		Match:            input.Match,
+		Steps:            input.Steps,
+		CreatedBy:        input.UserID,
+		CreationTime:     now,
+		LastModifiedBy:   input.UserID,
+		LastModifiedTime: now,
+	}
+
+	if policy.PolicyID == "" {
+		policy.PolicyID = uuid.New().String()
+	} else {
+		existing, err := escalationPoliciesTable.GetEscalationPolicy(policy.PolicyID)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			return nil, &genericapi.DoesNotExistError{Message: "policyId=" + policy.PolicyID + " does not exist"}
+		}
+		policy.CreatedBy, policy.CreationTime = existing.CreatedBy, existing.CreationTime
+	}
+
+	if err := escalationPoliciesTable.PutEscalationPolicy(policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// DeleteEscalationPolicy removes an escalation policy.
+func (API) DeleteEscalationPolicy(input *models.DeleteEscalationPolicyInput) error {
+	return escalationPoliciesTable.DeleteEscalationPolicy(input.PolicyID)
+}
+
+// ListOnCallSchedules returns the on-call schedules ordered by display name.
+func (API) ListOnCallSchedules(_ *models.ListOnCallSchedulesInput) (models.ListOnCallSchedulesOutput, error) {
+	schedules, err := onCallSchedulesTable.GetOnCallSchedules()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(schedules, func(i, j int) bool {
+		if schedules[i].DisplayName != schedules[j].DisplayName {
+			return schedules[i].DisplayName < schedules[j].DisplayName
+		}
+		return schedules[i].ScheduleID < schedules[j].ScheduleID
+	})
+	return schedules, nil
+}
+
+// PutOnCallSchedule creates an on-call schedule, or replaces an existing one.
+func (API) PutOnCallSchedule(input *models.PutOnCallScheduleInput) (*models.PutOnCallScheduleOutput, error) {
+	output, err := outputsTable.GetOutput(aws.String(input.OutputID))
+	if err != nil {
+		var notFound *genericapi.DoesNotExistError
+		if errors.As(err, &notFound) {
+			return nil, &genericapi.InvalidInputError{Message: "destination " + input.OutputID + " does not exist"}
+		}
+		return nil, err
+	}
+	if aws.StringValue(output.OutputType) != "email" {
+		return nil, &genericapi.InvalidInputError{Message: "on-call schedules notify users through an email destination"}
+	}
+
+	now := time.Now().UTC()
+	schedule := &models.OnCallSchedule{
+		ScheduleID:       input.ScheduleID,
+		DisplayName:      input.DisplayName,
+		OutputID:         input.OutputID,
+		RotationStart:    input.RotationStart.UTC(),
+		ShiftHours:       input.ShiftHours,
+		UserIDs:          input.UserIDs,
+		CreatedBy:        input.UserID,
+		CreationTime:     now,
+		LastModifiedBy:   input.UserID,
+		LastModifiedTime: now,
+	}
+
+	if schedule.ScheduleID == "" {
+		schedule.ScheduleID = uuid.New().String()
+	} else {
+		existing, err := onCallSchedulesTable.GetOnCallSchedule(schedule.ScheduleID)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			return nil, &genericapi.DoesNotExistError{Message: "scheduleId=" + schedule.ScheduleID + " does not exist"}
+		}
+		schedule.CreatedBy, schedule.CreationTime = existing.CreatedBy, existing.CreationTime
+	}
+
+	if err := onCallSchedulesTable.PutOnCallSchedule(schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// DeleteOnCallSchedule removes an on-call schedule which is not used by any escalation policy.
+func (API) DeleteOnCallSchedule(input *models.DeleteOnCallScheduleInput) error {
+	policies, err := escalationPoliciesTable.GetEscalationPolicies()
+	if err != nil {
+		return err
+	}
+	for _, policy := range policies {
+		for _, step := range policy.Steps {
+			for _, scheduleID := range step.ScheduleIDs {
+				if scheduleID == input.ScheduleID {
+					return &genericapi.InvalidInputError{
+						Message: "the schedule is used by escalation policy " + policy.DisplayName}
+				}
+			}
+		}
+	}
+	return onCallSchedulesTable.DeleteOnCallSchedule(input.ScheduleID)
+}
+
+// validateEscalationSteps checks that steps have targets and come in increasing order
+func validateEscalationSteps(steps []models.EscalationStep) error {
+	for i, step := range steps {
+		if len(step.OutputIDs) == 0 && len(step.ScheduleIDs) == 0 {
+			return errors.New("escalation step " + strconv.Itoa(i+1) + " needs destinations or schedules")
+		}
+		if i > 0 && step.AfterMinutes <= steps[i-1].AfterMinutes {
+			return errors.New("escalation steps must be in increasing order of afterMinutes")
+		}
+	}
+	return nil
+}
+
+// checkOnCallSchedules makes sure all schedules of an escalation step exist
+func checkOnCallSchedules(scheduleIDs []string) error {
+	for _, scheduleID := range scheduleIDs {
+		schedule, err := onCallSchedulesTable.GetOnCallSchedule(scheduleID)
+		if err != nil {
+			return err
+		}
+		if schedule == nil {
+			return &genericapi.InvalidInputError{Message: "on-call schedule " + scheduleID + " does not exist"}
+		}
+	}
+	return nil
+}
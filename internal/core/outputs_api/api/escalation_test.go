@@ -0,0 +1,162 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/internal/core/outputs_api/table"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const scheduleID = "0dd5ec36-43b5-4bb3-a4d4-7a1e6d7a2cf5"
+
+func TestListEscalationPoliciesSorted(t *testing.T) {
+	mockTable := &mockEscalationPoliciesTable{}
+	escalationPoliciesTable = mockTable
+	mockTable.On("GetEscalationPolicies").Return([]*models.EscalationPolicy{
+		{PolicyID: "c", DisplayName: "b"}, {PolicyID: "b", DisplayName: "a"}, {PolicyID: "a", DisplayName: "b"},
+	}, nil)
+
+	result, err := (API{}).ListEscalationPolicies(&models.ListEscalationPoliciesInput{})
+	require.NoError(t, err)
+	assert.Equal(t, models.ListEscalationPoliciesOutput{
+		{PolicyID: "b", DisplayName: "a"}, {PolicyID: "a", DisplayName: "b"}, {PolicyID: "c", DisplayName: "b"},
+	}, result)
+}
+
+func TestPutEscalationPolicyCreate(t *testing.T) {
+	mockTable := &mockEscalationPoliciesTable{}
+	escalationPoliciesTable = mockTable
+	mockSchedules := &mockOnCallSchedulesTable{}
+	onCallSchedulesTable = mockSchedules
+	mockOutputTable := &mockOutputTable{}
+	outputsTable = mockOutputTable
+	mockOutputTable.On("GetOutput", aws.String(routingOutputID)).Return(&table.AlertOutputItem{}, nil)
+	mockSchedules.On("GetOnCallSchedule", scheduleID).Return(&models.OnCallSchedule{ScheduleID: scheduleID}, nil)
+	mockTable.On("PutEscalationPolicy", mock.Anything).Return(nil)
+
+	input := &models.PutEscalationPolicyInput{
+		UserID:      routingUserID,
+		DisplayName: "Critical alerts",
+		Enabled:     true,
+		Match:       models.EscalationMatch{Severities: []string{"CRITICAL"}},
+		Steps: []models.EscalationStep{
+			{AfterMinutes: 15, ScheduleIDs: []string{scheduleID}},
+			{AfterMinutes: 60, OutputIDs: []string{routingOutputID}},
+		},
+	}
+	result, err := (API{}).PutEscalationPolicy(input)
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.PolicyID)
+	assert.Equal(t, routingUserID, result.CreatedBy)
+	assert.Equal(t, input.Steps, result.Steps)
+	mockTable.AssertExpectations(t)
+	mockSchedules.AssertExpectations(t)
+	mockOutputTable.AssertExpectations(t)
+}
+
+func TestPutEscalationPolicyMissingSchedule(t *testing.T) {
+	mockSchedules := &mockOnCallSchedulesTable{}
+	onCallSchedulesTable = mockSchedules
+	mockSchedules.On("GetOnCallSchedule", scheduleID).Return((*models.OnCallSchedule)(nil), nil)
+
+	result, err := (API{}).PutEscalationPolicy(&models.PutEscalationPolicyInput{
+		UserID:      routingUserID,
+		DisplayName: "Critical alerts",
+		Steps:       []models.EscalationStep{{AfterMinutes: 15, ScheduleIDs: []string{scheduleID}}},
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
+
+func TestValidateEscalationSteps(t *testing.T) {
+	assert.Error(t, validateEscalationSteps([]models.EscalationStep{{AfterMinutes: 15}}))
+	assert.Error(t, validateEscalationSteps([]models.EscalationStep{
+		{AfterMinutes: 30, OutputIDs: []string{routingOutputID}},
+		{AfterMinutes: 30, OutputIDs: []string{routingOutputID}},
+	}))
+	assert.NoError(t, validateEscalationSteps([]models.EscalationStep{
+		{AfterMinutes: 30, OutputIDs: []string{routingOutputID}},
+		{AfterMinutes: 45, ScheduleIDs: []string{scheduleID}},
+	}))
+}
+
+func TestPutOnCallScheduleCreate(t *testing.T) {
+	mockSchedules := &mockOnCallSchedulesTable{}
+	onCallSchedulesTable = mockSchedules
+	mockOutputTable := &mockOutputTable{}
+	outputsTable = mockOutputTable
+	mockOutputTable.On("GetOutput", aws.String(routingOutputID)).Return(
+		&table.AlertOutputItem{OutputType: aws.String("email")}, nil)
+	mockSchedules.On("PutOnCallSchedule", mock.Anything).Return(nil)
+
+	start := time.Date(2020, 7, 6, 9, 0, 0, 0, time.UTC)
+	result, err := (API{}).PutOnCallSchedule(&models.PutOnCallScheduleInput{
+		UserID:        routingUserID,
+		DisplayName:   "Security on-call",
+		OutputID:      routingOutputID,
+		RotationStart: start.In(time.FixedZone("EST", -5*60*60)),
+		ShiftHours:    168,
+		UserIDs:       []string{routingUserID},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.ScheduleID)
+	assert.Equal(t, start, result.RotationStart)
+	mockSchedules.AssertExpectations(t)
+}
+
+func TestPutOnCallScheduleNotEmail(t *testing.T) {
+	mockOutputTable := &mockOutputTable{}
+	outputsTable = mockOutputTable
+	mockOutputTable.On("GetOutput", aws.String(routingOutputID)).Return(
+		&table.AlertOutputItem{OutputType: aws.String("slack")}, nil)
+
+	result, err := (API{}).PutOnCallSchedule(&models.PutOnCallScheduleInput{
+		UserID:      routingUserID,
+		DisplayName: "Security on-call",
+		OutputID:    routingOutputID,
+		ShiftHours:  24,
+		UserIDs:     []string{routingUserID},
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
+
+func TestDeleteOnCallScheduleInUse(t *testing.T) {
+	mockTable := &mockEscalationPoliciesTable{}
+	escalationPoliciesTable = mockTable
+	mockSchedules := &mockOnCallSchedulesTable{}
+	onCallSchedulesTable = mockSchedules
+	mockTable.On("GetEscalationPolicies").Return([]*models.EscalationPolicy{{
+		DisplayName: "Critical alerts",
+		Steps:       []models.EscalationStep{{AfterMinutes: 15, ScheduleIDs: []string{scheduleID}}},
+	}}, nil)
+
+	err := (API{}).DeleteOnCallSchedule(&models.DeleteOnCallScheduleInput{ScheduleID: scheduleID})
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+	mockSchedules.AssertNotCalled(t, "DeleteOnCallSchedule", scheduleID)
+}
@@ -0,0 +1,58 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"time"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+// GetOutputsHealth returns the circuit breaker state of the outputs alert delivery recorded deliveries for
+func (API) GetOutputsHealth(input *models.GetOutputsHealthInput) (models.GetOutputsHealthOutput, error) {
+	health, err := outputHealthTable.GetOutputsHealth()
+	if err != nil {
+		return nil, err
+	}
+
+	requested := make(map[string]bool, len(input.OutputIDs))
+	for _, outputID := range input.OutputIDs {
+		requested[outputID] = true
+	}
+
+	now := time.Now()
+	result := make(models.GetOutputsHealthOutput, 0, len(health))
+	for _, item := range health {
+		if len(requested) > 0 && !requested[item.OutputID] {
+			continue
+		}
+		if item.CircuitState == "" {
+			item.CircuitState = models.CircuitClosed
+		}
+		// Once the cooldown has passed, the next delivery is let through as a trial
+		if item.CircuitState == models.CircuitOpen && item.OpenUntil != nil && now.After(*item.OpenUntil) {
+			item.CircuitState = models.CircuitHalfOpen
+		}
+		result = append(result, item)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].OutputID < result[j].OutputID })
+	return result, nil
+}
@@ -0,0 +1,54 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+func TestGetOutputsHealth(t *testing.T) {
+	mockTable := &mockOutputHealthTable{}
+	outputHealthTable = mockTable
+
+	past, future := time.Now().Add(-time.Minute), time.Now().Add(time.Minute)
+	mockTable.On("GetOutputsHealth").Return([]*models.OutputHealth{
+		{OutputID: "c", CircuitState: models.CircuitOpen, OpenUntil: &past, ConsecutiveFailures: 5},
+		{OutputID: "b", CircuitState: models.CircuitOpen, OpenUntil: &future, ConsecutiveFailures: 5},
+		{OutputID: "a"},
+	}, nil)
+
+	result, err := (API{}).GetOutputsHealth(&models.GetOutputsHealthInput{})
+	require.NoError(t, err)
+	assert.Equal(t, models.GetOutputsHealthOutput{
+		{OutputID: "a", CircuitState: models.CircuitClosed},
+		{OutputID: "b", CircuitState: models.CircuitOpen, OpenUntil: &future, ConsecutiveFailures: 5},
+		{OutputID: "c", CircuitState: models.CircuitHalfOpen, OpenUntil: &past, ConsecutiveFailures: 5},
+	}, result)
+
+	result, err = (API{}).GetOutputsHealth(&models.GetOutputsHealthInput{OutputIDs: []string{"b"}})
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, "b", result[0].OutputID)
+}
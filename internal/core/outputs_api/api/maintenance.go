@@ -0,0 +1,121 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// ListMaintenanceWindows returns the maintenance windows ordered by start time.
+func (API) ListMaintenanceWindows(_ *models.ListMaintenanceWindowsInput) (models.ListMaintenanceWindowsOutput, error) {
+	windows, err := maintenanceWindowsTable.GetMaintenanceWindows()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(windows, func(i, j int) bool {
+		if !windows[i].StartTime.Equal(windows[j].StartTime) {
+			return windows[i].StartTime.Before(windows[j].StartTime)
+		}
+		return windows[i].WindowID < windows[j].WindowID
+	})
+	return windows, nil
+}
+
+// PutMaintenanceWindow creates a maintenance window, or replaces an existing one.
+func (API) PutMaintenanceWindow(input *models.PutMaintenanceWindowInput) (*models.PutMaintenanceWindowOutput, error) {
+	if err := validateMaintenanceWindow(input); err != nil {
+		return nil, &genericapi.InvalidInputError{Message: err.Error()}
+	}
+
+	now := time.Now().UTC()
+	window := &models.MaintenanceWindow{
+		WindowID:         input.WindowID,
+		DisplayName:      input.DisplayName,
+		Enabled:          input.Enabled,
+		StartTime:        input.StartTime.UTC(),
+		EndTime:          input.EndTime.UTC(),
+		Recurrence:       input.Recurrence,
+		RecurUntil:       input.RecurUntil,
+		Match:            input.Match,
+		CreatedBy:        input.UserID,
+		CreationTime:     now,
+		LastModifiedBy:   input.UserID,
+		LastModifiedTime: now,
+	}
+
+	if window.WindowID == "" {
+		window.WindowID = uuid.New().String()
+	} else {
+		existing, err := maintenanceWindowsTable.GetMaintenanceWindow(window.WindowID)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			return nil, &genericapi.DoesNotExistError{Message: "windowId=" + window.WindowID + " does not exist"}
+		}
+		window.CreatedBy, window.CreationTime = existing.CreatedBy, existing.CreationTime
+	}
+
+	if err := maintenanceWindowsTable.PutMaintenanceWindow(window); err != nil {
+		return nil, err
+	}
+	return window, nil
+}
+
+// DeleteMaintenanceWindow removes a maintenance window.
+//
+// Alerts suppressed by the window are still delivered when its current occurrence would have ended.
+func (API) DeleteMaintenanceWindow(input *models.DeleteMaintenanceWindowInput) error {
+	return maintenanceWindowsTable.DeleteMaintenanceWindow(input.WindowID)
+}
+
+// validateMaintenanceWindow checks the time range of a maintenance window
+func validateMaintenanceWindow(input *models.PutMaintenanceWindowInput) error {
+	if !input.EndTime.After(input.StartTime) {
+		return errors.New("endTime must be after startTime")
+	}
+
+	duration := input.EndTime.Sub(input.StartTime)
+	switch input.Recurrence {
+	case models.RecurrenceDaily:
+		if duration > 24*time.Hour {
+			return errors.New("daily maintenance windows can not be longer than a day")
+		}
+	case models.RecurrenceWeekly:
+		if duration > 7*24*time.Hour {
+			return errors.New("weekly maintenance windows can not be longer than a week")
+		}
+	default:
+		if input.RecurUntil != nil {
+			return errors.New("only recurring maintenance windows can set recurUntil")
+		}
+	}
+	if input.RecurUntil != nil && input.RecurUntil.Before(input.StartTime) {
+		return errors.New("recurUntil must be after startTime")
+	}
+	return nil
+}
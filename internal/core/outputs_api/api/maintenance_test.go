@@ -0,0 +1,118 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+var maintenanceStart = time.Date(2020, 7, 4, 22, 0, 0, 0, time.UTC)
+
+func TestListMaintenanceWindowsSorted(t *testing.T) {
+	mockTable := &mockMaintenanceWindowsTable{}
+	maintenanceWindowsTable = mockTable
+	later := maintenanceStart.Add(time.Hour)
+	mockTable.On("GetMaintenanceWindows").Return([]*models.MaintenanceWindow{
+		{WindowID: "c", StartTime: later}, {WindowID: "b", StartTime: maintenanceStart}, {WindowID: "a", StartTime: later},
+	}, nil)
+
+	result, err := (API{}).ListMaintenanceWindows(&models.ListMaintenanceWindowsInput{})
+	require.NoError(t, err)
+	assert.Equal(t, models.ListMaintenanceWindowsOutput{
+		{WindowID: "b", StartTime: maintenanceStart}, {WindowID: "a", StartTime: later}, {WindowID: "c", StartTime: later},
+	}, result)
+}
+
+func TestPutMaintenanceWindowCreate(t *testing.T) {
+	mockTable := &mockMaintenanceWindowsTable{}
+	maintenanceWindowsTable = mockTable
+	mockTable.On("PutMaintenanceWindow", mock.Anything).Return(nil)
+
+	input := &models.PutMaintenanceWindowInput{
+		UserID:      routingUserID,
+		DisplayName: "Weekly database patching",
+		Enabled:     true,
+		StartTime:   maintenanceStart.In(time.FixedZone("EST", -5*60*60)),
+		EndTime:     maintenanceStart.Add(4 * time.Hour),
+		Recurrence:  models.RecurrenceWeekly,
+		Match:       models.MaintenanceMatch{LogTypes: []string{"AWS.RDS"}},
+	}
+	result, err := (API{}).PutMaintenanceWindow(input)
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.WindowID)
+	assert.Equal(t, routingUserID, result.CreatedBy)
+	assert.Equal(t, maintenanceStart, result.StartTime)
+	assert.Equal(t, input.Match, result.Match)
+	mockTable.AssertExpectations(t)
+}
+
+func TestPutMaintenanceWindowUpdateDoesNotExist(t *testing.T) {
+	mockTable := &mockMaintenanceWindowsTable{}
+	maintenanceWindowsTable = mockTable
+	mockTable.On("GetMaintenanceWindow", "window-id").Return((*models.MaintenanceWindow)(nil), nil)
+
+	result, err := (API{}).PutMaintenanceWindow(&models.PutMaintenanceWindowInput{
+		UserID:     routingUserID,
+		WindowID:   "window-id",
+		StartTime:  maintenanceStart,
+		EndTime:    maintenanceStart.Add(time.Hour),
+		Recurrence: models.RecurrenceNone,
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+	mockTable.AssertExpectations(t)
+}
+
+func TestValidateMaintenanceWindow(t *testing.T) {
+	for _, input := range []*models.PutMaintenanceWindowInput{
+		{StartTime: maintenanceStart, EndTime: maintenanceStart, Recurrence: models.RecurrenceNone},
+		{StartTime: maintenanceStart, EndTime: maintenanceStart.Add(25 * time.Hour), Recurrence: models.RecurrenceDaily},
+		{StartTime: maintenanceStart, EndTime: maintenanceStart.Add(8 * 24 * time.Hour), Recurrence: models.RecurrenceWeekly},
+		{
+			StartTime:  maintenanceStart,
+			EndTime:    maintenanceStart.Add(time.Hour),
+			Recurrence: models.RecurrenceNone,
+			RecurUntil: aws.Time(maintenanceStart.Add(24 * time.Hour)),
+		},
+		{
+			StartTime:  maintenanceStart,
+			EndTime:    maintenanceStart.Add(time.Hour),
+			Recurrence: models.RecurrenceDaily,
+			RecurUntil: aws.Time(maintenanceStart.Add(-time.Hour)),
+		},
+	} {
+		assert.Error(t, validateMaintenanceWindow(input), input)
+	}
+
+	assert.NoError(t, validateMaintenanceWindow(&models.PutMaintenanceWindowInput{
+		StartTime:  maintenanceStart,
+		EndTime:    maintenanceStart.Add(24 * time.Hour),
+		Recurrence: models.RecurrenceDaily,
+		RecurUntil: aws.Time(maintenanceStart.Add(30 * 24 * time.Hour)),
+	}))
+}
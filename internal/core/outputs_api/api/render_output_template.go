@@ -0,0 +1,71 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// RenderOutputTemplate renders a payload template so users can preview it before saving an output.
+func (API) RenderOutputTemplate(input *models.RenderOutputTemplateInput) (*models.RenderOutputTemplateOutput, error) {
+	alert := input.Alert
+	if alert == nil {
+		alert = sampleAlert()
+	}
+	if alert.AlertID == nil {
+		alert.AlertID = aws.String(alert.AnalysisID)
+	}
+
+	body, err := outputs.RenderTemplate(input.Template, alert)
+	if err != nil {
+		return nil, &genericapi.InvalidInputError{Message: "invalid payload template: " + err.Error()}
+	}
+	return &models.RenderOutputTemplateOutput{Body: string(body)}, nil
+}
+
+// sampleAlert is a rule alert with dummy values and a small alert context
+func sampleAlert() *deliverymodel.Alert {
+	return &deliverymodel.Alert{
+		AlertID:             aws.String("Sample.Alert"),
+		AnalysisID:          "Sample.Rule",
+		AnalysisName:        aws.String("Sample Rule"),
+		AnalysisDescription: "This is a sample alert",
+		Type:                deliverymodel.RuleType,
+		CreatedAt:           time.Now().UTC(),
+		Severity:            "HIGH",
+		Runbook:             "Stuck? Check out our docs: https://docs.runpanther.io",
+		Tags:                []string{"sample"},
+		Title:               "Sample alert title",
+		Version:             aws.String("abcdefg"),
+		Context: map[string]interface{}{
+			"host": map[string]interface{}{
+				"name": "web-01",
+				"ip":   "10.0.0.1",
+			},
+			"user": "alice",
+		},
+	}
+}
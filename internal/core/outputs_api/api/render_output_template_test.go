@@ -0,0 +1,68 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func TestRenderOutputTemplateSampleAlert(t *testing.T) {
+	input := &models.RenderOutputTemplateInput{
+		Template: `{"summary": {{json .Title}}, "host": {{json (context .AlertContext "host.name")}}}`,
+	}
+
+	result, err := (API{}).RenderOutputTemplate(input)
+	require.NoError(t, err)
+	assert.Equal(t, `{"summary": "New Alert: Sample alert title", "host": "web-01"}`, result.Body)
+}
+
+func TestRenderOutputTemplateInvalid(t *testing.T) {
+	input := &models.RenderOutputTemplateInput{Template: "{{.Title"}
+
+	result, err := (API{}).RenderOutputTemplate(input)
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
+
+func TestAddOutputInvalidTemplate(t *testing.T) {
+	mockOutputTable := &mockOutputTable{}
+	outputsTable = mockOutputTable
+	mockOutputTable.On("GetOutputByName", aws.String("my-webhook")).Return(nil, nil)
+
+	input := &models.AddOutputInput{
+		UserID:      aws.String("userId"),
+		DisplayName: aws.String("my-webhook"),
+		OutputConfig: &models.OutputConfig{CustomWebhook: &models.CustomWebhookConfig{
+			WebhookURL:      "https://soar.example.com/hooks/panther",
+			PayloadTemplate: "{{range .Tags}}",
+		}},
+	}
+
+	result, err := (API{}).AddOutput(input)
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+	mockOutputTable.AssertExpectations(t)
+}
@@ -0,0 +1,158 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/google/uuid"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// Layout of the start and end times of routing time windows
+const routingTimeLayout = "15:04"
+
+// ListRoutingRules returns the routing table in evaluation order.
+func (API) ListRoutingRules(_ *models.ListRoutingRulesInput) (models.ListRoutingRulesOutput, error) {
+	rules, err := routingRulesTable.GetRoutingRules()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Order != rules[j].Order {
+			return rules[i].Order < rules[j].Order
+		}
+		return rules[i].RuleID < rules[j].RuleID
+	})
+	return rules, nil
+}
+
+// PutRoutingRule creates a routing rule, or replaces an existing one.
+func (API) PutRoutingRule(input *models.PutRoutingRuleInput) (*models.PutRoutingRuleOutput, error) {
+	if err := validateRoutingRule(input); err != nil {
+		return nil, &genericapi.InvalidInputError{Message: err.Error()}
+	}
+	if err := checkRoutingDestinations(input.Action.OutputIDs); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	rule := &models.RoutingRule{
+		RuleID:           input.RuleID,
+		DisplayName:      input.DisplayName,
+		Order:            input.Order,
+		Enabled:          input.Enabled,
+		Match:            input.Match,
+		Action:           input.Action,
+		CreatedBy:        input.UserID,
+		CreationTime:     now,
+		LastModifiedBy:   input.UserID,
+		LastModifiedTime: now,
+	}
+
+	if rule.RuleID == "" {
+		rule.RuleID = uuid.New().String()
+	} else {
+		existing, err := routingRulesTable.GetRoutingRule(rule.RuleID)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			return nil, &genericapi.DoesNotExistError{Message: "ruleId=" + rule.RuleID + " does not exist"}
+		}
+		rule.CreatedBy, rule.CreationTime = existing.CreatedBy, existing.CreationTime
+	}
+
+	if err := routingRulesTable.PutRoutingRule(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeleteRoutingRule removes a routing rule.
+func (API) DeleteRoutingRule(input *models.DeleteRoutingRuleInput) error {
+	return routingRulesTable.DeleteRoutingRule(input.RuleID)
+}
+
+// validateRoutingRule checks what the struct validator can't: patterns, times and action options
+func validateRoutingRule(input *models.PutRoutingRuleInput) error {
+	match, action := &input.Match, &input.Action
+
+	if _, err := regexp.Compile(match.AnalysisIDPattern); err != nil {
+		return errors.New("invalid analysisIdPattern: " + err.Error())
+	}
+	for _, condition := range match.Context {
+		if condition.Operator != "MATCHES" {
+			continue
+		}
+		if _, err := regexp.Compile(condition.Value); err != nil {
+			return errors.New("invalid pattern for context path " + condition.Path + ": " + err.Error())
+		}
+	}
+
+	if window := match.TimeWindow; window != nil {
+		for _, value := range []string{window.StartTime, window.EndTime} {
+			if _, err := time.Parse(routingTimeLayout, value); err != nil {
+				return errors.New("invalid time window, times must be HH:MM: " + value)
+			}
+		}
+		if _, err := time.LoadLocation(window.Timezone); err != nil {
+			return errors.New("invalid time window timezone: " + window.Timezone)
+		}
+	}
+
+	switch action.Type {
+	case models.RoutingActionDrop:
+		if len(action.OutputIDs) > 0 {
+			return errors.New("DROP rules can not have destinations")
+		}
+	case models.RoutingActionDelay:
+		if action.DelayMinutes == 0 {
+			return errors.New("DELAY rules need delayMinutes")
+		}
+	}
+	if action.Continue && action.Type != models.RoutingActionSend {
+		return errors.New("only SEND rules can continue evaluation")
+	}
+	if action.Severity != "" && action.Type != models.RoutingActionEscalate {
+		return errors.New("only ESCALATE rules can set a severity")
+	}
+	return nil
+}
+
+// checkRoutingDestinations makes sure all destinations of a routing rule exist
+func checkRoutingDestinations(outputIDs []string) error {
+	for _, outputID := range outputIDs {
+		if _, err := outputsTable.GetOutput(aws.String(outputID)); err != nil {
+			var notFound *genericapi.DoesNotExistError
+			if errors.As(err, &notFound) {
+				return &genericapi.InvalidInputError{Message: "destination " + outputID + " does not exist"}
+			}
+			return err
+		}
+	}
+	return nil
+}
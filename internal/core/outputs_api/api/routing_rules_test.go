@@ -0,0 +1,151 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/internal/core/outputs_api/table"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	routingUserID   = "9d1c5854-f3ea-491c-8a52-0aa0d58cb456"
+	routingOutputID = "7d1c5854-f3ea-491c-8a52-0aa0d58cb456"
+)
+
+func TestListRoutingRulesSorted(t *testing.T) {
+	mockTable := &mockRoutingRulesTable{}
+	routingRulesTable = mockTable
+	mockTable.On("GetRoutingRules").Return([]*models.RoutingRule{
+		{RuleID: "c", Order: 20}, {RuleID: "b", Order: 10}, {RuleID: "a", Order: 20},
+	}, nil)
+
+	result, err := (API{}).ListRoutingRules(&models.ListRoutingRulesInput{})
+	require.NoError(t, err)
+	assert.Equal(t, models.ListRoutingRulesOutput{
+		{RuleID: "b", Order: 10}, {RuleID: "a", Order: 20}, {RuleID: "c", Order: 20},
+	}, result)
+}
+
+func TestPutRoutingRuleCreate(t *testing.T) {
+	mockTable := &mockRoutingRulesTable{}
+	routingRulesTable = mockTable
+	mockOutputTable := &mockOutputTable{}
+	outputsTable = mockOutputTable
+
+	mockOutputTable.On("GetOutput", aws.String(routingOutputID)).Return(&table.AlertOutputItem{}, nil)
+	mockTable.On("PutRoutingRule", mock.Anything).Return(nil)
+
+	input := &models.PutRoutingRuleInput{
+		UserID:      routingUserID,
+		DisplayName: "Critical to the SOC",
+		Enabled:     true,
+		Match:       models.RoutingMatch{Severities: []string{"CRITICAL"}},
+		Action:      models.RoutingAction{Type: models.RoutingActionSend, OutputIDs: []string{routingOutputID}},
+	}
+	result, err := (API{}).PutRoutingRule(input)
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.RuleID)
+	assert.Equal(t, routingUserID, result.CreatedBy)
+	assert.Equal(t, input.Action, result.Action)
+	mockTable.AssertExpectations(t)
+	mockOutputTable.AssertExpectations(t)
+}
+
+func TestPutRoutingRuleUpdateKeepsCreator(t *testing.T) {
+	mockTable := &mockRoutingRulesTable{}
+	routingRulesTable = mockTable
+
+	created := time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC)
+	mockTable.On("GetRoutingRule", "rule-id").Return(&models.RoutingRule{CreatedBy: "creator", CreationTime: created}, nil)
+	mockTable.On("PutRoutingRule", mock.Anything).Return(nil)
+
+	input := &models.PutRoutingRuleInput{
+		UserID:      routingUserID,
+		RuleID:      "rule-id",
+		DisplayName: "Drop test alerts",
+		Match:       models.RoutingMatch{AnalysisIDPattern: `^Test\.`},
+		Action:      models.RoutingAction{Type: models.RoutingActionDrop},
+	}
+	result, err := (API{}).PutRoutingRule(input)
+	require.NoError(t, err)
+	assert.Equal(t, "creator", result.CreatedBy)
+	assert.Equal(t, created, result.CreationTime)
+	assert.Equal(t, routingUserID, result.LastModifiedBy)
+	mockTable.AssertExpectations(t)
+}
+
+func TestPutRoutingRuleMissingDestination(t *testing.T) {
+	mockOutputTable := &mockOutputTable{}
+	outputsTable = mockOutputTable
+	mockOutputTable.On("GetOutput", aws.String(routingOutputID)).Return(
+		(*table.AlertOutputItem)(nil), &genericapi.DoesNotExistError{})
+
+	input := &models.PutRoutingRuleInput{
+		UserID:      routingUserID,
+		DisplayName: "Escalate",
+		Action:      models.RoutingAction{Type: models.RoutingActionEscalate, OutputIDs: []string{routingOutputID}},
+	}
+	result, err := (API{}).PutRoutingRule(input)
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
+
+func TestValidateRoutingRule(t *testing.T) {
+	for _, input := range []*models.PutRoutingRuleInput{
+		{Match: models.RoutingMatch{AnalysisIDPattern: "("}, Action: models.RoutingAction{Type: models.RoutingActionSend}},
+		{
+			Match: models.RoutingMatch{
+				Context: []models.RoutingContextCondition{{Path: "user", Operator: "MATCHES", Value: "["}},
+			},
+			Action: models.RoutingAction{Type: models.RoutingActionSend},
+		},
+		{
+			Match:  models.RoutingMatch{TimeWindow: &models.RoutingTimeWindow{StartTime: "25:00", EndTime: "08:00"}},
+			Action: models.RoutingAction{Type: models.RoutingActionSend},
+		},
+		{
+			Match: models.RoutingMatch{
+				TimeWindow: &models.RoutingTimeWindow{StartTime: "18:00", EndTime: "08:00", Timezone: "Mars/Olympus"},
+			},
+			Action: models.RoutingAction{Type: models.RoutingActionSend},
+		},
+		{Action: models.RoutingAction{Type: models.RoutingActionDrop, OutputIDs: []string{routingOutputID}}},
+		{Action: models.RoutingAction{Type: models.RoutingActionDelay}},
+		{Action: models.RoutingAction{Type: models.RoutingActionDelay, DelayMinutes: 5, Continue: true}},
+		{Action: models.RoutingAction{Type: models.RoutingActionSend, Severity: "HIGH"}},
+	} {
+		assert.Error(t, validateRoutingRule(input), input)
+	}
+
+	assert.NoError(t, validateRoutingRule(&models.PutRoutingRuleInput{
+		Match: models.RoutingMatch{
+			TimeWindow: &models.RoutingTimeWindow{StartTime: "18:00", EndTime: "08:00", Timezone: "Europe/Paris"},
+		},
+		Action: models.RoutingAction{Type: models.RoutingActionEscalate, Severity: "CRITICAL"},
+	}))
+}
@@ -62,6 +62,20 @@ func (API) UpdateOutput(input *models.UpdateOutputInput) (*models.UpdateOutputOu
 		if err != nil {
 			return nil, err
 		}
+		if err = validateOutputTemplates(newConfig); err != nil {
+			return nil, &genericapi.InvalidInputError{Message: err.Error()}
+		}
+	}
+
+	if input.DeliveryPolicy != nil {
+		existingOutput, err = outputsTable.GetOutput(input.OutputID)
+		if err != nil {
+			return nil, &genericapi.DoesNotExistError{
+				Message: "A destination with the ID " + *input.OutputID + " does not exist."}
+		}
+		if err = validateDeliveryPolicy(input.DeliveryPolicy, existingOutput.OutputType); err != nil {
+			return nil, &genericapi.InvalidInputError{Message: err.Error()}
+		}
 	}
 
 	alertOutput := &models.AlertOutput{
@@ -72,6 +86,7 @@ func (API) UpdateOutput(input *models.UpdateOutputInput) (*models.UpdateOutputOu
 		OutputConfig:       newConfig,
 		DefaultForSeverity: input.DefaultForSeverity,
 		AlertTypes:         input.AlertTypes,
+		DeliveryPolicy:     input.DeliveryPolicy,
 	}
 
 	alertOutputItem, err := AlertOutputToItem(alertOutput)
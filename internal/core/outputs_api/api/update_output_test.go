@@ -130,3 +130,49 @@ func TestUpdateSameOutputOutput(t *testing.T) {
 
 	mockOutputsTable.AssertExpectations(t)
 }
+
+func TestMergeConfigs(t *testing.T) {
+	oldConfig := &models.OutputConfig{Email: &models.EmailConfig{
+		Transport:    "SMTP",
+		FromAddress:  "panther@example.com",
+		SMTPHost:     "smtp.example.com",
+		SMTPUsername: "user",
+		SMTPPassword: "password",
+	}}
+	// The password was redacted, the username is cleared
+	newConfig := &models.OutputConfig{Email: &models.EmailConfig{
+		Transport:   "SMTP",
+		FromAddress: "alerts@example.com",
+		SMTPHost:    "smtp.example.com",
+	}}
+
+	result, err := mergeConfigs(oldConfig, newConfig)
+	require.NoError(t, err)
+	assert.Equal(t, &models.EmailConfig{
+		Transport:    "SMTP",
+		FromAddress:  "alerts@example.com",
+		SMTPHost:     "smtp.example.com",
+		SMTPPassword: "password",
+	}, result.Email)
+}
+
+func TestMergeConfigsHeaders(t *testing.T) {
+	oldConfig := &models.OutputConfig{CustomWebhook: &models.CustomWebhookConfig{
+		WebhookURL: "https://example.com",
+		Headers:    map[string]string{"X-Api-Key": "secret", "X-Tenant": "acme"},
+	}}
+	redacted := *oldConfig.CustomWebhook
+	redactOutput(&models.OutputConfig{CustomWebhook: &redacted})
+	assert.Equal(t, map[string]string{"X-Api-Key": "", "X-Tenant": ""}, redacted.Headers)
+
+	// The redacted API key is kept, the tenant header is removed and a new one is added
+	newConfig := &models.OutputConfig{CustomWebhook: &models.CustomWebhookConfig{
+		Headers: map[string]string{"X-Api-Key": "", "X-Region": "eu"},
+	}}
+	result, err := mergeConfigs(oldConfig, newConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com", result.CustomWebhook.WebhookURL)
+	assert.Equal(t, map[string]string{"X-Api-Key": "secret", "X-Region": "eu"}, result.CustomWebhook.Headers)
+	// The stored config is not redacted
+	assert.Equal(t, "secret", oldConfig.CustomWebhook.Headers["X-Api-Key"])
+}
@@ -45,6 +45,7 @@ func AlertOutputToItem(input *models.AlertOutput) (*table.AlertOutputItem, error
 		OutputType:         input.OutputType,
 		DefaultForSeverity: input.DefaultForSeverity,
 		AlertTypes:         input.AlertTypes,
+		DeliveryPolicy:     input.DeliveryPolicy,
 	}
 
 	if input.OutputConfig != nil {
@@ -70,6 +71,7 @@ func ItemToAlertOutput(input *table.AlertOutputItem) (alertOutput *models.AlertO
 		OutputType:         input.OutputType,
 		DefaultForSeverity: input.DefaultForSeverity,
 		AlertTypes:         input.AlertTypes,
+		DeliveryPolicy:     input.DeliveryPolicy,
 	}
 
 	// Decrypt the output before returning to the caller
@@ -83,29 +85,16 @@ func ItemToAlertOutput(input *table.AlertOutputItem) (alertOutput *models.AlertO
 }
 
 func redactOutput(outputConfig *models.OutputConfig) {
-	if outputConfig.Slack != nil {
-		outputConfig.Slack.WebhookURL = redacted
-	}
-	if outputConfig.PagerDuty != nil {
-		outputConfig.PagerDuty.IntegrationKey = redacted
-	}
-	if outputConfig.Github != nil {
-		outputConfig.Github.Token = redacted
-	}
-	if outputConfig.Jira != nil {
-		outputConfig.Jira.APIKey = redacted
-	}
-	if outputConfig.Opsgenie != nil {
-		outputConfig.Opsgenie.APIKey = redacted
-	}
-	if outputConfig.MsTeams != nil {
-		outputConfig.MsTeams.WebhookURL = redacted
-	}
-	if outputConfig.Asana != nil {
-		outputConfig.Asana.PersonalAccessToken = redacted
+	for _, field := range outputConfig.SecretFields() {
+		*field = redacted
 	}
 	if outputConfig.CustomWebhook != nil {
-		outputConfig.CustomWebhook.WebhookURL = redacted
+		// Headers often carry API keys, only their names are returned
+		headers := make(map[string]string, len(outputConfig.CustomWebhook.Headers))
+		for name := range outputConfig.CustomWebhook.Headers {
+			headers[name] = redacted
+		}
+		outputConfig.CustomWebhook.Headers = headers
 	}
 }
 
@@ -156,6 +145,24 @@ func getOutputType(outputConfig *models.OutputConfig) (*string, error) {
 	if outputConfig.CustomWebhook != nil {
 		return aws.String("customwebhook"), nil
 	}
+	if outputConfig.Email != nil {
+		return aws.String("email"), nil
+	}
+	if outputConfig.ServiceNow != nil {
+		return aws.String("servicenow"), nil
+	}
+	if outputConfig.Zendesk != nil {
+		return aws.String("zendesk"), nil
+	}
+	if outputConfig.Splunk != nil {
+		return aws.String("splunk"), nil
+	}
+	if outputConfig.Elasticsearch != nil {
+		return aws.String("elasticsearch"), nil
+	}
+	if outputConfig.Datadog != nil {
+		return aws.String("datadog"), nil
+	}
 
 	return nil, errors.New("no valid output configuration specified for alert output")
 }
@@ -163,6 +170,8 @@ func getOutputType(outputConfig *models.OutputConfig) (*string, error) {
 // mergeConfigs combines an old config with a new config based on the following rules:
 // 1. For every value in the new config, use it
 // 2. For every value in the old config, keep it if it is not overwritten by the new config
+// 3. An empty value keeps a redacted secret, since the caller never saw it. Other values can be cleared.
+// 4. Maps replace the old map, but an empty value in them keeps the old value of the key
 func mergeConfigs(oldConfig, newConfig *models.OutputConfig) (*models.OutputConfig, error) {
 	// Convert the old config into bytes so we can merge it with the new config
 	oldBytes, err := jsoniter.Marshal(oldConfig)
@@ -180,6 +189,28 @@ func mergeConfigs(oldConfig, newConfig *models.OutputConfig) (*models.OutputConf
 		}
 	}
 
+	// The config as the caller received it
+	redactedConfig := &models.OutputConfig{}
+	if err = jsoniter.Unmarshal(oldBytes, redactedConfig); err != nil {
+		return nil, &genericapi.InternalError{
+			Message: "Unable to process existing configuration from dynamo",
+		}
+	}
+	redactOutput(redactedConfig)
+	redactedBytes, err := jsoniter.Marshal(redactedConfig)
+	if err != nil {
+		return nil, &genericapi.InternalError{
+			Message: "Unable to extract existing configuration from dynamo",
+		}
+	}
+	var redactedMap map[string]map[string]interface{}
+	err = jsoniter.Unmarshal(redactedBytes, &redactedMap)
+	if err != nil {
+		return nil, &genericapi.InternalError{
+			Message: "Unable to process existing configuration from dynamo",
+		}
+	}
+
 	// Repeat for the new config
 	newBytes, err := jsoniter.Marshal(newConfig)
 	if err != nil {
@@ -198,9 +229,17 @@ func mergeConfigs(oldConfig, newConfig *models.OutputConfig) (*models.OutputConf
 	// Overwrite the existing configurations with the new configurations
 	for configType, configMap := range newMap {
 		for configKey, configValue := range configMap {
-			if configValue == "" {
+			if configValue == "" && redactedMap[configType][configKey] == redacted {
 				continue
 			}
+			if newValues, ok := configValue.(map[string]interface{}); ok {
+				oldValues, _ := oldMap[configType][configKey].(map[string]interface{})
+				for key, value := range newValues {
+					if oldValue, ok := oldValues[key]; ok && value == redacted {
+						newValues[key] = oldValue
+					}
+				}
+			}
 			oldMap[configType][configKey] = configValue
 		}
 	}
@@ -265,10 +304,73 @@ func validateConfigByType(config *models.OutputConfig, outputType *string) error
 			return nil
 		}
 	case "customwebhook":
-		if config.CustomWebhook.WebhookURL != "" {
+		webhook := config.CustomWebhook
+		if webhook.WebhookURL != "" && (webhook.AuthType == "" || webhook.AuthSecret != "") {
+			return nil
+		}
+	case "email":
+		email := config.Email
+		if email.FromAddress != "" && len(email.Recipients) != 0 && (email.Transport != "SMTP" || email.SMTPHost != "") {
+			return nil
+		}
+	case "servicenow":
+		if config.ServiceNow.InstanceURL != "" && config.ServiceNow.UserName != "" && config.ServiceNow.Password != "" {
+			return nil
+		}
+	case "zendesk":
+		if config.Zendesk.OrgDomain != "" && config.Zendesk.UserEmail != "" && config.Zendesk.APIToken != "" {
+			return nil
+		}
+	case "splunk":
+		if config.Splunk.HecURL != "" && config.Splunk.Token != "" {
+			return nil
+		}
+	case "elasticsearch":
+		if config.Elasticsearch.Endpoint != "" && config.Elasticsearch.Index != "" {
+			return nil
+		}
+	case "datadog":
+		if config.Datadog.APIKey != "" {
 			return nil
 		}
 	}
 
 	return errors.New("invalid output configuration specified for alert output, missing required fields")
 }
+
+// validateOutputTemplates checks that the payload templates of an output can be parsed
+func validateOutputTemplates(config *models.OutputConfig) error {
+	var templates []string
+	if config.Slack != nil {
+		templates = append(templates, config.Slack.MessageTemplate)
+	}
+	if config.MsTeams != nil {
+		templates = append(templates, config.MsTeams.MessageTemplate)
+	}
+	if config.CustomWebhook != nil {
+		templates = append(templates, config.CustomWebhook.PayloadTemplate)
+	}
+
+	for _, text := range templates {
+		if text == "" {
+			continue
+		}
+		if _, err := outputs.ParseTemplate(text); err != nil {
+			return errors.New("invalid payload template: " + err.Error())
+		}
+	}
+	return nil
+}
+
+// validateDeliveryPolicy checks that the output type supports the delivery policy
+func validateDeliveryPolicy(policy *models.DeliveryPolicy, outputType *string) error {
+	if policy == nil || policy.BatchWindowMinutes == 0 {
+		return nil
+	}
+	switch *outputType {
+	case "slack", "msteams":
+		return nil
+	default:
+		return errors.New("batching is only supported by Slack and Microsoft Teams outputs")
+	}
+}
@@ -20,6 +20,7 @@ package main
 
 import (
 	"context"
+	"os"
 
 	"github.com/aws/aws-lambda-go/lambda"
 
@@ -33,7 +34,7 @@ import (
 var router *genericapi.Router
 
 func init() {
-	validator, err := validator.Validator()
+	validator, err := validator.Validator(os.Getenv("ACCOUNT_ID"), os.Getenv("AWS_REGION"))
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,71 @@
+package table
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws/session"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+// EscalationPoliciesAPI defines the interface for the escalation policies table which can be used for mocking.
+type EscalationPoliciesAPI interface {
+	GetEscalationPolicies() ([]*models.EscalationPolicy, error)
+	GetEscalationPolicy(policyID string) (*models.EscalationPolicy, error)
+	PutEscalationPolicy(*models.EscalationPolicy) error
+	DeleteEscalationPolicy(policyID string) error
+}
+
+// EscalationPoliciesTable encapsulates a connection to the Dynamo escalation policies table.
+type EscalationPoliciesTable struct {
+	keyedItemTable
+}
+
+// NewEscalationPolicies creates an AWS client to interface with the escalation policies table.
+func NewEscalationPolicies(name string, sess *session.Session) *EscalationPoliciesTable {
+	return &EscalationPoliciesTable{newKeyedItemTable(name, "policyId", "an EscalationPolicy", sess)}
+}
+
+// GetEscalationPolicies returns all escalation policies, in no particular order
+func (table *EscalationPoliciesTable) GetEscalationPolicies() ([]*models.EscalationPolicy, error) {
+	var policies []*models.EscalationPolicy
+	if err := table.scanItems(&policies); err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// GetEscalationPolicy returns an escalation policy, or nil if it doesn't exist
+func (table *EscalationPoliciesTable) GetEscalationPolicy(policyID string) (*models.EscalationPolicy, error) {
+	var policy models.EscalationPolicy
+	if found, err := table.getItem(policyID, &policy); err != nil || !found {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// PutEscalationPolicy creates or replaces an escalation policy.
+func (table *EscalationPoliciesTable) PutEscalationPolicy(policy *models.EscalationPolicy) error {
+	return table.putItem(policy)
+}
+
+// DeleteEscalationPolicy removes an escalation policy from the table.
+func (table *EscalationPoliciesTable) DeleteEscalationPolicy(policyID string) error {
+	return table.deleteItem(policyID)
+}
@@ -0,0 +1,74 @@
+package table
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// OutputHealthAPI defines the interface for the output health table which can be used for mocking.
+//
+// The table is written by alert delivery, the outputs API only reads it.
+type OutputHealthAPI interface {
+	GetOutputsHealth() ([]*models.OutputHealth, error)
+}
+
+// OutputHealthTable encapsulates a connection to the Dynamo output health table.
+type OutputHealthTable struct {
+	Name   *string
+	client dynamodbiface.DynamoDBAPI
+}
+
+// NewOutputHealth creates an AWS client to interface with the output health table.
+func NewOutputHealth(name string, sess *session.Session) *OutputHealthTable {
+	return &OutputHealthTable{
+		Name:   aws.String(name),
+		client: dynamodb.New(sess),
+	}
+}
+
+// GetOutputsHealth returns the health of every output which had a delivery recorded, in no particular order
+func (table *OutputHealthTable) GetOutputsHealth() ([]*models.OutputHealth, error) {
+	var health []*models.OutputHealth
+	var unmarshalErr error
+	scanInput := &dynamodb.ScanInput{TableName: table.Name}
+	err := table.client.ScanPages(scanInput, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		var partial []*models.OutputHealth
+		if unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &partial); unmarshalErr != nil {
+			return false
+		}
+		health = append(health, partial...)
+		return true
+	})
+	if err != nil {
+		return nil, &genericapi.AWSError{Method: "dynamodb.Scan", Err: err}
+	}
+	if unmarshalErr != nil {
+		return nil, &genericapi.InternalError{
+			Message: "failed to unmarshal dynamo item to an OutputHealth: " + unmarshalErr.Error()}
+	}
+	return health, nil
+}
@@ -0,0 +1,121 @@
+package table
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// keyedItemTable stores one kind of item, keyed by a single string attribute.
+//
+// The routing rules and maintenance windows tables are built on it, they only add the item type.
+type keyedItemTable struct {
+	Name   *string
+	client dynamodbiface.DynamoDBAPI
+	// The partition key attribute, e.g. "ruleId"
+	keyName string
+	// The item type used in error messages, e.g. "a RoutingRule"
+	itemType string
+}
+
+func newKeyedItemTable(name, keyName, itemType string, sess *session.Session) keyedItemTable {
+	return keyedItemTable{
+		Name:     aws.String(name),
+		client:   dynamodb.New(sess),
+		keyName:  keyName,
+		itemType: itemType,
+	}
+}
+
+// scanItems unmarshals all the items, in no particular order, into a pointer to a slice
+func (table *keyedItemTable) scanItems(items interface{}) error {
+	var all []DynamoItem
+	scanInput := &dynamodb.ScanInput{TableName: table.Name}
+	err := table.client.ScanPages(scanInput, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		all = append(all, page.Items...)
+		return true
+	})
+	if err != nil {
+		return &genericapi.AWSError{Method: "dynamodb.Scan", Err: err}
+	}
+	if err = dynamodbattribute.UnmarshalListOfMaps(all, items); err != nil {
+		return &genericapi.InternalError{
+			Message: "failed to unmarshal dynamo item to " + table.itemType + ": " + err.Error()}
+	}
+	return nil
+}
+
+// getItem unmarshals the item with the given key, returns false if it doesn't exist
+func (table *keyedItemTable) getItem(key string, item interface{}) (bool, error) {
+	result, err := table.client.GetItem(&dynamodb.GetItemInput{
+		TableName: table.Name,
+		Key:       DynamoItem{table.keyName: {S: aws.String(key)}},
+	})
+	if err != nil {
+		return false, &genericapi.AWSError{Method: "dynamodb.GetItem", Err: err}
+	}
+	if result.Item == nil {
+		return false, nil
+	}
+
+	if err = dynamodbattribute.UnmarshalMap(result.Item, item); err != nil {
+		return false, &genericapi.InternalError{
+			Message: "failed to unmarshal dynamo item to " + table.itemType + ": " + err.Error()}
+	}
+	return true, nil
+}
+
+// putItem creates or replaces an item.
+func (table *keyedItemTable) putItem(item interface{}) error {
+	dynamoItem, err := dynamodbattribute.MarshalMap(item)
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to marshal " + table.itemType + " to a dynamo item: " + err.Error()}
+	}
+
+	if _, err = table.client.PutItem(&dynamodb.PutItemInput{Item: dynamoItem, TableName: table.Name}); err != nil {
+		return &genericapi.AWSError{Method: "dynamodb.PutItem", Err: err}
+	}
+	return nil
+}
+
+// deleteItem removes an item, returns a DoesNotExistError if there is no item with the key.
+func (table *keyedItemTable) deleteItem(key string) error {
+	_, err := table.client.DeleteItem(&dynamodb.DeleteItemInput{
+		TableName:           table.Name,
+		Key:                 DynamoItem{table.keyName: {S: aws.String(key)}},
+		ConditionExpression: aws.String("attribute_exists(" + table.keyName + ")"),
+	})
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return &genericapi.DoesNotExistError{Message: table.keyName + "=" + key + " does not exist"}
+		}
+		return &genericapi.AWSError{Method: "dynamodb.DeleteItem", Err: err}
+	}
+	return nil
+}
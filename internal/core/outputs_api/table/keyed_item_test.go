@@ -0,0 +1,110 @@
+package table
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+type testKeyedItem struct {
+	ItemID   string `json:"itemId"`
+	Severity string `json:"severity"`
+}
+
+func newTestKeyedItemTable(client *mockDynamoDB) *keyedItemTable {
+	return &keyedItemTable{Name: aws.String("TableName"), client: client, keyName: "itemId", itemType: "a TestItem"}
+}
+
+func TestKeyedItemTableScan(t *testing.T) {
+	dynamoDBClient := &mockDynamoDB{}
+	table := newTestKeyedItemTable(dynamoDBClient)
+	dynamoDBClient.On("ScanPages", &dynamodb.ScanInput{TableName: aws.String("TableName")}, mock.Anything).Return(nil).Once()
+
+	var items []*testKeyedItem
+	require.NoError(t, table.scanItems(&items))
+	assert.Equal(t, []*testKeyedItem{{Severity: "INFO"}}, items)
+	dynamoDBClient.AssertExpectations(t)
+}
+
+func TestKeyedItemTableGet(t *testing.T) {
+	dynamoDBClient := &mockDynamoDB{}
+	table := newTestKeyedItemTable(dynamoDBClient)
+	dynamoDBClient.On("GetItem", &dynamodb.GetItemInput{
+		TableName: aws.String("TableName"),
+		Key:       DynamoItem{"itemId": {S: aws.String("item-id")}},
+	}).Return(&dynamodb.GetItemOutput{
+		Item: DynamoItem{"itemId": {S: aws.String("item-id")}, "severity": {S: aws.String("HIGH")}},
+	}, nil).Once()
+	dynamoDBClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil).Once()
+	dynamoDBClient.On("GetItem", mock.Anything).Return((*dynamodb.GetItemOutput)(nil), errors.New("throttled")).Once()
+
+	var item testKeyedItem
+	found, err := table.getItem("item-id", &item)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, testKeyedItem{ItemID: "item-id", Severity: "HIGH"}, item)
+
+	found, err = table.getItem("missing-id", &item)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	_, err = table.getItem("item-id", &item)
+	assert.IsType(t, &genericapi.AWSError{}, err)
+	dynamoDBClient.AssertExpectations(t)
+}
+
+func TestKeyedItemTablePut(t *testing.T) {
+	dynamoDBClient := &mockDynamoDB{}
+	table := newTestKeyedItemTable(dynamoDBClient)
+	dynamoDBClient.On("PutItem", &dynamodb.PutItemInput{
+		TableName: aws.String("TableName"),
+		Item:      DynamoItem{"itemId": {S: aws.String("item-id")}, "severity": {S: aws.String("LOW")}},
+	}).Return(&dynamodb.PutItemOutput{}, nil).Once()
+
+	require.NoError(t, table.putItem(&testKeyedItem{ItemID: "item-id", Severity: "LOW"}))
+	dynamoDBClient.AssertExpectations(t)
+}
+
+func TestKeyedItemTableDelete(t *testing.T) {
+	dynamoDBClient := &mockDynamoDB{}
+	table := newTestKeyedItemTable(dynamoDBClient)
+	dynamoDBClient.On("DeleteItem", &dynamodb.DeleteItemInput{
+		TableName:           aws.String("TableName"),
+		Key:                 DynamoItem{"itemId": {S: aws.String("item-id")}},
+		ConditionExpression: aws.String("attribute_exists(itemId)"),
+	}).Return(&dynamodb.DeleteItemOutput{}, nil).Once()
+	dynamoDBClient.On("DeleteItem", mock.Anything).Return(
+		(*dynamodb.DeleteItemOutput)(nil),
+		awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "attribute does not exist", nil)).Once()
+
+	require.NoError(t, table.deleteItem("item-id"))
+	err := table.deleteItem("missing-id")
+	assert.Equal(t, &genericapi.DoesNotExistError{Message: "itemId=missing-id does not exist"}, err)
+	dynamoDBClient.AssertExpectations(t)
+}
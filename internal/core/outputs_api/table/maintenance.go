@@ -0,0 +1,71 @@
+package table
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws/session"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+// MaintenanceWindowsAPI defines the interface for the maintenance windows table which can be used for mocking.
+type MaintenanceWindowsAPI interface {
+	GetMaintenanceWindows() ([]*models.MaintenanceWindow, error)
+	GetMaintenanceWindow(windowID string) (*models.MaintenanceWindow, error)
+	PutMaintenanceWindow(*models.MaintenanceWindow) error
+	DeleteMaintenanceWindow(windowID string) error
+}
+
+// MaintenanceWindowsTable encapsulates a connection to the Dynamo maintenance windows table.
+type MaintenanceWindowsTable struct {
+	keyedItemTable
+}
+
+// NewMaintenanceWindows creates an AWS client to interface with the maintenance windows table.
+func NewMaintenanceWindows(name string, sess *session.Session) *MaintenanceWindowsTable {
+	return &MaintenanceWindowsTable{newKeyedItemTable(name, "windowId", "a MaintenanceWindow", sess)}
+}
+
+// GetMaintenanceWindows returns all maintenance windows, in no particular order
+func (table *MaintenanceWindowsTable) GetMaintenanceWindows() ([]*models.MaintenanceWindow, error) {
+	var windows []*models.MaintenanceWindow
+	if err := table.scanItems(&windows); err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
+
+// GetMaintenanceWindow returns a maintenance window, or nil if it doesn't exist
+func (table *MaintenanceWindowsTable) GetMaintenanceWindow(windowID string) (*models.MaintenanceWindow, error) {
+	var window models.MaintenanceWindow
+	if found, err := table.getItem(windowID, &window); err != nil || !found {
+		return nil, err
+	}
+	return &window, nil
+}
+
+// PutMaintenanceWindow creates or replaces a maintenance window.
+func (table *MaintenanceWindowsTable) PutMaintenanceWindow(window *models.MaintenanceWindow) error {
+	return table.putItem(window)
+}
+
+// DeleteMaintenanceWindow removes a maintenance window from the table.
+func (table *MaintenanceWindowsTable) DeleteMaintenanceWindow(windowID string) error {
+	return table.deleteItem(windowID)
+}
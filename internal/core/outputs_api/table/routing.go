@@ -0,0 +1,71 @@
+package table
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws/session"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+// RoutingRulesAPI defines the interface for the routing rules table which can be used for mocking.
+type RoutingRulesAPI interface {
+	GetRoutingRules() ([]*models.RoutingRule, error)
+	GetRoutingRule(ruleID string) (*models.RoutingRule, error)
+	PutRoutingRule(*models.RoutingRule) error
+	DeleteRoutingRule(ruleID string) error
+}
+
+// RoutingRulesTable encapsulates a connection to the Dynamo routing rules table.
+type RoutingRulesTable struct {
+	keyedItemTable
+}
+
+// NewRoutingRules creates an AWS client to interface with the routing rules table.
+func NewRoutingRules(name string, sess *session.Session) *RoutingRulesTable {
+	return &RoutingRulesTable{newKeyedItemTable(name, "ruleId", "a RoutingRule", sess)}
+}
+
+// GetRoutingRules returns all routing rules, in no particular order
+func (table *RoutingRulesTable) GetRoutingRules() ([]*models.RoutingRule, error) {
+	var rules []*models.RoutingRule
+	if err := table.scanItems(&rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// GetRoutingRule returns a routing rule, or nil if it doesn't exist
+func (table *RoutingRulesTable) GetRoutingRule(ruleID string) (*models.RoutingRule, error) {
+	var rule models.RoutingRule
+	if found, err := table.getItem(ruleID, &rule); err != nil || !found {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// PutRoutingRule creates or replaces a routing rule.
+func (table *RoutingRulesTable) PutRoutingRule(rule *models.RoutingRule) error {
+	return table.putItem(rule)
+}
+
+// DeleteRoutingRule removes a routing rule from the table.
+func (table *RoutingRulesTable) DeleteRoutingRule(ruleID string) error {
+	return table.deleteItem(ruleID)
+}
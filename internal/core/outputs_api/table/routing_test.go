@@ -0,0 +1,60 @@
+package table
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+func TestGetRoutingRule(t *testing.T) {
+	dynamoDBClient := &mockDynamoDB{}
+	table := &RoutingRulesTable{keyedItemTable{client: dynamoDBClient, Name: aws.String("TableName"), keyName: "ruleId"}}
+
+	dynamoDBClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{
+		Item: DynamoItem{
+			"ruleId":      {S: aws.String("rule-id")},
+			"displayName": {S: aws.String("Drop tests")},
+			"order":       {N: aws.String("5")},
+			"action":      {M: DynamoItem{"type": {S: aws.String("DROP")}}},
+		},
+	}, nil).Once()
+	dynamoDBClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil).Once()
+
+	rule, err := table.GetRoutingRule("rule-id")
+	require.NoError(t, err)
+	assert.Equal(t, &models.RoutingRule{
+		RuleID:      "rule-id",
+		DisplayName: "Drop tests",
+		Order:       5,
+		Action:      models.RoutingAction{Type: models.RoutingActionDrop},
+	}, rule)
+
+	rule, err = table.GetRoutingRule("missing-id")
+	require.NoError(t, err)
+	assert.Nil(t, rule)
+	dynamoDBClient.AssertExpectations(t)
+}
@@ -0,0 +1,71 @@
+package table
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws/session"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+// OnCallSchedulesAPI defines the interface for the on-call schedules table which can be used for mocking.
+type OnCallSchedulesAPI interface {
+	GetOnCallSchedules() ([]*models.OnCallSchedule, error)
+	GetOnCallSchedule(scheduleID string) (*models.OnCallSchedule, error)
+	PutOnCallSchedule(*models.OnCallSchedule) error
+	DeleteOnCallSchedule(scheduleID string) error
+}
+
+// OnCallSchedulesTable encapsulates a connection to the Dynamo on-call schedules table.
+type OnCallSchedulesTable struct {
+	keyedItemTable
+}
+
+// NewOnCallSchedules creates an AWS client to interface with the on-call schedules table.
+func NewOnCallSchedules(name string, sess *session.Session) *OnCallSchedulesTable {
+	return &OnCallSchedulesTable{newKeyedItemTable(name, "scheduleId", "an OnCallSchedule", sess)}
+}
+
+// GetOnCallSchedules returns all on-call schedules, in no particular order
+func (table *OnCallSchedulesTable) GetOnCallSchedules() ([]*models.OnCallSchedule, error) {
+	var schedules []*models.OnCallSchedule
+	if err := table.scanItems(&schedules); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// GetOnCallSchedule returns an on-call schedule, or nil if it doesn't exist
+func (table *OnCallSchedulesTable) GetOnCallSchedule(scheduleID string) (*models.OnCallSchedule, error) {
+	var schedule models.OnCallSchedule
+	if found, err := table.getItem(scheduleID, &schedule); err != nil || !found {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// PutOnCallSchedule creates or replaces an on-call schedule.
+func (table *OnCallSchedulesTable) PutOnCallSchedule(schedule *models.OnCallSchedule) error {
+	return table.putItem(schedule)
+}
+
+// DeleteOnCallSchedule removes an on-call schedule from the table.
+func (table *OnCallSchedulesTable) DeleteOnCallSchedule(scheduleID string) error {
+	return table.deleteItem(scheduleID)
+}
@@ -47,6 +47,9 @@ func (table *OutputsTable) UpdateOutput(alertOutput *AlertOutputItem) (*AlertOut
 	if alertOutput.AlertTypes != nil {
 		updateExpression.Set(expression.Name("alertTypes"), expression.Value(alertOutput.AlertTypes))
 	}
+	if alertOutput.DeliveryPolicy != nil {
+		updateExpression.Set(expression.Name("deliveryPolicy"), expression.Value(alertOutput.DeliveryPolicy))
+	}
 
 	conditionExpression := expression.Name("outputId").Equal(expression.Value(alertOutput.OutputID))
 	combinedExpression, err := expression.NewBuilder().
@@ -21,14 +21,33 @@ package validator
 import (
 	"github.com/aws/aws-sdk-go/aws/arn"
 	"gopkg.in/go-playground/validator.v9"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
 )
 
 // Validator builds a custom struct validator.
-func Validator() (*validator.Validate, error) {
+//
+// Secret references are accepted if they point to the given account and region.
+func Validator(accountID, region string) (*validator.Validate, error) {
 	result := validator.New()
 	if err := result.RegisterValidation("snsArn", validateAwsArn); err != nil {
 		return nil, err
 	}
+	validateSecretReference := func(fl validator.FieldLevel) bool {
+		return models.IsSecretReference(fl.Field().String(), accountID, region)
+	}
+	if err := result.RegisterValidation("secretRef", validateSecretReference); err != nil {
+		return nil, err
+	}
+	// A secret Panther can't read is rejected even if the field would accept the ARN as a value
+	result.RegisterStructValidation(func(sl validator.StructLevel) {
+		config := sl.Current().Interface().(models.OutputConfig)
+		for _, field := range config.SecretFields() {
+			if isSecretArn(*field) && !models.IsSecretReference(*field, accountID, region) {
+				sl.ReportError(*field, "SecretReference", "SecretReference", "secretRef", "")
+			}
+		}
+	}, models.OutputConfig{})
 	return result, nil
 }
 
@@ -36,3 +55,9 @@ func validateAwsArn(fl validator.FieldLevel) bool {
 	fieldArn, err := arn.Parse(fl.Field().String())
 	return err == nil && fieldArn.Service == "sns"
 }
+
+// isSecretArn returns true if a value is the ARN of a Secrets Manager or SSM Parameter Store resource
+func isSecretArn(value string) bool {
+	valueArn, err := arn.Parse(value)
+	return err == nil && (valueArn.Service == models.SecretsManagerService || valueArn.Service == models.ParameterStoreService)
+}
@@ -38,7 +38,7 @@ func expectedMsg(structName string, fieldName string, tagName string) string {
 }
 
 func TestAddOutputNoName(t *testing.T) {
-	validator, err := Validator()
+	validator, err := Validator("123456789012", "us-west-2")
 	require.NoError(t, err)
 	err = validator.Struct(&models.AddOutputInput{
 		UserID:       aws.String("3601990c-b566-404b-b367-3c6eacd6fe60"),
@@ -51,7 +51,7 @@ func TestAddOutputNoName(t *testing.T) {
 }
 
 func TestAddOutputValid(t *testing.T) {
-	validator, err := Validator()
+	validator, err := Validator("123456789012", "us-west-2")
 	require.NoError(t, err)
 	assert.NoError(t, validator.Struct(&models.AddOutputInput{
 		UserID:      aws.String("3601990c-b566-404b-b367-3c6eacd6fe60"),
@@ -64,7 +64,7 @@ func TestAddOutputValid(t *testing.T) {
 }
 
 func TestAddInvalidArn(t *testing.T) {
-	validator, err := Validator()
+	validator, err := Validator("123456789012", "us-west-2")
 	require.NoError(t, err)
 	err = validator.Struct(&models.AddOutputInput{
 		UserID:      aws.String("3601990c-b566-404b-b367-3c6eacd6fe60"),
@@ -79,7 +79,7 @@ func TestAddInvalidArn(t *testing.T) {
 }
 
 func TestAddNonSnsArn(t *testing.T) {
-	validator, err := Validator()
+	validator, err := Validator("123456789012", "us-west-2")
 	require.NoError(t, err)
 	err = validator.Struct(&models.AddOutputInput{
 		UserID:      aws.String("3601990c-b566-404b-b367-3c6eacd6fe60"),
@@ -92,3 +92,62 @@ func TestAddNonSnsArn(t *testing.T) {
 	require.Error(t, err)
 	assert.Equal(t, expectedMsg("AddOutputInput.OutputConfig.Sns", "TopicArn", "snsArn"), err.Error())
 }
+
+func TestAddSecretReference(t *testing.T) {
+	validator, err := Validator("123456789012", "us-west-2")
+	require.NoError(t, err)
+	assert.NoError(t, validator.Struct(&models.AddOutputInput{
+		UserID:      aws.String("3601990c-b566-404b-b367-3c6eacd6fe60"),
+		DisplayName: aws.String("mypagerduty"),
+		AlertTypes:  []string{deliverymodel.RuleType},
+		OutputConfig: &models.OutputConfig{
+			PagerDuty: &models.PagerDutyConfig{
+				IntegrationKey: "arn:aws:secretsmanager:us-west-2:123456789012:secret:panther/pagerduty-AbCdEf#integrationKey",
+			},
+		},
+	}))
+	assert.NoError(t, validator.Struct(&models.AddOutputInput{
+		UserID:      aws.String("3601990c-b566-404b-b367-3c6eacd6fe60"),
+		DisplayName: aws.String("mychannel"),
+		AlertTypes:  []string{deliverymodel.RuleType},
+		OutputConfig: &models.OutputConfig{
+			Slack: &models.SlackConfig{WebhookURL: "arn:aws:ssm:us-west-2:123456789012:parameter/panther/slack-webhook"},
+		},
+	}))
+
+	// Only secrets of the account and region Panther is deployed in can be read
+	for _, config := range []*models.OutputConfig{
+		{Slack: &models.SlackConfig{WebhookURL: "arn:aws:ssm:us-west-2:210987654321:parameter/panther/slack-webhook"}},
+		{Jira: &models.JiraConfig{
+			OrgDomain:  "https://example.atlassian.net",
+			ProjectKey: "SEC",
+			UserName:   "panther",
+			APIKey:     "arn:aws:secretsmanager:eu-west-1:123456789012:secret:panther/jira-AbCdEf",
+			Labels:     []string{"panther"},
+		}},
+	} {
+		err = validator.Struct(&models.AddOutputInput{
+			UserID:       aws.String("3601990c-b566-404b-b367-3c6eacd6fe60"),
+			DisplayName:  aws.String("myoutput"),
+			AlertTypes:   []string{deliverymodel.RuleType},
+			OutputConfig: config,
+		})
+		require.Error(t, err)
+		assert.Equal(t, expectedMsg("AddOutputInput.OutputConfig", "SecretReference", "secretRef"), err.Error())
+	}
+}
+
+func TestAddInvalidWebhookURL(t *testing.T) {
+	validator, err := Validator("123456789012", "us-west-2")
+	require.NoError(t, err)
+	err = validator.Struct(&models.AddOutputInput{
+		UserID:      aws.String("3601990c-b566-404b-b367-3c6eacd6fe60"),
+		DisplayName: aws.String("mychannel"),
+		AlertTypes:  []string{deliverymodel.RuleType},
+		OutputConfig: &models.OutputConfig{
+			Slack: &models.SlackConfig{WebhookURL: "hooks.slack.com"},
+		},
+	})
+	require.Error(t, err)
+	assert.Equal(t, expectedMsg("AddOutputInput.OutputConfig.Slack", "WebhookURL", "secretRef|url"), err.Error())
+}
@@ -22,6 +22,7 @@ import (
 	"crypto/md5" // nolint(gosec)
 	"encoding/hex"
 	"strconv"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
@@ -49,6 +50,7 @@ type Handler struct {
 	Cache            RuleCache
 	DdbClient        dynamodbiface.DynamoDBAPI
 	AlertTable       string
+	AlertDedupTable  string
 	AlertingQueueURL string
 	MetricsLogger    metrics.Logger
 }
@@ -70,6 +72,9 @@ func (h *Handler) Do(oldAlertDedupEvent, newAlertDedupEvent *alertApiModels.Aler
 	if shouldIgnoreChange(newRule, newAlertDedupEvent) {
 		return nil
 	}
+	if newRule.AlertGrouping != nil && newAlertDedupEvent.Type == alertModel.RuleType {
+		return h.handleGroupedAlert(oldRule, newRule, oldAlertDedupEvent, newAlertDedupEvent)
+	}
 	if needToCreateNewAlert(oldRule, oldAlertDedupEvent, newAlertDedupEvent) {
 		return h.handleNewAlert(newRule, newAlertDedupEvent)
 	}
@@ -98,11 +103,17 @@ func needToCreateNewAlert(oldRule *ruleModel.Rule, oldAlertDedupEvent, newAlertD
 }
 
 func (h *Handler) handleNewAlert(rule *ruleModel.Rule, event *alertApiModels.AlertDedupEvent) error {
-	if err := h.storeNewAlert(rule, event); err != nil {
+	return h.createAlert(rule, event, generateAlertID(event), nil, nil)
+}
+
+func (h *Handler) createAlert(rule *ruleModel.Rule, event *alertApiModels.AlertDedupEvent, alertID string, dedupAlertIDs []string,
+	eventsSince *time.Time) error {
+
+	if err := h.storeNewAlert(rule, event, alertID, dedupAlertIDs, eventsSince); err != nil {
 		return errors.Wrap(err, "failed to store new alert in DDB")
 	}
 
-	err := h.sendAlertNotification(rule, event)
+	err := h.sendAlertNotification(rule, event, alertID)
 	if err == nil && event.Type == alertModel.RuleType {
 		h.logStats(rule, event)
 	}
@@ -155,15 +166,19 @@ func (h *Handler) updateExistingAlert(event *alertApiModels.AlertDedupEvent) err
 	return nil
 }
 
-func (h *Handler) storeNewAlert(rule *ruleModel.Rule, alertDedup *alertApiModels.AlertDedupEvent) error {
+func (h *Handler) storeNewAlert(rule *ruleModel.Rule, alertDedup *alertApiModels.AlertDedupEvent,
+	alertID string, dedupAlertIDs []string, eventsSince *time.Time) error {
+
 	alert := &alertApiModels.Alert{
-		ID:                  generateAlertID(alertDedup),
+		ID:                  alertID,
 		TimePartition:       defaultTimePartition,
 		Severity:            aws.String(getSeverity(rule, alertDedup)),
 		RuleDisplayName:     getRuleDisplayName(rule),
 		Title:               getTitle(rule, alertDedup),
 		FirstEventMatchTime: alertDedup.CreationTime,
 		LogTypes:            alertDedup.LogTypes,
+		DedupAlertIDs:       dedupAlertIDs,
+		EventsSince:         eventsSince,
 		AlertDedupEvent: alertApiModels.AlertDedupEvent{
 			RuleID:              alertDedup.RuleID,
 			RuleVersion:         alertDedup.RuleVersion,
@@ -176,6 +191,8 @@ func (h *Handler) storeNewAlert(rule *ruleModel.Rule, alertDedup *alertApiModels
 			EventCount:   alertDedup.EventCount,
 			LogTypes:     alertDedup.LogTypes,
 			Type:         alertDedup.Type,
+			// Scheduled rules and notices about a rule have no events in S3
+			NoStoredEvents: alertDedup.NoStoredEvents,
 			// Generated Fields
 			GeneratedTitle:        aws.String(getTitle(rule, alertDedup)),
 			GeneratedDescription:  aws.String(getDescription(rule, alertDedup)),
@@ -201,9 +218,9 @@ func (h *Handler) storeNewAlert(rule *ruleModel.Rule, alertDedup *alertApiModels
 	return nil
 }
 
-func (h *Handler) sendAlertNotification(rule *ruleModel.Rule, alertDedup *alertApiModels.AlertDedupEvent) error {
+func (h *Handler) sendAlertNotification(rule *ruleModel.Rule, alertDedup *alertApiModels.AlertDedupEvent, alertID string) error {
 	alertNotification := &alertModel.Alert{
-		AlertID:      aws.String(generateAlertID(alertDedup)),
+		AlertID:      &alertID,
 		AnalysisID:   alertDedup.RuleID,
 		AnalysisName: getRuleDisplayName(rule),
 		// In case a rule has a threshold, we want the alert creation time to be the same time
@@ -0,0 +1,253 @@
+package forwarder
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"crypto/md5" // nolint(gosec)
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/pkg/errors"
+
+	ruleModel "github.com/panther-labs/panther/api/lambda/analysis/models"
+	alertApiModels "github.com/panther-labs/panther/internal/log_analysis/alerts_api/models"
+)
+
+// The alert an alert dedup entry is currently grouped into, for rules with an alert grouping policy.
+// It is stored in the alert dedup table next to the attributes managed by the rules engines.
+type alertGroup struct {
+	AlertID      string    `dynamodbav:"groupAlertId"`
+	CreationTime time.Time `dynamodbav:"groupCreationTime,unixtime"`
+	UpdateTime   time.Time `dynamodbav:"groupUpdateTime,unixtime"`
+	EventCount   int64     `dynamodbav:"groupEventCount"`
+}
+
+// Applies the alert grouping policy of the rule to decide whether the events are added to the current alert
+// or a new alert is created.
+func (h *Handler) handleGroupedAlert(oldRule, rule *ruleModel.Rule, oldAlertDedupEvent,
+	newAlertDedupEvent *alertApiModels.AlertDedupEvent) error {
+
+	if isGroupUpdate(oldAlertDedupEvent, newAlertDedupEvent) {
+		// Our own write of the group to the dedup table
+		return nil
+	}
+
+	key := dedupTableKey(newAlertDedupEvent)
+	group, err := h.getAlertGroup(key)
+	if err != nil {
+		return err
+	}
+
+	newAlert := needToCreateNewAlert(oldRule, oldAlertDedupEvent, newAlertDedupEvent)
+	newEvents := newAlertDedupEvent.EventCount
+	if !newAlert {
+		newEvents -= oldAlertDedupEvent.EventCount
+	}
+	dedupAlertID := generateAlertID(newAlertDedupEvent)
+	dedupPeriod := time.Duration(rule.DedupPeriodMinutes) * time.Minute
+
+	if continueAlertGroup(rule.AlertGrouping, dedupPeriod, group, newAlert, newAlertDedupEvent) {
+		group.EventCount += newEvents
+		group.UpdateTime = newAlertDedupEvent.UpdateTime
+		if err := h.updateGroupedAlert(group, dedupAlertID, newAlertDedupEvent.LogTypes); err != nil {
+			return err
+		}
+		return h.putAlertGroup(key, group)
+	}
+
+	// The rules engine alert, or the alert it was last split into
+	previousAlertID := dedupAlertID
+	if group != nil {
+		previousAlertID = group.AlertID
+	}
+	group = &alertGroup{
+		AlertID:      dedupAlertID,
+		CreationTime: newAlertDedupEvent.UpdateTime,
+		UpdateTime:   newAlertDedupEvent.UpdateTime,
+		EventCount:   newEvents,
+	}
+	event := *newAlertDedupEvent
+	event.EventCount = newEvents
+	var eventsSince *time.Time
+	if !newAlert {
+		// The rules engine is still adding events to the alert we are splitting, it needs a new id.
+		// Both alerts include the events with the p_alert_id of the rules engine alert, so they are split by time.
+		group.AlertID = splitAlertID(dedupAlertID, newAlertDedupEvent.UpdateTime)
+		event.CreationTime = newAlertDedupEvent.UpdateTime
+		eventsSince = &newAlertDedupEvent.UpdateTime
+		if err := h.endAlertEvents(previousAlertID, newAlertDedupEvent.UpdateTime); err != nil {
+			return err
+		}
+	}
+	if err := h.createAlert(rule, &event, group.AlertID, []string{dedupAlertID}, eventsSince); err != nil {
+		return err
+	}
+	return h.putAlertGroup(key, group)
+}
+
+// Report whether the events should be added to the alert of the group, according to the grouping policy
+func continueAlertGroup(policy *ruleModel.AlertGrouping, dedupPeriod time.Duration, group *alertGroup, newAlert bool,
+	event *alertApiModels.AlertDedupEvent) bool {
+
+	if group == nil {
+		return false
+	}
+	if newAlert {
+		// The dedup period of the rules engine expired. With a sliding window we keep the alert open
+		// as long as the gap between the events is shorter than the period.
+		if policy.Window != ruleModel.AlertGroupingSliding || event.UpdateTime.Sub(group.UpdateTime) > dedupPeriod {
+			return false
+		}
+	}
+	maxAge := time.Duration(policy.MaxAlertAgeMinutes) * time.Minute
+	if maxAge > 0 && event.UpdateTime.Sub(group.CreationTime) > maxAge {
+		return false
+	}
+	if policy.MaxEventsPerAlert > 0 && group.EventCount >= policy.MaxEventsPerAlert {
+		return false
+	}
+	return true
+}
+
+// The forwarder writing the group to the dedup table triggers a change without new events
+func isGroupUpdate(oldAlertDedupEvent, newAlertDedupEvent *alertApiModels.AlertDedupEvent) bool {
+	return oldAlertDedupEvent != nil &&
+		oldAlertDedupEvent.AlertCount == newAlertDedupEvent.AlertCount &&
+		oldAlertDedupEvent.EventCount == newAlertDedupEvent.EventCount &&
+		oldAlertDedupEvent.UpdateTime.Equal(newAlertDedupEvent.UpdateTime)
+}
+
+func (h *Handler) getAlertGroup(key map[string]*dynamodb.AttributeValue) (*alertGroup, error) {
+	output, err := h.DdbClient.GetItem(&dynamodb.GetItemInput{
+		TableName: &h.AlertDedupTable,
+		Key:       key,
+		// We must see the group we wrote while handling the previous change
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get alert group")
+	}
+	var group alertGroup
+	if err := dynamodbattribute.UnmarshalMap(output.Item, &group); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal alert group")
+	}
+	if group.AlertID == "" {
+		return nil, nil
+	}
+	return &group, nil
+}
+
+func (h *Handler) putAlertGroup(key map[string]*dynamodb.AttributeValue, group *alertGroup) error {
+	update := expression.
+		Set(expression.Name("groupAlertId"), expression.Value(group.AlertID)).
+		Set(expression.Name("groupCreationTime"), expression.Value(dynamodbattribute.UnixTime(group.CreationTime))).
+		Set(expression.Name("groupUpdateTime"), expression.Value(dynamodbattribute.UnixTime(group.UpdateTime))).
+		Set(expression.Name("groupEventCount"), expression.Value(group.EventCount))
+	expr, err := expression.NewBuilder().WithUpdate(update).Build()
+	if err != nil {
+		return errors.Wrap(err, "failed to build update expression")
+	}
+	_, err = h.DdbClient.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:                 &h.AlertDedupTable,
+		Key:                       key,
+		UpdateExpression:          expr.Update(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to store alert group")
+	}
+	return nil
+}
+
+func (h *Handler) updateGroupedAlert(group *alertGroup, dedupAlertID string, logTypes []string) error {
+	update := expression.
+		Set(expression.Name(alertApiModels.AlertTableEventCountAttribute), expression.Value(group.EventCount)).
+		Set(expression.Name(alertApiModels.AlertTableUpdateTimeAttribute), expression.Value(group.UpdateTime)).
+		Add(expression.Name(alertApiModels.AlertTableLogTypesAttribute), expression.Value(stringSet(logTypes))).
+		Add(expression.Name(alertApiModels.AlertTableDedupAlertIDsAttribute), expression.Value(stringSet([]string{dedupAlertID})))
+	expr, err := expression.NewBuilder().WithUpdate(update).Build()
+	if err != nil {
+		return errors.Wrap(err, "failed to build update expression")
+	}
+	_, err = h.DdbClient.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:                 &h.AlertTable,
+		UpdateExpression:          expr.Update(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Key: map[string]*dynamodb.AttributeValue{
+			alertApiModels.AlertTablePartitionKey: {S: &group.AlertID},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to update alert")
+	}
+	return nil
+}
+
+// Exclude the events the rules engine adds from the split time from an alert which was split
+func (h *Handler) endAlertEvents(alertID string, splitTime time.Time) error {
+	update := expression.Set(expression.Name(alertApiModels.AlertTableEventsUntilAttribute), expression.Value(splitTime))
+	// Alerts created before the rule had a grouping policy may be missing
+	condition := expression.AttributeExists(expression.Name(alertApiModels.AlertTablePartitionKey))
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
+	if err != nil {
+		return errors.Wrap(err, "failed to build update expression")
+	}
+	_, err = h.DdbClient.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:                 &h.AlertTable,
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Key: map[string]*dynamodb.AttributeValue{
+			alertApiModels.AlertTablePartitionKey: {S: &alertID},
+		},
+	})
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return nil
+		}
+		return errors.Wrap(err, "failed to update split alert")
+	}
+	return nil
+}
+
+func stringSet(values []string) *dynamodb.AttributeValue {
+	return &dynamodb.AttributeValue{SS: aws.StringSlice(values)}
+}
+
+// Same key as the one the rules engines use for the alert dedup table
+func dedupTableKey(event *alertApiModels.AlertDedupEvent) map[string]*dynamodb.AttributeValue {
+	keyHash := md5.Sum([]byte(event.RuleID + ":" + event.DeduplicationString)) // nolint(gosec)
+	return map[string]*dynamodb.AttributeValue{
+		alertApiModels.AlertDedupTablePartitionKey: {S: aws.String(hex.EncodeToString(keyHash[:]))},
+	}
+}
+
+func splitAlertID(dedupAlertID string, splitTime time.Time) string {
+	keyHash := md5.Sum([]byte(dedupAlertID + ":" + strconv.FormatInt(splitTime.UnixNano(), 10))) // nolint(gosec)
+	return hex.EncodeToString(keyHash[:])
+}
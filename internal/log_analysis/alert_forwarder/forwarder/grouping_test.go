@@ -0,0 +1,198 @@
+package forwarder
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	ruleModel "github.com/panther-labs/panther/api/lambda/analysis/models"
+	alertApiModels "github.com/panther-labs/panther/internal/log_analysis/alerts_api/models"
+	"github.com/panther-labs/panther/pkg/gatewayapi"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+func TestContinueAlertGroup(t *testing.T) {
+	t.Parallel()
+	now := time.Now().UTC()
+	group := &alertGroup{AlertID: "alertId", CreationTime: now.Add(-3 * time.Hour), UpdateTime: now.Add(-30 * time.Minute), EventCount: 10}
+	event := &alertApiModels.AlertDedupEvent{UpdateTime: now}
+	fixed := &ruleModel.AlertGrouping{}
+	sliding := &ruleModel.AlertGrouping{Window: ruleModel.AlertGroupingSliding}
+
+	assert.False(t, continueAlertGroup(fixed, time.Hour, nil, false, event))
+	assert.True(t, continueAlertGroup(fixed, time.Hour, group, false, event))
+	// The dedup period expired
+	assert.False(t, continueAlertGroup(fixed, time.Hour, group, true, event))
+	assert.True(t, continueAlertGroup(sliding, time.Hour, group, true, event))
+	assert.False(t, continueAlertGroup(sliding, 15*time.Minute, group, true, event))
+	// Limits apply to both windows
+	assert.False(t, continueAlertGroup(&ruleModel.AlertGrouping{MaxAlertAgeMinutes: 120}, time.Hour, group, false, event))
+	assert.False(t, continueAlertGroup(&ruleModel.AlertGrouping{Window: ruleModel.AlertGroupingSliding, MaxEventsPerAlert: 10},
+		time.Hour, group, true, event))
+	assert.True(t, continueAlertGroup(&ruleModel.AlertGrouping{MaxAlertAgeMinutes: 240, MaxEventsPerAlert: 11},
+		time.Hour, group, false, event))
+}
+
+func TestHandleSlidingWindowKeepsAlertOpen(t *testing.T) {
+	t.Parallel()
+	ddbMock := &testutils.DynamoDBMock{}
+	sqsMock := &testutils.SqsMock{}
+	metricsMock := &testutils.LoggerMock{}
+	analysisMock := &gatewayapi.MockClient{}
+
+	handler := &Handler{
+		AlertTable:       "alertsTable",
+		AlertDedupTable:  "alertsDedupTable",
+		AlertingQueueURL: "queueUrl",
+		Cache:            NewCache(analysisMock),
+		DdbClient:        ddbMock,
+		SqsClient:        sqsMock,
+		MetricsLogger:    metricsMock,
+	}
+	rule := *testRuleResponse
+	rule.DedupPeriodMinutes = 60
+	rule.AlertGrouping = &ruleModel.AlertGrouping{Window: ruleModel.AlertGroupingSliding}
+	analysisMock.On("Invoke", expectedGetRuleInput, &ruleModel.Rule{}).Return(http.StatusOK, nil, &rule).Once()
+
+	// The rules engine started a new alert, but the previous one was updated 10 minutes ago
+	group := alertGroup{
+		AlertID:      "groupAlertId",
+		CreationTime: newAlertDedupEvent.UpdateTime.Add(-2 * time.Hour),
+		UpdateTime:   newAlertDedupEvent.UpdateTime.Add(-10 * time.Minute),
+		EventCount:   500,
+	}
+	item, err := dynamodbattribute.MarshalMap(&group)
+	require.NoError(t, err)
+	ddbMock.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: item}, nil).Once()
+
+	var alertUpdate, groupUpdate *dynamodb.UpdateItemInput
+	ddbMock.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Run(func(args mock.Arguments) {
+		input := args.Get(0).(*dynamodb.UpdateItemInput)
+		if *input.TableName == "alertsTable" {
+			alertUpdate = input
+		} else {
+			groupUpdate = input
+		}
+	}).Twice()
+
+	require.NoError(t, handler.Do(oldAlertDedupEvent, newAlertDedupEvent))
+
+	require.NotNil(t, alertUpdate)
+	assert.Equal(t, "groupAlertId", *alertUpdate.Key["id"].S)
+	require.NotNil(t, groupUpdate)
+	assert.Equal(t, "alertsDedupTable", *groupUpdate.TableName)
+	// The events of the new dedup alert are added to the group
+	assert.Contains(t, groupUpdate.ExpressionAttributeValues, ":3")
+	assert.Equal(t, "600", *groupUpdate.ExpressionAttributeValues[":3"].N)
+	ddbMock.AssertExpectations(t)
+	// No new alert is sent
+	sqsMock.AssertExpectations(t)
+	analysisMock.AssertExpectations(t)
+}
+
+func TestHandleSplitsAlert(t *testing.T) {
+	t.Parallel()
+	ddbMock := &testutils.DynamoDBMock{}
+	sqsMock := &testutils.SqsMock{}
+	metricsMock := &testutils.LoggerMock{}
+	analysisMock := &gatewayapi.MockClient{}
+
+	handler := &Handler{
+		AlertTable:       "alertsTable",
+		AlertDedupTable:  "alertsDedupTable",
+		AlertingQueueURL: "queueUrl",
+		Cache:            NewCache(analysisMock),
+		DdbClient:        ddbMock,
+		SqsClient:        sqsMock,
+		MetricsLogger:    metricsMock,
+	}
+	rule := *testRuleResponse
+	rule.DedupPeriodMinutes = 60
+	rule.AlertGrouping = &ruleModel.AlertGrouping{MaxEventsPerAlert: 100}
+	analysisMock.On("Invoke", expectedGetRuleInput, &ruleModel.Rule{}).Return(http.StatusOK, nil, &rule).Once()
+
+	// The rules engine keeps adding events to its alert, but the group reached the event limit
+	oldEvent := *newAlertDedupEvent
+	newEvent := oldEvent
+	newEvent.EventCount = oldEvent.EventCount + 50
+	newEvent.UpdateTime = oldEvent.UpdateTime.Add(time.Minute)
+	dedupAlertID := generateAlertID(&newEvent)
+
+	group := alertGroup{
+		AlertID:      "groupAlertId",
+		CreationTime: oldEvent.CreationTime,
+		UpdateTime:   oldEvent.UpdateTime,
+		EventCount:   100,
+	}
+	item, err := dynamodbattribute.MarshalMap(&group)
+	require.NoError(t, err)
+	ddbMock.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: item}, nil).Once()
+
+	var alertUpdate, groupUpdate *dynamodb.UpdateItemInput
+	ddbMock.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Run(func(args mock.Arguments) {
+		input := args.Get(0).(*dynamodb.UpdateItemInput)
+		if *input.TableName == "alertsTable" {
+			alertUpdate = input
+		} else {
+			groupUpdate = input
+		}
+	}).Twice()
+	var newAlert map[string]*dynamodb.AttributeValue
+	ddbMock.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Run(func(args mock.Arguments) {
+		newAlert = args.Get(0).(*dynamodb.PutItemInput).Item
+	}).Once()
+	sqsMock.On("SendMessage", mock.Anything).Return(&sqs.SendMessageOutput{}, nil).Once()
+	metricsMock.On("Log", mock.Anything, mock.Anything).Once()
+
+	require.NoError(t, handler.Do(&oldEvent, &newEvent))
+
+	// The alert of the group no longer includes the events from the split time
+	require.NotNil(t, alertUpdate)
+	assert.Equal(t, "groupAlertId", *alertUpdate.Key["id"].S)
+	assert.NotNil(t, alertUpdate.ConditionExpression)
+	assert.Equal(t, map[string]string{"#0": "id", "#1": alertApiModels.AlertTableEventsUntilAttribute},
+		aws.StringValueMap(alertUpdate.ExpressionAttributeNames))
+
+	// The new alert shares the p_alert_id of the rules engine alert, but only includes the events from the split time
+	require.NotNil(t, newAlert)
+	assert.Equal(t, splitAlertID(dedupAlertID, newEvent.UpdateTime), *newAlert["id"].S)
+	assert.Equal(t, []string{dedupAlertID}, aws.StringValueSlice(newAlert["dedupAlertIds"].SS))
+	require.Contains(t, newAlert, "eventsSince")
+	var eventsSince time.Time
+	require.NoError(t, dynamodbattribute.Unmarshal(newAlert["eventsSince"], &eventsSince))
+	assert.True(t, newEvent.UpdateTime.Equal(eventsSince))
+	assert.Equal(t, "50", *newAlert["eventCount"].N)
+
+	require.NotNil(t, groupUpdate)
+	assert.Equal(t, "alertsDedupTable", *groupUpdate.TableName)
+	ddbMock.AssertExpectations(t)
+	sqsMock.AssertExpectations(t)
+	metricsMock.AssertExpectations(t)
+	analysisMock.AssertExpectations(t)
+}
@@ -40,6 +40,7 @@ var (
 
 type envConfig struct {
 	AlertsTable      string `required:"true" split_words:"true"`
+	AlertsDedupTable string `required:"true" split_words:"true"`
 	AlertingQueueURL string `required:"true" split_words:"true"`
 }
 
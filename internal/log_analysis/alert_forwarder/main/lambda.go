@@ -60,6 +60,7 @@ func init() {
 		Cache:            cache,
 		AlertingQueueURL: env.AlertingQueueURL,
 		AlertTable:       env.AlertsTable,
+		AlertDedupTable:  env.AlertsDedupTable,
 		MetricsLogger:    metricsLogger,
 	}
 }
@@ -42,6 +42,7 @@ import (
 type API struct {
 	awsSession *session.Session
 	alertsDB   table.API
+	historyDB  table.DeliveryHistoryAPI
 	s3Client   s3iface.S3API
 	ruleCache  forwarder.RuleCache
 
@@ -52,6 +53,7 @@ const maxDDBPageSize = 10
 
 type envConfig struct {
 	table.AlertsTableEnvConfig
+	table.DeliveryHistoryTableEnvConfig
 	ProcessedDataBucket string `required:"true" split_words:"true"`
 }
 
@@ -61,13 +63,15 @@ func Setup() *API {
 	envconfig.MustProcess("", &env)
 
 	awsSession := session.Must(session.NewSession())
+	dynamoClient := dynamodb.New(awsSession)
 	lambdaClient := lambda.New(awsSession)
 	analysisClient := gatewayapi.NewClient(lambdaClient, "panther-analysis-api")
 	ruleCache := forwarder.NewCache(analysisClient)
 
 	return &API{
 		awsSession: awsSession,
-		alertsDB:   env.NewAlertsTable(dynamodb.New(awsSession)),
+		alertsDB:   env.NewAlertsTable(dynamoClient),
+		historyDB:  env.NewDeliveryHistoryTable(dynamoClient),
 		s3Client:   s3.New(awsSession.Copy(aws.NewConfig().WithMaxRetries(10))),
 		env:        env,
 		ruleCache:  ruleCache,
@@ -70,7 +70,12 @@ func (api *API) GetAlert(input *models.GetAlertInput) (*models.GetAlertOutput, e
 		zap.Any("token", token))
 
 	var events []string
-	for _, logType := range alertItem.LogTypes {
+	logTypes := alertItem.LogTypes
+	if alertItem.NoStoredEvents {
+		// There are no events to search for in S3
+		logTypes = nil
+	}
+	for _, logType := range logTypes {
 		// Each alert can contain events from multiple log types.
 		// Retrieve results from each log type.
 
@@ -136,7 +141,7 @@ func (api *API) getEventsForLogType(
 			client:              api.s3Client,
 			bucket:              api.env.ProcessedDataBucket,
 			objectKey:           token.S3ObjectKey,
-			alertID:             alert.AlertID,
+			condition:           eventsCondition(alert),
 			exclusiveStartIndex: token.EventIndex,
 			maxResults:          maxResults,
 		}
@@ -213,6 +218,14 @@ func (api *API) getEventsForLogType(
 	return outEvents, &outToken, nil
 }
 
+// The p_alert_id of the events in the alert
+func eventAlertIDs(alert *table.AlertItem) []string {
+	if len(alert.DedupAlertIDs) > 0 {
+		return alert.DedupAlertIDs
+	}
+	return []string{alert.AlertID}
+}
+
 func getFirstEventTime(alert *table.AlertItem) time.Time {
 	if alert.FirstEventMatchTime.IsZero() {
 		// This check is for backward compatibility since
@@ -52,6 +52,29 @@ func TestGetAlertDoesNotExist(t *testing.T) {
 	tableMock.AssertExpectations(t)
 }
 
+func TestGetAlertWithoutStoredEvents(t *testing.T) {
+	api := initTestAPI()
+	alertItem := &table.AlertItem{
+		AlertID:        "alertId",
+		RuleID:         "ruleId",
+		RuleVersion:    "ruleVersion",
+		CreationTime:   time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC),
+		UpdateTime:     time.Date(2020, 1, 2, 1, 0, 0, 0, time.UTC),
+		EventCount:     3,
+		LogTypes:       []string{"logtype"},
+		NoStoredEvents: true,
+	}
+	api.mockTable.On("GetAlert", "alertId").Return(alertItem, nil).Once()
+	api.mockRuleCache.On("Get", "ruleId", "ruleVersion").Return(&rulemodels.Rule{}, nil).Once()
+
+	result, err := api.GetAlert(&models.GetAlertInput{AlertID: "alertId", EventsPageSize: aws.Int(5)})
+	require.NoError(t, err)
+	assert.Empty(t, result.Events)
+	// S3 is not searched
+	api.mockS3.AssertExpectations(t)
+	api.mockTable.AssertExpectations(t)
+}
+
 func TestGetRuleAlert(t *testing.T) {
 	api := initTestAPI()
 
@@ -299,3 +322,16 @@ func getChannel(events ...string) <-chan s3.SelectObjectContentEventStreamEvent
 	close(channel)
 	return channel
 }
+
+func TestEventsCondition(t *testing.T) {
+	t.Parallel()
+	splitTime := time.Date(2020, 1, 1, 1, 59, 10, 123456789, time.UTC)
+	assert.Equal(t, "o.p_alert_id='alertId'", eventsCondition(&table.AlertItem{AlertID: "alertId"}))
+	assert.Equal(t, "o.p_alert_id IN ('a','b')",
+		eventsCondition(&table.AlertItem{AlertID: "alertId", DedupAlertIDs: []string{"a", "b"}}))
+	// The alert was split while the rules engine was adding events with the same p_alert_id
+	assert.Equal(t, "o.p_alert_id='alertId' AND o.p_alert_update_time < '2020-01-01 01:59:10.123456000'",
+		eventsCondition(&table.AlertItem{AlertID: "alertId", EventsUntil: &splitTime}))
+	assert.Equal(t, "o.p_alert_id='a' AND o.p_alert_update_time >= '2020-01-01 01:59:10.123456000'",
+		eventsCondition(&table.AlertItem{AlertID: "splitId", DedupAlertIDs: []string{"a"}, EventsSince: &splitTime}))
+}
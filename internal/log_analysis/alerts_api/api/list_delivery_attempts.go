@@ -0,0 +1,46 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// ListDeliveryAttempts returns the delivery timeline of an alert or an output.
+func (api *API) ListDeliveryAttempts(input *models.ListDeliveryAttemptsInput) (*models.ListDeliveryAttemptsOutput, error) {
+	if (input.AlertID == nil) == (input.OutputID == nil) {
+		return nil, &genericapi.InvalidInputError{Message: "exactly one of alertId or outputId is required"}
+	}
+
+	items, lastEvaluatedKey, err := api.historyDB.ListDeliveryAttempts(input)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ListDeliveryAttemptsOutput{
+		DeliveryAttempts: make([]*models.DeliveryAttempt, 0, len(items)),
+		LastEvaluatedKey: lastEvaluatedKey,
+	}
+	for _, item := range items {
+		attempt := item.DeliveryAttempt
+		result.DeliveryAttempts = append(result.DeliveryAttempts, &attempt)
+	}
+	return result, nil
+}
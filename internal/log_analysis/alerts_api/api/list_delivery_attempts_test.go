@@ -0,0 +1,75 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/internal/log_analysis/alerts_api/table"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func TestListDeliveryAttempts(t *testing.T) {
+	t.Parallel()
+	api := initTestAPI()
+
+	attempt := models.DeliveryAttempt{
+		AlertID:         "alertId",
+		OutputID:        "output-id",
+		DispatchedAt:    time.Now().UTC(),
+		StatusCode:      504,
+		LatencyMillis:   30000,
+		RetryCount:      2,
+		ResponseExcerpt: "gateway timeout",
+	}
+	input := &models.ListDeliveryAttemptsInput{OutputID: aws.String("output-id")}
+	api.mockHistory.On("ListDeliveryAttempts", input).Return(
+		[]*table.DeliveryAttemptItem{{DeliveryAttempt: attempt, AttemptKey: "key"}}, aws.String("lastKey"), nil).Once()
+
+	result, err := api.ListDeliveryAttempts(input)
+	require.NoError(t, err)
+	assert.Equal(t, &models.ListDeliveryAttemptsOutput{
+		DeliveryAttempts: []*models.DeliveryAttempt{&attempt},
+		LastEvaluatedKey: aws.String("lastKey"),
+	}, result)
+
+	api.AssertExpectations(t)
+}
+
+func TestListDeliveryAttemptsRequiresOneKey(t *testing.T) {
+	t.Parallel()
+	api := initTestAPI()
+
+	_, err := api.ListDeliveryAttempts(&models.ListDeliveryAttemptsInput{})
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+
+	_, err = api.ListDeliveryAttempts(&models.ListDeliveryAttemptsInput{
+		AlertID:  aws.String("alertId"),
+		OutputID: aws.String("output-id"),
+	})
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+
+	api.AssertExpectations(t)
+}
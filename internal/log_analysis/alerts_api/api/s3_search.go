@@ -143,7 +143,7 @@ func (s *S3Search) queryPage(ctx context.Context, objects []*s3.Object) ([]*S3Se
 			bucket:     *s.list.Bucket,
 			client:     s.client,
 			objectKey:  *object.Key,
-			alertID:    s.alert.AlertID,
+			condition:  eventsCondition(s.alert),
 			maxResults: s.maxResults,
 		}
 		queryChan <- s3SelectQuery
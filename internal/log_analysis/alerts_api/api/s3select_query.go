@@ -5,11 +5,14 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/internal/log_analysis/alerts_api/table"
 )
 
 /**
@@ -30,11 +33,14 @@ import (
  * along with this program.  If not, see <https://www.gnu.org/licenses/>.
  */
 
+// The layout of p_alert_update_time in the events written by the rules engines
+const eventAlertTimeLayout = "2006-01-02 15:04:05.000000000"
+
 type S3Select struct {
 	client              s3iface.S3API
 	bucket              string
 	objectKey           string
-	alertID             string
+	condition           string
 	exclusiveStartIndex int
 	maxResults          int
 }
@@ -49,9 +55,9 @@ type Event struct {
 	payload string
 }
 
-// Queries a specific S3 object events associated to `alertID`.
+// Queries a specific S3 object for the events matching `condition`.
 // Returns :
-// 1. The events that are associated to the given alertID that are present in that S3 object. It will return maximum `maxResults` events
+// 1. The events matching the condition that are present in that S3 object. It will return maximum `maxResults` events
 // 2. The index of the last event returned. This will be used as a pagination token - future queries to the same S3 object can start listing
 // after that.
 func (s *S3Select) Query(ctx context.Context) (*S3SelectResult, error) {
@@ -63,8 +69,8 @@ func (s *S3Select) Query(ctx context.Context) (*S3SelectResult, error) {
 	maxResults := s.maxResults + s.exclusiveStartIndex
 
 	// nolint:gosec
-	// The alertID is an MD5 hash. AlertsAPI is performing the appropriate validation
-	query := fmt.Sprintf("SELECT * FROM S3Object o WHERE o.p_alert_id='%s' LIMIT %d", s.alertID, maxResults)
+	// The alert IDs are MD5 hashes. AlertsAPI is performing the appropriate validation
+	query := fmt.Sprintf("SELECT * FROM S3Object o WHERE %s LIMIT %d", s.condition, maxResults)
 
 	zap.L().Debug("querying object using S3 Select",
 		zap.String("S3ObjectKey", s.objectKey),
@@ -129,3 +135,27 @@ func (s *S3Select) QueryAsync(ctx context.Context, outChan chan<- *S3SelectResul
 	outChan <- result
 	return nil
 }
+
+// The S3 Select condition matching the events of an alert
+func eventsCondition(alert *table.AlertItem) string {
+	condition := alertIDCondition(eventAlertIDs(alert))
+	if alert.EventsSince != nil {
+		condition += fmt.Sprintf(" AND o.p_alert_update_time >= '%s'", formatAlertTime(*alert.EventsSince))
+	}
+	if alert.EventsUntil != nil {
+		condition += fmt.Sprintf(" AND o.p_alert_update_time < '%s'", formatAlertTime(*alert.EventsUntil))
+	}
+	return condition
+}
+
+// Format a time like the p_alert_update_time of the events, which compares as a string
+func formatAlertTime(t time.Time) string {
+	return t.UTC().Truncate(time.Microsecond).Format(eventAlertTimeLayout)
+}
+
+func alertIDCondition(alertIDs []string) string {
+	if len(alertIDs) == 1 {
+		return fmt.Sprintf("o.p_alert_id='%s'", alertIDs[0])
+	}
+	return fmt.Sprintf("o.p_alert_id IN ('%s')", strings.Join(alertIDs, "','"))
+}
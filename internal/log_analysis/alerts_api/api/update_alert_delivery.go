@@ -27,7 +27,30 @@ import (
 )
 
 // UpdateAlertDelivery modifies an alert's attributes.
+//
+// Every delivery response is also recorded in the delivery history before the alert is updated:
+// history writes are idempotent, so a retried invocation will not duplicate them.
 func (api *API) UpdateAlertDelivery(input *models.UpdateAlertDeliveryInput) (result *models.UpdateAlertDeliveryOutput, err error) {
+	attempts := make([]*models.DeliveryAttempt, 0, len(input.DeliveryResponses))
+	for _, response := range input.DeliveryResponses {
+		attempts = append(attempts, &models.DeliveryAttempt{
+			AlertID:         input.AlertID,
+			OutputID:        response.OutputID,
+			DispatchedAt:    response.DispatchedAt,
+			StatusCode:      response.StatusCode,
+			Success:         response.Success,
+			LatencyMillis:   response.LatencyMillis,
+			RetryCount:      response.RetryCount,
+			Resent:          response.Resent,
+			TicketID:        response.TicketID,
+			Status:          response.Status,
+			ResponseExcerpt: response.Message,
+		})
+	}
+	if err = api.historyDB.PutDeliveryAttempts(attempts); err != nil {
+		return nil, err
+	}
+
 	// Run the update alert query
 	alertItem, err := api.alertsDB.UpdateAlertDelivery(input)
 	if err != nil {
@@ -19,9 +19,12 @@ package api
  */
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 
 	"github.com/panther-labs/panther/api/lambda/alerts/models"
 	rulemodels "github.com/panther-labs/panther/api/lambda/analysis/models"
@@ -33,31 +36,63 @@ func TestUpdateAlertDelivery(t *testing.T) {
 	api := initTestAPI()
 
 	alertID := "alertId"
+	dispatchedAt := time.Now().UTC()
 	deliveryResponse := &models.DeliveryResponse{
-		OutputID: "output-id",
-		Message:  "successful delivery",
-		Success:  true,
+		OutputID:      "output-id",
+		Message:       "successful delivery",
+		StatusCode:    200,
+		Success:       true,
+		DispatchedAt:  dispatchedAt,
+		LatencyMillis: 120,
+		Resent:        true,
+	}
+	suppressedResponse := &models.DeliveryResponse{
+		OutputID:     "other-output-id",
+		Message:      "suppressed by maintenance window window-id",
+		Success:      true,
+		DispatchedAt: dispatchedAt,
+		Status:       models.DeliverySuppressedStatus,
 	}
 
 	// Mocking table interactions
 	input := &models.UpdateAlertDeliveryInput{
 		AlertID:           alertID,
-		DeliveryResponses: []*models.DeliveryResponse{},
+		DeliveryResponses: []*models.DeliveryResponse{deliveryResponse, suppressedResponse},
 	}
 	output := &table.AlertItem{
 		AlertID:           alertID,
 		Type:              "RULE",
 		RuleID:            "ruleId",
 		RuleVersion:       "ruleVersion",
-		DeliveryResponses: []*models.DeliveryResponse{deliveryResponse},
+		DeliveryResponses: []*models.DeliveryResponse{deliveryResponse, suppressedResponse},
 	}
+	api.mockHistory.On("PutDeliveryAttempts", []*models.DeliveryAttempt{
+		{
+			AlertID:         alertID,
+			OutputID:        "output-id",
+			DispatchedAt:    dispatchedAt,
+			StatusCode:      200,
+			Success:         true,
+			LatencyMillis:   120,
+			Resent:          true,
+			ResponseExcerpt: "successful delivery",
+		},
+		{
+			AlertID:         alertID,
+			OutputID:        "other-output-id",
+			DispatchedAt:    dispatchedAt,
+			Success:         true,
+			Status:          models.DeliverySuppressedStatus,
+			ResponseExcerpt: "suppressed by maintenance window window-id",
+		},
+	}).Return(nil).Once()
 	api.mockTable.On("UpdateAlertDelivery", input).Return(output, nil).Once()
 
 	api.mockRuleCache.On("Get", "ruleId", "ruleVersion").Return(&rulemodels.Rule{}, nil).Once()
 
 	expectedSummary := &models.AlertSummary{
 		AlertID:           alertID,
-		DeliveryResponses: []*models.DeliveryResponse{deliveryResponse},
+		DeliveryResponses: []*models.DeliveryResponse{deliveryResponse, suppressedResponse},
 	}
 
 	result, err := api.UpdateAlertDelivery(input)
@@ -72,3 +107,23 @@ func TestUpdateAlertDelivery(t *testing.T) {
 
 	api.AssertExpectations(t)
 }
+
+func TestUpdateAlertDeliveryHistoryFailure(t *testing.T) {
+	t.Parallel()
+	api := initTestAPI()
+
+	input := &models.UpdateAlertDeliveryInput{
+		AlertID: "alertId",
+		DeliveryResponses: []*models.DeliveryResponse{
+			{OutputID: "output-id", Message: "gateway timeout", StatusCode: 504},
+		},
+	}
+	api.mockHistory.On("PutDeliveryAttempts", mock.Anything).Return(errors.New("throttled")).Once()
+
+	// The alert is not updated so the retried invocation does not append the responses twice
+	result, err := api.UpdateAlertDelivery(input)
+	assert.Error(t, err)
+	assert.Nil(t, result)
+
+	api.AssertExpectations(t)
+}
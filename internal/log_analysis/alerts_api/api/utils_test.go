@@ -34,6 +34,7 @@ type AlertAPITest struct {
 	API
 
 	mockTable     *tableMock
+	mockHistory   *historyMock
 	mockRuleCache *ruleCacheMock
 	mockS3        *testutils.S3Mock
 }
@@ -42,6 +43,7 @@ func (a *AlertAPITest) AssertExpectations(t *testing.T) {
 	a.mockS3.AssertExpectations(t)
 	a.mockRuleCache.AssertExpectations(t)
 	a.mockTable.AssertExpectations(t)
+	a.mockHistory.AssertExpectations(t)
 }
 
 type ruleCacheMock struct {
@@ -79,13 +81,30 @@ func (m *tableMock) UpdateAlertDelivery(input *models.UpdateAlertDeliveryInput)
 	return args.Get(0).(*table.AlertItem), args.Error(1)
 }
 
+type historyMock struct {
+	table.DeliveryHistoryAPI
+	mock.Mock
+}
+
+func (m *historyMock) PutDeliveryAttempts(input []*models.DeliveryAttempt) error {
+	args := m.Called(input)
+	return args.Error(0)
+}
+
+func (m *historyMock) ListDeliveryAttempts(input *models.ListDeliveryAttemptsInput) ([]*table.DeliveryAttemptItem, *string, error) {
+	args := m.Called(input)
+	return args.Get(0).([]*table.DeliveryAttemptItem), args.Get(1).(*string), args.Error(2)
+}
+
 func initTestAPI() *AlertAPITest {
 	mockTable := &tableMock{}
+	mockHistory := &historyMock{}
 	mockS3 := &testutils.S3Mock{}
 	mockRuleCache := &ruleCacheMock{}
 
 	api := API{
 		alertsDB:  mockTable,
+		historyDB: mockHistory,
 		s3Client:  mockS3,
 		ruleCache: mockRuleCache,
 		env: envConfig{
@@ -97,6 +116,7 @@ func initTestAPI() *AlertAPITest {
 		mockRuleCache: mockRuleCache,
 		mockS3:        mockS3,
 		mockTable:     mockTable,
+		mockHistory:   mockHistory,
 		API:           api,
 	}
 }
@@ -0,0 +1,148 @@
+package awsglue
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/glue"
+	"github.com/aws/aws-sdk-go/service/glue/glueiface"
+	"github.com/pkg/errors"
+
+	cloudsecglue "github.com/panther-labs/panther/internal/compliance/awsglue"
+	"github.com/panther-labs/panther/internal/log_analysis/pantherdb"
+	"github.com/panther-labs/panther/pkg/awsutils"
+)
+
+const (
+	// https://github.com/awslabs/aws-athena-query-federation/tree/master/athena-dynamodb
+
+	DeliveryHistoryTableDDB = "panther-alert-delivery-history"
+	// DeliveryLogType is the name the delivery history is queried by in the data lake
+	DeliveryLogType          = "Panther.AlertDelivery"
+	DeliveryTableDescription = "Every attempt Panther made to deliver an alert to a destination"
+)
+
+// DeliveryTable is the name of the data lake table for the delivery history (panther_alertdelivery)
+var DeliveryTable = pantherdb.TableName(DeliveryLogType)
+
+// CreateOrUpdateDeliveryTable exposes the delivery history DynamoDB table in the panther_alerts database.
+// It is kept out of panther_logs because tables there are expected to be partitioned S3 tables.
+func CreateOrUpdateDeliveryTable(glueClient glueiface.GlueAPI, locationARN string) error {
+	// FIXME: Remove when the DDB connector is GA
+	supported, err := cloudsecglue.DDBConnectorSupported(locationARN)
+	if err != nil || !supported {
+		return err
+	}
+
+	tableInput := &glue.TableInput{
+		Name:        aws.String(DeliveryTable),
+		Description: aws.String(DeliveryTableDescription),
+		Parameters: map[string]*string{
+			// per https://github.com/awslabs/aws-athena-query-federation/tree/master/athena-dynamodb
+			"classification": aws.String("dynamodb"),
+			"sourceTable":    aws.String(DeliveryHistoryTableDDB),
+			// for attrs with upper case
+			// nolint:lll
+			"columnMapping": aws.String(`alertid=alertId,outputid=outputId,dispatchedat=dispatchedAt,statuscode=statusCode,latencymillis=latencyMillis,retrycount=retryCount,ticketid=ticketId,responseexcerpt=responseExcerpt`),
+		},
+		StorageDescriptor: &glue.StorageDescriptor{
+			Location: &locationARN,
+
+			Columns: []*glue.Column{
+				{
+					Name:    aws.String("alertid"),
+					Type:    aws.String("string"),
+					Comment: aws.String("The ID of the alert that was delivered."),
+				},
+				{
+					Name:    aws.String("outputid"),
+					Type:    aws.String("string"),
+					Comment: aws.String("The ID of the destination the alert was delivered to."),
+				},
+				{
+					Name:    aws.String("dispatchedat"),
+					Type:    aws.String("string"),
+					Comment: aws.String("Timestamp of the delivery attempt."),
+				},
+				{
+					Name:    aws.String("statuscode"),
+					Type:    aws.String("int"),
+					Comment: aws.String("The status code returned by the destination."),
+				},
+				{
+					Name:    aws.String("success"),
+					Type:    aws.String("boolean"),
+					Comment: aws.String("True if the alert was delivered."),
+				},
+				{
+					Name:    aws.String("latencymillis"),
+					Type:    aws.String("bigint"),
+					Comment: aws.String("How long the destination took to respond, in milliseconds."),
+				},
+				{
+					Name:    aws.String("retrycount"),
+					Type:    aws.String("int"),
+					Comment: aws.String("The number of previous attempts to deliver the alert."),
+				},
+				{
+					Name:    aws.String("resent"),
+					Type:    aws.String("boolean"),
+					Comment: aws.String("True if the delivery was manually requested by a user."),
+				},
+				{
+					Name:    aws.String("ticketid"),
+					Type:    aws.String("string"),
+					Comment: aws.String("The ID of the ticket created by ticketing destinations."),
+				},
+				{
+					Name:    aws.String("status"),
+					Type:    aws.String("string"),
+					Comment: aws.String("SUPPRESSED if a maintenance window held the alert back, empty otherwise."),
+				},
+				{
+					Name:    aws.String("responseexcerpt"),
+					Type:    aws.String("string"),
+					Comment: aws.String("The beginning of the message or response body returned by the destination."),
+				},
+			},
+		},
+		TableType: aws.String("EXTERNAL_TABLE"),
+	}
+
+	createTableInput := &glue.CreateTableInput{
+		DatabaseName: aws.String(pantherdb.AlertsDatabase),
+		TableInput:   tableInput,
+	}
+
+	_, err = glueClient.CreateTable(createTableInput)
+	if err != nil {
+		if awsutils.IsAnyError(err, glue.ErrCodeAlreadyExistsException) {
+			// need to do an update
+			updateTableInput := &glue.UpdateTableInput{
+				DatabaseName: aws.String(pantherdb.AlertsDatabase),
+				TableInput:   tableInput,
+			}
+			_, err := glueClient.UpdateTable(updateTableInput)
+			return errors.Wrapf(err, "failed to update table %s.%s", pantherdb.AlertsDatabase, DeliveryTable)
+		}
+		return errors.Wrapf(err, "failed to create table %s.%s", pantherdb.AlertsDatabase, DeliveryTable)
+	}
+
+	return nil
+}
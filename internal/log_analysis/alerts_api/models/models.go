@@ -31,6 +31,12 @@ const (
 	AlertTableLogTypesAttribute   = "logTypes"
 	AlertTableEventCountAttribute = "eventCount"
 	AlertTableUpdateTimeAttribute = "updateTime"
+	// The ids of the events grouped into the alert, see Alert.DedupAlertIDs
+	AlertTableDedupAlertIDsAttribute = "dedupAlertIds"
+	// The end of the events of an alert which was split, see Alert.EventsSince
+	AlertTableEventsUntilAttribute = "eventsUntil"
+
+	AlertDedupTablePartitionKey = "partitionKey"
 )
 
 // AlertDedupEvent represents the event stored in the alert dedup DDB table by the rules engine
@@ -44,6 +50,8 @@ type AlertDedupEvent struct {
 	LogTypes            []string  `dynamodbav:"logTypes,stringset"`
 	AlertContext        *string   `dynamodbav:"context,string"`
 	Type                string    `dynamodbav:"type"`
+	// The events of the alert are not stored in S3 (scheduled rules, notices about a rule)
+	NoStoredEvents bool `dynamodbav:"noStoredEvents,omitempty"`
 	// Generated Fields
 	GeneratedTitle        *string  `dynamodbav:"title,string"`
 	GeneratedDescription  *string  `dynamodbav:"description,string"`
@@ -74,6 +82,13 @@ type Alert struct {
 	LogTypes            []string  `dynamodbav:"logTypes,stringset"`
 	// Alert Title - will be the Python-generated title or a default one if no Python-generated title is available.
 	Title string `dynamodbav:"title,string"`
+	// The ids the rules engine assigned to the events of the alert (p_alert_id), if the alert forwarder
+	// grouped them differently than the rules engine. Not set if the events have the id of the alert.
+	DedupAlertIDs []string `dynamodbav:"dedupAlertIds,stringset,omitempty"`
+	// Set if the alert forwarder split a rules engine alert which is still adding events with the same id.
+	// Only the events the rules engine added from this time belong to the alert. The alert which was split
+	// gets an eventsUntil attribute with the same time.
+	EventsSince *time.Time `dynamodbav:"eventsSince,omitempty"`
 	AlertDedupEvent
 	AlertPolicy
 }
@@ -187,6 +202,11 @@ func FromDynamodDBAttribute(input map[string]events.DynamoDBAttributeValue) (eve
 		result.Type = alertType.String()
 	}
 
+	noStoredEvents := getOptionalAttribute("noStoredEvents", input)
+	if noStoredEvents != nil {
+		result.NoStoredEvents = noStoredEvents.Boolean()
+	}
+
 	return result, nil
 }
 
@@ -0,0 +1,285 @@
+package table
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/pkg/awsbatch/dynamodbbatch"
+)
+
+const (
+	DeliveryAlertIDKey      = "alertId"
+	DeliveryAttemptKey      = "attemptKey"
+	DeliveryOutputIDKey     = "outputId"
+	DeliveryDispatchedAtKey = "dispatchedAt"
+
+	// Delivery attempts age out of the table (and the data lake table built on top of it) after this period
+	deliveryHistoryRetention = 90 * 24 * time.Hour
+	// Longest we retry writing a batch of delivery attempts
+	maxDeliveryWriteBackoff = 30 * time.Second
+	// Longest response excerpt we store for a delivery attempt
+	maxResponseExcerptLength = 1024
+	defaultDeliveryPageSize  = 25
+	// Dispatch times are compared as strings, so they are stored with a fixed width.
+	// RFC3339Nano drops trailing zeros, "...:40.5Z" would sort after "...:40.51Z".
+	deliveryTimeLayout = "2006-01-02T15:04:05.000000000Z07:00"
+)
+
+// DeliveryHistoryAPI defines the interface for the delivery history table which can be used for mocking.
+type DeliveryHistoryAPI interface {
+	PutDeliveryAttempts([]*models.DeliveryAttempt) error
+	ListDeliveryAttempts(*models.ListDeliveryAttemptsInput) ([]*DeliveryAttemptItem, *string, error)
+}
+
+// DeliveryHistoryTable encapsulates a connection to the Dynamo delivery history table.
+//
+// Every attempt to deliver an alert to an output is stored under the alert ID,
+// with a secondary index to list the attempts made to a given output.
+type DeliveryHistoryTable struct {
+	TableName                 string
+	OutputIDDispatchIndexName string
+	Client                    dynamodbiface.DynamoDBAPI
+}
+
+type DeliveryHistoryTableEnvConfig struct {
+	// env config for instantiating a table.DeliveryHistoryTable
+	DeliveryHistoryTableName       string `required:"true" split_words:"true"`
+	DeliveryHistoryOutputIndexName string `required:"true" split_words:"true"`
+}
+
+func (config *DeliveryHistoryTableEnvConfig) NewDeliveryHistoryTable(client dynamodbiface.DynamoDBAPI) *DeliveryHistoryTable {
+	return &DeliveryHistoryTable{
+		TableName:                 config.DeliveryHistoryTableName,
+		OutputIDDispatchIndexName: config.DeliveryHistoryOutputIndexName,
+		Client:                    client,
+	}
+}
+
+// The DeliveryHistoryTable must satisfy the DeliveryHistoryAPI interface.
+var _ DeliveryHistoryAPI = (*DeliveryHistoryTable)(nil)
+
+// DeliveryAttemptItem is a DDB representation of a delivery attempt
+type DeliveryAttemptItem struct {
+	models.DeliveryAttempt
+	// AttemptKey is the sort key: "dispatchedAt#outputId"
+	AttemptKey string `json:"attemptKey"`
+	// ExpiresAt is the TTL of the item, in epoch seconds
+	ExpiresAt int64 `json:"expiresAt"`
+}
+
+// NewDeliveryAttemptItem builds the DDB item for a delivery attempt, truncating the response excerpt
+func NewDeliveryAttemptItem(attempt *models.DeliveryAttempt) *DeliveryAttemptItem {
+	item := &DeliveryAttemptItem{
+		DeliveryAttempt: *attempt,
+		AttemptKey:      formatDeliveryTime(attempt.DispatchedAt) + "#" + attempt.OutputID,
+		ExpiresAt:       attempt.DispatchedAt.Add(deliveryHistoryRetention).Unix(),
+	}
+	item.DispatchedAt = attempt.DispatchedAt.UTC()
+	if len(item.ResponseExcerpt) > maxResponseExcerptLength {
+		item.ResponseExcerpt = item.ResponseExcerpt[:maxResponseExcerptLength]
+	}
+	return item
+}
+
+// PutDeliveryAttempts - records a list of delivery attempts
+func (table *DeliveryHistoryTable) PutDeliveryAttempts(attempts []*models.DeliveryAttempt) error {
+	if len(attempts) == 0 {
+		return nil
+	}
+
+	writeRequests := make([]*dynamodb.WriteRequest, 0, len(attempts))
+	for _, attempt := range attempts {
+		item, err := dynamodbattribute.MarshalMap(NewDeliveryAttemptItem(attempt))
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal delivery attempt")
+		}
+		// The sort key of the output index
+		item[DeliveryDispatchedAtKey] = &dynamodb.AttributeValue{S: aws.String(formatDeliveryTime(attempt.DispatchedAt))}
+		writeRequests = append(writeRequests, &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: item}})
+	}
+
+	input := &dynamodb.BatchWriteItemInput{
+		RequestItems: map[string][]*dynamodb.WriteRequest{table.TableName: writeRequests},
+	}
+	return errors.Wrap(dynamodbbatch.BatchWriteItem(table.Client, maxDeliveryWriteBackoff, input),
+		"failed to write delivery attempts")
+}
+
+// ListDeliveryAttempts - lists the delivery attempts of an alert or an output, newest first
+func (table *DeliveryHistoryTable) ListDeliveryAttempts(input *models.ListDeliveryAttemptsInput) (
+	attempts []*DeliveryAttemptItem, lastEvaluatedKey *string, err error) {
+
+	queryInput, err := table.buildDeliveryQuery(input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pageSize := defaultDeliveryPageSize
+	if input.PageSize != nil {
+		pageSize = *input.PageSize
+	}
+
+	var lastKey DynamoItem
+	var errMarshal error
+	err = table.Client.QueryPages(queryInput, func(page *dynamodb.QueryOutput, isLast bool) bool {
+		for _, item := range page.Items {
+			var attempt *DeliveryAttemptItem
+			if errMarshal = dynamodbattribute.UnmarshalMap(item, &attempt); errMarshal != nil {
+				return false
+			}
+			attempts = append(attempts, attempt)
+
+			if len(attempts) == pageSize {
+				lastKey = getDeliveryLastKey(input, item)
+				return false // we are done, stop paging
+			}
+		}
+		return true // keep paging
+	})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "QueryPages() failed for delivery attempts")
+	}
+	if errMarshal != nil {
+		return nil, nil, errors.Wrap(errMarshal, "failed to unmarshal delivery attempt")
+	}
+
+	if len(lastKey) > 0 {
+		lastEvaluatedKeySerialized, err := jsoniter.MarshalToString(lastKey)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to Marshal LastEvaluatedKey)")
+		}
+		lastEvaluatedKey = &lastEvaluatedKeySerialized
+	}
+	return attempts, lastEvaluatedKey, nil
+}
+
+// buildDeliveryQuery - queries by alert on the table, or by output on the secondary index
+func (table *DeliveryHistoryTable) buildDeliveryQuery(input *models.ListDeliveryAttemptsInput) (*dynamodb.QueryInput, error) {
+	var keyCondition expression.KeyConditionBuilder
+	var index *string
+	builder := expression.NewBuilder()
+	if input.AlertID != nil {
+		keyCondition = expression.Key(DeliveryAlertIDKey).Equal(expression.Value(*input.AlertID))
+		// The sort key starts with the dispatch time, the time range is a filter on the attempts of the alert
+		if filter, ok := dispatchedAtFilter(input); ok {
+			builder = builder.WithFilter(filter)
+		}
+	} else {
+		index = &table.OutputIDDispatchIndexName
+		keyCondition = expression.Key(DeliveryOutputIDKey).Equal(expression.Value(aws.StringValue(input.OutputID)))
+		if rangeCondition, ok := dispatchedAtKeyCondition(input); ok {
+			keyCondition = keyCondition.And(rangeCondition)
+		}
+	}
+
+	queryExpression, err := builder.WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build expression")
+	}
+
+	var exclusiveStartKey DynamoItem
+	if input.ExclusiveStartKey != nil {
+		if err = jsoniter.UnmarshalFromString(*input.ExclusiveStartKey, &exclusiveStartKey); err != nil {
+			return nil, errors.Wrap(err, "failed to Unmarshal ExclusiveStartKey")
+		}
+	}
+
+	return &dynamodb.QueryInput{
+		TableName:                 &table.TableName,
+		IndexName:                 index,
+		ScanIndexForward:          aws.Bool(false),
+		ExpressionAttributeNames:  queryExpression.Names(),
+		ExpressionAttributeValues: queryExpression.Values(),
+		FilterExpression:          queryExpression.Filter(),
+		KeyConditionExpression:    queryExpression.KeyCondition(),
+		ExclusiveStartKey:         exclusiveStartKey,
+	}, nil
+}
+
+// dispatchedAtKeyCondition - builds the sort key condition for the requested time range
+func dispatchedAtKeyCondition(input *models.ListDeliveryAttemptsInput) (expression.KeyConditionBuilder, bool) {
+	key := expression.Key(DeliveryDispatchedAtKey)
+	after, before := dispatchedAtRange(input)
+	switch {
+	case after != nil && before != nil:
+		return key.Between(*after, *before), true
+	case after != nil:
+		return key.GreaterThanEqual(*after), true
+	case before != nil:
+		return key.LessThanEqual(*before), true
+	default:
+		return expression.KeyConditionBuilder{}, false
+	}
+}
+
+// dispatchedAtFilter - builds the filter for the requested time range
+func dispatchedAtFilter(input *models.ListDeliveryAttemptsInput) (expression.ConditionBuilder, bool) {
+	name := expression.Name(DeliveryDispatchedAtKey)
+	after, before := dispatchedAtRange(input)
+	switch {
+	case after != nil && before != nil:
+		return name.Between(*after, *before), true
+	case after != nil:
+		return name.GreaterThanEqual(*after), true
+	case before != nil:
+		return name.LessThanEqual(*before), true
+	default:
+		return expression.ConditionBuilder{}, false
+	}
+}
+
+// dispatchedAtRange - timestamps are stored in UTC and compared as strings
+func dispatchedAtRange(input *models.ListDeliveryAttemptsInput) (after, before *expression.ValueBuilder) {
+	if input.DispatchedAtAfter != nil {
+		value := expression.Value(formatDeliveryTime(*input.DispatchedAtAfter))
+		after = &value
+	}
+	if input.DispatchedAtBefore != nil {
+		value := expression.Value(formatDeliveryTime(*input.DispatchedAtBefore))
+		before = &value
+	}
+	return after, before
+}
+
+func formatDeliveryTime(t time.Time) string {
+	return t.UTC().Format(deliveryTimeLayout)
+}
+
+// getDeliveryLastKey - manually constructs the lastEvaluatedKey to be returned to the frontend
+func getDeliveryLastKey(input *models.ListDeliveryAttemptsInput, item DynamoItem) DynamoItem {
+	lastKey := DynamoItem{
+		DeliveryAlertIDKey: item[DeliveryAlertIDKey],
+		DeliveryAttemptKey: item[DeliveryAttemptKey],
+	}
+	if input.AlertID == nil {
+		lastKey[DeliveryOutputIDKey] = item[DeliveryOutputIDKey]
+		lastKey[DeliveryDispatchedAtKey] = item[DeliveryDispatchedAtKey]
+	}
+	return lastKey
+}
@@ -0,0 +1,111 @@
+package table
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+func TestNewDeliveryAttemptItem(t *testing.T) {
+	dispatchedAt := time.Date(2020, 6, 17, 15, 49, 40, 0, time.FixedZone("EST", -5*60*60))
+	item := NewDeliveryAttemptItem(&models.DeliveryAttempt{
+		AlertID:         "alertId",
+		OutputID:        "output-id",
+		DispatchedAt:    dispatchedAt,
+		ResponseExcerpt: strings.Repeat("x", 2000),
+	})
+
+	assert.Equal(t, "2020-06-17T20:49:40.000000000Z#output-id", item.AttemptKey)
+	assert.Equal(t, dispatchedAt.UTC(), item.DispatchedAt)
+	assert.Equal(t, dispatchedAt.Add(90*24*time.Hour).Unix(), item.ExpiresAt)
+	assert.Len(t, item.ResponseExcerpt, maxResponseExcerptLength)
+}
+
+func TestPutDeliveryAttempts(t *testing.T) {
+	mockDdbClient := &testutils.DynamoDBMock{}
+	table := DeliveryHistoryTable{TableName: "historyTableName", Client: mockDdbClient}
+
+	mockDdbClient.On("BatchWriteItem", mock.Anything).Return(&dynamodb.BatchWriteItemOutput{}, nil).Once()
+
+	dispatchedAt := time.Date(2020, 6, 17, 15, 49, 40, 500000000, time.UTC)
+	require.NoError(t, table.PutDeliveryAttempts([]*models.DeliveryAttempt{
+		{AlertID: "alertId", OutputID: "output-1", DispatchedAt: time.Now()},
+		{AlertID: "alertId", OutputID: "output-2", DispatchedAt: dispatchedAt},
+	}))
+
+	request := mockDdbClient.Calls[0].Arguments.Get(0).(*dynamodb.BatchWriteItemInput)
+	items := request.RequestItems["historyTableName"]
+	require.Len(t, items, 2)
+	assert.Equal(t, aws.String("output-2"), items[1].PutRequest.Item["outputId"].S)
+	assert.NotNil(t, items[1].PutRequest.Item["expiresAt"].N)
+	assert.Equal(t, aws.String("2020-06-17T15:49:40.500000000Z"), items[1].PutRequest.Item["dispatchedAt"].S)
+	assert.Equal(t, aws.String("2020-06-17T15:49:40.500000000Z#output-2"), items[1].PutRequest.Item["attemptKey"].S)
+	mockDdbClient.AssertExpectations(t)
+}
+
+func TestPutDeliveryAttemptsEmpty(t *testing.T) {
+	mockDdbClient := &testutils.DynamoDBMock{}
+	table := DeliveryHistoryTable{TableName: "historyTableName", Client: mockDdbClient}
+
+	require.NoError(t, table.PutDeliveryAttempts(nil))
+	mockDdbClient.AssertExpectations(t)
+}
+
+func TestBuildDeliveryQueryByOutput(t *testing.T) {
+	table := DeliveryHistoryTable{TableName: "historyTableName", OutputIDDispatchIndexName: "outputIndex"}
+
+	after := time.Date(2020, 6, 17, 0, 0, 0, 0, time.UTC)
+	query, err := table.buildDeliveryQuery(&models.ListDeliveryAttemptsInput{
+		OutputID:          aws.String("output-id"),
+		DispatchedAtAfter: &after,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, aws.String("outputIndex"), query.IndexName)
+	assert.Equal(t, aws.Bool(false), query.ScanIndexForward)
+	assert.Equal(t, aws.String("(#0 = :0) AND (#1 >= :1)"), query.KeyConditionExpression)
+	assert.Equal(t, aws.String("2020-06-17T00:00:00.000000000Z"), query.ExpressionAttributeValues[":1"].S)
+	assert.Nil(t, query.FilterExpression)
+}
+
+func TestBuildDeliveryQueryByAlert(t *testing.T) {
+	table := DeliveryHistoryTable{TableName: "historyTableName", OutputIDDispatchIndexName: "outputIndex"}
+
+	before := time.Date(2020, 6, 17, 0, 0, 0, 0, time.UTC)
+	query, err := table.buildDeliveryQuery(&models.ListDeliveryAttemptsInput{
+		AlertID:            aws.String("alertId"),
+		DispatchedAtBefore: &before,
+		ExclusiveStartKey:  aws.String(`{"alertId":{"S":"alertId"},"attemptKey":{"S":"key"}}`),
+	})
+	require.NoError(t, err)
+	assert.Nil(t, query.IndexName)
+	assert.Equal(t, aws.String("#1 = :1"), query.KeyConditionExpression)
+	assert.Equal(t, aws.String("#0 <= :0"), query.FilterExpression)
+	assert.Equal(t, aws.String("key"), query.ExclusiveStartKey["attemptKey"].S)
+}
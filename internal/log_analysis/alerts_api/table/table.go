@@ -86,17 +86,21 @@ type DynamoItem = map[string]*dynamodb.AttributeValue
 
 // AlertItem is a DDB representation of an Alert
 type AlertItem struct {
-	AlertID             string                     `json:"id"`
-	Type                string                     `json:"type"`
-	RuleID              string                     `json:"ruleId"`
-	RuleVersion         string                     `json:"ruleVersion"`
-	RuleDisplayName     *string                    `json:"ruleDisplayName"`
-	Title               string                     `json:"title"`
-	Description         *string                    `json:"description"`
-	Reference           *string                    `json:"reference"`
-	Runbook             *string                    `json:"runbook"`
-	Destinations        []string                   `json:"destinations,omitempty" validate:"dive,uuid4"`
-	DedupString         string                     `json:"dedup"`
+	AlertID         string   `json:"id"`
+	Type            string   `json:"type"`
+	RuleID          string   `json:"ruleId"`
+	RuleVersion     string   `json:"ruleVersion"`
+	RuleDisplayName *string  `json:"ruleDisplayName"`
+	Title           string   `json:"title"`
+	Description     *string  `json:"description"`
+	Reference       *string  `json:"reference"`
+	Runbook         *string  `json:"runbook"`
+	Destinations    []string `json:"destinations,omitempty" validate:"dive,uuid4"`
+	DedupString     string   `json:"dedup"`
+	DedupAlertIDs   []string `json:"dedupAlertIds"`
+	// EventsSince and EventsUntil - bound the events of an alert which was split, see models.Alert.EventsSince
+	EventsSince         *time.Time                 `json:"eventsSince,omitempty"`
+	EventsUntil         *time.Time                 `json:"eventsUntil,omitempty"`
 	FirstEventMatchTime time.Time                  `json:"firstEventMatchTime"`
 	CreationTime        time.Time                  `json:"creationTime"`
 	DeliveryResponses   []*models.DeliveryResponse `json:"deliveryResponses"`
@@ -106,6 +110,8 @@ type AlertItem struct {
 	Status     string    `json:"status"`
 	EventCount int       `json:"eventCount"`
 	LogTypes   []string  `json:"logTypes"`
+	// NoStoredEvents - the events of the alert are not stored in S3, so there is nothing to search for
+	NoStoredEvents bool `json:"noStoredEvents"`
 	// LastUpdatedBy - stores the UserID of the last person who modified the Alert
 	LastUpdatedBy string `json:"lastUpdatedBy"`
 	// LastUpdatedByTime - stores the timestamp of the last person who modified the Alert
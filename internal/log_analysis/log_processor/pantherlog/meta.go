@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -72,6 +73,7 @@ const (
 	FieldAWSTag
 	FieldEmail
 	FieldUsername
+	FieldPort
 )
 
 // ScanValues implements ValueScanner interface
@@ -79,6 +81,15 @@ func (id FieldID) ScanValues(w ValueWriter, input string) {
 	w.WriteValues(id, strings.TrimSpace(input))
 }
 
+// WritePorts writes non-zero port numbers to the port indicator field
+func WritePorts(w ValueWriter, ports ...Uint16) {
+	for _, port := range ports {
+		if port.Exists && port.Value != 0 {
+			w.WriteValues(FieldPort, strconv.FormatUint(uint64(port.Value), 10))
+		}
+	}
+}
+
 // CoreFields are the 'core' fields Panther adds to each log.
 // External modules cannot add core fields.
 type CoreFields struct {
@@ -210,6 +221,11 @@ func init() {
 		NameJSON:    "p_any_usernames",
 		Description: "Panther added field with collection of usernames associated with the row",
 	})
+	MustRegisterIndicator(FieldPort, FieldMeta{
+		Name:        "PantherAnyPorts",
+		NameJSON:    "p_any_ports",
+		Description: "Panther added field with collection of network ports associated with the row",
+	})
 	MustRegisterScannerFunc("ip", ScanIPAddress, FieldIPAddress)
 	MustRegisterScannerFunc("domain", ScanDomainName, FieldDomainName)
 	MustRegisterScannerFunc("md5", ScanMD5Hash, FieldMD5Hash)
@@ -0,0 +1,47 @@
+package atlassianlogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes"
+)
+
+func LogTypes() logtypes.Group {
+	return logTypes
+}
+
+// nolint: lll
+var logTypes = logtypes.Must("Atlassian",
+	logtypes.ConfigJSON{
+		Name:         TypeJiraAudit,
+		Description:  `Jira audit records of changes to users, groups, permissions, projects and workflows.`,
+		ReferenceURL: `https://developer.atlassian.com/cloud/jira/platform/rest/v2/api-group-audit-records/`,
+		NewEvent: func() interface{} {
+			return &JiraAudit{}
+		},
+	},
+	logtypes.ConfigJSON{
+		Name:         TypeConfluenceAudit,
+		Description:  `Confluence audit records of changes to users, groups, permissions, spaces and global settings.`,
+		ReferenceURL: `https://developer.atlassian.com/cloud/confluence/rest/v1/api-group-audit/`,
+		NewEvent: func() interface{} {
+			return &ConfluenceAudit{}
+		},
+	},
+)
@@ -0,0 +1,65 @@
+package atlassianlogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+const TypeConfluenceAudit = `Atlassian.ConfluenceAudit`
+
+// nolint:lll
+type ConfluenceAudit struct {
+	Author            *ConfluenceUser          `json:"author" description:"The user who made the change."`
+	RemoteAddress     pantherlog.String        `json:"remoteAddress" panther:"ip" description:"The IP address of the user that made the change."`
+	CreationDate      pantherlog.Time          `json:"creationDate" validate:"required" tcodec:"unix_ms" event_time:"true" description:"The date and time on which the audit record was created."`
+	Summary           pantherlog.String        `json:"summary" validate:"required" description:"The summary of the audit record."`
+	Description       pantherlog.String        `json:"description" description:"The description of the audit record."`
+	Category          pantherlog.String        `json:"category" description:"The category of the audit record (e.g. Permissions, Users and groups, Spaces)."`
+	SysAdmin          pantherlog.Bool          `json:"sysAdmin" description:"Whether the change was made by a system administrator."`
+	SuperAdmin        pantherlog.Bool          `json:"superAdmin" description:"Whether the change was made by a super administrator."`
+	AffectedObject    *ConfluenceObject        `json:"affectedObject" description:"The object the change was made to."`
+	ChangedValues     []ConfluenceChangedValue `json:"changedValues" description:"The list of values changed in the record event."`
+	AssociatedObjects []ConfluenceObject       `json:"associatedObjects" description:"The list of objects associated with the changed record."`
+}
+
+// nolint:lll
+type ConfluenceUser struct {
+	Type        pantherlog.String `json:"type" description:"The type of the user (user, anonymous, known)."`
+	DisplayName pantherlog.String `json:"displayName" description:"The display name of the user."`
+	PublicName  pantherlog.String `json:"publicName" description:"The public name of the user."`
+	Username    pantherlog.String `json:"username" panther:"username" description:"The username of the user (Confluence Server)."`
+	UserKey     pantherlog.String `json:"userKey" panther:"username" description:"The key of the user (Confluence Server)."`
+	AccountID   pantherlog.String `json:"accountId" panther:"username" description:"The account ID of the user."`
+	AccountType pantherlog.String `json:"accountType" description:"The account type of the user (atlassian, app)."`
+	Email       pantherlog.String `json:"email" panther:"email" description:"The email address of the user, if visible."`
+}
+
+// nolint:lll
+type ConfluenceObject struct {
+	Name       pantherlog.String `json:"name" description:"The name of the object."`
+	ObjectType pantherlog.String `json:"objectType" description:"The type of the object."`
+}
+
+// nolint:lll
+type ConfluenceChangedValue struct {
+	Name     pantherlog.String `json:"name" description:"The name of the value changed."`
+	OldValue pantherlog.String `json:"oldValue" description:"The value before the change."`
+	NewValue pantherlog.String `json:"newValue" description:"The value after the change."`
+}
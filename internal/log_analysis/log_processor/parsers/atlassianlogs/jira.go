@@ -0,0 +1,57 @@
+package atlassianlogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+const TypeJiraAudit = `Atlassian.JiraAudit`
+
+// nolint:lll
+type JiraAudit struct {
+	ID              pantherlog.Int64   `json:"id" validate:"required" description:"The ID of the audit record."`
+	Summary         pantherlog.String  `json:"summary" validate:"required" description:"The summary of the audit record."`
+	RemoteAddress   pantherlog.String  `json:"remoteAddress" panther:"ip" description:"The IP address of the user that made the change."`
+	AuthorKey       pantherlog.String  `json:"authorKey" panther:"username" description:"The key of the user who made the change (deprecated in favor of authorAccountId)."`
+	AuthorAccountID pantherlog.String  `json:"authorAccountId" panther:"username" description:"The account ID of the user who made the change."`
+	Created         pantherlog.Time    `json:"created" validate:"required" tcodec:"layout=2006-01-02T15:04:05.999-0700" event_time:"true" description:"The date and time on which the audit record was created."`
+	Category        pantherlog.String  `json:"category" description:"The category of the audit record (e.g. user management, group management, permissions)."`
+	EventSource     pantherlog.String  `json:"eventSource" description:"The event the audit record originated from."`
+	Description     pantherlog.String  `json:"description" description:"The description of the audit record."`
+	ObjectItem      *JiraAuditItem     `json:"objectItem" description:"The object the change was made to."`
+	ChangedValues   []JiraChangedValue `json:"changedValues" description:"The list of values changed in the record event."`
+	AssociatedItems []JiraAuditItem    `json:"associatedItems" description:"The list of items associated with the changed record."`
+}
+
+// nolint:lll
+type JiraAuditItem struct {
+	ID         pantherlog.String `json:"id" description:"The ID of the associated record."`
+	Name       pantherlog.String `json:"name" description:"The name of the associated record."`
+	TypeName   pantherlog.String `json:"typeName" description:"The type of the associated record."`
+	ParentID   pantherlog.String `json:"parentId" description:"The ID of the parent of the associated record."`
+	ParentName pantherlog.String `json:"parentName" description:"The name of the parent of the associated record."`
+}
+
+// nolint:lll
+type JiraChangedValue struct {
+	FieldName   pantherlog.String `json:"fieldName" description:"The name of the field changed."`
+	ChangedFrom pantherlog.String `json:"changedFrom" panther:"email" description:"The value of the field before the change."`
+	ChangedTo   pantherlog.String `json:"changedTo" panther:"email" description:"The value of the field after the change."`
+}
@@ -20,20 +20,32 @@ package awslogs
  */
 
 import (
+	"time"
+
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog/tcodec"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers"
 )
 
 const (
 	TypeALB               = "AWS.ALB"
 	TypeAuroraMySQLAudit  = `AWS.AuroraMySQLAudit`
+	TypeCloudFront        = "AWS.CloudFront"
 	TypeCloudTrail        = `AWS.CloudTrail`
 	TypeCloudTrailDigest  = "AWS.CloudTrailDigest"
 	TypeCloudTrailInsight = "AWS.CloudTrailInsight"
 	TypeCloudWatchEvents  = "AWS.CloudWatchEvents"
+	TypeConfigItem        = "AWS.ConfigConfigurationItem"
+	TypeConfigNotify      = "AWS.ConfigNotification"
+	TypeELBClassic        = "AWS.ELBClassic"
 	TypeGuardDuty         = "AWS.GuardDuty"
+	TypeInspector         = "AWS.InspectorFinding"
+	TypeNetFirewallAlert  = "AWS.NetworkFirewallAlert"
+	TypeNetFirewallFlow   = "AWS.NetworkFirewallFlow"
+	TypeRDSPostgreSQL     = "AWS.RDSPostgreSQL"
 	TypeS3ServerAccess    = "AWS.S3ServerAccess"
+	TypeSecurityHub       = "AWS.SecurityHubFinding"
 	TypeVPCDns            = "AWS.VPCDns"
 	TypeVPCFlow           = "AWS.VPCFlow"
 	TypeWAFWebACL         = "AWS.WAFWebACL"
@@ -44,91 +56,163 @@ func LogTypes() logtypes.Group {
 	return logTypes
 }
 
+// We use an immediately called function to register the time decoder before building the logtype entries.
 // nolint:lll
-var logTypes = logtypes.Must("AWS",
-	logtypes.Config{
-		Name:         TypeALB,
-		Description:  `Application Load Balancer logs Layer 7 network logs for your application load balancer.`,
-		ReferenceURL: `https://docs.aws.amazon.com/elasticloadbalancing/latest/application/load-balancer-access-logs.html`,
-		Schema:       ALB{},
-		NewParser:    parsers.AdapterFactory(&ALBParser{}),
-	},
-	logtypes.Config{
-		Name:         TypeAuroraMySQLAudit,
-		Description:  `AuroraMySQLAudit is an RDS Aurora audit log which contains context around database calls.`,
-		ReferenceURL: `https://docs.aws.amazon.com/AmazonRDS/latest/AuroraUserGuide/AuroraMySQL.Auditing.html`,
-		Schema:       AuroraMySQLAudit{},
-		NewParser:    parsers.AdapterFactory(&AuroraMySQLAuditParser{}),
-	},
-	logtypes.Config{
-		Name:         TypeCloudTrail,
-		Description:  `AWSCloudTrail represents the content of a CloudTrail S3 object.`,
-		ReferenceURL: `https://docs.aws.amazon.com/awscloudtrail/latest/userguide/cloudtrail-event-reference.html`,
-		Schema:       mustBuildEventSchema(CloudTrail{}),
-		NewParser:    pantherlog.FactoryFunc(newCloudTrailParser),
-	},
-	logtypes.ConfigJSON{
-		Name:         TypeCloudTrailDigest,
-		Description:  `AWSCloudTrailDigest contains the names of the log files that were delivered to your Amazon S3 bucket during the last hour, the hash values for those log files, and the signature of the previous digest file.`,
-		ReferenceURL: `https://docs.aws.amazon.com/awscloudtrail/latest/userguide/cloudtrail-log-file-validation-digest-file-structure.html`,
-		NewEvent: func() interface{} {
-			return &CloudTrailDigest{}
-		},
-	},
-	logtypes.Config{
-		Name:         TypeCloudTrailInsight,
-		Description:  `AWSCloudTrailInsight represents the content of a CloudTrail Insight event record S3 object.`,
-		ReferenceURL: `https://docs.aws.amazon.com/awscloudtrail/latest/userguide/cloudtrail-event-reference.html`,
-		Schema:       mustBuildEventSchema(CloudTrailInsight{}),
-		NewParser: pantherlog.FactoryFunc(func(_ interface{}) (parsers.Interface, error) {
-			return &CloudTrailInsightParser{}, nil
-		}),
-	},
-	logtypes.Config{
-		Name:         TypeCloudWatchEvents,
-		Description:  `Amazon CloudWatch Events describe a change in Amazon Web Services (AWS) resources.`,
-		ReferenceURL: `https://docs.aws.amazon.com/AmazonCloudWatch/latest/events/CloudWatchEventsandEventPatterns.html`,
-		Schema:       CloudWatchEvent{},
-		NewParser:    parsers.AdapterFactory(&CloudWatchEventParser{}),
-	},
-	logtypes.Config{
-		Name:         TypeGuardDuty,
-		Description:  `Amazon GuardDuty is a threat detection service that continuously monitors for malicious activity and unauthorized behavior inside AWS Accounts.`,
-		ReferenceURL: `https://docs.aws.amazon.com/guardduty/latest/ug/guardduty_finding-format.html`,
-		Schema:       GuardDuty{},
-		NewParser:    parsers.AdapterFactory(&GuardDutyParser{}),
-	},
-	logtypes.Config{
-		Name:         TypeS3ServerAccess,
-		Description:  `S3ServerAccess is an AWS S3 Access Log.`,
-		ReferenceURL: `https://docs.aws.amazon.com/AmazonS3/latest/dev/LogFormat.html`,
-		Schema:       S3ServerAccess{},
-		NewParser:    parsers.AdapterFactory(&S3ServerAccessParser{}),
-	},
-	logtypes.ConfigJSON{
-		Name:         TypeVPCDns,
-		Description:  `DNS query logs of the queries that VPC DNS resolvers forward to Route 53.`,
-		ReferenceURL: `https://docs.aws.amazon.com/Route53/latest/DeveloperGuide/resolver-query-logs-format.html`,
-		NewEvent: func() interface{} {
-			return &VPCDns{}
-		},
-		ExtraIndicators: pantherlog.FieldSet{ // these are not present in the struct but added by the extractors
-			pantherlog.FieldDomainName,
-		},
-	},
-	logtypes.Config{
-		Name:         TypeVPCFlow,
-		Description:  `VPCFlow is a VPC NetFlow log, which is a layer 3 representation of network traffic in EC2.`,
-		ReferenceURL: `https://docs.aws.amazon.com/vpc/latest/userguide/flow-logs-records-examples.html`,
-		Schema:       VPCFlow{},
-		NewParser:    parsers.AdapterFactory(&VPCFlowParser{}),
-	},
-	logtypes.ConfigJSON{
-		Name:         TypeWAFWebACL,
-		Description:  `WAF Web ACL traffic information logs.`,
-		ReferenceURL: `https://docs.aws.amazon.com/waf/latest/developerguide/logging.html`,
-		NewEvent: func() interface{} {
-			return &WAFWebACL{}
-		},
-	},
-)
+var logTypes = func() logtypes.Group {
+	tcodec.MustRegister(`inspector`, tcodec.Join(
+		&inspectorTimeDecoder{},
+		tcodec.LayoutCodec(time.RFC3339), // encoder
+	))
+	return logtypes.Must("AWS",
+		logtypes.Config{
+			Name:         TypeALB,
+			Description:  `Application Load Balancer logs Layer 7 network logs for your application load balancer.`,
+			ReferenceURL: `https://docs.aws.amazon.com/elasticloadbalancing/latest/application/load-balancer-access-logs.html`,
+			Schema:       ALB{},
+			NewParser:    parsers.AdapterFactory(&ALBParser{}),
+		},
+		logtypes.Config{
+			Name:         TypeAuroraMySQLAudit,
+			Description:  `AuroraMySQLAudit is an RDS Aurora audit log which contains context around database calls.`,
+			ReferenceURL: `https://docs.aws.amazon.com/AmazonRDS/latest/AuroraUserGuide/AuroraMySQL.Auditing.html`,
+			Schema:       AuroraMySQLAudit{},
+			NewParser:    parsers.AdapterFactory(&AuroraMySQLAuditParser{}),
+		},
+		logtypes.Config{
+			Name:         TypeCloudFront,
+			Description:  `CloudFront standard logs contain detailed information about every user request that CloudFront receives.`,
+			ReferenceURL: `https://docs.aws.amazon.com/AmazonCloudFront/latest/DeveloperGuide/AccessLogs.html`,
+			Schema:       mustBuildEventSchema(CloudFront{}),
+			NewParser:    pantherlog.FactoryFunc(newCloudFrontParser),
+		},
+		logtypes.Config{
+			Name:         TypeCloudTrail,
+			Description:  `AWSCloudTrail represents the content of a CloudTrail S3 object.`,
+			ReferenceURL: `https://docs.aws.amazon.com/awscloudtrail/latest/userguide/cloudtrail-event-reference.html`,
+			Schema:       mustBuildEventSchema(CloudTrail{}),
+			NewParser:    pantherlog.FactoryFunc(newCloudTrailParser),
+		},
+		logtypes.ConfigJSON{
+			Name:         TypeCloudTrailDigest,
+			Description:  `AWSCloudTrailDigest contains the names of the log files that were delivered to your Amazon S3 bucket during the last hour, the hash values for those log files, and the signature of the previous digest file.`,
+			ReferenceURL: `https://docs.aws.amazon.com/awscloudtrail/latest/userguide/cloudtrail-log-file-validation-digest-file-structure.html`,
+			NewEvent: func() interface{} {
+				return &CloudTrailDigest{}
+			},
+		},
+		logtypes.Config{
+			Name:         TypeCloudTrailInsight,
+			Description:  `AWSCloudTrailInsight represents the content of a CloudTrail Insight event record S3 object.`,
+			ReferenceURL: `https://docs.aws.amazon.com/awscloudtrail/latest/userguide/cloudtrail-event-reference.html`,
+			Schema:       mustBuildEventSchema(CloudTrailInsight{}),
+			NewParser: pantherlog.FactoryFunc(func(_ interface{}) (parsers.Interface, error) {
+				return &CloudTrailInsightParser{}, nil
+			}),
+		},
+		logtypes.Config{
+			Name:         TypeCloudWatchEvents,
+			Description:  `Amazon CloudWatch Events describe a change in Amazon Web Services (AWS) resources.`,
+			ReferenceURL: `https://docs.aws.amazon.com/AmazonCloudWatch/latest/events/CloudWatchEventsandEventPatterns.html`,
+			Schema:       CloudWatchEvent{},
+			NewParser:    parsers.AdapterFactory(&CloudWatchEventParser{}),
+		},
+		logtypes.Config{
+			Name:         TypeConfigItem,
+			Description:  `AWS Config configuration items from configuration history and snapshot files, SNS notifications or EventBridge events.`,
+			ReferenceURL: `https://docs.aws.amazon.com/config/latest/developerguide/config-concepts.html#config-items`,
+			Schema:       mustBuildEventSchema(ConfigConfigurationItem{}),
+			NewParser:    newEventBridgeParser(TypeConfigItem, readConfigConfigurationItems),
+		},
+		logtypes.Config{
+			Name:         TypeConfigNotify,
+			Description:  `AWS Config notifications about configuration changes, rule compliance changes and delivery status, sent to SNS or EventBridge.`,
+			ReferenceURL: `https://docs.aws.amazon.com/config/latest/developerguide/notifications-for-AWS-Config.html`,
+			Schema:       mustBuildEventSchema(ConfigNotification{}),
+			NewParser:    newEventBridgeParser(TypeConfigNotify, readConfigNotification),
+		},
+		logtypes.Config{
+			Name:         TypeELBClassic,
+			Description:  `Classic Load Balancer access logs capture detailed information about requests sent to the load balancer.`,
+			ReferenceURL: `https://docs.aws.amazon.com/elasticloadbalancing/latest/classic/access-log-collection.html`,
+			Schema:       mustBuildEventSchema(ELBClassic{}),
+			NewParser:    pantherlog.FactoryFunc(newELBClassicParser),
+		},
+		logtypes.Config{
+			Name:         TypeGuardDuty,
+			Description:  `Amazon GuardDuty is a threat detection service that continuously monitors for malicious activity and unauthorized behavior inside AWS Accounts.`,
+			ReferenceURL: `https://docs.aws.amazon.com/guardduty/latest/ug/guardduty_finding-format.html`,
+			Schema:       GuardDuty{},
+			NewParser:    parsers.AdapterFactory(&GuardDutyParser{}),
+		},
+		logtypes.Config{
+			Name:         TypeInspector,
+			Description:  `Amazon Inspector findings for software vulnerabilities and unintended network exposure of EC2 instances and container images.`,
+			ReferenceURL: `https://docs.aws.amazon.com/inspector/latest/user/findings-understanding.html`,
+			Schema:       mustBuildEventSchema(InspectorFinding{}),
+			NewParser:    newEventBridgeParser(TypeInspector, readInspectorFinding),
+		},
+		logtypes.ConfigJSON{
+			Name:         TypeNetFirewallAlert,
+			Description:  `AWS Network Firewall alert logs report traffic that matches stateful rules with a drop or alert action.`,
+			ReferenceURL: `https://docs.aws.amazon.com/network-firewall/latest/developerguide/firewall-logging.html`,
+			NewEvent: func() interface{} {
+				return &NetworkFirewallAlert{}
+			},
+		},
+		logtypes.ConfigJSON{
+			Name:         TypeNetFirewallFlow,
+			Description:  `AWS Network Firewall flow logs provide standard network traffic flow information for traffic forwarded to the stateful engine.`,
+			ReferenceURL: `https://docs.aws.amazon.com/network-firewall/latest/developerguide/firewall-logging.html`,
+			NewEvent: func() interface{} {
+				return &NetworkFirewallFlow{}
+			},
+		},
+		logtypes.Config{
+			Name:         TypeRDSPostgreSQL,
+			Description:  `RDS PostgreSQL database logs, including pgaudit session and object audit records.`,
+			ReferenceURL: `https://docs.aws.amazon.com/AmazonRDS/latest/UserGuide/USER_LogAccess.Concepts.PostgreSQL.html`,
+			Schema:       mustBuildEventSchema(RDSPostgreSQLLog{}),
+			NewParser:    pantherlog.FactoryFunc(newRDSPostgreSQLParser),
+		},
+		logtypes.Config{
+			Name:         TypeS3ServerAccess,
+			Description:  `S3ServerAccess is an AWS S3 Access Log.`,
+			ReferenceURL: `https://docs.aws.amazon.com/AmazonS3/latest/dev/LogFormat.html`,
+			Schema:       S3ServerAccess{},
+			NewParser:    parsers.AdapterFactory(&S3ServerAccessParser{}),
+		},
+		logtypes.Config{
+			Name:         TypeSecurityHub,
+			Description:  `AWS Security Hub findings in the AWS Security Finding Format (ASFF), either raw or as delivered by EventBridge.`,
+			ReferenceURL: `https://docs.aws.amazon.com/securityhub/latest/userguide/securityhub-findings-format.html`,
+			Schema:       mustBuildEventSchema(SecurityHubFinding{}),
+			NewParser:    newEventBridgeParser(TypeSecurityHub, readSecurityHubFindings),
+		},
+		logtypes.ConfigJSON{
+			Name:         TypeVPCDns,
+			Description:  `DNS query logs of the queries that VPC DNS resolvers forward to Route 53.`,
+			ReferenceURL: `https://docs.aws.amazon.com/Route53/latest/DeveloperGuide/resolver-query-logs-format.html`,
+			NewEvent: func() interface{} {
+				return &VPCDns{}
+			},
+			ExtraIndicators: pantherlog.FieldSet{ // these are not present in the struct but added by the extractors
+				pantherlog.FieldDomainName,
+			},
+		},
+		logtypes.Config{
+			Name:         TypeVPCFlow,
+			Description:  `VPCFlow is a VPC NetFlow log, which is a layer 3 representation of network traffic in EC2.`,
+			ReferenceURL: `https://docs.aws.amazon.com/vpc/latest/userguide/flow-logs-records-examples.html`,
+			Schema:       VPCFlow{},
+			NewParser:    parsers.AdapterFactory(&VPCFlowParser{}),
+		},
+		logtypes.ConfigJSON{
+			Name:         TypeWAFWebACL,
+			Description:  `WAF Web ACL traffic information logs.`,
+			ReferenceURL: `https://docs.aws.amazon.com/waf/latest/developerguide/logging.html`,
+			NewEvent: func() interface{} {
+				return &WAFWebACL{}
+			},
+		},
+	)
+}()
@@ -0,0 +1,185 @@
+package awslogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+// CloudFront is a CloudFront standard (access) log entry.
+// The log files use the W3C extended log file format with a `#Fields` header listing the columns of each line.
+// nolint:lll,maligned
+type CloudFront struct {
+	Timestamp              pantherlog.Time    `json:"timestamp" validate:"required" tcodec:"rfc3339" event_time:"true" description:"The date and time (UTC) on which the event occurred (combines the 'date' and 'time' fields)."`
+	EdgeLocation           pantherlog.String  `json:"x_edge_location" description:"The edge location that served the request. Each edge location is identified by a three-letter code and an arbitrarily assigned number (for example, DFW3)."`
+	ServerBytes            pantherlog.Int64   `json:"sc_bytes" description:"The total number of bytes that CloudFront served to the viewer in response to the request, including headers."`
+	ClientIP               pantherlog.String  `json:"c_ip" validate:"required" panther:"ip" description:"The IP address of the viewer that made the request."`
+	Method                 pantherlog.String  `json:"cs_method" description:"The HTTP request method."`
+	Host                   pantherlog.String  `json:"cs_host" panther:"domain" description:"The domain name of the CloudFront distribution (for example, d111111abcdef8.cloudfront.net)."`
+	URIStem                pantherlog.String  `json:"cs_uri_stem" description:"The portion of the request URL that identifies the path and object (for example, /images/cat.jpg)."`
+	Status                 pantherlog.Int16   `json:"sc_status" description:"The HTTP status code of the server's response. A value of 000 indicates that the viewer closed the connection before the server could respond."`
+	Referer                pantherlog.String  `json:"cs_referer" description:"The value of the Referer header in the request."`
+	UserAgent              pantherlog.String  `json:"cs_user_agent" description:"The value of the User-Agent header in the request."`
+	URIQuery               pantherlog.String  `json:"cs_uri_query" description:"The query string portion of the request URL, if any."`
+	Cookie                 pantherlog.String  `json:"cs_cookie" description:"The Cookie header in the request, including name-value pairs and the associated attributes."`
+	EdgeResultType         pantherlog.String  `json:"x_edge_result_type" description:"How the server classified the response after the last byte left the server (Hit, RefreshHit, Miss, LimitExceeded, CapacityExceeded, Error or Redirect)."`
+	EdgeRequestID          pantherlog.String  `json:"x_edge_request_id" panther:"trace_id" description:"An opaque string that uniquely identifies a request."`
+	HostHeader             pantherlog.String  `json:"x_host_header" panther:"domain" description:"The value that the viewer included in the Host header of the request."`
+	Protocol               pantherlog.String  `json:"cs_protocol" description:"The protocol of the viewer request (http, https, ws or wss)."`
+	ClientBytes            pantherlog.Int64   `json:"cs_bytes" description:"The total number of bytes of data that the viewer included in the request, including headers."`
+	TimeTaken              pantherlog.Float64 `json:"time_taken" description:"The number of seconds (to the thousandth of a second) between the time the server receives the viewer's request and the time it writes the last byte of the response to the output queue."`
+	ForwardedFor           pantherlog.String  `json:"x_forwarded_for" description:"If the viewer used an HTTP proxy or a load balancer to send the request, the value of the c-ip field is the IP address of the proxy or load balancer. In that case, this field is the IP address of the viewer that originated the request."`
+	SSLProtocol            pantherlog.String  `json:"ssl_protocol" description:"When the request used HTTPS, this field contains the SSL/TLS protocol that the viewer and server negotiated for transmitting the request and response."`
+	SSLCipher              pantherlog.String  `json:"ssl_cipher" description:"When the request used HTTPS, this field contains the SSL/TLS cipher that the viewer and server negotiated for encrypting the request and response."`
+	EdgeResponseResultType pantherlog.String  `json:"x_edge_response_result_type" description:"How the server classified the response just before returning the response to the viewer."`
+	ProtocolVersion        pantherlog.String  `json:"cs_protocol_version" description:"The HTTP version that the viewer specified in the request."`
+	FLEStatus              pantherlog.String  `json:"fle_status" description:"When field-level encryption is configured for a distribution, this field contains a code that indicates whether the request body was successfully processed."`
+	FLEEncryptedFields     pantherlog.Int64   `json:"fle_encrypted_fields" description:"The number of field-level encryption fields that the server encrypted and forwarded to the origin."`
+	ClientPort             pantherlog.Uint16  `json:"c_port" description:"The port number of the request from the viewer."`
+	TimeToFirstByte        pantherlog.Float64 `json:"time_to_first_byte" description:"The number of seconds between receiving the request and writing the first byte of the response, as measured on the server."`
+	EdgeDetailedResultType pantherlog.String  `json:"x_edge_detailed_result_type" description:"When the x-edge-result-type field is not Error, this field contains the same value as x-edge-result-type. When the x-edge-result-type field is Error, this field contains the specific type of error."`
+	ContentType            pantherlog.String  `json:"sc_content_type" description:"The value of the HTTP Content-Type header of the response."`
+	ContentLength          pantherlog.Int64   `json:"sc_content_len" description:"The value of the HTTP Content-Length header of the response."`
+	RangeStart             pantherlog.Int64   `json:"sc_range_start" description:"When the response contains the HTTP Content-Range header, this field contains the range start value."`
+	RangeEnd               pantherlog.Int64   `json:"sc_range_end" description:"When the response contains the HTTP Content-Range header, this field contains the range end value."`
+}
+
+var _ pantherlog.ValueWriterTo = (*CloudFront)(nil)
+
+func (event *CloudFront) WriteValuesTo(w pantherlog.ValueWriter) {
+	// CloudFront URL-encodes spaces in field values (`192.0.2.1,%20198.51.100.1`)
+	forwardedFor, err := url.PathUnescape(event.ForwardedFor.Value)
+	if err != nil {
+		forwardedFor = event.ForwardedFor.Value
+	}
+	for _, addr := range strings.Split(forwardedFor, ",") {
+		pantherlog.ScanIPAddress(w, strings.TrimSpace(addr))
+	}
+}
+
+// cloudFrontFields are the default fields of CloudFront standard log files.
+// They are used until a `#Fields` directive is read from the log file.
+var cloudFrontFields = []string{
+	"date",
+	"time",
+	"x-edge-location",
+	"sc-bytes",
+	"c-ip",
+	"cs-method",
+	"cs(Host)",
+	"cs-uri-stem",
+	"sc-status",
+	"cs(Referer)",
+	"cs(User-Agent)",
+	"cs-uri-query",
+	"cs(Cookie)",
+	"x-edge-result-type",
+	"x-edge-request-id",
+	"x-host-header",
+	"cs-protocol",
+	"cs-bytes",
+	"time-taken",
+	"x-forwarded-for",
+	"ssl-protocol",
+	"ssl-cipher",
+	"x-edge-response-result-type",
+	"cs-protocol-version",
+	"fle-status",
+	"fle-encrypted-fields",
+	"c-port",
+	"time-to-first-byte",
+	"x-edge-detailed-result-type",
+	"sc-content-type",
+	"sc-content-len",
+	"sc-range-start",
+	"sc-range-end",
+}
+
+// cloudFrontParser converts W3C log lines to JSON and parses them as CloudFront events.
+// It keeps track of the `#Fields` directive so that it can handle log files with a custom selection of fields.
+type cloudFrontParser struct {
+	*textParser
+	columns []string
+}
+
+func newCloudFrontParser(_ interface{}) (pantherlog.LogParser, error) {
+	parser, err := newTextParser(TypeCloudFront, func() interface{} {
+		return &CloudFront{}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &cloudFrontParser{
+		textParser: parser,
+		columns:    cloudFrontFields,
+	}, nil
+}
+
+var _ pantherlog.LogParser = (*cloudFrontParser)(nil)
+
+// ParseLog implements pantherlog.LogParser interface
+func (p *cloudFrontParser) ParseLog(log string) ([]*pantherlog.Result, error) {
+	const (
+		versionDirective = "#Version:"
+		fieldsDirective  = "#Fields:"
+	)
+	log = strings.TrimSpace(log)
+	switch {
+	case strings.HasPrefix(log, fieldsDirective):
+		p.columns = strings.Fields(strings.TrimPrefix(log, fieldsDirective))
+		return nil, nil
+	case strings.HasPrefix(log, versionDirective):
+		return nil, nil
+	case strings.HasPrefix(log, "#"):
+		// Only W3C directives are skipped, so that comment lines of other log types are not claimed by this parser
+		return nil, errors.New("invalid CloudFront directive")
+	}
+	values := strings.Split(log, "\t")
+	if len(values) < 2 || len(values) > len(p.columns) {
+		return nil, errors.Errorf("invalid number of columns %d", len(values))
+	}
+	p.reset()
+	var date, tm string
+	for i, value := range values {
+		switch name := p.columns[i]; name {
+		case "date":
+			date = value
+		case "time":
+			tm = value
+		default:
+			p.set(cloudFrontFieldName(name), value)
+		}
+	}
+	if date != "" && tm != "" {
+		p.set("timestamp", date+"T"+tm+"Z")
+	}
+	return p.parse()
+}
+
+var cloudFrontFieldReplacer = strings.NewReplacer("-", "_", "(", "_", ")", "")
+
+// cloudFrontFieldName converts W3C field names to column names (`cs(User-Agent)` -> `cs_user_agent`)
+func cloudFrontFieldName(name string) string {
+	return cloudFrontFieldReplacer.Replace(strings.ToLower(name))
+}
@@ -0,0 +1,51 @@
+package awslogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes/logtesting"
+)
+
+func TestCloudFront(t *testing.T) {
+	logtesting.RunTestsFromYAML(t, LogTypes(), "./testdata/cloudfront_tests.yml")
+}
+
+func TestCloudFrontDirectives(t *testing.T) {
+	parser, err := newCloudFrontParser(nil)
+	require.NoError(t, err)
+	for _, directive := range []string{
+		"#Version: 1.0",
+		"#Fields: date time x-edge-location sc-bytes c-ip",
+	} {
+		results, err := parser.ParseLog(directive)
+		require.NoError(t, err, directive)
+		require.Nil(t, results, directive)
+	}
+	for _, comment := range []string{
+		"# Generated by a firewall",
+		"#Software: Microsoft Internet Information Services 10.0",
+	} {
+		_, err := parser.ParseLog(comment)
+		require.Error(t, err, comment)
+	}
+}
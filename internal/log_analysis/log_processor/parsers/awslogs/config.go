@@ -0,0 +1,129 @@
+package awslogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+// ConfigConfigurationItem is an AWS Config configuration item as delivered to S3, SNS or EventBridge.
+// nolint:lll,maligned
+type ConfigConfigurationItem struct {
+	ConfigurationItemVersion     pantherlog.String     `json:"configurationItemVersion" description:"The version number of the configuration item."`
+	ConfigurationItemCaptureTime pantherlog.Time       `json:"configurationItemCaptureTime" validate:"required" tcodec:"rfc3339" event_time:"true" description:"The time when configuration recording was initiated."`
+	ConfigurationItemStatus      pantherlog.String     `json:"configurationItemStatus" validate:"required" description:"The configuration item status (OK, ResourceDiscovered, ResourceNotRecorded, ResourceDeleted or ResourceDeletedNotRecorded)."`
+	ConfigurationStateID         pantherlog.String     `json:"configurationStateId" description:"An identifier that indicates the ordering of the configuration items of a resource."`
+	ConfigurationStateMD5Hash    pantherlog.String     `json:"configurationStateMd5Hash" description:"Unique MD5 hash that represents the configuration item's state."`
+	AWSAccountID                 pantherlog.String     `json:"awsAccountId" validate:"required" panther:"aws_account_id" description:"The 12-digit AWS account ID associated with the resource."`
+	ARN                          pantherlog.String     `json:"ARN" panther:"aws_arn" description:"The Amazon Resource Name (ARN) associated with the resource."`
+	ResourceType                 pantherlog.String     `json:"resourceType" validate:"required" description:"The type of AWS resource."`
+	ResourceID                   pantherlog.String     `json:"resourceId" validate:"required" description:"The ID of the resource (for example, sg-xxxxxx)."`
+	ResourceName                 pantherlog.String     `json:"resourceName" description:"The custom name of the resource, if available."`
+	AWSRegion                    pantherlog.String     `json:"awsRegion" description:"The region where the resource resides."`
+	AvailabilityZone             pantherlog.String     `json:"availabilityZone" description:"The Availability Zone associated with the resource."`
+	ResourceCreationTime         pantherlog.Time       `json:"resourceCreationTime" tcodec:"rfc3339" description:"The time stamp when the resource was created."`
+	Tags                         map[string]string     `json:"tags" description:"A mapping of key value tags associated with the resource."`
+	RelatedEvents                []string              `json:"relatedEvents" description:"A list of CloudTrail event IDs."`
+	Relationships                []ConfigRelationship  `json:"relationships" description:"A list of related AWS resources."`
+	Configuration                pantherlog.RawMessage `json:"configuration" description:"The description of the resource configuration."`
+	SupplementaryConfiguration   pantherlog.RawMessage `json:"supplementaryConfiguration" description:"Configuration attributes that AWS Config returns for certain resource types to supplement the information returned for the configuration parameter."`
+}
+
+// nolint:lll
+type ConfigRelationship struct {
+	Name         pantherlog.String `json:"name" description:"The type of relationship with the related resource."`
+	ResourceType pantherlog.String `json:"resourceType" description:"The resource type of the related resource."`
+	ResourceID   pantherlog.String `json:"resourceId" description:"The ID of the related resource (for example, sg-xxxxxx)."`
+	ResourceName pantherlog.String `json:"resourceName" description:"The custom name of the related resource, if available."`
+}
+
+var _ pantherlog.ValueWriterTo = (*ConfigConfigurationItem)(nil)
+
+func (item *ConfigConfigurationItem) WriteValuesTo(w pantherlog.ValueWriter) {
+	for key, value := range item.Tags {
+		w.WriteValues(pantherlog.FieldAWSTag, key+":"+value)
+	}
+	pantherlog.ExtractRawMessageIndicators(w, extractIndicators, item.Configuration, item.SupplementaryConfiguration)
+}
+
+// configConfigurationItems 'catches' single configuration items, configuration history/snapshot files
+// and configuration change notifications.
+type configConfigurationItems struct {
+	ConfigConfigurationItem
+	ConfigurationItems []ConfigConfigurationItem `json:"configurationItems"`
+	ConfigurationItem  *ConfigConfigurationItem  `json:"configurationItem"`
+}
+
+func readConfigConfigurationItems(data []byte) ([]interface{}, error) {
+	hybrid := configConfigurationItems{}
+	if err := pantherlog.ConfigJSON().Unmarshal(data, &hybrid); err != nil {
+		return nil, err
+	}
+	switch {
+	case hybrid.ConfigurationItem != nil:
+		// The log entry was a configuration item change notification
+		return []interface{}{hybrid.ConfigurationItem}, nil
+	case hybrid.ConfigurationItems != nil:
+		events := make([]interface{}, len(hybrid.ConfigurationItems))
+		for i := range hybrid.ConfigurationItems {
+			events[i] = &hybrid.ConfigurationItems[i]
+		}
+		return events, nil
+	default:
+		// The log entry was a single configuration item
+		return []interface{}{&hybrid.ConfigConfigurationItem}, nil
+	}
+}
+
+// ConfigNotification is a notification sent by AWS Config to SNS or EventBridge.
+// nolint:lll,maligned
+type ConfigNotification struct {
+	MessageType              pantherlog.String        `json:"messageType" validate:"required" description:"The type of the notification (for example ConfigurationItemChangeNotification, ComplianceChangeNotification, ConfigRulesEvaluationStarted, ConfigurationSnapshotDeliveryCompleted or OversizedConfigurationItemChangeNotification)."`
+	RecordVersion            pantherlog.String        `json:"recordVersion" description:"The version of the notification record."`
+	NotificationCreationTime pantherlog.Time          `json:"notificationCreationTime" tcodec:"rfc3339" event_time:"true" description:"The time the notification was created."`
+	AWSAccountID             pantherlog.String        `json:"awsAccountId" panther:"aws_account_id" description:"The 12-digit AWS account ID the notification refers to."`
+	AWSRegion                pantherlog.String        `json:"awsRegion" description:"The region the notification refers to."`
+	ResourceType             pantherlog.String        `json:"resourceType" description:"The type of AWS resource the notification refers to."`
+	ResourceID               pantherlog.String        `json:"resourceId" description:"The ID of the resource the notification refers to."`
+	ConfigRuleName           pantherlog.String        `json:"configRuleName" description:"The name of the Config rule that was evaluated."`
+	ConfigRuleARN            pantherlog.String        `json:"configRuleARN" panther:"aws_arn" description:"The ARN of the Config rule that was evaluated."`
+	ConfigRuleNames          []string                 `json:"configRuleNames" description:"The names of the Config rules whose evaluation started."`
+	NewEvaluationResult      pantherlog.RawMessage    `json:"newEvaluationResult" description:"The evaluation result after the compliance change."`
+	OldEvaluationResult      pantherlog.RawMessage    `json:"oldEvaluationResult" description:"The evaluation result before the compliance change."`
+	ConfigurationItem        *ConfigConfigurationItem `json:"configurationItem" description:"The configuration item that changed."`
+	ConfigurationItemDiff    pantherlog.RawMessage    `json:"configurationItemDiff" description:"The changes to the configuration item since the previously recorded one."`
+	ConfigurationItemSummary pantherlog.RawMessage    `json:"configurationItemSummary" description:"The summary of an oversized configuration item."`
+	S3ObjectKey              pantherlog.String        `json:"s3ObjectKey" description:"The S3 object key of a delivered configuration snapshot or history file."`
+	S3Bucket                 pantherlog.String        `json:"s3Bucket" description:"The S3 bucket of a delivered configuration snapshot or history file."`
+	ConfigSnapshotID         pantherlog.String        `json:"configSnapshotId" description:"The ID of a delivered configuration snapshot."`
+}
+
+var _ pantherlog.ValueWriterTo = (*ConfigNotification)(nil)
+
+func (n *ConfigNotification) WriteValuesTo(w pantherlog.ValueWriter) {
+	pantherlog.ExtractRawMessageIndicators(w, extractIndicators, n.ConfigurationItemDiff, n.ConfigurationItemSummary)
+}
+
+func readConfigNotification(data []byte) ([]interface{}, error) {
+	event := ConfigNotification{}
+	if err := pantherlog.ConfigJSON().Unmarshal(data, &event); err != nil {
+		return nil, err
+	}
+	return []interface{}{&event}, nil
+}
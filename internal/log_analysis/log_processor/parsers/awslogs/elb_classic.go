@@ -0,0 +1,129 @@
+package awslogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"encoding/csv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+// ELBClassic is an access log entry of a Classic Load Balancer.
+// nolint:lll,maligned
+type ELBClassic struct {
+	Timestamp              pantherlog.Time    `json:"timestamp" validate:"required" tcodec:"rfc3339" event_time:"true" description:"The time when the load balancer received the request from the client, in ISO 8601 format."`
+	ELB                    pantherlog.String  `json:"elb" validate:"required" description:"The name of the load balancer."`
+	ClientIP               pantherlog.String  `json:"clientIp" panther:"ip" description:"The IP address of the requesting client."`
+	ClientPort             pantherlog.Uint16  `json:"clientPort" description:"The port of the requesting client."`
+	BackendIP              pantherlog.String  `json:"backendIp" panther:"ip" description:"The IP address of the registered instance that processed this request. If the load balancer can't send the request to a registered instance, or if the instance closes the connection before a response can be sent, this value is empty."`
+	BackendPort            pantherlog.Uint16  `json:"backendPort" description:"The port of the registered instance that processed this request."`
+	RequestProcessingTime  pantherlog.Float64 `json:"requestProcessingTime" description:"The total time elapsed, in seconds, from the time the load balancer received the request until the time it sent it to a registered instance. This value is set to -1 if the load balancer can't dispatch the request to a registered instance."`
+	BackendProcessingTime  pantherlog.Float64 `json:"backendProcessingTime" description:"The total time elapsed, in seconds, from the time the load balancer sent the request to a registered instance until the instance started to send the response headers. This value is set to -1 if the load balancer can't dispatch the request to a registered instance."`
+	ResponseProcessingTime pantherlog.Float64 `json:"responseProcessingTime" description:"The total time elapsed (in seconds) from the time the load balancer received the response header from the registered instance until it started to send the response to the client. This value is set to -1 if the load balancer can't dispatch the request to a registered instance."`
+	ELBStatusCode          pantherlog.Int16   `json:"elbStatusCode" description:"[HTTP listener] The status code of the response from the load balancer."`
+	BackendStatusCode      pantherlog.Int16   `json:"backendStatusCode" description:"[HTTP listener] The status code of the response from the registered instance."`
+	ReceivedBytes          pantherlog.Int64   `json:"receivedBytes" description:"The size of the request, in bytes, received from the client (requester)."`
+	SentBytes              pantherlog.Int64   `json:"sentBytes" description:"The size of the response, in bytes, sent to the client (requester)."`
+	RequestHTTPMethod      pantherlog.String  `json:"requestHttpMethod" description:"[HTTP listener] The HTTP method of the request."`
+	RequestURL             pantherlog.String  `json:"requestUrl" panther:"url" description:"[HTTP listener] The URL of the request."`
+	RequestHTTPVersion     pantherlog.String  `json:"requestHttpVersion" description:"[HTTP listener] The HTTP version of the request."`
+	UserAgent              pantherlog.String  `json:"userAgent" description:"[HTTP/HTTPS listener] A User-Agent string that identifies the client that originated the request."`
+	SSLCipher              pantherlog.String  `json:"sslCipher" description:"[HTTPS/SSL listener] The SSL cipher."`
+	SSLProtocol            pantherlog.String  `json:"sslProtocol" description:"[HTTPS/SSL listener] The SSL protocol."`
+}
+
+const elbClassicNumColumns = 15
+
+// elbClassicParser parses Classic Load Balancer access logs.
+// The logs are space separated values with quoted request and user agent fields.
+type elbClassicParser struct {
+	*textParser
+	logReader *strings.Reader
+	csvReader *csv.Reader
+}
+
+func newELBClassicParser(_ interface{}) (pantherlog.LogParser, error) {
+	parser, err := newTextParser(TypeELBClassic, func() interface{} {
+		return &ELBClassic{}
+	})
+	if err != nil {
+		return nil, err
+	}
+	r := strings.NewReader("")
+	csvReader := csv.NewReader(r)
+	csvReader.Comma = ' '
+	csvReader.LazyQuotes = true
+	csvReader.ReuseRecord = true
+	return &elbClassicParser{
+		textParser: parser,
+		logReader:  r,
+		csvReader:  csvReader,
+	}, nil
+}
+
+var _ pantherlog.LogParser = (*elbClassicParser)(nil)
+
+// ParseLog implements pantherlog.LogParser interface
+func (p *elbClassicParser) ParseLog(log string) ([]*pantherlog.Result, error) {
+	p.logReader.Reset(log)
+	row, err := p.csvReader.Read()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read Classic ELB log line")
+	}
+	if len(row) != elbClassicNumColumns {
+		return nil, errors.Errorf("invalid number of columns %d", len(row))
+	}
+	p.reset()
+	p.set("timestamp", row[0])
+	p.set("elb", row[1])
+	clientIP, clientPort := splitHostPort(row[2])
+	p.set("clientIp", clientIP)
+	p.set("clientPort", clientPort)
+	backendIP, backendPort := splitHostPort(row[3])
+	p.set("backendIp", backendIP)
+	p.set("backendPort", backendPort)
+	p.set("requestProcessingTime", row[4])
+	p.set("backendProcessingTime", row[5])
+	p.set("responseProcessingTime", row[6])
+	p.set("elbStatusCode", row[7])
+	p.set("backendStatusCode", row[8])
+	p.set("receivedBytes", row[9])
+	p.set("sentBytes", row[10])
+	// The request line is `METHOD URL VERSION` or `- - - ` for TCP listeners
+	if request := strings.Fields(row[11]); len(request) == 3 {
+		p.set("requestHttpMethod", request[0])
+		p.set("requestUrl", request[1])
+		p.set("requestHttpVersion", request[2])
+	}
+	p.set("userAgent", row[12])
+	p.set("sslCipher", row[13])
+	p.set("sslProtocol", row[14])
+	return p.parse()
+}
+
+// splitHostPort splits an `ip:port` address, returning empty values for `-`
+func splitHostPort(addr string) (host, port string) {
+	if pos := strings.LastIndexByte(addr, ':'); pos != -1 {
+		return addr[:pos], addr[pos+1:]
+	}
+	return addr, ""
+}
@@ -0,0 +1,90 @@
+package awslogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+// eventBridgeEnvelope is the envelope used by EventBridge (CloudWatch Events) to deliver events from AWS services.
+// It is the same structure that is parsed by the AWS.CloudWatchEvents log type.
+type eventBridgeEnvelope struct {
+	DetailType string                `json:"detail-type"`
+	Source     string                `json:"source"`
+	Detail     pantherlog.RawMessage `json:"detail"`
+}
+
+// unwrapEventBridge returns the `detail` payload of an EventBridge event or the log itself if it is not wrapped.
+func unwrapEventBridge(log string) ([]byte, error) {
+	envelope := eventBridgeEnvelope{}
+	if err := pantherlog.ConfigJSON().UnmarshalFromString(log, &envelope); err != nil {
+		return nil, err
+	}
+	if envelope.DetailType != "" && envelope.Source != "" && len(envelope.Detail) > 0 {
+		return envelope.Detail, nil
+	}
+	return []byte(log), nil
+}
+
+// eventBridgeParser parses events that are delivered either as raw JSON or wrapped in an EventBridge envelope.
+// A single log line can produce multiple events, i.e. Security Hub delivers findings in batches.
+type eventBridgeParser struct {
+	logType    string
+	readEvents func(data []byte) ([]interface{}, error)
+	builder    pantherlog.ResultBuilder
+}
+
+// newEventBridgeParser returns a parser factory for a log type that can be wrapped in an EventBridge envelope.
+// The readEvents function decodes the events from the raw JSON or from the `detail` field of the envelope.
+func newEventBridgeParser(logType string, readEvents func(data []byte) ([]interface{}, error)) pantherlog.FactoryFunc {
+	return func(_ interface{}) (pantherlog.LogParser, error) {
+		return &eventBridgeParser{
+			logType:    logType,
+			readEvents: readEvents,
+		}, nil
+	}
+}
+
+var _ pantherlog.LogParser = (*eventBridgeParser)(nil)
+
+// ParseLog implements pantherlog.LogParser interface
+func (p *eventBridgeParser) ParseLog(log string) ([]*pantherlog.Result, error) {
+	data, err := unwrapEventBridge(log)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %q JSON event", p.logType)
+	}
+	events, err := p.readEvents(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %q JSON event", p.logType)
+	}
+	results := make([]*pantherlog.Result, 0, len(events))
+	for _, event := range events {
+		if err := pantherlog.ValidateStruct(event); err != nil {
+			return nil, errors.Wrapf(err, "log event %q validation failed", p.logType)
+		}
+		result, err := p.builder.BuildResult(p.logType, event)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to build %q log event", p.logType)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,132 @@
+package awslogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+// InspectorFinding is an Amazon Inspector finding.
+// nolint:lll,maligned
+type InspectorFinding struct {
+	AWSAccountID                pantherlog.String     `json:"awsAccountId" validate:"required" panther:"aws_account_id" description:"The AWS account ID associated with the finding."`
+	FindingARN                  pantherlog.String     `json:"findingArn" validate:"required" panther:"aws_arn" description:"The Amazon Resource Number (ARN) of the finding."`
+	Type                        pantherlog.String     `json:"type" validate:"required" description:"The type of the finding (NETWORK_REACHABILITY or PACKAGE_VULNERABILITY)."`
+	Title                       pantherlog.String     `json:"title" description:"The title of the finding."`
+	Description                 pantherlog.String     `json:"description" validate:"required" description:"The description of the finding."`
+	Severity                    pantherlog.String     `json:"severity" validate:"required" description:"The severity of the finding (INFORMATIONAL, LOW, MEDIUM, HIGH, CRITICAL or UNTRIAGED)."`
+	Status                      pantherlog.String     `json:"status" validate:"required" description:"The status of the finding (ACTIVE, SUPPRESSED or CLOSED)."`
+	FirstObservedAt             pantherlog.Time       `json:"firstObservedAt" validate:"required" tcodec:"inspector" description:"The date and time that the finding was first observed."`
+	LastObservedAt              pantherlog.Time       `json:"lastObservedAt" validate:"required" tcodec:"inspector" description:"The date and time that the finding was last observed."`
+	UpdatedAt                   pantherlog.Time       `json:"updatedAt" tcodec:"inspector" event_time:"true" description:"The date and time the finding was last updated at."`
+	InspectorScore              pantherlog.Float64    `json:"inspectorScore" description:"The Amazon Inspector score given to the finding."`
+	InspectorScoreDetails       pantherlog.RawMessage `json:"inspectorScoreDetails" description:"An object that contains details of the Amazon Inspector score."`
+	FixAvailable                pantherlog.String     `json:"fixAvailable" description:"Details on whether a fix is available through a version update (YES, NO or PARTIAL)."`
+	ExploitAvailable            pantherlog.String     `json:"exploitAvailable" description:"If a finding discovered in your environment has an exploit available (YES or NO)."`
+	NetworkReachabilityDetails  pantherlog.RawMessage `json:"networkReachabilityDetails" description:"An object that contains the details of a network reachability finding."`
+	PackageVulnerabilityDetails pantherlog.RawMessage `json:"packageVulnerabilityDetails" description:"An object that contains the details of a package vulnerability finding."`
+	Remediation                 *InspectorRemediation `json:"remediation" description:"An object that contains the details about how to remediate a finding."`
+	Resources                   []InspectorResource   `json:"resources" validate:"required,min=1" description:"Contains information on the resources involved in a finding."`
+}
+
+// nolint:lll
+type InspectorRemediation struct {
+	Recommendation *InspectorRecommendation `json:"recommendation" description:"An object that contains information about the recommended course of action to remediate the finding."`
+}
+
+// nolint:lll
+type InspectorRecommendation struct {
+	Text pantherlog.String `json:"text" description:"The recommended course of action to remediate the finding."`
+	URL  pantherlog.String `json:"Url" panther:"url" description:"The URL address to the CVE remediation recommendations."`
+}
+
+// nolint:lll
+type InspectorResource struct {
+	Type      pantherlog.String     `json:"type" validate:"required" description:"The type of resource."`
+	ID        pantherlog.String     `json:"id" validate:"required" description:"The ID of the resource."`
+	Partition pantherlog.String     `json:"partition" description:"The partition of the resource."`
+	Region    pantherlog.String     `json:"region" description:"The AWS Region the impacted resource is located in."`
+	Tags      map[string]string     `json:"tags" description:"The tags attached to the resource."`
+	Details   pantherlog.RawMessage `json:"details" description:"An object that contains details about the resource involved in a finding."`
+}
+
+var _ pantherlog.ValueWriterTo = (*InspectorResource)(nil)
+
+func (r *InspectorResource) WriteValuesTo(w pantherlog.ValueWriter) {
+	switch r.Type.Value {
+	case "AWS_EC2_INSTANCE":
+		pantherlog.ScanAWSInstanceID(w, r.ID.Value)
+	default:
+		pantherlog.ScanARN(w, r.ID.Value)
+	}
+	for key, value := range r.Tags {
+		w.WriteValues(pantherlog.FieldAWSTag, key+":"+value)
+	}
+	pantherlog.ExtractRawMessageIndicators(w, extractIndicators, r.Details)
+}
+
+var _ pantherlog.ValueWriterTo = (*InspectorFinding)(nil)
+
+func (f *InspectorFinding) WriteValuesTo(w pantherlog.ValueWriter) {
+	pantherlog.ExtractRawMessageIndicators(w, extractIndicators, f.NetworkReachabilityDetails)
+}
+
+func readInspectorFinding(data []byte) ([]interface{}, error) {
+	event := InspectorFinding{}
+	if err := pantherlog.ConfigJSON().Unmarshal(data, &event); err != nil {
+		return nil, err
+	}
+	return []interface{}{&event}, nil
+}
+
+// inspectorTimeLayout is the timestamp layout used by Amazon Inspector in findings published to EventBridge.
+const inspectorTimeLayout = `Jan 2, 2006, 3:04:05 PM`
+
+// inspectorTimeDecoder decodes Amazon Inspector timestamps.
+// Findings exported through the API use RFC3339 timestamps but EventBridge events use a human readable layout.
+type inspectorTimeDecoder struct{}
+
+// DecodeTime implements tcodec.TimeDecoder for timestamps in Amazon Inspector findings.
+func (*inspectorTimeDecoder) DecodeTime(iter *jsoniter.Iterator) time.Time {
+	const opName = "ParseInspectorTimestamp"
+	switch iter.WhatIsNext() {
+	case jsoniter.StringValue:
+		s := iter.ReadString()
+		if tm, err := time.Parse(time.RFC3339, s); err == nil {
+			return tm.UTC()
+		}
+		tm, err := time.Parse(inspectorTimeLayout, s)
+		if err != nil {
+			iter.ReportError(opName, err.Error())
+			return time.Time{}
+		}
+		return tm.UTC()
+	case jsoniter.NilValue:
+		iter.ReadNil()
+		return time.Time{}
+	default:
+		iter.Skip()
+		iter.ReportError(opName, "invalid JSON value")
+		return time.Time{}
+	}
+}
@@ -0,0 +1,117 @@
+package awslogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+// NetworkFirewallAlert is an AWS Network Firewall alert log record.
+// nolint:lll
+type NetworkFirewallAlert struct {
+	FirewallName     pantherlog.String          `json:"firewall_name" validate:"required" description:"The name of the firewall that's associated with the log."`
+	AvailabilityZone pantherlog.String          `json:"availability_zone" validate:"required" description:"The Availability Zone of the firewall endpoint that generated the log."`
+	EventTimestamp   pantherlog.Time            `json:"event_timestamp" validate:"required" tcodec:"unix" description:"The time that the log was created."`
+	Event            *NetworkFirewallAlertEvent `json:"event" validate:"required" description:"The Suricata EVE JSON alert event."`
+}
+
+// NetworkFirewallFlow is an AWS Network Firewall flow log record.
+// nolint:lll
+type NetworkFirewallFlow struct {
+	FirewallName     pantherlog.String         `json:"firewall_name" validate:"required" description:"The name of the firewall that's associated with the log."`
+	AvailabilityZone pantherlog.String         `json:"availability_zone" validate:"required" description:"The Availability Zone of the firewall endpoint that generated the log."`
+	EventTimestamp   pantherlog.Time           `json:"event_timestamp" validate:"required" tcodec:"unix" description:"The time that the log was created."`
+	Event            *NetworkFirewallFlowEvent `json:"event" validate:"required" description:"The Suricata EVE JSON netflow event."`
+}
+
+// nolint:lll
+type NetworkFirewallAlertEvent struct {
+	Timestamp pantherlog.Time       `json:"timestamp" validate:"required" tcodec:"layout=2006-01-02T15:04:05.999999999-0700" event_time:"true" description:"The time the event occurred."`
+	FlowID    pantherlog.Int64      `json:"flow_id" description:"The identifier of the flow the event belongs to."`
+	EventType pantherlog.String     `json:"event_type" validate:"required,eq=alert" description:"The type of the event (alert)."`
+	SrcIP     pantherlog.String     `json:"src_ip" validate:"required" panther:"ip" description:"The source IP address."`
+	SrcPort   pantherlog.Uint16     `json:"src_port" description:"The source port."`
+	DestIP    pantherlog.String     `json:"dest_ip" validate:"required" panther:"ip" description:"The destination IP address."`
+	DestPort  pantherlog.Uint16     `json:"dest_port" description:"The destination port."`
+	Proto     pantherlog.String     `json:"proto" description:"The transport protocol."`
+	AppProto  pantherlog.String     `json:"app_proto" description:"The application protocol."`
+	Alert     *NetworkFirewallRule  `json:"alert" validate:"required" description:"The stateful rule that matched the traffic."`
+	HTTP      *NetworkFirewallHTTP  `json:"http" description:"HTTP metadata of the traffic."`
+	TLS       *NetworkFirewallTLS   `json:"tls" description:"TLS metadata of the traffic."`
+	DNS       pantherlog.RawMessage `json:"dns" description:"DNS metadata of the traffic."`
+}
+
+// nolint:lll
+type NetworkFirewallFlowEvent struct {
+	Timestamp pantherlog.Time         `json:"timestamp" validate:"required" tcodec:"layout=2006-01-02T15:04:05.999999999-0700" event_time:"true" description:"The time the event occurred."`
+	FlowID    pantherlog.Int64        `json:"flow_id" description:"The identifier of the flow."`
+	EventType pantherlog.String       `json:"event_type" validate:"required,eq=netflow" description:"The type of the event (netflow)."`
+	SrcIP     pantherlog.String       `json:"src_ip" validate:"required" panther:"ip" description:"The source IP address."`
+	SrcPort   pantherlog.Uint16       `json:"src_port" description:"The source port."`
+	DestIP    pantherlog.String       `json:"dest_ip" validate:"required" panther:"ip" description:"The destination IP address."`
+	DestPort  pantherlog.Uint16       `json:"dest_port" description:"The destination port."`
+	Proto     pantherlog.String       `json:"proto" description:"The transport protocol."`
+	AppProto  pantherlog.String       `json:"app_proto" description:"The application protocol."`
+	Netflow   *NetworkFirewallNetflow `json:"netflow" validate:"required" description:"The flow statistics."`
+	TCP       pantherlog.RawMessage   `json:"tcp" description:"The TCP flags seen in the flow."`
+}
+
+// nolint:lll
+type NetworkFirewallRule struct {
+	Action      pantherlog.String `json:"action" description:"The action taken by the rule (allowed or blocked)."`
+	SignatureID pantherlog.Int64  `json:"signature_id" description:"The signature ID (sid) of the rule."`
+	Rev         pantherlog.Int64  `json:"rev" description:"The revision of the rule."`
+	Signature   pantherlog.String `json:"signature" description:"The message of the rule."`
+	Category    pantherlog.String `json:"category" description:"The category of the rule."`
+	Severity    pantherlog.Int64  `json:"severity" description:"The severity of the rule."`
+}
+
+// nolint:lll
+type NetworkFirewallNetflow struct {
+	Packets pantherlog.Int64 `json:"pkts" description:"The number of packets in the flow."`
+	Bytes   pantherlog.Int64 `json:"bytes" description:"The number of bytes in the flow."`
+	Start   pantherlog.Time  `json:"start" tcodec:"layout=2006-01-02T15:04:05.999999999-0700" description:"The time the flow started."`
+	End     pantherlog.Time  `json:"end" tcodec:"layout=2006-01-02T15:04:05.999999999-0700" description:"The time the flow ended."`
+	Age     pantherlog.Int64 `json:"age" description:"The duration of the flow in seconds."`
+	MinTTL  pantherlog.Int64 `json:"min_ttl" description:"The minimum TTL of the packets in the flow."`
+	MaxTTL  pantherlog.Int64 `json:"max_ttl" description:"The maximum TTL of the packets in the flow."`
+}
+
+// nolint:lll
+type NetworkFirewallHTTP struct {
+	Hostname      pantherlog.String `json:"hostname" panther:"hostname" description:"The hostname of the HTTP request."`
+	URL           pantherlog.String `json:"url" description:"The URL of the HTTP request."`
+	HTTPUserAgent pantherlog.String `json:"http_user_agent" description:"The user agent of the HTTP request."`
+	HTTPMethod    pantherlog.String `json:"http_method" description:"The method of the HTTP request."`
+	Protocol      pantherlog.String `json:"protocol" description:"The HTTP protocol version."`
+	Status        pantherlog.Int16  `json:"status" description:"The status code of the HTTP response."`
+	Length        pantherlog.Int64  `json:"length" description:"The length of the HTTP response."`
+}
+
+// nolint:lll
+type NetworkFirewallTLS struct {
+	Subject     pantherlog.String `json:"subject" description:"The subject of the server certificate."`
+	IssuerDN    pantherlog.String `json:"issuerdn" description:"The issuer of the server certificate."`
+	Serial      pantherlog.String `json:"serial" description:"The serial number of the server certificate."`
+	Fingerprint pantherlog.String `json:"fingerprint" description:"The SHA1 fingerprint of the server certificate."`
+	SNI         pantherlog.String `json:"sni" panther:"domain" description:"The Server Name Indication sent by the client."`
+	Version     pantherlog.String `json:"version" description:"The TLS version."`
+	NotBefore   pantherlog.String `json:"notbefore" description:"The start of the validity period of the server certificate."`
+	NotAfter    pantherlog.String `json:"notafter" description:"The end of the validity period of the server certificate."`
+}
@@ -0,0 +1,152 @@
+package awslogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"encoding/csv"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+// RDSPostgreSQLLog is a log entry of an RDS PostgreSQL instance, including pgaudit records.
+// nolint:lll,maligned
+type RDSPostgreSQLLog struct {
+	Timestamp      pantherlog.Time   `json:"timestamp" validate:"required" tcodec:"layout=2006-01-02 15:04:05 MST" event_time:"true" description:"The time the log entry was written."`
+	RemoteHost     pantherlog.String `json:"remoteHost" panther:"ip" description:"The IP address of the client."`
+	RemotePort     pantherlog.Uint16 `json:"remotePort" description:"The port of the client."`
+	User           pantherlog.String `json:"user" panther:"username" description:"The database user name."`
+	Database       pantherlog.String `json:"database" description:"The database name."`
+	ProcessID      pantherlog.Int64  `json:"processId" description:"The ID of the PostgreSQL backend process."`
+	Severity       pantherlog.String `json:"severity" validate:"required" description:"The severity of the message (LOG, ERROR, FATAL, etc)."`
+	Message        pantherlog.String `json:"message" description:"The log message."`
+	AuditType      pantherlog.String `json:"auditType" description:"[pgaudit] SESSION or OBJECT."`
+	StatementID    pantherlog.Int64  `json:"statementId" description:"[pgaudit] Unique statement ID for this session."`
+	SubstatementID pantherlog.Int64  `json:"substatementId" description:"[pgaudit] Sequential ID for each sub-statement within the main statement."`
+	Class          pantherlog.String `json:"class" description:"[pgaudit] The class of the statement (READ, WRITE, FUNCTION, ROLE, DDL, MISC)."`
+	Command        pantherlog.String `json:"command" description:"[pgaudit] The command class (e.g. SELECT, ALTER TABLE)."`
+	ObjectType     pantherlog.String `json:"objectType" description:"[pgaudit] The type of the object (TABLE, INDEX, VIEW, etc)."`
+	ObjectName     pantherlog.String `json:"objectName" description:"[pgaudit] The fully-qualified object name (e.g. public.account)."`
+	Statement      pantherlog.String `json:"statement" description:"[pgaudit] The statement executed on the backend."`
+	Parameter      pantherlog.String `json:"parameter" description:"[pgaudit] The statement parameters if pgaudit.log_parameter is set."`
+}
+
+// rdsPostgreSQLPrefix matches the RDS log_line_prefix `%t:%r:%u@%d:[%p]:` and the severity of the message
+var rdsPostgreSQLPrefix = regexp.MustCompile(
+	`^(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2} \w+):([^:]*):([^@:]*)@([^:]*):\[(\d*)\]:([A-Z0-9]+):\s+`)
+
+const rdsPostgreSQLAuditPrefix = "AUDIT: "
+
+// rdsPostgreSQLParser parses RDS PostgreSQL logs.
+type rdsPostgreSQLParser struct {
+	*textParser
+	logReader *strings.Reader
+	csvReader *csv.Reader
+}
+
+func newRDSPostgreSQLParser(_ interface{}) (pantherlog.LogParser, error) {
+	parser, err := newTextParser(TypeRDSPostgreSQL, func() interface{} {
+		return &RDSPostgreSQLLog{}
+	})
+	if err != nil {
+		return nil, err
+	}
+	r := strings.NewReader("")
+	csvReader := csv.NewReader(r)
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+	csvReader.ReuseRecord = true
+	return &rdsPostgreSQLParser{
+		textParser: parser,
+		logReader:  r,
+		csvReader:  csvReader,
+	}, nil
+}
+
+var _ pantherlog.LogParser = (*rdsPostgreSQLParser)(nil)
+
+// ParseLog implements pantherlog.LogParser interface
+func (p *rdsPostgreSQLParser) ParseLog(log string) ([]*pantherlog.Result, error) {
+	log = strings.TrimSpace(log)
+	match := rdsPostgreSQLPrefix.FindStringSubmatchIndex(log)
+	if match == nil {
+		return nil, errors.New("invalid RDS PostgreSQL log line prefix")
+	}
+	group := func(n int) string {
+		return log[match[2*n]:match[2*n+1]]
+	}
+	p.reset()
+	p.set("timestamp", group(1))
+	host, port := splitRemoteHost(group(2))
+	p.set("remoteHost", host)
+	p.set("remotePort", port)
+	p.set("user", group(3))
+	p.set("database", group(4))
+	p.set("processId", group(5))
+	p.set("severity", group(6))
+	message := log[match[1]:]
+	p.set("message", message)
+	if strings.HasPrefix(message, rdsPostgreSQLAuditPrefix) {
+		if err := p.setAudit(strings.TrimPrefix(message, rdsPostgreSQLAuditPrefix)); err != nil {
+			return nil, err
+		}
+	}
+	return p.parse()
+}
+
+// setAudit sets the fields of a pgaudit CSV record
+func (p *rdsPostgreSQLParser) setAudit(record string) error {
+	p.logReader.Reset(record)
+	row, err := p.csvReader.Read()
+	if err != nil {
+		return errors.Wrap(err, "failed to read pgaudit record")
+	}
+	fields := []string{
+		"auditType",
+		"statementId",
+		"substatementId",
+		"class",
+		"command",
+		"objectType",
+		"objectName",
+		"statement",
+		"parameter",
+	}
+	for i, value := range row {
+		if i == len(fields) {
+			break
+		}
+		if fields[i] == "parameter" && value == "<not logged>" {
+			continue
+		}
+		p.set(fields[i], value)
+	}
+	return nil
+}
+
+// splitRemoteHost splits a `host(port)` remote address
+func splitRemoteHost(addr string) (host, port string) {
+	if pos := strings.IndexByte(addr, '('); pos != -1 && strings.HasSuffix(addr, ")") {
+		return addr[:pos], addr[pos+1 : len(addr)-1]
+	}
+	return addr, ""
+}
@@ -0,0 +1,77 @@
+package awslogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+// textParser is a helper for parsers of text logs.
+// The fields of each log line are converted to a JSON object that is parsed by a JSON parser for the log type.
+type textParser struct {
+	parser pantherlog.LogParser
+	stream *jsoniter.Stream
+	fields []string
+}
+
+func newTextParser(logType string, newEvent func() interface{}) (*textParser, error) {
+	parser, err := (&pantherlog.JSONParserFactory{
+		LogType:  logType,
+		NewEvent: newEvent,
+	}).NewParser(nil)
+	if err != nil {
+		return nil, err
+	}
+	const bufferSize = 8192
+	return &textParser{
+		parser: parser,
+		stream: jsoniter.NewStream(jsoniter.ConfigDefault, nil, bufferSize),
+	}, nil
+}
+
+// reset clears the key/value pairs of the previous log line
+func (p *textParser) reset() {
+	p.fields = p.fields[:0]
+}
+
+// set adds a field to the JSON object, skipping empty placeholder values
+func (p *textParser) set(key, value string) {
+	if value == "" || value == "-" {
+		return
+	}
+	p.fields = append(p.fields, key, value)
+}
+
+// parse converts the fields to a JSON object and parses it
+func (p *textParser) parse() ([]*pantherlog.Result, error) {
+	stream := p.stream
+	stream.Reset(nil)
+	stream.WriteObjectStart()
+	for i := 0; i+1 < len(p.fields); i += 2 {
+		if i > 0 {
+			stream.WriteMore()
+		}
+		stream.WriteObjectField(p.fields[i])
+		stream.WriteString(p.fields[i+1])
+	}
+	stream.WriteObjectEnd()
+	return p.parser.ParseLog(string(stream.Buffer()))
+}
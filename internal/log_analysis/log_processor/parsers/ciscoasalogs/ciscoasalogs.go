@@ -0,0 +1,41 @@
+// Package ciscoasalogs provides parsers for Cisco ASA firewall logs
+package ciscoasalogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+const TypeSyslog = "CiscoASA.Syslog"
+
+// LogTypes exports the available log type entries
+func LogTypes() logtypes.Group {
+	return logTypes
+}
+
+// nolint:lll
+var logTypes = logtypes.Must("CiscoASA", logtypes.Config{
+	Name:         TypeSyslog,
+	Description:  `Cisco ASA syslog messages (%ASA-level-message_id). Network addresses and users are extracted from common connection, access-list and authentication messages.`,
+	ReferenceURL: `https://www.cisco.com/c/en/us/td/docs/security/asa/syslog/b_syslog.html`,
+	Schema:       pantherlog.MustBuildEventSchema(Syslog{}, pantherlog.FieldPort),
+	NewParser:    pantherlog.FactoryFunc(newSyslogParser),
+})
@@ -0,0 +1,295 @@
+package ciscoasalogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/preprocessors"
+	"github.com/panther-labs/panther/pkg/x/fastmatch"
+)
+
+// Syslog is a Cisco ASA syslog message.
+// nolint:lll,maligned
+type Syslog struct {
+	Timestamp    pantherlog.Time   `json:"timestamp" validate:"required" tcodec:"rfc3339" event_time:"true" description:"The time the message was logged."`
+	Hostname     pantherlog.String `json:"hostname" panther:"hostname" description:"The hostname or IP address of the ASA device."`
+	Severity     pantherlog.Uint8  `json:"severity" description:"The severity level of the message (0 emergencies - 7 debugging)."`
+	MessageID    pantherlog.String `json:"messageId" validate:"required" description:"The six-digit identifier of the message."`
+	Message      pantherlog.String `json:"message" description:"The text of the message."`
+	Action       pantherlog.String `json:"action" description:"The action described by the message (Built, Teardown, Deny, permitted, denied, ...)."`
+	Direction    pantherlog.String `json:"direction" description:"The direction of the connection (inbound, outbound)."`
+	Protocol     pantherlog.String `json:"protocol" description:"The protocol of the connection or packet."`
+	ConnectionID pantherlog.Int64  `json:"connectionId" description:"The ID of the connection."`
+	SrcInterface pantherlog.String `json:"srcInterface" description:"The interface of the source address."`
+	SrcIP        pantherlog.String `json:"srcIp" panther:"ip" description:"The source IP address."`
+	SrcPort      pantherlog.Uint16 `json:"srcPort" description:"The source port."`
+	DstInterface pantherlog.String `json:"dstInterface" description:"The interface of the destination address."`
+	DstIP        pantherlog.String `json:"dstIp" panther:"ip" description:"The destination IP address."`
+	DstPort      pantherlog.Uint16 `json:"dstPort" description:"The destination port."`
+	Service      pantherlog.String `json:"service" description:"The service of a management session (ssh, https, telnet)."`
+	AccessGroup  pantherlog.String `json:"accessGroup" description:"The name of the access list that matched the traffic."`
+	Flags        pantherlog.String `json:"flags" description:"The TCP flags of the packet."`
+	Duration     pantherlog.String `json:"duration" description:"The duration of the connection (h:mm:ss)."`
+	Bytes        pantherlog.Int64  `json:"bytes" description:"The number of bytes transferred by the connection."`
+	Reason       pantherlog.String `json:"reason" description:"The reason for the action."`
+	User         pantherlog.String `json:"user" panther:"username" description:"The name of the user."`
+	Group        pantherlog.String `json:"group" description:"The group policy of a VPN user."`
+	AAAServer    pantherlog.String `json:"aaaServer" panther:"ip" description:"The AAA server that authenticated the user."`
+	Command      pantherlog.String `json:"command" description:"The command executed by the user."`
+}
+
+var _ pantherlog.ValueWriterTo = (*Syslog)(nil)
+
+// WriteValuesTo implements pantherlog.ValueWriterTo interface
+func (event *Syslog) WriteValuesTo(w pantherlog.ValueWriter) {
+	for _, port := range []pantherlog.Uint16{event.SrcPort, event.DstPort} {
+		if port.Exists && port.Value != 0 {
+			w.WriteValues(pantherlog.FieldPort, strconv.FormatUint(uint64(port.Value), 10))
+		}
+	}
+}
+
+// messagePatterns are used to extract fields from the text of specific messages.
+// For each message ID the patterns are tried in order until one matches.
+var messagePatterns = map[string][]string{
+	// Inbound TCP connection denied from 203.0.113.5/4444 to 10.0.1.25/22 flags SYN  on interface outside
+	"106001": {`%{direction} %{protocol} connection %{action} from %{srcIp}/%{srcPort} to %{dstIp}/%{dstPort} flags %{flags} on interface %{srcInterface}`},
+	// Deny inbound UDP from 203.0.113.5/53 to 10.0.1.25/5353 on interface outside
+	"106006": {`%{action} %{direction} %{protocol} from %{srcIp}/%{srcPort} to %{dstIp}/%{dstPort} on interface %{srcInterface}`},
+	// Deny TCP (no connection) from 10.0.1.25/52314 to 93.184.216.34/443 flags RST  on interface inside
+	"106015": {`%{action} %{protocol} (no connection) from %{srcIp}/%{srcPort} to %{dstIp}/%{dstPort} flags %{flags} on interface %{srcInterface}`},
+	// Deny tcp src outside:203.0.113.5/4444 dst inside:10.0.1.25/22 by access-group "OUTSIDE_IN" [0x0, 0x0]
+	"106023": {
+		`%{action} %{protocol} src %{srcInterface}:%{srcIp}/%{srcPort} dst %{dstInterface}:%{dstIp}/%{dstPort} by access-group "%{accessGroup}"`,
+		`%{action} %{protocol} src %{srcInterface}:%{srcIp} dst %{dstInterface}:%{dstIp} (%{}) by access-group "%{accessGroup}"`,
+	},
+	// access-list OUTSIDE_IN denied tcp outside/203.0.113.5(4444) -> inside/10.0.1.25(22) hit-cnt 1 first hit [0x0, 0x0]
+	"106100": {`access-list %{accessGroup} %{action} %{protocol} %{srcInterface}/%{srcIp}(%{srcPort}) -> %{dstInterface}/%{dstIp}(%{dstPort})`},
+	// User 'admin' executed the 'write memory' command.
+	"111008": {`User '%{user}' executed the '%{command}' command.`},
+	// User 'admin', running 'CLI' from IP 10.0.0.5, executed 'write memory'
+	"111010": {`User '%{user}', running '%{}' from IP %{srcIp}, executed '%{command}'`},
+	// AAA user authentication Successful : server = 10.0.0.20 : user = jdoe
+	"113004": {`AAA user %{} Successful : server = %{aaaServer} : user = %{user}`},
+	// AAA user authentication Rejected : reason = AAA failure : server = 10.0.0.20 : user = jdoe : user IP = 198.51.100.7
+	"113005": {`AAA user %{} Rejected : reason = %{reason} : server = %{aaaServer} : user = %{user} : user IP = %{srcIp}`},
+	// AAA user authentication Rejected : reason = Invalid password : local database : user = admin : user IP = 10.0.0.5
+	"113015": {`AAA user %{} Rejected : reason = %{reason} : local database : user = %{user} : user IP = %{srcIp}`},
+	// Built inbound TCP connection 38249 for outside:198.51.100.7/61022 (198.51.100.7/61022) to dmz:10.0.2.15/8080 (203.0.113.20/8080)
+	"302013": {`%{action} %{direction} %{protocol} connection %{connectionId} for %{srcInterface}:%{srcIp}/%{srcPort} (%{}) to %{dstInterface}:%{dstIp}/%{dstPort} (`},
+	// Teardown TCP connection 38249 for outside:198.51.100.7/61022 to dmz:10.0.2.15/8080 duration 0:00:30 bytes 6254 TCP FINs
+	"302014": {
+		`%{action} %{protocol} connection %{connectionId} for %{srcInterface}:%{srcIp}/%{srcPort} to %{dstInterface}:%{dstIp}/%{dstPort} duration %{duration} bytes %{bytes} %{reason}`,
+		`%{action} %{protocol} connection %{connectionId} for %{srcInterface}:%{srcIp}/%{srcPort} to %{dstInterface}:%{dstIp}/%{dstPort} duration %{duration} bytes %{bytes}`,
+	},
+	// Login permitted from 10.0.0.5/52314 to inside:10.0.0.1/ssh for user "admin"
+	"605004": {`Login %{action} from %{srcIp}/%{srcPort} to %{dstInterface}:%{dstIp}/%{service} for user "%{user}"`},
+	// User authentication succeeded: IP address: 10.0.0.5, Uname: admin
+	"611101": {`User authentication %{action}: IP address: %{srcIp}, Uname: %{user}`},
+}
+
+// vpnPatterns are tried for all messages that don't have specific patterns.
+// Most AnyConnect and WebVPN messages are prefixed with the group, user and IP of the session.
+var vpnPatterns = []string{
+	// Group <GroupPolicy_VPN> User <jdoe> IP <198.51.100.7> AnyConnect parent session started.
+	`Group <%{group}> User <%{user}> IP <%{srcIp}>`,
+	// TunnelGroup <VPN> GroupPolicy <GroupPolicy_VPN> User <jdoe> IP <198.51.100.7> No IPv6 address available for SVC connection
+	`TunnelGroup <%{}> GroupPolicy <%{group}> User <%{user}> IP <%{srcIp}>`,
+}
+
+func init() {
+	// Messages that share the same format
+	messagePatterns["302015"] = messagePatterns["302013"]
+	messagePatterns["302016"] = messagePatterns["302014"]
+	messagePatterns["605005"] = messagePatterns["605004"]
+	messagePatterns["611102"] = messagePatterns["611101"]
+}
+
+func newSyslogParser(_ interface{}) (pantherlog.LogParser, error) {
+	m, err := newMessageMatcher(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	parser, err := (&pantherlog.JSONParserFactory{
+		LogType: TypeSyslog,
+		NewEvent: func() interface{} {
+			return &Syslog{}
+		},
+	}).NewParser(nil)
+	if err != nil {
+		return nil, err
+	}
+	return preprocessors.Wrap(parser, preprocessors.MatchText(m.match, "")), nil
+}
+
+// messageMatcher converts ASA syslog messages to key/value pairs
+type messageMatcher struct {
+	now      time.Time
+	header   *fastmatch.Pattern
+	messages map[string][]*fastmatch.Pattern
+	vpn      []*fastmatch.Pattern
+}
+
+func newMessageMatcher(now time.Time) (*messageMatcher, error) {
+	// fastmatch patterns are not safe for concurrent use so each parser compiles its own
+	header, err := fastmatch.Compile(`%ASA-%{severity}-%{messageId}: %{message}`)
+	if err != nil {
+		return nil, err
+	}
+	messages := make(map[string][]*fastmatch.Pattern, len(messagePatterns))
+	for id, src := range messagePatterns {
+		patterns, err := compilePatterns(src...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to compile patterns for message %s", id)
+		}
+		messages[id] = patterns
+	}
+	vpn, err := compilePatterns(vpnPatterns...)
+	if err != nil {
+		return nil, err
+	}
+	return &messageMatcher{
+		now:      now,
+		header:   header,
+		messages: messages,
+		vpn:      vpn,
+	}, nil
+}
+
+func compilePatterns(src ...string) ([]*fastmatch.Pattern, error) {
+	patterns := make([]*fastmatch.Pattern, len(src))
+	for i, src := range src {
+		p, err := fastmatch.Compile(src)
+		if err != nil {
+			return nil, err
+		}
+		patterns[i] = p
+	}
+	return patterns, nil
+}
+
+// match implements the match function of preprocessors.MatchText
+func (m *messageMatcher) match(dst []string, log string) ([]string, error) {
+	log = strings.TrimSpace(log)
+	pos := strings.Index(log, "%ASA-")
+	if pos == -1 {
+		return dst, errors.New("invalid ASA syslog message")
+	}
+	tm, hostname, err := m.parseHeader(log[:pos])
+	if err != nil {
+		return dst, err
+	}
+	dst = append(dst, "timestamp", tm.Format(time.RFC3339), "hostname", hostname)
+	dst, err = m.header.MatchString(dst, log[pos:])
+	if err != nil {
+		return dst, errors.Wrap(err, "invalid ASA message")
+	}
+	messageID, message := dst[len(dst)-3], dst[len(dst)-1]
+	patterns, ok := m.messages[messageID]
+	if !ok {
+		patterns = m.vpn
+	}
+	for _, p := range patterns {
+		n := len(dst)
+		matches, err := p.MatchString(dst, message)
+		if err != nil {
+			continue
+		}
+		for i := n + 1; i < len(matches); i += 2 {
+			matches[i] = strings.TrimSpace(matches[i])
+		}
+		if isOutbound(matches[n:]) {
+			swapSourceDestination(matches[n:])
+		}
+		return matches, nil
+	}
+	return dst, nil
+}
+
+// parseHeader parses the syslog header that precedes the `%ASA-` tag.
+// The header can include the syslog priority, the timestamp and the hostname of the device.
+func (m *messageMatcher) parseHeader(header string) (tm time.Time, hostname string, err error) {
+	header = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(header), ":"))
+	if strings.HasPrefix(header, "<") {
+		if pos := strings.IndexByte(header, '>'); pos != -1 {
+			header = header[pos+1:]
+		}
+	}
+	fields := strings.Fields(header)
+	// RFC5424 timestamp (2021-01-14T10:15:22Z)
+	if len(fields) > 0 {
+		if tm, err := time.Parse(time.RFC3339, fields[0]); err == nil {
+			return tm, strings.Join(fields[1:], " "), nil
+		}
+	}
+	// Default ASA timestamp (Jan 14 2021 10:15:22)
+	if len(fields) >= 4 {
+		ts := strings.Join(fields[:4], " ")
+		if tm, err := time.ParseInLocation(`Jan 2 2006 15:04:05`, ts, time.UTC); err == nil {
+			return tm, strings.Join(fields[4:], " "), nil
+		}
+	}
+	// BSD syslog timestamp without a year (Jan 14 10:15:22)
+	if len(fields) >= 3 {
+		ts := strings.Join(fields[:3], " ")
+		if tm, err := time.ParseInLocation(`Jan 2 15:04:05`, ts, time.UTC); err == nil {
+			return m.guessYear(tm), strings.Join(fields[3:], " "), nil
+		}
+	}
+	return time.Time{}, "", errors.Errorf("invalid ASA syslog header %q", header)
+}
+
+// guessYear sets the year of timestamps that don't include it.
+// Timestamps in December are from the previous year if we parse them in January.
+func (m *messageMatcher) guessYear(tm time.Time) time.Time {
+	year := m.now.Year()
+	if m.now.Month() == time.January && tm.Month() == time.December {
+		year--
+	}
+	return tm.AddDate(year, 0, 0)
+}
+
+// isOutbound checks if the key/value pairs describe an outbound connection.
+// Messages for outbound connections list the foreign address first.
+func isOutbound(fields []string) bool {
+	for i := 0; i+1 < len(fields); i += 2 {
+		if fields[i] == "direction" {
+			return fields[i+1] == "outbound"
+		}
+	}
+	return false
+}
+
+// swapSourceDestination swaps the source and destination keys in place
+func swapSourceDestination(fields []string) {
+	for i := 0; i < len(fields); i += 2 {
+		switch key := fields[i]; {
+		case strings.HasPrefix(key, "src"):
+			fields[i] = "dst" + strings.TrimPrefix(key, "src")
+		case strings.HasPrefix(key, "dst"):
+			fields[i] = "src" + strings.TrimPrefix(key, "dst")
+		}
+	}
+}
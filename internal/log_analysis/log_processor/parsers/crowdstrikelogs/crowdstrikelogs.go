@@ -44,6 +44,12 @@ var logTypes = logtypes.Must(TypePrefix,
 	TypeSyntheticProcessRollup2,
 	TypeUserIdentity,
 	TypeGroupIdentity,
+	TypeUserLogon,
+	TypeFileWritten,
+	TypeScriptControl,
+	TypeModuleLoad,
+	// Falcon Event Streams API events
+	TypeDetectionSummary,
 	// Falcon Insight Special Raw Events
 	TypeAIDMaster,
 	TypeManagedAssets,
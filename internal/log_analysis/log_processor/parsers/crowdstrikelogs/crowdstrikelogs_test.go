@@ -91,7 +91,9 @@ func TestUnknownEventParser(t *testing.T) {
 		"name": "SomeOtherEventMacV5",
 		"id": "45e7efdb-9e19-11ea-88ea-02960d476b37",
 		"aid": "0659cf9079964e887615b6e4da7b0545",
-		"p_any_ip_addresses": ["71.198.164.96"],
+		"LocalAddressIP4": "0.0.0.0",
+		"RemoteAddressIP4": "127.0.0.1",
+		"p_any_ip_addresses": ["0.0.0.0", "127.0.0.1", "71.198.164.96"],
 		"p_log_type": "Crowdstrike.Unknown",
 		"p_event_time": "%s"
 		}`,
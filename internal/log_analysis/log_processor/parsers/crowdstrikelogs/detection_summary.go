@@ -0,0 +1,98 @@
+package crowdstrikelogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+// Events from the Event Streams API don't have an event_simpleName so we don't use mustBuild
+// nolint:lll
+var TypeDetectionSummary = logtypes.MustBuild(logtypes.ConfigJSON{
+	Name:         TypePrefix + ".DetectionSummary",
+	Description:  `Detection summary events from the Falcon Event Streams API, generated when a detection is raised on a host.`,
+	ReferenceURL: `https://developer.crowdstrike.com/crowdstrike/docs/streaming-api-events#detection-summary-event`,
+	NewEvent:     func() interface{} { return &DetectionSummary{} },
+})
+
+// nolint:lll
+type DetectionSummary struct {
+	Metadata *StreamingMetadata    `json:"metadata" validate:"required" description:"The metadata of the streaming event."`
+	Event    *DetectionSummaryData `json:"event" validate:"required" description:"The detection."`
+}
+
+var _ pantherlog.EventTimer = (*DetectionSummary)(nil)
+
+// PantherEventTime implements pantherlog.EventTimer and uses the time the event was created.
+func (e *DetectionSummary) PantherEventTime() time.Time {
+	if e.Metadata == nil {
+		return time.Time{}
+	}
+	return e.Metadata.EventCreationTime
+}
+
+// nolint:lll
+type StreamingMetadata struct {
+	CustomerIDString  pantherlog.String `json:"customerIDString" description:"The customer ID."`
+	Offset            pantherlog.Int64  `json:"offset" description:"The offset of the event in the stream."`
+	EventType         pantherlog.String `json:"eventType" validate:"required,eq=DetectionSummaryEvent" description:"The type of the event."`
+	EventCreationTime time.Time         `json:"eventCreationTime" validate:"required" tcodec:"unix_ms" description:"The time the event was created."`
+	Version           pantherlog.String `json:"version" description:"The version of the event schema."`
+}
+
+// nolint:lll
+type DetectionSummaryData struct {
+	DetectID                      pantherlog.String     `json:"DetectId" description:"The ID of the detection."`
+	DetectName                    pantherlog.String     `json:"DetectName" description:"The name of the detection."`
+	DetectDescription             pantherlog.String     `json:"DetectDescription" description:"The description of the detection."`
+	Severity                      pantherlog.Int32      `json:"Severity" description:"The severity of the detection (1-5)."`
+	SeverityName                  pantherlog.String     `json:"SeverityName" description:"The name of the severity of the detection (Informational, Low, Medium, High, Critical)."`
+	Tactic                        pantherlog.String     `json:"Tactic" description:"The MITRE ATT&CK tactic of the detection."`
+	Technique                     pantherlog.String     `json:"Technique" description:"The MITRE ATT&CK technique of the detection."`
+	Objective                     pantherlog.String     `json:"Objective" description:"The objective of the detection."`
+	SensorID                      pantherlog.String     `json:"SensorId" description:"The sensor ID (aid) of the host."`
+	ComputerName                  pantherlog.String     `json:"ComputerName" panther:"hostname" description:"The name of the host."`
+	MachineDomain                 pantherlog.String     `json:"MachineDomain" description:"The domain of the host."`
+	LocalIP                       pantherlog.String     `json:"LocalIP" panther:"ip" description:"The local IP address of the host."`
+	MACAddress                    pantherlog.String     `json:"MACAddress" description:"The MAC address of the host."`
+	UserName                      pantherlog.String     `json:"UserName" panther:"username" description:"The user that ran the process."`
+	ProcessID                     pantherlog.Int64      `json:"ProcessId" description:"The unique ID of the process."`
+	ParentProcessID               pantherlog.Int64      `json:"ParentProcessId" description:"The unique ID of the parent process."`
+	ProcessStartTime              pantherlog.Time       `json:"ProcessStartTime" tcodec:"unix" description:"The time the process started."`
+	ProcessEndTime                pantherlog.Time       `json:"ProcessEndTime" tcodec:"unix" description:"The time the process ended."`
+	FileName                      pantherlog.String     `json:"FileName" description:"The file name of the process."`
+	FilePath                      pantherlog.String     `json:"FilePath" description:"The path of the file of the process."`
+	CommandLine                   pantherlog.String     `json:"CommandLine" description:"The command line of the process."`
+	SHA256String                  pantherlog.String     `json:"SHA256String" panther:"sha256" description:"The SHA256 hash of the file of the process."`
+	SHA1String                    pantherlog.String     `json:"SHA1String" panther:"sha1" description:"The SHA1 hash of the file of the process."`
+	MD5String                     pantherlog.String     `json:"MD5String" panther:"md5" description:"The MD5 hash of the file of the process."`
+	ParentImageFileName           pantherlog.String     `json:"ParentImageFileName" description:"The file name of the parent process."`
+	ParentCommandLine             pantherlog.String     `json:"ParentCommandLine" description:"The command line of the parent process."`
+	GrandparentImageFileName      pantherlog.String     `json:"GrandparentImageFileName" description:"The file name of the grandparent process."`
+	GrandparentCommandLine        pantherlog.String     `json:"GrandparentCommandLine" description:"The command line of the grandparent process."`
+	IOCType                       pantherlog.String     `json:"IOCType" description:"The type of the indicator of compromise (e.g. hash_sha256, domain, filename)."`
+	IOCValue                      pantherlog.String     `json:"IOCValue" description:"The value of the indicator of compromise."`
+	PatternDispositionValue       pantherlog.Int32      `json:"PatternDispositionValue" description:"The action taken by the sensor as a numeric value."`
+	PatternDispositionDescription pantherlog.String     `json:"PatternDispositionDescription" description:"The description of the action taken by the sensor."`
+	PatternDispositionFlags       pantherlog.RawMessage `json:"PatternDispositionFlags" description:"The flags of the action taken by the sensor."`
+	FalconHostLink                pantherlog.String     `json:"FalconHostLink" panther:"url" description:"The link to the detection in the Falcon console."`
+}
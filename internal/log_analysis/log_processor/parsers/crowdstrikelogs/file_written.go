@@ -0,0 +1,49 @@
+package crowdstrikelogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+// nolint:lll
+var TypeFileWritten = mustBuild(logtypes.ConfigJSON{
+	Name:         TypePrefix + ".FileWritten",
+	Description:  `These events are generated when a file of a known type (executable, script, document, archive) is written to disk.`,
+	ReferenceURL: `https://developer.crowdstrike.com/crowdstrike/page/event-explorer#section-event-NewExecutableWritten`,
+	NewEvent:     func() interface{} { return &FileWritten{} },
+})
+
+// nolint:lll
+type FileWritten struct {
+	EventSimpleName pantherlog.String `json:"event_simpleName" validate:"required,oneof=NewExecutableWritten NewScriptWritten PeFileWritten ELFFileWritten MachOFileWritten GenericFileWritten JarFileWritten JavaClassFileWritten ZipFileWritten RarFileWritten SevenZipFileWritten GzipFileWritten CabFileWritten PdfFileWritten OleFileWritten OoxmlFileWritten RtfFileWritten LnkFileWritten MsiFileWritten DmpFileWritten" description:"Event name"`
+	ContextEvent
+
+	TargetFileName             pantherlog.String `json:"TargetFileName" description:"The full path to the file that was written."`
+	FileIdentifier             pantherlog.String `json:"FileIdentifier" description:"The unique identifier of the file on the host."`
+	Size                       pantherlog.Int64  `json:"Size" description:"The size of the file in bytes."`
+	SHA256HashData             pantherlog.String `json:"SHA256HashData" panther:"sha256" description:"The SHA256 hash of the file."`
+	SHA1HashData               pantherlog.String `json:"SHA1HashData" panther:"sha1" description:"The SHA1 hash of the file."`
+	MD5HashData                pantherlog.String `json:"MD5HashData" panther:"md5" description:"The MD5 hash of the file."`
+	IsOnNetwork                pantherlog.Int32  `json:"IsOnNetwork" description:"Whether the file was written to a network share (1) or not (0)."`
+	IsOnRemovableDisk          pantherlog.Int32  `json:"IsOnRemovableDisk" description:"Whether the file was written to a removable disk (1) or not (0)."`
+	DiskParentDeviceInstanceID pantherlog.String `json:"DiskParentDeviceInstanceId" description:"The instance ID of the parent device of the disk (Windows only)."`
+	UserSID                    pantherlog.String `json:"UserSid" description:"The security identifier of the user that wrote the file (Windows only)."`
+}
@@ -0,0 +1,47 @@
+package crowdstrikelogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+// nolint:lll
+var TypeModuleLoad = mustBuild(logtypes.ConfigJSON{
+	Name:         TypePrefix + ".ModuleLoad",
+	Description:  `These events are generated when a process loads a module (DLL, shared library).`,
+	ReferenceURL: `https://developer.crowdstrike.com/crowdstrike/page/event-explorer#section-event-ClassifiedModuleLoad`,
+	NewEvent:     func() interface{} { return &ModuleLoad{} },
+})
+
+// nolint:lll
+type ModuleLoad struct {
+	EventSimpleName pantherlog.String `json:"event_simpleName" validate:"required,oneof=ImageHash ClassifiedModuleLoad UnsignedModuleLoad" description:"Event name"`
+	ContextEvent
+
+	ImageFileName         pantherlog.String `json:"ImageFileName" description:"The full path to the module that was loaded."`
+	TargetProcessID       pantherlog.String `json:"TargetProcessId" description:"The unique ID of the process that loaded the module."`
+	SHA256HashData        pantherlog.String `json:"SHA256HashData" panther:"sha256" description:"The SHA256 hash of the module."`
+	SHA1HashData          pantherlog.String `json:"SHA1HashData" panther:"sha1" description:"The SHA1 hash of the module."`
+	MD5HashData           pantherlog.String `json:"MD5HashData" panther:"md5" description:"The MD5 hash of the module."`
+	ModuleCharacteristics pantherlog.String `json:"ModuleCharacteristics" description:"The characteristics of the module (bitfield)."`
+	ModuleSize            pantherlog.Int64  `json:"ModuleSize" description:"The size of the module in bytes."`
+	SignInfoFlags         pantherlog.String `json:"SignInfoFlags" description:"The signature information of the module (bitfield)."`
+}
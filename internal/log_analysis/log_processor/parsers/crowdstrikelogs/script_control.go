@@ -0,0 +1,49 @@
+package crowdstrikelogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+// nolint:lll
+var TypeScriptControl = mustBuild(logtypes.ConfigJSON{
+	Name:         TypePrefix + ".ScriptControl",
+	Description:  `These events are generated when the script control feature scans the content of a script run by an interpreter (PowerShell, VBScript, JScript, ...).`,
+	ReferenceURL: `https://developer.crowdstrike.com/crowdstrike/page/event-explorer#section-event-ScriptControlScanTelemetry`,
+	NewEvent:     func() interface{} { return &ScriptControl{} },
+})
+
+// nolint:lll
+type ScriptControl struct {
+	EventSimpleName pantherlog.String `json:"event_simpleName" validate:"required,oneof=ScriptControlScanTelemetry ScriptControlScanInfo ScriptControlDetectInfo ScriptControlDetectInvalid" description:"Event name"`
+	ContextEvent
+
+	ScriptContent       pantherlog.String `json:"ScriptContent" description:"The content of the script that was scanned."`
+	ScriptContentName   pantherlog.String `json:"ScriptContentName" description:"The name of the script that was scanned."`
+	ScriptContentSource pantherlog.Int32  `json:"ScriptContentSource" description:"The source of the script content."`
+	ScriptContentBytes  pantherlog.Int64  `json:"ScriptContentBytes" description:"The size of the script content in bytes."`
+	ScriptContentScanID pantherlog.String `json:"ScriptContentScanId" description:"The ID of the scan."`
+	ScriptingLanguageID pantherlog.Int32  `json:"ScriptingLanguageId" description:"The scripting language of the script."`
+	ScriptModuleName    pantherlog.String `json:"ScriptModuleName" description:"The name of the module that ran the script."`
+	TargetFileName      pantherlog.String `json:"TargetFileName" description:"The full path to the file of the script, if any."`
+	ImageFileName       pantherlog.String `json:"ImageFileName" description:"The full path to the executable of the interpreter."`
+	SHA256HashData      pantherlog.String `json:"SHA256HashData" panther:"sha256" description:"The SHA256 hash of the script content."`
+}
@@ -34,7 +34,7 @@ var (
 	// TypeUnknownEvent is a special event collects all crowdstrike events that don't yet have a registered log type
 	TypeUnknownEvent = logtypes.MustBuild(logtypes.ConfigJSON{
 		Name:         TypePrefix + ".Unknown",
-		Description:  `This event is used to store all unknown crowdstrike log events`,
+		Description:  `This event is used to store all unknown crowdstrike log events. Common fields are available as columns and the full event is kept in unknown_payload.`,
 		ReferenceURL: `-`,
 		NewEvent:     func() interface{} { return &UnknownEventWithPayload{} },
 	})
@@ -48,9 +48,32 @@ type UnknownEventWithPayload struct {
 }
 
 // This event holds all common fields for crowdstrike events.
+// nolint:lll
 type UnknownEvent struct {
 	EventSimpleName null.String `json:"event_simpleName" validate:"required" description:"Event name"`
 	ContextEvent
+	CommonEventFields
+}
+
+// CommonEventFields are fields shared by many event types.
+// We use null.String for all fields so that decoding never fails for events that use a different type.
+// nolint:lll
+type CommonEventFields struct {
+	TargetProcessID  null.String `json:"TargetProcessId" description:"The unique ID of the target process, if the event has one."`
+	ParentProcessID  null.String `json:"ParentProcessId" description:"The unique ID of the parent process, if the event has one."`
+	ImageFileName    null.String `json:"ImageFileName" description:"The full path to an executable file, if the event has one."`
+	CommandLine      null.String `json:"CommandLine" description:"The command line of the process, if the event has one."`
+	TargetFileName   null.String `json:"TargetFileName" description:"The full path to the file affected by the event, if the event has one."`
+	SHA256HashData   null.String `json:"SHA256HashData" panther:"sha256" description:"The SHA256 hash of a file, if the event has one."`
+	SHA1HashData     null.String `json:"SHA1HashData" panther:"sha1" description:"The SHA1 hash of a file, if the event has one."`
+	MD5HashData      null.String `json:"MD5HashData" panther:"md5" description:"The MD5 hash of a file, if the event has one."`
+	UserName         null.String `json:"UserName" panther:"username" description:"The name of the user, if the event has one."`
+	UserSID          null.String `json:"UserSid" description:"The security identifier of the user, if the event has one (Windows only)."`
+	LocalAddressIP4  null.String `json:"LocalAddressIP4" panther:"ip" description:"The local IPv4 address, if the event has one."`
+	LocalAddressIP6  null.String `json:"LocalAddressIP6" panther:"ip" description:"The local IPv6 address, if the event has one."`
+	RemoteAddressIP4 null.String `json:"RemoteAddressIP4" panther:"ip" description:"The remote IPv4 address, if the event has one."`
+	RemoteAddressIP6 null.String `json:"RemoteAddressIP6" panther:"ip" description:"The remote IPv6 address, if the event has one."`
+	DomainName       null.String `json:"DomainName" panther:"domain" description:"The domain name, if the event has one."`
 }
 
 // Register jsoniter decoder for UnknownEventWithPayload
@@ -0,0 +1,56 @@
+package crowdstrikelogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+// nolint:lll
+var TypeUserLogon = mustBuild(logtypes.ConfigJSON{
+	Name:         TypePrefix + ".UserLogon",
+	Description:  `These events are generated when a user logs on, logs off or fails to log on to a host.`,
+	ReferenceURL: `https://developer.crowdstrike.com/crowdstrike/page/event-explorer#section-event-UserLogon`,
+	NewEvent:     func() interface{} { return &UserLogon{} },
+})
+
+// nolint:lll
+type UserLogon struct {
+	EventSimpleName pantherlog.String `json:"event_simpleName" validate:"required,oneof=UserLogon UserLogoff UserLogonFailed UserLogonFailed2" description:"Event name"`
+	ContextEvent
+
+	UserName              pantherlog.String `json:"UserName" panther:"username" description:"The name of the user."`
+	UserSID               pantherlog.String `json:"UserSid" description:"The security identifier of the user (Windows only)."`
+	UserPrincipal         pantherlog.String `json:"UserPrincipal" panther:"username" description:"The user principal name of the user."`
+	UID                   pantherlog.Int64  `json:"UID" description:"The user ID (Mac and Linux only)."`
+	LogonDomain           pantherlog.String `json:"LogonDomain" description:"The domain of the user."`
+	LogonServer           pantherlog.String `json:"LogonServer" panther:"hostname" description:"The server that authenticated the user."`
+	LogonTime             pantherlog.Time   `json:"LogonTime" tcodec:"unix" description:"The time the user logged on."`
+	LogonType             pantherlog.Int32  `json:"LogonType" description:"Values: INTERACTIVE (2), NETWORK (3), BATCH (4), SERVICE (5), PROXY (6), UNLOCK (7), NETWORK_CLEARTEXT (8), NEW_CREDENTIALS (9), REMOTE_INTERACTIVE (10), CACHED_INTERACTIVE (11), CACHED_REMOTE_INTERACTIVE (12), CACHED_UNLOCK (13)"`
+	AuthenticationPackage pantherlog.String `json:"AuthenticationPackage" description:"The authentication package used (e.g. NTLM, Kerberos, Negotiate)."`
+	AuthenticationID      pantherlog.String `json:"AuthenticationId" description:"The authentication identifier of the logon session."`
+	RemoteAddressIP4      pantherlog.String `json:"RemoteAddressIP4" panther:"ip" description:"The IPv4 address of the remote host the logon originated from."`
+	RemoteAddressIP6      pantherlog.String `json:"RemoteAddressIP6" panther:"ip" description:"The IPv6 address of the remote host the logon originated from."`
+	ClientComputerName    pantherlog.String `json:"ClientComputerName" panther:"hostname" description:"The name of the remote host the logon originated from."`
+	UserIsAdmin           pantherlog.Int32  `json:"UserIsAdmin" description:"Whether the user is an administrator (1) or not (0)."`
+	PasswordLastSet       pantherlog.Time   `json:"PasswordLastSet" tcodec:"unix" description:"The time the password of the user was last changed."`
+	Status                pantherlog.String `json:"Status" description:"The status code of a failed logon (Windows only)."`
+	SubStatus             pantherlog.String `json:"SubStatus" description:"The sub-status code of a failed logon (Windows only)."`
+}
@@ -0,0 +1,129 @@
+package fortinetlogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+// FortiGate is a FortiGate log message.
+// All log types (traffic, event, utm) share the same set of columns.
+// nolint:lll,maligned
+type FortiGate struct {
+	Timestamp        pantherlog.Time   `json:"timestamp" validate:"required" tcodec:"rfc3339" event_time:"true" description:"The date and time the log message was recorded (combines the 'date', 'time' and 'tz' fields)."`
+	EventTime        pantherlog.Int64  `json:"eventtime" description:"The epoch time the event was generated (seconds or nanoseconds depending on FortiOS version)."`
+	TZ               pantherlog.String `json:"tz" description:"The timezone of the FortiGate."`
+	LogID            pantherlog.String `json:"logid" validate:"required" description:"A ten-digit number that identifies the log type, subtype and message ID."`
+	Type             pantherlog.String `json:"type" validate:"required" description:"The section of the system where the event occurred (traffic, event, utm)."`
+	Subtype          pantherlog.String `json:"subtype" description:"The subtype of the log message (forward, local, system, vpn, user, virus, webfilter, ips, app-ctrl, dns, ...)."`
+	Level            pantherlog.String `json:"level" description:"The severity level of the log message (emergency, alert, critical, error, warning, notice, information, debug)."`
+	DeviceName       pantherlog.String `json:"devname" panther:"hostname" description:"The hostname of the FortiGate device."`
+	DeviceID         pantherlog.String `json:"devid" description:"The serial number of the FortiGate device."`
+	VirtualDomain    pantherlog.String `json:"vd" description:"The name of the virtual domain (VDOM)."`
+	LogDescription   pantherlog.String `json:"logdesc" description:"The description of the log message."`
+	Message          pantherlog.String `json:"msg" description:"The log message."`
+	Action           pantherlog.String `json:"action" description:"The action taken by the FortiGate."`
+	Status           pantherlog.String `json:"status" description:"The status of the action."`
+	Reason           pantherlog.String `json:"reason" description:"The reason for the action or status."`
+	SessionID        pantherlog.Int64  `json:"sessionid" description:"The session ID."`
+	Protocol         pantherlog.Uint8  `json:"proto" description:"The IP protocol number."`
+	Service          pantherlog.String `json:"service" description:"The name of the service."`
+	SrcIP            pantherlog.String `json:"srcip" panther:"ip" description:"The source IP address."`
+	SrcPort          pantherlog.Uint16 `json:"srcport" description:"The source port."`
+	SrcInterface     pantherlog.String `json:"srcintf" description:"The source interface."`
+	SrcInterfaceRole pantherlog.String `json:"srcintfrole" description:"The role of the source interface (lan, wan, dmz, undefined)."`
+	SrcName          pantherlog.String `json:"srcname" panther:"hostname" description:"The name of the source device."`
+	SrcMAC           pantherlog.String `json:"srcmac" description:"The MAC address of the source device."`
+	SrcCountry       pantherlog.String `json:"srccountry" description:"The country of the source IP address."`
+	DstIP            pantherlog.String `json:"dstip" panther:"ip" description:"The destination IP address."`
+	DstPort          pantherlog.Uint16 `json:"dstport" description:"The destination port."`
+	DstInterface     pantherlog.String `json:"dstintf" description:"The destination interface."`
+	DstInterfaceRole pantherlog.String `json:"dstintfrole" description:"The role of the destination interface (lan, wan, dmz, undefined)."`
+	DstCountry       pantherlog.String `json:"dstcountry" description:"The country of the destination IP address."`
+	TranDisp         pantherlog.String `json:"trandisp" description:"The NAT translation type (noop, snat, dnat, snat+dnat)."`
+	TranSIP          pantherlog.String `json:"transip" panther:"ip" description:"The source IP address after NAT."`
+	TranSPort        pantherlog.Uint16 `json:"transport" description:"The source port after NAT."`
+	TranIP           pantherlog.String `json:"tranip" panther:"ip" description:"The destination IP address after NAT."`
+	TranPort         pantherlog.Uint16 `json:"tranport" description:"The destination port after NAT."`
+	PolicyID         pantherlog.Int64  `json:"policyid" description:"The ID of the firewall policy that matched the traffic."`
+	PolicyName       pantherlog.String `json:"policyname" description:"The name of the firewall policy that matched the traffic."`
+	PolicyType       pantherlog.String `json:"policytype" description:"The type of the firewall policy."`
+	PolicyUUID       pantherlog.String `json:"poluuid" description:"The UUID of the firewall policy."`
+	Duration         pantherlog.Int64  `json:"duration" description:"The duration of the session in seconds."`
+	SentByte         pantherlog.Int64  `json:"sentbyte" description:"The number of bytes sent."`
+	RcvdByte         pantherlog.Int64  `json:"rcvdbyte" description:"The number of bytes received."`
+	SentPkt          pantherlog.Int64  `json:"sentpkt" description:"The number of packets sent."`
+	RcvdPkt          pantherlog.Int64  `json:"rcvdpkt" description:"The number of packets received."`
+	AppID            pantherlog.Int64  `json:"appid" description:"The ID of the application."`
+	App              pantherlog.String `json:"app" description:"The name of the application."`
+	AppCat           pantherlog.String `json:"appcat" description:"The category of the application."`
+	AppRisk          pantherlog.String `json:"apprisk" description:"The risk level of the application."`
+	User             pantherlog.String `json:"user" panther:"username" description:"The name of the user."`
+	Group            pantherlog.String `json:"group" description:"The user group of the user."`
+	UnauthUser       pantherlog.String `json:"unauthuser" panther:"username" description:"The name of an unauthenticated user."`
+	XAuthUser        pantherlog.String `json:"xauthuser" panther:"username" description:"The XAuth user name of a VPN connection."`
+	AuthServer       pantherlog.String `json:"authserver" description:"The authentication server used for the user."`
+	UI               pantherlog.String `json:"ui" description:"The user interface used by an administrator (e.g. https(10.0.0.5), ssh(10.0.0.5))."`
+	Method           pantherlog.String `json:"method" description:"The method used (e.g. https, ssh, domain)."`
+	RemoteIP         pantherlog.String `json:"remip" panther:"ip" description:"The remote IP address of a VPN tunnel."`
+	RemotePort       pantherlog.Uint16 `json:"remport" description:"The remote port of a VPN tunnel."`
+	LocalIP          pantherlog.String `json:"locip" panther:"ip" description:"The local IP address of a VPN tunnel."`
+	LocalPort        pantherlog.Uint16 `json:"locport" description:"The local port of a VPN tunnel."`
+	TunnelType       pantherlog.String `json:"tunneltype" description:"The type of the VPN tunnel."`
+	VPNTunnel        pantherlog.String `json:"vpntunnel" description:"The name of the VPN tunnel."`
+	EventType        pantherlog.String `json:"eventtype" description:"The type of a security event."`
+	Profile          pantherlog.String `json:"profile" description:"The name of the security profile that detected the event."`
+	Direction        pantherlog.String `json:"direction" description:"The direction of the traffic (incoming, outgoing)."`
+	Hostname         pantherlog.String `json:"hostname" panther:"hostname" description:"The hostname of a URL or DNS request."`
+	URL              pantherlog.String `json:"url" description:"The path of the requested URL."`
+	Category         pantherlog.Int64  `json:"cat" description:"The ID of the web filter category."`
+	CategoryDesc     pantherlog.String `json:"catdesc" description:"The description of the web filter category."`
+	RequestType      pantherlog.String `json:"reqtype" description:"The request type (direct, referral)."`
+	QueryName        pantherlog.String `json:"qname" panther:"domain" description:"The domain name of a DNS query."`
+	QueryType        pantherlog.String `json:"qtype" description:"The type of a DNS query."`
+	Attack           pantherlog.String `json:"attack" description:"The name of the attack detected by IPS."`
+	AttackID         pantherlog.Int64  `json:"attackid" description:"The ID of the attack detected by IPS."`
+	Severity         pantherlog.String `json:"severity" description:"The severity of the attack or threat."`
+	Reference        pantherlog.String `json:"ref" panther:"url" description:"A URL with more information about the threat."`
+	Virus            pantherlog.String `json:"virus" description:"The name of the virus detected."`
+	VirusID          pantherlog.Int64  `json:"virusid" description:"The ID of the virus detected."`
+	DType            pantherlog.String `json:"dtype" description:"The type of the detection."`
+	FileName         pantherlog.String `json:"filename" description:"The name of the file."`
+	FileType         pantherlog.String `json:"filetype" description:"The type of the file."`
+	Checksum         pantherlog.String `json:"checksum" description:"The checksum of the file."`
+	CRScore          pantherlog.Int64  `json:"crscore" description:"The client reputation score."`
+	CRAction         pantherlog.Int64  `json:"craction" description:"The client reputation action."`
+	CRLevel          pantherlog.String `json:"crlevel" description:"The client reputation level."`
+	OSName           pantherlog.String `json:"osname" description:"The name of the operating system of the source device."`
+	DeviceType       pantherlog.String `json:"devtype" description:"The type of the source device."`
+}
+
+var _ pantherlog.ValueWriterTo = (*FortiGate)(nil)
+
+// WriteValuesTo implements pantherlog.ValueWriterTo interface
+func (event *FortiGate) WriteValuesTo(w pantherlog.ValueWriter) {
+	pantherlog.WritePorts(w,
+		event.SrcPort,
+		event.DstPort,
+		event.TranSPort,
+		event.TranPort,
+		event.RemotePort,
+		event.LocalPort,
+	)
+}
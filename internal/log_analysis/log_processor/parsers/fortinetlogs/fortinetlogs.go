@@ -0,0 +1,145 @@
+// Package fortinetlogs provides parsers for Fortinet FortiGate logs
+package fortinetlogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/preprocessors"
+)
+
+const TypeFortiGate = "Fortinet.FortiGate"
+
+// LogTypes exports the available log type entries
+func LogTypes() logtypes.Group {
+	return logTypes
+}
+
+// nolint:lll
+var logTypes = logtypes.Must("Fortinet", logtypes.Config{
+	Name:         TypeFortiGate,
+	Description:  `FortiGate traffic, event and security (UTM) logs in key=value format.`,
+	ReferenceURL: `https://docs.fortinet.com/document/fortigate/6.4.0/fortios-log-message-reference/357866/log-message-fields`,
+	Schema:       pantherlog.MustBuildEventSchema(FortiGate{}, pantherlog.FieldPort),
+	NewParser:    pantherlog.FactoryFunc(newFortiGateParser),
+})
+
+func newFortiGateParser(_ interface{}) (pantherlog.LogParser, error) {
+	parser, err := (&pantherlog.JSONParserFactory{
+		LogType: TypeFortiGate,
+		NewEvent: func() interface{} {
+			return &FortiGate{}
+		},
+	}).NewParser(nil)
+	if err != nil {
+		return nil, err
+	}
+	return preprocessors.Wrap(parser, preprocessors.MatchText(matchKeyValues, "", "N/A")), nil
+}
+
+// matchKeyValues appends the `key=value` pairs of a FortiGate log line to dst.
+// Tokens that are not `key=value` pairs, such as a syslog header, are ignored.
+// The `date`, `time` and `tz` fields are combined to an RFC3339 `timestamp` field.
+func matchKeyValues(dst []string, log string) ([]string, error) {
+	var date, tm, tz string
+	numPairs := 0
+	log = strings.TrimSpace(log)
+	// Strip the syslog priority (`<189>`)
+	if strings.HasPrefix(log, "<") {
+		if pos := strings.IndexByte(log, '>'); pos != -1 {
+			log = log[pos+1:]
+		}
+	}
+	for ; log != ""; log = strings.TrimLeft(log, " ") {
+		pos := strings.IndexAny(log, "= ")
+		if pos == -1 {
+			break
+		}
+		if log[pos] == ' ' {
+			// Skip tokens that are not key=value pairs
+			log = log[pos:]
+			continue
+		}
+		key := log[:pos]
+		value, tail, err := readValue(log[pos+1:])
+		if err != nil {
+			return dst, errors.Wrapf(err, "invalid value for %q", key)
+		}
+		log = tail
+		numPairs++
+		switch key {
+		case "date":
+			date = value
+		case "time":
+			tm = value
+		case "tz":
+			tz = value
+			dst = append(dst, key, value)
+		default:
+			dst = append(dst, key, value)
+		}
+	}
+	if numPairs == 0 {
+		return dst, errors.New("invalid FortiGate log")
+	}
+	if date != "" && tm != "" {
+		dst = append(dst, "timestamp", date+"T"+tm+timezoneOffset(tz))
+	}
+	return dst, nil
+}
+
+// readValue reads a plain or double quoted value
+func readValue(s string) (value, tail string, err error) {
+	if !strings.HasPrefix(s, `"`) {
+		if pos := strings.IndexByte(s, ' '); pos != -1 {
+			return s[:pos], s[pos:], nil
+		}
+		return s, "", nil
+	}
+	// Quoted values can contain escaped quotes
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			value, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				// Values are not always valid Go strings, use the raw value instead
+				value = s[1:i]
+			}
+			return value, s[i+1:], nil
+		}
+	}
+	return "", "", errors.New("unterminated quoted value")
+}
+
+// timezoneOffset converts a FortiGate `tz` value (`-0800`) to an RFC3339 offset (`-08:00`).
+// Logs without a timezone are in UTC.
+func timezoneOffset(tz string) string {
+	if len(tz) != len("-0700") || (tz[0] != '-' && tz[0] != '+') {
+		return "Z"
+	}
+	return tz[:3] + ":" + tz[3:]
+}
@@ -0,0 +1,65 @@
+package githublogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+// nolint:lll
+type Audit struct {
+	Timestamp                pantherlog.Time       `json:"@timestamp" validate:"required" tcodec:"unix_ms" event_time:"true" description:"The time the event occurred."`
+	DocumentID               pantherlog.String     `json:"_document_id" description:"The unique identifier of the audit log entry."`
+	Action                   pantherlog.String     `json:"action" validate:"required" description:"The name of the action that was performed (e.g. repo.create, org.add_member)."`
+	Actor                    pantherlog.String     `json:"actor" panther:"username" description:"The login of the user or application that performed the action."`
+	ActorID                  pantherlog.Int64      `json:"actor_id" description:"The ID of the actor."`
+	ActorIP                  pantherlog.String     `json:"actor_ip" panther:"ip" description:"The IP address of the actor."`
+	ActorIsBot               pantherlog.Bool       `json:"actor_is_bot" description:"Whether the actor is a bot."`
+	ActorLocation            *ActorLocation        `json:"actor_location" description:"The location of the actor."`
+	Business                 pantherlog.String     `json:"business" description:"The name of the enterprise affected by the action."`
+	BusinessID               pantherlog.Int64      `json:"business_id" description:"The ID of the enterprise affected by the action."`
+	CreatedAt                pantherlog.Time       `json:"created_at" tcodec:"unix_ms" description:"The time the audit log entry was created."`
+	ExternalIdentityNameID   pantherlog.String     `json:"external_identity_nameid" panther:"email" description:"The SAML NameID or SCIM identity of the user, usually an email address."`
+	ExternalIdentityUsername pantherlog.String     `json:"external_identity_username" panther:"username" description:"The username of the user in the identity provider."`
+	HashedToken              pantherlog.String     `json:"hashed_token" description:"The SHA-256 hash of the access token used to authenticate the action."`
+	OperationType            pantherlog.String     `json:"operation_type" description:"The type of operation performed (create, access, modify, remove, authentication, transfer, restore)."`
+	Org                      pantherlog.String     `json:"org" description:"The name of the organization affected by the action."`
+	OrgID                    pantherlog.Int64      `json:"org_id" description:"The ID of the organization affected by the action."`
+	Permission               pantherlog.String     `json:"permission" description:"The permission granted or changed by the action."`
+	ProgrammaticAccessType   pantherlog.String     `json:"programmatic_access_type" description:"The type of programmatic access used (e.g. Personal access token, OAuth access token, GitHub App server-to-server token)."`
+	PublicRepo               pantherlog.Bool       `json:"public_repo" description:"Whether the repository is public."`
+	Repo                     pantherlog.String     `json:"repo" description:"The name of the repository affected by the action, with its owner."`
+	RepoID                   pantherlog.Int64      `json:"repo_id" description:"The ID of the repository affected by the action."`
+	Team                     pantherlog.String     `json:"team" description:"The name of the team affected by the action."`
+	TokenID                  pantherlog.Int64      `json:"token_id" description:"The ID of the access token used to authenticate the action."`
+	TokenScopes              pantherlog.String     `json:"token_scopes" description:"The scopes of the access token used to authenticate the action."`
+	Topic                    pantherlog.String     `json:"topic" description:"The repository topic affected by the action."`
+	TransportProtocol        pantherlog.Int64      `json:"transport_protocol" description:"The ID of the protocol used to access a repository."`
+	TransportProtocolName    pantherlog.String     `json:"transport_protocol_name" description:"The name of the protocol used to access a repository (http, ssh)."`
+	User                     pantherlog.String     `json:"user" panther:"username" description:"The login of the user affected by the action."`
+	UserID                   pantherlog.Int64      `json:"user_id" description:"The ID of the user affected by the action."`
+	UserAgent                pantherlog.String     `json:"user_agent" description:"The user agent of the actor."`
+	Visibility               pantherlog.String     `json:"visibility" description:"The visibility of the affected resource (public, private, internal)."`
+	Config                   pantherlog.RawMessage `json:"config" description:"The configuration of a webhook or integration changed by the action."`
+	Data                     pantherlog.RawMessage `json:"data" description:"Additional data about the action."`
+}
+
+type ActorLocation struct {
+	CountryCode pantherlog.String `json:"country_code" description:"The ISO 3166-1 alpha-2 code of the country."`
+}
@@ -0,0 +1,108 @@
+// Package paloaltologs provides parsers for Palo Alto Networks PAN-OS logs
+package paloaltologs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/preprocessors"
+)
+
+const (
+	TypeSystem  = "PaloAlto.System"
+	TypeThreat  = "PaloAlto.Threat"
+	TypeTraffic = "PaloAlto.Traffic"
+)
+
+// LogTypes exports the available log type entries
+func LogTypes() logtypes.Group {
+	return logTypes
+}
+
+// nolint:lll
+var logTypes = logtypes.Must("PaloAlto",
+	logtypes.Config{
+		Name:         TypeSystem,
+		Description:  `PAN-OS system logs show system events on the firewall, such as HA failures, link status changes and administrator logins.`,
+		ReferenceURL: `https://docs.paloaltonetworks.com/pan-os/10-0/pan-os-admin/monitoring/use-syslog-for-monitoring/syslog-field-descriptions/system-log-fields.html`,
+		Schema:       pantherlog.MustBuildEventSchema(System{}, pantherlog.FieldIPAddress),
+		NewParser:    newParser(TypeSystem, systemColumns, func() interface{} { return &System{} }),
+	},
+	logtypes.Config{
+		Name:         TypeThreat,
+		Description:  `PAN-OS threat logs display entries when traffic matches a Security Profile attached to a security rule on the firewall.`,
+		ReferenceURL: `https://docs.paloaltonetworks.com/pan-os/10-0/pan-os-admin/monitoring/use-syslog-for-monitoring/syslog-field-descriptions/threat-log-fields.html`,
+		Schema:       pantherlog.MustBuildEventSchema(Threat{}, pantherlog.FieldPort),
+		NewParser:    newParser(TypeThreat, threatColumns, func() interface{} { return &Threat{} }),
+	},
+	logtypes.Config{
+		Name:         TypeTraffic,
+		Description:  `PAN-OS traffic logs display an entry for the start and end of each session.`,
+		ReferenceURL: `https://docs.paloaltonetworks.com/pan-os/10-0/pan-os-admin/monitoring/use-syslog-for-monitoring/syslog-field-descriptions/traffic-log-fields.html`,
+		Schema:       pantherlog.MustBuildEventSchema(Traffic{}, pantherlog.FieldPort),
+		NewParser:    newParser(TypeTraffic, trafficColumns, func() interface{} { return &Traffic{} }),
+	},
+)
+
+// newParser creates a parser factory for PAN-OS logs in CSV format.
+// The CSV fields are converted to a JSON object using the columns of the log type.
+func newParser(logType string, columns []string, newEvent func() interface{}) pantherlog.FactoryFunc {
+	return func(_ interface{}) (pantherlog.LogParser, error) {
+		csv, err := preprocessors.CSVMatchConfig{
+			Columns:     columns,
+			EmptyValues: []string{""},
+		}.BuildPreprocessor()
+		if err != nil {
+			return nil, err
+		}
+		parser, err := (&pantherlog.JSONParserFactory{
+			LogType:  logType,
+			NewEvent: newEvent,
+		}).NewParser(nil)
+		if err != nil {
+			return nil, err
+		}
+		return preprocessors.Wrap(parser, &syslogHeader{}, csv), nil
+	}
+}
+
+// syslogHeader strips the syslog header from PAN-OS logs forwarded over syslog
+// (`<14>Jan 14 10:15:22 PA-VM 1,2021/01/14 10:15:22,...`).
+type syslogHeader struct{}
+
+var _ preprocessors.Interface = (*syslogHeader)(nil)
+
+// PreProcessLog implements preprocessors.Interface
+func (*syslogHeader) PreProcessLog(log string) (string, error) {
+	log = strings.TrimSpace(log)
+	pos := strings.IndexByte(log, ',')
+	if pos == -1 {
+		return "", errors.New("invalid PAN-OS log")
+	}
+	// The first CSV field has no spaces so anything before the last space is the syslog header.
+	if start := strings.LastIndexByte(log[:pos], ' '); start != -1 {
+		return log[start+1:], nil
+	}
+	return log, nil
+}
@@ -0,0 +1,85 @@
+package paloaltologs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+// System is a PAN-OS system log entry.
+// nolint:lll
+type System struct {
+	ReceiveTime           pantherlog.Time   `json:"receiveTime" tcodec:"layout=2006/01/02 15:04:05" description:"Time the log was received at the management plane."`
+	SerialNumber          pantherlog.String `json:"serialNumber" description:"Serial number of the firewall that generated the log."`
+	Type                  pantherlog.String `json:"type" validate:"required,eq=SYSTEM" description:"Specifies the type of log (SYSTEM)."`
+	Subtype               pantherlog.String `json:"subtype" description:"Subtype of the system log; refers to the system daemon generating the log (crypto, dhcp, dnsproxy, dos, general, global-protect, ha, hw, nat, ntpd, pbf, port, pppoe, ras, routing, satd, sslmgr, sslvpn, userid, url-filtering, vpn)."`
+	GeneratedTime         pantherlog.Time   `json:"generatedTime" validate:"required" tcodec:"layout=2006/01/02 15:04:05" event_time:"true" description:"Time the log was generated on the dataplane."`
+	VirtualSystem         pantherlog.String `json:"virtualSystem" description:"Virtual System associated with the event."`
+	EventID               pantherlog.String `json:"eventId" description:"String showing the name of the event."`
+	Object                pantherlog.String `json:"object" description:"Name of the object associated with the system event."`
+	Module                pantherlog.String `json:"module" description:"This field is valid only when the value of the Subtype field is general. It provides additional information about the sub-system generating the log."`
+	Severity              pantherlog.String `json:"severity" description:"Severity associated with the event (informational, low, medium, high, critical)."`
+	Description           pantherlog.String `json:"description" description:"Detailed description of the event."`
+	SequenceNumber        pantherlog.Int64  `json:"sequenceNumber" description:"A 64-bit log entry identifier incremented sequentially; each log type has a unique number space."`
+	ActionFlags           pantherlog.String `json:"actionFlags" description:"A bit field indicating if the log was forwarded to Panorama."`
+	DeviceGroupHierarchy1 pantherlog.Int64  `json:"deviceGroupHierarchyLevel1" description:"The ID of the device group at level 1 of the hierarchy."`
+	DeviceGroupHierarchy2 pantherlog.Int64  `json:"deviceGroupHierarchyLevel2" description:"The ID of the device group at level 2 of the hierarchy."`
+	DeviceGroupHierarchy3 pantherlog.Int64  `json:"deviceGroupHierarchyLevel3" description:"The ID of the device group at level 3 of the hierarchy."`
+	DeviceGroupHierarchy4 pantherlog.Int64  `json:"deviceGroupHierarchyLevel4" description:"The ID of the device group at level 4 of the hierarchy."`
+	VirtualSystemName     pantherlog.String `json:"virtualSystemName" description:"The name of the virtual system associated with the event."`
+	DeviceName            pantherlog.String `json:"deviceName" description:"The hostname of the firewall on which the event was logged."`
+}
+
+var systemColumns = []string{
+	"",
+	"receiveTime",
+	"serialNumber",
+	"type",
+	"subtype",
+	"",
+	"generatedTime",
+	"virtualSystem",
+	"eventId",
+	"object",
+	"",
+	"",
+	"module",
+	"severity",
+	"description",
+	"sequenceNumber",
+	"actionFlags",
+	"deviceGroupHierarchyLevel1",
+	"deviceGroupHierarchyLevel2",
+	"deviceGroupHierarchyLevel3",
+	"deviceGroupHierarchyLevel4",
+	"virtualSystemName",
+	"deviceName",
+}
+
+var _ pantherlog.ValueWriterTo = (*System)(nil)
+
+// WriteValuesTo implements pantherlog.ValueWriterTo interface.
+// The descriptions of authentication events include the IP address of the client.
+func (event *System) WriteValuesTo(w pantherlog.ValueWriter) {
+	for _, field := range strings.Fields(event.Description.Value) {
+		pantherlog.ScanIPAddress(w, strings.Trim(field, ".,;:()'\""))
+	}
+}
@@ -0,0 +1,188 @@
+package paloaltologs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+// Threat is a PAN-OS threat log entry.
+// nolint:lll,maligned
+type Threat struct {
+	ReceiveTime           pantherlog.Time   `json:"receiveTime" tcodec:"layout=2006/01/02 15:04:05" description:"Time the log was received at the management plane."`
+	SerialNumber          pantherlog.String `json:"serialNumber" description:"Serial number of the firewall that generated the log."`
+	Type                  pantherlog.String `json:"type" validate:"required,eq=THREAT" description:"Specifies the type of log (THREAT)."`
+	Subtype               pantherlog.String `json:"subtype" description:"Subtype of threat log (data, file, flood, packet, scan, spyware, url, ml-virus, virus, vulnerability, wildfire, wildfire-virus)."`
+	GeneratedTime         pantherlog.Time   `json:"generatedTime" validate:"required" tcodec:"layout=2006/01/02 15:04:05" event_time:"true" description:"Time the log was generated on the dataplane."`
+	SourceAddress         pantherlog.String `json:"sourceAddress" panther:"ip" description:"Original session source IP address."`
+	DestinationAddress    pantherlog.String `json:"destinationAddress" panther:"ip" description:"Original session destination IP address."`
+	NATSourceIP           pantherlog.String `json:"natSourceIp" panther:"ip" description:"If Source NAT performed, the post-NAT Source IP address."`
+	NATDestinationIP      pantherlog.String `json:"natDestinationIp" panther:"ip" description:"If Destination NAT performed, the post-NAT Destination IP address."`
+	RuleName              pantherlog.String `json:"ruleName" description:"Name of the rule that the session matched."`
+	SourceUser            pantherlog.String `json:"sourceUser" panther:"username" description:"Username of the user who initiated the session."`
+	DestinationUser       pantherlog.String `json:"destinationUser" panther:"username" description:"Username of the user to which the session was destined."`
+	Application           pantherlog.String `json:"application" description:"Application associated with the session."`
+	VirtualSystem         pantherlog.String `json:"virtualSystem" description:"Virtual System associated with the session."`
+	SourceZone            pantherlog.String `json:"sourceZone" description:"Zone the session was sourced from."`
+	DestinationZone       pantherlog.String `json:"destinationZone" description:"Zone the session was destined to."`
+	InboundInterface      pantherlog.String `json:"inboundInterface" description:"Interface that the session was sourced from."`
+	OutboundInterface     pantherlog.String `json:"outboundInterface" description:"Interface that the session was destined to."`
+	LogAction             pantherlog.String `json:"logAction" description:"Log Forwarding Profile that was applied to the session."`
+	SessionID             pantherlog.Int64  `json:"sessionId" description:"An internal numerical identifier applied to each session."`
+	RepeatCount           pantherlog.Int64  `json:"repeatCount" description:"Number of sessions with same Source IP, Destination IP, Application, and Subtype seen within 5 seconds."`
+	SourcePort            pantherlog.Uint16 `json:"sourcePort" description:"Source port utilized by the session."`
+	DestinationPort       pantherlog.Uint16 `json:"destinationPort" description:"Destination port utilized by the session."`
+	NATSourcePort         pantherlog.Uint16 `json:"natSourcePort" description:"Post-NAT source port."`
+	NATDestinationPort    pantherlog.Uint16 `json:"natDestinationPort" description:"Post-NAT destination port."`
+	Flags                 pantherlog.String `json:"flags" description:"32-bit field that provides details on session (hexadecimal)."`
+	Protocol              pantherlog.String `json:"protocol" description:"IP protocol associated with the session."`
+	Action                pantherlog.String `json:"action" description:"Action taken for the session (alert, allow, deny, drop, drop-all-packets, reset-client, reset-server, reset-both, block-url)."`
+	URL                   pantherlog.String `json:"url" description:"The URL for URL Filtering logs or the name of the file for file type logs."`
+	ThreatID              pantherlog.String `json:"threatId" description:"Palo Alto Networks identifier for known and custom threats (name and ID)."`
+	Category              pantherlog.String `json:"category" description:"For URL Subtype, it is the URL Category; For WildFire subtype, it is the verdict on the file."`
+	Severity              pantherlog.String `json:"severity" description:"Severity associated with the threat (informational, low, medium, high, critical)."`
+	Direction             pantherlog.String `json:"direction" description:"Indicates the direction of the attack, client-to-server or server-to-client."`
+	SequenceNumber        pantherlog.Int64  `json:"sequenceNumber" description:"A 64-bit log entry identifier incremented sequentially; each log type has a unique number space."`
+	ActionFlags           pantherlog.String `json:"actionFlags" description:"A bit field indicating if the log was forwarded to Panorama."`
+	SourceCountry         pantherlog.String `json:"sourceCountry" description:"Source country or Internal region for private addresses."`
+	DestinationCountry    pantherlog.String `json:"destinationCountry" description:"Destination country or Internal region for private addresses."`
+	ContentType           pantherlog.String `json:"contentType" description:"Content type of the HTTP response data. Applicable only to URL Filtering subtype."`
+	PCAPID                pantherlog.Int64  `json:"pcapId" description:"Packet capture (pcap) ID is a 64 bit unsigned integral denoting an ID to correlate threat pcap files with extended pcaps taken as a part of that flow."`
+	FileDigest            pantherlog.String `json:"fileDigest" panther:"sha256" description:"Only for WildFire subtype; all other types do not use this field. The filedigest string shows the binary hash of the file sent to be analyzed by the WildFire service."`
+	Cloud                 pantherlog.String `json:"cloud" description:"Only for WildFire subtype; the FQDN of either the WildFire appliance (private) or the WildFire cloud (public) from where the file was uploaded for analysis."`
+	URLIndex              pantherlog.Int64  `json:"urlIndex" description:"Used in URL Filtering and WildFire subtypes to correlate logs."`
+	UserAgent             pantherlog.String `json:"userAgent" description:"Only for the URL Filtering subtype; the User Agent field specifies the web browser that the user used to access the URL."`
+	FileType              pantherlog.String `json:"fileType" description:"Only for the WildFire subtype; specifies the type of file that the firewall forwarded for WildFire analysis."`
+	XForwardedFor         pantherlog.String `json:"xForwardedFor" panther:"ip" description:"Only for the URL Filtering subtype; the IP address of the user who requested the web page."`
+	Referer               pantherlog.String `json:"referer" description:"Only for the URL Filtering subtype; the Referer field in the HTTP header."`
+	Sender                pantherlog.String `json:"sender" panther:"email" description:"Only for WildFire subtype; the email address of the sender of an email that WildFire determined to be malicious."`
+	Subject               pantherlog.String `json:"subject" description:"Only for WildFire subtype; the subject of an email that WildFire determined to be malicious."`
+	Recipient             pantherlog.String `json:"recipient" panther:"email" description:"Only for WildFire subtype; the email address of the recipient of an email that WildFire determined to be malicious."`
+	ReportID              pantherlog.String `json:"reportId" description:"Only for WildFire subtype; the identifier for the request on the WildFire cloud or appliance."`
+	DeviceGroupHierarchy1 pantherlog.Int64  `json:"deviceGroupHierarchyLevel1" description:"The ID of the device group at level 1 of the hierarchy."`
+	DeviceGroupHierarchy2 pantherlog.Int64  `json:"deviceGroupHierarchyLevel2" description:"The ID of the device group at level 2 of the hierarchy."`
+	DeviceGroupHierarchy3 pantherlog.Int64  `json:"deviceGroupHierarchyLevel3" description:"The ID of the device group at level 3 of the hierarchy."`
+	DeviceGroupHierarchy4 pantherlog.Int64  `json:"deviceGroupHierarchyLevel4" description:"The ID of the device group at level 4 of the hierarchy."`
+	VirtualSystemName     pantherlog.String `json:"virtualSystemName" description:"The name of the virtual system associated with the session."`
+	DeviceName            pantherlog.String `json:"deviceName" description:"The hostname of the firewall on which the session was logged."`
+	SourceVMUUID          pantherlog.String `json:"sourceVmUuid" description:"Identifies the source universal unique identifier for a guest virtual machine in the VMware NSX environment."`
+	DestinationVMUUID     pantherlog.String `json:"destinationVmUuid" description:"Identifies the destination universal unique identifier for a guest virtual machine in the VMware NSX environment."`
+	HTTPMethod            pantherlog.String `json:"httpMethod" description:"Only in URL filtering logs. Describes the HTTP Method used in the web request."`
+	TunnelID              pantherlog.String `json:"tunnelId" description:"ID of the tunnel being inspected or the International Mobile Subscriber Identity (IMSI) ID of the mobile user."`
+	MonitorTag            pantherlog.String `json:"monitorTag" description:"Monitor name configured for the Tunnel Inspection policy rule or the International Mobile Equipment Identity (IMEI) ID of the mobile device."`
+	ParentSessionID       pantherlog.Int64  `json:"parentSessionId" description:"ID of the session in which this session is tunneled."`
+	ParentStartTime       pantherlog.Time   `json:"parentStartTime" tcodec:"layout=2006/01/02 15:04:05" description:"Time the parent tunnel session began."`
+	TunnelType            pantherlog.String `json:"tunnelType" description:"Type of tunnel, such as GRE or IPSec."`
+	ThreatCategory        pantherlog.String `json:"threatCategory" description:"Describes threat categories used to classify different types of threat signatures."`
+	ContentVersion        pantherlog.String `json:"contentVersion" description:"Applications and Threats version on your firewall when the log was generated."`
+	SCTPAssociationID     pantherlog.Int64  `json:"sctpAssociationId" description:"Number that identifies all connections for an association between two SCTP endpoints."`
+	PayloadProtocolID     pantherlog.String `json:"payloadProtocolId" description:"ID of the protocol for the payload in the data portion of the data chunk."`
+	HTTPHeaders           pantherlog.String `json:"httpHeaders" description:"Indicates the inserted HTTP header."`
+	URLCategoryList       pantherlog.String `json:"urlCategoryList" description:"Lists the URL Filtering categories that the firewall used to enforce policy."`
+	RuleUUID              pantherlog.String `json:"ruleUuid" description:"The UUID that permanently identifies the rule."`
+	HTTP2Connection       pantherlog.String `json:"http2Connection" description:"Identifies if traffic used an HTTP/2 connection by displaying the parent session ID or 0."`
+}
+
+var threatColumns = []string{
+	"",
+	"receiveTime",
+	"serialNumber",
+	"type",
+	"subtype",
+	"",
+	"generatedTime",
+	"sourceAddress",
+	"destinationAddress",
+	"natSourceIp",
+	"natDestinationIp",
+	"ruleName",
+	"sourceUser",
+	"destinationUser",
+	"application",
+	"virtualSystem",
+	"sourceZone",
+	"destinationZone",
+	"inboundInterface",
+	"outboundInterface",
+	"logAction",
+	"",
+	"sessionId",
+	"repeatCount",
+	"sourcePort",
+	"destinationPort",
+	"natSourcePort",
+	"natDestinationPort",
+	"flags",
+	"protocol",
+	"action",
+	"url",
+	"threatId",
+	"category",
+	"severity",
+	"direction",
+	"sequenceNumber",
+	"actionFlags",
+	"sourceCountry",
+	"destinationCountry",
+	"",
+	"contentType",
+	"pcapId",
+	"fileDigest",
+	"cloud",
+	"urlIndex",
+	"userAgent",
+	"fileType",
+	"xForwardedFor",
+	"referer",
+	"sender",
+	"subject",
+	"recipient",
+	"reportId",
+	"deviceGroupHierarchyLevel1",
+	"deviceGroupHierarchyLevel2",
+	"deviceGroupHierarchyLevel3",
+	"deviceGroupHierarchyLevel4",
+	"virtualSystemName",
+	"deviceName",
+	"",
+	"sourceVmUuid",
+	"destinationVmUuid",
+	"httpMethod",
+	"tunnelId",
+	"monitorTag",
+	"parentSessionId",
+	"parentStartTime",
+	"tunnelType",
+	"threatCategory",
+	"contentVersion",
+	"",
+	"sctpAssociationId",
+	"payloadProtocolId",
+	"httpHeaders",
+	"urlCategoryList",
+	"ruleUuid",
+	"http2Connection",
+}
+
+var _ pantherlog.ValueWriterTo = (*Threat)(nil)
+
+// WriteValuesTo implements pantherlog.ValueWriterTo interface
+func (event *Threat) WriteValuesTo(w pantherlog.ValueWriter) {
+	pantherlog.WritePorts(w, event.SourcePort, event.DestinationPort, event.NATSourcePort, event.NATDestinationPort)
+}
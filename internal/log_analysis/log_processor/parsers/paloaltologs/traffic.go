@@ -0,0 +1,167 @@
+package paloaltologs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+// Traffic is a PAN-OS traffic log entry.
+// nolint:lll,maligned
+type Traffic struct {
+	ReceiveTime           pantherlog.Time   `json:"receiveTime" tcodec:"layout=2006/01/02 15:04:05" description:"Time the log was received at the management plane."`
+	SerialNumber          pantherlog.String `json:"serialNumber" description:"Serial number of the firewall that generated the log."`
+	Type                  pantherlog.String `json:"type" validate:"required,eq=TRAFFIC" description:"Specifies the type of log (TRAFFIC)."`
+	Subtype               pantherlog.String `json:"subtype" description:"Subtype of traffic log (start, end, drop, deny)."`
+	GeneratedTime         pantherlog.Time   `json:"generatedTime" validate:"required" tcodec:"layout=2006/01/02 15:04:05" event_time:"true" description:"Time the log was generated on the dataplane."`
+	SourceAddress         pantherlog.String `json:"sourceAddress" panther:"ip" description:"Original session source IP address."`
+	DestinationAddress    pantherlog.String `json:"destinationAddress" panther:"ip" description:"Original session destination IP address."`
+	NATSourceIP           pantherlog.String `json:"natSourceIp" panther:"ip" description:"If Source NAT performed, the post-NAT Source IP address."`
+	NATDestinationIP      pantherlog.String `json:"natDestinationIp" panther:"ip" description:"If Destination NAT performed, the post-NAT Destination IP address."`
+	RuleName              pantherlog.String `json:"ruleName" description:"Name of the rule that the session matched."`
+	SourceUser            pantherlog.String `json:"sourceUser" panther:"username" description:"Username of the user who initiated the session."`
+	DestinationUser       pantherlog.String `json:"destinationUser" panther:"username" description:"Username of the user to which the session was destined."`
+	Application           pantherlog.String `json:"application" description:"Application associated with the session."`
+	VirtualSystem         pantherlog.String `json:"virtualSystem" description:"Virtual System associated with the session."`
+	SourceZone            pantherlog.String `json:"sourceZone" description:"Zone the session was sourced from."`
+	DestinationZone       pantherlog.String `json:"destinationZone" description:"Zone the session was destined to."`
+	InboundInterface      pantherlog.String `json:"inboundInterface" description:"Interface that the session was sourced from."`
+	OutboundInterface     pantherlog.String `json:"outboundInterface" description:"Interface that the session was destined to."`
+	LogAction             pantherlog.String `json:"logAction" description:"Log Forwarding Profile that was applied to the session."`
+	SessionID             pantherlog.Int64  `json:"sessionId" description:"An internal numerical identifier applied to each session."`
+	RepeatCount           pantherlog.Int64  `json:"repeatCount" description:"Number of sessions with same Source IP, Destination IP, Application, and Subtype seen within 5 seconds."`
+	SourcePort            pantherlog.Uint16 `json:"sourcePort" description:"Source port utilized by the session."`
+	DestinationPort       pantherlog.Uint16 `json:"destinationPort" description:"Destination port utilized by the session."`
+	NATSourcePort         pantherlog.Uint16 `json:"natSourcePort" description:"Post-NAT source port."`
+	NATDestinationPort    pantherlog.Uint16 `json:"natDestinationPort" description:"Post-NAT destination port."`
+	Flags                 pantherlog.String `json:"flags" description:"32-bit field that provides details on session (hexadecimal)."`
+	Protocol              pantherlog.String `json:"protocol" description:"IP protocol associated with the session."`
+	Action                pantherlog.String `json:"action" description:"Action taken for the session (allow, deny, drop, reset-client, reset-server, reset-both)."`
+	Bytes                 pantherlog.Int64  `json:"bytes" description:"Number of total bytes (transmit and receive) for the session."`
+	BytesSent             pantherlog.Int64  `json:"bytesSent" description:"Number of bytes in the client-to-server direction of the session."`
+	BytesReceived         pantherlog.Int64  `json:"bytesReceived" description:"Number of bytes in the server-to-client direction of the session."`
+	Packets               pantherlog.Int64  `json:"packets" description:"Number of total packets (transmit and receive) for the session."`
+	StartTime             pantherlog.Time   `json:"startTime" tcodec:"layout=2006/01/02 15:04:05" description:"Time of session start."`
+	ElapsedTime           pantherlog.Int64  `json:"elapsedTime" description:"Elapsed time of the session in seconds."`
+	Category              pantherlog.String `json:"category" description:"URL category associated with the session (if applicable)."`
+	SequenceNumber        pantherlog.Int64  `json:"sequenceNumber" description:"A 64-bit log entry identifier incremented sequentially; each log type has a unique number space."`
+	ActionFlags           pantherlog.String `json:"actionFlags" description:"A bit field indicating if the log was forwarded to Panorama."`
+	SourceCountry         pantherlog.String `json:"sourceCountry" description:"Source country or Internal region for private addresses."`
+	DestinationCountry    pantherlog.String `json:"destinationCountry" description:"Destination country or Internal region for private addresses."`
+	PacketsSent           pantherlog.Int64  `json:"packetsSent" description:"Number of client-to-server packets for the session."`
+	PacketsReceived       pantherlog.Int64  `json:"packetsReceived" description:"Number of server-to-client packets for the session."`
+	SessionEndReason      pantherlog.String `json:"sessionEndReason" description:"The reason a session terminated."`
+	DeviceGroupHierarchy1 pantherlog.Int64  `json:"deviceGroupHierarchyLevel1" description:"The ID of the device group at level 1 of the hierarchy."`
+	DeviceGroupHierarchy2 pantherlog.Int64  `json:"deviceGroupHierarchyLevel2" description:"The ID of the device group at level 2 of the hierarchy."`
+	DeviceGroupHierarchy3 pantherlog.Int64  `json:"deviceGroupHierarchyLevel3" description:"The ID of the device group at level 3 of the hierarchy."`
+	DeviceGroupHierarchy4 pantherlog.Int64  `json:"deviceGroupHierarchyLevel4" description:"The ID of the device group at level 4 of the hierarchy."`
+	VirtualSystemName     pantherlog.String `json:"virtualSystemName" description:"The name of the virtual system associated with the session."`
+	DeviceName            pantherlog.String `json:"deviceName" description:"The hostname of the firewall on which the session was logged."`
+	ActionSource          pantherlog.String `json:"actionSource" description:"Specifies whether the action taken to allow or block an application was defined in the application or in policy."`
+	SourceVMUUID          pantherlog.String `json:"sourceVmUuid" description:"Identifies the source universal unique identifier for a guest virtual machine in the VMware NSX environment."`
+	DestinationVMUUID     pantherlog.String `json:"destinationVmUuid" description:"Identifies the destination universal unique identifier for a guest virtual machine in the VMware NSX environment."`
+	TunnelID              pantherlog.String `json:"tunnelId" description:"ID of the tunnel being inspected or the International Mobile Subscriber Identity (IMSI) ID of the mobile user."`
+	MonitorTag            pantherlog.String `json:"monitorTag" description:"Monitor name configured for the Tunnel Inspection policy rule or the International Mobile Equipment Identity (IMEI) ID of the mobile device."`
+	ParentSessionID       pantherlog.Int64  `json:"parentSessionId" description:"ID of the session in which this session is tunneled."`
+	ParentStartTime       pantherlog.Time   `json:"parentStartTime" tcodec:"layout=2006/01/02 15:04:05" description:"Time the parent tunnel session began."`
+	TunnelType            pantherlog.String `json:"tunnelType" description:"Type of tunnel, such as GRE or IPSec."`
+	SCTPAssociationID     pantherlog.Int64  `json:"sctpAssociationId" description:"Number that identifies all connections for an association between two SCTP endpoints."`
+	SCTPChunks            pantherlog.Int64  `json:"sctpChunks" description:"Sum of SCTP chunks sent and received for an association."`
+	SCTPChunksSent        pantherlog.Int64  `json:"sctpChunksSent" description:"Number of SCTP chunks sent for an association."`
+	SCTPChunksReceived    pantherlog.Int64  `json:"sctpChunksReceived" description:"Number of SCTP chunks received for an association."`
+	RuleUUID              pantherlog.String `json:"ruleUuid" description:"The UUID that permanently identifies the rule."`
+	HTTP2Connection       pantherlog.String `json:"http2Connection" description:"Identifies if traffic used an HTTP/2 connection by displaying the parent session ID or 0."`
+}
+
+var trafficColumns = []string{
+	"",
+	"receiveTime",
+	"serialNumber",
+	"type",
+	"subtype",
+	"",
+	"generatedTime",
+	"sourceAddress",
+	"destinationAddress",
+	"natSourceIp",
+	"natDestinationIp",
+	"ruleName",
+	"sourceUser",
+	"destinationUser",
+	"application",
+	"virtualSystem",
+	"sourceZone",
+	"destinationZone",
+	"inboundInterface",
+	"outboundInterface",
+	"logAction",
+	"",
+	"sessionId",
+	"repeatCount",
+	"sourcePort",
+	"destinationPort",
+	"natSourcePort",
+	"natDestinationPort",
+	"flags",
+	"protocol",
+	"action",
+	"bytes",
+	"bytesSent",
+	"bytesReceived",
+	"packets",
+	"startTime",
+	"elapsedTime",
+	"category",
+	"",
+	"sequenceNumber",
+	"actionFlags",
+	"sourceCountry",
+	"destinationCountry",
+	"",
+	"packetsSent",
+	"packetsReceived",
+	"sessionEndReason",
+	"deviceGroupHierarchyLevel1",
+	"deviceGroupHierarchyLevel2",
+	"deviceGroupHierarchyLevel3",
+	"deviceGroupHierarchyLevel4",
+	"virtualSystemName",
+	"deviceName",
+	"actionSource",
+	"sourceVmUuid",
+	"destinationVmUuid",
+	"tunnelId",
+	"monitorTag",
+	"parentSessionId",
+	"parentStartTime",
+	"tunnelType",
+	"sctpAssociationId",
+	"sctpChunks",
+	"sctpChunksSent",
+	"sctpChunksReceived",
+	"ruleUuid",
+	"http2Connection",
+}
+
+var _ pantherlog.ValueWriterTo = (*Traffic)(nil)
+
+// WriteValuesTo implements pantherlog.ValueWriterTo interface
+func (event *Traffic) WriteValuesTo(w pantherlog.ValueWriter) {
+	pantherlog.WritePorts(w, event.SourcePort, event.DestinationPort, event.NATSourcePort, event.NATDestinationPort)
+}
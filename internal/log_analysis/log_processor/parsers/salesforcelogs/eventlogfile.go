@@ -0,0 +1,124 @@
+package salesforcelogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/preprocessors"
+)
+
+const TypeEventLogFile = `Salesforce.EventLogFile`
+
+// EventLogFile is a row of a Salesforce EventLogFile CSV.
+// The columns of each file depend on its event type, so the struct has the union of the most common columns.
+// nolint:lll,maligned
+type EventLogFile struct {
+	EventType           pantherlog.String `json:"EVENT_TYPE" validate:"required" description:"The type of event (e.g. Login, API, Report, URI)."`
+	Timestamp           pantherlog.Time   `json:"TIMESTAMP" validate:"required" tcodec:"layout=20060102150405.000" event_time:"true" description:"The time the event occurred (UTC)."`
+	TimestampDerived    pantherlog.Time   `json:"TIMESTAMP_DERIVED" tcodec:"rfc3339" description:"The time the event occurred in ISO 8601 format."`
+	RequestID           pantherlog.String `json:"REQUEST_ID" description:"The unique ID of a single transaction."`
+	OrganizationID      pantherlog.String `json:"ORGANIZATION_ID" description:"The 15-character ID of the organization."`
+	UserID              pantherlog.String `json:"USER_ID" description:"The 15-character ID of the user who performed the action."`
+	UserIDDerived       pantherlog.String `json:"USER_ID_DERIVED" description:"The 18-character ID of the user who performed the action."`
+	UserName            pantherlog.String `json:"USER_NAME" panther:"username,email" description:"The username of the user."`
+	UserType            pantherlog.String `json:"USER_TYPE" description:"The category of user license of the user (e.g. Standard, Guest, CsnOnly)."`
+	ClientIP            pantherlog.String `json:"CLIENT_IP" panther:"ip" description:"The IP address of the client that is using Salesforce services."`
+	SourceIP            pantherlog.String `json:"SOURCE_IP" panther:"ip" description:"The source IP address of the login request."`
+	SessionKey          pantherlog.String `json:"SESSION_KEY" description:"The user's unique session ID."`
+	LoginKey            pantherlog.String `json:"LOGIN_KEY" description:"The string that ties together all events in a given user's login session."`
+	URI                 pantherlog.String `json:"URI" description:"The URI of the page or endpoint receiving the request."`
+	URIIDDerived        pantherlog.String `json:"URI_ID_DERIVED" description:"The 18-character ID of the record being accessed."`
+	RequestStatus       pantherlog.String `json:"REQUEST_STATUS" description:"The status of the request (S: Success, F: Failure, U: Undefined, A: Authorization Error, R: Redirect, N: Not Found)."`
+	StatusCode          pantherlog.Int32  `json:"STATUS_CODE" description:"The HTTP status code of the response."`
+	LoginStatus         pantherlog.String `json:"LOGIN_STATUS" description:"The status of the login attempt (e.g. LOGIN_NO_ERROR, LOGIN_ERROR_INVALID_PASSWORD)."`
+	BrowserType         pantherlog.String `json:"BROWSER_TYPE" description:"The user agent of the browser or client."`
+	UserAgent           pantherlog.String `json:"USER_AGENT" description:"The user agent of the client."`
+	APIType             pantherlog.String `json:"API_TYPE" description:"The type of API request (e.g. E: Enterprise, P: Partner, R: REST)."`
+	APIVersion          pantherlog.String `json:"API_VERSION" description:"The version of the API being used."`
+	ClientName          pantherlog.String `json:"CLIENT_NAME" description:"The name of the client that is using Salesforce services."`
+	ConnectedAppID      pantherlog.String `json:"CONNECTED_APP_ID" description:"The 15-character ID of the connected app."`
+	MethodName          pantherlog.String `json:"METHOD_NAME" description:"The name of the API or Apex method that was invoked."`
+	EntityName          pantherlog.String `json:"ENTITY_NAME" description:"The name of the object affected by the request."`
+	RowsProcessed       pantherlog.Int64  `json:"ROWS_PROCESSED" description:"The number of rows that were processed in the request."`
+	ReportIDDerived     pantherlog.String `json:"REPORT_ID_DERIVED" description:"The 18-character ID of the report that was run or exported."`
+	DelegatedUserID     pantherlog.String `json:"DELEGATED_USER_ID" description:"The 15-character ID of the user that an administrator logged in as."`
+	DelegatedUserName   pantherlog.String `json:"DELEGATED_USER_NAME" panther:"username,email" description:"The username of the user that an administrator logged in as."`
+	TLSProtocol         pantherlog.String `json:"TLS_PROTOCOL" description:"The TLS protocol version used for the login."`
+	CipherSuite         pantherlog.String `json:"CIPHER_SUITE" description:"The TLS cipher suite used for the login."`
+	AuthMethodReference pantherlog.String `json:"AUTHENTICATION_METHOD_REFERENCE" description:"The authentication method used by a third-party identification provider."`
+	RunTime             pantherlog.Int64  `json:"RUN_TIME" description:"The amount of time that the request took in milliseconds."`
+	CPUTime             pantherlog.Int64  `json:"CPU_TIME" description:"The CPU time in milliseconds used to complete the request."`
+	DBTotalTime         pantherlog.Int64  `json:"DB_TOTAL_TIME" description:"The time in nanoseconds for a database round trip."`
+}
+
+func newEventLogFileParser(_ interface{}) (pantherlog.LogParser, error) {
+	csv, err := preprocessors.CSVMatchConfig{
+		HasHeader:   true,
+		EmptyValues: []string{""},
+	}.BuildPreprocessor()
+	if err != nil {
+		return nil, err
+	}
+	parser, err := (&pantherlog.JSONParserFactory{
+		LogType: TypeEventLogFile,
+		NewEvent: func() interface{} {
+			return &EventLogFile{}
+		},
+	}).NewParser(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &eventLogFileParser{
+		csv:    csv,
+		parser: parser,
+	}, nil
+}
+
+// eventLogFileParser reads the columns of an event log file from its header
+type eventLogFileParser struct {
+	csv    preprocessors.Interface
+	parser pantherlog.LogParser
+	header bool
+}
+
+var _ pantherlog.LogParser = (*eventLogFileParser)(nil)
+
+// ParseLog implements pantherlog.LogParser interface
+func (p *eventLogFileParser) ParseLog(log string) ([]*pantherlog.Result, error) {
+	log = strings.TrimSpace(log)
+	if !p.header {
+		// Avoid using arbitrary CSV rows as the header
+		if !strings.HasPrefix(strings.TrimPrefix(log, `"`), "EVENT_TYPE") {
+			return nil, errors.New("invalid EventLogFile header")
+		}
+		p.header = true
+	}
+	log, err := p.csv.PreProcessLog(log)
+	if err != nil {
+		return nil, err
+	}
+	if log == "" {
+		return nil, nil
+	}
+	return p.parser.ParseLog(log)
+}
@@ -0,0 +1,72 @@
+package salesforcelogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes/logtesting"
+)
+
+// nolint:lll
+func TestEventLogFile(t *testing.T) {
+	header := `"EVENT_TYPE","TIMESTAMP","REQUEST_ID","ORGANIZATION_ID","USER_ID","RUN_TIME","CPU_TIME","URI","USER_TYPE","REQUEST_STATUS","BROWSER_TYPE","USER_NAME","TLS_PROTOCOL","LOGIN_STATUS","TIMESTAMP_DERIVED","USER_ID_DERIVED","CLIENT_IP","URI_ID_DERIVED","SOURCE_IP"`
+	log := `"Login","20210114101610.456","4exLFFQZ5678xMGTUb0Lo-","00D5w000004qJ2x","0055w00000FgHiJ","45","20","/index.jsp","Standard","","Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7)","mallory@example.com","TLSv1.2","LOGIN_ERROR_INVALID_PASSWORD","2021-01-14T10:16:10.456Z","0055w00000FgHiJAAV","203.0.113.99","",""`
+	expect := `{
+		"EVENT_TYPE": "Login",
+		"TIMESTAMP": "20210114101610.456",
+		"REQUEST_ID": "4exLFFQZ5678xMGTUb0Lo-",
+		"ORGANIZATION_ID": "00D5w000004qJ2x",
+		"USER_ID": "0055w00000FgHiJ",
+		"RUN_TIME": 45,
+		"CPU_TIME": 20,
+		"URI": "/index.jsp",
+		"USER_TYPE": "Standard",
+		"BROWSER_TYPE": "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7)",
+		"USER_NAME": "mallory@example.com",
+		"TLS_PROTOCOL": "TLSv1.2",
+		"LOGIN_STATUS": "LOGIN_ERROR_INVALID_PASSWORD",
+		"TIMESTAMP_DERIVED": "2021-01-14T10:16:10.456Z",
+		"USER_ID_DERIVED": "0055w00000FgHiJAAV",
+		"CLIENT_IP": "203.0.113.99",
+		"p_log_type": "Salesforce.EventLogFile",
+		"p_event_time": "2021-01-14T10:16:10.456Z",
+		"p_any_emails": ["mallory@example.com"],
+		"p_any_ip_addresses": ["203.0.113.99"],
+		"p_any_usernames": ["mallory@example.com"]
+	}`
+	p, err := LogTypes().Find(TypeEventLogFile).NewParser(nil)
+	require.NoError(t, err)
+	results, err := p.ParseLog(header)
+	require.NoError(t, err)
+	require.Empty(t, results)
+	results, err = p.ParseLog(log)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	logtesting.TestResult(t, expect, results[0])
+}
+
+func TestEventLogFileMissingHeader(t *testing.T) {
+	p, err := LogTypes().Find(TypeEventLogFile).NewParser(nil)
+	require.NoError(t, err)
+	_, err = p.ParseLog(`"Login","20210114101610.456","4exLFFQZ5678xMGTUb0Lo-"`)
+	require.Error(t, err)
+}
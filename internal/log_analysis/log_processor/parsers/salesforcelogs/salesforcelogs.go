@@ -0,0 +1,37 @@
+package salesforcelogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+func LogTypes() logtypes.Group {
+	return logTypes
+}
+
+// nolint: lll
+var logTypes = logtypes.Must("Salesforce", logtypes.Config{
+	Name:         TypeEventLogFile,
+	Description:  `Salesforce event monitoring log files (EventLogFile) for login, API, report, URI and other event types.`,
+	ReferenceURL: `https://developer.salesforce.com/docs/atlas.en-us.object_reference.meta/object_reference/sforce_api_objects_eventlogfile.htm`,
+	Schema:       EventLogFile{},
+	NewParser:    pantherlog.FactoryFunc(newEventLogFileParser),
+})
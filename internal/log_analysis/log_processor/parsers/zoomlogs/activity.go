@@ -0,0 +1,35 @@
+package zoomlogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+const TypeActivity = `Zoom.Activity`
+
+// nolint:lll
+type Activity struct {
+	Email      pantherlog.String `json:"email" validate:"required" panther:"email" description:"The email address of the user."`
+	Time       pantherlog.Time   `json:"time" validate:"required" tcodec:"rfc3339" event_time:"true" description:"The time of the activity."`
+	Type       pantherlog.String `json:"type" validate:"required" description:"The type of activity (Sign in, Sign out)."`
+	IPAddress  pantherlog.String `json:"ip_address" panther:"ip" description:"The IP address of the user's device."`
+	ClientType pantherlog.String `json:"client_type" description:"The type of client used (e.g. Browser, Windows, Mac, iOS, Android)."`
+	Version    pantherlog.String `json:"version" description:"The version of the Zoom client."`
+}
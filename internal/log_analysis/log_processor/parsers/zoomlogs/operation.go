@@ -0,0 +1,46 @@
+package zoomlogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+const TypeOperation = `Zoom.Operation`
+
+// nolint:lll
+type Operation struct {
+	Time            pantherlog.Time   `json:"time" validate:"required" tcodec:"rfc3339" event_time:"true" description:"The time at which the operation was performed."`
+	Operator        pantherlog.String `json:"operator" validate:"required" panther:"email" description:"The email address of the user who performed the operation."`
+	CategoryType    pantherlog.String `json:"category_type" description:"The category of the operation (e.g. User, Account, Role, Recording)."`
+	Action          pantherlog.String `json:"action" validate:"required" description:"The action performed (e.g. Add, Update, Delete)."`
+	OperationDetail pantherlog.String `json:"operation_detail" description:"The details of the operation."`
+}
+
+var _ pantherlog.ValueWriterTo = (*Operation)(nil)
+
+// WriteValuesTo implements pantherlog.ValueWriterTo interface
+func (event *Operation) WriteValuesTo(w pantherlog.ValueWriter) {
+	// Details mention the users that were affected by the operation (e.g. "Add User jdoe@example.com - User Type: Basic")
+	for _, field := range strings.Fields(event.OperationDetail.Value) {
+		pantherlog.ScanEmail(w, strings.Trim(field, ".,;:()[]'\""))
+	}
+}
@@ -28,6 +28,17 @@ import (
 
 type stringMatcher func(dst []string, src string) ([]string, error)
 
+// MatchText creates a pre-processor that converts text to a JSON object using a match function.
+// The match function should append key/value pairs to dst.
+// Pairs with a value in emptyValues are omitted from the JSON object.
+func MatchText(match func(dst []string, src string) ([]string, error), emptyValues ...string) Interface {
+	return &matchTextPreprocessor{
+		match:       match,
+		emptyValues: emptyValues,
+		stream:      buildJSONStream(),
+	}
+}
+
 type matchTextPreprocessor struct {
 	match        stringMatcher
 	skipLines    int
@@ -68,6 +79,8 @@ func (p *matchTextPreprocessor) PreProcessLog(log string) (string, error) {
 			}
 		}
 	}
+	// Discard the JSON of the previous log line
+	p.stream.Reset(nil)
 	writeFieldsJSON(p.stream, matches)
 	// Reuse buffer
 	p.matches = matches
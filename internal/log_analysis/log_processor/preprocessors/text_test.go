@@ -19,6 +19,7 @@ package preprocessors
  */
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -31,3 +32,16 @@ func TestExpandFieldTempalte(t *testing.T) {
 	result := expandFieldTemplate(nil, fields, tpl)
 	require.Equal(t, "10", string(result))
 }
+
+func TestMatchText(t *testing.T) {
+	pp := MatchText(func(dst []string, src string) ([]string, error) {
+		fields := strings.Fields(src)
+		return append(dst, "foo", fields[0], "bar", fields[1]), nil
+	}, "-")
+	actual, err := pp.PreProcessLog("1 2")
+	require.NoError(t, err)
+	require.Equal(t, `{"foo":"1","bar":"2"}`, actual)
+	actual, err = pp.PreProcessLog("3 -")
+	require.NoError(t, err)
+	require.Equal(t, `{"foo":"3"}`, actual)
+}
@@ -23,14 +23,18 @@ import (
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes"
 	// Packages that export log types
 	apachelogs "github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/apachelogs"
+	atlassianlogs "github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/atlassianlogs"
 	awslogs "github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/awslogs"
 	boxlogs "github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/boxlogs"
+	ciscoasalogs "github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/ciscoasalogs"
 	cloudflarelogs "github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/cloudflarelogs"
 	crowdstrikelogs "github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/crowdstrikelogs"
 	duologs "github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/duologs"
 	fastlylogs "github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/fastlylogs"
 	fluentdsyslogs "github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/fluentdsyslogs"
+	fortinetlogs "github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/fortinetlogs"
 	gcplogs "github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/gcplogs"
+	githublogs "github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/githublogs"
 	gitlablogs "github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/gitlablogs"
 	gravitationallogs "github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/gravitationallogs"
 	gsuitelogs "github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/gsuitelogs"
@@ -41,12 +45,15 @@ import (
 	oneloginlogs "github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/oneloginlogs"
 	osquerylogs "github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/osquerylogs"
 	osseclogs "github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/osseclogs"
+	paloaltologs "github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/paloaltologs"
+	salesforcelogs "github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/salesforcelogs"
 	slacklogs "github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/slacklogs"
 	sophoslogs "github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/sophoslogs"
 	suricatalogs "github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/suricatalogs"
 	sysloglogs "github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/sysloglogs"
 	umbrellalogs "github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/umbrellalogs"
 	zeeklogs "github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/zeeklogs"
+	zoomlogs "github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/zoomlogs"
 )
 
 func init() {
@@ -55,10 +62,14 @@ func init() {
 
 		apachelogs.LogTypes(),
 
+		atlassianlogs.LogTypes(),
+
 		awslogs.LogTypes(),
 
 		boxlogs.LogTypes(),
 
+		ciscoasalogs.LogTypes(),
+
 		cloudflarelogs.LogTypes(),
 
 		crowdstrikelogs.LogTypes(),
@@ -69,8 +80,12 @@ func init() {
 
 		fluentdsyslogs.LogTypes(),
 
+		fortinetlogs.LogTypes(),
+
 		gcplogs.LogTypes(),
 
+		githublogs.LogTypes(),
+
 		gitlablogs.LogTypes(),
 
 		gravitationallogs.LogTypes(),
@@ -91,6 +106,10 @@ func init() {
 
 		osseclogs.LogTypes(),
 
+		paloaltologs.LogTypes(),
+
+		salesforcelogs.LogTypes(),
+
 		slacklogs.LogTypes(),
 
 		sophoslogs.LogTypes(),
@@ -102,5 +121,7 @@ func init() {
 		umbrellalogs.LogTypes(),
 
 		zeeklogs.LogTypes(),
+
+		zoomlogs.LogTypes(),
 	)
 }
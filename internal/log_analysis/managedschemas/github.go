@@ -33,6 +33,8 @@ type GitHubRepository struct {
 	Repo   string
 	Owner  string
 	Client *github.Client
+	// The release asset to download (default: managed-schemas.zip)
+	AssetName string
 }
 
 const githubAssetName = "managed-schemas.zip"
@@ -43,9 +45,13 @@ func (p *GitHubRepository) ReleaseFeed(ctx context.Context, sinceTag string) ([]
 	if err != nil {
 		return nil, err
 	}
+	assetName := p.AssetName
+	if assetName == "" {
+		assetName = githubAssetName
+	}
 	feed := make([]Release, 0, len(latestReleases))
 	for _, rel := range latestReleases {
-		r := fromGitHubRelease(rel, githubAssetName)
+		r := fromGitHubRelease(rel, assetName)
 		if !r.IsValid() {
 			continue
 		}
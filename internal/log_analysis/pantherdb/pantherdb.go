@@ -42,6 +42,9 @@ const (
 
 	TempDatabase            = "panther_temp"
 	TempDatabaseDescription = "Holds temporary tables used for processing tasks"
+
+	AlertsDatabase            = "panther_alerts"
+	AlertsDatabaseDescription = "Holds tables related to Panther alert delivery"
 )
 
 var Databases = map[string]string{
@@ -51,6 +54,7 @@ var Databases = map[string]string{
 	RuleErrorsDatabase:    RuleErrorsDatabaseDescription,
 	ViewsDatabase:         ViewsDatabaseDescription,
 	TempDatabase:          TempDatabaseDescription,
+	AlertsDatabase:        AlertsDatabaseDescription,
 }
 
 // The type of data that are stored in the Panther
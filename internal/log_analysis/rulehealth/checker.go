@@ -0,0 +1,271 @@
+package rulehealth
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/analysis/models"
+	"github.com/panther-labs/panther/internal/log_analysis/rulespec/engine"
+	"github.com/panther-labs/panther/pkg/gatewayapi"
+	"github.com/panther-labs/panther/pkg/metrics"
+)
+
+const (
+	// The user ID of changes made by Panther itself
+	systemUserID = "00000000-0000-4000-8000-000000000000"
+	// The notification is deduplicated per rule for a day, in case the rule is enabled and disabled again
+	disabledAlertDedup              = "ruleAutoDisabled"
+	disabledAlertDedupPeriodMinutes = 24 * 60
+	// Each rule needs 2 queries and GetMetricData accepts at most 500
+	maxRulesPerRequest = 250
+)
+
+// Checker disables the rules that raise errors for too many of the events they evaluate.
+// Such rules flood their destinations with error alerts and hide the events they were meant to detect.
+// A RULE_ERROR alert notifies the destinations of the rule that it was disabled.
+type Checker struct {
+	CloudWatchClient cloudwatchiface.CloudWatchAPI
+	AnalysisClient   gatewayapi.API
+	Merger           *engine.AlertMerger
+	// Rules that errored for at least this percentage of their evaluations are disabled. Zero disables the checks.
+	MaxErrorPercent float64
+	// Rules evaluated fewer times during the window are never disabled
+	MinEvaluations int
+	// The period the error rate is computed over
+	Window time.Duration
+}
+
+// Stats reports the work done for a check
+type Stats struct {
+	Checked  int
+	Disabled int
+}
+
+type ruleHealth struct {
+	ruleID      string
+	errors      float64
+	evaluations float64
+}
+
+// ErrorPercent is the percentage of the evaluations of the rule that raised an error
+func (h *ruleHealth) ErrorPercent() float64 {
+	if h.evaluations == 0 {
+		return 0
+	}
+	return 100 * h.errors / h.evaluations
+}
+
+// Run checks the rules that raised errors during the window ending at now.
+// A rule that cannot be disabled does not prevent the others from being checked; all errors are returned together.
+func (c *Checker) Run(now time.Time) (*Stats, error) {
+	stats := &Stats{}
+	if c.MaxErrorPercent <= 0 {
+		return stats, nil
+	}
+	ruleIDs, err := c.erroredRules()
+	if err != nil {
+		return stats, err
+	}
+	var errs error
+	for start := 0; start < len(ruleIDs); start += maxRulesPerRequest {
+		end := start + maxRulesPerRequest
+		if end > len(ruleIDs) {
+			end = len(ruleIDs)
+		}
+		health, err := c.ruleHealth(ruleIDs[start:end], now)
+		if err != nil {
+			return stats, err
+		}
+		for _, rule := range health {
+			stats.Checked++
+			if rule.evaluations < float64(c.MinEvaluations) || rule.ErrorPercent() < c.MaxErrorPercent {
+				continue
+			}
+			disabled, err := c.disable(rule, now)
+			if disabled {
+				stats.Disabled++
+			}
+			errs = multierr.Append(errs, err)
+		}
+	}
+	return stats, errs
+}
+
+// erroredRules lists the rules that reported errors recently
+func (c *Checker) erroredRules() ([]string, error) {
+	var ruleIDs []string
+	err := c.CloudWatchClient.ListMetricsPages(&cloudwatch.ListMetricsInput{
+		MetricName: aws.String(engine.MetricRuleErrors),
+		Namespace:  aws.String(metrics.Namespace),
+		Dimensions: []*cloudwatch.DimensionFilter{{Name: aws.String(engine.AnalysisIDDimension)}},
+	}, func(page *cloudwatch.ListMetricsOutput, _ bool) bool {
+		for _, metric := range page.Metrics {
+			for _, dimension := range metric.Dimensions {
+				if aws.StringValue(dimension.Name) == engine.AnalysisIDDimension {
+					ruleIDs = append(ruleIDs, aws.StringValue(dimension.Value))
+				}
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list rule error metrics")
+	}
+	return ruleIDs, nil
+}
+
+// ruleHealth sums the errors and evaluations of the rules over the window
+func (c *Checker) ruleHealth(ruleIDs []string, now time.Time) ([]*ruleHealth, error) {
+	health := make([]*ruleHealth, len(ruleIDs))
+	// The sum each query result is added to, by query ID
+	sums := make(map[string]*float64, 2*len(ruleIDs))
+	queries := make([]*cloudwatch.MetricDataQuery, 0, 2*len(ruleIDs))
+	for i, ruleID := range ruleIDs {
+		rule := &ruleHealth{ruleID: ruleID}
+		health[i] = rule
+		for _, metric := range []struct {
+			name string
+			sum  *float64
+		}{
+			{engine.MetricRuleErrors, &rule.errors},
+			{engine.MetricRuleEvaluations, &rule.evaluations},
+		} {
+			// IDs must start with a lowercase letter
+			id := "query" + strconv.Itoa(len(queries))
+			sums[id] = metric.sum
+			queries = append(queries, &cloudwatch.MetricDataQuery{
+				Id: aws.String(id),
+				MetricStat: &cloudwatch.MetricStat{
+					Metric: &cloudwatch.Metric{
+						Dimensions: []*cloudwatch.Dimension{
+							{Name: aws.String(engine.AnalysisIDDimension), Value: aws.String(ruleID)},
+						},
+						MetricName: aws.String(metric.name),
+						Namespace:  aws.String(metrics.Namespace),
+					},
+					Period: aws.Int64(int64(c.Window / time.Second)),
+					Stat:   aws.String("Sum"),
+				},
+			})
+		}
+	}
+
+	err := c.CloudWatchClient.GetMetricDataPages(&cloudwatch.GetMetricDataInput{
+		StartTime:         aws.Time(now.Add(-c.Window)),
+		EndTime:           aws.Time(now),
+		MetricDataQueries: queries,
+	}, func(page *cloudwatch.GetMetricDataOutput, _ bool) bool {
+		for _, result := range page.MetricDataResults {
+			sum, ok := sums[aws.StringValue(result.Id)]
+			if !ok {
+				continue
+			}
+			for _, value := range result.Values {
+				*sum += aws.Float64Value(value)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get rule metrics")
+	}
+	return health, nil
+}
+
+// disable disables the rule and notifies its destinations. It reports false if the rule was already disabled.
+func (c *Checker) disable(health *ruleHealth, now time.Time) (bool, error) {
+	var rule models.Rule
+	statusCode, err := c.AnalysisClient.Invoke(&models.LambdaInput{GetRule: &models.GetRuleInput{ID: health.ruleID}}, &rule)
+	if err != nil {
+		if statusCode == http.StatusNotFound {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "failed to get rule %s", health.ruleID)
+	}
+	if !rule.Enabled {
+		return false, nil
+	}
+
+	input := models.LambdaInput{UpdateRule: rule.UpdateInput(systemUserID)}
+	input.UpdateRule.Enabled = false
+	var updated models.Rule
+	if _, err := c.AnalysisClient.Invoke(&input, &updated); err != nil {
+		return false, errors.Wrapf(err, "failed to disable rule %s", health.ruleID)
+	}
+	zap.L().Warn("disabled rule with a high error rate",
+		zap.String("ruleId", rule.ID),
+		zap.Float64("errors", health.errors),
+		zap.Float64("evaluations", health.evaluations))
+
+	_, err = c.Merger.UpdateAlertInfo(&engine.MatchGroup{
+		RuleID:             rule.ID,
+		RuleVersion:        updated.VersionID,
+		LogTypes:           rule.LogTypes,
+		Dedup:              disabledAlertDedup,
+		DedupPeriodMinutes: disabledAlertDedupPeriodMinutes,
+		NumMatches:         1,
+		ProcessingTime:     now,
+		Title: fmt.Sprintf("Rule %s was disabled: it raised errors for %.0f%% of the events it evaluated",
+			rule.ID, health.ErrorPercent()),
+		Description: fmt.Sprintf("The rule raised %.0f errors in %.0f evaluations during the last %s. "+
+			"Fix the errors before enabling it again.", health.errors, health.evaluations, formatWindow(c.Window)),
+		Severity:       string(rule.Severity),
+		RuleError:      true,
+		NoStoredEvents: true,
+	})
+	if err != nil {
+		// The rule is disabled, the next check will not notify again
+		return true, errors.Wrapf(err, "failed to notify that rule %s was disabled", health.ruleID)
+	}
+	return true, nil
+}
+
+// formatWindow formats the check window for the alert description, e.g. "1 hour" instead of "1h0m0s"
+func formatWindow(window time.Duration) string {
+	units := []struct {
+		name     string
+		duration time.Duration
+	}{
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+	}
+	for _, unit := range units {
+		if window < unit.duration || window%unit.duration != 0 {
+			continue
+		}
+		count := int64(window / unit.duration)
+		if count == 1 {
+			return "1 " + unit.name
+		}
+		return strconv.FormatInt(count, 10) + " " + unit.name + "s"
+	}
+	return window.String()
+}
@@ -0,0 +1,176 @@
+package rulehealth
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"crypto/md5" // nolint: gosec
+	"encoding/hex"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/analysis/models"
+	"github.com/panther-labs/panther/internal/log_analysis/rulespec/engine"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+func ruleMetric(ruleID string) *cloudwatch.Metric {
+	return &cloudwatch.Metric{
+		Dimensions: []*cloudwatch.Dimension{{Name: aws.String("AnalysisID"), Value: aws.String(ruleID)}},
+		MetricName: aws.String("RuleErrors"),
+	}
+}
+
+func TestCheckerRun(t *testing.T) {
+	now := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	// The errors and evaluations of each rule during the last hour
+	counts := []struct {
+		ruleID      string
+		errors      float64
+		evaluations float64
+	}{
+		{"Noisy", 60, 100},
+		{"Rare", 5, 10},
+		{"Healthy", 1, 1000},
+		{"Disabled", 100, 100},
+	}
+
+	cwMock := &testutils.CloudWatchMock{}
+	var metrics []*cloudwatch.Metric
+	for _, c := range counts {
+		metrics = append(metrics, ruleMetric(c.ruleID))
+	}
+	cwMock.On("ListMetricsPages", mock.Anything, mock.Anything).Return(&cloudwatch.ListMetricsOutput{Metrics: metrics}, nil).Once()
+	var results []*cloudwatch.MetricDataResult
+	for i, c := range counts {
+		// CloudWatch may split the sum over several data points
+		results = append(results,
+			&cloudwatch.MetricDataResult{
+				Id:     aws.String("query" + strconv.Itoa(2*i)),
+				Values: aws.Float64Slice([]float64{c.errors / 2, c.errors / 2}),
+			},
+			&cloudwatch.MetricDataResult{
+				Id:     aws.String("query" + strconv.Itoa(2*i+1)),
+				Values: aws.Float64Slice([]float64{c.evaluations}),
+			},
+		)
+	}
+	cwMock.On("GetMetricDataPages", mock.Anything, mock.Anything).Return(&cloudwatch.GetMetricDataOutput{
+		MetricDataResults: results,
+	}, nil).Run(func(args mock.Arguments) {
+		input := args.Get(0).(*cloudwatch.GetMetricDataInput)
+		assert.Equal(t, now.Add(-time.Hour), *input.StartTime)
+		require.Len(t, input.MetricDataQueries, 8)
+		assert.Equal(t, "RuleErrors", *input.MetricDataQueries[2].MetricStat.Metric.MetricName)
+		assert.Equal(t, "Rare", *input.MetricDataQueries[2].MetricStat.Metric.Dimensions[0].Value)
+		assert.Equal(t, "RuleEvaluations", *input.MetricDataQueries[3].MetricStat.Metric.MetricName)
+		assert.Equal(t, int64(3600), *input.MetricDataQueries[3].MetricStat.Period)
+	}).Once()
+
+	analysisMock := &testutils.GatewayapiMock{}
+	getRule := func(ruleID string) interface{} {
+		return mock.MatchedBy(func(input *models.LambdaInput) bool {
+			return input.GetRule != nil && input.GetRule.ID == ruleID
+		})
+	}
+	analysisMock.On("Invoke", getRule("Noisy"), mock.Anything).Return(200, nil).Run(func(args mock.Arguments) {
+		*args.Get(1).(*models.Rule) = models.Rule{
+			ID:        "Noisy",
+			Enabled:   true,
+			Body:      "def rule(e): return e['missing']",
+			LogTypes:  []string{"AWS.CloudTrail"},
+			Severity:  "HIGH",
+			OutputIDs: []string{"output"},
+			VersionID: "v1",
+			AlertGrouping: &models.AlertGrouping{
+				Window:            models.AlertGroupingSliding,
+				MaxEventsPerAlert: 1000,
+			},
+		}
+	}).Once()
+	analysisMock.On("Invoke", getRule("Disabled"), mock.Anything).Return(200, nil).Run(func(args mock.Arguments) {
+		*args.Get(1).(*models.Rule) = models.Rule{ID: "Disabled", Enabled: false}
+	}).Once()
+	analysisMock.On("Invoke", mock.MatchedBy(func(input *models.LambdaInput) bool {
+		return input.UpdateRule != nil
+	}), mock.Anything).Return(200, nil).Run(func(args mock.Arguments) {
+		input := args.Get(0).(*models.LambdaInput).UpdateRule
+		assert.Equal(t, "Noisy", input.ID)
+		assert.False(t, input.Enabled)
+		assert.Equal(t, systemUserID, input.UserID)
+		assert.Equal(t, "def rule(e): return e['missing']", input.Body)
+		assert.Equal(t, []string{"output"}, input.OutputIDs)
+		// Disabling the rule keeps the rest of its settings
+		assert.Equal(t, &models.AlertGrouping{Window: models.AlertGroupingSliding, MaxEventsPerAlert: 1000}, input.AlertGrouping)
+		*args.Get(1).(*models.Rule) = models.Rule{ID: "Noisy", VersionID: "v2"}
+	}).Once()
+
+	ddbMock := &testutils.DynamoDBMock{}
+	ddbMock.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{
+		Attributes: map[string]*dynamodb.AttributeValue{"alertCount": {N: aws.String("1")}},
+	}, nil).Run(func(args mock.Arguments) {
+		input := args.Get(0).(*dynamodb.UpdateItemInput)
+		sum := md5.Sum([]byte("Noisy:ruleAutoDisabled:error")) // nolint: gosec
+		assert.Equal(t, hex.EncodeToString(sum[:]), *input.Key["partitionKey"].S)
+		assert.Equal(t, "RULE_ERROR", *input.ExpressionAttributeValues[":11"].S)
+		assert.True(t, *input.ExpressionAttributeValues[":19"].BOOL)
+		assert.Equal(t, "v2", *input.ExpressionAttributeValues[":10"].S)
+		assert.Equal(t, "HIGH", *input.ExpressionAttributeValues[":16"].S)
+		assert.Equal(t, "The rule raised 60 errors in 100 evaluations during the last 1 hour. "+
+			"Fix the errors before enabling it again.", *input.ExpressionAttributeValues[":14"].S)
+	}).Once()
+
+	checker := &Checker{
+		CloudWatchClient: cwMock,
+		AnalysisClient:   analysisMock,
+		Merger:           &engine.AlertMerger{DynamoDBClient: ddbMock, TableName: "dedup"},
+		MaxErrorPercent:  50,
+		MinEvaluations:   100,
+		Window:           time.Hour,
+	}
+	stats, err := checker.Run(now)
+	require.NoError(t, err)
+	assert.Equal(t, &Stats{Checked: 4, Disabled: 1}, stats)
+	cwMock.AssertExpectations(t)
+	analysisMock.AssertExpectations(t)
+	ddbMock.AssertExpectations(t)
+}
+
+func TestCheckerRunDisabled(t *testing.T) {
+	// Without a maximum error rate nothing is checked
+	checker := &Checker{CloudWatchClient: &testutils.CloudWatchMock{}}
+	stats, err := checker.Run(time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, &Stats{}, stats)
+}
+
+func TestFormatWindow(t *testing.T) {
+	assert.Equal(t, "1 hour", formatWindow(time.Hour))
+	assert.Equal(t, "6 hours", formatWindow(6*time.Hour))
+	assert.Equal(t, "2 days", formatWindow(48*time.Hour))
+	assert.Equal(t, "90 minutes", formatWindow(90*time.Minute))
+	assert.Equal(t, "30s", formatWindow(30*time.Second))
+}
@@ -0,0 +1,62 @@
+package main
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/kelseyhightower/envconfig"
+
+	"github.com/panther-labs/panther/internal/log_analysis/rulehealth"
+	"github.com/panther-labs/panther/internal/log_analysis/rulespec/engine"
+	"github.com/panther-labs/panther/pkg/gatewayapi"
+)
+
+var (
+	env     envConfig
+	checker *rulehealth.Checker
+)
+
+type envConfig struct {
+	AlertsDedupTable string  `required:"true" split_words:"true"`
+	MaxErrorPercent  float64 `required:"true" split_words:"true"`
+	MinEvaluations   int     `default:"100" split_words:"true"`
+}
+
+// Setup parses the environment and builds the AWS clients.
+func Setup() {
+	envconfig.MustProcess("", &env)
+
+	awsSession := session.Must(session.NewSession())
+	checker = &rulehealth.Checker{
+		CloudWatchClient: cloudwatch.New(awsSession),
+		AnalysisClient:   gatewayapi.NewClient(lambda.New(awsSession), "panther-analysis-api"),
+		Merger: &engine.AlertMerger{
+			DynamoDBClient: dynamodb.New(awsSession),
+			TableName:      env.AlertsDedupTable,
+		},
+		MaxErrorPercent: env.MaxErrorPercent,
+		MinEvaluations:  env.MinEvaluations,
+		Window:          time.Hour,
+	}
+}
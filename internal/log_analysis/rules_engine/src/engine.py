@@ -16,9 +16,9 @@
 
 import collections
 from collections.abc import Mapping
-from datetime import datetime, timedelta
+from datetime import datetime, timedelta, timezone
 from timeit import default_timer
-from typing import Any, Dict, List
+from typing import Any, Dict, List, Optional, Tuple
 from unittest.mock import patch, MagicMock
 
 from . import EngineResult
@@ -27,6 +27,8 @@ from .data_model import DataModel
 from .enriched_event import PantherEvent
 from .logging import get_logger
 from .rule import Rule
+from .rule_exception import RuleException
+from .rule_metrics import RuleMetrics
 
 _RULES_CACHE_DURATION = timedelta(minutes=5)
 
@@ -42,6 +44,9 @@ class Engine:
         self.log_type_to_data_models: Dict[str, DataModel] = collections.defaultdict()
         self.log_type_to_rules: Dict[str, List[Rule]] = collections.defaultdict(list)
         self._analysis_client = analysis_api
+        # (rule id, exception id) -> (number of suppressed events, time of the last one)
+        self.exception_counts: Dict[Tuple[str, str], Tuple[int, datetime]] = {}
+        self.rule_metrics = RuleMetrics()
         self._populate_rules()
         self._populate_data_models()
 
@@ -120,7 +125,10 @@ class Engine:
 
         for rule in self.log_type_to_rules[log_type]:
             self.logger.debug("running rule [%s]", rule.rule_id)
+            start = default_timer()
             result = rule.run(panther_event, batch_mode=True)
+            latency_ms = (default_timer() - start) * 1000
+            self.rule_metrics.record(rule.rule_id, latency_ms, matched=bool(result.matched) and not result.errored, errored=result.errored)
             if result.errored:
                 rule_error = EngineResult(
                     rule_id=rule.rule_id,
@@ -135,7 +143,7 @@ class Engine:
                     error_message=result.error_message
                 )
                 engine_results.append(rule_error)
-            elif result.matched:
+            elif result.matched and not self._suppressed(rule, event):
                 match = EngineResult(
                     rule_id=rule.rule_id,
                     rule_version=rule.rule_version,
@@ -157,6 +165,19 @@ class Engine:
 
         return engine_results
 
+    def _suppressed(self, rule: Rule, event: Mapping) -> bool:
+        """Checks if an exception of the rule applies to a matched event, counting the suppressed events."""
+        if not rule.rule_exceptions:
+            return False
+        now = datetime.now(timezone.utc)
+        exception: Optional[RuleException] = next((e for e in rule.rule_exceptions if e.matches(event, now)), None)
+        if exception is None:
+            return False
+        key = (rule.rule_id, exception.exception_id)
+        count, _ = self.exception_counts.get(key, (0, now))
+        self.exception_counts[key] = (count + 1, now)
+        return True
+
     def _populate_rules(self) -> None:
         """Import all rules."""
         import_count = 0
@@ -170,6 +191,9 @@ class Engine:
         self.log_type_to_rules.clear()
 
         for raw_rule in rules:
+            if raw_rule.get('spec'):
+                # Declarative rules are evaluated by the Go rules engine
+                continue
             try:
                 rule = Rule(raw_rule)
             except Exception as err:  # pylint: disable=broad-except
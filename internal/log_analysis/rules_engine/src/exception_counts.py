@@ -0,0 +1,54 @@
+# Panther is a Cloud-Native SIEM for the Modern Security Team.
+# Copyright (C) 2020 Panther Labs Inc
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as
+# published by the Free Software Foundation, either version 3 of the
+# License, or (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <https://www.gnu.org/licenses/>.
+import os
+from datetime import datetime
+from typing import Dict, Tuple
+
+from .aws_clients import DDB_CLIENT
+
+_DDB_TABLE_NAME = os.environ['EXCEPTION_COUNTS_TABLE']
+
+# (rule id, exception id) -> (number of suppressed events, time of the last one)
+ExceptionCounts = Dict[Tuple[str, str], Tuple[int, datetime]]
+
+
+def update_exception_counts(counts: ExceptionCounts) -> None:
+    """Adds the number of events suppressed by rule exceptions to the counts table shared with the Go rules engine."""
+    for (rule_id, exception_id), (count, last_suppressed) in counts.items():
+        DDB_CLIENT.update_item(
+            TableName=_DDB_TABLE_NAME,
+            Key={
+                'ruleId': {
+                    'S': rule_id
+                },
+                'exceptionId': {
+                    'S': exception_id
+                }
+            },
+            UpdateExpression='ADD #1 :1 SET #2 = :2',
+            ExpressionAttributeNames={
+                '#1': 'suppressedCount',
+                '#2': 'lastSuppressedAt'
+            },
+            ExpressionAttributeValues={
+                ':1': {
+                    'N': str(count)
+                },
+                ':2': {
+                    'S': last_suppressed.strftime('%Y-%m-%dT%H:%M:%SZ')
+                }
+            }
+        )
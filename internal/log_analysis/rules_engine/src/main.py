@@ -24,6 +24,7 @@ from typing import Any, Dict, List, Optional, Tuple
 from .analysis_api import AnalysisAPIClient
 from .aws_clients import S3_CLIENT
 from .engine import Engine
+from .exception_counts import update_exception_counts
 from .logging import get_logger
 from .output import MatchedEventsBuffer
 
@@ -82,10 +83,22 @@ def log_analysis(event: Dict[str, Any]) -> None:
                         matches += 1
                     output_buffer.add_event(analysis_result)
     output_buffer.flush()
+    _flush_exception_counts()
+    _RULES_ENGINE.rule_metrics.flush()
     end = default_timer()
     _LOGGER.info("Matched %d events in %s seconds", matches, end - start)
 
 
+def _flush_exception_counts() -> None:
+    counts = _RULES_ENGINE.exception_counts
+    _RULES_ENGINE.exception_counts = {}
+    try:
+        update_exception_counts(counts)
+    except Exception as err:  # pylint: disable=broad-except
+        # The alerts are out, failing the batch for the counts would duplicate them
+        _LOGGER.warning("failed to store exception counts %s", err)
+
+
 # Reads lambda events wrapping s3 notifications, returns dictionary containing mapping from log type to list of TextIOWrapper's
 def _load_event(event: Dict[str, Any]) -> Dict[str, List[TextIOWrapper]]:
     log_type_to_data: Dict[str, List[TextIOWrapper]] = collections.defaultdict(list)
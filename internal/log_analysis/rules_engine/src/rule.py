@@ -26,6 +26,7 @@ from typing import Any, Optional, Callable, List
 from .logging import get_logger
 from .util import id_to_path, import_file_as_module, store_modules
 from .enriched_event import PantherEvent
+from .rule_exception import RuleException
 
 _RULE_FOLDER = os.path.join(tempfile.gettempdir(), 'rules')
 
@@ -181,6 +182,14 @@ class Rule:
                 values.sort()
             self.rule_reports = config['reports']
 
+        self.rule_exceptions: List[RuleException] = []
+        for raw_exception in config.get('exceptions') or []:
+            # The analysis API validates exceptions, skip those that are not valid anyway
+            try:
+                self.rule_exceptions.append(RuleException(raw_exception))
+            except Exception as err:  # pylint: disable=broad-except
+                self.logger.error('Failed to import exception %s of rule %s. Error: [%s]', raw_exception.get('id'), self.rule_id, err)
+
         self._store_rule()
 
         self._setup_exception = None
@@ -0,0 +1,121 @@
+# Panther is a Cloud-Native SIEM for the Modern Security Team.
+# Copyright (C) 2020 Panther Labs Inc
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as
+# published by the Free Software Foundation, either version 3 of the
+# License, or (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <https://www.gnu.org/licenses/>.
+import ipaddress
+import re
+from datetime import datetime, timezone
+from typing import Any, Callable, List, Mapping, Optional
+
+_OPERATORS = ('equals', 'contains', 'regex', 'cidr')
+
+# Go serializes times as RFC3339 with up to nanosecond precision
+_TIME_PATTERN = re.compile(r'^(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2})(\.\d+)?(Z|[+-]\d{2}:\d{2})$')
+
+
+class RuleException:
+    """An exception of a rule, suppressing its matches on events with a known-benign field value.
+
+    The semantics are the same as the exceptions of the Go rules engine (see rulespec/exception.go).
+    """
+
+    def __init__(self, config: Mapping):
+        self.exception_id: str = config['id']
+        self._path: List[str] = config['field'].split('.')
+        if not all(self._path):
+            raise ValueError('invalid field path "%s"' % config['field'])
+
+        operator = config['operator']
+        values: List[str] = config['values']
+        if not values:
+            raise ValueError('exception "%s" has no values' % self.exception_id)
+        if operator not in _OPERATORS:
+            raise ValueError('exception "%s" has unknown operator "%s"' % (self.exception_id, operator))
+
+        self._test: Callable[[Any], bool]
+        if operator == 'equals':
+            self._test = lambda value: _format_scalar(value) in values
+        elif operator == 'contains':
+            self._test = lambda value: isinstance(value, str) and any(v in value for v in values)
+        elif operator == 'regex':
+            patterns = [re.compile(v) for v in values]
+            self._test = lambda value: isinstance(value, str) and any(p.search(value) for p in patterns)
+        else:
+            networks = [ipaddress.ip_network(v, strict=False) for v in values]
+            self._test = lambda value: _in_networks(value, networks)
+
+        expires_at = config.get('expiresAt')
+        self.expires_at: Optional[datetime] = parse_time(expires_at) if expires_at else None
+
+    def matches(self, event: Mapping, now: datetime) -> bool:
+        """Checks if the exception suppresses an event. Expired exceptions match nothing."""
+        if self.expires_at and now >= self.expires_at:
+            return False
+        found, value = _lookup(event, self._path)
+        if not found:
+            return False
+        if isinstance(value, list):
+            return any(self._test(v) for v in value)
+        return self._test(value)
+
+
+def parse_time(value: str) -> datetime:
+    """Parses an RFC3339 time to a timezone aware datetime."""
+    match = _TIME_PATTERN.match(value)
+    if not match:
+        raise ValueError('invalid time "%s"' % value)
+    seconds, fraction, zone = match.groups()
+    micros = (fraction or '.0')[1:7].ljust(6, '0')
+    if zone == 'Z':
+        zone = '+00:00'
+    return datetime.fromisoformat(seconds + '.' + micros + zone).astimezone(timezone.utc)
+
+
+def _lookup(event: Any, path: List[str]) -> Any:
+    value = event
+    for segment in path:
+        if isinstance(value, Mapping):
+            if segment not in value:
+                return False, None
+            value = value[segment]
+        elif isinstance(value, list):
+            if not segment.isdigit() or int(segment) >= len(value):
+                return False, None
+            value = value[int(segment)]
+        else:
+            return False, None
+    return True, value
+
+
+def _format_scalar(value: Any) -> Optional[str]:
+    # Nested objects and nulls are never equal
+    if isinstance(value, str):
+        return value
+    if isinstance(value, bool):
+        return 'true' if value else 'false'
+    if isinstance(value, int):
+        return str(value)
+    if isinstance(value, float):
+        return str(int(value)) if value.is_integer() else repr(value)
+    return None
+
+
+def _in_networks(value: Any, networks: List[Any]) -> bool:
+    if not isinstance(value, str):
+        return False
+    try:
+        address = ipaddress.ip_address(value)
+    except ValueError:
+        return False
+    return any(address.version == network.version and address in network for network in networks)
@@ -0,0 +1,104 @@
+# Panther is a Cloud-Native SIEM for the Modern Security Team.
+# Copyright (C) 2020 Panther Labs Inc
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as
+# published by the Free Software Foundation, either version 3 of the
+# License, or (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+import json
+import random
+import sys
+import time
+from typing import Any, Dict, List, TextIO
+
+# Same metrics as the Go rules engine writes, see rulespec/engine/metrics.go
+_NAMESPACE = 'Panther'
+_DIMENSION = 'AnalysisID'
+# Per the AWS specification, a metric can have at most 100 values in a single log event
+_MAX_LATENCY_SAMPLES = 100
+
+
+class _RuleStats:
+    """The telemetry of a single rule since the last flush."""
+
+    def __init__(self) -> None:
+        self.evaluations = 0
+        self.errors = 0
+        self.matches = 0
+        self.latency_samples: List[float] = []
+
+    def add_latency(self, latency_ms: float) -> None:
+        """Keeps a uniform sample of the latencies, with reservoir sampling."""
+        if len(self.latency_samples) < _MAX_LATENCY_SAMPLES:
+            self.latency_samples.append(latency_ms)
+            return
+        i = random.randrange(self.evaluations)  # nosec
+        if i < _MAX_LATENCY_SAMPLES:
+            self.latency_samples[i] = latency_ms
+
+
+class RuleMetrics:
+    """Records per-rule evaluation telemetry and writes it in the CloudWatch embedded metric format."""
+
+    def __init__(self, output: TextIO = sys.stdout) -> None:
+        self._output = output
+        self._stats: Dict[str, _RuleStats] = {}
+
+    def record(self, rule_id: str, latency_ms: float, matched: bool, errored: bool) -> None:
+        """Adds a single evaluation of a rule."""
+        stats = self._stats.setdefault(rule_id, _RuleStats())
+        stats.evaluations += 1
+        stats.add_latency(latency_ms)
+        if matched:
+            stats.matches += 1
+        if errored:
+            stats.errors += 1
+
+    def flush(self) -> None:
+        """Writes one log event per rule with the telemetry recorded since the last flush."""
+        timestamp = int(time.time() * 1000)
+        for rule_id, stats in self._stats.items():
+            metrics = [
+                {
+                    'Name': 'RuleEvaluations',
+                    'Unit': 'Count'
+                },
+                {
+                    'Name': 'RuleMatches',
+                    'Unit': 'Count'
+                },
+                {
+                    'Name': 'RuleLatency',
+                    'Unit': 'Milliseconds'
+                },
+            ]
+            event: Dict[str, Any] = {
+                _DIMENSION: rule_id,
+                'RuleEvaluations': stats.evaluations,
+                'RuleMatches': stats.matches,
+                'RuleLatency': stats.latency_samples,
+            }
+            # Only rules that errored have the error metric, so that the rule health checks can find them
+            if stats.errors > 0:
+                metrics.append({'Name': 'RuleErrors', 'Unit': 'Count'})
+                event['RuleErrors'] = stats.errors
+            event['_aws'] = {
+                'Timestamp': timestamp,
+                'CloudWatchMetrics': [{
+                    'Namespace': _NAMESPACE,
+                    'Dimensions': [[_DIMENSION]],
+                    'Metrics': metrics
+                }],
+            }
+            self._output.write(json.dumps(event) + '\n')
+        self._output.flush()
+        self._stats = {}
@@ -59,6 +59,26 @@ class TestEngine(TestCase):
         self.assertEqual(len(engine.log_type_to_rules['log']), 1)
         self.assertEqual(engine.log_type_to_rules['log'][0].rule_id, 'rule_id')
 
+    def test_loading_rules_skips_specs(self) -> None:
+        analysis_api = mock.MagicMock()
+        analysis_api.get_enabled_rules.return_value = [
+            {
+                'id': 'rule_id',
+                'logTypes': ['log'],
+                'body': 'def rule(event):\n\treturn True',
+                'versionId': 'version'
+            }, {
+                'id': 'spec_rule_id',
+                'logTypes': ['log'],
+                'body': '',
+                'spec': 'match: {field: a, exists: true}',
+                'versionId': 'version'
+            }
+        ]
+        engine = Engine(analysis_api)
+        self.assertEqual(len(engine.log_type_to_rules['log']), 1)
+        self.assertEqual(engine.log_type_to_rules['log'][0].rule_id, 'rule_id')
+
     def test_analyze_single_rule_with_udm(self) -> None:
         analysis_api = mock.MagicMock()
         analysis_api.get_enabled_data_models.return_value = [
@@ -364,3 +384,41 @@ class TestEngine(TestCase):
         engine = Engine(analysis_api)
         result = engine.analyze_single_rule(rule, event)
         self.assertEqual(expected_result, result)
+
+    def test_analyze_rule_with_exceptions(self) -> None:
+        analysis_api = mock.MagicMock()
+        analysis_api.get_enabled_rules.return_value = [
+            {
+                'id': 'rule_id',
+                'logTypes': ['log'],
+                'body': 'def rule(event):\n\treturn True',
+                'versionId': 'version',
+                'exceptions': [
+                    {
+                        'id': 'scanners',
+                        'field': 'user',
+                        'operator': 'regex',
+                        'values': ['^scanner-']
+                    }, {
+                        'id': 'expired',
+                        'field': 'user',
+                        'operator': 'equals',
+                        'values': ['alice'],
+                        'expiresAt': '2020-01-01T00:00:00Z'
+                    }, {
+                        'id': 'invalid',
+                        'field': 'user',
+                        'operator': 'regex',
+                        'values': ['(']
+                    }
+                ]
+            }
+        ]
+        engine = Engine(analysis_api)
+        self.assertEqual(len(engine.log_type_to_rules['log'][0].rule_exceptions), 2)
+
+        self.assertEqual(engine.analyze('log', {'user': 'scanner-1'}), [])
+        self.assertEqual(engine.analyze('log', {'user': 'scanner-2'}), [])
+        self.assertEqual(len(engine.analyze('log', {'user': 'alice'})), 1)
+        self.assertEqual(engine.exception_counts[('rule_id', 'scanners')][0], 2)
+        self.assertNotIn(('rule_id', 'expired'), engine.exception_counts)
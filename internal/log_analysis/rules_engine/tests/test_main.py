@@ -56,6 +56,7 @@ _ENV_VARIABLES_MOCK = {
     'ALERTS_DEDUP_TABLE': 'table_name',
     'S3_BUCKET': 's3_bucket',
     'NOTIFICATIONS_TOPIC': 'sns_topic',
+    'EXCEPTION_COUNTS_TABLE': 'exception_counts',
 }
 with mock.patch.dict(os.environ, _ENV_VARIABLES_MOCK), \
      mock.patch.object(boto3, 'client', side_effect=mock_to_return):
@@ -0,0 +1,73 @@
+# Panther is a Cloud-Native SIEM for the Modern Security Team.
+# Copyright (C) 2020 Panther Labs Inc
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as
+# published by the Free Software Foundation, either version 3 of the
+# License, or (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+from datetime import datetime, timezone
+from unittest import TestCase
+
+from ..src.rule_exception import RuleException, parse_time
+
+_NOW = datetime(2021, 1, 1, tzinfo=timezone.utc)
+_EVENT = {
+    'userIdentity': {
+        'arn': 'arn:aws:iam::123456789012:user/scanner'
+    },
+    'p_any_ip_addresses': ['1.1.1.1', '10.1.2.3'],
+    'readOnly': True,
+    'count': 3,
+}
+
+
+def _exception(field: str, operator: str, *values: str) -> RuleException:
+    return RuleException({'id': 'id', 'field': field, 'operator': operator, 'values': list(values)})
+
+
+class TestRuleException(TestCase):
+
+    def test_operators(self) -> None:
+        self.assertTrue(_exception('userIdentity.arn', 'equals', 'other', 'arn:aws:iam::123456789012:user/scanner').matches(_EVENT, _NOW))
+        self.assertTrue(_exception('readOnly', 'equals', 'true').matches(_EVENT, _NOW))
+        self.assertTrue(_exception('count', 'equals', '3').matches(_EVENT, _NOW))
+        self.assertFalse(_exception('userIdentity', 'equals', '').matches(_EVENT, _NOW))
+        self.assertTrue(_exception('userIdentity.arn', 'contains', ':user/scan').matches(_EVENT, _NOW))
+        self.assertFalse(_exception('count', 'contains', '3').matches(_EVENT, _NOW))
+        self.assertTrue(_exception('userIdentity.arn', 'regex', 'user/scan.*$').matches(_EVENT, _NOW))
+        self.assertTrue(_exception('p_any_ip_addresses', 'cidr', '10.0.0.0/8').matches(_EVENT, _NOW))
+        self.assertTrue(_exception('p_any_ip_addresses.1', 'cidr', '10.0.0.0/8').matches(_EVENT, _NOW))
+        self.assertFalse(_exception('p_any_ip_addresses', 'cidr', '192.168.0.0/16').matches(_EVENT, _NOW))
+        self.assertFalse(_exception('missing', 'equals', '').matches(_EVENT, _NOW))
+
+    def test_expiry(self) -> None:
+        exception = RuleException(
+            {
+                'id': 'id',
+                'field': 'readOnly',
+                'operator': 'equals',
+                'values': ['true'],
+                'expiresAt': '2021-01-01T01:00:00.123456789Z'
+            }
+        )
+        self.assertTrue(exception.matches(_EVENT, _NOW))
+        self.assertFalse(exception.matches(_EVENT, parse_time('2021-01-01T03:00:01+02:00')))
+
+    def test_invalid(self) -> None:
+        with self.assertRaises(ValueError):
+            _exception('a..b', 'equals', 'x')
+        with self.assertRaises(ValueError):
+            _exception('a', 'startswith', 'x')
+        with self.assertRaises(ValueError):
+            _exception('a', 'equals')
+        with self.assertRaises(ValueError):
+            _exception('a', 'cidr', 'not an address')
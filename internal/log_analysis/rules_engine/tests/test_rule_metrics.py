@@ -0,0 +1,67 @@
+# Panther is a Cloud-Native SIEM for the Modern Security Team.
+# Copyright (C) 2020 Panther Labs Inc
+#
+# This program is free software: you can redistribute it and/or modify
+# it under the terms of the GNU Affero General Public License as
+# published by the Free Software Foundation, either version 3 of the
+# License, or (at your option) any later version.
+#
+# This program is distributed in the hope that it will be useful,
+# but WITHOUT ANY WARRANTY; without even the implied warranty of
+# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+# GNU Affero General Public License for more details.
+#
+# You should have received a copy of the GNU Affero General Public License
+# along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+
+import io
+import json
+from unittest import TestCase
+
+from ..src.rule_metrics import RuleMetrics
+
+
+class TestRuleMetrics(TestCase):
+
+    def test_flush(self) -> None:
+        output = io.StringIO()
+        metrics = RuleMetrics(output)
+        metrics.record('rule.one', 1.5, matched=True, errored=False)
+        metrics.record('rule.one', 2.5, matched=False, errored=False)
+        metrics.record('rule.two', 3, matched=False, errored=True)
+        metrics.flush()
+
+        events = {event['AnalysisID']: event for event in map(json.loads, output.getvalue().splitlines())}
+        self.assertEqual(events.keys(), {'rule.one', 'rule.two'})
+
+        one = events['rule.one']
+        self.assertEqual(one['RuleEvaluations'], 2)
+        self.assertEqual(one['RuleMatches'], 1)
+        self.assertEqual(one['RuleLatency'], [1.5, 2.5])
+        self.assertNotIn('RuleErrors', one)
+        directive = one['_aws']['CloudWatchMetrics'][0]
+        self.assertEqual(directive['Namespace'], 'Panther')
+        self.assertEqual(directive['Dimensions'], [['AnalysisID']])
+        self.assertEqual({m['Name'] for m in directive['Metrics']}, {'RuleEvaluations', 'RuleMatches', 'RuleLatency'})
+
+        two = events['rule.two']
+        self.assertEqual(two['RuleErrors'], 1)
+        self.assertEqual(two['RuleMatches'], 0)
+        self.assertIn({'Name': 'RuleErrors', 'Unit': 'Count'}, two['_aws']['CloudWatchMetrics'][0]['Metrics'])
+
+        # The telemetry is reset after a flush
+        output.truncate(0)
+        metrics.flush()
+        self.assertEqual(output.getvalue(), '')
+
+    def test_latency_sample_is_bounded(self) -> None:
+        output = io.StringIO()
+        metrics = RuleMetrics(output)
+        for i in range(1000):
+            metrics.record('rule', float(i), matched=False, errored=False)
+        metrics.flush()
+
+        event = json.loads(output.getvalue())
+        self.assertEqual(event['RuleEvaluations'], 1000)
+        self.assertEqual(len(event['RuleLatency']), 100)
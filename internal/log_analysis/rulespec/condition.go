@@ -0,0 +1,265 @@
+package rulespec
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// matcher checks a decoded JSON event
+type matcher interface {
+	Match(event map[string]interface{}) bool
+}
+
+type allMatcher []matcher
+
+func (m allMatcher) Match(event map[string]interface{}) bool {
+	for _, sub := range m {
+		if !sub.Match(event) {
+			return false
+		}
+	}
+	return true
+}
+
+type anyMatcher []matcher
+
+func (m anyMatcher) Match(event map[string]interface{}) bool {
+	for _, sub := range m {
+		if sub.Match(event) {
+			return true
+		}
+	}
+	return false
+}
+
+type notMatcher struct {
+	matcher matcher
+}
+
+func (m *notMatcher) Match(event map[string]interface{}) bool {
+	return !m.matcher.Match(event)
+}
+
+type existsMatcher struct {
+	path   fieldPath
+	exists bool
+}
+
+func (m *existsMatcher) Match(event map[string]interface{}) bool {
+	value, ok := m.path.Lookup(event)
+	return (ok && value != nil) == m.exists
+}
+
+// fieldMatcher checks the value of a field.
+// If the field holds a list, it matches if any of the list values match.
+type fieldMatcher struct {
+	path fieldPath
+	test func(value interface{}) bool
+}
+
+func (m *fieldMatcher) Match(event map[string]interface{}) bool {
+	value, ok := m.path.Lookup(event)
+	if !ok {
+		return false
+	}
+	if values, isList := value.([]interface{}); isList {
+		for _, v := range values {
+			if m.test(v) {
+				return true
+			}
+		}
+		return false
+	}
+	return m.test(value)
+}
+
+func compileCondition(c *Condition) (matcher, error) {
+	combinators := 0
+	if c.All != nil {
+		combinators++
+	}
+	if c.Any != nil {
+		combinators++
+	}
+	if c.Not != nil {
+		combinators++
+	}
+	if c.Field != "" {
+		combinators++
+	}
+	if combinators != 1 {
+		return nil, errors.New("condition must have exactly one of 'all', 'any', 'not' or 'field'")
+	}
+
+	switch {
+	case c.All != nil:
+		matchers, err := compileConditions(c.All)
+		if err != nil {
+			return nil, err
+		}
+		return allMatcher(matchers), nil
+	case c.Any != nil:
+		matchers, err := compileConditions(c.Any)
+		if err != nil {
+			return nil, err
+		}
+		return anyMatcher(matchers), nil
+	case c.Not != nil:
+		m, err := compileCondition(c.Not)
+		if err != nil {
+			return nil, err
+		}
+		return &notMatcher{matcher: m}, nil
+	default:
+		return compileFieldCondition(c)
+	}
+}
+
+func compileConditions(conditions []Condition) ([]matcher, error) {
+	if len(conditions) == 0 {
+		return nil, errors.New("empty list of conditions")
+	}
+	matchers := make([]matcher, len(conditions))
+	for i := range conditions {
+		m, err := compileCondition(&conditions[i])
+		if err != nil {
+			return nil, err
+		}
+		matchers[i] = m
+	}
+	return matchers, nil
+}
+
+func compileFieldCondition(c *Condition) (matcher, error) {
+	path, err := parseFieldPath(c.Field)
+	if err != nil {
+		return nil, err
+	}
+	operators := 0
+	for _, isSet := range []bool{c.Equals != nil, c.In != nil, c.Contains != "", c.Regex != "", c.CIDR != nil, c.Exists != nil} {
+		if isSet {
+			operators++
+		}
+	}
+	if operators != 1 {
+		return nil, errors.Errorf("field %q must have exactly one of 'equals', 'in', 'contains', 'regex', 'cidr' or 'exists'", c.Field)
+	}
+
+	var test func(value interface{}) bool
+	switch {
+	case c.Exists != nil:
+		return &existsMatcher{path: path, exists: *c.Exists}, nil
+	case c.Equals != nil:
+		want, err := scalarValue(c.Equals)
+		if err != nil {
+			return nil, errors.Wrapf(err, "field %q", c.Field)
+		}
+		test = func(value interface{}) bool {
+			return normalizeValue(value) == want
+		}
+	case c.In != nil:
+		set := make([]interface{}, len(c.In))
+		for i, v := range c.In {
+			if set[i], err = scalarValue(v); err != nil {
+				return nil, errors.Wrapf(err, "field %q", c.Field)
+			}
+		}
+		test = func(value interface{}) bool {
+			value = normalizeValue(value)
+			for _, v := range set {
+				if v == value {
+					return true
+				}
+			}
+			return false
+		}
+	case c.Contains != "":
+		substr := c.Contains
+		test = func(value interface{}) bool {
+			s, ok := value.(string)
+			return ok && strings.Contains(s, substr)
+		}
+	case c.Regex != "":
+		re, err := regexp.Compile(c.Regex)
+		if err != nil {
+			return nil, errors.Wrapf(err, "field %q invalid regex", c.Field)
+		}
+		test = func(value interface{}) bool {
+			s, ok := value.(string)
+			return ok && re.MatchString(s)
+		}
+	case c.CIDR != nil:
+		networks := make([]*net.IPNet, len(c.CIDR))
+		for i, cidr := range c.CIDR {
+			_, network, err := net.ParseCIDR(cidr)
+			if err != nil {
+				return nil, errors.Wrapf(err, "field %q", c.Field)
+			}
+			networks[i] = network
+		}
+		test = func(value interface{}) bool {
+			s, ok := value.(string)
+			if !ok {
+				return false
+			}
+			ip := net.ParseIP(s)
+			if ip == nil {
+				return false
+			}
+			for _, network := range networks {
+				if network.Contains(ip) {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	return &fieldMatcher{path: path, test: test}, nil
+}
+
+// scalarValue checks that a value from the spec can be compared to a JSON scalar
+func scalarValue(v interface{}) (interface{}, error) {
+	switch v := normalizeValue(v).(type) {
+	case string, bool, float64:
+		return v, nil
+	default:
+		return nil, errors.Errorf("unsupported value %v, expected a string, number or boolean", v)
+	}
+}
+
+// normalizeValue converts numbers to float64 so that YAML integers compare equal to JSON numbers
+func normalizeValue(v interface{}) interface{} {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case uint64:
+		return float64(n)
+	case float32:
+		return float64(n)
+	default:
+		return v
+	}
+}
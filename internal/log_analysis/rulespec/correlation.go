@@ -0,0 +1,231 @@
+package rulespec
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// MaxCorrelationSteps is the maximum number of steps of a correlation rule
+	MaxCorrelationSteps = 10
+	// MaxCorrelationWindowMinutes is the maximum window of a correlation rule
+	MaxCorrelationWindowMinutes = 24 * 60
+
+	// CorrelationKeyField holds the correlation key in the event passed to the templates of a correlation rule
+	CorrelationKeyField = "key"
+)
+
+// CorrelationSpec makes a rule fire on a group of events instead of a single event.
+// The rule fires when every step has matched an event with the same key within the window:
+//
+//	correlation:
+//	  windowMinutes: 15
+//	  sequence: true
+//	  steps:
+//	    - name: mfa_reset
+//	      logType: Okta.SystemLog
+//	      match:
+//	        field: eventType
+//	        equals: user.mfa.factor.reset_all
+//	      key: target.0.alternateId
+//	    - name: console_login
+//	      logType: AWS.CloudTrail
+//	      match:
+//	        field: eventName
+//	        equals: ConsoleLogin
+//	      key: userIdentity.userName
+//	title: "MFA reset followed by console login for {key} from {console_login.sourceIPAddress}"
+//
+// The templates of a correlation rule see the key and the latest contributing event of each step by name.
+type CorrelationSpec struct {
+	WindowMinutes int `yaml:"windowMinutes"`
+	// If true the steps must happen in the listed order, by event time
+	Sequence bool                  `yaml:"sequence,omitempty"`
+	Steps    []CorrelationStepSpec `yaml:"steps"`
+}
+
+// CorrelationStepSpec selects the events of one step and the field joining them with the other steps
+type CorrelationStepSpec struct {
+	Name    string    `yaml:"name"`
+	LogType string    `yaml:"logType"`
+	Match   Condition `yaml:"match"`
+	Key     string    `yaml:"key"`
+}
+
+// Correlation is a compiled correlation spec
+type Correlation struct {
+	Window   time.Duration
+	Sequence bool
+	Steps    []*CorrelationStep
+}
+
+// CorrelationStep is a compiled correlation step
+type CorrelationStep struct {
+	Name    string
+	LogType string
+	matcher matcher
+	key     fieldPath
+}
+
+func compileCorrelation(s *CorrelationSpec) (*Correlation, error) {
+	if s.WindowMinutes <= 0 || s.WindowMinutes > MaxCorrelationWindowMinutes {
+		return nil, errors.Errorf("correlation window must be between 1 and %d minutes", MaxCorrelationWindowMinutes)
+	}
+	if len(s.Steps) < 2 || len(s.Steps) > MaxCorrelationSteps {
+		return nil, errors.Errorf("correlation must have between 2 and %d steps", MaxCorrelationSteps)
+	}
+	result := Correlation{
+		Window:   time.Duration(s.WindowMinutes) * time.Minute,
+		Sequence: s.Sequence,
+	}
+	names := make(map[string]bool, len(s.Steps))
+	for i := range s.Steps {
+		step := &s.Steps[i]
+		if step.Name == "" || strings.Contains(step.Name, ".") || step.Name == CorrelationKeyField {
+			return nil, errors.Errorf("invalid correlation step name %q", step.Name)
+		}
+		if names[step.Name] {
+			return nil, errors.Errorf("duplicate correlation step %q", step.Name)
+		}
+		names[step.Name] = true
+		if step.LogType == "" {
+			return nil, errors.Errorf("correlation step %q has no log type", step.Name)
+		}
+		m, err := compileCondition(&step.Match)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid correlation step %q match", step.Name)
+		}
+		key, err := parseFieldPath(step.Key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid correlation step %q key", step.Name)
+		}
+		result.Steps = append(result.Steps, &CorrelationStep{
+			Name:    step.Name,
+			LogType: step.LogType,
+			matcher: m,
+			key:     key,
+		})
+	}
+	return &result, nil
+}
+
+// LogTypes returns the distinct log types of the steps
+func (c *Correlation) LogTypes() []string {
+	var logTypes []string
+	seen := make(map[string]bool)
+	for _, step := range c.Steps {
+		if !seen[step.LogType] {
+			seen[step.LogType] = true
+			logTypes = append(logTypes, step.LogType)
+		}
+	}
+	sort.Strings(logTypes)
+	return logTypes
+}
+
+// Match returns the correlation key of an event of the step's log type.
+// Keys are compared case-insensitively since different sources rarely agree on the case of emails or names.
+func (s *CorrelationStep) Match(event map[string]interface{}) (string, bool) {
+	if !s.matcher.Match(event) {
+		return "", false
+	}
+	value, ok := s.key.Lookup(event)
+	if !ok {
+		return "", false
+	}
+	key := strings.ToLower(formatValue(value))
+	return key, key != ""
+}
+
+// CorrelatedEvent is an event that contributed to a correlation
+type CorrelatedEvent struct {
+	Step  string
+	Time  time.Time
+	Event map[string]interface{}
+}
+
+// Find returns the events completing the correlation, one per step in step order, or nil.
+// Events must be sorted by time.
+func (c *Correlation) Find(events []CorrelatedEvent) []CorrelatedEvent {
+	for start := range events {
+		if found := c.findFrom(events, start); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// findFrom looks for a completed correlation within the window starting at events[start].
+// The earliest event of each step is picked, so alerts reference the activity which first completed the correlation.
+func (c *Correlation) findFrom(events []CorrelatedEvent, start int) []CorrelatedEvent {
+	first := events[start]
+	end := first.Time.Add(c.Window)
+	found := make([]CorrelatedEvent, len(c.Steps))
+	if c.Sequence {
+		// Each step must follow the previous one
+		if first.Step != c.Steps[0].Name {
+			return nil
+		}
+		found[0] = first
+		next := 1
+		for _, e := range events[start+1:] {
+			if e.Time.After(end) || next == len(c.Steps) {
+				break
+			}
+			if e.Step == c.Steps[next].Name {
+				found[next] = e
+				next++
+			}
+		}
+		if next < len(c.Steps) {
+			return nil
+		}
+		return found
+	}
+	complete := 0
+	for _, e := range events[start:] {
+		if e.Time.After(end) {
+			break
+		}
+		for i, step := range c.Steps {
+			if e.Step == step.Name && found[i].Event == nil {
+				found[i] = e
+				complete++
+			}
+		}
+		if complete == len(c.Steps) {
+			return found
+		}
+	}
+	return nil
+}
+
+// Event builds the event passed to the templates when the rule fires
+func (c *Correlation) Event(key string, events []CorrelatedEvent) map[string]interface{} {
+	result := map[string]interface{}{CorrelationKeyField: key}
+	for _, e := range events {
+		result[e.Step] = e.Event
+	}
+	return result
+}
@@ -0,0 +1,250 @@
+package engine
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/internal/log_analysis/rulespec"
+)
+
+const (
+	// Only the latest events of each step are kept for a key
+	maxCorrelatedEventsPerStep = 10
+	// Larger events are kept as references only (log type, row id and time)
+	maxCorrelatedEventSize = 8 * 1024
+	// Concurrent updates of the same key are retried
+	maxCorrelationUpdateAttempts = 5
+)
+
+// CorrelationStore keeps the recent events of correlation rules, by rule and key.
+// Items expire through the DynamoDB TTL on expiresAt once the window of their latest event has passed.
+type CorrelationStore struct {
+	DynamoDBClient dynamodbiface.DynamoDBAPI
+	TableName      string
+}
+
+type correlationState struct {
+	PartitionKey string            `json:"partitionKey"`
+	RuleID       string            `json:"ruleId"`
+	Key          string            `json:"key"`
+	Events       []correlatedEvent `json:"events"`
+	Version      int               `json:"version"`
+	ExpiresAt    int64             `json:"expiresAt"`
+}
+
+// correlatedEvent is the stored form of a contributing event
+type correlatedEvent struct {
+	Step    string    `json:"step"`
+	LogType string    `json:"logType"`
+	RowID   string    `json:"rowId"`
+	Time    time.Time `json:"time"`
+	Event   string    `json:"event,omitempty"`
+}
+
+// Add records an event of a correlation step and returns the contributing events if the correlation completed.
+// A completed correlation clears the state of the key, so that the same events do not fire again.
+// Events that are already recorded (SQS retries of the same batch) are skipped.
+func (s *CorrelationStore) Add(rule *Rule, logType, key string, step *rulespec.CorrelationStep,
+	event map[string]interface{}, eventTime time.Time) ([]correlatedEvent, error) {
+
+	newEvent := correlatedEvent{
+		Step:    step.Name,
+		LogType: logType,
+		RowID:   stringField(event, "p_row_id"),
+		Time:    eventTime,
+	}
+	if data, err := jsoniter.MarshalToString(event); err == nil && len(data) <= maxCorrelatedEventSize {
+		newEvent.Event = data
+	}
+	correlation := rule.Correlation()
+	partitionKey := md5Hex(rule.ID + ":" + key)
+	for attempt := 1; ; attempt++ {
+		state, err := s.get(partitionKey)
+		if err != nil {
+			return nil, err
+		}
+		if state.contains(&newEvent) {
+			return nil, nil
+		}
+		version := state.Version
+		state.PartitionKey, state.RuleID, state.Key = partitionKey, rule.ID, key
+		state.Events = pruneEvents(append(state.Events, newEvent), correlation.Window)
+
+		found := correlation.Find(toCorrelatedEvents(state.Events))
+		var fired []correlatedEvent
+		if found != nil {
+			fired = fromCorrelatedEvents(found, state.Events)
+			state.Events = nil
+		}
+		latest := state.Events
+		if len(latest) == 0 {
+			latest = fired
+		}
+		state.ExpiresAt = latest[len(latest)-1].Time.Add(correlation.Window).Unix()
+		state.Version++
+
+		err = s.put(state, version)
+		if err == nil {
+			return fired, nil
+		}
+		if awsErr, ok := err.(awserr.Error); !ok || awsErr.Code() != dynamodb.ErrCodeConditionalCheckFailedException ||
+			attempt == maxCorrelationUpdateAttempts {
+
+			return nil, errors.Wrap(err, "failed to update correlation state")
+		}
+	}
+}
+
+// contains checks if an event was already recorded for the same step
+func (state *correlationState) contains(event *correlatedEvent) bool {
+	if event.RowID == "" {
+		return false
+	}
+	for i := range state.Events {
+		if e := &state.Events[i]; e.RowID == event.RowID && e.Step == event.Step {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *CorrelationStore) get(partitionKey string) (*correlationState, error) {
+	output, err := s.DynamoDBClient.GetItem(&dynamodb.GetItemInput{
+		TableName:      &s.TableName,
+		Key:            map[string]*dynamodb.AttributeValue{partitionKeyName: {S: &partitionKey}},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get correlation state")
+	}
+	var state correlationState
+	if err := dynamodbattribute.UnmarshalMap(output.Item, &state); err != nil {
+		return nil, errors.Wrap(err, "invalid correlation state")
+	}
+	return &state, nil
+}
+
+// put writes the state if nobody else updated it since it was read
+func (s *CorrelationStore) put(state *correlationState, version int) error {
+	item, err := dynamodbattribute.MarshalMap(state)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal correlation state")
+	}
+	_, err = s.DynamoDBClient.PutItem(&dynamodb.PutItemInput{
+		TableName:           &s.TableName,
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(#1) OR #2 = :2"),
+		ExpressionAttributeNames: map[string]*string{
+			"#1": aws.String(partitionKeyName),
+			"#2": aws.String("version"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":2": {N: aws.String(strconv.Itoa(version))},
+		},
+	})
+	return err
+}
+
+// pruneEvents sorts the events by time and drops those that can no longer complete a correlation
+func pruneEvents(events []correlatedEvent, window time.Duration) []correlatedEvent {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+	cutoff := events[len(events)-1].Time.Add(-window)
+	perStep := make(map[string]int)
+	var result []correlatedEvent
+	// Walk from the latest event to keep the latest events of each step
+	for i := len(events) - 1; i >= 0; i-- {
+		e := events[i]
+		if e.Time.Before(cutoff) || perStep[e.Step] == maxCorrelatedEventsPerStep {
+			continue
+		}
+		perStep[e.Step]++
+		result = append(result, e)
+	}
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result
+}
+
+func toCorrelatedEvents(events []correlatedEvent) []rulespec.CorrelatedEvent {
+	result := make([]rulespec.CorrelatedEvent, len(events))
+	for i, e := range events {
+		result[i] = rulespec.CorrelatedEvent{Step: e.Step, Time: e.Time, Event: e.event()}
+	}
+	return result
+}
+
+// fromCorrelatedEvents maps the events found by the correlation back to the stored events
+func fromCorrelatedEvents(found []rulespec.CorrelatedEvent, events []correlatedEvent) []correlatedEvent {
+	result := make([]correlatedEvent, 0, len(found))
+	for _, f := range found {
+		for _, e := range events {
+			if e.Step == f.Step && e.Time.Equal(f.Time) {
+				result = append(result, e)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// event returns the stored event, or its reference fields if it was too large to store
+func (e *correlatedEvent) event() map[string]interface{} {
+	var event map[string]interface{}
+	if e.Event != "" && jsoniter.UnmarshalFromString(e.Event, &event) == nil {
+		return event
+	}
+	return map[string]interface{}{
+		"p_log_type":   e.LogType,
+		"p_row_id":     e.RowID,
+		"p_event_time": e.Time.Format(time.RFC3339Nano),
+	}
+}
+
+// Event times are formatted by the log processor, but tolerate other layouts
+var eventTimeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999"}
+
+// eventTime returns the p_event_time of an event, or the fallback if missing
+func eventTime(event map[string]interface{}, fallback time.Time) time.Time {
+	value := stringField(event, "p_event_time")
+	for _, layout := range eventTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC()
+		}
+	}
+	return fallback
+}
+
+func stringField(event map[string]interface{}, name string) string {
+	value, _ := event[name].(string)
+	return value
+}
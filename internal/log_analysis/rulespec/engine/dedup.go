@@ -0,0 +1,250 @@
+package engine
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"crypto/md5" // nolint: gosec
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/pkg/errors"
+)
+
+// Attributes of the alert dedup table, shared with the Python rules engine and read by the alert forwarder
+const (
+	partitionKeyName          = "partitionKey"
+	ruleIDAttrName            = "ruleId"
+	ruleVersionAttrName       = "ruleVersion"
+	dedupStrAttrName          = "dedup"
+	alertCreationTimeAttrName = "alertCreationTime"
+	alertUpdateTimeAttrName   = "alertUpdateTime"
+	alertCountAttrName        = "alertCount"
+	alertEventCountAttrName   = "eventCount"
+	alertLogTypesAttrName     = "logTypes"
+	alertContextAttrName      = "context"
+	alertTitleAttrName        = "title"
+	alertDescriptionAttrName  = "description"
+	alertReferenceAttrName    = "reference"
+	alertSeverityAttrName     = "severity"
+	alertRunbookAttrName      = "runbook"
+	alertDestinationsAttrName = "destinations"
+	alertTypeAttrName         = "type"
+	alertNoStoredEventsName   = "noStoredEvents"
+
+	alertTypeRule      = "RULE"
+	alertTypeRuleError = "RULE_ERROR"
+)
+
+// MatchGroup is a batch of events that matched a rule with the same dedup string
+type MatchGroup struct {
+	RuleID             string
+	RuleVersion        string
+	LogTypes           []string
+	Dedup              string
+	DedupPeriodMinutes int
+	NumMatches         int
+	ProcessingTime     time.Time
+	AlertContext       string
+	Title              string
+	Description        string
+	Reference          string
+	Severity           string
+	Runbook            string
+	Destinations       []string
+	// Rule errors are deduplicated separately from the matches of the rule
+	RuleError bool
+	// The events are not written to S3, e.g. the results of a scheduled query or a notice about the rule itself.
+	// The alerts API doesn't search for them.
+	NoStoredEvents bool
+}
+
+// AlertInfo identifies the alert a group of events was merged into
+type AlertInfo struct {
+	AlertID      string
+	CreationTime time.Time
+	UpdateTime   time.Time
+}
+
+// AlertMerger updates the alert dedup table the same way the Python rules engine does,
+// so that dedup periods and thresholds apply identically to spec and Python rules.
+type AlertMerger struct {
+	DynamoDBClient dynamodbiface.DynamoDBAPI
+	TableName      string
+}
+
+// UpdateAlertInfo updates the creation time and event count of an alert.
+// If the dedup period has expired a new alert is created.
+func (m *AlertMerger) UpdateAlertInfo(group *MatchGroup) (*AlertInfo, error) {
+	info, err := m.updateConditional(group)
+	if err == nil {
+		return info, nil
+	}
+	if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		// The alert is still within its dedup period so the events need to be merged
+		return m.update(group)
+	}
+	return nil, errors.Wrap(err, "failed to update alert dedup table")
+}
+
+// The condition succeeds only if this is the first time the rule fires with this dedup string
+// or if the dedup period of the previous alert has expired.
+func (m *AlertMerger) updateConditional(group *MatchGroup) (*AlertInfo, error) {
+	epoch := strconv.FormatInt(group.ProcessingTime.Unix(), 10)
+	updateExpression := "ADD #3 :3\nSET #4=:4, #5=:5, #6=:6, #7=:7, #8=:8, #9=:9, #10=:10, #11=:11"
+	names := map[string]*string{
+		"#1":  aws.String(alertCreationTimeAttrName),
+		"#2":  aws.String(partitionKeyName),
+		"#3":  aws.String(alertCountAttrName),
+		"#4":  aws.String(ruleIDAttrName),
+		"#5":  aws.String(dedupStrAttrName),
+		"#6":  aws.String(alertCreationTimeAttrName),
+		"#7":  aws.String(alertUpdateTimeAttrName),
+		"#8":  aws.String(alertEventCountAttrName),
+		"#9":  aws.String(alertLogTypesAttrName),
+		"#10": aws.String(ruleVersionAttrName),
+		"#11": aws.String(alertTypeAttrName),
+	}
+	values := map[string]*dynamodb.AttributeValue{
+		":1":  {N: aws.String(strconv.FormatInt(group.ProcessingTime.Unix()-int64(group.DedupPeriodMinutes*60), 10))},
+		":3":  {N: aws.String("1")},
+		":4":  {S: aws.String(group.RuleID)},
+		":5":  {S: aws.String(group.Dedup)},
+		":6":  {N: aws.String(epoch)},
+		":7":  {N: aws.String(epoch)},
+		":8":  {N: aws.String(strconv.Itoa(group.NumMatches))},
+		":9":  {SS: aws.StringSlice(group.LogTypes)},
+		":10": {S: aws.String(group.RuleVersion)},
+		":11": {S: aws.String(alertTypeRule)},
+	}
+	if group.RuleError {
+		values[":11"] = &dynamodb.AttributeValue{S: aws.String(alertTypeRuleError)}
+	}
+
+	optional := []struct {
+		name  string
+		value string
+	}{
+		{alertContextAttrName, group.AlertContext},
+		{alertTitleAttrName, group.Title},
+		{alertDescriptionAttrName, group.Description},
+		{alertReferenceAttrName, group.Reference},
+		{alertSeverityAttrName, group.Severity},
+		{alertRunbookAttrName, group.Runbook},
+	}
+	for i, attr := range optional {
+		if attr.value == "" {
+			continue
+		}
+		key := strconv.Itoa(12 + i)
+		updateExpression += ", #" + key + "=:" + key
+		names["#"+key] = aws.String(attr.name)
+		values[":"+key] = &dynamodb.AttributeValue{S: aws.String(attr.value)}
+	}
+	if len(group.Destinations) > 0 {
+		updateExpression += ", #18=:18"
+		names["#18"] = aws.String(alertDestinationsAttrName)
+		values[":18"] = &dynamodb.AttributeValue{SS: aws.StringSlice(group.Destinations)}
+	}
+	if group.NoStoredEvents {
+		updateExpression += ", #19=:19"
+		names["#19"] = aws.String(alertNoStoredEventsName)
+		values[":19"] = &dynamodb.AttributeValue{BOOL: aws.Bool(true)}
+	}
+
+	output, err := m.DynamoDBClient.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:                 &m.TableName,
+		Key:                       dedupKey(group),
+		UpdateExpression:          &updateExpression,
+		ConditionExpression:       aws.String("(#1 < :1) OR (attribute_not_exists(#2))"),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+		ReturnValues:              aws.String(dynamodb.ReturnValueAllNew),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &AlertInfo{
+		AlertID:      alertID(group, numberAttribute(output.Attributes, alertCountAttrName)),
+		CreationTime: group.ProcessingTime,
+		UpdateTime:   group.ProcessingTime,
+	}, nil
+}
+
+// update adds the events to an existing alert
+func (m *AlertMerger) update(group *MatchGroup) (*AlertInfo, error) {
+	output, err := m.DynamoDBClient.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:        &m.TableName,
+		Key:              dedupKey(group),
+		UpdateExpression: aws.String("SET #1=:1\nADD #2 :2, #3 :3"),
+		ExpressionAttributeNames: map[string]*string{
+			"#1": aws.String(alertUpdateTimeAttrName),
+			"#2": aws.String(alertEventCountAttrName),
+			"#3": aws.String(alertLogTypesAttrName),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":1": {N: aws.String(strconv.FormatInt(group.ProcessingTime.Unix(), 10))},
+			":2": {N: aws.String(strconv.Itoa(group.NumMatches))},
+			":3": {SS: aws.StringSlice(group.LogTypes)},
+		},
+		ReturnValues: aws.String(dynamodb.ReturnValueAllNew),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to update alert dedup table")
+	}
+	creationTime, err := strconv.ParseInt(numberAttribute(output.Attributes, alertCreationTimeAttrName), 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid alert creation time")
+	}
+	return &AlertInfo{
+		AlertID:      alertID(group, numberAttribute(output.Attributes, alertCountAttrName)),
+		CreationTime: time.Unix(creationTime, 0).UTC(),
+		UpdateTime:   group.ProcessingTime,
+	}, nil
+}
+
+func dedupKey(group *MatchGroup) map[string]*dynamodb.AttributeValue {
+	key := group.RuleID + ":" + group.Dedup
+	if group.RuleError {
+		key += ":error"
+	}
+	return map[string]*dynamodb.AttributeValue{
+		partitionKeyName: {S: aws.String(md5Hex(key))},
+	}
+}
+
+func alertID(group *MatchGroup, count string) string {
+	return md5Hex(group.RuleID + ":" + count + ":" + group.Dedup)
+}
+
+func numberAttribute(attributes map[string]*dynamodb.AttributeValue, name string) string {
+	if value := attributes[name]; value != nil {
+		return aws.StringValue(value.N)
+	}
+	return ""
+}
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s)) // nolint: gosec
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,243 @@
+package engine
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/internal/log_analysis/notify"
+	"github.com/panther-labs/panther/internal/log_analysis/rulespec"
+)
+
+// The id message attribute holds the log type of processed data notifications
+const logTypeAttributeName = "id"
+
+// Handler evaluates spec rules over processed log files, the Go counterpart of the Python rules engine
+type Handler struct {
+	S3Client     s3iface.S3API
+	Rules        *RuleCache
+	Output       *OutputWriter
+	Correlations *CorrelationStore
+	Exceptions   *ExceptionCounter
+	Metrics      *RuleMetrics
+}
+
+// Stats reports the work done for a batch of notifications
+type Stats struct {
+	Events     int
+	Matches    int
+	Suppressed int
+}
+
+// HandleSQSEvent processes the S3 notifications in the queue messages.
+// If any file fails the whole batch is retried.
+func (h *Handler) HandleSQSEvent(event *events.SQSEvent) (*Stats, error) {
+	stats := &Stats{}
+	for i := range event.Records {
+		record := &event.Records[i]
+		attr, ok := record.MessageAttributes[logTypeAttributeName]
+		if !ok || attr.StringValue == nil {
+			zap.L().Warn("skipping message without log type", zap.String("messageId", record.MessageId))
+			continue
+		}
+		logType := *attr.StringValue
+		rules, err := h.Rules.Rules(logType)
+		if err != nil {
+			return stats, err
+		}
+		if len(rules) == 0 {
+			continue
+		}
+
+		var notification notify.S3Notification
+		if err := jsoniter.UnmarshalFromString(record.Body, &notification); err != nil {
+			return stats, errors.Wrap(err, "invalid S3 notification")
+		}
+		for _, s3Record := range notification.Records {
+			if err := h.processObject(stats, logType, rules, s3Record.S3.Bucket.Name, s3Record.S3.Object.Key); err != nil {
+				return stats, err
+			}
+		}
+	}
+	if err := h.Output.Flush(); err != nil {
+		return stats, err
+	}
+	// The alerts are out, retrying the batch for the counts would duplicate them
+	if err := h.Exceptions.Flush(); err != nil {
+		zap.L().Warn("failed to store exception counts", zap.Error(err))
+	}
+	return stats, nil
+}
+
+func (h *Handler) processObject(stats *Stats, logType string, rules []*Rule, bucket, key string) error {
+	output, err := h.S3Client.GetObject(&s3.GetObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get s3://%s/%s", bucket, key)
+	}
+	defer output.Body.Close()
+
+	gzipReader, err := gzip.NewReader(output.Body)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read s3://%s/%s", bucket, key)
+	}
+	reader := bufio.NewReader(gzipReader)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			if matchErr := h.processEvent(stats, logType, rules, line); matchErr != nil {
+				return matchErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to read s3://%s/%s", bucket, key)
+		}
+	}
+}
+
+func (h *Handler) processEvent(stats *Stats, logType string, rules []*Rule, line []byte) error {
+	var event map[string]interface{}
+	if err := jsoniter.Unmarshal(line, &event); err != nil {
+		// Best effort, do not log the data
+		zap.L().Error("data is not valid JSON", zap.Error(err))
+		return nil
+	}
+	stats.Events++
+	for _, rule := range rules {
+		start := time.Now()
+		if rule.Correlation() != nil {
+			fired, err := h.correlate(stats, logType, rule, event, len(line))
+			if err != nil {
+				return err
+			}
+			h.Metrics.Record(rule.ID, time.Since(start), fired, false)
+			continue
+		}
+		result := rule.Run(event, true)
+		h.Metrics.Record(rule.ID, time.Since(start), result.RuleOutput, result.Errored)
+		if !result.RuleOutput || h.suppressed(stats, rule, event) {
+			continue
+		}
+		stats.Matches++
+		err := h.Output.Add(&match{
+			rule:    rule,
+			logType: logType,
+			result:  result,
+			event:   event,
+			size:    len(line),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// correlate records the event for each step of the correlation rule it matches.
+// When the correlation completes, the event is output as the match of a single alert referencing all contributing events.
+// It reports whether the correlation fired.
+func (h *Handler) correlate(stats *Stats, logType string, rule *Rule, event map[string]interface{}, size int) (bool, error) {
+	matched := false
+	correlation := rule.Correlation()
+	for _, step := range correlation.Steps {
+		if step.LogType != logType {
+			continue
+		}
+		key, ok := step.Match(event)
+		if !ok || h.suppressed(stats, rule, event) {
+			continue
+		}
+		fired, err := h.Correlations.Add(rule, logType, key, step, event, eventTime(event, time.Now().UTC()))
+		if err != nil {
+			return matched, err
+		}
+		if fired == nil {
+			continue
+		}
+		matched = true
+		stats.Matches++
+		result := rule.RunCorrelated(correlation.Event(key, toCorrelatedEvents(fired)))
+		result.AlertContextOutput = correlationContext(result.AlertContextOutput, key, fired)
+		err = h.Output.Add(&match{
+			rule:    rule,
+			logType: logType,
+			result:  result,
+			event:   event,
+			size:    size,
+		})
+		if err != nil {
+			return matched, err
+		}
+	}
+	return matched, nil
+}
+
+// suppressed checks if an exception of the rule applies to a matching event
+func (h *Handler) suppressed(stats *Stats, rule *Rule, event map[string]interface{}) bool {
+	if len(rule.Exceptions) == 0 {
+		return false
+	}
+	now := time.Now().UTC()
+	exception := rulespec.MatchException(rule.Exceptions, event, now)
+	if exception == nil {
+		return false
+	}
+	stats.Suppressed++
+	h.Exceptions.Add(rule.ID, exception.ID, now)
+	return true
+}
+
+// correlationContext adds the key and references to the contributing events to the alert context
+func correlationContext(alertContext, key string, events []correlatedEvent) string {
+	context := make(map[string]interface{})
+	if alertContext != "" {
+		_ = jsoniter.UnmarshalFromString(alertContext, &context)
+	}
+	refs := make([]map[string]string, len(events))
+	for i, e := range events {
+		refs[i] = map[string]string{
+			"step":         e.Step,
+			"p_log_type":   e.LogType,
+			"p_row_id":     e.RowID,
+			"p_event_time": e.Time.Format(time.RFC3339Nano),
+		}
+	}
+	context["correlationKey"] = key
+	context["correlatedEvents"] = refs
+	result, err := jsoniter.MarshalToString(context)
+	if err != nil {
+		return alertContext
+	}
+	return result
+}
@@ -0,0 +1,488 @@
+package engine
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sns"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	enginemodels "github.com/panther-labs/panther/api/lambda/analysis"
+	"github.com/panther-labs/panther/api/lambda/analysis/models"
+	"github.com/panther-labs/panther/internal/log_analysis/notify"
+	"github.com/panther-labs/panther/internal/log_analysis/rulespec"
+	"github.com/panther-labs/panther/pkg/metrics"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+const testSpec = `
+match:
+  field: eventName
+  equals: ConsoleLogin
+title: "Login by {user}"
+`
+
+func gzipLines(t *testing.T, lines ...string) []byte {
+	var buffer bytes.Buffer
+	w := gzip.NewWriter(&buffer)
+	_, err := w.Write([]byte(strings.Join(lines, "\n")))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buffer.Bytes()
+}
+
+func gunzipLines(t *testing.T, data []byte) []map[string]interface{} {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	raw, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	var result []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(string(raw)), "\n") {
+		var event map[string]interface{}
+		require.NoError(t, jsoniter.UnmarshalFromString(line, &event))
+		result = append(result, event)
+	}
+	return result
+}
+
+func TestHandler(t *testing.T) {
+	analysisMock := &testutils.GatewayapiMock{}
+	analysisMock.On("Invoke", mock.Anything, mock.Anything).Return(200, nil).Run(func(args mock.Arguments) {
+		output := args.Get(1).(*models.ListRulesOutput)
+		*output = models.ListRulesOutput{
+			Paging: models.Paging{ThisPage: 1, TotalPages: 1},
+			Rules: []models.Rule{
+				{ID: "Python.Rule", Body: "def rule(e): return True", LogTypes: []string{"AWS.CloudTrail"}},
+				{ID: "Spec.Rule", Spec: testSpec, LogTypes: []string{"AWS.CloudTrail"}, VersionID: "v1", Tags: []string{"tag"}},
+			},
+		}
+	}).Once()
+
+	s3Mock := &testutils.S3Mock{}
+	s3Mock.On("GetObject", mock.Anything).Return(&s3.GetObjectOutput{
+		Body: ioutil.NopCloser(bytes.NewReader(gzipLines(t,
+			`{"eventName": "ConsoleLogin", "user": "alice"}`,
+			`not json`,
+			`{"eventName": "GetObject", "user": "alice"}`,
+			`{"eventName": "ConsoleLogin", "user": "alice"}`,
+			`{"eventName": "ConsoleLogin", "user": "bob"}`,
+		))),
+	}, nil).Once()
+	var written [][]byte
+	s3Mock.On("PutObject", mock.Anything).Return(&s3.PutObjectOutput{}, nil).Run(func(args mock.Arguments) {
+		input := args.Get(0).(*s3.PutObjectInput)
+		assert.Equal(t, "bucket", *input.Bucket)
+		assert.True(t, strings.HasPrefix(*input.Key, "rules/aws_cloudtrail/year="))
+		assert.Contains(t, *input.Key, "/rule_id=Spec.Rule/")
+		data, err := ioutil.ReadAll(input.Body)
+		require.NoError(t, err)
+		written = append(written, data)
+	}).Twice()
+
+	snsMock := &testutils.SnsMock{}
+	snsMock.On("Publish", mock.Anything).Return(&sns.PublishOutput{}, nil).Twice()
+
+	ddbMock := &testutils.DynamoDBMock{}
+	// alice has an existing alert within the dedup period, bob is a new alert
+	conditionFailed := awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "", nil)
+	ddbMock.On("UpdateItem", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return input.ConditionExpression != nil && *input.ExpressionAttributeValues[":5"].S == "Login by alice"
+	})).Return(&dynamodb.UpdateItemOutput{}, conditionFailed).Once()
+	ddbMock.On("UpdateItem", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return input.ConditionExpression == nil
+	})).Return(&dynamodb.UpdateItemOutput{
+		Attributes: map[string]*dynamodb.AttributeValue{
+			"alertCount":        {N: aws.String("3")},
+			"alertCreationTime": {N: aws.String("1600000000")},
+		},
+	}, nil).Once()
+	ddbMock.On("UpdateItem", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return input.ConditionExpression != nil && *input.ExpressionAttributeValues[":5"].S == "Login by bob"
+	})).Return(&dynamodb.UpdateItemOutput{
+		Attributes: map[string]*dynamodb.AttributeValue{
+			"alertCount": {N: aws.String("1")},
+		},
+	}, nil).Once()
+
+	var metricsOutput bytes.Buffer
+	cwManager := metrics.NewCWEmbeddedMetrics(&metricsOutput)
+	handler := &Handler{
+		S3Client: s3Mock,
+		Rules:    NewRuleCache(analysisMock),
+		Output: &OutputWriter{
+			S3Client:  s3Mock,
+			SNSClient: snsMock,
+			Merger:    &AlertMerger{DynamoDBClient: ddbMock, TableName: "dedup"},
+			Bucket:    "bucket",
+			TopicARN:  "topic",
+		},
+		Exceptions: &ExceptionCounter{DynamoDBClient: ddbMock, TableName: "exceptions"},
+		Metrics:    NewRuleMetrics(cwManager),
+	}
+
+	body, err := jsoniter.MarshalToString(notify.NewS3ObjectPutNotification("processed", "logs/aws_cloudtrail/file.json.gz", 100))
+	require.NoError(t, err)
+	stats, err := handler.HandleSQSEvent(&events.SQSEvent{
+		Records: []events.SQSMessage{
+			{
+				Body: body,
+				MessageAttributes: map[string]events.SQSMessageAttribute{
+					"id": {StringValue: aws.String("AWS.CloudTrail")},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &Stats{Events: 4, Matches: 3}, stats)
+
+	require.NoError(t, cwManager.Sync())
+	ruleMetrics := make(map[string]interface{})
+	for _, line := range strings.Split(strings.TrimSpace(metricsOutput.String()), "\n") {
+		var metric map[string]interface{}
+		require.NoError(t, jsoniter.UnmarshalFromString(line, &metric))
+		assert.Equal(t, "Spec.Rule", metric["AnalysisID"])
+		for _, name := range []string{MetricRuleEvaluations, MetricRuleErrors, MetricRuleMatches, MetricRuleLatency} {
+			if value, ok := metric[name]; ok {
+				ruleMetrics[name] = value
+			}
+		}
+	}
+	assert.Equal(t, float64(4), ruleMetrics[MetricRuleEvaluations])
+	assert.Equal(t, float64(3), ruleMetrics[MetricRuleMatches])
+	assert.NotContains(t, ruleMetrics, MetricRuleErrors)
+	assert.Len(t, ruleMetrics[MetricRuleLatency], 4)
+
+	analysisMock.AssertExpectations(t)
+	s3Mock.AssertExpectations(t)
+	snsMock.AssertExpectations(t)
+	ddbMock.AssertExpectations(t)
+
+	require.Len(t, written, 2)
+	byUser := make(map[string][]map[string]interface{})
+	for _, data := range written {
+		for _, event := range gunzipLines(t, data) {
+			byUser[event["user"].(string)] = append(byUser[event["user"].(string)], event)
+		}
+	}
+	require.Len(t, byUser["alice"], 2)
+	require.Len(t, byUser["bob"], 1)
+	alice := byUser["alice"][0]
+	assert.Equal(t, "Spec.Rule", alice["p_rule_id"])
+	assert.Equal(t, []interface{}{"tag"}, alice["p_rule_tags"])
+	assert.Equal(t, md5Hex("Spec.Rule:3:Login by alice"), alice["p_alert_id"])
+	assert.Equal(t, "2020-09-13 12:26:40.000000000", alice["p_alert_creation_time"])
+	assert.Equal(t, md5Hex("Spec.Rule:1:Login by bob"), byUser["bob"][0]["p_alert_id"])
+}
+
+func TestHandlerExceptions(t *testing.T) {
+	expired := time.Now().Add(-time.Hour)
+	analysisMock := &testutils.GatewayapiMock{}
+	analysisMock.On("Invoke", mock.Anything, mock.Anything).Return(200, nil).Run(func(args mock.Arguments) {
+		output := args.Get(1).(*models.ListRulesOutput)
+		*output = models.ListRulesOutput{
+			Paging: models.Paging{ThisPage: 1, TotalPages: 1},
+			Rules: []models.Rule{
+				{
+					ID:       "Spec.Rule",
+					Spec:     testSpec,
+					LogTypes: []string{"AWS.CloudTrail"},
+					Exceptions: []models.RuleException{
+						{ID: "scanners", Field: "user", Operator: "regex", Values: []string{"^scanner-"}},
+						{ID: "expired", Field: "user", Operator: "equals", Values: []string{"alice"}, ExpiresAt: &expired},
+						{ID: "invalid", Field: "user", Operator: "regex", Values: []string{"("}},
+					},
+				},
+			},
+		}
+	}).Once()
+
+	s3Mock := &testutils.S3Mock{}
+	s3Mock.On("GetObject", mock.Anything).Return(&s3.GetObjectOutput{
+		Body: ioutil.NopCloser(bytes.NewReader(gzipLines(t,
+			`{"eventName": "ConsoleLogin", "user": "scanner-1"}`,
+			`{"eventName": "ConsoleLogin", "user": "alice"}`,
+			`{"eventName": "ConsoleLogin", "user": "scanner-2"}`,
+		))),
+	}, nil).Once()
+	s3Mock.On("PutObject", mock.Anything).Return(&s3.PutObjectOutput{}, nil).Once()
+	snsMock := &testutils.SnsMock{}
+	snsMock.On("Publish", mock.Anything).Return(&sns.PublishOutput{}, nil).Once()
+
+	ddbMock := &testutils.DynamoDBMock{}
+	ddbMock.On("UpdateItem", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return *input.TableName == "dedup"
+	})).Return(&dynamodb.UpdateItemOutput{
+		Attributes: map[string]*dynamodb.AttributeValue{"alertCount": {N: aws.String("1")}},
+	}, nil).Once()
+	ddbMock.On("UpdateItem", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return *input.TableName == "exceptions" && *input.Key["ruleId"].S == "Spec.Rule" &&
+			*input.Key["exceptionId"].S == "scanners" && *input.ExpressionAttributeValues[":1"].N == "2"
+	})).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+
+	handler := &Handler{
+		S3Client: s3Mock,
+		Rules:    NewRuleCache(analysisMock),
+		Output: &OutputWriter{
+			S3Client:  s3Mock,
+			SNSClient: snsMock,
+			Merger:    &AlertMerger{DynamoDBClient: ddbMock, TableName: "dedup"},
+			Bucket:    "bucket",
+			TopicARN:  "topic",
+		},
+		Exceptions: &ExceptionCounter{DynamoDBClient: ddbMock, TableName: "exceptions"},
+		Metrics:    NewRuleMetrics(metrics.NewCWEmbeddedMetrics(ioutil.Discard)),
+	}
+
+	body, err := jsoniter.MarshalToString(notify.NewS3ObjectPutNotification("processed", "logs/aws_cloudtrail/file.json.gz", 100))
+	require.NoError(t, err)
+	stats, err := handler.HandleSQSEvent(&events.SQSEvent{
+		Records: []events.SQSMessage{
+			{
+				Body: body,
+				MessageAttributes: map[string]events.SQSMessageAttribute{
+					"id": {StringValue: aws.String("AWS.CloudTrail")},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &Stats{Events: 3, Matches: 1, Suppressed: 2}, stats)
+
+	analysisMock.AssertExpectations(t)
+	s3Mock.AssertExpectations(t)
+	snsMock.AssertExpectations(t)
+	ddbMock.AssertExpectations(t)
+}
+
+const testCorrelationSpec = `
+correlation:
+  windowMinutes: 15
+  sequence: true
+  steps:
+    - name: mfa_reset
+      logType: Okta.SystemLog
+      match: {field: eventType, equals: user.mfa.factor.reset_all}
+      key: target.0.alternateId
+    - name: console_login
+      logType: AWS.CloudTrail
+      match: {field: eventName, equals: ConsoleLogin}
+      key: userIdentity.userName
+title: "MFA reset followed by console login for {key}"
+alertContext:
+  ip: console_login.sourceIPAddress
+`
+
+// correlationTable is an in-memory correlation state table
+type correlationTable struct {
+	dynamodbiface.DynamoDBAPI
+	items map[string]map[string]*dynamodb.AttributeValue
+}
+
+func (c *correlationTable) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	return &dynamodb.GetItemOutput{Item: c.items[*input.Key["partitionKey"].S]}, nil
+}
+
+func (c *correlationTable) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	c.items[*input.Item["partitionKey"].S] = input.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func TestHandlerCorrelation(t *testing.T) {
+	analysisMock := &testutils.GatewayapiMock{}
+	analysisMock.On("Invoke", mock.Anything, mock.Anything).Return(200, nil).Run(func(args mock.Arguments) {
+		output := args.Get(1).(*models.ListRulesOutput)
+		*output = models.ListRulesOutput{
+			Paging: models.Paging{ThisPage: 1, TotalPages: 1},
+			Rules:  []models.Rule{{ID: "Correlation.Rule", Spec: testCorrelationSpec, VersionID: "v1"}},
+		}
+	}).Once()
+
+	files := map[string][]byte{
+		"logs/okta_systemlog/file.json.gz": gzipLines(t,
+			`{"eventType": "user.mfa.factor.reset_all", "target": [{"alternateId": "alice"}], "p_row_id": "a1",`+
+				` "p_event_time": "2020-11-02T10:00:00Z"}`,
+		),
+		"logs/aws_cloudtrail/file.json.gz": gzipLines(t,
+			// bob never had an MFA reset
+			`{"eventName": "ConsoleLogin", "userIdentity": {"userName": "bob"}, "p_event_time": "2020-11-02T10:05:00Z"}`,
+			`{"eventName": "ConsoleLogin", "userIdentity": {"userName": "Alice"}, "sourceIPAddress": "1.2.3.4", "p_row_id": "c1",`+
+				` "p_event_time": "2020-11-02T10:05:00Z"}`,
+		),
+	}
+	s3Mock := &testutils.S3Mock{}
+	for key, data := range files {
+		key := key
+		s3Mock.On("GetObject", mock.MatchedBy(func(input *s3.GetObjectInput) bool {
+			return *input.Key == key
+		})).Return(&s3.GetObjectOutput{Body: ioutil.NopCloser(bytes.NewReader(data))}, nil).Once()
+	}
+	var written []byte
+	s3Mock.On("PutObject", mock.Anything).Return(&s3.PutObjectOutput{}, nil).Run(func(args mock.Arguments) {
+		input := args.Get(0).(*s3.PutObjectInput)
+		assert.Contains(t, *input.Key, "rules/aws_cloudtrail/")
+		data, err := ioutil.ReadAll(input.Body)
+		require.NoError(t, err)
+		written = data
+	}).Once()
+	snsMock := &testutils.SnsMock{}
+	snsMock.On("Publish", mock.Anything).Return(&sns.PublishOutput{}, nil).Once()
+
+	ddbMock := &testutils.DynamoDBMock{}
+	correlations := &correlationTable{items: make(map[string]map[string]*dynamodb.AttributeValue)}
+	var alertUpdate *dynamodb.UpdateItemInput
+	ddbMock.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{
+		Attributes: map[string]*dynamodb.AttributeValue{"alertCount": {N: aws.String("1")}},
+	}, nil).Run(func(args mock.Arguments) {
+		alertUpdate = args.Get(0).(*dynamodb.UpdateItemInput)
+	}).Once()
+
+	handler := &Handler{
+		S3Client: s3Mock,
+		Rules:    NewRuleCache(analysisMock),
+		Output: &OutputWriter{
+			S3Client:  s3Mock,
+			SNSClient: snsMock,
+			Merger:    &AlertMerger{DynamoDBClient: ddbMock, TableName: "dedup"},
+			Bucket:    "bucket",
+			TopicARN:  "topic",
+		},
+		Correlations: &CorrelationStore{DynamoDBClient: correlations, TableName: "correlation"},
+		Exceptions:   &ExceptionCounter{DynamoDBClient: ddbMock, TableName: "exceptions"},
+		Metrics:      NewRuleMetrics(metrics.NewCWEmbeddedMetrics(ioutil.Discard)),
+	}
+
+	message := func(logType, key string) events.SQSMessage {
+		body, err := jsoniter.MarshalToString(notify.NewS3ObjectPutNotification("processed", key, 100))
+		require.NoError(t, err)
+		return events.SQSMessage{
+			Body:              body,
+			MessageAttributes: map[string]events.SQSMessageAttribute{"id": {StringValue: aws.String(logType)}},
+		}
+	}
+	stats, err := handler.HandleSQSEvent(&events.SQSEvent{
+		Records: []events.SQSMessage{
+			message("Okta.SystemLog", "logs/okta_systemlog/file.json.gz"),
+			message("AWS.CloudTrail", "logs/aws_cloudtrail/file.json.gz"),
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &Stats{Events: 3, Matches: 1}, stats)
+	s3Mock.AssertExpectations(t)
+	snsMock.AssertExpectations(t)
+	ddbMock.AssertExpectations(t)
+
+	// The completed correlation cleared the state of alice, bob is waiting for an MFA reset
+	require.Len(t, correlations.items, 2)
+	assert.Empty(t, correlations.items[md5Hex("Correlation.Rule:alice")]["events"].L)
+	assert.Len(t, correlations.items[md5Hex("Correlation.Rule:bob")]["events"].L, 1)
+
+	require.NotNil(t, alertUpdate)
+	assert.Equal(t, "MFA reset followed by console login for alice", *alertUpdate.ExpressionAttributeValues[":5"].S)
+	var context struct {
+		IP               string              `json:"ip"`
+		CorrelationKey   string              `json:"correlationKey"`
+		CorrelatedEvents []map[string]string `json:"correlatedEvents"`
+	}
+	require.NoError(t, jsoniter.UnmarshalFromString(*alertUpdate.ExpressionAttributeValues[":12"].S, &context))
+	assert.Equal(t, "1.2.3.4", context.IP)
+	assert.Equal(t, "alice", context.CorrelationKey)
+	require.Len(t, context.CorrelatedEvents, 2)
+	assert.Equal(t, "a1", context.CorrelatedEvents[0]["p_row_id"])
+	assert.Equal(t, "Okta.SystemLog", context.CorrelatedEvents[0]["p_log_type"])
+	assert.Equal(t, "c1", context.CorrelatedEvents[1]["p_row_id"])
+
+	output := gunzipLines(t, written)
+	require.Len(t, output, 1)
+	assert.Equal(t, "c1", output[0]["p_row_id"])
+}
+
+func TestHandlerCorrelationRetry(t *testing.T) {
+	analysisMock := &testutils.GatewayapiMock{}
+	analysisMock.On("Invoke", mock.Anything, mock.Anything).Return(200, nil).Run(func(args mock.Arguments) {
+		output := args.Get(1).(*models.ListRulesOutput)
+		*output = models.ListRulesOutput{
+			Paging: models.Paging{ThisPage: 1, TotalPages: 1},
+			Rules:  []models.Rule{{ID: "Correlation.Rule", Spec: testCorrelationSpec, VersionID: "v1"}},
+		}
+	}).Once()
+
+	data := gzipLines(t,
+		`{"eventType": "user.mfa.factor.reset_all", "target": [{"alternateId": "alice"}], "p_row_id": "a1",`+
+			` "p_event_time": "2020-11-02T10:00:00Z"}`,
+	)
+	s3Mock := &testutils.S3Mock{}
+	s3Mock.On("GetObject", mock.Anything).Return(&s3.GetObjectOutput{Body: ioutil.NopCloser(bytes.NewReader(data))}, nil).Once()
+	s3Mock.On("GetObject", mock.Anything).Return(&s3.GetObjectOutput{Body: ioutil.NopCloser(bytes.NewReader(data))}, nil).Once()
+
+	correlations := &correlationTable{items: make(map[string]map[string]*dynamodb.AttributeValue)}
+	handler := &Handler{
+		S3Client:     s3Mock,
+		Rules:        NewRuleCache(analysisMock),
+		Output:       &OutputWriter{S3Client: s3Mock, Bucket: "bucket", TopicARN: "topic"},
+		Correlations: &CorrelationStore{DynamoDBClient: correlations, TableName: "correlation"},
+		Exceptions:   &ExceptionCounter{DynamoDBClient: &testutils.DynamoDBMock{}, TableName: "exceptions"},
+		Metrics:      NewRuleMetrics(metrics.NewCWEmbeddedMetrics(ioutil.Discard)),
+	}
+
+	body, err := jsoniter.MarshalToString(notify.NewS3ObjectPutNotification("processed", "logs/okta_systemlog/file.json.gz", 100))
+	require.NoError(t, err)
+	event := &events.SQSEvent{
+		Records: []events.SQSMessage{{
+			Body:              body,
+			MessageAttributes: map[string]events.SQSMessageAttribute{"id": {StringValue: aws.String("Okta.SystemLog")}},
+		}},
+	}
+	// The same batch is delivered again after a failure
+	for i := 0; i < 2; i++ {
+		_, err := handler.HandleSQSEvent(event)
+		require.NoError(t, err)
+	}
+	s3Mock.AssertExpectations(t)
+
+	// The MFA reset is recorded once
+	stored := correlations.items[md5Hex("Correlation.Rule:alice")]["events"].L
+	require.Len(t, stored, 1)
+	assert.Equal(t, "a1", *stored[0].M["rowId"].S)
+}
+
+func TestOutputEventAlertTimes(t *testing.T) {
+	created := time.Date(2020, 9, 13, 12, 26, 40, 123456789, time.UTC)
+	out := outputEvent(
+		&match{rule: &Rule{Rule: &rulespec.Rule{ID: "Rule"}}, result: &enginemodels.RuleResult{}, event: map[string]interface{}{}},
+		&AlertInfo{AlertID: "alert", CreationTime: created, UpdateTime: created.Add(time.Second)},
+	)
+	// Microsecond precision padded with zeros, like the Python engine
+	assert.Equal(t, "2020-09-13 12:26:40.123456000", out["p_alert_creation_time"])
+	assert.Equal(t, "2020-09-13 12:26:41.123456000", out["p_alert_update_time"])
+}
@@ -0,0 +1,89 @@
+package engine
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/pkg/errors"
+)
+
+// ExceptionCounter counts the events suppressed by rule exceptions, shared with the Python rules engine.
+// Counts are buffered in memory and added to the table on Flush.
+type ExceptionCounter struct {
+	DynamoDBClient dynamodbiface.DynamoDBAPI
+	TableName      string
+	counts         map[exceptionKey]*exceptionCount
+}
+
+type exceptionKey struct {
+	RuleID      string
+	ExceptionID string
+}
+
+type exceptionCount struct {
+	count int64
+	last  time.Time
+}
+
+// Add records an event suppressed by an exception of a rule
+func (c *ExceptionCounter) Add(ruleID, exceptionID string, now time.Time) {
+	if c.counts == nil {
+		c.counts = make(map[exceptionKey]*exceptionCount)
+	}
+	key := exceptionKey{RuleID: ruleID, ExceptionID: exceptionID}
+	count, ok := c.counts[key]
+	if !ok {
+		count = &exceptionCount{}
+		c.counts[key] = count
+	}
+	count.count++
+	count.last = now
+}
+
+// Flush adds the buffered counts to the table
+func (c *ExceptionCounter) Flush() error {
+	for key, count := range c.counts {
+		_, err := c.DynamoDBClient.UpdateItem(&dynamodb.UpdateItemInput{
+			TableName: &c.TableName,
+			Key: map[string]*dynamodb.AttributeValue{
+				"ruleId":      {S: aws.String(key.RuleID)},
+				"exceptionId": {S: aws.String(key.ExceptionID)},
+			},
+			UpdateExpression: aws.String("ADD #1 :1 SET #2 = :2"),
+			ExpressionAttributeNames: map[string]*string{
+				"#1": aws.String("suppressedCount"),
+				"#2": aws.String("lastSuppressedAt"),
+			},
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":1": {N: aws.String(strconv.FormatInt(count.count, 10))},
+				":2": {S: aws.String(count.last.Format(time.RFC3339))},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to update exception counts")
+		}
+		delete(c.counts, key)
+	}
+	return nil
+}
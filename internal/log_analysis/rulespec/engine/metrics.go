@@ -0,0 +1,65 @@
+package engine
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/panther-labs/panther/pkg/metrics"
+)
+
+// Per-rule telemetry, shared with the Python rules engine, the metrics API and the rule health checks
+const (
+	MetricRuleEvaluations = "RuleEvaluations"
+	MetricRuleErrors      = "RuleErrors"
+	MetricRuleMatches     = "RuleMatches"
+	MetricRuleLatency     = "RuleLatency"
+
+	AnalysisIDDimension = "AnalysisID"
+)
+
+// RuleMetrics records the evaluations, errors, matches and latency of each rule
+type RuleMetrics struct {
+	evaluations metrics.Counter
+	errors      metrics.Counter
+	matches     metrics.Counter
+	latency     metrics.Histogram
+}
+
+// NewRuleMetrics creates the rule metrics, which are written on every Sync of the manager
+func NewRuleMetrics(manager metrics.Manager) *RuleMetrics {
+	return &RuleMetrics{
+		evaluations: manager.NewCounter(MetricRuleEvaluations, metrics.UnitCount),
+		errors:      manager.NewCounter(MetricRuleErrors, metrics.UnitCount),
+		matches:     manager.NewCounter(MetricRuleMatches, metrics.UnitCount),
+		latency:     manager.NewHistogram(MetricRuleLatency, metrics.UnitMilliseconds),
+	}
+}
+
+// Record adds a single evaluation of a rule
+func (m *RuleMetrics) Record(ruleID string, latency time.Duration, matched, errored bool) {
+	m.evaluations.With(AnalysisIDDimension, ruleID).Add(1)
+	m.latency.With(AnalysisIDDimension, ruleID).Observe(float64(latency) / float64(time.Millisecond))
+	if matched {
+		m.matches.With(AnalysisIDDimension, ruleID).Add(1)
+	}
+	if errored {
+		m.errors.With(AnalysisIDDimension, ruleID).Add(1)
+	}
+}
@@ -0,0 +1,229 @@
+package engine
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sns/snsiface"
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+
+	enginemodels "github.com/panther-labs/panther/api/lambda/analysis"
+	"github.com/panther-labs/panther/internal/log_analysis/notify"
+	"github.com/panther-labs/panther/internal/log_analysis/pantherdb"
+)
+
+const (
+	// Same layout as the matches written by the Python rules engine
+	ruleMatchesKeyFormat = "rules/%s/year=%d/month=%02d/day=%02d/hour=%02d/rule_id=%s/%s-%s.json.gz"
+	s3KeyDateLayout      = "20060102T150405Z"
+	// Nanosecond layout, alert times are truncated to microseconds first so the last 3 digits are
+	// always zero like the "%f000" format of the Python engine.
+	alertTimeLayout = "2006-01-02 15:04:05.000000000"
+	// Maximum size of the buffered events before the largest group is flushed
+	maxBufferedBytes = 100000000
+)
+
+// OutputWriter stores the events that matched rules and publishes a notification for each new S3 object
+type OutputWriter struct {
+	S3Client    s3iface.S3API
+	SNSClient   snsiface.SNSAPI
+	Merger      *AlertMerger
+	Bucket      string
+	TopicARN    string
+	maxBytes    int
+	bufferBytes int
+	groups      map[groupKey]*eventGroup
+}
+
+type groupKey struct {
+	RuleID  string
+	LogType string
+	Dedup   string
+}
+
+type eventGroup struct {
+	matches []*match
+	size    int
+}
+
+type match struct {
+	rule    *Rule
+	logType string
+	result  *enginemodels.RuleResult
+	event   map[string]interface{}
+	size    int
+}
+
+// Add buffers a matched event, flushing the largest group if the buffer is full
+func (w *OutputWriter) Add(m *match) error {
+	if w.groups == nil {
+		w.groups = make(map[groupKey]*eventGroup)
+	}
+	if w.maxBytes == 0 {
+		w.maxBytes = maxBufferedBytes
+	}
+	key := groupKey{RuleID: m.rule.ID, LogType: m.logType, Dedup: m.result.DedupOutput}
+	group, ok := w.groups[key]
+	if !ok {
+		group = &eventGroup{}
+		w.groups[key] = group
+	}
+	group.matches = append(group.matches, m)
+	group.size += m.size
+	w.bufferBytes += m.size
+
+	if w.bufferBytes <= w.maxBytes {
+		return nil
+	}
+	var largest groupKey
+	maxSize := 0
+	for key, group := range w.groups {
+		if group.size > maxSize {
+			largest, maxSize = key, group.size
+		}
+	}
+	if err := w.write(time.Now().UTC(), largest, w.groups[largest].matches); err != nil {
+		return err
+	}
+	w.bufferBytes -= maxSize
+	delete(w.groups, largest)
+	return nil
+}
+
+// Flush writes all buffered events to S3
+func (w *OutputWriter) Flush() error {
+	now := time.Now().UTC()
+	for key, group := range w.groups {
+		if err := w.write(now, key, group.matches); err != nil {
+			return err
+		}
+		delete(w.groups, key)
+	}
+	w.bufferBytes = 0
+	return nil
+}
+
+func (w *OutputWriter) write(now time.Time, key groupKey, matches []*match) error {
+	// The rule version, title etc might differ if the rule was modified while running. Pick the first.
+	first := matches[0]
+	info, err := w.Merger.UpdateAlertInfo(&MatchGroup{
+		RuleID:             key.RuleID,
+		RuleVersion:        first.rule.Version,
+		LogTypes:           []string{key.LogType},
+		Dedup:              key.Dedup,
+		DedupPeriodMinutes: first.rule.DedupPeriodMinutes,
+		NumMatches:         len(matches),
+		ProcessingTime:     now,
+		AlertContext:       first.result.AlertContextOutput,
+		Title:              first.result.TitleOutput,
+		Description:        first.result.DescriptionOutput,
+		Reference:          first.result.ReferenceOutput,
+		Severity:           first.result.SeverityOutput,
+		Runbook:            first.result.RunbookOutput,
+		Destinations:       first.result.DestinationsOutput,
+	})
+	if err != nil {
+		return err
+	}
+
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	stream := jsoniter.NewStream(jsoniter.ConfigDefault, writer, 8192)
+	for _, m := range matches {
+		stream.WriteVal(outputEvent(m, info))
+		stream.WriteRaw("\n")
+		if stream.Error != nil {
+			return errors.Wrap(stream.Error, "failed to serialize event")
+		}
+	}
+	if err := stream.Flush(); err != nil {
+		return errors.Wrap(err, "failed to serialize events")
+	}
+	if err := writer.Close(); err != nil {
+		return errors.Wrap(err, "failed to compress events")
+	}
+
+	objectKey := fmt.Sprintf(ruleMatchesKeyFormat, pantherdb.TableName(key.LogType),
+		now.Year(), now.Month(), now.Day(), now.Hour(), key.RuleID, now.Format(s3KeyDateLayout), uuid.New())
+	size := buffer.Len()
+	_, err = w.S3Client.PutObject(&s3.PutObjectInput{
+		Bucket:      &w.Bucket,
+		Key:         &objectKey,
+		Body:        bytes.NewReader(buffer.Bytes()),
+		ContentType: aws.String("gzip"),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to write rule matches to S3")
+	}
+
+	notification, err := jsoniter.MarshalToString(notify.NewS3ObjectPutNotification(w.Bucket, objectKey, size))
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal notification")
+	}
+	// The attributes are needed so that subscribers to the topic can filter the notifications
+	_, err = w.SNSClient.Publish(&sns.PublishInput{
+		TopicArn:          &w.TopicARN,
+		Message:           &notification,
+		MessageAttributes: notify.NewLogAnalysisSNSMessageAttributes(pantherdb.RuleData, key.RuleID),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to send notification to topic")
+	}
+	return nil
+}
+
+// outputEvent adds the rule and alert fields to an event. Fields of the event take precedence.
+func outputEvent(m *match, info *AlertInfo) map[string]interface{} {
+	var alertContext interface{}
+	if m.result.AlertContextOutput != "" {
+		alertContext = m.result.AlertContextOutput
+	}
+	tags := m.rule.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	reports := m.rule.Reports
+	if reports == nil {
+		reports = map[string][]string{}
+	}
+	out := map[string]interface{}{
+		"p_rule_id":             m.rule.ID,
+		"p_rule_tags":           tags,
+		"p_rule_reports":        reports,
+		"p_alert_id":            info.AlertID,
+		"p_alert_creation_time": info.CreationTime.Truncate(time.Microsecond).Format(alertTimeLayout),
+		"p_alert_update_time":   info.UpdateTime.Truncate(time.Microsecond).Format(alertTimeLayout),
+		"p_rule_error":          nil,
+		"p_alert_context":       alertContext,
+	}
+	for k, v := range m.event {
+		out[k] = v
+	}
+	return out
+}
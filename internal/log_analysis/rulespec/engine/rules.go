@@ -0,0 +1,143 @@
+package engine
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/analysis/models"
+	"github.com/panther-labs/panther/internal/log_analysis/rulespec"
+	"github.com/panther-labs/panther/pkg/gatewayapi"
+)
+
+const (
+	rulesCacheDuration            = 5 * time.Minute
+	defaultRuleDedupPeriodMinutes = 60
+	listRulesPageSize             = 250
+)
+
+// Rule is a compiled spec rule along with the metadata needed for alerting
+type Rule struct {
+	*rulespec.Rule
+	Version            string
+	Tags               []string
+	Reports            map[string][]string
+	DedupPeriodMinutes int
+	Exceptions         []*rulespec.Exception
+}
+
+// RuleCache keeps the enabled spec rules by log type, refreshing them periodically like the Python engine does.
+type RuleCache struct {
+	client     gatewayapi.API
+	lastUpdate time.Time
+	byLogType  map[string][]*Rule
+}
+
+func NewRuleCache(client gatewayapi.API) *RuleCache {
+	return &RuleCache{client: client}
+}
+
+// Rules returns the enabled spec rules for a log type
+func (c *RuleCache) Rules(logType string) ([]*Rule, error) {
+	if time.Since(c.lastUpdate) > rulesCacheDuration {
+		if err := c.refresh(); err != nil {
+			return nil, err
+		}
+	}
+	return c.byLogType[logType], nil
+}
+
+func (c *RuleCache) refresh() error {
+	input := models.LambdaInput{
+		ListRules: &models.ListRulesInput{
+			Enabled:  aws.Bool(true),
+			Page:     1,
+			PageSize: listRulesPageSize,
+		},
+	}
+	byLogType := make(map[string][]*Rule)
+	for {
+		var output models.ListRulesOutput
+		statusCode, err := c.client.Invoke(&input, &output)
+		if err != nil {
+			return errors.Wrap(err, "failed to list rules")
+		}
+		if statusCode != http.StatusOK {
+			return errors.Errorf("failed to list rules: status code %d", statusCode)
+		}
+		for i := range output.Rules {
+			rule := &output.Rules[i]
+			if rule.Spec == "" {
+				// Python rules are handled by the Python rules engine
+				continue
+			}
+			compiled, err := rulespec.Parse(rule.ID, rule.Spec)
+			if err != nil {
+				zap.L().Error("failed to compile rule spec", zap.String("ruleId", rule.ID), zap.Error(err))
+				continue
+			}
+			dedupPeriod := rule.DedupPeriodMinutes
+			if dedupPeriod == 0 {
+				dedupPeriod = defaultRuleDedupPeriodMinutes
+			}
+			r := &Rule{
+				Rule:               compiled,
+				Version:            rule.VersionID,
+				Tags:               rule.Tags,
+				Reports:            rule.Reports,
+				DedupPeriodMinutes: dedupPeriod,
+				Exceptions:         compileExceptions(rule),
+			}
+			logTypes := rule.LogTypes
+			if correlation := compiled.Correlation(); correlation != nil {
+				// Correlation rules receive the events of the log types of their steps
+				logTypes = correlation.LogTypes()
+			}
+			for _, logType := range logTypes {
+				byLogType[logType] = append(byLogType[logType], r)
+			}
+		}
+		if output.Paging.ThisPage >= output.Paging.TotalPages {
+			break
+		}
+		input.ListRules.Page++
+	}
+	c.byLogType = byLogType
+	c.lastUpdate = time.Now()
+	return nil
+}
+
+// compileExceptions skips invalid exceptions, the analysis API validates them so this should not happen
+func compileExceptions(rule *models.Rule) []*rulespec.Exception {
+	var result []*rulespec.Exception
+	for _, e := range rule.Exceptions {
+		compiled, err := rulespec.NewException(e.ID, e.Field, e.Operator, e.Values, e.ExpiresAt)
+		if err != nil {
+			zap.L().Error("failed to compile rule exception", zap.String("ruleId", rule.ID), zap.Error(err))
+			continue
+		}
+		result = append(result, compiled)
+	}
+	return result
+}
@@ -0,0 +1,157 @@
+package rulespec
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Operators of rule exceptions, an exception matches if the field matches any of its values
+const (
+	ExceptionEquals   = "equals"
+	ExceptionContains = "contains"
+	ExceptionRegex    = "regex"
+	ExceptionCIDR     = "cidr"
+)
+
+// Exception suppresses the matches of a rule on events with a known-benign field value.
+//
+// Exceptions are kept separate from the rule logic so that they apply to Python and spec rules alike.
+// The Python rules engine implements the same semantics.
+type Exception struct {
+	ID        string
+	ExpiresAt *time.Time
+	matcher   matcher
+}
+
+// NewException compiles an exception, checking its field path and values
+func NewException(id, field, operator string, values []string, expiresAt *time.Time) (*Exception, error) {
+	path, err := parseFieldPath(field)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, errors.Errorf("exception %q has no values", id)
+	}
+
+	var test func(value interface{}) bool
+	switch operator {
+	case ExceptionEquals:
+		test = func(value interface{}) bool {
+			switch value.(type) {
+			case string, bool, float64:
+			default:
+				return false // nested objects and nulls are never equal
+			}
+			s := formatValue(value)
+			for _, v := range values {
+				if s == v {
+					return true
+				}
+			}
+			return false
+		}
+	case ExceptionContains:
+		test = func(value interface{}) bool {
+			s, ok := value.(string)
+			if !ok {
+				return false
+			}
+			for _, v := range values {
+				if strings.Contains(s, v) {
+					return true
+				}
+			}
+			return false
+		}
+	case ExceptionRegex:
+		patterns := make([]*regexp.Regexp, len(values))
+		for i, v := range values {
+			if patterns[i], err = regexp.Compile(v); err != nil {
+				return nil, errors.Wrapf(err, "exception %q invalid regex", id)
+			}
+		}
+		test = func(value interface{}) bool {
+			s, ok := value.(string)
+			if !ok {
+				return false
+			}
+			for _, re := range patterns {
+				if re.MatchString(s) {
+					return true
+				}
+			}
+			return false
+		}
+	case ExceptionCIDR:
+		networks := make([]*net.IPNet, len(values))
+		for i, v := range values {
+			if _, networks[i], err = net.ParseCIDR(v); err != nil {
+				return nil, errors.Wrapf(err, "exception %q", id)
+			}
+		}
+		test = func(value interface{}) bool {
+			s, ok := value.(string)
+			if !ok {
+				return false
+			}
+			ip := net.ParseIP(s)
+			if ip == nil {
+				return false
+			}
+			for _, network := range networks {
+				if network.Contains(ip) {
+					return true
+				}
+			}
+			return false
+		}
+	default:
+		return nil, errors.Errorf("exception %q has unknown operator %q", id, operator)
+	}
+
+	return &Exception{
+		ID:        id,
+		ExpiresAt: expiresAt,
+		matcher:   &fieldMatcher{path: path, test: test},
+	}, nil
+}
+
+// Match checks if the exception suppresses an event. Expired exceptions match nothing.
+func (e *Exception) Match(event map[string]interface{}, now time.Time) bool {
+	if e.ExpiresAt != nil && !now.Before(*e.ExpiresAt) {
+		return false
+	}
+	return e.matcher.Match(event)
+}
+
+// MatchException returns the first exception which suppresses the event, or nil
+func MatchException(exceptions []*Exception, event map[string]interface{}, now time.Time) *Exception {
+	for _, e := range exceptions {
+		if e.Match(event, now) {
+			return e
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package rulespec
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strconv"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+)
+
+// fieldPath is a dot separated path to a field of an event.
+// Numeric segments index into lists, e.g. `resources.0.arn`.
+type fieldPath []string
+
+func parseFieldPath(path string) (fieldPath, error) {
+	if path == "" {
+		return nil, errors.New("empty field path")
+	}
+	segments := strings.Split(path, ".")
+	for _, s := range segments {
+		if s == "" {
+			return nil, errors.Errorf("invalid field path %q", path)
+		}
+	}
+	return segments, nil
+}
+
+// Lookup returns the value at the path and whether it was found
+func (p fieldPath) Lookup(event map[string]interface{}) (interface{}, bool) {
+	var value interface{} = event
+	for _, segment := range p {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			next, ok := v[segment]
+			if !ok {
+				return nil, false
+			}
+			value = next
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, false
+			}
+			value = v[index]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+func (p fieldPath) String() string {
+	return strings.Join(p, ".")
+}
+
+// formatValue renders a field value in templates
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		s, err := jsoniter.MarshalToString(v)
+		if err != nil {
+			return ""
+		}
+		return s
+	}
+}
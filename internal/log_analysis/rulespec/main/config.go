@@ -0,0 +1,83 @@
+package main
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/kelseyhightower/envconfig"
+
+	"github.com/panther-labs/panther/internal/log_analysis/rulespec/engine"
+	"github.com/panther-labs/panther/pkg/gatewayapi"
+	"github.com/panther-labs/panther/pkg/metrics"
+)
+
+var (
+	env       envConfig
+	handler   *engine.Handler
+	cwManager metrics.Manager
+)
+
+type envConfig struct {
+	S3Bucket             string `required:"true" split_words:"true"`
+	NotificationsTopic   string `required:"true" split_words:"true"`
+	AlertsDedupTable     string `required:"true" split_words:"true"`
+	CorrelationTable     string `required:"true" split_words:"true"`
+	ExceptionCountsTable string `required:"true" split_words:"true"`
+}
+
+// Setup parses the environment and builds the AWS clients.
+func Setup() {
+	envconfig.MustProcess("", &env)
+
+	awsSession := session.Must(session.NewSession())
+	s3Client := s3.New(awsSession)
+	analysisClient := gatewayapi.NewClient(lambda.New(awsSession), "panther-analysis-api")
+	dynamoClient := dynamodb.New(awsSession)
+	cwManager = metrics.NewCWEmbeddedMetrics(os.Stdout)
+
+	handler = &engine.Handler{
+		S3Client: s3Client,
+		Rules:    engine.NewRuleCache(analysisClient),
+		Output: &engine.OutputWriter{
+			S3Client:  s3Client,
+			SNSClient: sns.New(awsSession),
+			Merger: &engine.AlertMerger{
+				DynamoDBClient: dynamoClient,
+				TableName:      env.AlertsDedupTable,
+			},
+			Bucket:   env.S3Bucket,
+			TopicARN: env.NotificationsTopic,
+		},
+		Correlations: &engine.CorrelationStore{
+			DynamoDBClient: dynamoClient,
+			TableName:      env.CorrelationTable,
+		},
+		Exceptions: &engine.ExceptionCounter{
+			DynamoDBClient: dynamoClient,
+			TableName:      env.ExceptionCountsTable,
+		},
+		Metrics: engine.NewRuleMetrics(cwManager),
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+	"github.com/panther-labs/panther/pkg/lambdalogger"
+)
+
+func init() {
+	// Required only once per Lambda container
+	Setup()
+}
+
+func main() {
+	lambda.Start(handle)
+}
+
+func handle(ctx context.Context, event events.SQSEvent) error {
+	lc, _ := lambdalogger.ConfigureGlobal(ctx, nil)
+	return process(lc, &event)
+}
+
+func process(lc *lambdacontext.LambdaContext, event *events.SQSEvent) (err error) {
+	operation := common.OpLogManager.Start(lc.InvokedFunctionArn, common.OpLogLambdaServiceDim).WithMemUsed(lambdacontext.MemoryLimitInMB)
+	defer func() {
+		if err := cwManager.Sync(); err != nil {
+			zap.L().Warn("failed to sync metrics", zap.Error(err))
+		}
+	}()
+	stats, err := handler.HandleSQSEvent(event)
+	operation.Stop().Log(err,
+		zap.Int("messageCount", len(event.Records)),
+		zap.Int("eventCount", stats.Events),
+		zap.Int("matchCount", stats.Matches))
+	return err
+}
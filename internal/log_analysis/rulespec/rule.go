@@ -0,0 +1,295 @@
+package rulespec
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+
+	enginemodels "github.com/panther-labs/panther/api/lambda/analysis"
+)
+
+// MaxDestinationsSize is the maximum number of destination overrides
+const MaxDestinationsSize = 10
+
+var severityTypes = map[string]bool{
+	"INFO":     true,
+	"LOW":      true,
+	"MEDIUM":   true,
+	"HIGH":     true,
+	"CRITICAL": true,
+}
+
+// Rule is a compiled rule spec
+type Rule struct {
+	ID string
+
+	matcher      matcher
+	correlation  *Correlation
+	title        *template
+	dedup        *template
+	description  *template
+	reference    *template
+	runbook      *template
+	severity     *template
+	destinations []string
+	alertContext []contextField
+}
+
+type contextField struct {
+	key  string
+	path fieldPath
+}
+
+// Compile builds a rule from a spec
+func Compile(ruleID string, s *Spec) (*Rule, error) {
+	var rule Rule
+	var err error
+	switch {
+	case s.Match != nil && s.Correlation != nil:
+		return nil, errors.New("rule spec cannot have both match and correlation")
+	case s.Match != nil:
+		if rule.matcher, err = compileCondition(s.Match); err != nil {
+			return nil, errors.Wrap(err, "invalid rule spec match")
+		}
+	case s.Correlation != nil:
+		if rule.correlation, err = compileCorrelation(s.Correlation); err != nil {
+			return nil, errors.Wrap(err, "invalid rule spec correlation")
+		}
+	default:
+		return nil, errors.New("rule spec requires match or correlation")
+	}
+	rule.ID = ruleID
+	templates := []struct {
+		name string
+		src  string
+		dst  **template
+	}{
+		{"title", s.Title, &rule.title},
+		{"dedup", s.Dedup, &rule.dedup},
+		{"description", s.Description, &rule.description},
+		{"reference", s.Reference, &rule.reference},
+		{"runbook", s.Runbook, &rule.runbook},
+		{"severity", s.Severity, &rule.severity},
+	}
+	for _, t := range templates {
+		if *t.dst, err = compileTemplate(t.src); err != nil {
+			return nil, errors.Wrapf(err, "invalid rule spec %s", t.name)
+		}
+	}
+	// Static severities can be checked right away
+	if rule.severity != nil && len(rule.severity.fields) == 0 {
+		if !severityTypes[strings.ToUpper(s.Severity)] {
+			return nil, errors.Errorf("invalid rule spec severity %q", s.Severity)
+		}
+	}
+	if len(s.Destinations) > MaxDestinationsSize {
+		return nil, errors.Errorf("rule spec has more than %d destinations", MaxDestinationsSize)
+	}
+	rule.destinations = s.Destinations
+	for key, path := range s.AlertContext {
+		p, err := parseFieldPath(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid rule spec alertContext %q", key)
+		}
+		rule.alertContext = append(rule.alertContext, contextField{key: key, path: p})
+	}
+	sort.Slice(rule.alertContext, func(i, j int) bool {
+		return rule.alertContext[i].key < rule.alertContext[j].key
+	})
+	return &rule, nil
+}
+
+// Match reports whether the event matches the rule. Correlation rules never match a single event.
+func (r *Rule) Match(event map[string]interface{}) bool {
+	return r.matcher != nil && r.matcher.Match(event)
+}
+
+// Correlation returns the correlation of the rule, or nil if the rule matches single events
+func (r *Rule) Correlation() *Correlation {
+	return r.correlation
+}
+
+// Run evaluates the rule and its generated fields, returning the same result a Python rule would.
+//
+// In batch mode (log analysis) the generated fields are only computed when the rule matches and
+// errors in them are replaced by defaults, so that an alert is never missed.
+func (r *Rule) Run(event map[string]interface{}, batchMode bool) *enginemodels.RuleResult {
+	result := &enginemodels.RuleResult{
+		RuleID:     r.ID,
+		RuleOutput: r.Match(event),
+	}
+	if batchMode && !result.RuleOutput {
+		return result
+	}
+	r.render(result, event, batchMode)
+	return result
+}
+
+// RunCorrelated computes the generated fields of a correlation rule that fired, in batch mode.
+// The event is built with Correlation.Event.
+func (r *Rule) RunCorrelated(event map[string]interface{}) *enginemodels.RuleResult {
+	result := &enginemodels.RuleResult{
+		RuleID:     r.ID,
+		RuleOutput: true,
+	}
+	r.render(result, event, true)
+	return result
+}
+
+func (r *Rule) render(result *enginemodels.RuleResult, event map[string]interface{}, batchMode bool) {
+	var err error
+	if result.TitleOutput, err = r.renderField(r.title, event); err != nil {
+		if batchMode {
+			result.TitleOutput = r.ID
+		} else {
+			result.TitleError = err.Error()
+		}
+	}
+	if result.DescriptionOutput, err = r.renderField(r.description, event); err != nil && !batchMode {
+		result.DescriptionError = err.Error()
+	}
+	if result.ReferenceOutput, err = r.renderField(r.reference, event); err != nil && !batchMode {
+		result.ReferenceError = err.Error()
+	}
+	if result.RunbookOutput, err = r.renderField(r.runbook, event); err != nil && !batchMode {
+		result.RunbookError = err.Error()
+	}
+	if result.SeverityOutput, err = r.renderSeverity(event); err != nil {
+		if batchMode {
+			result.SeverityOutput = "INFO"
+		} else {
+			result.SeverityError = err.Error()
+		}
+	}
+	result.DestinationsOutput = r.destinations
+	if result.DedupOutput, err = r.renderDedup(event, result.TitleOutput); err != nil {
+		if batchMode {
+			result.DedupOutput = r.defaultDedupString()
+		} else {
+			result.DedupError = err.Error()
+		}
+	}
+	result.AlertContextOutput = r.renderAlertContext(event)
+
+	result.Errored = result.TitleError != "" || result.DescriptionError != "" || result.ReferenceError != "" ||
+		result.RunbookError != "" || result.SeverityError != "" || result.DedupError != ""
+}
+
+func (r *Rule) defaultDedupString() string {
+	return "defaultDedupString:" + r.ID
+}
+
+func (r *Rule) renderField(t *template, event map[string]interface{}) (string, error) {
+	if t == nil {
+		return "", nil
+	}
+	s, err := t.Render(event)
+	if err != nil {
+		return "", err
+	}
+	return truncate(s, MaxGeneratedFieldSize), nil
+}
+
+func (r *Rule) renderSeverity(event map[string]interface{}) (string, error) {
+	if r.severity == nil {
+		return "", nil
+	}
+	s, err := r.severity.Render(event)
+	if err != nil {
+		return "", err
+	}
+	severity := strings.ToUpper(s)
+	if !severityTypes[severity] {
+		return "", errors.Errorf("Expected severity to be any of INFO, LOW, MEDIUM, HIGH, CRITICAL, got [%s] instead", s)
+	}
+	return severity, nil
+}
+
+// Without a dedup template, the title is used as the dedup string
+func (r *Rule) renderDedup(event map[string]interface{}, title string) (string, error) {
+	if r.dedup == nil {
+		if title != "" {
+			return title, nil
+		}
+		return r.defaultDedupString(), nil
+	}
+	dedup, err := r.dedup.Render(event)
+	if err != nil {
+		return "", err
+	}
+	if dedup == "" {
+		return r.defaultDedupString(), nil
+	}
+	return truncate(dedup, MaxDedupStringSize), nil
+}
+
+// Missing alert context fields are set to null
+func (r *Rule) renderAlertContext(event map[string]interface{}) string {
+	if len(r.alertContext) == 0 {
+		return ""
+	}
+	stream := jsoniter.ConfigDefault.BorrowStream(nil)
+	defer jsoniter.ConfigDefault.ReturnStream(stream)
+	stream.WriteObjectStart()
+	for i, field := range r.alertContext {
+		if i > 0 {
+			stream.WriteMore()
+		}
+		value, _ := field.path.Lookup(event)
+		stream.WriteObjectField(field.key)
+		stream.WriteVal(value)
+	}
+	stream.WriteObjectEnd()
+	if stream.Error != nil {
+		return alertContextError(stream.Error.Error())
+	}
+	if n := len(stream.Buffer()); n > MaxAlertContextSize {
+		return alertContextError(errors.Errorf("alert_context size is [%d] characters, bigger than maximum of [%d] characters",
+			n, MaxAlertContextSize).Error())
+	}
+	return string(stream.Buffer())
+}
+
+func alertContextError(msg string) string {
+	s, _ := jsoniter.MarshalToString(map[string]string{alertContextErrorKey: msg})
+	return s
+}
+
+func truncate(s string, size int) string {
+	if len(s) <= size {
+		return s
+	}
+	return TruncateUTF8(s, size-len(truncatedStringSuffix)) + truncatedStringSuffix
+}
+
+// TruncateUTF8 returns the longest prefix of s which is at most size bytes, without splitting a multi-byte character.
+func TruncateUTF8(s string, size int) string {
+	if len(s) <= size {
+		return s
+	}
+	for size > 0 && !utf8.RuneStart(s[size]) {
+		size--
+	}
+	return s[:size]
+}
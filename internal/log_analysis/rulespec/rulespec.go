@@ -0,0 +1,103 @@
+package rulespec
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package rulespec compiles declarative YAML rule specs into matchers evaluated natively over parsed log events.
+//
+// A spec is an alternative to a Python rule body for simple field checks:
+//
+//   match:
+//     all:
+//       - field: eventName
+//         equals: ConsoleLogin
+//       - field: responseElements.ConsoleLogin
+//         equals: Failure
+//       - not:
+//           field: sourceIPAddress
+//           cidr: [10.0.0.0/8]
+//   title: "Failed console login by {userIdentity.arn}"
+//   dedup: "{userIdentity.arn}"
+//   alertContext:
+//     ip: sourceIPAddress
+//
+// The generated fields (title, dedup, severity etc) follow the same rules as the
+// equivalent Python rule functions so that alerts from both kinds of rules are indistinguishable.
+//
+// Instead of match, a spec can define a correlation across events of several log types (see CorrelationSpec).
+import (
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// MaxDedupStringSize is the maximum size for a dedup string
+	MaxDedupStringSize = 1000
+	// MaxGeneratedFieldSize is the maximum size for a generated field (title, description etc)
+	MaxGeneratedFieldSize = 1000
+	// MaxAlertContextSize is the maximum size of the serialized alert context
+	MaxAlertContextSize = 200 * 1024
+
+	truncatedStringSuffix = "... (truncated)"
+	alertContextErrorKey  = "_error"
+)
+
+// Spec is the YAML document describing a declarative rule.
+type Spec struct {
+	// Exactly one of match and correlation is required
+	Match       *Condition       `yaml:"match,omitempty"`
+	Correlation *CorrelationSpec `yaml:"correlation,omitempty"`
+	// Templates for the generated alert fields. Placeholders in braces, e.g. "{userIdentity.arn}", are replaced
+	// by the value of the event field at that path.
+	Title       string `yaml:"title,omitempty"`
+	Dedup       string `yaml:"dedup,omitempty"`
+	Description string `yaml:"description,omitempty"`
+	Reference   string `yaml:"reference,omitempty"`
+	Runbook     string `yaml:"runbook,omitempty"`
+	Severity    string `yaml:"severity,omitempty"`
+	// Output IDs overriding the destinations of the rule
+	Destinations []string `yaml:"destinations,omitempty"`
+	// Maps alert context keys to event field paths
+	AlertContext map[string]string `yaml:"alertContext,omitempty"`
+}
+
+// Condition is a node in the match expression.
+//
+// A condition is either a boolean combinator (all/any/not) or a field check with exactly one operator.
+type Condition struct {
+	All []Condition `yaml:"all,omitempty"`
+	Any []Condition `yaml:"any,omitempty"`
+	Not *Condition  `yaml:"not,omitempty"`
+
+	Field    string        `yaml:"field,omitempty"`
+	Equals   interface{}   `yaml:"equals,omitempty"`
+	In       []interface{} `yaml:"in,omitempty"`
+	Contains string        `yaml:"contains,omitempty"`
+	Regex    string        `yaml:"regex,omitempty"`
+	CIDR     []string      `yaml:"cidr,omitempty"`
+	Exists   *bool         `yaml:"exists,omitempty"`
+}
+
+// Parse decodes a YAML spec and compiles it to a rule.
+func Parse(ruleID, spec string) (*Rule, error) {
+	var s Spec
+	if err := yaml.UnmarshalStrict([]byte(spec), &s); err != nil {
+		return nil, errors.Wrap(err, "invalid rule spec")
+	}
+	return Compile(ruleID, &s)
+}
@@ -0,0 +1,282 @@
+package rulespec
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSpec = `
+match:
+  all:
+    - field: eventName
+      equals: ConsoleLogin
+    - field: responseElements.ConsoleLogin
+      in: [Failure, Error]
+    - any:
+        - field: userIdentity.type
+          regex: "^(Root|IAMUser)$"
+        - field: p_any_ip_addresses
+          cidr: [10.0.0.0/8]
+    - not:
+        field: userIdentity.arn
+        contains: ":assumed-role/"
+    - field: errorCode
+      exists: false
+title: "Failed console login by {userIdentity.arn}"
+dedup: "{userIdentity.arn}"
+severity: "{p_severity}"
+alertContext:
+  ip: sourceIPAddress
+  mfa: additionalEventData.MFAUsed
+`
+
+func mustEvent(t *testing.T, s string) map[string]interface{} {
+	t.Helper()
+	var event map[string]interface{}
+	require.NoError(t, jsoniter.UnmarshalFromString(s, &event))
+	return event
+}
+
+func TestRuleMatch(t *testing.T) {
+	rule, err := Parse("Test.Rule", testSpec)
+	require.NoError(t, err)
+
+	matching := mustEvent(t, `{
+"eventName": "ConsoleLogin",
+"responseElements": {"ConsoleLogin": "Failure"},
+"userIdentity": {"type": "IAMUser", "arn": "arn:aws:iam::123456789012:user/alice"},
+"sourceIPAddress": "1.2.3.4",
+"p_severity": "high"
+}`)
+	result := rule.Run(matching, true)
+	assert.True(t, result.RuleOutput)
+	assert.False(t, result.Errored)
+	assert.Equal(t, "Failed console login by arn:aws:iam::123456789012:user/alice", result.TitleOutput)
+	assert.Equal(t, "arn:aws:iam::123456789012:user/alice", result.DedupOutput)
+	assert.Equal(t, "HIGH", result.SeverityOutput)
+	assert.Equal(t, `{"ip":"1.2.3.4","mfa":null}`, result.AlertContextOutput)
+
+	// Matched by CIDR on a list field
+	assert.True(t, rule.Match(mustEvent(t, `{
+"eventName": "ConsoleLogin",
+"responseElements": {"ConsoleLogin": "Error"},
+"userIdentity": {"type": "AssumedRole", "arn": "arn:aws:iam::123456789012:user/bob"},
+"p_any_ip_addresses": ["1.1.1.1", "10.1.2.3"]
+}`)))
+	// Excluded by 'not'
+	assert.False(t, rule.Match(mustEvent(t, `{
+"eventName": "ConsoleLogin",
+"responseElements": {"ConsoleLogin": "Failure"},
+"userIdentity": {"type": "Root", "arn": "arn:aws:sts::123456789012:assumed-role/admin/bob"}
+}`)))
+	// Excluded by 'exists: false'
+	assert.False(t, rule.Match(mustEvent(t, `{
+"eventName": "ConsoleLogin",
+"responseElements": {"ConsoleLogin": "Failure"},
+"userIdentity": {"type": "Root", "arn": "arn:aws:iam::123456789012:root"},
+"errorCode": "AccessDenied"
+}`)))
+
+	// Not matching events skip the generated fields in batch mode
+	result = rule.Run(mustEvent(t, `{"eventName": "GetObject"}`), true)
+	assert.False(t, result.RuleOutput)
+	assert.Empty(t, result.TitleOutput)
+}
+
+func TestRuleGeneratedFieldErrors(t *testing.T) {
+	rule, err := Parse("Test.Rule", `
+match:
+  field: count
+  equals: 3
+title: "{user.name} did it"
+severity: "{level}"
+`)
+	require.NoError(t, err)
+	event := mustEvent(t, `{"count": 3}`)
+
+	// In test mode errors are reported
+	result := rule.Run(event, false)
+	assert.True(t, result.RuleOutput)
+	assert.True(t, result.Errored)
+	assert.Equal(t, `KeyError: field "user.name" not found`, result.TitleError)
+	assert.NotEmpty(t, result.SeverityError)
+	assert.Equal(t, "defaultDedupString:Test.Rule", result.DedupOutput)
+
+	// In batch mode errors fall back to defaults
+	result = rule.Run(event, true)
+	assert.False(t, result.Errored)
+	assert.Equal(t, "Test.Rule", result.TitleOutput)
+	assert.Equal(t, "INFO", result.SeverityOutput)
+	assert.Equal(t, "Test.Rule", result.DedupOutput)
+}
+
+func TestTemplate(t *testing.T) {
+	tpl, err := compileTemplate("{{literal}} {a.b} / {list.1} {n}")
+	require.NoError(t, err)
+	s, err := tpl.Render(mustEvent(t, `{"a": {"b": true}, "list": ["x", "y"], "n": 4.5}`))
+	require.NoError(t, err)
+	assert.Equal(t, "{literal} true / y 4.5", s)
+
+	_, err = compileTemplate("{unterminated")
+	assert.Error(t, err)
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", truncate("short", 10))
+	assert.Equal(t, "abcde"+truncatedStringSuffix, truncate("abcdefghijklmnopqrstuvwxyz", 5+len(truncatedStringSuffix)))
+	// "é" takes 2 bytes and is not split
+	assert.Equal(t, "ab"+truncatedStringSuffix, truncate("abéééééééééééééééé", 3+len(truncatedStringSuffix)))
+	assert.Equal(t, "ab", TruncateUTF8("abé", 3))
+	assert.Equal(t, "abé", TruncateUTF8("abé", 4))
+	assert.Equal(t, "", TruncateUTF8("日本", 2))
+}
+
+func TestParseErrors(t *testing.T) {
+	for name, spec := range map[string]string{
+		"unknown key":          "match: {field: a, equals: b}\ntitel: x",
+		"no operator":          "match: {field: a}",
+		"two operators":        "match: {field: a, equals: b, regex: c}",
+		"field and combinator": "match: {field: a, equals: b, any: [{field: c, exists: true}]}",
+		"empty all":            "match: {all: []}",
+		"bad regex":            "match: {field: a, regex: '('}",
+		"bad cidr":             "match: {field: a, cidr: [10.0.0.0]}",
+		"non scalar equals":    "match: {field: a, equals: [b]}",
+		"bad severity":         "match: {field: a, exists: true}\nseverity: SEVERE",
+		"bad path":             "match: {field: a..b, exists: true}",
+		"no match":             "title: x",
+		"match and correlation": `match: {field: a, exists: true}
+correlation: {windowMinutes: 5, steps: [{name: a, logType: A, match: {field: a, exists: true}, key: a}]}`,
+		"single step":   "correlation: {windowMinutes: 5, steps: [{name: a, logType: A, match: {field: a, exists: true}, key: a}]}",
+		"no window":     "correlation: {steps: [{name: a, logType: A, match: {field: a, exists: true}, key: a}, {name: b, logType: B, match: {field: b, exists: true}, key: b}]}",
+		"same step":     "correlation: {windowMinutes: 5, steps: [{name: a, logType: A, match: {field: a, exists: true}, key: a}, {name: a, logType: B, match: {field: b, exists: true}, key: b}]}",
+		"reserved step": "correlation: {windowMinutes: 5, steps: [{name: key, logType: A, match: {field: a, exists: true}, key: a}, {name: b, logType: B, match: {field: b, exists: true}, key: b}]}",
+	} {
+		_, err := Parse("Test.Rule", spec)
+		assert.Error(t, err, name)
+	}
+}
+
+const testCorrelationSpec = `
+correlation:
+  windowMinutes: 15
+  sequence: true
+  steps:
+    - name: mfa_reset
+      logType: Okta.SystemLog
+      match:
+        field: eventType
+        equals: user.mfa.factor.reset_all
+      key: target.0.alternateId
+    - name: console_login
+      logType: AWS.CloudTrail
+      match:
+        field: eventName
+        equals: ConsoleLogin
+      key: userIdentity.userName
+title: "MFA reset followed by console login for {key} from {console_login.sourceIPAddress}"
+`
+
+func TestCorrelation(t *testing.T) {
+	rule, err := Parse("Test.Correlation", testCorrelationSpec)
+	require.NoError(t, err)
+	correlation := rule.Correlation()
+	require.NotNil(t, correlation)
+	assert.Equal(t, []string{"AWS.CloudTrail", "Okta.SystemLog"}, correlation.LogTypes())
+
+	login := mustEvent(t, `{"eventName": "ConsoleLogin", "userIdentity": {"userName": "Alice@example.com"}, "sourceIPAddress": "1.2.3.4"}`)
+	assert.False(t, rule.Match(login), "correlation rules do not match single events")
+	key, ok := correlation.Steps[1].Match(login)
+	assert.True(t, ok)
+	assert.Equal(t, "alice@example.com", key)
+	_, ok = correlation.Steps[0].Match(login)
+	assert.False(t, ok)
+
+	start := time.Date(2020, 11, 2, 10, 0, 0, 0, time.UTC)
+	reset := CorrelatedEvent{Step: "mfa_reset", Time: start, Event: map[string]interface{}{}}
+	inTime := CorrelatedEvent{Step: "console_login", Time: start.Add(10 * time.Minute), Event: login}
+	late := CorrelatedEvent{Step: "console_login", Time: start.Add(20 * time.Minute), Event: login}
+	before := CorrelatedEvent{Step: "console_login", Time: start.Add(-time.Minute), Event: login}
+
+	assert.Equal(t, []CorrelatedEvent{reset, inTime}, correlation.Find([]CorrelatedEvent{reset, inTime}))
+	assert.Nil(t, correlation.Find([]CorrelatedEvent{reset, late}))
+	assert.Nil(t, correlation.Find([]CorrelatedEvent{before, reset}))
+	// The earliest event of each step is picked
+	again := CorrelatedEvent{Step: "console_login", Time: start.Add(12 * time.Minute), Event: login}
+	assert.Equal(t, []CorrelatedEvent{reset, inTime}, correlation.Find([]CorrelatedEvent{reset, inTime, again}))
+	// Without a sequence the order does not matter
+	correlation.Sequence = false
+	assert.Equal(t, []CorrelatedEvent{reset, before}, correlation.Find([]CorrelatedEvent{before, reset}))
+	assert.Equal(t, []CorrelatedEvent{reset, inTime}, correlation.Find([]CorrelatedEvent{reset, inTime, again}))
+
+	result := rule.RunCorrelated(correlation.Event("alice@example.com", []CorrelatedEvent{reset, inTime}))
+	assert.True(t, result.RuleOutput)
+	assert.Equal(t, "MFA reset followed by console login for alice@example.com from 1.2.3.4", result.TitleOutput)
+	assert.Equal(t, result.TitleOutput, result.DedupOutput)
+}
+
+func TestException(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	event := mustEvent(t, `{
+"userIdentity": {"arn": "arn:aws:iam::123456789012:user/scanner"},
+"p_any_ip_addresses": ["1.1.1.1", "10.1.2.3"],
+"readOnly": true,
+"count": 3
+}`)
+
+	newException := func(field, operator string, values ...string) *Exception {
+		e, err := NewException("id", field, operator, values, nil)
+		require.NoError(t, err)
+		return e
+	}
+	assert.True(t, newException("userIdentity.arn", ExceptionEquals, "other", "arn:aws:iam::123456789012:user/scanner").Match(event, now))
+	assert.True(t, newException("readOnly", ExceptionEquals, "true").Match(event, now))
+	assert.True(t, newException("count", ExceptionEquals, "3").Match(event, now))
+	assert.False(t, newException("userIdentity", ExceptionEquals, "").Match(event, now))
+	assert.True(t, newException("userIdentity.arn", ExceptionContains, ":user/scan").Match(event, now))
+	assert.False(t, newException("count", ExceptionContains, "3").Match(event, now))
+	assert.True(t, newException("userIdentity.arn", ExceptionRegex, `user/scan.*$`).Match(event, now))
+	assert.True(t, newException("p_any_ip_addresses", ExceptionCIDR, "10.0.0.0/8").Match(event, now))
+	assert.False(t, newException("p_any_ip_addresses", ExceptionCIDR, "192.168.0.0/16").Match(event, now))
+	assert.False(t, newException("missing", ExceptionEquals, "").Match(event, now))
+
+	expiresAt := now.Add(time.Hour)
+	expiring, err := NewException("expiring", "readOnly", ExceptionEquals, []string{"true"}, &expiresAt)
+	require.NoError(t, err)
+	assert.True(t, expiring.Match(event, now))
+	assert.False(t, expiring.Match(event, expiresAt))
+	assert.Nil(t, MatchException([]*Exception{expiring}, event, expiresAt))
+	assert.Equal(t, expiring, MatchException([]*Exception{newException("count", ExceptionEquals, "4"), expiring}, event, now))
+
+	_, err = NewException("id", "a..b", ExceptionEquals, []string{"x"}, nil)
+	assert.Error(t, err)
+	_, err = NewException("id", "a", "startswith", []string{"x"}, nil)
+	assert.Error(t, err)
+	_, err = NewException("id", "a", ExceptionRegex, []string{"("}, nil)
+	assert.Error(t, err)
+	_, err = NewException("id", "a", ExceptionCIDR, []string{"10.0.0.1"}, nil)
+	assert.Error(t, err)
+	_, err = NewException("id", "a", ExceptionEquals, nil, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,88 @@
+package rulespec
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// template is a string with `{field.path}` placeholders.
+// A literal brace can be written as `{{` or `}}`.
+type template struct {
+	literals []string
+	fields   []fieldPath
+}
+
+func compileTemplate(tpl string) (*template, error) {
+	if tpl == "" {
+		return nil, nil
+	}
+	t := &template{}
+	var literal strings.Builder
+	for i := 0; i < len(tpl); i++ {
+		c := tpl[i]
+		switch c {
+		case '{':
+			if i+1 < len(tpl) && tpl[i+1] == '{' {
+				literal.WriteByte('{')
+				i++
+				continue
+			}
+			end := strings.IndexByte(tpl[i:], '}')
+			if end == -1 {
+				return nil, errors.Errorf("unterminated placeholder in %q", tpl)
+			}
+			path, err := parseFieldPath(strings.TrimSpace(tpl[i+1 : i+end]))
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid placeholder in %q", tpl)
+			}
+			t.literals = append(t.literals, literal.String())
+			t.fields = append(t.fields, path)
+			literal.Reset()
+			i += end
+		case '}':
+			if i+1 < len(tpl) && tpl[i+1] == '}' {
+				i++
+			}
+			literal.WriteByte('}')
+		default:
+			literal.WriteByte(c)
+		}
+	}
+	t.literals = append(t.literals, literal.String())
+	return t, nil
+}
+
+// Render fails if a placeholder field is missing from the event, the way a Python rule
+// function raises a KeyError.
+func (t *template) Render(event map[string]interface{}) (string, error) {
+	var b strings.Builder
+	for i, path := range t.fields {
+		b.WriteString(t.literals[i])
+		value, ok := path.Lookup(event)
+		if !ok {
+			return "", errors.Errorf("KeyError: field %q not found", path.String())
+		}
+		b.WriteString(formatValue(value))
+	}
+	b.WriteString(t.literals[len(t.literals)-1])
+	return b.String(), nil
+}
@@ -0,0 +1,76 @@
+package scheduledrules
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/api/lambda/analysis/models"
+)
+
+const (
+	// Macros expanded in the SQL of scheduled queries to the bounds of the lookback window
+	StartTimeMacro = "{{start_time}}"
+	EndTimeMacro   = "{{end_time}}"
+
+	DefaultLookbackMinutes = 60
+	DefaultResultLimit     = 100
+	// Athena returns at most 1000 rows in a page of results
+	MaxResultLimit = 1000
+
+	athenaTimestampLayout = "2006-01-02 15:04:05.000"
+)
+
+// Validate checks a scheduled query before it is saved
+func Validate(query *models.ScheduledQuery) error {
+	if _, err := ParseSchedule(query.Schedule); err != nil {
+		return err
+	}
+	if query.ResultLimit > MaxResultLimit {
+		return errors.Errorf("result limit cannot exceed %d", MaxResultLimit)
+	}
+	if strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(query.SQL), ";")) == "" {
+		return errors.New("empty SQL query")
+	}
+	return nil
+}
+
+// BuildSQL expands the lookback window macros and limits the number of result rows.
+// The lookback window ends at the scheduled run time.
+func BuildSQL(query *models.ScheduledQuery, runTime time.Time) string {
+	lookback := query.LookbackMinutes
+	if lookback == 0 {
+		lookback = DefaultLookbackMinutes
+	}
+	end := runTime.UTC().Truncate(time.Minute)
+	start := end.Add(-time.Duration(lookback) * time.Minute)
+
+	sql := strings.TrimSuffix(strings.TrimSpace(query.SQL), ";")
+	sql = strings.ReplaceAll(sql, StartTimeMacro, athenaTimestamp(start))
+	sql = strings.ReplaceAll(sql, EndTimeMacro, athenaTimestamp(end))
+	return fmt.Sprintf("SELECT * FROM (\n%s\n) LIMIT %d", sql, resultLimit(query))
+}
+
+func athenaTimestamp(t time.Time) string {
+	return "TIMESTAMP '" + t.Format(athenaTimestampLayout) + "'"
+}
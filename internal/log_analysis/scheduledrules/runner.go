@@ -0,0 +1,250 @@
+package scheduledrules
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/aws/aws-sdk-go/service/athena/athenaiface"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/analysis/models"
+	"github.com/panther-labs/panther/internal/log_analysis/rulespec"
+	"github.com/panther-labs/panther/internal/log_analysis/rulespec/engine"
+	"github.com/panther-labs/panther/pkg/awsathena"
+	"github.com/panther-labs/panther/pkg/gatewayapi"
+)
+
+const (
+	listRulesPageSize             = 250
+	defaultRuleDedupPeriodMinutes = 60
+)
+
+// Runner runs the scheduled rules that are due and merges their result rows into the alert dedup table.
+// From there the alert forwarder creates and delivers alerts exactly like it does for streaming rules.
+type Runner struct {
+	AnalysisClient gatewayapi.API
+	AthenaClient   athenaiface.AthenaAPI
+	Merger         *engine.AlertMerger
+	Workgroup      string
+	Database       string
+}
+
+// Stats reports the work done for a scheduled run
+type Stats struct {
+	Rules  int
+	Rows   int
+	Failed int
+}
+
+type queryRun struct {
+	rule    *models.Detection
+	queryID string
+}
+
+// Run executes all enabled scheduled rules whose schedule matches the minute of runTime.
+// A failing rule does not prevent the others from running; all errors are returned together.
+func (r *Runner) Run(runTime time.Time) (*Stats, error) {
+	runTime = runTime.UTC().Truncate(time.Minute)
+	stats := &Stats{}
+	rules, err := r.dueRules(runTime)
+	if err != nil {
+		return stats, err
+	}
+
+	// Start all queries first so that Athena runs them concurrently
+	var runs []queryRun
+	var errs error
+	for _, rule := range rules {
+		sql := BuildSQL(rule.ScheduledQuery, runTime)
+		output, err := awsathena.StartQuery(r.AthenaClient, r.Workgroup, r.Database, sql)
+		if err != nil {
+			stats.Failed++
+			errs = multierr.Append(errs, errors.Wrapf(err, "failed to start query for rule %s", rule.ID))
+			continue
+		}
+		runs = append(runs, queryRun{rule: rule, queryID: aws.StringValue(output.QueryExecutionId)})
+	}
+
+	for _, run := range runs {
+		rows, err := r.results(run.queryID, resultLimit(run.rule.ScheduledQuery))
+		if err == nil {
+			err = r.merge(run.rule, rows)
+		}
+		if err != nil {
+			stats.Failed++
+			errs = multierr.Append(errs, errors.Wrapf(err, "scheduled rule %s failed", run.rule.ID))
+			continue
+		}
+		stats.Rules++
+		stats.Rows += len(rows)
+	}
+	return stats, errs
+}
+
+// dueRules lists the enabled scheduled rules whose schedule matches runTime
+func (r *Runner) dueRules(runTime time.Time) ([]*models.Detection, error) {
+	input := models.LambdaInput{
+		ListDetections: &models.ListDetectionsInput{
+			AnalysisTypes: []models.DetectionType{models.TypeScheduledRule},
+			Enabled:       aws.Bool(true),
+			Page:          1,
+			PageSize:      listRulesPageSize,
+		},
+	}
+	var result []*models.Detection
+	for {
+		var output models.ListDetectionsOutput
+		statusCode, err := r.AnalysisClient.Invoke(&input, &output)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list scheduled rules")
+		}
+		if statusCode != http.StatusOK {
+			return nil, errors.Errorf("failed to list scheduled rules: status code %d", statusCode)
+		}
+		for i := range output.Detections {
+			rule := &output.Detections[i]
+			if rule.ScheduledQuery == nil {
+				continue
+			}
+			schedule, err := ParseSchedule(rule.ScheduledQuery.Schedule)
+			if err != nil {
+				zap.L().Error("invalid rule schedule", zap.String("ruleId", rule.ID), zap.Error(err))
+				continue
+			}
+			if schedule.Matches(runTime) {
+				result = append(result, rule)
+			}
+		}
+		if output.Paging.ThisPage >= output.Paging.TotalPages {
+			break
+		}
+		input.ListDetections.Page++
+	}
+	return result, nil
+}
+
+// results waits for a query to finish and reads up to limit rows, keyed by column name
+func (r *Runner) results(queryID string, limit int) ([]map[string]string, error) {
+	output, err := awsathena.WaitForResults(r.AthenaClient, queryID)
+	if err != nil {
+		return nil, err
+	}
+	var columns []string
+	for _, column := range output.ResultSet.ResultSetMetadata.ColumnInfo {
+		columns = append(columns, aws.StringValue(column.Name))
+	}
+	var rows []map[string]string
+	// The first row of the first page holds the column names
+	skip := 1
+	for {
+		for _, row := range output.ResultSet.Rows[min(skip, len(output.ResultSet.Rows)):] {
+			if len(rows) == limit {
+				return rows, nil
+			}
+			rows = append(rows, rowValues(columns, row))
+		}
+		if output.NextToken == nil || len(rows) == limit {
+			return rows, nil
+		}
+		skip = 0
+		if output, err = awsathena.Results(r.AthenaClient, queryID, output.NextToken, nil); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func rowValues(columns []string, row *athena.Row) map[string]string {
+	values := make(map[string]string, len(columns))
+	for i, datum := range row.Data {
+		if i < len(columns) && datum.VarCharValue != nil {
+			values[columns[i]] = *datum.VarCharValue
+		}
+	}
+	return values
+}
+
+// merge groups the rows by dedup string and updates the alert dedup table.
+// Each row counts as one event of the alert and the first row of a group becomes its alert context.
+func (r *Runner) merge(rule *models.Detection, rows []map[string]string) error {
+	dedupPeriod := rule.DedupPeriodMinutes
+	if dedupPeriod == 0 {
+		dedupPeriod = defaultRuleDedupPeriodMinutes
+	}
+	now := time.Now().UTC()
+	var groups []*engine.MatchGroup
+	byDedup := make(map[string]*engine.MatchGroup)
+	for _, row := range rows {
+		dedup := dedupString(rule, row)
+		if group, ok := byDedup[dedup]; ok {
+			group.NumMatches++
+			continue
+		}
+		group := &engine.MatchGroup{
+			RuleID:             rule.ID,
+			RuleVersion:        rule.VersionID,
+			LogTypes:           rule.LogTypes,
+			Dedup:              dedup,
+			DedupPeriodMinutes: dedupPeriod,
+			NumMatches:         1,
+			ProcessingTime:     now,
+			// The rows are only kept as the alert context
+			NoStoredEvents: true,
+		}
+		if context, err := jsoniter.MarshalToString(row); err == nil && len(context) <= rulespec.MaxAlertContextSize {
+			group.AlertContext = context
+		}
+		byDedup[dedup] = group
+		groups = append(groups, group)
+	}
+	for _, group := range groups {
+		if _, err := r.Merger.UpdateAlertInfo(group); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func dedupString(rule *models.Detection, row map[string]string) string {
+	dedup := row[rule.ScheduledQuery.DedupColumn]
+	if dedup == "" {
+		return "defaultDedupString:" + rule.ID
+	}
+	return rulespec.TruncateUTF8(dedup, rulespec.MaxDedupStringSize)
+}
+
+func resultLimit(query *models.ScheduledQuery) int {
+	if query.ResultLimit == 0 {
+		return DefaultResultLimit
+	}
+	return query.ResultLimit
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,218 @@
+package scheduledrules
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/analysis/models"
+	"github.com/panther-labs/panther/internal/log_analysis/rulespec"
+	"github.com/panther-labs/panther/internal/log_analysis/rulespec/engine"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+func TestSchedule(t *testing.T) {
+	at := func(value string) time.Time {
+		result, err := time.Parse(time.RFC3339, value)
+		require.NoError(t, err)
+		return result
+	}
+	testCases := []struct {
+		expr    string
+		matches []string
+		misses  []string
+	}{
+		{"* * * * *", []string{"2020-11-02T10:17:00Z"}, nil},
+		{"*/15 * * * *", []string{"2020-11-02T10:00:00Z", "2020-11-02T10:45:59Z"}, []string{"2020-11-02T10:17:00Z"}},
+		{"5,35 9-17 * * *", []string{"2020-11-02T09:05:00Z", "2020-11-02T17:35:00Z"}, []string{"2020-11-02T18:05:00Z"}},
+		{"0 0 1 * *", []string{"2020-12-01T00:00:00Z"}, []string{"2020-12-02T00:00:00Z"}},
+		// Monday to Friday, Sunday as 7
+		{"0 8 * * 1-5", []string{"2020-11-02T08:00:00Z"}, []string{"2020-11-01T08:00:00Z"}},
+		{"0 8 * * 7", []string{"2020-11-01T08:00:00Z"}, []string{"2020-11-02T08:00:00Z"}},
+		// Restricted day of month and day of week match either
+		{"0 0 15 * 1", []string{"2020-11-15T00:00:00Z", "2020-11-02T00:00:00Z"}, []string{"2020-11-03T00:00:00Z"}},
+		// Schedules are evaluated in UTC
+		{"0 10 * * *", []string{"2020-11-02T12:00:00+02:00"}, []string{"2020-11-02T10:00:00+02:00"}},
+	}
+	for _, tc := range testCases {
+		schedule, err := ParseSchedule(tc.expr)
+		require.NoError(t, err, tc.expr)
+		for _, value := range tc.matches {
+			assert.True(t, schedule.Matches(at(value)), "%s should match %s", tc.expr, value)
+		}
+		for _, value := range tc.misses {
+			assert.False(t, schedule.Matches(at(value)), "%s should not match %s", tc.expr, value)
+		}
+	}
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "a * * * *", "5-1 * * * *"} {
+		_, err := ParseSchedule(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestBuildSQL(t *testing.T) {
+	query := &models.ScheduledQuery{
+		SQL: `SELECT sourceIPAddress, count(distinct userIdentity.arn) AS users
+FROM panther_logs.aws_cloudtrail
+WHERE p_event_time BETWEEN {{start_time}} AND {{end_time}}
+GROUP BY 1 HAVING count(distinct userIdentity.arn) > 50;`,
+		Schedule: "0 * * * *",
+	}
+	runTime := time.Date(2020, 11, 2, 10, 0, 30, 0, time.UTC)
+	expected := `SELECT * FROM (
+SELECT sourceIPAddress, count(distinct userIdentity.arn) AS users
+FROM panther_logs.aws_cloudtrail
+WHERE p_event_time BETWEEN TIMESTAMP '2020-11-02 09:00:00.000' AND TIMESTAMP '2020-11-02 10:00:00.000'
+GROUP BY 1 HAVING count(distinct userIdentity.arn) > 50
+) LIMIT 100`
+	assert.Equal(t, expected, BuildSQL(query, runTime))
+
+	query.LookbackMinutes = 1440
+	query.ResultLimit = 5
+	assert.Contains(t, BuildSQL(query, runTime), "TIMESTAMP '2020-11-01 10:00:00.000' AND TIMESTAMP '2020-11-02 10:00:00.000'")
+	assert.Contains(t, BuildSQL(query, runTime), ") LIMIT 5")
+
+	assert.NoError(t, Validate(query))
+	assert.Error(t, Validate(&models.ScheduledQuery{SQL: "SELECT 1", Schedule: "every hour"}))
+	assert.Error(t, Validate(&models.ScheduledQuery{SQL: " ; ", Schedule: "0 * * * *"}))
+}
+
+func resultRows(rows ...[]string) *athena.GetQueryResultsOutput {
+	output := &athena.GetQueryResultsOutput{
+		ResultSet: &athena.ResultSet{
+			ResultSetMetadata: &athena.ResultSetMetadata{},
+		},
+	}
+	for _, column := range rows[0] {
+		output.ResultSet.ResultSetMetadata.ColumnInfo = append(output.ResultSet.ResultSetMetadata.ColumnInfo,
+			&athena.ColumnInfo{Name: aws.String(column)})
+	}
+	for _, row := range rows {
+		var data []*athena.Datum
+		for _, value := range row {
+			data = append(data, &athena.Datum{VarCharValue: aws.String(value)})
+		}
+		output.ResultSet.Rows = append(output.ResultSet.Rows, &athena.Row{Data: data})
+	}
+	return output
+}
+
+func TestRunner(t *testing.T) {
+	analysisMock := &testutils.GatewayapiMock{}
+	analysisMock.On("Invoke", mock.Anything, mock.Anything).Return(200, nil).Run(func(args mock.Arguments) {
+		input := args.Get(0).(*models.LambdaInput)
+		assert.Equal(t, []models.DetectionType{models.TypeScheduledRule}, input.ListDetections.AnalysisTypes)
+		output := args.Get(1).(*models.ListDetectionsOutput)
+		*output = models.ListDetectionsOutput{
+			Paging: models.Paging{ThisPage: 1, TotalPages: 1},
+			Detections: []models.Detection{
+				{
+					ID:        "Brute.Force",
+					LogTypes:  []string{"AWS.CloudTrail"},
+					VersionID: "v1",
+					ScheduledQuery: &models.ScheduledQuery{
+						SQL:         "SELECT ip, users FROM failures",
+						Schedule:    "0 * * * *",
+						DedupColumn: "ip",
+					},
+				},
+				{
+					ID:             "Daily.Report",
+					LogTypes:       []string{"AWS.CloudTrail"},
+					ScheduledQuery: &models.ScheduledQuery{SQL: "SELECT 1", Schedule: "0 0 * * *"},
+				},
+			},
+		}
+	}).Once()
+
+	athenaMock := &testutils.AthenaMock{}
+	athenaMock.On("StartQueryExecution", mock.Anything).Return(&athena.StartQueryExecutionOutput{
+		QueryExecutionId: aws.String("query-1"),
+	}, nil).Once()
+	athenaMock.On("GetQueryExecution", mock.Anything).Return(&athena.GetQueryExecutionOutput{
+		QueryExecution: &athena.QueryExecution{
+			QueryExecutionId: aws.String("query-1"),
+			Status:           &athena.QueryExecutionStatus{State: aws.String(athena.QueryExecutionStateSucceeded)},
+		},
+	}, nil).Once()
+	athenaMock.On("GetQueryResults", mock.Anything).Return(resultRows(
+		[]string{"ip", "users"},
+		[]string{"1.2.3.4", "51"},
+		[]string{"5.6.7.8", "60"},
+		[]string{"1.2.3.4", "55"},
+	), nil).Once()
+
+	dynamoMock := &testutils.DynamoDBMock{}
+	dynamoMock.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{
+		Attributes: map[string]*dynamodb.AttributeValue{"alertCount": {N: aws.String("1")}},
+	}, nil).Times(2)
+
+	runner := &Runner{
+		AnalysisClient: analysisMock,
+		AthenaClient:   athenaMock,
+		Merger:         &engine.AlertMerger{DynamoDBClient: dynamoMock, TableName: "dedup"},
+		Workgroup:      "panther",
+		Database:       "panther_views",
+	}
+	stats, err := runner.Run(time.Date(2020, 11, 2, 10, 0, 12, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, &Stats{Rules: 1, Rows: 3}, stats)
+	analysisMock.AssertExpectations(t)
+	athenaMock.AssertExpectations(t)
+	dynamoMock.AssertExpectations(t)
+
+	startInput := athenaMock.Calls[0].Arguments.Get(0).(*athena.StartQueryExecutionInput)
+	assert.Equal(t, "SELECT * FROM (\nSELECT ip, users FROM failures\n) LIMIT 100", *startInput.QueryString)
+	assert.Equal(t, "panther", *startInput.WorkGroup)
+
+	// Rows are grouped by the dedup column
+	first := dynamoMock.Calls[0].Arguments.Get(0).(*dynamodb.UpdateItemInput)
+	assert.Equal(t, "1.2.3.4", *first.ExpressionAttributeValues[":5"].S)
+	assert.Equal(t, "2", *first.ExpressionAttributeValues[":8"].N)
+	// The rows are not written to S3
+	assert.Equal(t, "noStoredEvents", *first.ExpressionAttributeNames["#19"])
+	assert.True(t, *first.ExpressionAttributeValues[":19"].BOOL)
+	assert.Equal(t, []string{"AWS.CloudTrail"}, aws.StringValueSlice(first.ExpressionAttributeValues[":9"].SS))
+	var context map[string]string
+	require.NoError(t, jsoniter.UnmarshalFromString(*first.ExpressionAttributeValues[":12"].S, &context))
+	assert.Equal(t, map[string]string{"ip": "1.2.3.4", "users": "51"}, context)
+	second := dynamoMock.Calls[1].Arguments.Get(0).(*dynamodb.UpdateItemInput)
+	assert.Equal(t, "5.6.7.8", *second.ExpressionAttributeValues[":5"].S)
+	assert.Equal(t, "1", *second.ExpressionAttributeValues[":8"].N)
+}
+
+func TestDedupStringTruncated(t *testing.T) {
+	rule := &models.Detection{ScheduledQuery: &models.ScheduledQuery{DedupColumn: "user"}}
+	dedup := dedupString(rule, map[string]string{"user": "a" + strings.Repeat("é", rulespec.MaxDedupStringSize)})
+	// The last character that would be split in half is dropped
+	assert.Len(t, dedup, rulespec.MaxDedupStringSize-1)
+	assert.True(t, utf8.ValidString(dedup))
+}
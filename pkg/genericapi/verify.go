@@ -19,6 +19,7 @@ package genericapi
  */
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 )
@@ -27,6 +28,15 @@ import (
 //
 // This should be part of the unit tests for your Lambda function.
 func (r *Router) VerifyHandlers(lambdaInput interface{}) error {
+	return r.verifyHandlers(lambdaInput, false)
+}
+
+// VerifyHandlersWithContext is VerifyHandlers for handlers called by HandleWithContext.
+func (r *Router) VerifyHandlersWithContext(lambdaInput interface{}) error {
+	return r.verifyHandlers(lambdaInput, true)
+}
+
+func (r *Router) verifyHandlers(lambdaInput interface{}, withContext bool) error {
 	inputValue := reflect.Indirect(reflect.ValueOf(lambdaInput))
 	numFields := inputValue.NumField()
 
@@ -44,7 +54,7 @@ func (r *Router) VerifyHandlers(lambdaInput interface{}) error {
 			return &InternalError{Message: "func " + handlerName + " does not exist"}
 		}
 
-		err := verifySignature(handlerName, handler.Type(), inputValue.Field(i).Type())
+		err := verifySignature(handlerName, handler.Type(), inputValue.Field(i).Type(), withContext)
 		if err != nil {
 			return err
 		}
@@ -54,16 +64,26 @@ func (r *Router) VerifyHandlers(lambdaInput interface{}) error {
 }
 
 // verifySignature returns an error if the handler function signature is invalid.
-func verifySignature(name string, handler reflect.Type, input reflect.Type) error {
-	if handler.NumIn() != 1 {
+func verifySignature(name string, handler reflect.Type, input reflect.Type, withContext bool) error {
+	if !withContext && handler.NumIn() != 1 {
 		return &InternalError{Message: fmt.Sprintf(
 			"%s should have 1 argument, found %d", name, handler.NumIn())}
 	}
+	if withContext {
+		if handler.NumIn() != 2 {
+			return &InternalError{Message: fmt.Sprintf(
+				"%s should have 2 arguments, found %d", name, handler.NumIn())}
+		}
+		if contextInterface := reflect.TypeOf((*context.Context)(nil)).Elem(); handler.In(0) != contextInterface {
+			return &InternalError{Message: fmt.Sprintf(
+				"%s first argument is %s, expected context.Context", name, handler.In(0).String())}
+		}
+	}
 
-	if handler.In(0) != input {
+	if inputArg := handler.In(handler.NumIn() - 1); inputArg != input {
 		return &InternalError{Message: fmt.Sprintf(
 			"%s expects an argument of type %s, input has type %s",
-			name, handler.In(0).String(), input.String())}
+			name, inputArg.String(), input.String())}
 	}
 
 	errorInterface := reflect.TypeOf((*error)(nil)).Elem()
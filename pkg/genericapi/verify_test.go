@@ -19,6 +19,7 @@ package genericapi
  */
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -91,3 +92,17 @@ func TestVerifyNoReturns(t *testing.T) {
 func TestVerifyValid(t *testing.T) {
 	assert.Nil(t, testRouter.VerifyHandlers(&lambdaInput{}))
 }
+
+type withContext struct{}
+
+func (*withContext) AddRule(context.Context, *addRuleInput) error { return nil }
+
+func TestVerifyWithContext(t *testing.T) {
+	type input struct{ AddRule *addRuleInput }
+	router := NewRouter("testNamespace", "testComponent", nil, &withContext{})
+	assert.Nil(t, router.VerifyHandlersWithContext(&input{}))
+	assert.Equal(t, "AddRule should have 1 argument, found 2", router.VerifyHandlers(&input{}).(*InternalError).Message)
+
+	err := NewRouter("testNamespace", "testComponent", nil, &wrongReturnDouble{}).VerifyHandlersWithContext(&input{})
+	assert.Equal(t, "AddRule should have 2 arguments, found 1", err.(*InternalError).Message)
+}
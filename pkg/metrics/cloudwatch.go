@@ -34,6 +34,8 @@ type Manager interface {
 	Run(ctx context.Context, interval time.Duration)
 	// Returns a new Counter
 	NewCounter(name, unit string) Counter
+	// Returns a new Histogram
+	NewHistogram(name, unit string) Histogram
 	// Sync the metrics to the underlying system
 	Sync() error
 }
@@ -43,6 +45,8 @@ type CWEmbeddedMetricsManager struct {
 	mtx sync.Mutex
 	// Space that keeps track of the counters
 	counters *Space
+	// Space that keeps track of the histograms
+	histograms *Space
 	// The writer will the metrics will be written to
 	writer io.Writer
 	stream *jsoniter.Stream
@@ -57,9 +61,10 @@ type CWEmbeddedMetricsManager struct {
 // manually or with one of the helper methods.
 func NewCWEmbeddedMetrics(writer io.Writer) *CWEmbeddedMetricsManager {
 	cwManager := &CWEmbeddedMetricsManager{
-		writer:   writer,
-		counters: NewSpace(),
-		stream:   jsoniter.NewStream(jsoniter.ConfigDefault, nil, 8192),
+		writer:     writer,
+		counters:   NewSpace(),
+		histograms: NewSampledSpace(maxHistogramSamples),
+		stream:     jsoniter.NewStream(jsoniter.ConfigDefault, nil, 8192),
 		timeFunc: func() int64 {
 			return time.Now().UnixNano() / 1e6
 		},
@@ -77,6 +82,16 @@ func (c *CWEmbeddedMetricsManager) NewCounter(name, unit string) Counter {
 	}
 }
 
+// NewHistogram returns a histogram. A sample of at most 100 observations per time series
+// is emitted once per Sync invocation.
+func (c *CWEmbeddedMetricsManager) NewHistogram(name, unit string) Histogram {
+	return &DimensionsHistogram{
+		name: name,
+		unit: unit,
+		obs:  c.histograms.Observe,
+	}
+}
+
 func (c *CWEmbeddedMetricsManager) Run(ctx context.Context, interval time.Duration) {
 	ticker := time.NewTicker(interval)
 	for {
@@ -118,39 +133,11 @@ func (c *CWEmbeddedMetricsManager) sync() ([]byte, error) {
 	timeNow := c.timeFunc()
 
 	c.counters.Reset().Walk(func(name, unit string, dms DimensionValues, value float64, observations int64) bool {
-		c.stream.WriteObjectStart()
-
-		// Write `"<metric name>" : <value>`
-		c.stream.WriteObjectField(name)
-		c.stream.WriteVal(value)
-		c.stream.WriteMore()
-
-		// Write dimension values
-		dims := dms
-		var labelName, labelValue string
-		for len(dims) >= 2 {
-			labelName, labelValue, dims = dims[0], dims[1], dims[2:]
-			c.stream.WriteObjectField(labelName)
-			c.stream.WriteVal(labelValue)
-			c.stream.WriteMore()
-		}
-
-		embeddedMetric := EmbeddedMetric{
-			Timestamp: timeNow,
-			CloudWatchMetrics: []MetricDirectiveObject{
-				{
-					Namespace:  Namespace,
-					Dimensions: []DimensionSet{dimensionNames(dms...)},
-					Metrics:    []Metric{{Name: name, Unit: unit}},
-				},
-			},
-		}
-
-		const rootElement = "_aws"
-		c.stream.WriteObjectField(rootElement)
-		c.stream.WriteVal(embeddedMetric)
-		c.stream.WriteObjectEnd()
-		c.stream.WriteRaw("\n")
+		c.writeMetric(timeNow, name, unit, dms, value)
+		return true
+	})
+	c.histograms.Reset().WalkSamples(func(name, unit string, dms DimensionValues, samples []float64) bool {
+		c.writeMetric(timeNow, name, unit, dms, samples)
 		return true
 	})
 
@@ -163,6 +150,43 @@ func (c *CWEmbeddedMetricsManager) sync() ([]byte, error) {
 	return c.stream.Buffer(), c.stream.Error
 }
 
+// writeMetric writes a log event with a single metric. The value can be a number or an array of numbers.
+func (c *CWEmbeddedMetricsManager) writeMetric(timeNow int64, name, unit string, dms DimensionValues, value interface{}) {
+	c.stream.WriteObjectStart()
+
+	// Write `"<metric name>" : <value>`
+	c.stream.WriteObjectField(name)
+	c.stream.WriteVal(value)
+	c.stream.WriteMore()
+
+	// Write dimension values
+	dims := dms
+	var labelName, labelValue string
+	for len(dims) >= 2 {
+		labelName, labelValue, dims = dims[0], dims[1], dims[2:]
+		c.stream.WriteObjectField(labelName)
+		c.stream.WriteVal(labelValue)
+		c.stream.WriteMore()
+	}
+
+	embeddedMetric := EmbeddedMetric{
+		Timestamp: timeNow,
+		CloudWatchMetrics: []MetricDirectiveObject{
+			{
+				Namespace:  Namespace,
+				Dimensions: []DimensionSet{dimensionNames(dms...)},
+				Metrics:    []Metric{{Name: name, Unit: unit}},
+			},
+		},
+	}
+
+	const rootElement = "_aws"
+	c.stream.WriteObjectField(rootElement)
+	c.stream.WriteVal(embeddedMetric)
+	c.stream.WriteObjectEnd()
+	c.stream.WriteRaw("\n")
+}
+
 func dimensionNames(dimensionValues ...string) DimensionSet {
 	dimensions := make([]string, len(dimensionValues)/2)
 	for i, j := 0, 0; i < len(dimensionValues); i, j = i+2, j+1 {
@@ -137,3 +137,42 @@ func TestNewCounter(t *testing.T) {
 		assert.Equal(t, fmt.Sprintf(`{"test":%d,"dimension":"value1","_aws":{"CloudWatchMetrics":[{"Namespace":"Panther","Dimensions":[["dimension"]],"Metrics":[{"Name":"test","Unit":"Count"}]}],"Timestamp":1000}}`, parallelInvocations)+"\n", buf.String())
 	})
 }
+
+func TestNewHistogram(t *testing.T) {
+	buf := bytes.NewBuffer([]byte{})
+	cm := NewCWEmbeddedMetrics(buf)
+	// Stubbing the time function
+	cm.timeFunc = func() int64 {
+		return 1000
+	}
+
+	t.Run("histogram with dimensions", func(t *testing.T) {
+		buf.Reset()
+		histogram := cm.NewHistogram("test", UnitMilliseconds)
+		histogram.With("dimension", "value1").Observe(1.5)
+		histogram.With("dimension", "value1").Observe(3)
+		assert.NoError(t, cm.Sync())
+		// nolint: lll
+		assert.Equal(t, `{"test":[1.5,3],"dimension":"value1","_aws":{"CloudWatchMetrics":[{"Namespace":"Panther","Dimensions":[["dimension"]],"Metrics":[{"Name":"test","Unit":"Milliseconds"}]}],"Timestamp":1000}}`+"\n", buf.String())
+
+		buf.Reset()
+		// This sync shouldn't write anything, we already synced above
+		assert.NoError(t, cm.Sync())
+		assert.Equal(t, 0, buf.Len())
+	})
+
+	t.Run("histogram keeps at most 100 samples", func(t *testing.T) {
+		buf.Reset()
+		histogram := cm.NewHistogram("test", UnitMilliseconds)
+		for i := 0; i < 1000; i++ {
+			histogram.Observe(float64(i))
+		}
+		var samples int
+		cm.histograms.WalkSamples(func(name, unit string, dvs DimensionValues, values []float64) bool {
+			samples += len(values)
+			return true
+		})
+		assert.Equal(t, maxHistogramSamples, samples)
+		assert.NoError(t, cm.Sync())
+	})
+}
@@ -18,7 +18,10 @@ package metrics
  * along with this program.  If not, see <https://www.gnu.org/licenses/>.
  */
 
-import "sync"
+import (
+	"math/rand"
+	"sync"
+)
 
 type observerFunc func(name, unit string, dvs DimensionValues, value float64)
 
@@ -69,18 +72,25 @@ func NewSpace() *Space {
 	return &Space{}
 }
 
+// NewSampledSpace returns an N-dimensional vector space that also keeps a uniform sample
+// of at most maxSamples observations per time series.
+func NewSampledSpace(maxSamples int) *Space {
+	return &Space{maxSamples: maxSamples}
+}
+
 // Space represents an N-dimensional vector space. Each name and unique label
 // value pair establishes a new dimension and point within that dimension. Order
 // matters, i.e. [a=1 b=2] identifies a different timeseries than [b=2 a=1].
 type Space struct {
-	mtx   sync.RWMutex
-	nodes map[string]*node
+	mtx        sync.RWMutex
+	nodes      map[string]*node
+	maxSamples int
 }
 
 // Observe locates the time series identified by the name and label values in
 // the vector space, and appends the value to the list of observations.
 func (s *Space) Observe(name, unit string, dvs DimensionValues, value float64) {
-	s.nodeFor(name, unit).observe(dvs, value)
+	s.nodeFor(name, unit).observe(dvs, value, s.maxSamples)
 }
 
 // Walk traverses the vector space and invokes fn for each non-empty time series
@@ -98,12 +108,27 @@ func (s *Space) Walk(fn func(name, unit string, dvs DimensionValues, sum float64
 	}
 }
 
+// WalkSamples traverses the vector space and invokes fn with the sampled observations of each non-empty
+// time series. The space must have been created with NewSampledSpace. Return false to abort the traversal.
+func (s *Space) WalkSamples(fn func(name, unit string, dvs DimensionValues, samples []float64) bool) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	for name, node := range s.nodes {
+		name := name
+		unit := node.unit
+		f := func(dvs DimensionValues, samples []float64) bool { return fn(name, unit, dvs, samples) }
+		if !node.walkSamples(DimensionValues{}, f) {
+			return
+		}
+	}
+}
+
 // Reset empties the current space and returns a new Space with the old
 // contents. Reset a Space to get an immutable copy suitable for walking.
 func (s *Space) Reset() *Space {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
-	n := NewSpace()
+	n := NewSampledSpace(s.maxSamples)
 	n.nodes, s.nodes = s.nodes, n.nodes
 	return n
 }
@@ -132,17 +157,20 @@ type node struct {
 	sum float64
 	// number of observations
 	observations int64
-	children     map[pair]*node
+	// uniform sample of the observations, if the space keeps samples
+	samples  []float64
+	children map[pair]*node
 }
 
 type pair struct{ label, value string }
 
-func (n *node) observe(dvs DimensionValues, value float64) {
+func (n *node) observe(dvs DimensionValues, value float64, maxSamples int) {
 	n.mtx.Lock()
 	defer n.mtx.Unlock()
 	if len(dvs) <= 0 {
 		n.observations++
 		n.sum += value
+		n.sample(value, maxSamples)
 		return
 	}
 	if len(dvs) < 2 {
@@ -157,7 +185,21 @@ func (n *node) observe(dvs DimensionValues, value float64) {
 		child = &node{unit: n.unit}
 		n.children[head] = child
 	}
-	child.observe(tail, value)
+	child.observe(tail, value, maxSamples)
+}
+
+// sample keeps the value with reservoir sampling, so that every observation has the same chance to be kept
+func (n *node) sample(value float64, maxSamples int) {
+	if len(n.samples) < maxSamples {
+		n.samples = append(n.samples, value)
+		return
+	}
+	if maxSamples == 0 {
+		return
+	}
+	if i := rand.Int63n(n.observations); i < int64(maxSamples) { // nolint: gosec
+		n.samples[i] = value
+	}
 }
 
 func (n *node) walk(dvs DimensionValues, fn func(DimensionValues, float64, int64) bool) bool {
@@ -173,3 +215,17 @@ func (n *node) walk(dvs DimensionValues, fn func(DimensionValues, float64, int64
 	}
 	return true
 }
+
+func (n *node) walkSamples(dvs DimensionValues, fn func(DimensionValues, []float64) bool) bool {
+	n.mtx.RLock()
+	defer n.mtx.RUnlock()
+	if len(n.samples) > 0 && !fn(dvs, n.samples) {
+		return false
+	}
+	for p, child := range n.children {
+		if !child.walkSamples(append(dvs, p.label, p.value), fn) {
+			return false
+		}
+	}
+	return true
+}
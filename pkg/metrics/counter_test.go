@@ -19,6 +19,7 @@ package metrics
  */
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 )
@@ -110,3 +111,36 @@ func TestSpaceWalkSkipsEmptyDimensions(t *testing.T) {
 		t.Errorf("want %d, have %d", want, have)
 	}
 }
+
+func TestSpaceWalkSamples(t *testing.T) {
+	s := NewSampledSpace(3)
+	s.Observe("foo", "unit", DimensionValues{"bar", "1"}, 1)
+	s.Observe("foo", "unit", DimensionValues{"bar", "1"}, 2)
+	s.Observe("foo", "unit", DimensionValues{"bar", "2"}, 4)
+	for i := 0; i < 100; i++ {
+		s.Observe("foo", "unit", DimensionValues{"bar", "3"}, 8)
+	}
+
+	have := map[string][]float64{}
+	s.WalkSamples(func(name, unit string, lvs DimensionValues, samples []float64) bool {
+		have[strings.Join(lvs, "")] = samples
+		return true
+	})
+	if want := 3; len(have) != want {
+		t.Fatalf("want %d series, have %d", want, len(have))
+	}
+	if want, got := "[1 2]", fmt.Sprint(have["bar1"]); want != got {
+		t.Errorf("want %s, have %s", want, got)
+	}
+	if want, got := "[8 8 8]", fmt.Sprint(have["bar3"]); want != got {
+		t.Errorf("want %s, have %s", want, got)
+	}
+
+	// Spaces without samples do not keep any
+	s = NewSpace()
+	s.Observe("foo", "unit", DimensionValues{}, 1)
+	s.WalkSamples(func(name, unit string, lvs DimensionValues, samples []float64) bool {
+		t.Errorf("unexpected samples %v", samples)
+		return true
+	})
+}
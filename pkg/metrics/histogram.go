@@ -0,0 +1,52 @@
+package metrics
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Per the AWS specification, a metric can have at most 100 values in a single log event
+const maxHistogramSamples = 100
+
+type Histogram interface {
+	With(dimensionValues ...string) Histogram
+	Observe(value float64)
+}
+
+// DimensionsHistogram is a histogram. Observations are forwarded to a node
+// object, which keeps a uniform sample of them per timeseries. CloudWatch computes
+// percentile statistics from the samples.
+type DimensionsHistogram struct {
+	name string
+	unit string
+	dvs  DimensionValues
+	obs  observerFunc
+}
+
+// With implements metrics.Histogram.
+func (d *DimensionsHistogram) With(dvs ...string) Histogram {
+	return &DimensionsHistogram{
+		name: d.name,
+		unit: d.unit,
+		dvs:  d.dvs.With(dvs...),
+		obs:  d.obs,
+	}
+}
+
+// Observe implements metrics.Histogram.
+func (d *DimensionsHistogram) Observe(value float64) {
+	d.obs(d.name, d.unit, d.dvs, value)
+}
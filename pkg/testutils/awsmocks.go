@@ -25,6 +25,8 @@ import (
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/athena"
 	"github.com/aws/aws-sdk-go/service/athena/athenaiface"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 	"github.com/aws/aws-sdk-go/service/eventbridge"
@@ -76,6 +78,11 @@ func (m *S3Mock) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error
 	return args.Get(0).(*s3.GetObjectOutput), args.Error(1)
 }
 
+func (m *S3Mock) PutObject(input *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*s3.PutObjectOutput), args.Error(1)
+}
+
 func (m *S3Mock) GetObjectWithContext(ctx aws.Context, input *s3.GetObjectInput, options ...request.Option) (*s3.GetObjectOutput, error) {
 	args := m.Called(ctx, input, options)
 	return args.Get(0).(*s3.GetObjectOutput), args.Error(1)
@@ -111,6 +118,14 @@ func (m *S3Mock) ListObjectsV2Pages(input *s3.ListObjectsV2Input, f func(page *s
 	return args.Error(1)
 }
 
+func (m *S3Mock) ListObjectVersionsPages(input *s3.ListObjectVersionsInput,
+	f func(page *s3.ListObjectVersionsOutput, lastPage bool) bool) error {
+
+	args := m.Called(input, f)
+	f(args.Get(0).(*s3.ListObjectVersionsOutput), true)
+	return args.Error(1)
+}
+
 func (m *S3Mock) ListObjectsV2PagesWithContext(ctx aws.Context, input *s3.ListObjectsV2Input,
 	f func(page *s3.ListObjectsV2Output, morePages bool) bool, options ...request.Option) error {
 
@@ -211,6 +226,11 @@ func (m *DynamoDBMock) DeleteItem(input *dynamodb.DeleteItemInput) (*dynamodb.De
 	return args.Get(0).(*dynamodb.DeleteItemOutput), args.Error(1)
 }
 
+func (m *DynamoDBMock) BatchWriteItem(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.BatchWriteItemOutput), args.Error(1)
+}
+
 func (m *DynamoDBMock) Query(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
 	args := m.Called(input)
 	return args.Get(0).(*dynamodb.QueryOutput), args.Error(1)
@@ -471,3 +491,24 @@ func (m *FirehoseMock) PutRecordBatchWithContext(
 	args := m.Called(ctx, input, options)
 	return args.Get(0).(*firehose.PutRecordBatchOutput), args.Error(1)
 }
+
+type CloudWatchMock struct {
+	cloudwatchiface.CloudWatchAPI
+	mock.Mock
+}
+
+func (m *CloudWatchMock) ListMetricsPages(input *cloudwatch.ListMetricsInput,
+	f func(page *cloudwatch.ListMetricsOutput, lastPage bool) bool) error {
+
+	args := m.Called(input, f)
+	f(args.Get(0).(*cloudwatch.ListMetricsOutput), true)
+	return args.Error(1)
+}
+
+func (m *CloudWatchMock) GetMetricDataPages(input *cloudwatch.GetMetricDataInput,
+	f func(page *cloudwatch.GetMetricDataOutput, lastPage bool) bool) error {
+
+	args := m.Called(input, f)
+	f(args.Get(0).(*cloudwatch.GetMetricDataOutput), true)
+	return args.Error(1)
+}
@@ -49,6 +49,19 @@ func (m *CounterMock) Add(delta float64) {
 	m.Called(delta)
 }
 
+type HistogramMock struct {
+	metrics.Histogram
+	mock.Mock
+}
+
+func (m *HistogramMock) With(dimensionValues ...string) metrics.Histogram {
+	args := m.Called(dimensionValues)
+	return args.Get(0).(metrics.Histogram)
+}
+func (m *HistogramMock) Observe(value float64) {
+	m.Called(value)
+}
+
 type MetricsManagerMock struct {
 	metrics.Manager
 	mock.Mock
@@ -62,6 +75,11 @@ func (m *MetricsManagerMock) NewCounter(name, unit string) metrics.Counter {
 	return args.Get(0).(metrics.Counter)
 }
 
+func (m *MetricsManagerMock) NewHistogram(name, unit string) metrics.Histogram {
+	args := m.Called(name, unit)
+	return args.Get(0).(metrics.Histogram)
+}
+
 func (m *MetricsManagerMock) Sync() error {
 	return m.Called().Error(0)
 }
@@ -52,10 +52,11 @@ type Infra struct {
 }
 
 type Monitoring struct {
-	AlarmSnsTopicArn           string `yaml:"AlarmSnsTopicArn"`
-	CloudWatchLogRetentionDays int    `yaml:"CloudWatchLogRetentionDays"`
-	Debug                      bool   `yaml:"Debug"`
-	TracingMode                string `yaml:"TracingMode"`
+	AlarmSnsTopicArn            string `yaml:"AlarmSnsTopicArn"`
+	CloudWatchLogRetentionDays  int    `yaml:"CloudWatchLogRetentionDays"`
+	Debug                       bool   `yaml:"Debug"`
+	RuleAutoDisableErrorPercent int    `yaml:"RuleAutoDisableErrorPercent"`
+	TracingMode                 string `yaml:"TracingMode"`
 }
 
 type Setup struct {
@@ -68,6 +69,7 @@ type Setup struct {
 	S3AccessLogsBucket    string           `yaml:"S3AccessLogsBucket"`
 	DataReplicationBucket string           `yaml:"DataReplicationBucket"`
 	InitialAnalysisSets   []string         `yaml:"InitialAnalysisSets"`
+	DetectionPackBuckets  []string         `yaml:"DetectionPackBuckets"`
 	LogSubscriptions      LogSubscriptions `yaml:"LogSubscriptions"`
 }
 
@@ -510,6 +510,7 @@ func deployCoreStack(settings *PantherConfig, packager *pkg.Packager, outputs ma
 		"CompanyEmail":               settings.Setup.Company.Email,
 		"CustomResourceVersion":      customResourceVersion(),
 		"Debug":                      strconv.FormatBool(settings.Monitoring.Debug),
+		"DetectionPackBuckets":       strings.Join(settings.Setup.DetectionPackBuckets, ","),
 		"DynamoScalingRoleArn":       outputs["DynamoScalingRoleArn"],
 		"InputDataBucket":            outputs["InputDataBucket"],
 		"InputDataTopicArn":          outputs["InputDataTopicArn"],
@@ -517,6 +518,7 @@ func deployCoreStack(settings *PantherConfig, packager *pkg.Packager, outputs ma
 		"OutputsKeyId":               outputs["OutputsEncryptionKeyId"],
 		"PantherVersion":             util.Semver(),
 		"KvTableBillingMode":         settings.Infra.KvTableBillingMode,
+		"ProcessedDataBucket":        outputs["ProcessedDataBucket"],
 		"SqsKeyId":                   outputs["QueueEncryptionKeyId"],
 		"TracingMode":                settings.Monitoring.TracingMode,
 		"UserPoolId":                 outputs["UserPoolId"],
@@ -547,6 +549,7 @@ func deployLogAnalysisStack(settings *PantherConfig, packager *pkg.Packager, out
 		"PythonAssumableRoleArns":            strings.Join(settings.Infra.PythonAssumableRoleArns, ","),
 		"PythonLayerVersionArn":              outputs["PythonLayerVersionArn"],
 		"PythonManagedPolicyArn":             settings.Infra.PythonManagedPolicyArn,
+		"RuleAutoDisableErrorPercent":        strconv.Itoa(settings.Monitoring.RuleAutoDisableErrorPercent),
 		"SqsKeyId":                           outputs["QueueEncryptionKeyId"],
 		"TracingMode":                        settings.Monitoring.TracingMode,
 	})